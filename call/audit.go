@@ -0,0 +1,115 @@
+package call
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+)
+
+// auditAnnotation marks commands which modify repositories and should be recorded in the audit log.
+const auditAnnotation = "batch-tool/audit"
+
+// AuditEntry is a single record in the audit log, describing one run of a write-capable command.
+type AuditEntry struct {
+	Time      time.Time         `json:"time"`
+	User      string            `json:"user"`
+	Command   string            `json:"command"`
+	Flags     map[string]string `json:"flags,omitempty"`
+	Selection []string          `json:"selection"`
+	Results   []AuditResult     `json:"results"`
+}
+
+// AuditResult is the outcome of a write-capable command for a single repository.
+type AuditResult struct {
+	Repo        string `json:"repo"`
+	Success     bool   `json:"success"`
+	ExitCode    int    `json:"exit_code,omitempty"`
+	PullRequest int    `json:"pull_request,omitempty"`
+	MergeCommit string `json:"merge_commit,omitempty"`
+}
+
+// Audited marks the command as write-capable, so that each run is recorded in the audit log (if configured).
+func Audited(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+
+	cmd.Annotations[auditAnnotation] = "true"
+
+	return cmd
+}
+
+// IsAudited indicates whether the command is marked as write-capable.
+func IsAudited(cmd *cobra.Command) bool {
+	return cmd.Annotations[auditAnnotation] == "true"
+}
+
+// writeAudit appends an entry describing the completed run to the configured audit log.
+// Nothing is written if the command is not write-capable or no audit log is configured.
+func writeAudit(cmd *cobra.Command, selection []string, results []output.Result) error {
+	path := config.Viper(cmd.Context()).GetString(config.AuditPath)
+	if path == "" || !IsAudited(cmd) {
+		return nil
+	}
+
+	entry := AuditEntry{
+		Time:      time.Now().UTC(),
+		User:      auditUser(),
+		Command:   cmd.CommandPath(),
+		Flags:     make(map[string]string),
+		Selection: selection,
+		Results:   make([]AuditResult, len(results)),
+	}
+
+	// record only the flags declared as meaningful which were explicitly provided
+	for _, flag := range output.RecordedFlags(cmd) {
+		entry.Flags[flag.Name] = flag.Value
+	}
+
+	for i, result := range results {
+		entry.Results[i] = AuditResult{Repo: result.Repo, Success: !result.Failed(), ExitCode: result.ExitCode}
+
+		if pr := result.PullRequest; pr != nil {
+			entry.Results[i].PullRequest = pr.Number
+			entry.Results[i].MergeCommit = pr.MergeCommit
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// auditUser returns the name of the user running the command.
+func auditUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
@@ -0,0 +1,138 @@
+package call
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// readAudit parses every entry in the audit log at the given path.
+func readAudit(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse audit entry %q: %v", scanner.Text(), err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestDoWritesAudit(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	config.Viper(ctx).Set(config.AuditPath, path)
+
+	var buf bytes.Buffer
+
+	cmd := Audited(output.RecordFlags(fakeCmd(t, ctx, &buf), "message"))
+	cmd.Flags().String("message", "", "")
+	cmd.Flags().StringSlice("env", nil, "")
+	if err := cmd.Flags().Set("message", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	// flags which aren't declared to be recorded (such as credentials) are left out of the audit log
+	if err := cmd.Flags().Set("env", "AUTH_TOKEN=secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run twice to verify that entries are appended rather than overwritten
+	for range 2 {
+		Do(cmd, repos, func(ctx context.Context, ch output.Channel) error {
+			if ch.Name() == "repo1" {
+				ch.SetPullRequest(&scm.PullRequest{Number: 7, MergeCommit: "abc123"})
+			}
+
+			return fakeCallFunc(t, ch.Name() == "repo2")(ctx, ch)
+		})
+	}
+
+	entries := readAudit(t, path)
+	testhelper.AssertLength(t, entries, 2)
+
+	entry := entries[0]
+	if entry.Command != cmd.CommandPath() {
+		t.Errorf("Expected command %q, got %q", cmd.CommandPath(), entry.Command)
+	}
+
+	if entry.Time.IsZero() {
+		t.Error("Expected audit entry to have a timestamp")
+	}
+
+	if !reflect.DeepEqual(entry.Selection, repos) {
+		t.Errorf("Expected selection %v, got %v", repos, entry.Selection)
+	}
+
+	if want := map[string]string{"message": "hello"}; !reflect.DeepEqual(entry.Flags, want) {
+		t.Errorf("Expected flags %v, got %v", want, entry.Flags)
+	}
+
+	want := []AuditResult{{Repo: "repo1", Success: true, PullRequest: 7, MergeCommit: "abc123"}, {Repo: "repo2", Success: false}}
+	if !reflect.DeepEqual(entry.Results, want) {
+		t.Errorf("Expected results %v, got %v", want, entry.Results)
+	}
+}
+
+func TestDoSkipsAudit(t *testing.T) {
+	tests := []struct {
+		name    string
+		audited bool
+		path    bool
+	}{
+		{name: "command not audited", audited: false, path: true},
+		{name: "audit log not configured", audited: true, path: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			repos := []string{"repo1"}
+			testhelper.SetupDirs(t, ctx, repos)
+
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			if tt.path {
+				config.Viper(ctx).Set(config.AuditPath, path)
+			}
+
+			var buf bytes.Buffer
+
+			cmd := fakeCmd(t, ctx, &buf)
+			if tt.audited {
+				cmd = Audited(cmd)
+			}
+
+			Do(cmd, repos, fakeCallFunc(t, false))
+
+			testhelper.AssertLength(t, readAudit(t, path), 0)
+		})
+	}
+}
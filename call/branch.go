@@ -0,0 +1,113 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// filterByBranch keeps only the repositories in which a branch matching the pattern (a branch name or glob such as
+// feature/*) exists. The branches of each local clone are checked first, including its remote-tracking branches,
+// followed by the remote's branches. Repositories which haven't been cloned yet are checked against the remote only.
+func filterByBranch(ctx context.Context, repos []string, pattern string) ([]string, error) {
+	maxConcurrency := config.Viper(ctx).GetInt(config.MaxConcurrency)
+	if maxConcurrency <= 0 {
+		maxConcurrency = runtime.NumCPU()
+	}
+
+	sem := semaphore.NewWeighted(int64(maxConcurrency))
+	wg := new(sync.WaitGroup)
+
+	found := make([]bool, len(repos))
+	errs := make([]error, len(repos))
+
+	for i, repo := range repos {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if errs[i] = sem.Acquire(ctx, 1); errs[i] != nil {
+				return
+			}
+			defer sem.Release(1)
+
+			found[i], errs[i] = hasBranch(ctx, repo, pattern)
+		}()
+	}
+
+	wg.Wait()
+
+	filtered := make([]string, 0, len(repos))
+
+	for i, repo := range repos {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to check for branch %s in %s: %w", pattern, repo, errs[i])
+		}
+
+		if found[i] {
+			filtered = append(filtered, repo)
+		}
+	}
+
+	return filtered, nil
+}
+
+// hasBranch reports whether a branch matching the pattern exists in the repository, locally or on the remote.
+func hasBranch(ctx context.Context, name, pattern string) (bool, error) {
+	repoName, _ := utils.SplitTarget(name)
+	ref := "refs/heads/" + pattern
+
+	if _, err := os.Stat(utils.RepoPath(ctx, repoName)); os.IsNotExist(err) {
+		return remoteHasBranch(ctx, repoName, ref)
+	}
+
+	// local branches and remote-tracking branches avoid a round trip to the remote
+	local, err := gitRefs(ctx, repoName, "for-each-ref", "--count=1", "--format=%(refname)", ref, "refs/remotes/origin/"+pattern)
+	if err != nil || local != "" {
+		return local != "", err
+	}
+
+	remote, err := gitRefs(ctx, repoName, "ls-remote", "--heads", "origin", ref)
+
+	return remote != "", err
+}
+
+// remoteHasBranch reports whether a branch matching the ref pattern exists on the remote of a repository which
+// hasn't been cloned, authenticating over HTTPS in the same way as a clone.
+func remoteHasBranch(ctx context.Context, repoName, ref string) (bool, error) {
+	repoURL := utils.RepoURL(ctx, repoName)
+
+	auth, err := gitAuthEnv(ctx, repoName)
+	if err != nil {
+		return false, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", repoURL, ref)
+	cmd.Env = append(os.Environ(), auth...)
+
+	out, err := cmd.Output()
+
+	return strings.TrimSpace(string(out)) != "", err
+}
+
+// gitRefs runs the git command in the repository and returns its trimmed output.
+func gitRefs(ctx context.Context, repoName string, args ...string) (string, error) {
+	cmd, err := utils.Cmd(ctx, repoName, "git", args...)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := cmd.Output()
+
+	return strings.TrimSpace(string(out)), err
+}
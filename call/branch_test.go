@@ -0,0 +1,82 @@
+package call
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestFilterByBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2", "repo-3"})
+	projectPath := filepath.Join(reposPath, "example.com", "test-project")
+
+	// repo-1 has an unpushed local branch, while repo-2 pushes a branch which repo-3 only sees on the remote
+	testhelper.ExecCommand(t, filepath.Join(projectPath, "repo-1"), "git", "branch", "feature/local")
+	testhelper.ExecCommand(t, filepath.Join(projectPath, "repo-2"), "git", "push", "origin", "HEAD:refs/heads/release/1.0")
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitDirectory, reposPath)
+	viper.Set(config.GitHost, "example.com")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.GitProvider, "fake")
+
+	// repositories which haven't been cloned are checked against their remote
+	original := utils.CatalogURLLookup
+	t.Cleanup(func() { utils.CatalogURLLookup = original })
+	utils.CatalogURLLookup = func(_ context.Context, _ string, _ bool) string { return filepath.Join(reposPath, "origin.git") }
+
+	repos := []string{"repo-1", "repo-2", "repo-3", "repo-4"}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "local branch", pattern: "feature/local", want: []string{"repo-1"}},
+		{name: "local glob", pattern: "feature/*", want: []string{"repo-1"}},
+		{name: "remote branch", pattern: "release/*", want: []string{"repo-1", "repo-2", "repo-3", "repo-4"}},
+		{name: "missing branch", pattern: "hotfix/*", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterByBranch(ctx, repos, tt.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, strings.Join(got, ","), strings.Join(tt.want, ","))
+		})
+	}
+}
+
+func TestSelectRepositoriesBranchPattern(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"})
+	testhelper.ExecCommand(t, filepath.Join(reposPath, "example.com", "test-project", "repo-2"), "git", "branch", "feature-branch")
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitDirectory, reposPath)
+	viper.Set(config.GitHost, "example.com")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.BranchPattern, "feature-branch")
+
+	repos, err := SelectRepositories(ctx, []string{"repo-1", "repo-2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, strings.Join(repos, ","), "repo-2")
+
+	viper.Set(config.BranchPattern, "missing-branch")
+
+	_, err = SelectRepositories(ctx, []string{"repo-1", "repo-2"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "--branch-pattern missing-branch")
+}
@@ -2,6 +2,7 @@ package call
 
 import (
 	"context"
+	"errors"
 
 	"github.com/ryclarke/batch-tool/output"
 	"github.com/ryclarke/batch-tool/utils"
@@ -29,12 +30,28 @@ func Wrap(calls ...Func) Func {
 // Exec creates a new Func to execute the given command and arguments,
 // streaming Stdout and Stderr to the channel and returning error status.
 func Exec(command string, arguments ...string) Func {
+	return execFunc(false, command, arguments...)
+}
+
+// ExecGroup is like Exec, but runs the command in its own process group, which is killed as a whole when the
+// context is done (e.g. on a timeout). Since the command no longer receives the signals sent to the terminal's
+// process group, the caller must cancel the context when interrupted.
+func ExecGroup(command string, arguments ...string) Func {
+	return execFunc(true, command, arguments...)
+}
+
+// execFunc creates the Func for Exec and ExecGroup, running the command in its own process group if group is set.
+func execFunc(group bool, command string, arguments ...string) Func {
 	return func(ctx context.Context, ch output.Channel) error {
 		cmd, err := utils.Cmd(ctx, ch.Name(), command, arguments...)
 		if err != nil {
 			return err
 		}
 
+		if group {
+			utils.SetProcessGroup(cmd)
+		}
+
 		// Directly use channel as io.Writer
 		cmd.Stdout, cmd.Stderr = ch, ch
 
@@ -42,11 +59,19 @@ func Exec(command string, arguments ...string) Func {
 	}
 }
 
+// ErrNoRepositories is returned when the provided repository selection does not match any repositories.
+var ErrNoRepositories = errors.New("no repositories matched")
+
 // Error wraps runtime errors that occur during subprocess execution.
 type Error struct {
 	error
 }
 
+// NewError wraps the given error as an Error, marking it as a runtime failure rather than a usage error.
+func NewError(err error) error {
+	return &Error{err}
+}
+
 // Error implements the error interface.
 func (e *Error) Error() string {
 	if e.error != nil {
@@ -0,0 +1,83 @@
+package call
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+func init() {
+	utils.GitAuthEnv = gitAuthEnv
+}
+
+// clone clones the repository into the given directory using the configured clone protocol. HTTPS clones
+// authenticate with the SCM auth token (if any), which is passed to git through the environment so that it
+// is never written to the repository's git config or exposed in the process arguments.
+// Path-scoped targets clone the whole repository containing them.
+func clone(ctx context.Context, ch output.Channel, repoDir string) error {
+	repo := utils.ResolveRepoName(ch.Name())
+
+	cmd, err := utils.Cmd(ctx, repo, "git", "clone", utils.RepoURL(ctx, repo), repoDir)
+	if err != nil {
+		return err
+	}
+
+	// run from the parent directory, since the repository's own directory is only populated by the clone
+	cmd.Dir = filepath.Dir(repoDir)
+	cmd.Stdout, cmd.Stderr = ch, ch
+
+	return cmd.Run()
+}
+
+// gitAuthEnv returns the environment variables which authenticate git over HTTPS with the SCM auth token, for every
+// git command run against the repository and not just its clone, since the token is never stored with its remote.
+// Nothing is returned when cloning over SSH.
+func gitAuthEnv(ctx context.Context, repo string) ([]string, error) {
+	if utils.CloneWithSSH(ctx) {
+		return nil, nil
+	}
+
+	token, err := scm.AuthToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneAuthEnv(ctx, utils.RepoURL(ctx, utils.ResolveRepoName(repo)), token), nil
+}
+
+// cloneAuthEnv returns the environment variables which configure git to send the token as an authorization
+// header for requests to the host of the given HTTPS URL. It returns nil if there is no token to send.
+func cloneAuthEnv(ctx context.Context, repoURL, token string) []string {
+	u, err := url.Parse(repoURL)
+	if token == "" || err != nil || u.Scheme != "https" {
+		return nil
+	}
+
+	// GitHub, GitLab and Bitbucket Cloud expect basic auth for git over HTTPS, while other providers accept the
+	// token as a bearer token
+	header := "Authorization: Bearer " + token
+	switch config.Viper(ctx).GetString(config.GitProvider) {
+	case "github":
+		header = "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token))
+	case "gitlab":
+		header = "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:"+token))
+	case "bitbucket":
+		if strings.EqualFold(u.Host, "bitbucket.org") {
+			header = "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("x-token-auth:"+token))
+		}
+	}
+
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		fmt.Sprintf("GIT_CONFIG_KEY_0=http.https://%s/.extraHeader", u.Host),
+		"GIT_CONFIG_VALUE_0=" + header,
+	}
+}
@@ -0,0 +1,219 @@
+package call
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/utils"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestCloneAuthEnv(t *testing.T) {
+	basic := base64.StdEncoding.EncodeToString([]byte("x-access-token:secret"))
+
+	tests := []struct {
+		name     string
+		provider string
+		url      string
+		token    string
+		want     []string
+	}{
+		{
+			name:     "github basic auth",
+			provider: "github",
+			url:      "https://github.com/test-project/repo.git",
+			token:    "secret",
+			want: []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Basic " + basic,
+			},
+		},
+		{
+			name:     "gitlab basic auth",
+			provider: "gitlab",
+			url:      "https://gitlab.example.com/test-project/repo.git",
+			token:    "secret",
+			want: []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.https://gitlab.example.com/.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:secret")),
+			},
+		},
+		{
+			name:     "bitbucket cloud basic auth",
+			provider: "bitbucket",
+			url:      "https://bitbucket.org/test-project/repo.git",
+			token:    "secret",
+			want: []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.https://bitbucket.org/.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("x-token-auth:secret")),
+			},
+		},
+		{
+			name:     "bearer auth for other providers",
+			provider: "bitbucket",
+			url:      "https://bitbucket.example.com/scm/proj/repo.git",
+			token:    "secret",
+			want: []string{
+				"GIT_CONFIG_COUNT=1",
+				"GIT_CONFIG_KEY_0=http.https://bitbucket.example.com/.extraHeader",
+				"GIT_CONFIG_VALUE_0=Authorization: Bearer secret",
+			},
+		},
+		{name: "no token", provider: "github", url: "https://github.com/test-project/repo.git"},
+		{name: "SSH URL", provider: "github", url: "ssh://git@github.com/test-project/repo.git", token: "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.GitProvider, tt.provider)
+
+			got := cloneAuthEnv(ctx, tt.url, tt.token)
+			testhelper.AssertLength(t, got, len(tt.want))
+
+			for i := range tt.want {
+				testhelper.AssertEqual(t, got[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestCloneDoesNotStoreToken(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.AuthToken, "super-secret-token")
+	viper.Set(config.GitDirectory, t.TempDir())
+
+	// Serve the clone from a local repository in place of the remote
+	origin := testhelper.SetupRepos(t, []string{"origin-repo"})
+	originPath := filepath.Join(origin, "example.com", "test-project", "origin-repo")
+
+	original := utils.CatalogURLLookup
+	t.Cleanup(func() { utils.CatalogURLLookup = original })
+	utils.CatalogURLLookup = func(_ context.Context, _ string, _ bool) string { return originPath }
+
+	repoDir := utils.RepoPath(ctx, "cloned-repo")
+	if err := os.MkdirAll(repoDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := testhelper.NewMockChannel("cloned-repo")
+	if err := clone(ctx, ch, repoDir); err != nil {
+		t.Fatalf("Clone failed: %v\n%s", err, ch.Output())
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoDir, ".git", "config"))
+	if err != nil {
+		t.Fatalf("Failed to read git config: %v", err)
+	}
+
+	if strings.Contains(string(data), "super-secret-token") {
+		t.Errorf("Expected the token not to be stored in the git config, got:\n%s", data)
+	}
+}
+
+func TestClonePathScopedTargets(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitDirectory, t.TempDir())
+	viper.Set(config.MaxConcurrency, 2)
+	viper.Set(config.SortRepos, false)
+
+	// Serve the clone from a local monorepo in place of the remote
+	origin := t.TempDir()
+	for _, dir := range []string{"services/api", "services/worker"} {
+		if err := os.MkdirAll(filepath.Join(origin, dir), 0o750); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(origin, dir, "main.go"), []byte("package main\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "-m", "Initial commit"},
+	} {
+		testhelper.ExecCommand(t, origin, "git", args...)
+	}
+
+	original := utils.CatalogURLLookup
+	t.Cleanup(func() { utils.CatalogURLLookup = original })
+	utils.CatalogURLLookup = func(_ context.Context, _ string, _ bool) string { return origin }
+
+	testFunc := func(ctx context.Context, ch output.Channel) error {
+		if _, err := os.Stat(filepath.Join(utils.RepoPath(ctx, ch.Name()), "main.go")); err != nil {
+			return err
+		}
+
+		ch.WriteString("ran in " + ch.Name())
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	// sibling targets of the missing monorepo clone it once, before running in their subdirectories
+	err := Do(fakeCmd(t, ctx, &buf), []string{"monorepo//services/api", "monorepo//services/worker"}, testFunc)
+	if err != nil {
+		t.Fatalf("Expected path-scoped targets to clone their repository: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"ran in monorepo//services/api", "ran in monorepo//services/worker"})
+
+	if got := strings.Count(buf.String(), "Cloning into"); got != 1 {
+		t.Errorf("Expected the repository to be cloned once, got %d clones:\n%s", got, buf.String())
+	}
+}
+
+func TestGitCommandsAuthenticate(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitProvider, "github")
+	viper.Set(config.AuthToken, "secret")
+
+	original := utils.CatalogURLLookup
+	t.Cleanup(func() { utils.CatalogURLLookup = original })
+	utils.CatalogURLLookup = func(_ context.Context, _ string, ssh bool) string {
+		if ssh {
+			return "ssh://git@github.com/test-project/repo.git"
+		}
+
+		return "https://github.com/test-project/repo.git"
+	}
+
+	tests := []struct {
+		name     string
+		protocol string
+		command  string
+		wantAuth bool
+	}{
+		{name: "git over HTTPS", protocol: config.CloneProtocolHTTPS, command: "git", wantAuth: true},
+		{name: "git over SSH", protocol: config.CloneProtocolSSH, command: "git"},
+		{name: "other commands", protocol: config.CloneProtocolHTTPS, command: "make"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(config.CloneProtocol, tt.protocol)
+
+			cmd, err := utils.Cmd(ctx, "repo", tt.command, "push")
+			testhelper.AssertError(t, err, false)
+
+			found := slices.Contains(cmd.Env, "GIT_CONFIG_KEY_0=http.https://github.com/.extraHeader")
+			testhelper.AssertEqual(t, found, tt.wantAuth)
+		})
+	}
+}
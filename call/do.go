@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
-	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
@@ -23,16 +24,40 @@ import (
 // Output formatting can be fully customized by optionally providing one or more OutputHandler functions. Each
 // repository will also be cloned first if it is missing from the local file system.
 func Do(cmd *cobra.Command, repos []string, callFunc Func, handler ...output.Handler) error {
+	_, err := DoResults(cmd, repos, callFunc, handler...)
+
+	return err
+}
+
+// DoResults is like Do, but also returns the typed result of the Func for each repository, in the order the
+// repositories were processed. This is intended for using batch-tool as a library, where the outcome of each
+// repository (such as the pull request opened or the exit code of a failed command) is needed programmatically.
+func DoResults(cmd *cobra.Command, repos []string, callFunc Func, handler ...output.Handler) ([]output.Result, error) {
 	// Establish a single cancellable context for the entire batch run. The cancel function is
 	// attached to the context as a value so that output handlers (e.g. the TUI) can trigger
 	// cancellation in response to user input, propagating SIGKILL to all in-flight subprocesses.
-	ctx, cancel := config.WithCancel(cmd.Context())
+	//
+	// Commands run in their own process groups (see ExecGroup) don't receive the interrupt from the terminal, so it
+	// stops them by cancelling the context instead. The default behavior is restored afterward, so a second interrupt
+	// exits immediately.
+	interrupted, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	context.AfterFunc(interrupted, stop)
+
+	ctx, cancel := config.WithCancel(interrupted)
 	defer cancel()
 
 	cmd.SetContext(ctx)
 
 	viper := config.Viper(ctx)
-	repos = processArguments(ctx, repos)
+	selection := repos
+	start := time.Now()
+
+	repos, err := SelectRepositories(ctx, selection)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine concurrency level
 	maxConcurrency := viper.GetInt(config.MaxConcurrency)
@@ -49,45 +74,126 @@ func Do(cmd *cobra.Command, repos []string, callFunc Func, handler ...output.Han
 		channels[i] = output.NewChannel(ctx, repos[i], sem, wg)
 	}
 
-	// start workers with concurrency limit
-	for i := range repos {
-		wg.Add(1)
-		// launch each Func in its own goroutine with a child Viper context
-		go runCallFunc(config.SetChild(ctx), channels[i], callFunc)
+	// Split the repositories into batches which run one after another, or a single batch if unlimited
+	batchSize := viper.GetInt(config.BatchSize)
+	if batchSize <= 0 {
+		batchSize = len(repos)
 	}
 
+	// start workers with concurrency limit, in selection order so that the semaphore is acquired
+	// in the same order that output is rendered (otherwise a later repository could hold the
+	// semaphore while blocked on its output, which the handler hasn't started reading yet)
+	wg.Add(len(repos))
+	go func() {
+		for lo := 0; lo < len(repos); lo += batchSize {
+			batch := new(sync.WaitGroup)
+			batchStart := time.Now()
+			hi := min(lo+batchSize, len(repos))
+
+			for i := lo; i < hi; i++ {
+				started := make(chan struct{})
+				startAt := batchStart.Add(startJitter(ctx, repos[i], i-lo, hi-lo))
+
+				// launch each Func in its own goroutine with a child Viper context
+				batch.Add(1)
+				go func() {
+					defer batch.Done()
+					runCallFunc(config.SetChild(ctx), channels[i], callFunc, started, startAt)
+				}()
+				<-started
+			}
+
+			// every repository in the batch must finish (successfully or not) before the next batch starts
+			batch.Wait()
+		}
+	}()
+
 	// use the default output handler if none provided
 	if len(handler) == 0 {
 		handler = append(handler, output.GetHandler(ctx))
 	}
 
+	// tee the output of each repository into its log file, if configured
+	handled := output.LogChannels(cmd, channels)
+
 	// process output using provided handler(s)
 	for _, handle := range handler {
-		handle(cmd, channels)
+		handle(cmd, handled)
 	}
 
 	wg.Wait()
 
+	results := output.Results(channels)
+
+	if err := writeAudit(cmd, selection, results); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: failed to write audit log: %v\n", err)
+	}
+
+	if err := writeSummary(cmd, results, time.Since(start)); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: failed to write JSON summary: %v\n", err)
+	}
+
 	var numFailed int
-	for _, ch := range channels {
-		if ch.Failed() {
+	for _, result := range results {
+		if result.Failed() {
 			numFailed++
 		}
 	}
 
 	if numFailed > 0 {
-		return &Error{fmt.Errorf("%d failures reported (see output for details)", numFailed)}
+		return results, &Error{fmt.Errorf("%d of %d repositories failed (see output for details)", numFailed, len(channels))}
 	}
 
-	return nil
+	return results, nil
+}
+
+// SelectRepositories resolves the arguments to the repositories to operate on, keeping only those with a matching
+// branch if a branch pattern is configured. An empty selection is refused, since it is almost always a mistake in
+// the provided filters, unless explicitly allowed.
+func SelectRepositories(ctx context.Context, args []string) ([]string, error) {
+	viper := config.Viper(ctx)
+	repos := processArguments(ctx, args)
+	filters := strings.Join(args, " ")
+
+	for _, repo := range repos {
+		if err := utils.ValidateTarget(repo); err != nil {
+			return nil, err
+		}
+	}
+
+	if pattern := viper.GetString(config.BranchPattern); pattern != "" {
+		var err error
+		if repos, err = filterByBranch(ctx, repos, pattern); err != nil {
+			return nil, err
+		}
+
+		filters += " --branch-pattern " + pattern
+	}
+
+	if len(repos) == 0 && !viper.GetBool(config.AllowEmpty) {
+		return nil, fmt.Errorf("%w: %s (use --allow-empty to proceed anyway)", ErrNoRepositories, filters)
+	}
+
+	return repos, nil
 }
 
 // runCallFunc executes the provided Func for a single repository, managing concurrency via the provided semaphore and wait group.
-// Output channels are closed after execution, and the repository is cloned first if it does not exist locally.
-func runCallFunc(ctx context.Context, ch output.Channel, callFunc Func) {
+// The started channel is closed once the semaphore has been acquired (or failed to be acquired). Output channels are closed
+// after execution, the start is delayed until the given time (the start of the batch plus its jitter), and the repository
+// is cloned first if it does not exist locally.
+func runCallFunc(ctx context.Context, ch output.Channel, callFunc Func, started chan<- struct{}, startAt time.Time) {
 	defer ch.Close()
 
-	if err := ch.Start(1); err != nil {
+	// Spread out the start of each repository, if configured, to avoid bursts of requests to the provider. The jitter
+	// is waited before acquiring the semaphore, so that no slot is held in the meantime.
+	err := waitJitter(ctx, startAt)
+	if err == nil {
+		err = ch.Start(1)
+	}
+
+	close(started)
+
+	if err != nil {
 		ch.WriteError(err)
 		return
 	}
@@ -96,19 +202,18 @@ func runCallFunc(ctx context.Context, ch output.Channel, callFunc Func) {
 	ch.WriteString("")
 
 	if ch.Name() != "." {
-		// If the repository is missing, attempt to clone it first
-		repoDir := utils.RepoPath(ctx, ch.Name())
-		if _, err := os.Stat(repoDir); os.IsNotExist(err) {
-			// Create the directory if it doesn't exist yet
-			if err := os.MkdirAll(repoDir, 0o750); err != nil {
-				ch.WriteError(err)
-				return
-			}
+		// If the repository is missing, attempt to clone it first (the whole repository for path-scoped targets)
+		repoName, subdir := utils.SplitTarget(ch.Name())
+		if err := ensureCloned(ctx, ch, utils.RepoPath(ctx, repoName)); err != nil {
+			// Clone failed, return the error and abort further processing
+			ch.WriteError(err)
+			return
+		}
 
-			// Execute git clone into the target directory
-			if err := Exec("git", "clone", utils.RepoURL(ctx, ch.Name()), repoDir)(ctx, ch); err != nil {
-				// Clone failed, return the error and abort further processing
-				ch.WriteError(err)
+		// Path-scoped targets must refer to an existing directory within the repository
+		if subdir != "" {
+			if info, err := os.Stat(utils.RepoPath(ctx, ch.Name())); err != nil || !info.IsDir() {
+				ch.WriteError(fmt.Errorf("path %q not found in repository %s", subdir, repoName))
 				return
 			}
 		}
@@ -120,7 +225,37 @@ func runCallFunc(ctx context.Context, ch output.Channel, callFunc Func) {
 	}
 }
 
-// processArguments expands repository aliases, sorts repositories if configured, and sets appropriate write backoff.
+// cloneLocks holds a mutex for each repository directory, so that the path-scoped targets of a repository
+// which are run concurrently clone it only once.
+var cloneLocks sync.Map
+
+// ensureCloned clones the repository into repoDir unless it already exists, waiting on any other target of
+// the same repository which is cloning it.
+func ensureCloned(ctx context.Context, ch output.Channel, repoDir string) error {
+	lock, _ := cloneLocks.LoadOrStore(repoDir, new(sync.Mutex))
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if _, err := os.Stat(repoDir); !os.IsNotExist(err) {
+		return nil
+	}
+
+	// Create the directory if it doesn't exist yet
+	if err := os.MkdirAll(repoDir, 0o750); err != nil {
+		return err
+	}
+
+	// Execute git clone into the target directory
+	if err := clone(ctx, ch, repoDir); err != nil {
+		// remove the empty directory, so that the clone is attempted again rather than treated as existing
+		os.Remove(repoDir)
+		return err
+	}
+
+	return nil
+}
+
+// processArguments expands repository aliases in a deterministic order (sorted if configured), and sets appropriate write backoff.
 func processArguments(ctx context.Context, args []string) []string {
 	viper := config.Viper(ctx)
 	if len(args) == 1 && strings.TrimSpace(args[0]) == "." {
@@ -128,12 +263,7 @@ func processArguments(ctx context.Context, args []string) []string {
 		return []string{"."}
 	}
 
-	repos := catalog.RepositoryList(ctx, args...).ToSlice()
-
-	// Sort the repositories alphabetically
-	if viper.GetBool(config.SortRepos) {
-		sort.Strings(repos)
-	}
+	repos := catalog.RepositoryNames(ctx, args...)
 
 	// Determine appropriate write backoff based on number of repositories to be processed (within provider-specific limits)
 	switch viper.GetString(config.GitProvider) {
@@ -143,7 +273,7 @@ func processArguments(ctx context.Context, args []string) []string {
 		} else {
 			viper.Set(config.WriteBackoff, viper.GetString(config.GithubBackoffLarge))
 		}
-	case "bitbucket":
+	case "bitbucket", "gitea", "gitlab", "azuredevops", "rest":
 		// use default write backoff
 	}
 
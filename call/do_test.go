@@ -3,7 +3,12 @@ package call
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,6 +19,8 @@ import (
 
 	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -75,6 +82,48 @@ func TestDo(t *testing.T) {
 }
 
 // TestDoConcurrency tests the concurrency configuration of Do
+// TestDoResults tests that the typed result of each repository is delivered in selection order
+func TestDoResults(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		switch ch.Name() {
+		case "repo1":
+			ch.SetPullRequest(&scm.PullRequest{Number: 7, Repo: ch.Name()})
+		case "repo2":
+			return exec.Command("sh", "-c", "exit 3").Run()
+		case "repo3":
+			ch.Skip("nothing to do")
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	results, err := DoResults(fakeCmd(t, ctx, &buf), repos, callFunc)
+
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertLength(t, results, len(repos))
+
+	for i, result := range results {
+		testhelper.AssertEqual(t, result.Repo, repos[i])
+	}
+
+	if pr := results[0].PullRequest; results[0].Failed() || pr == nil || pr.Number != 7 {
+		t.Errorf("Expected repo1 to succeed with pull request #7, got %+v", results[0])
+	}
+
+	if !results[1].Failed() || results[1].ExitCode != 3 {
+		t.Errorf("Expected repo2 to fail with exit code 3, got %+v", results[1])
+	}
+
+	if results[2].Failed() || results[2].Skipped != "nothing to do" {
+		t.Errorf("Expected repo3 to be skipped, got %+v", results[2])
+	}
+}
+
 func TestDoConcurrency(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -203,7 +252,79 @@ func TestDoBatching(t *testing.T) {
 	}
 }
 
-// TestProcessArguments tests the processArguments function which expands and sorts repos
+// TestDoBatchSize tests that repositories are processed in sequential batches of the configured size
+func TestDoBatchSize(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	repos := []string{"repo1", "repo2", "repo3", "repo4", "repo5"}
+
+	viper.Set(config.BatchSize, 2)
+	viper.Set(config.MaxConcurrency, len(repos))
+	viper.Set(config.ChannelBuffer, 10)
+	viper.Set(config.SortRepos, false)
+
+	testhelper.SetupDirs(t, ctx, repos)
+
+	var mutex sync.Mutex
+	var active, maxActive int
+	started, finished := make(map[string]int), make(map[string]int)
+	var events int
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		mutex.Lock()
+		events++
+		started[ch.Name()] = events
+		active++
+		maxActive = max(maxActive, active)
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		events++
+		finished[ch.Name()] = events
+		active--
+		mutex.Unlock()
+
+		ch.WriteString(ch.Name())
+
+		// the whole first batch fails, which must not prevent the later batches from running
+		if ch.Name() == "repo1" || ch.Name() == "repo2" {
+			return errors.New("batch failed")
+		}
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	results, err := DoResults(fakeCmd(t, ctx, &buf), repos, callFunc, output.NativeHandler)
+	testhelper.AssertError(t, err, true)
+
+	if maxActive > 2 {
+		t.Errorf("Expected at most 2 repositories to be active at once, got %d", maxActive)
+	}
+
+	testhelper.AssertLength(t, finished, len(repos))
+
+	// each batch starts only once every repository of the previous batch has finished
+	for i := 2; i < len(repos); i++ {
+		prev := repos[(i/2-1)*2 : i/2*2]
+		for _, repo := range prev {
+			if started[repos[i]] < finished[repo] {
+				t.Errorf("Expected %s to start after %s finished", repos[i], repo)
+			}
+		}
+	}
+
+	// results are reported in selection order, regardless of batching
+	for i, result := range results {
+		testhelper.AssertEqual(t, result.Repo, repos[i])
+		testhelper.AssertEqual(t, result.Failed(), i < 2)
+	}
+}
+
+// TestProcessArguments tests the processArguments function which expands and orders repos
 func TestProcessArguments(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -269,10 +390,9 @@ func TestProcessArguments(t *testing.T) {
 
 			result := processArguments(ctx, tt.args)
 
-			if tt.sortRepos {
-				testhelper.AssertOutput(t, result, tt.want, nil, false)
-			} else if len(result) != len(tt.want) {
-				t.Errorf("Expected %d repos, got %d", len(tt.want), len(result))
+			// Without sorting, repositories keep the order in which they were provided
+			if !slices.Equal(result, tt.want) {
+				t.Errorf("Expected repos %v, got %v", tt.want, result)
 			}
 
 			if backoff := viper.GetDuration(config.WriteBackoff); backoff != tt.wantBackoff {
@@ -384,6 +504,60 @@ func TestDoCurrentDirectoryArgument(t *testing.T) {
 	}
 }
 
+// TestDoEmptySelection tests that Do refuses to proceed when the selection matches no repositories
+func TestDoEmptySelection(t *testing.T) {
+	tests := []struct {
+		name       string
+		repos      []string
+		allowEmpty bool
+		wantErr    bool
+	}{
+		{
+			name:    "unknown label",
+			repos:   []string{"~nonexistent"},
+			wantErr: true,
+		},
+		{
+			name:    "all repositories excluded",
+			repos:   []string{"repo1", "!repo1"},
+			wantErr: true,
+		},
+		{
+			name:       "allow empty selection",
+			repos:      []string{"~nonexistent"},
+			allowEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.AllowEmpty, tt.allowEmpty)
+
+			var callCount int64
+			callFunc := func(_ context.Context, _ output.Channel) error {
+				atomic.AddInt64(&callCount, 1)
+				return nil
+			}
+
+			var buf bytes.Buffer
+			err := Do(fakeCmd(t, ctx, &buf), tt.repos, callFunc)
+
+			testhelper.AssertError(t, err, tt.wantErr)
+			testhelper.AssertEqual(t, atomic.LoadInt64(&callCount), int64(0))
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoRepositories) {
+					t.Errorf("Expected ErrNoRepositories, got %v", err)
+				}
+
+				// The applied filters are echoed back in the error
+				testhelper.AssertContains(t, err.Error(), []string{strings.Join(tt.repos, " ")})
+			}
+		})
+	}
+}
+
 // TestDoVariousModes tests various execution modes of Do
 func TestDoVariousModes(t *testing.T) {
 	tests := []struct {
@@ -460,6 +634,59 @@ func TestDoVariousModes(t *testing.T) {
 	}
 }
 
+// TestDoStartsWorkersInOrder tests that workers acquire the semaphore in selection order, so that an in-order
+// output handler never waits on a repository which is blocked behind a later one holding the semaphore
+func TestDoStartsWorkersInOrder(t *testing.T) {
+	repos := []string{"repo1", "repo2", "repo3", "repo4", "repo5"}
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 1)
+	viper.Set(config.SortRepos, false)
+
+	testhelper.SetupDirs(t, ctx, repos)
+
+	// Each repository writes more output than the channel can buffer
+	noisy := func(_ context.Context, ch output.Channel) error {
+		for i := range 20 {
+			fmt.Fprintf(ch, "%s line %d\n", ch.Name(), i)
+		}
+
+		return nil
+	}
+
+	for range 20 {
+		var buf bytes.Buffer
+		done := make(chan error, 1)
+
+		go func() {
+			done <- Do(fakeCmd(t, ctx, &buf), repos, noisy, output.NativeHandler)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Do deadlocked with limited concurrency")
+		}
+
+		out := buf.String()
+		last := -1
+
+		for _, repo := range repos {
+			idx := strings.Index(out, "------ "+repo+" ------")
+			if idx <= last {
+				t.Fatalf("Expected output for %s in selection order, got:\n%s", repo, out)
+			}
+
+			last = idx
+		}
+	}
+}
+
 // TestDoWithContextCancellation tests that Do handles context cancellation properly
 func TestDoWithContextCancellation(t *testing.T) {
 	ctx := loadFixture(t)
@@ -499,6 +726,48 @@ func TestDoWithContextCancellation(t *testing.T) {
 	testhelper.AssertContains(t, errOutput, []string{"context canceled"})
 }
 
+// TestDoInterruptStopsProcessGroups tests that an interrupt cancels commands running in their own process groups,
+// which don't receive the interrupt from the terminal themselves
+func TestDoInterruptStopsProcessGroups(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupt signals cannot be sent to a process on Windows")
+	}
+
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+	config.Viper(ctx).Set(config.MaxConcurrency, 2)
+
+	// send the interrupt once both commands are running
+	var running sync.WaitGroup
+	running.Add(2)
+
+	go func() {
+		running.Wait()
+
+		if proc, err := os.FindProcess(os.Getpid()); err == nil {
+			proc.Signal(os.Interrupt)
+		}
+	}()
+
+	sleep := ExecGroup("sleep", "30")
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	start := time.Now()
+	err := Do(cmd, []string{"repo1", "repo2"}, func(ctx context.Context, ch output.Channel) error {
+		running.Done()
+		return sleep(ctx, ch)
+	})
+
+	testhelper.AssertError(t, err, true)
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the interrupt to stop the commands, took %s", elapsed)
+	}
+}
+
 // TestRunCallFuncCloning tests the repository cloning path in runCallFunc
 func TestRunCallFuncCloning(t *testing.T) {
 	ctx := loadFixture(t)
@@ -530,6 +799,35 @@ func TestRunCallFuncCloning(t *testing.T) {
 	testhelper.AssertContains(t, errOutput, []string{"ERROR:"})
 }
 
+// TestRunCallFuncPathScopedTarget tests that path-scoped targets run within an existing subdirectory of the repository
+func TestRunCallFuncPathScopedTarget(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"monorepo//services/api"})
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 10)
+	viper.Set(config.SortRepos, false)
+
+	testFunc := func(ctx context.Context, ch output.Channel) error {
+		ch.WriteString("executed in " + utils.RepoPath(ctx, ch.Name()))
+		return nil
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	err := Do(cmd, []string{"monorepo//services/api", "monorepo//services/missing"}, testFunc, output.NativeHandler)
+	if err == nil {
+		t.Fatal("Expected error for missing subdirectory")
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"executed in " + utils.RepoPath(ctx, "monorepo//services/api")})
+	testhelper.AssertContains(t, errBuf.String(), []string{`path "services/missing" not found in repository monorepo`})
+	testhelper.AssertNotContains(t, buf.String(), []string{"Cloning into"})
+}
+
 // TestDoHandlerCancelPropagation verifies that an output handler invoking
 // config.Cancel(cmd.Context()) propagates cancellation to in-flight Funcs.
 // This guards against a regression where the TUI's quit key would only stop
@@ -588,3 +886,12 @@ func TestDoHandlerCancelPropagation(t *testing.T) {
 		t.Errorf("expected both workers to observe cancellation, got %d/2", got)
 	}
 }
+
+// TestSelectRepositoriesRejectsEscapingTarget tests that path-scoped targets may not escape their repository
+func TestSelectRepositoriesRejectsEscapingTarget(t *testing.T) {
+	ctx := loadFixture(t)
+
+	_, err := SelectRepositories(ctx, []string{"monorepo//services/../../other"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"must not contain"})
+}
@@ -0,0 +1,62 @@
+package call
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+// startJitter returns the delay before the Func starts for the repository at the given index of a batch of count
+// repositories, so that API calls to the provider are spread out rather than made by every repository at once. The
+// window is split into one slot per repository in selection order, and each delay is a random offset within its own
+// slot; since repositories are launched in order, this keeps the start times spread across the whole window instead of
+// bunching up behind the longest delay so far. With a nonzero seed the offset of each repository is reproducible.
+func startJitter(ctx context.Context, name string, index, count int) time.Duration {
+	viper := config.Viper(ctx)
+
+	window := viper.GetDuration(config.StartJitter)
+	if window <= 0 || count <= 0 {
+		return 0
+	}
+
+	slot := window / time.Duration(count)
+	delay := slot * time.Duration(index)
+
+	if slot <= 0 {
+		return delay
+	}
+
+	seed := viper.GetUint64(config.JitterSeed)
+	if seed == 0 {
+		return delay + rand.N(slot) //nolint:gosec // spreading out requests, not security sensitive
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+
+	rng := rand.New(rand.NewPCG(seed, hash.Sum64())) //nolint:gosec // reproducible delay, not security sensitive
+
+	return delay + time.Duration(rng.Int64N(int64(slot)))
+}
+
+// waitJitter waits until the given start time, returning early with an error if the context is cancelled. Every start
+// time is measured from the start of the batch, so repositories which wait in turn don't add up their delays.
+func waitJitter(ctx context.Context, until time.Time) error {
+	delay := time.Until(until)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
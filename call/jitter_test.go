@@ -0,0 +1,132 @@
+package call
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestStartJitter(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	// disabled by default
+	testhelper.AssertEqual(t, startJitter(ctx, "repo1", 0, 1), time.Duration(0))
+
+	window := time.Second
+	viper.Set(config.StartJitter, window.String())
+	viper.Set(config.JitterSeed, 42)
+
+	repos := []string{"repo1", "repo2", "repo3", "repo4"}
+	slot := window / time.Duration(len(repos))
+	offsets := make(map[time.Duration]bool)
+
+	for i, repo := range repos {
+		// each repository is given a delay within its own slot of the window, in selection order
+		delay := startJitter(ctx, repo, i, len(repos))
+		if lo := slot * time.Duration(i); delay < lo || delay >= lo+slot {
+			t.Errorf("Expected delay of %s within [%s, %s), got %s", repo, lo, lo+slot, delay)
+		}
+
+		// the same seed always gives each repository the same delay
+		testhelper.AssertEqual(t, startJitter(ctx, repo, i, len(repos)), delay)
+
+		offsets[delay%slot] = true
+	}
+
+	if len(offsets) < 2 {
+		t.Errorf("Expected repositories to be given different offsets, got %v", offsets)
+	}
+}
+
+func TestDoStaggersStartTimes(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3", "repo4", "repo5"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	window := 300 * time.Millisecond
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, len(repos))
+	viper.Set(config.StartJitter, window.String())
+	viper.Set(config.JitterSeed, 7)
+
+	var (
+		mu     sync.Mutex
+		starts = make(map[string]time.Duration)
+	)
+
+	var buf bytes.Buffer
+	begin := time.Now()
+
+	err := Do(fakeCmd(t, ctx, &buf), repos, func(_ context.Context, ch output.Channel) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		starts[ch.Name()] = time.Since(begin)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// allow for scheduling delays beyond the window, but not for the repositories running one after another
+	const slack = 250 * time.Millisecond
+
+	for i, repo := range repos {
+		start, delay := starts[repo], startJitter(ctx, repo, i, len(repos))
+
+		if start < delay || start > window+slack {
+			t.Errorf("Expected %s to start after its delay of %s and within the window of %s, started at %s", repo, delay, window, start)
+		}
+
+		// repositories start in selection order, each after the end of the previous repository's slot
+		if lo := window / time.Duration(len(repos)) * time.Duration(i); start < lo {
+			t.Errorf("Expected %s to start no earlier than its slot at %s, started at %s", repo, lo, start)
+		}
+	}
+
+	// the start times are spread across most of the window rather than bunched up at one end
+	if spread := starts[repos[len(repos)-1]] - starts[repos[0]]; spread < window/2 {
+		t.Errorf("Expected start times to spread across the window of %s, spread over %s: %v", window, spread, starts)
+	}
+}
+
+func TestDoJitterDoesNotAccumulate(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3", "repo4", "repo5"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	window := 300 * time.Millisecond
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.StartJitter, window.String())
+	viper.Set(config.JitterSeed, 7)
+
+	var total time.Duration
+	for i, repo := range repos {
+		total += startJitter(ctx, repo, i, len(repos))
+	}
+
+	var buf bytes.Buffer
+	begin := time.Now()
+
+	if err := Do(fakeCmd(t, ctx, &buf), repos, func(context.Context, output.Channel) error { return nil }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the jitter is waited before acquiring the semaphore, so running one repository at a time doesn't add up the delays
+	const slack = 250 * time.Millisecond
+
+	if elapsed := time.Since(begin); elapsed > window+slack {
+		t.Errorf("Expected the run to finish within the jitter window of %s, took %s (delays add up to %s)", window, elapsed, total)
+	}
+}
@@ -0,0 +1,75 @@
+package call
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// printSelectionFlag prints the selected repositories instead of running the command.
+const printSelectionFlag = "print-selection"
+
+// PrintsSelection adds the --print-selection flag to the command and its subcommands, which prints the repositories
+// selected by the arguments and exits without running the command. This allows the targets of a set of filters to be
+// verified before acting on them.
+func PrintsSelection(cmd *cobra.Command) *cobra.Command {
+	cmd.PersistentFlags().Bool(printSelectionFlag, false, "print the repositories selected by the arguments and exit without running the command")
+
+	wrapPrintSelection(cmd)
+
+	return cmd
+}
+
+// wrapPrintSelection replaces the run function of the command and its subcommands with one which prints the
+// selection instead when the --print-selection flag is set. The pre-run validation of the command is skipped as
+// well, so that the selection can be printed without the flags needed to run it.
+func wrapPrintSelection(cmd *cobra.Command) {
+	if preRun := cmd.PreRunE; preRun != nil {
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			if ok, err := cmd.Flags().GetBool(printSelectionFlag); err != nil || ok {
+				return err
+			}
+
+			return preRun(cmd, args)
+		}
+	}
+
+	if preRun := cmd.PreRun; preRun != nil {
+		cmd.PreRun = func(cmd *cobra.Command, args []string) {
+			if ok, _ := cmd.Flags().GetBool(printSelectionFlag); !ok {
+				preRun(cmd, args)
+			}
+		}
+	}
+
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if ok, err := cmd.Flags().GetBool(printSelectionFlag); err != nil {
+				return err
+			} else if ok {
+				return PrintSelection(cmd, args)
+			}
+
+			return run(cmd, args)
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		wrapPrintSelection(sub)
+	}
+}
+
+// PrintSelection prints the repositories which the arguments resolve to, one per line and in the order they
+// would be processed, after applying any labels, exclusions, and forced inclusions.
+func PrintSelection(cmd *cobra.Command, args []string) error {
+	repos, err := SelectRepositories(cmd.Context(), args)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		fmt.Fprintln(cmd.OutOrStdout(), repo)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package call
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestPrintsSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "labels with exclusions", args: []string{"~backend", "!repo2"}},
+		{name: "unwanted repositories are skipped", args: []string{"~backend", "~unwanted"}},
+		{name: "forced inclusion of unwanted repositories", args: []string{"~backend", "+~unwanted"}},
+		{name: "empty selection", args: []string{"~nonexistent"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			viper := config.Viper(ctx)
+			viper.Set(config.UnwantedLabels, []string{"unwanted"})
+			viper.Set(config.SkipUnwanted, true)
+			viper.Set(config.SortRepos, true)
+
+			labels := catalog.Labels
+			t.Cleanup(func() { catalog.Labels = labels })
+
+			catalog.Labels = map[string]mapset.Set[string]{
+				"backend":  mapset.NewSet("repo1", "repo2", "repo3"),
+				"unwanted": mapset.NewSet("repo4"),
+			}
+
+			var ran bool
+
+			parent := &cobra.Command{Use: "parent"}
+			parent.AddCommand(&cobra.Command{
+				Use: "child",
+				RunE: func(_ *cobra.Command, _ []string) error {
+					ran = true
+					return nil
+				},
+			})
+			PrintsSelection(parent)
+
+			var buf bytes.Buffer
+			parent.SetOut(&buf)
+			parent.SetErr(&buf)
+			parent.SetArgs(append([]string{"child", "--print-selection"}, tt.args...))
+
+			err := parent.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if ran {
+				t.Error("Expected the command not to run when printing the selection")
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoRepositories) {
+					t.Errorf("Expected ErrNoRepositories, got %v", err)
+				}
+
+				return
+			}
+
+			want := catalog.RepositoryList(ctx, tt.args...).ToSlice()
+			got := strings.Fields(buf.String())
+
+			testhelper.AssertLength(t, got, len(want))
+			testhelper.AssertContains(t, buf.String(), want)
+		})
+	}
+}
+
+func TestPrintsSelectionRunsWithoutFlag(t *testing.T) {
+	ctx := loadFixture(t)
+
+	var ran bool
+
+	cmd := PrintsSelection(&cobra.Command{
+		Use: "test",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ran = true
+			return nil
+		},
+	})
+	cmd.SetArgs([]string{"repo1"})
+
+	testhelper.AssertError(t, cmd.ExecuteContext(ctx), false)
+
+	if !ran {
+		t.Error("Expected the command to run without --print-selection")
+	}
+}
@@ -0,0 +1,119 @@
+package call
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+)
+
+// summaryStderr is the --summary-json path which writes the summary to stderr instead of a file, keeping it apart
+// from the output of the run on stdout.
+const summaryStderr = "-"
+
+// status of a repository in the run summary
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+	statusSkipped   = "skipped"
+)
+
+// RunSummary is the machine-readable summary of a completed run, for assertions in CI.
+type RunSummary struct {
+	Command   string             `json:"command"`
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Skipped   int                `json:"skipped"`
+	Duration  float64            `json:"duration_seconds"`
+	Results   []RunSummaryResult `json:"results"`
+}
+
+// RunSummaryResult is the outcome of a run for a single repository. A repository which was skipped after an error
+// is reported as failed.
+type RunSummaryResult struct {
+	Repo        string  `json:"repo"`
+	Status      string  `json:"status"`
+	Duration    float64 `json:"duration_seconds"`
+	ExitCode    int     `json:"exit_code,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	SkipReason  string  `json:"skip_reason,omitempty"`
+	PullRequest int     `json:"pull_request,omitempty"`
+}
+
+// buildSummary summarizes the results of a run which took the given time.
+func buildSummary(cmd *cobra.Command, results []output.Result, elapsed time.Duration) RunSummary {
+	summary := RunSummary{
+		Command:  cmd.CommandPath(),
+		Total:    len(results),
+		Duration: seconds(elapsed),
+		Results:  make([]RunSummaryResult, len(results)),
+	}
+
+	for i, result := range results {
+		entry := RunSummaryResult{
+			Repo:       result.Repo,
+			Status:     statusSucceeded,
+			Duration:   seconds(result.Duration),
+			ExitCode:   result.ExitCode,
+			SkipReason: result.Skipped,
+		}
+
+		switch {
+		case result.Failed():
+			entry.Status = statusFailed
+			entry.Error = result.Err.Error()
+			summary.Failed++
+		case result.Skipped != "":
+			entry.Status = statusSkipped
+			summary.Skipped++
+		default:
+			summary.Succeeded++
+		}
+
+		if result.PullRequest != nil {
+			entry.PullRequest = result.PullRequest.Number
+		}
+
+		summary.Results[i] = entry
+	}
+
+	return summary
+}
+
+// seconds converts the duration to seconds, rounded to the millisecond.
+func seconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+// writeSummary writes the JSON summary of the completed run to the configured path, or to stderr if the path is "-".
+// Nothing is written if no path is configured.
+func writeSummary(cmd *cobra.Command, results []output.Result, elapsed time.Duration) error {
+	path := config.Viper(cmd.Context()).GetString(config.SummaryJSON)
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(buildSummary(cmd, results, elapsed), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+
+	if path == summaryStderr {
+		_, err = cmd.ErrOrStderr().Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
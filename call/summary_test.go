@@ -0,0 +1,120 @@
+package call
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestDoWritesSummaryJSON(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	path := filepath.Join(t.TempDir(), "ci", "summary.json")
+	config.Viper(ctx).Set(config.SummaryJSON, path)
+
+	var buf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+
+	// a mixed run: one pull request, one failed command, and one skipped repository
+	Do(cmd, repos, func(ctx context.Context, ch output.Channel) error {
+		switch ch.Name() {
+		case "repo1":
+			ch.SetPullRequest(&scm.PullRequest{Number: 7})
+		case "repo2":
+			return exec.CommandContext(ctx, "sh", "-c", "exit 3").Run()
+		case "repo3":
+			ch.Skip("nothing to do")
+		}
+
+		return nil
+	})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+
+	var summary RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Failed to parse summary %q: %v", data, err)
+	}
+
+	testhelper.AssertEqual(t, summary.Command, cmd.CommandPath())
+	testhelper.AssertEqual(t, summary.Total, 3)
+	testhelper.AssertEqual(t, summary.Succeeded, 1)
+	testhelper.AssertEqual(t, summary.Failed, 1)
+	testhelper.AssertEqual(t, summary.Skipped, 1)
+	testhelper.AssertLength(t, summary.Results, 3)
+
+	if summary.Duration < 0 {
+		t.Errorf("Expected a non-negative duration, got %v", summary.Duration)
+	}
+
+	// durations vary between runs, so only check that each was recorded before comparing the rest
+	for i := range summary.Results {
+		if summary.Results[i].Duration < 0 {
+			t.Errorf("Expected a non-negative duration for %s, got %v", summary.Results[i].Repo, summary.Results[i].Duration)
+		}
+
+		summary.Results[i].Duration = 0
+	}
+
+	want := []RunSummaryResult{
+		{Repo: "repo1", Status: "succeeded", PullRequest: 7},
+		{Repo: "repo2", Status: "failed", ExitCode: 3, Error: "exit status 3"},
+		{Repo: "repo3", Status: "skipped", SkipReason: "nothing to do"},
+	}
+	if !reflect.DeepEqual(summary.Results, want) {
+		t.Errorf("Expected results %+v, got %+v", want, summary.Results)
+	}
+}
+
+func TestWriteSummaryStderr(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.SummaryJSON, "-")
+
+	var stdout, buf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &stdout)
+	cmd.SetErr(&buf)
+
+	results := []output.Result{{Repo: "repo1", Duration: 1500 * time.Millisecond}}
+	if err := writeSummary(cmd, results, 2*time.Second); err != nil {
+		t.Fatalf("Failed to write summary: %v", err)
+	}
+
+	var summary RunSummary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to parse summary %q: %v", buf.String(), err)
+	}
+
+	testhelper.AssertEqual(t, summary.Succeeded, 1)
+	testhelper.AssertEqual(t, summary.Duration, 2.0)
+	testhelper.AssertEqual(t, summary.Results[0].Duration, 1.5)
+
+	// the summary is kept apart from the output of the run
+	testhelper.AssertEqual(t, stdout.String(), "")
+}
+
+func TestWriteSummaryNotConfigured(t *testing.T) {
+	ctx := loadFixture(t)
+
+	var buf bytes.Buffer
+	if err := writeSummary(fakeCmd(t, ctx, &buf), []output.Result{{Repo: "repo1"}}, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, buf.String(), "")
+}
@@ -0,0 +1,163 @@
+package call
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+// Watch runs the provided function once, then again each time a file beneath one of the given paths is
+// modified, until the command context is cancelled or the user interrupts the process. Bursts of file
+// events are coalesced into a single run once no further events have arrived within the configured
+// debounce interval. Errors from individual runs are reported but do not stop the watch.
+func Watch(cmd *cobra.Command, paths []string, run func() error) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, path := range paths {
+		if err := watchPath(watcher, path); err != nil {
+			return err
+		}
+	}
+
+	// Don't block on user input between runs
+	config.Viper(ctx).Set(config.WaitOnExit, false)
+
+	trigger := func() {
+		// Do cancels the command context when it returns, so each run starts from the watch context
+		cmd.SetContext(ctx)
+
+		if err := run(); err != nil {
+			fmt.Fprintln(cmd.ErrOrStderr(), err)
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Watching %s for changes (press Ctrl+C to stop)...\n", strings.Join(paths, ", "))
+	}
+
+	trigger()
+
+	debounce(ctx, watchEvents(ctx, cmd, watcher), config.Viper(ctx).GetDuration(config.ExecWatchDebounce), trigger)
+
+	return nil
+}
+
+// watchPath adds the given path to the watcher, including all subdirectories (excluding .git) when it is a directory.
+func watchPath(watcher *fsnotify.Watcher, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to watch %q: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		return watcher.Add(path)
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if err := watcher.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", p, err)
+		}
+
+		return nil
+	})
+}
+
+// watchEvents forwards relevant file events from the watcher until the context is cancelled. Watcher
+// errors are reported to the command's error output. Newly created directories are watched as well.
+func watchEvents(ctx context.Context, cmd *cobra.Command, watcher *fsnotify.Watcher) <-chan struct{} {
+	events := make(chan struct{})
+
+	go func() {
+		defer close(events)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// Ignore attribute-only changes (e.g. chmod)
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						_ = watchPath(watcher, event.Name)
+					}
+				}
+
+				select {
+				case events <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: file watcher error: %v\n", err)
+			}
+		}
+	}()
+
+	return events
+}
+
+// debounce calls trigger once no further events have arrived within the given delay of the most recent event.
+// It returns when the context is cancelled or the events channel is closed.
+func debounce(ctx context.Context, events <-chan struct{}, delay time.Duration, trigger func()) {
+	timer := time.NewTimer(delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+
+			timer.Reset(delay)
+
+		case <-timer.C:
+			trigger()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package call
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// runDebounce runs debounce in the background and returns a channel which is closed when it returns.
+func runDebounce(ctx context.Context, events <-chan struct{}, delay time.Duration, trigger func()) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		debounce(ctx, events, delay, trigger)
+	}()
+
+	return done
+}
+
+func TestDebounceCoalescesBurst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan struct{})
+
+	var count atomic.Int32
+	done := runDebounce(ctx, events, 50*time.Millisecond, func() { count.Add(1) })
+
+	for range 5 {
+		events <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	testhelper.AssertEqual(t, count.Load(), int32(1))
+
+	cancel()
+	<-done
+}
+
+func TestDebounceSeparateBursts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan struct{})
+
+	var count atomic.Int32
+	done := runDebounce(ctx, events, 20*time.Millisecond, func() { count.Add(1) })
+
+	events <- struct{}{}
+	time.Sleep(100 * time.Millisecond)
+
+	events <- struct{}{}
+	events <- struct{}{}
+	time.Sleep(100 * time.Millisecond)
+
+	testhelper.AssertEqual(t, count.Load(), int32(2))
+
+	cancel()
+	<-done
+}
+
+func TestDebounceStops(t *testing.T) {
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		events := make(chan struct{})
+
+		var count atomic.Int32
+		done := runDebounce(ctx, events, 50*time.Millisecond, func() { count.Add(1) })
+
+		// A pending trigger is dropped when the context is cancelled
+		events <- struct{}{}
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("debounce did not return after context cancellation")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		testhelper.AssertEqual(t, count.Load(), int32(0))
+	})
+
+	t.Run("events closed", func(t *testing.T) {
+		events := make(chan struct{})
+		done := runDebounce(context.Background(), events, 50*time.Millisecond, func() {})
+
+		close(events)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("debounce did not return after events channel was closed")
+		}
+	})
+}
+
+func TestWatchRerunsOnChange(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.ExecWatchDebounce, "20ms")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "change.txt")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var buf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+
+	runs := make(chan int, 10)
+
+	var count int
+	run := func() error {
+		count++
+		runs <- count
+
+		if count >= 2 {
+			cancel()
+		}
+
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- Watch(cmd, []string{dir}, run) }()
+
+	// The first run happens immediately, before any changes
+	select {
+	case n := <-runs:
+		testhelper.AssertEqual(t, n, 1)
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not perform the initial run")
+	}
+
+	if err := os.WriteFile(file, []byte("changed"), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	select {
+	case n := <-runs:
+		testhelper.AssertEqual(t, n, 2)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Watch did not re-run after a file change")
+	}
+
+	select {
+	case err := <-done:
+		testhelper.AssertError(t, err, false)
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after cancellation")
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"Watching " + dir + " for changes"})
+	testhelper.AssertEqual(t, config.Viper(ctx).GetBool(config.WaitOnExit), false)
+}
+
+func TestWatchMissingPath(t *testing.T) {
+	ctx := loadFixture(t)
+
+	var buf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+
+	var ran bool
+	err := Watch(cmd, []string{filepath.Join(t.TempDir(), "missing")}, func() error {
+		ran = true
+		return nil
+	})
+
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertEqual(t, ran, false)
+}
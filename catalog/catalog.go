@@ -1,12 +1,19 @@
 package catalog
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -27,18 +34,33 @@ var Catalog = make(map[string]scm.Repository)
 // Labels contains a mapping of label names with the set of repositories matching each label
 var Labels = make(map[string]mapset.Set[string])
 
+// mu guards the Catalog and Labels mappings within this package, since they may be loaded and read by several
+// commands running concurrently in the same process (e.g. from a batch file). The mappings must not be modified
+// outside this package once the catalog has been initialized.
+var mu sync.RWMutex
+
 // Init initializes the repository catalog and label mappings, updating the cache if necessary (based on configured TTL).
 func Init(ctx context.Context, flush bool) {
-	viper := config.Viper(ctx)
-
 	// Register catalog lookup functions for utils package
 	utils.CatalogProjectLookup = GetProjectForRepo
 	utils.CatalogBranchLookup = GetBranchForRepo
+	utils.CatalogURLLookup = GetURLForRepo
 
 	if err := initRepositoryCatalog(ctx, flush); err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: Could not load repository metadata: %v\n", err)
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
+	initLabels(ctx)
+}
+
+// initLabels adds the locally-configured aliases, monorepo paths, and the superset label to the label mapping.
+// The caller must hold mu.
+func initLabels(ctx context.Context) {
+	viper := config.Viper(ctx)
+
 	// Add locally-configured aliases to the defined labels
 	for name, repos := range viper.GetStringMapStringSlice(config.RepoAliases) {
 		if _, ok := Labels[name]; !ok {
@@ -48,6 +70,20 @@ func Init(ctx context.Context, flush bool) {
 		}
 	}
 
+	// Add a label for each monorepo which matches the path-scoped targets for its configured subdirectories
+	for name, paths := range viper.GetStringMapStringSlice(config.RepoPaths) {
+		targets := make([]string, 0, len(paths))
+		for _, path := range paths {
+			targets = append(targets, name+utils.PathSeparator+strings.Trim(path, "/"))
+		}
+
+		if _, ok := Labels[name]; !ok {
+			Labels[name] = mapset.NewSet(targets...)
+		} else {
+			Labels[name].Append(targets...)
+		}
+	}
+
 	// Add superset label which matches all repositories in the catalog
 	Labels[viper.GetString(config.SuperSetLabel)] = mapset.NewSet[string]()
 
@@ -64,6 +100,9 @@ func Init(ctx context.Context, flush bool) {
 func GetRepository(ctx context.Context, repoName string) (*scm.Repository, bool) {
 	viper := config.Viper(ctx)
 
+	mu.RLock()
+	defer mu.RUnlock()
+
 	// Try direct lookup first (project/name format)
 	if repo, exists := Catalog[repoName]; exists {
 		return &repo, true
@@ -103,6 +142,9 @@ func GetProjectForRepo(ctx context.Context, repoName string) string {
 func GetLabelsForRepo(name string) []string {
 	var labels []string
 
+	mu.RLock()
+	defer mu.RUnlock()
+
 	for label, repos := range Labels {
 		if repos.Contains(name) {
 			labels = append(labels, label)
@@ -112,6 +154,34 @@ func GetLabelsForRepo(name string) []string {
 	return labels
 }
 
+// Repositories returns a copy of the repositories in the catalog, keyed by name, which is safe to read while the
+// catalog is refreshed.
+func Repositories() map[string]scm.Repository {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	repos := make(map[string]scm.Repository, len(Catalog))
+	for name, repo := range Catalog {
+		repo.Labels = slices.Clone(repo.Labels)
+		repos[name] = repo
+	}
+
+	return repos
+}
+
+// LabelSets returns a copy of the label mapping, which is safe to read while the catalog is refreshed.
+func LabelSets() map[string]mapset.Set[string] {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	labels := make(map[string]mapset.Set[string], len(Labels))
+	for name, repos := range Labels {
+		labels[name] = repos.Clone()
+	}
+
+	return labels
+}
+
 // GetBranchForRepo returns the default branch for a given repository name.
 // It checks the catalog first, then falls back to the configured default.
 func GetBranchForRepo(ctx context.Context, repoName string) string {
@@ -123,19 +193,68 @@ func GetBranchForRepo(ctx context.Context, repoName string) string {
 	return config.Viper(ctx).GetString(config.DefaultBranch)
 }
 
+// GetURLForRepo returns the SSH or HTTPS clone URL reported by the SCM provider for the given repository,
+// or an empty string if it is unknown.
+func GetURLForRepo(ctx context.Context, repoName string, ssh bool) string {
+	repo, exists := GetRepository(ctx, repoName)
+	if !exists {
+		return ""
+	}
+
+	if ssh {
+		return repo.SSHURL
+	}
+
+	return repo.CloneURL
+}
+
 // RepositoryList returns the set of repository names matching the given filters.
 func RepositoryList(ctx context.Context, filters ...string) mapset.Set[string] {
 	// Parse filters into include/exclude/forced sets for set-theory operations
 	includeSet, excludeSet, forcedSet := parseLabelFilters(ctx, filters...)
 
-	// Final set is (forced ∪ (include \ exclude)) - forced repos and matched repos which aren't excluded
+	// Final set is (forced ∪ (include \ exclude)) - forced repos and matched repos which aren't excluded,
+	// where include has already been narrowed by any intersections
 	return forcedSet.Union(includeSet.Difference(excludeSet))
 }
 
+// RepositoryNames returns the repository names matching the given filters as an ordered slice. If SortRepos
+// is enabled the names are sorted alphabetically, otherwise they keep the order in which the filters selected
+// them, with the repositories matched by each label in alphabetical order.
+func RepositoryNames(ctx context.Context, filters ...string) []string {
+	selected := RepositoryList(ctx, filters...)
+
+	if config.Viper(ctx).GetBool(config.SortRepos) {
+		names := selected.ToSlice()
+		sort.Strings(names)
+
+		return names
+	}
+
+	names := make([]string, 0, selected.Cardinality())
+
+	for _, filter := range filters {
+		repos, _ := filterRepos(ctx, filter)
+
+		for _, name := range repos {
+			// each name is only listed at the position of the first filter that selected it
+			if selected.Contains(name) {
+				names = append(names, name)
+				selected.Remove(name)
+			}
+		}
+	}
+
+	return names
+}
+
 func parseLabelFilters(ctx context.Context, filters ...string) (include, exclude, forced mapset.Set[string]) {
 	viper := config.Viper(ctx)
 	include, exclude, forced = mapset.NewSet[string](), mapset.NewSet[string](), mapset.NewSet[string]()
 
+	var intersections []mapset.Set[string]
+	included := false
+
 	for _, filter := range filters {
 		switch {
 		case strings.Contains(filter, viper.GetString(config.TokenSkip)):
@@ -144,47 +263,182 @@ func parseLabelFilters(ctx context.Context, filters ...string) (include, exclude
 		case strings.Contains(filter, viper.GetString(config.TokenForced)):
 			addFilterToSet(ctx, filter, forced)
 
+		case strings.Contains(filter, viper.GetString(config.TokenIntersect)):
+			set := mapset.NewSet[string]()
+			addFilterToSet(ctx, filter, set)
+			intersections = append(intersections, set)
+
 		default:
 			addFilterToSet(ctx, filter, include)
+			included = true
 		}
 	}
 
-	// If configured to skip unwanted labels, expand each label to its repos and exclude them
+	// Intersections narrow the included repos before exclusions and forced repos are applied. If nothing else
+	// is included, the repos in every intersected label are included.
+	for i, set := range intersections {
+		if i == 0 && !included {
+			include = set
+		} else {
+			include = include.Intersect(set)
+		}
+	}
+
+	// Exclude repos matched by unwanted labels or archived, as configured
+	for _, label := range unknownUnwantedLabels(ctx) {
+		fmt.Fprintf(os.Stderr, "WARNING: Label '%s' not recognized\n", label)
+	}
+
+	exclude = exclude.Union(UnwantedRepos(ctx))
+
+	return include, exclude, forced
+}
+
+// WantedRepos returns the set of catalog repositories which are selected by default, i.e. all
+// repositories in the catalog except those returned by [UnwantedRepos].
+func WantedRepos(ctx context.Context) mapset.Set[string] {
+	mu.RLock()
+	wanted := mapset.NewSetWithSize[string](len(Catalog))
+	for name := range Catalog {
+		wanted.Add(name)
+	}
+	mu.RUnlock()
+
+	return wanted.Difference(UnwantedRepos(ctx))
+}
+
+// UnwantedRepos returns the set of repositories which are excluded by default. This includes
+// repositories matched by the configured unwanted labels (if SkipUnwanted is enabled) and
+// archived repositories (if SkipArchived is enabled).
+func UnwantedRepos(ctx context.Context) mapset.Set[string] {
+	viper := config.Viper(ctx)
+	unwanted := mapset.NewSet[string]()
+
+	mu.RLock()
+	defer mu.RUnlock()
+
 	if viper.GetBool(config.SkipUnwanted) {
 		for _, label := range viper.GetStringSlice(config.UnwantedLabels) {
-			addFilterToSet(ctx, viper.GetString(config.TokenLabel)+label, exclude)
+			if set, ok := matchLabels(label); ok {
+				unwanted.Append(set.ToSlice()...)
+			}
 		}
 	}
 
-	// If configured to skip archived repos, add them to the exclude set
 	if viper.GetBool(config.SkipArchived) {
-		exclude.Append(archivedRepos()...)
+		unwanted.Append(archivedRepos()...)
 	}
 
-	return include, exclude, forced
+	return unwanted
+}
+
+// unknownUnwantedLabels returns the configured unwanted labels which don't match any label in the catalog, if they
+// are skipped. These are reported when selecting repositories, rather than by [UnwantedRepos], which is also used to
+// count the repositories for display.
+func unknownUnwantedLabels(ctx context.Context) []string {
+	viper := config.Viper(ctx)
+	if !viper.GetBool(config.SkipUnwanted) {
+		return nil
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var unknown []string
+	for _, label := range viper.GetStringSlice(config.UnwantedLabels) {
+		if _, ok := matchLabels(label); !ok {
+			unknown = append(unknown, label)
+		}
+	}
+
+	return unknown
 }
 
 func addFilterToSet(ctx context.Context, filter string, set mapset.Set[string]) {
-	replacer := strings.NewReplacer(
-		config.Viper(ctx).GetString(config.TokenLabel), "",
-		config.Viper(ctx).GetString(config.TokenSkip), "",
-		config.Viper(ctx).GetString(config.TokenForced), "",
-	)
-	filterName := replacer.Replace(filter)
-
-	if strings.Contains(filter, config.Viper(ctx).GetString(config.TokenLabel)) {
-		// if it's a label filter, add all repos matching that label to the set
-		if labelSet, ok := Labels[filterName]; ok {
-			set.Append(labelSet.ToSlice()...)
-		} else {
-			fmt.Fprintf(os.Stderr, "WARNING: Label '%s' not recognized\n", filterName)
+	repos, ok := filterRepos(ctx, filter)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "WARNING: Label '%s' not recognized\n", utils.CleanFilter(ctx, filter))
+	}
+
+	set.Append(repos...)
+}
+
+// filterRepos returns the sorted repository names matched by a single filter, and whether the filter was recognized.
+// A label filter may be a glob (e.g. ~legacy-*), matching the repositories of every label whose name matches it.
+func filterRepos(ctx context.Context, filter string) ([]string, bool) {
+	filterName := utils.CleanFilter(ctx, filter)
+
+	if !isLabelFilter(ctx, filter) {
+		// if it's a repo filter, the repo name is matched directly
+		return []string{filterName}, true
+	}
+
+	// if it's a label filter, all repos matching that label are matched
+	mu.RLock()
+	labelSet, ok := matchLabels(filterName)
+	mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	repos := labelSet.ToSlice()
+	sort.Strings(repos)
+
+	return repos, true
+}
+
+// isLabelFilter reports whether the filter selects the repositories of a label, rather than a repository by name.
+// Intersected filters (e.g. &go) always name labels.
+func isLabelFilter(ctx context.Context, filter string) bool {
+	viper := config.Viper(ctx)
+
+	return strings.Contains(filter, viper.GetString(config.TokenLabel)) || strings.Contains(filter, viper.GetString(config.TokenIntersect))
+}
+
+// matchLabels returns the repositories of the named label, or the union of the repositories of every label matching
+// the name if it is a glob, and whether any label matched. The caller must hold mu.
+func matchLabels(name string) (mapset.Set[string], bool) {
+	if !isLabelGlob(name) {
+		labelSet, ok := Labels[name]
+		return labelSet, ok
+	}
+
+	matched := MatchLabelNames(name, Labels)
+
+	repos := mapset.NewSet[string]()
+	for _, label := range matched {
+		repos.Append(Labels[label].ToSlice()...)
+	}
+
+	return repos, len(matched) > 0
+}
+
+// MatchLabelNames returns the name of the label, or the sorted names of the given labels matching it if it is a glob
+// (e.g. from [LabelSets]).
+func MatchLabelNames(name string, labels map[string]mapset.Set[string]) []string {
+	if !isLabelGlob(name) {
+		return []string{name}
+	}
+
+	var matched []string
+	for label := range labels {
+		if ok, _ := path.Match(name, label); ok {
+			matched = append(matched, label)
 		}
-	} else {
-		// if it's a repo filter, add the repo name directly to the set
-		set.Add(filterName)
 	}
+
+	slices.Sort(matched)
+
+	return matched
+}
+
+// isLabelGlob reports whether the label name is a glob pattern matching other labels.
+func isLabelGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
 }
 
+// archivedRepos returns the names of the archived repositories in the catalog. The caller must hold mu.
 func archivedRepos() []string {
 	archived := []string{}
 
@@ -198,11 +452,26 @@ func archivedRepos() []string {
 }
 
 func initRepositoryCatalog(ctx context.Context, flush bool) error {
+	mu.RLock()
+	loaded := len(Catalog) > 0
+	mu.RUnlock()
+
 	// If catalog is already loaded and not flushing, skip subsequent initialization
-	if len(Catalog) > 0 && !flush {
+	if loaded && !flush {
 		return nil
 	}
 
+	// Bypass the local cache without flushing it, always fetching fresh data
+	if config.Viper(ctx).GetBool(config.CatalogNoCache) {
+		return fetchRepositoryData(ctx)
+	}
+
+	// Refetch and rewrite the local cache regardless of its TTL
+	if config.Viper(ctx).GetBool(config.CatalogRefresh) {
+		_, err := Refresh(ctx)
+		return err
+	}
+
 	ttl := flushTTL // Use short TTL when flushing to force refetch
 	if !flush {
 		ttl = config.Viper(ctx).GetDuration(config.CatalogCacheTTL)
@@ -221,21 +490,19 @@ func initRepositoryCatalog(ctx context.Context, flush bool) error {
 	return fetchRepositoryData(ctx)
 }
 
+// cacheVersion is the schema version of the catalog cache. It must be bumped whenever repositoryCache (or the
+// cached repository data) changes incompatibly, so that caches written by other versions are refetched.
+const cacheVersion = 1
+
 type repositoryCache struct {
+	Version      int                       `json:"version"`
 	UpdatedAt    time.Time                 `json:"updated_at"`
 	Repositories map[string]scm.Repository `json:"repositories"`
 }
 
 func loadCatalogCache(ctx context.Context, ttl time.Duration) error {
-	file, err := os.Open(catalogCachePath(ctx))
+	cached, err := readCatalogCache(ctx)
 	if err != nil {
-		return fmt.Errorf("local cache of repository catalog is missing or invalid - fetching remote info")
-	}
-
-	defer file.Close()
-
-	var cached repositoryCache
-	if err := json.NewDecoder(file).Decode(&cached); err != nil {
 		return err
 	}
 
@@ -243,77 +510,211 @@ func loadCatalogCache(ctx context.Context, ttl time.Duration) error {
 		return fmt.Errorf("local cache of repository catalog is too old - fetching remote info")
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
 	Catalog = cached.Repositories
 
 	for _, repo := range Catalog {
 		// Use project-qualified name for consistent scoping
-		repoKey := repo.Project + "/" + repo.Name
+		addLabels(repo.Project+"/"+repo.Name, repo.Labels)
+	}
 
-		for _, label := range repo.Labels {
-			if _, ok := Labels[label]; !ok {
-				Labels[label] = mapset.NewSet(repoKey)
-			} else {
-				Labels[label].Add(repoKey)
-			}
+	return nil
+}
+
+// readCatalogCache decodes the local cache of the repository catalog, regardless of its age.
+func readCatalogCache(ctx context.Context) (*repositoryCache, error) {
+	path := catalogCachePath(ctx)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("local cache of repository catalog is missing or invalid - fetching remote info")
+	}
+
+	defer file.Close()
+
+	var reader io.Reader = file
+
+	// Caches with a .gz extension are gzip compressed
+	if isCompressedCache(path) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress catalog cache: %w", err)
 		}
+
+		defer gz.Close()
+
+		reader = gz
 	}
 
-	return nil
+	var cached repositoryCache
+	if err := json.NewDecoder(reader).Decode(&cached); err != nil {
+		return nil, fmt.Errorf("local cache of repository catalog is invalid (%v) - fetching remote info", err)
+	}
+
+	// Caches written before versioning (or by a different version) may not decode correctly, so refetch them
+	if cached.Version != cacheVersion {
+		return nil, fmt.Errorf("local cache of repository catalog has schema version %d (expected %d) - fetching remote info", cached.Version, cacheVersion)
+	}
+
+	return &cached, nil
 }
 
 func saveCatalogCache(ctx context.Context) error {
-	cache := repositoryCache{
+	if config.Viper(ctx).GetBool(config.CatalogNoSave) {
+		return nil
+	}
+
+	mu.RLock()
+	data, err := json.Marshal(&repositoryCache{
+		Version:      cacheVersion,
 		UpdatedAt:    time.Now().UTC(),
 		Repositories: Catalog,
+	})
+	mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	path := catalogCachePath(ctx)
+
+	if isCompressedCache(path) {
+		if data, err = compress(data); err != nil {
+			return fmt.Errorf("failed to compress catalog cache: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
 	}
 
-	data, err := json.Marshal(&cache)
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes the data to a temporary file which then replaces the file at the given path, so that
+// concurrent readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(catalogCachePath(ctx)), 0o750); err != nil {
+	defer os.Remove(tmp.Name()) // no-op once the file has been renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
 		return err
 	}
 
-	return os.WriteFile(catalogCachePath(ctx), data, 0o600)
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// isCompressedCache reports whether the cache at the given path is gzip compressed, based on its extension.
+func isCompressedCache(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gz")
+}
+
+// compress returns the gzip compressed data.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
 func fetchRepositoryData(ctx context.Context) error {
+	repos, err := listRepositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	for repoKey, repo := range repos {
+		addRepository(repoKey, repo)
+	}
+	mu.Unlock()
+
+	return saveCatalogCache(ctx)
+}
+
+// Projects returns the sorted names of all configured projects, including the default project.
+func Projects(ctx context.Context) []string {
 	viper := config.Viper(ctx)
 
-	// Build set of all projects to fetch
 	projects := mapset.NewSet(viper.GetStringSlice(config.GitProjects)...)
 	if defaultProject := viper.GetString(config.GitProject); defaultProject != "" {
 		projects.Add(defaultProject)
 	}
 
+	names := projects.ToSlice()
+	sort.Strings(names)
+
+	return names
+}
+
+// listRepositories fetches all repositories from the configured projects, keyed by project-qualified name.
+func listRepositories(ctx context.Context) (map[string]scm.Repository, error) {
+	viper := config.Viper(ctx)
+	result := make(map[string]scm.Repository)
+
+	names := Projects(ctx)
+
+	progress := NewFetchProgress(ctx)
+	progress.Start(len(names))
+	defer progress.Stop()
+
 	// Fetch repositories from all projects
-	for project := range projects.Iter() {
+	for _, project := range names {
+		progress.Fetching(project)
+
 		provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
 
 		repos, err := provider.ListRepositories()
 		if err != nil {
-			return fmt.Errorf("failed to fetch repositories from project %s: %w", project, err)
+			return nil, fmt.Errorf("failed to fetch repositories from project %s: %w", project, err)
 		}
 
 		for _, repo := range repos {
 			// Always store with project-qualified name for consistency
-			repoKey := repo.Project + "/" + repo.Name
+			result[repo.Project+"/"+repo.Name] = *repo
+		}
+
+		progress.Fetched(project, len(repos))
+	}
 
-			Catalog[repoKey] = *repo
+	return result, nil
+}
 
-			for _, label := range repo.Labels {
-				if _, ok := Labels[label]; !ok {
-					Labels[label] = mapset.NewSet(repoKey)
-				} else {
-					Labels[label].Add(repoKey)
-				}
-			}
+// addRepository adds the repository to the catalog and its labels to the label mapping. The caller must hold mu.
+func addRepository(repoKey string, repo scm.Repository) {
+	Catalog[repoKey] = repo
+
+	addLabels(repoKey, repo.Labels)
+}
+
+// addLabels adds the repository to the label mapping for each of the given labels. The caller must hold mu.
+func addLabels(repoKey string, labels []string) {
+	for _, label := range labels {
+		if _, ok := Labels[label]; !ok {
+			Labels[label] = mapset.NewSet(repoKey)
+		} else {
+			Labels[label].Add(repoKey)
 		}
 	}
-
-	return saveCatalogCache(ctx)
 }
 
 func catalogCachePath(ctx context.Context) string {
@@ -324,6 +725,24 @@ func catalogCachePath(ctx context.Context) string {
 		return customPath
 	}
 
-	// Default: store in gitdir/host/.batch-tool-cache.json
-	return filepath.Join(viper.GetString(config.GitDirectory), viper.GetString(config.GitHost), defaultCacheFile)
+	name := defaultCacheFile
+	if viper.GetBool(config.CatalogCacheCompress) {
+		name += ".gz"
+	}
+
+	// Default: store in gitdir/host/.batch-tool-cache.json. Other locations may be shared between hosts (the configured
+	// cache directory, or the git directory itself for layouts without host directories), so the file name includes the
+	// host to keep their caches separate.
+	host := strings.NewReplacer("/", "_", ":", "_").Replace(viper.GetString(config.GitHost))
+	hostName := strings.Replace(name, ".json", "."+host+".json", 1)
+
+	if dir := viper.GetString(config.CatalogCacheDir); dir != "" {
+		return filepath.Join(dir, hostName)
+	}
+
+	if layout := viper.GetString(config.GitLayout); layout == config.GitLayoutProject || layout == config.GitLayoutFlat {
+		return filepath.Join(viper.GetString(config.GitDirectory), hostName)
+	}
+
+	return filepath.Join(viper.GetString(config.GitDirectory), viper.GetString(config.GitHost), name)
 }
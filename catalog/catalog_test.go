@@ -3,8 +3,14 @@ package catalog
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -92,6 +98,42 @@ func TestInit(t *testing.T) {
 	}
 }
 
+// TestInitTopicLabels tests that repository topics reported as labels are merged with the configured aliases
+func TestInitTopicLabels(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+	t.Cleanup(func() { cleanupCache(t, ctx) })
+
+	config.Viper(ctx).Set(config.RepoAliases, map[string][]string{"backend": {"test-project/other-repo"}})
+
+	setupCacheFile(t, ctx, map[string]scm.Repository{
+		"test-project/topic-repo": {
+			Name:          "topic-repo",
+			Project:       "test-project",
+			DefaultBranch: "main",
+			Labels:        []string{"backend", "go"},
+		},
+	}, time.Now())
+
+	Init(ctx, false)
+
+	for label, want := range map[string]string{
+		"backend": "test-project/other-repo,test-project/topic-repo",
+		"go":      "test-project/topic-repo",
+	} {
+		set, ok := Labels[label]
+		if !ok {
+			t.Fatalf("Expected label %q to exist", label)
+		}
+
+		repos := set.ToSlice()
+		slices.Sort(repos)
+		testhelper.AssertEqual(t, strings.Join(repos, ","), want)
+	}
+
+	testhelper.AssertContains(t, strings.Join(GetLabelsForRepo("test-project/topic-repo"), ","), []string{"backend", "go"})
+}
+
 // TestInitRepositoryCatalog tests the internal initialization logic
 func TestInitRepositoryCatalog(t *testing.T) {
 	tests := []struct {
@@ -154,6 +196,165 @@ func TestInitRepositoryCatalog(t *testing.T) {
 	}
 }
 
+func TestInitRepositoryCatalogNoCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		noSave     bool
+		wantCached string // repository expected in the cache file after init
+	}{
+		{name: "fetch and save", wantCached: "test-project/live-repo"},
+		{name: "fetch without save", noSave: true, wantCached: "test-project/cached-repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			viper := config.Viper(ctx)
+			resetCatalogState(t)
+			t.Cleanup(func() { cleanupCache(t, ctx) })
+
+			providerName := "fake-no-cache-" + t.Name()
+			viper.Set(config.GitProvider, providerName)
+			viper.Set(config.GitProject, "test-project")
+			viper.Set(config.CatalogNoCache, true)
+			viper.Set(config.CatalogNoSave, tt.noSave)
+
+			testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", []*scm.Repository{
+				{Name: "live-repo", Project: "test-project"},
+			})
+
+			// A valid, unexpired cache is present but must be bypassed
+			cachePath := setupCacheFile(t, ctx, map[string]scm.Repository{
+				"test-project/cached-repo": {Name: "cached-repo", Project: "test-project"},
+			}, time.Now())
+
+			testhelper.AssertError(t, initRepositoryCatalog(ctx, false), false)
+
+			if _, ok := Catalog["test-project/live-repo"]; !ok {
+				t.Errorf("Expected catalog to be fetched from provider, got %v", Catalog)
+			}
+			if _, ok := Catalog["test-project/cached-repo"]; ok {
+				t.Error("Expected cached repository to be ignored")
+			}
+
+			// The existing cache is never deleted, and only overwritten when saving is enabled
+			data, err := os.ReadFile(cachePath)
+			if err != nil {
+				t.Fatalf("Expected cache file to remain: %v", err)
+			}
+			if !strings.Contains(string(data), tt.wantCached) {
+				t.Errorf("Expected cache file to contain %q, got %s", tt.wantCached, data)
+			}
+		})
+	}
+}
+
+// TestInitRepositoryCatalogNoSaveUnwritableCache tests that fetching succeeds with --no-save when the cache can't be written
+func TestInitRepositoryCatalogNoSaveUnwritableCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		cachePath func(t *testing.T) string
+	}{
+		{
+			name: "read-only directory",
+			cachePath: func(t *testing.T) string {
+				if os.Getuid() == 0 {
+					t.Skip("Cannot test permission errors as root")
+				}
+
+				dir := filepath.Join(t.TempDir(), "readonly")
+				if err := os.Mkdir(dir, 0o555); err != nil {
+					t.Fatalf("Failed to create cache directory: %v", err)
+				}
+				t.Cleanup(func() { os.Chmod(dir, 0o755) })
+
+				return filepath.Join(dir, "cache.json")
+			},
+		},
+		{
+			name: "directory blocked by a file",
+			cachePath: func(t *testing.T) string {
+				file := filepath.Join(t.TempDir(), "not-a-directory")
+				if err := os.WriteFile(file, nil, 0o600); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+
+				return filepath.Join(file, "cache.json")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, noSave := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/no-save=%v", tt.name, noSave), func(t *testing.T) {
+				ctx := loadFixture(t)
+				viper := config.Viper(ctx)
+				resetCatalogState(t)
+
+				providerName := "fake-no-save-" + t.Name()
+				viper.Set(config.GitProvider, providerName)
+				viper.Set(config.GitProject, "test-project")
+				viper.Set(config.CatalogCachePath, tt.cachePath(t))
+				viper.Set(config.CatalogNoSave, noSave)
+
+				testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", []*scm.Repository{
+					{Name: "live-repo", Project: "test-project"},
+				})
+
+				// the fetch only fails when the unwritable cache is saved
+				testhelper.AssertError(t, initRepositoryCatalog(ctx, false), !noSave)
+
+				if _, ok := Catalog["test-project/live-repo"]; !ok {
+					t.Errorf("Expected catalog to be fetched from provider, got %v", Catalog)
+				}
+			})
+		}
+	}
+}
+
+// TestInitRepositoryCatalogVersionMismatch tests that a cache with a different schema version is refetched
+func TestInitRepositoryCatalogVersionMismatch(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	resetCatalogState(t)
+	t.Cleanup(func() { cleanupCache(t, ctx) })
+
+	providerName := "fake-version-" + t.Name()
+	viper.Set(config.GitProvider, providerName)
+	viper.Set(config.GitProject, "test-project")
+
+	testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", []*scm.Repository{
+		{Name: "live-repo", Project: "test-project"},
+	})
+
+	// A cache which is unexpired, but written with an older schema version
+	cachePath := setupCacheFileVersion(t, ctx, map[string]scm.Repository{
+		"test-project/cached-repo": {Name: "cached-repo", Project: "test-project"},
+	}, time.Now(), cacheVersion-1)
+
+	testhelper.AssertError(t, initRepositoryCatalog(ctx, false), false)
+
+	if _, ok := Catalog["test-project/live-repo"]; !ok {
+		t.Errorf("Expected catalog to be fetched from provider, got %v", Catalog)
+	}
+	if _, ok := Catalog["test-project/cached-repo"]; ok {
+		t.Error("Expected outdated cache to be ignored")
+	}
+
+	// The refetched catalog replaces the outdated cache with the current version
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Failed to read cache file: %v", err)
+	}
+
+	var cache repositoryCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		t.Fatalf("Failed to unmarshal cache: %v", err)
+	}
+
+	testhelper.AssertEqual(t, cache.Version, cacheVersion)
+}
+
 // TestLoadCatalogCache tests loading the catalog from cache
 func TestLoadCatalogCache(t *testing.T) {
 	tests := []struct {
@@ -207,6 +408,26 @@ func TestLoadCatalogCache(t *testing.T) {
 			wantLabelMin:  0,
 			wantError:     true,
 		},
+		{
+			name: "load cache with mismatched version returns error",
+			setupFunc: func(t *testing.T, ctx context.Context) {
+				repos := map[string]scm.Repository{
+					"repo1": {Name: "repo1", Project: "test-project", Labels: []string{"backend"}},
+				}
+				setupCacheFileVersion(t, ctx, repos, time.Now(), cacheVersion+1)
+			},
+			wantError: true,
+		},
+		{
+			name: "load unversioned cache returns error",
+			setupFunc: func(t *testing.T, ctx context.Context) {
+				repos := map[string]scm.Repository{
+					"repo1": {Name: "repo1", Project: "test-project", Labels: []string{"backend"}},
+				}
+				setupCacheFileVersion(t, ctx, repos, time.Now(), 0)
+			},
+			wantError: true,
+		},
 		{
 			name: "load missing cache returns error",
 			setupFunc: func(_ *testing.T, _ context.Context) {
@@ -1005,7 +1226,127 @@ func TestRepositoryListForcedVsExcluded(t *testing.T) {
 	}
 }
 
+func TestRepositoryListWildcardLabels(t *testing.T) {
+	ctx := loadFixture(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantRepos []string
+	}{
+		{
+			name:      "exclude a label family while including a specific set",
+			args:      []string{"~backend", "~frontend", "!~legacy-*"},
+			wantRepos: []string{"api-server", "mobile-app"},
+		},
+		{
+			name:      "include a label family",
+			args:      []string{"~legacy-*"},
+			wantRepos: []string{"old-api", "old-web", "web-app", "worker"},
+		},
+		{
+			name:      "force a label family past an exclusion",
+			args:      []string{"~backend", "!~legacy-*", "+~legacy-a?i"},
+			wantRepos: []string{"api-server", "old-api", "worker"},
+		},
+		{
+			name:      "character class",
+			args:      []string{"~legacy-[w]*"},
+			wantRepos: []string{"old-web", "web-app"},
+		},
+		{
+			name:      "wildcard matching no labels",
+			args:      []string{"~backend", "!~obsolete-*"},
+			wantRepos: []string{"api-server", "worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Labels = map[string]mapset.Set[string]{
+				"frontend":   mapset.NewSet("web-app", "mobile-app"),
+				"backend":    mapset.NewSet("api-server", "worker"),
+				"legacy-api": mapset.NewSet("old-api", "worker"),
+				"legacy-web": mapset.NewSet("old-web", "web-app"),
+			}
+
+			repos := RepositoryList(ctx, tt.args...).ToSlice()
+			sort.Strings(repos)
+
+			testhelper.AssertEqual(t, strings.Join(repos, ","), strings.Join(tt.wantRepos, ","))
+		})
+	}
+}
+
 // TestRepositoryListWithSkipUnwantedAndForced tests forced inclusion with skip-unwanted enabled
+func TestRepositoryListIntersections(t *testing.T) {
+	ctx := loadFixture(t)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantRepos []string
+	}{
+		{
+			name:      "intersection of labels",
+			args:      []string{"&go", "&critical"},
+			wantRepos: []string{"api-server", "billing"},
+		},
+		{
+			name:      "single intersection selects the label",
+			args:      []string{"&go"},
+			wantRepos: []string{"api-server", "billing", "worker"},
+		},
+		{
+			name:      "intersection narrows the included labels",
+			args:      []string{"~backend", "~frontend", "&critical"},
+			wantRepos: []string{"api-server", "billing", "web-app"},
+		},
+		{
+			name:      "intersection narrows repositories selected by name",
+			args:      []string{"worker", "web-app", "&go"},
+			wantRepos: []string{"worker"},
+		},
+		{
+			name:      "exclusions apply after intersections",
+			args:      []string{"&go", "&critical", "!~legacy"},
+			wantRepos: []string{"api-server"},
+		},
+		{
+			name:      "forced repositories are added after intersections",
+			args:      []string{"&go", "&critical", "!billing", "+~frontend"},
+			wantRepos: []string{"api-server", "mobile-app", "web-app"},
+		},
+		{
+			name:      "intersection with an unknown label is empty",
+			args:      []string{"&go", "&unknown"},
+			wantRepos: []string{},
+		},
+		{
+			name:      "glob intersection",
+			args:      []string{"&go", "&crit*"},
+			wantRepos: []string{"api-server", "billing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Labels = map[string]mapset.Set[string]{
+				"go":       mapset.NewSet("api-server", "billing", "worker"),
+				"critical": mapset.NewSet("api-server", "billing", "web-app"),
+				"backend":  mapset.NewSet("api-server", "billing", "worker"),
+				"frontend": mapset.NewSet("web-app", "mobile-app"),
+				"legacy":   mapset.NewSet("billing"),
+			}
+
+			repos := RepositoryList(ctx, tt.args...).ToSlice()
+			slices.Sort(repos)
+
+			testhelper.AssertEqual(t, strings.Join(repos, ","), strings.Join(tt.wantRepos, ","))
+		})
+	}
+}
+
 func TestRepositoryListWithSkipUnwantedAndForced(t *testing.T) {
 	ctx := loadFixture(t)
 
@@ -1093,6 +1434,57 @@ func TestRepositoryListComplexScenario(t *testing.T) {
 }
 
 // TestInitWithFakeProvider tests catalog initialization with a fake SCM provider
+func TestRepositoryNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortRepos bool
+		args      []string
+		want      []string
+	}{
+		{
+			name:      "sorted repositories",
+			sortRepos: true,
+			args:      []string{"worker", "~frontend", "api-server"},
+			want:      []string{"api-server", "mobile-app", "web-app", "worker"},
+		},
+		{
+			name: "unsorted repositories keep filter order",
+			args: []string{"worker", "~frontend", "api-server"},
+			want: []string{"worker", "mobile-app", "web-app", "api-server"},
+		},
+		{
+			name: "unsorted duplicates listed at first selection",
+			args: []string{"web-app", "~all", "~frontend"},
+			want: []string{"web-app", "api-server", "mobile-app", "tools", "worker"},
+		},
+		{
+			name: "unsorted exclusions and forced repositories",
+			args: []string{"~all", "!~backend", "+worker"},
+			want: []string{"mobile-app", "tools", "web-app", "worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.SortRepos, tt.sortRepos)
+
+			Labels = map[string]mapset.Set[string]{
+				"frontend": mapset.NewSet("web-app", "mobile-app"),
+				"backend":  mapset.NewSet("api-server", "worker"),
+				"all":      mapset.NewSet("web-app", "mobile-app", "api-server", "worker", "tools"),
+			}
+
+			// Repeated calls must produce identical results despite map iteration order
+			for range 5 {
+				if got := RepositoryNames(ctx, tt.args...); !slices.Equal(got, tt.want) {
+					t.Fatalf("RepositoryNames() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
 func TestInitWithFakeProvider(t *testing.T) {
 	_ = loadFixture(t)
 
@@ -1536,6 +1928,27 @@ func TestInitWithRepoAliases(t *testing.T) {
 }
 
 // TestInitWithExistingAliasLabel tests Init when alias overlaps with existing label
+func TestInitWithRepoPaths(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	resetCatalogState(t)
+
+	viper.Set(config.RepoPaths, map[string]interface{}{
+		"monorepo": []string{"services/api", "/services/worker/"},
+	})
+
+	Catalog = map[string]scm.Repository{
+		"monorepo": {Name: "monorepo", Project: "test-project"},
+	}
+
+	Init(ctx, false)
+
+	// Each monorepo gets a label matching the path-scoped targets for its subdirectories
+	if got := RepositoryNames(ctx, "~monorepo"); !slices.Equal(got, []string{"monorepo//services/api", "monorepo//services/worker"}) {
+		t.Errorf("Expected path-scoped targets for ~monorepo, got %v", got)
+	}
+}
+
 func TestInitWithExistingAliasLabel(t *testing.T) {
 	ctx := loadFixture(t)
 	resetCatalogState(t)
@@ -1605,6 +2018,128 @@ func TestCatalogCachePathDefault(t *testing.T) {
 	}
 }
 
+// TestCatalogCachePathLayout tests that layouts without host directories keep the cache in the git directory, named by host
+func TestCatalogCachePathLayout(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	gitDir := "/home/user/repos"
+
+	viper.Set(config.CatalogCachePath, "")
+	viper.Set(config.GitDirectory, gitDir)
+	viper.Set(config.GitHost, "git.example.com:7990")
+
+	for _, layout := range []string{config.GitLayoutProject, config.GitLayoutFlat} {
+		viper.Set(config.GitLayout, layout)
+
+		if path, want := catalogCachePath(ctx), filepath.Join(gitDir, ".batch-tool-cache.git.example.com_7990.json"); path != want {
+			t.Errorf("Expected %s layout path %q, got %q", layout, want, path)
+		}
+	}
+
+	viper.Set(config.CatalogCacheCompress, true)
+
+	if path, want := catalogCachePath(ctx), filepath.Join(gitDir, ".batch-tool-cache.git.example.com_7990.json.gz"); path != want {
+		t.Errorf("Expected compressed layout path %q, got %q", want, path)
+	}
+}
+
+// TestCatalogCachePathWithDirectory tests catalogCachePath with a configured cache directory
+func TestCatalogCachePathWithDirectory(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	cacheDir := "/custom/cache/dir"
+
+	viper.Set(config.CatalogCachePath, "")
+	viper.Set(config.CatalogCacheDir, cacheDir)
+	viper.Set(config.GitHost, "git.example.com:7990")
+
+	// the file name includes the host, since the directory may be shared between hosts
+	if path, want := catalogCachePath(ctx), filepath.Join(cacheDir, ".batch-tool-cache.git.example.com_7990.json"); path != want {
+		t.Errorf("Expected path %q, got %q", want, path)
+	}
+
+	viper.Set(config.GitHost, "github.com")
+
+	if path, want := catalogCachePath(ctx), filepath.Join(cacheDir, ".batch-tool-cache.github.com.json"); path != want {
+		t.Errorf("Expected path for another host %q, got %q", want, path)
+	}
+
+	viper.Set(config.CatalogCacheCompress, true)
+
+	if path, want := catalogCachePath(ctx), filepath.Join(cacheDir, ".batch-tool-cache.github.com.json.gz"); path != want {
+		t.Errorf("Expected compressed path %q, got %q", want, path)
+	}
+
+	// An explicit cache path takes precedence over the directory
+	viper.Set(config.CatalogCachePath, "/custom/path/to/cache.json")
+
+	if path := catalogCachePath(ctx); path != "/custom/path/to/cache.json" {
+		t.Errorf("Expected custom path to take precedence, got %q", path)
+	}
+}
+
+// TestCatalogCacheGzipRoundTrip tests that a gzip compressed cache is written and read back transparently
+func TestCatalogCacheGzipRoundTrip(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json.gz")
+	config.Viper(ctx).Set(config.CatalogCachePath, cachePath)
+
+	want := scm.Repository{
+		Name:          "repo1",
+		Description:   "Repository 1",
+		Project:       "test-project",
+		DefaultBranch: "main",
+		Labels:        []string{"backend"},
+	}
+
+	Catalog = map[string]scm.Repository{"test-project/repo1": want}
+
+	if err := saveCatalogCache(ctx); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Failed to read cache file: %v", err)
+	}
+
+	// Verify the gzip magic header is present
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		t.Fatalf("Expected gzip compressed cache file, got %q", data)
+	}
+
+	resetCatalogState(t)
+
+	if err := loadCatalogCache(ctx, time.Hour); err != nil {
+		t.Fatalf("Failed to load cache: %v", err)
+	}
+
+	if got := Catalog["test-project/repo1"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected cached repository %+v, got %+v", want, got)
+	}
+}
+
+// TestLoadCatalogCacheInvalidGzip tests that a corrupt gzip cache is reported as an error
+func TestLoadCatalogCacheInvalidGzip(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json.gz")
+	config.Viper(ctx).Set(config.CatalogCachePath, cachePath)
+
+	if err := os.WriteFile(cachePath, []byte(`{"updated_at":"2024-01-01T00:00:00Z"}`), 0o600); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	if err := loadCatalogCache(ctx, time.Hour); err == nil {
+		t.Error("Expected error loading uncompressed data from a .gz cache")
+	}
+}
+
 // TestSaveCatalogCacheCreatesDirs tests that saveCatalogCache creates parent directories
 func TestSaveCatalogCacheCreatesDirs(t *testing.T) {
 	ctx := loadFixture(t)
@@ -2034,6 +2569,109 @@ func TestArchivedRepos(t *testing.T) {
 	}
 }
 
+func TestUnwantedRepos(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	Labels = map[string]mapset.Set[string]{
+		"unwanted1": mapset.NewSet("repo1", "repo2"),
+		"unwanted2": mapset.NewSet("repo3"),
+		"wanted":    mapset.NewSet("repo4", "repo5"),
+	}
+	Catalog = map[string]scm.Repository{
+		"repo1": {Name: "repo1"},
+		"repo2": {Name: "repo2"},
+		"repo3": {Name: "repo3"},
+		"repo4": {Name: "repo4"},
+		"repo5": {Name: "repo5", Archived: true},
+	}
+	viper.Set(config.UnwantedLabels, []string{"unwanted1", "unwanted2", "missing"})
+
+	tests := []struct {
+		name         string
+		skipUnwanted bool
+		skipArchived bool
+		want         []string
+	}{
+		{name: "skip both", skipUnwanted: true, skipArchived: true, want: []string{"repo1", "repo2", "repo3", "repo5"}},
+		{name: "skip unwanted only", skipUnwanted: true, want: []string{"repo1", "repo2", "repo3"}},
+		{name: "skip archived only", skipArchived: true, want: []string{"repo5"}},
+		{name: "skip neither", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set(config.SkipUnwanted, tt.skipUnwanted)
+			viper.Set(config.SkipArchived, tt.skipArchived)
+
+			got := UnwantedRepos(ctx)
+			if !got.Equal(mapset.NewSet(tt.want...)) {
+				t.Errorf("UnwantedRepos() = %v, want %v", got.ToSlice(), tt.want)
+			}
+
+			// Wanted and unwanted repos must partition the catalog
+			wanted := WantedRepos(ctx)
+			if wanted.Cardinality()+got.Cardinality() != len(Catalog) {
+				t.Errorf("Expected wanted (%d) + unwanted (%d) to equal catalog size %d",
+					wanted.Cardinality(), got.Cardinality(), len(Catalog))
+			}
+			if !wanted.Intersect(got).IsEmpty() {
+				t.Errorf("Expected wanted and unwanted sets to be disjoint, got %v", wanted.Intersect(got).ToSlice())
+			}
+		})
+	}
+}
+
+func TestUnknownUnwantedLabels(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	Labels = map[string]mapset.Set[string]{
+		"unwanted1":  mapset.NewSet("repo1"),
+		"legacy-web": mapset.NewSet("repo2"),
+	}
+	viper.Set(config.UnwantedLabels, []string{"unwanted1", "missing", "legacy-*", "old-*"})
+
+	viper.Set(config.SkipUnwanted, true)
+	if got := unknownUnwantedLabels(ctx); !slices.Equal(got, []string{"missing", "old-*"}) {
+		t.Errorf("unknownUnwantedLabels() = %v, want [missing old-*]", got)
+	}
+
+	// unwanted labels which aren't skipped are never reported
+	viper.Set(config.SkipUnwanted, false)
+	if got := unknownUnwantedLabels(ctx); len(got) != 0 {
+		t.Errorf("unknownUnwantedLabels() = %v, want none", got)
+	}
+}
+
+func TestWantedReposMatchesRepositoryList(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	Labels = map[string]mapset.Set[string]{
+		"all":        mapset.NewSet("web-app", "mobile-app", "deprecated-app", "old-app"),
+		"deprecated": mapset.NewSet("deprecated-app"),
+	}
+	Catalog = map[string]scm.Repository{
+		"web-app":        {Name: "web-app"},
+		"mobile-app":     {Name: "mobile-app"},
+		"deprecated-app": {Name: "deprecated-app"},
+		"old-app":        {Name: "old-app", Archived: true},
+	}
+	viper.Set(config.UnwantedLabels, []string{"deprecated"})
+
+	for _, skip := range []bool{true, false} {
+		viper.Set(config.SkipUnwanted, skip)
+		viper.Set(config.SkipArchived, skip)
+
+		// Selecting the superset label must yield exactly the wanted repos
+		got := RepositoryList(ctx, "~all")
+		if want := WantedRepos(ctx); !got.Equal(want) {
+			t.Errorf("skip=%v: RepositoryList(~all) = %v, WantedRepos() = %v", skip, got.ToSlice(), want.ToSlice())
+		}
+	}
+}
+
 func TestGetLabelsForRepo(t *testing.T) {
 	Labels = map[string]mapset.Set[string]{
 		"backend":  mapset.NewSet("repo-a", "repo-b"),
@@ -2090,3 +2728,97 @@ func TestGetLabelsForRepo(t *testing.T) {
 		})
 	}
 }
+
+// TestCatalogCacheRepositoryURLs tests that repository URLs survive a round trip through the cache
+func TestCatalogCacheRepositoryURLs(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+	t.Cleanup(func() { cleanupCache(t, ctx) })
+
+	want := scm.Repository{
+		Name:          "repo1",
+		Project:       "test-project",
+		DefaultBranch: "main",
+		CloneURL:      "https://example.com/test-project/repo1.git",
+		SSHURL:        "git@example.com:test-project/repo1.git",
+		WebURL:        "https://example.com/test-project/repo1",
+	}
+
+	Catalog = map[string]scm.Repository{"test-project/repo1": want}
+
+	if err := saveCatalogCache(ctx); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	resetCatalogState(t)
+
+	if err := loadCatalogCache(ctx, time.Hour); err != nil {
+		t.Fatalf("Failed to load cache: %v", err)
+	}
+
+	if got := Catalog["test-project/repo1"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected cached repository %+v, got %+v", want, got)
+	}
+}
+
+// TestGetURLForRepo tests that the clone URL matching the requested protocol is returned
+func TestGetURLForRepo(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+
+	Catalog["test-project/repo1"] = scm.Repository{
+		Name:     "repo1",
+		Project:  "test-project",
+		CloneURL: "https://example.com/test-project/repo1.git",
+		SSHURL:   "git@example.com:test-project/repo1.git",
+	}
+
+	testhelper.AssertEqual(t, GetURLForRepo(ctx, "test-project/repo1", false), "https://example.com/test-project/repo1.git")
+	testhelper.AssertEqual(t, GetURLForRepo(ctx, "test-project/repo1", true), "git@example.com:test-project/repo1.git")
+	testhelper.AssertEqual(t, GetURLForRepo(ctx, "test-project/missing", true), "")
+}
+
+func TestRepositoriesAndLabelSets(t *testing.T) {
+	resetCatalogState(t)
+
+	Catalog = map[string]scm.Repository{
+		"repo-1": {Name: "repo-1", Project: "test", Labels: []string{"backend", "api"}},
+	}
+	Labels = map[string]mapset.Set[string]{
+		"backend": mapset.NewSet("repo-1"),
+	}
+
+	repos := Repositories()
+	labels := LabelSets()
+
+	testhelper.AssertEqual(t, repos["repo-1"].Project, "test")
+	testhelper.AssertEqual(t, labels["backend"].Contains("repo-1"), true)
+
+	// the copies can be modified without affecting the catalog
+	repos["repo-1"].Labels[0] = "modified"
+	delete(repos, "repo-1")
+	labels["backend"].Add("repo-2")
+
+	testhelper.AssertEqual(t, Catalog["repo-1"].Labels[0], "backend")
+	testhelper.AssertEqual(t, Labels["backend"].Contains("repo-2"), false)
+
+	// and read safely while the catalog is replaced
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			flush()
+			Catalog["repo-1"] = scm.Repository{Name: "repo-1"}
+			Labels["backend"] = mapset.NewSet("repo-1")
+		})
+
+		wg.Go(func() {
+			_ = Repositories()
+			_ = LabelSets()
+		})
+	}
+
+	wg.Wait()
+}
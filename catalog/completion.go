@@ -24,6 +24,9 @@ func CompletionFunc() cobra.CompletionFunc {
 		ctx := cmd.Context()
 		viper := config.Viper(ctx)
 
+		mu.RLock()
+		defer mu.RUnlock()
+
 		validCompletions := mapset.NewSet[cobra.Completion]()
 		for label := range Labels {
 			// suggest label names matching the partial input
@@ -0,0 +1,101 @@
+package catalog
+
+import (
+	"context"
+	"sort"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// Changes describes how the live repository data differs from the cached catalog.
+type Changes struct {
+	// Added lists the repositories returned upstream which aren't in the catalog.
+	Added []string
+	// Removed lists the repositories in the catalog which are no longer returned upstream.
+	Removed []string
+	// Relabeled lists the repositories whose labels have changed upstream.
+	Relabeled []LabelChange
+	// AddedLabels lists the labels returned upstream which no repository in the catalog has.
+	AddedLabels []string
+	// RemovedLabels lists the labels in the catalog which no repository returned upstream has.
+	RemovedLabels []string
+}
+
+// LabelChange describes the labels added to and removed from a repository upstream.
+type LabelChange struct {
+	Repo    string
+	Added   []string
+	Removed []string
+}
+
+// Empty reports whether the catalog matches the live data.
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Relabeled) == 0
+}
+
+// Diff fetches the repositories from the configured providers and compares them against the catalog,
+// reporting added and removed repositories and changed labels in sorted order. Neither the catalog
+// nor its local cache is modified.
+func Diff(ctx context.Context) (*Changes, error) {
+	live, err := listRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return compareCatalogs(Catalog, live), nil
+}
+
+// compareCatalogs reports the differences between the cached and live repository data in sorted order.
+func compareCatalogs(cached, live map[string]scm.Repository) *Changes {
+	changes := &Changes{Added: make([]string, 0), Removed: make([]string, 0)}
+	cachedLabels, liveLabels := mapset.NewSet[string](), mapset.NewSet[string]()
+
+	for name, repo := range live {
+		liveLabels.Append(repo.Labels...)
+
+		old, ok := cached[name]
+		if !ok {
+			changes.Added = append(changes.Added, name)
+			continue
+		}
+
+		before, after := mapset.NewSet(old.Labels...), mapset.NewSet(repo.Labels...)
+		if !before.Equal(after) {
+			changes.Relabeled = append(changes.Relabeled, LabelChange{
+				Repo:    name,
+				Added:   sortedSet(after.Difference(before)),
+				Removed: sortedSet(before.Difference(after)),
+			})
+		}
+	}
+
+	for name, repo := range cached {
+		cachedLabels.Append(repo.Labels...)
+
+		if _, ok := live[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	changes.AddedLabels = sortedSet(liveLabels.Difference(cachedLabels))
+	changes.RemovedLabels = sortedSet(cachedLabels.Difference(liveLabels))
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Slice(changes.Relabeled, func(i, j int) bool { return changes.Relabeled[i].Repo < changes.Relabeled[j].Repo })
+
+	return changes
+}
+
+// sortedSet returns the members of the set in sorted order.
+func sortedSet(set mapset.Set[string]) []string {
+	members := set.ToSlice()
+	sort.Strings(members)
+
+	return members
+}
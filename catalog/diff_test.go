@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestDiff(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	cachePath := config.Viper(ctx).GetString(config.CatalogCachePath)
+	cached, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Failed to read catalog cache: %v", err)
+	}
+
+	// Upstream, repo-2 is deleted, repo-4 is created, and the labels of repo-1 and repo-3 change
+	provider.Repositories = []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", Labels: []string{"backend", "go"}},
+		{Name: "repo-3", Project: "test-project", Labels: []string{"web"}},
+		{Name: "repo-4", Project: "test-project"},
+	}
+
+	changes, err := Diff(ctx)
+	testhelper.AssertError(t, err, false)
+
+	testhelper.AssertEqual(t, strings.Join(changes.Added, ","), "test-project/repo-4")
+	testhelper.AssertEqual(t, strings.Join(changes.Removed, ","), "test-project/repo-2")
+	testhelper.AssertEqual(t, strings.Join(changes.AddedLabels, ","), "go,web")
+	testhelper.AssertEqual(t, strings.Join(changes.RemovedLabels, ","), "frontend,legacy")
+	testhelper.AssertLength(t, changes.Relabeled, 2)
+
+	testhelper.AssertEqual(t, changes.Relabeled[0].Repo, "test-project/repo-1")
+	testhelper.AssertEqual(t, strings.Join(changes.Relabeled[0].Added, ","), "go")
+	testhelper.AssertLength(t, changes.Relabeled[0].Removed, 0)
+
+	testhelper.AssertEqual(t, changes.Relabeled[1].Repo, "test-project/repo-3")
+	testhelper.AssertEqual(t, strings.Join(changes.Relabeled[1].Added, ","), "web")
+	testhelper.AssertEqual(t, strings.Join(changes.Relabeled[1].Removed, ","), "frontend")
+
+	// Neither the catalog nor its cache is modified
+	testhelper.AssertLength(t, Catalog, 3)
+	if _, ok := Catalog["test-project/repo-4"]; ok {
+		t.Error("Expected repo-4 not to be added to the catalog")
+	}
+
+	after, err := os.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Failed to read catalog cache: %v", err)
+	}
+
+	testhelper.AssertEqual(t, string(after), string(cached))
+}
+
+func TestDiffUpToDate(t *testing.T) {
+	ctx, _ := setupPruneTest(t)
+
+	changes, err := Diff(ctx)
+	testhelper.AssertError(t, err, false)
+	testhelper.AssertEqual(t, changes.Empty(), true)
+}
+
+func TestDiffProviderError(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	provider.SetError("ListRepositories", errors.New("api unavailable"))
+
+	_, err := Diff(ctx)
+	testhelper.AssertError(t, err, true)
+}
@@ -24,9 +24,17 @@ func loadFixture(t *testing.T) context.Context {
 func setupCacheFile(t *testing.T, ctx context.Context, repos map[string]scm.Repository, updatedAt time.Time) string {
 	t.Helper()
 
+	return setupCacheFileVersion(t, ctx, repos, updatedAt, cacheVersion)
+}
+
+// setupCacheFileVersion creates a cache file with the given repositories and timestamp, and schema version
+func setupCacheFileVersion(t *testing.T, ctx context.Context, repos map[string]scm.Repository, updatedAt time.Time, version int) string {
+	t.Helper()
+
 	cachePath := catalogCachePath(ctx)
 
 	cache := repositoryCache{
+		Version:      version,
 		UpdatedAt:    updatedAt,
 		Repositories: repos,
 	}
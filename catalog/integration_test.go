@@ -233,19 +233,14 @@ func TestConcurrentCatalogInitialization(t *testing.T) {
 	viper.Set(config.GitProject, "test-project")
 
 	// Try to initialize catalog from multiple goroutines
-	// Note: This test verifies that concurrent calls don't panic, but since
-	// Catalog is a shared global map, we serialize access to prevent races
 	const numGoroutines = 10
 	var wg sync.WaitGroup
-	var mu sync.Mutex // Protect shared Catalog/Labels state
 	errors := make(chan error, numGoroutines)
 
 	for i := 0; i < numGoroutines; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			mu.Lock()
-			defer mu.Unlock()
 			if err := initRepositoryCatalog(ctx, false); err != nil {
 				errors <- err
 			}
@@ -573,23 +568,33 @@ func TestConcurrentCacheAccess(t *testing.T) {
 		t.Fatalf("Failed to save initial cache: %v", err)
 	}
 
+	resetCatalogState(t)
+
 	const numOperations = 20
 	var wg sync.WaitGroup
-	errors := make(chan error, numOperations)
-	var mu sync.Mutex // Protect concurrent access to catalog globals
+	errors := make(chan error, 3*numOperations)
 
-	// Concurrent reads - serialize catalog state modifications
+	// Concurrent loads, saves, and reads of the shared catalog state
 	for i := 0; i < numOperations; i++ {
-		wg.Add(1)
+		wg.Add(3)
 		go func() {
 			defer wg.Done()
-			mu.Lock()
-			defer mu.Unlock()
-			resetCatalogState(t)
 			if err := loadCatalogCache(ctx, flushTTL); err != nil {
 				errors <- fmt.Errorf("load failed: %w", err)
 			}
 		}()
+		go func() {
+			defer wg.Done()
+			if err := saveCatalogCache(ctx); err != nil {
+				errors <- fmt.Errorf("save failed: %w", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			RepositoryList(ctx, "~backend")
+			GetRepository(ctx, "repo-1")
+			GetLabelsForRepo("test/repo-1")
+		}()
 	}
 
 	wg.Wait()
@@ -599,6 +604,14 @@ func TestConcurrentCacheAccess(t *testing.T) {
 	for err := range errors {
 		t.Errorf("Concurrent cache operation failed: %v", err)
 	}
+
+	if repo, ok := GetRepository(ctx, "repo-1"); !ok || repo.Name != "repo-1" {
+		t.Errorf("Expected repo-1 to be loaded from the cache, got %v", repo)
+	}
+
+	if !RepositoryList(ctx, "~backend").Contains("test/repo-1") {
+		t.Error("Expected the backend label to be loaded from the cache")
+	}
 }
 
 // Helper function
@@ -15,8 +15,9 @@ import (
 
 // set-theory notation symbols
 const (
-	union = "∪" // U+222A
-	minus = "∖" // U+2216
+	union     = "∪" // U+222A
+	minus     = "∖" // U+2216
+	intersect = "∩" // U+2229
 )
 
 // Label represents a logical grouping of repository names.
@@ -32,16 +33,27 @@ func (l *Label) String() string {
 	return strings.Join(slice, " "+union+" ")
 }
 
+// Intersection provides a set-theory representation of the intersection of the Label's members.
+func (l *Label) Intersection() string {
+	slice := l.ToSlice()
+	slices.Sort(slice)
+
+	return strings.Join(slice, " "+intersect+" ")
+}
+
 // LabelGroup holds categorized label names extracted from a list of filter arguments.
 type LabelGroup struct {
 	Forced   Label
 	Included Label
 	Excluded Label
+
+	// Intersected labels narrow the included repositories to those in every one of them.
+	Intersected Label
 }
 
 // String provides a set-theory representation of the LabelGroup.
 //
-//	(forced ∪ (included ∖ excluded))
+//	(forced ∪ ((included ∩ intersected) ∖ excluded))
 func (lg LabelGroup) String() string {
 	// Build set notation string
 	var setBuilder strings.Builder
@@ -53,7 +65,7 @@ func (lg LabelGroup) String() string {
 		}
 	}
 
-	fmt.Fprintf(&setBuilder, "(%s)", lg.Included.String())
+	setBuilder.WriteString(lg.IncludedString())
 
 	if lg.Excluded.Cardinality() > 0 {
 		fmt.Fprintf(&setBuilder, " %s (%s)", minus, lg.Excluded.String())
@@ -65,8 +77,20 @@ func (lg LabelGroup) String() string {
 	return setBuilder.String()
 }
 
+// IncludedString provides a set-theory representation of the included labels, narrowed by any intersected labels.
+func (lg LabelGroup) IncludedString() string {
+	switch {
+	case lg.Intersected.Set == nil || lg.Intersected.Cardinality() == 0:
+		return fmt.Sprintf("(%s)", lg.Included.String())
+	case lg.Included.Cardinality() == 0:
+		return fmt.Sprintf("(%s)", lg.Intersected.Intersection())
+	default:
+		return fmt.Sprintf("((%s) %s (%s))", lg.Included.String(), intersect, lg.Intersected.Intersection())
+	}
+}
+
 // ToSlices converts the LabelGroup sets to sorted slices.
-func (lg LabelGroup) ToSlices() (forced, included, excluded []string) {
+func (lg LabelGroup) ToSlices() (forced, included, excluded, intersected []string) {
 	forced = lg.Forced.ToSlice()
 	sort.Strings(forced)
 
@@ -76,18 +100,31 @@ func (lg LabelGroup) ToSlices() (forced, included, excluded []string) {
 	excluded = lg.Excluded.ToSlice()
 	sort.Strings(excluded)
 
+	if lg.Intersected.Set != nil {
+		intersected = lg.Intersected.ToSlice()
+		sort.Strings(intersected)
+	}
+
 	return
 }
 
 // ParseLabels constructs a parsed group of labels based on the provided filters, along with the
 // list of matching repositories from the local cache.
 func ParseLabels(ctx context.Context, filters ...string) (LabelGroup, []string) {
+	return GroupLabels(ctx, filters...), RepositoryNames(ctx, filters...)
+}
+
+// GroupLabels categorizes the provided filters into a group of labels, without matching any repositories. The
+// names of label filters keep a trailing label token, distinguishing them from the repositories selected by name.
+func GroupLabels(ctx context.Context, filters ...string) LabelGroup {
 	viper := config.Viper(ctx)
 
 	labels := LabelGroup{
 		Forced:   Label{mapset.NewSet[string]()},
 		Included: Label{mapset.NewSet[string]()},
 		Excluded: Label{mapset.NewSet[string]()},
+
+		Intersected: Label{mapset.NewSet[string]()},
 	}
 
 	// If configured to skip unwanted labels, add them to the excluded list
@@ -106,18 +143,15 @@ func ParseLabels(ctx context.Context, filters ...string) (LabelGroup, []string)
 		case strings.Contains(filter, viper.GetString(config.TokenForced)):
 			labels.Forced.Add(filterName)
 
+		case strings.Contains(filter, viper.GetString(config.TokenIntersect)):
+			labels.Intersected.Add(filterName)
+
 		default:
 			labels.Included.Add(filterName)
 		}
 	}
 
-	// Get matched repos
-	repoList := RepositoryList(ctx, filters...).ToSlice()
-	if viper.GetBool(config.SortRepos) {
-		sort.Strings(repoList)
-	}
-
-	return labels, repoList
+	return labels
 }
 
 // clean the filter name and re-append label token if needed
@@ -125,9 +159,47 @@ func cleanName(ctx context.Context, filter string) string {
 	viper := config.Viper(ctx)
 	name := utils.CleanFilter(ctx, filter)
 
-	if strings.Contains(filter, viper.GetString(config.TokenLabel)) {
+	if isLabelFilter(ctx, filter) {
 		name += viper.GetString(config.TokenLabel)
 	}
 
 	return name
 }
+
+// UnusedLabel is a label which no longer selects any repository by default.
+type UnusedLabel struct {
+	Name string
+	// Repos is the number of repositories matched by the label, all of which are unwanted or archived.
+	Repos int
+}
+
+// UnusedLabels returns the labels, sorted by name, which are empty or only match repositories excluded by default
+// (see [UnwantedRepos]), and so select nothing unless forced. The configured unwanted labels and the superset label
+// are never reported, since they are expected to match only unwanted repositories or to be empty respectively.
+func UnusedLabels(ctx context.Context) []UnusedLabel {
+	viper := config.Viper(ctx)
+
+	unwanted := UnwantedRepos(ctx)
+
+	skip := mapset.NewSet(viper.GetStringSlice(config.UnwantedLabels)...)
+	skip.Add(viper.GetString(config.SuperSetLabel))
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	unused := make([]UnusedLabel, 0)
+
+	for name, repos := range Labels {
+		if skip.Contains(name) || !repos.IsSubset(unwanted) {
+			continue
+		}
+
+		unused = append(unused, UnusedLabel{Name: name, Repos: repos.Cardinality()})
+	}
+
+	slices.SortFunc(unused, func(a, b UnusedLabel) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return unused
+}
@@ -1,12 +1,14 @@
 package catalog
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
 	mapset "github.com/deckarep/golang-set/v2"
 
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
 )
 
 func TestLabelString(t *testing.T) {
@@ -34,6 +36,7 @@ func TestLabelGroupString(t *testing.T) {
 	tests := []struct {
 		name              string
 		forced, inc, exc  []string
+		intersected       []string
 		wantContainsAll   []string
 		wantNotContaining []string
 	}{
@@ -55,6 +58,18 @@ func TestLabelGroupString(t *testing.T) {
 			exc:             []string{"exc1"},
 			wantContainsAll: []string{"(force1)", "∪", "(inc1)", "∖", "(exc1)"},
 		},
+		{
+			name:            "intersected only",
+			intersected:     []string{"go", "critical"},
+			wantContainsAll: []string{"(critical ∩ go)"},
+		},
+		{
+			name:            "included and intersected",
+			inc:             []string{"alpha"},
+			intersected:     []string{"go"},
+			exc:             []string{"omega"},
+			wantContainsAll: []string{"((alpha) ∩ (go)) ∖ (omega)"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -63,6 +78,8 @@ func TestLabelGroupString(t *testing.T) {
 				Forced:   Label{Set: mapset.NewSet(tt.forced...)},
 				Included: Label{Set: mapset.NewSet(tt.inc...)},
 				Excluded: Label{Set: mapset.NewSet(tt.exc...)},
+
+				Intersected: Label{Set: mapset.NewSet(tt.intersected...)},
 			}
 
 			got := lg.String()
@@ -80,9 +97,15 @@ func TestLabelGroupToSlices(t *testing.T) {
 		Forced:   Label{Set: mapset.NewSet("c", "a", "b")},
 		Included: Label{Set: mapset.NewSet("z", "y")},
 		Excluded: Label{Set: mapset.NewSet("m")},
+
+		Intersected: Label{Set: mapset.NewSet("q", "p")},
 	}
 
-	forced, inc, exc := lg.ToSlices()
+	forced, inc, exc, intersected := lg.ToSlices()
+
+	if !sliceEqual(intersected, []string{"p", "q"}) {
+		t.Errorf("intersected not sorted: %v", intersected)
+	}
 
 	if !sliceEqual(forced, []string{"a", "b", "c"}) {
 		t.Errorf("forced not sorted: %v", forced)
@@ -110,6 +133,8 @@ func TestParseLabels(t *testing.T) {
 		wantForcedNames   []string
 		wantIncludedNames []string
 		wantExcludedNames []string
+
+		wantIntersectedNames []string
 	}{
 		{
 			name:              "plain repo",
@@ -138,13 +163,25 @@ func TestParseLabels(t *testing.T) {
 			wantIncludedNames: []string{"keep"},
 			wantExcludedNames: []string{"drop"},
 		},
+		{
+			name:                 "intersect token routes to Intersected as a label",
+			filters:              []string{"~keep", "&go"},
+			wantIncludedNames:    []string{"keep~"},
+			wantIntersectedNames: []string{"go~"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			lg, _ := ParseLabels(ctx, tt.filters...)
 
-			f, inc, exc := lg.ToSlices()
+			intersected := lg.Intersected.ToSlice()
+			slices.Sort(intersected)
+			if !sliceEqual(intersected, tt.wantIntersectedNames) {
+				t.Errorf("intersected = %v, want %v", intersected, tt.wantIntersectedNames)
+			}
+
+			f, inc, exc, _ := lg.ToSlices()
 			if !sliceEqual(f, tt.wantForcedNames) {
 				t.Errorf("forced = %v, want %v", f, tt.wantForcedNames)
 			}
@@ -169,7 +206,7 @@ func TestParseLabelsAppendsUnwanted(t *testing.T) {
 	v.Set(config.SortRepos, true)
 
 	lg, _ := ParseLabels(ctx, "keep")
-	_, _, exc := lg.ToSlices()
+	_, _, exc, _ := lg.ToSlices()
 
 	found := false
 	for _, e := range exc {
@@ -211,3 +248,82 @@ func sliceEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func TestUnusedLabels(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+	t.Cleanup(func() { resetCatalogState(t) })
+
+	v := config.Viper(ctx)
+	v.Set(config.SkipUnwanted, true)
+	v.Set(config.SkipArchived, true)
+	v.Set(config.UnwantedLabels, []string{"deprecated"})
+
+	Catalog = map[string]scm.Repository{
+		"proj/active":   {Name: "active", Project: "proj"},
+		"proj/old":      {Name: "old", Project: "proj"},
+		"proj/archived": {Name: "archived", Project: "proj", Archived: true},
+	}
+	Labels = map[string]mapset.Set[string]{
+		"all":        mapset.NewSet("proj/active", "proj/old", "proj/archived"),
+		"deprecated": mapset.NewSet("proj/old"),
+		"backend":    mapset.NewSet("proj/active", "proj/old"),
+		"legacy":     mapset.NewSet("proj/old", "proj/archived"),
+		"frozen":     mapset.NewSet("proj/archived"),
+		"empty":      mapset.NewSet[string](),
+	}
+
+	got := UnusedLabels(ctx)
+
+	want := []UnusedLabel{
+		{Name: "empty", Repos: 0},
+		{Name: "frozen", Repos: 1},
+		{Name: "legacy", Repos: 2},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("UnusedLabels() = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("UnusedLabels()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// archived repositories are only unwanted while they are skipped
+	v.Set(config.SkipArchived, false)
+
+	for _, label := range UnusedLabels(ctx) {
+		if label.Name == "frozen" || label.Name == "legacy" {
+			t.Errorf("Expected %s to be used when archived repositories aren't skipped", label.Name)
+		}
+	}
+}
+
+func TestMatchLabelNames(t *testing.T) {
+	labels := map[string]mapset.Set[string]{
+		"backend":    mapset.NewSet("api"),
+		"legacy-web": mapset.NewSet("old-web"),
+		"legacy-api": mapset.NewSet("old-api"),
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "backend", want: []string{"backend"}},
+		{name: "missing", want: []string{"missing"}},
+		{name: "legacy-*", want: []string{"legacy-api", "legacy-web"}},
+		{name: "legacy-[w]eb", want: []string{"legacy-web"}},
+		{name: "none-*", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLabelNames(tt.name, labels); !slices.Equal(got, tt.want) {
+				t.Errorf("MatchLabelNames(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package catalog
+
+import "context"
+
+// FetchProgress reports the progress of fetching repository data from each project when the catalog isn't cached.
+type FetchProgress interface {
+	// Start is called before fetching begins with the number of projects to fetch.
+	Start(projects int)
+	// Fetching is called as fetching begins for each project.
+	Fetching(project string)
+	// Fetched is called once the repositories of a project have been fetched.
+	Fetched(project string, repos int)
+	// Stop is called once fetching has finished, successfully or not.
+	Stop()
+}
+
+// NewFetchProgress returns the reporter used while fetching repository data. It is initialized
+// in the output package to avoid circular import, and reports nothing by default.
+var NewFetchProgress = func(_ context.Context) FetchProgress {
+	return NoFetchProgress{}
+}
+
+// NoFetchProgress is a FetchProgress which reports nothing.
+type NoFetchProgress struct{}
+
+func (NoFetchProgress) Start(int)           {}
+func (NoFetchProgress) Fetching(string)     {}
+func (NoFetchProgress) Fetched(string, int) {}
+func (NoFetchProgress) Stop()               {}
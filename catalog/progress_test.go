@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/scm/fake"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// recordedProgress records each progress event reported while fetching.
+type recordedProgress struct {
+	events []string
+}
+
+func (p *recordedProgress) Start(projects int) {
+	p.events = append(p.events, fmt.Sprintf("start %d", projects))
+}
+
+func (p *recordedProgress) Fetching(project string) {
+	p.events = append(p.events, "fetching "+project)
+}
+
+func (p *recordedProgress) Fetched(project string, repos int) {
+	p.events = append(p.events, fmt.Sprintf("fetched %s %d", project, repos))
+}
+
+func (p *recordedProgress) Stop() {
+	p.events = append(p.events, "stop")
+}
+
+// recordProgress replaces the fetch progress reporter for the duration of the test.
+func recordProgress(t *testing.T) *recordedProgress {
+	t.Helper()
+
+	progress := &recordedProgress{}
+	original := NewFetchProgress
+	NewFetchProgress = func(context.Context) FetchProgress { return progress }
+	t.Cleanup(func() { NewFetchProgress = original })
+
+	return progress
+}
+
+func TestFetchRepositoryDataProgress(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+	t.Cleanup(func() { cleanupCache(t, ctx) })
+
+	progress := recordProgress(t)
+
+	scm.Register("fake-progress", func(_ context.Context, project string) scm.Provider {
+		return fake.NewFake(project, fake.CreateTestRepositories(project))
+	})
+
+	viper := config.Viper(ctx)
+	viper.Set(config.GitProvider, "fake-progress")
+	viper.Set(config.GitProject, "beta")
+	viper.Set(config.GitProjects, []string{"alpha"})
+
+	testhelper.AssertError(t, fetchRepositoryData(ctx), false)
+
+	// projects are fetched in order, and each is reported before and after it is fetched
+	testhelper.AssertEqual(t, strings.Join(progress.events, ", "),
+		"start 2, fetching alpha, fetched alpha 5, fetching beta, fetched beta 5, stop")
+}
+
+func TestFetchRepositoryDataProgressFailure(t *testing.T) {
+	ctx := loadFixture(t)
+	resetCatalogState(t)
+	t.Cleanup(func() { cleanupCache(t, ctx) })
+
+	progress := recordProgress(t)
+
+	scm.Register("fake-progress-failure", func(_ context.Context, project string) scm.Provider {
+		provider := fake.NewFake(project, fake.CreateTestRepositories(project))
+		provider.Errors["ListRepositories"] = errors.New("unavailable")
+
+		return provider
+	})
+
+	viper := config.Viper(ctx)
+	viper.Set(config.GitProvider, "fake-progress-failure")
+	viper.Set(config.GitProject, "alpha")
+	viper.Set(config.GitProjects, []string{})
+
+	testhelper.AssertError(t, fetchRepositoryData(ctx), true)
+
+	// the reporter is stopped even when fetching fails
+	testhelper.AssertEqual(t, strings.Join(progress.events, ", "), "start 1, fetching alpha, stop")
+}
@@ -0,0 +1,46 @@
+package catalog
+
+import (
+	"context"
+	"sort"
+)
+
+// Prune refreshes the repository catalog from the configured providers and removes any cached
+// repositories which are no longer returned (e.g. deleted or renamed upstream), rebuilding the
+// labels of those which remain. The sorted names of the stale repositories are returned. If dryRun is set,
+// the catalog and its local cache are left unmodified.
+func Prune(ctx context.Context, dryRun bool) ([]string, error) {
+	live, err := listRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+
+	pruned := make([]string, 0)
+	for name := range Catalog {
+		if _, ok := live[name]; !ok {
+			pruned = append(pruned, name)
+		}
+	}
+	sort.Strings(pruned)
+
+	if dryRun {
+		mu.Unlock()
+		return pruned, nil
+	}
+
+	// Rebuild the catalog and its labels from the remaining repositories, as Refresh does, so that labels removed
+	// upstream are dropped and configured aliases are kept even if none of their repositories remain
+	flush()
+
+	for repoKey, repo := range live {
+		addRepository(repoKey, repo)
+	}
+
+	initLabels(ctx)
+
+	mu.Unlock()
+
+	return pruned, saveCatalogCache(ctx)
+}
@@ -0,0 +1,148 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/scm/fake"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// setupPruneTest populates the catalog from a fake provider and returns it for modification
+func setupPruneTest(t *testing.T) (context.Context, *fake.Fake) {
+	t.Helper()
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	providerName := "fake-prune-" + t.Name()
+	viper.Set(config.GitProvider, providerName)
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.CatalogCachePath, filepath.Join(t.TempDir(), "cache.json"))
+
+	provider := testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", Labels: []string{"backend"}},
+		{Name: "repo-2", Project: "test-project", Labels: []string{"backend", "legacy"}},
+		{Name: "repo-3", Project: "test-project", Labels: []string{"frontend"}},
+	}).(*fake.Fake)
+
+	resetCatalogState(t)
+	t.Cleanup(func() { resetCatalogState(t) })
+
+	if err := fetchRepositoryData(ctx); err != nil {
+		t.Fatalf("Failed to fetch repository data: %v", err)
+	}
+
+	return ctx, provider
+}
+
+func TestPrune(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	// repo-2 is deleted upstream between fetches
+	provider.Repositories = slices.Delete(provider.Repositories, 1, 2)
+
+	pruned, err := Prune(ctx, false)
+	testhelper.AssertError(t, err, false)
+	if !slices.Equal(pruned, []string{"test-project/repo-2"}) {
+		t.Errorf("Expected repo-2 to be pruned, got %v", pruned)
+	}
+
+	if _, ok := Catalog["test-project/repo-2"]; ok {
+		t.Error("Expected repo-2 to be removed from the catalog")
+	}
+	if len(Catalog) != 2 {
+		t.Errorf("Expected 2 repositories in catalog, got %d", len(Catalog))
+	}
+
+	// Labels referencing the pruned repo are cleaned up, and emptied labels are dropped
+	if !Labels["backend"].Equal(mapset.NewSet("test-project/repo-1")) {
+		t.Errorf("Expected backend label to contain only repo-1, got %v", Labels["backend"].ToSlice())
+	}
+	if _, ok := Labels["legacy"]; ok {
+		t.Error("Expected empty legacy label to be removed")
+	}
+
+	// The pruned catalog is persisted to the local cache
+	resetCatalogState(t)
+	testhelper.AssertError(t, loadCatalogCache(ctx, time.Hour), false)
+	if _, ok := Catalog["test-project/repo-2"]; ok {
+		t.Error("Expected repo-2 to be removed from the cache")
+	}
+}
+
+func TestPruneRebuildsLabels(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+	config.Viper(ctx).Set(config.RepoAliases, map[string][]string{"old": {"test-project/repo-2"}})
+
+	// repo-2 is deleted and repo-3 loses its label upstream between fetches
+	provider.Repositories = slices.Delete(provider.Repositories, 1, 2)
+	provider.Repositories[1].Labels = []string{"web"}
+
+	_, err := Prune(ctx, false)
+	testhelper.AssertError(t, err, false)
+
+	if _, ok := Labels["frontend"]; ok {
+		t.Errorf("Expected frontend label removed upstream to be dropped, got %v", Labels["frontend"].ToSlice())
+	}
+	if !Labels["web"].Equal(mapset.NewSet("test-project/repo-3")) {
+		t.Errorf("Expected web label to contain repo-3, got %v", Labels["web"])
+	}
+
+	// configured aliases are kept, even if their repositories were pruned
+	if _, ok := Labels["old"]; !ok {
+		t.Error("Expected configured alias to be kept")
+	}
+	if !Labels[config.Viper(ctx).GetString(config.SuperSetLabel)].Equal(mapset.NewSet("test-project/repo-1", "test-project/repo-3")) {
+		t.Errorf("Expected superset label to contain the remaining repositories, got %v", Labels[config.Viper(ctx).GetString(config.SuperSetLabel)])
+	}
+}
+
+func TestPruneDryRun(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	provider.Repositories = provider.Repositories[:2]
+
+	pruned, err := Prune(ctx, true)
+	testhelper.AssertError(t, err, false)
+	if !slices.Equal(pruned, []string{"test-project/repo-3"}) {
+		t.Errorf("Expected repo-3 to be reported, got %v", pruned)
+	}
+
+	// Dry run leaves the catalog and labels untouched
+	if _, ok := Catalog["test-project/repo-3"]; !ok {
+		t.Error("Expected repo-3 to remain in the catalog")
+	}
+	if _, ok := Labels["frontend"]; !ok {
+		t.Error("Expected frontend label to remain")
+	}
+}
+
+func TestPruneNothingStale(t *testing.T) {
+	ctx, _ := setupPruneTest(t)
+
+	pruned, err := Prune(ctx, false)
+	testhelper.AssertError(t, err, false)
+	testhelper.AssertLength(t, pruned, 0)
+	testhelper.AssertLength(t, Catalog, 3)
+}
+
+func TestPruneProviderError(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	provider.SetError("ListRepositories", errors.New("api unavailable"))
+
+	_, err := Prune(ctx, false)
+	testhelper.AssertError(t, err, true)
+
+	// A failed refresh must not prune anything
+	testhelper.AssertLength(t, Catalog, 3)
+}
@@ -0,0 +1,50 @@
+package catalog
+
+import (
+	"context"
+	"maps"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// Refresh refetches the repository catalog from the configured providers regardless of the cache TTL,
+// replacing the catalog and rewriting its local cache. The changes from the previous local cache (regardless of
+// its age) are returned, or from the loaded catalog if there is no readable cache. If the fetch fails, the catalog
+// and its local cache are left unmodified.
+func Refresh(ctx context.Context) (*Changes, error) {
+	live, err := listRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous local cache is the baseline, since the loaded catalog may already hold fresh data (e.g. with --no-save)
+	cached, cacheErr := readCatalogCache(ctx)
+
+	mu.Lock()
+
+	// Capture the previous catalog before flushing it, so that the fresh data can be compared against it
+	previous := maps.Clone(Catalog)
+	if cacheErr == nil {
+		previous = cached.Repositories
+	}
+
+	flush()
+
+	for repoKey, repo := range live {
+		addRepository(repoKey, repo)
+	}
+
+	initLabels(ctx)
+
+	mu.Unlock()
+
+	return compareCatalogs(previous, live), saveCatalogCache(ctx)
+}
+
+// flush clears the catalog and label mappings. The caller must hold mu.
+func flush() {
+	Catalog = make(map[string]scm.Repository)
+	Labels = make(map[string]mapset.Set[string])
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestRefresh(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+	config.Viper(ctx).Set(config.RepoAliases, map[string][]string{"services": {"test-project/repo-4"}})
+
+	// A valid cache exists, so initialization alone keeps the stale catalog
+	resetCatalogState(t)
+	Init(ctx, false)
+	testhelper.AssertLength(t, Catalog, 3)
+
+	// Upstream, repo-2 is deleted, repo-4 is created, and the frontend label of repo-3 is renamed
+	provider.Repositories = []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", Labels: []string{"backend"}},
+		{Name: "repo-3", Project: "test-project", Labels: []string{"web"}},
+		{Name: "repo-4", Project: "test-project"},
+	}
+
+	Init(ctx, false)
+	if _, ok := Catalog["test-project/repo-4"]; ok {
+		t.Fatal("Expected initialization to keep the cached catalog")
+	}
+
+	changes, err := Refresh(ctx)
+	testhelper.AssertError(t, err, false)
+
+	testhelper.AssertEqual(t, strings.Join(changes.Added, ","), "test-project/repo-4")
+	testhelper.AssertEqual(t, strings.Join(changes.Removed, ","), "test-project/repo-2")
+	testhelper.AssertEqual(t, strings.Join(changes.AddedLabels, ","), "web")
+	testhelper.AssertEqual(t, strings.Join(changes.RemovedLabels, ","), "frontend,legacy")
+	testhelper.AssertLength(t, changes.Relabeled, 1)
+
+	// The catalog is replaced rather than merged, and the stale labels are dropped
+	testhelper.AssertLength(t, Catalog, 3)
+	if _, ok := Catalog["test-project/repo-2"]; ok {
+		t.Error("Expected repo-2 to be removed from the catalog")
+	}
+	for _, label := range []string{"frontend", "legacy"} {
+		if _, ok := Labels[label]; ok {
+			t.Errorf("Expected stale label %q to be removed", label)
+		}
+	}
+
+	// Aliases and the superset label are restored for the fresh catalog
+	testhelper.AssertEqual(t, strings.Join(Labels["services"].ToSlice(), ","), "test-project/repo-4")
+	testhelper.AssertEqual(t, Labels[config.Viper(ctx).GetString(config.SuperSetLabel)].Cardinality(), 3)
+
+	// The refreshed catalog is persisted to the local cache
+	resetCatalogState(t)
+	testhelper.AssertError(t, loadCatalogCache(ctx, time.Hour), false)
+
+	names := make([]string, 0, len(Catalog))
+	for name := range Catalog {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	testhelper.AssertEqual(t, strings.Join(names, ","), "test-project/repo-1,test-project/repo-3,test-project/repo-4")
+}
+
+func TestRefreshFlag(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+	config.Viper(ctx).Set(config.CatalogRefresh, true)
+
+	provider.Repositories = append(provider.Repositories, &scm.Repository{Name: "repo-4", Project: "test-project"})
+
+	// The cache hasn't expired, but the refresh flag refetches it anyway
+	resetCatalogState(t)
+	Init(ctx, false)
+
+	testhelper.AssertLength(t, Catalog, 4)
+	if _, ok := Catalog["test-project/repo-4"]; !ok {
+		t.Error("Expected repo-4 to be fetched despite the valid cache")
+	}
+}
+
+func TestRefreshProviderError(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+
+	provider.SetError("ListRepositories", errors.New("api unavailable"))
+
+	_, err := Refresh(ctx)
+	testhelper.AssertError(t, err, true)
+
+	// A failed refresh must not flush the catalog
+	testhelper.AssertLength(t, Catalog, 3)
+	if _, ok := Labels["legacy"]; !ok {
+		t.Error("Expected the legacy label to remain")
+	}
+}
+
+func TestRefreshComparesWithCache(t *testing.T) {
+	ctx, provider := setupPruneTest(t)
+	config.Viper(ctx).Set(config.CatalogNoSave, true)
+
+	provider.Repositories = append(provider.Repositories, &scm.Repository{Name: "repo-4", Project: "test-project"})
+
+	// The loaded catalog already holds the live data, but the cache doesn't
+	resetCatalogState(t)
+	testhelper.AssertError(t, fetchRepositoryData(ctx), false)
+	testhelper.AssertLength(t, Catalog, 4)
+
+	changes, err := Refresh(ctx)
+	testhelper.AssertError(t, err, false)
+	testhelper.AssertEqual(t, strings.Join(changes.Added, ","), "test-project/repo-4")
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// doctorCmd configures the doctor command
+func doctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that batch-tool is ready to run",
+		Long: `Check that batch-tool is ready to run.
+
+These checks are useful before a large batch operation, to find problems with
+the environment or configuration before they cause every repository to fail.`,
+		Example: `  # Verify connectivity and authentication for every configured project
+  batch-tool doctor provider`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(doctorProviderCmd())
+
+	return cmd
+}
+
+// doctorProviderCmd configures the doctor provider command
+func doctorProviderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "provider",
+		Short: "Verify connectivity and authentication with the SCM provider",
+		Long: `Verify connectivity and authentication with the SCM provider.
+
+For each configured project (git.project and git.projects), this command makes
+a cheap authenticated call to the configured SCM provider to look up the current
+user, and reports whether the provider is reachable and the credentials are
+authorized. The command fails if any project fails the check.`,
+		Example: `  # Check the provider for every configured project
+  batch-tool doctor provider`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			providerName := config.Viper(ctx).GetString(config.GitProvider)
+
+			projects := catalog.Projects(ctx)
+			if len(projects) == 0 {
+				return fmt.Errorf("no projects configured; set %s or %s", config.GitProject, config.GitProjects)
+			}
+
+			var failed int
+
+			for _, project := range projects {
+				user, err := scm.Get(ctx, providerName, project).CurrentUser()
+				if err != nil {
+					failed++
+				}
+
+				fmt.Fprint(cmd.OutOrStdout(), formatProviderCheck(project, user, err))
+			}
+
+			if failed > 0 {
+				return call.NewError(fmt.Errorf("%d of %d projects failed the %s provider check", failed, len(projects), providerName))
+			}
+
+			return nil
+		},
+	}
+}
+
+// formatProviderCheck describes the result of the provider check for a project.
+func formatProviderCheck(project, user string, err error) string {
+	if err != nil {
+		// keep each result on a single line, since provider errors may include response bodies
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+
+		return fmt.Sprintf("  ✗ %s: %s\n", project, msg)
+	}
+
+	if user == "" {
+		return fmt.Sprintf("  ✓ %s: reachable and authorized\n", project)
+	}
+
+	return fmt.Sprintf("  ✓ %s: authorized as %s\n", project, user)
+}
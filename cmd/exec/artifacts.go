@@ -0,0 +1,149 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// captureArtifacts returns a Func which runs the given Func and then copies the files matching the globs out of
+// the repository, into a directory for the repository beneath dir. Artifacts are collected even if the command
+// fails, since reports are often most useful then. Globs which match nothing are reported without failing.
+func captureArtifacts(callFunc call.Func, dir string, globs []string) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		runErr := callFunc(ctx, ch)
+
+		if err := collectArtifacts(ctx, ch, dir, globs); err != nil {
+			return errors.Join(runErr, err)
+		}
+
+		return runErr
+	}
+}
+
+// collectArtifacts copies the files matching the globs (relative to the repository) into the artifacts directory
+// of the repository, keeping their paths within the repository. Matched directories are copied recursively.
+func collectArtifacts(ctx context.Context, ch output.Channel, dir string, globs []string) error {
+	repoDir := utils.RepoPath(ctx, ch.Name())
+	dest := artifactsPath(ctx, dir, ch.Name())
+
+	var captured int
+
+	for _, glob := range globs {
+		if err := validateArtifactGlob(glob); err != nil {
+			return err
+		}
+
+		matches, err := filepath.Glob(filepath.Join(repoDir, glob))
+		if err != nil {
+			return fmt.Errorf("invalid artifact glob %q: %w", glob, err)
+		}
+
+		if len(matches) == 0 {
+			fmt.Fprintf(ch, "No artifacts matched %q\n", glob)
+			continue
+		}
+
+		for _, match := range matches {
+			n, err := copyArtifact(repoDir, match, dest)
+			if err != nil {
+				return fmt.Errorf("failed to capture artifact %s: %w", match, err)
+			}
+
+			captured += n
+		}
+	}
+
+	if captured > 0 {
+		fmt.Fprintf(ch, "Captured %d artifact(s) to %s\n", captured, dest)
+	}
+
+	return nil
+}
+
+// validateArtifactGlob returns an error if the glob is malformed, or could match files outside of the repository
+// because it is absolute or escapes the repository with "..".
+func validateArtifactGlob(glob string) error {
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return fmt.Errorf("invalid artifact glob %q: %w", glob, err)
+	}
+
+	if !filepath.IsLocal(glob) {
+		return fmt.Errorf("invalid artifact glob %q: must be relative to the repository and stay within it", glob)
+	}
+
+	return nil
+}
+
+// artifactsPath returns the directory beneath dir for the artifacts of the repository, namespaced by its project
+// and name (and the subdirectory of path-scoped targets).
+func artifactsPath(ctx context.Context, dir, repo string) string {
+	_, project, name := utils.ParseRepo(ctx, repo)
+	_, subdir := utils.SplitTarget(repo)
+
+	return filepath.Join(dir, project, name, filepath.FromSlash(subdir))
+}
+
+// copyArtifact copies the file or directory at src into dest, at its path relative to the root.
+// It returns the number of files copied.
+func copyArtifact(root, src, dest string) (int, error) {
+	var copied int
+
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil // directories are created along with their files, and links aren't followed
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if err := copyFile(path, filepath.Join(dest, rel)); err != nil {
+			return err
+		}
+
+		copied++
+
+		return nil
+	})
+
+	return copied, err
+}
+
+// copyFile copies the contents of the file at src to dest, creating its parent directories as needed.
+func copyFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
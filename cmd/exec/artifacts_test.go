@@ -0,0 +1,110 @@
+package exec
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/call"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestCaptureArtifacts(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+
+	dir := t.TempDir()
+	script := call.Exec("sh", "-c", `mkdir -p reports/nested && basename "$PWD" > reports/nested/result.txt && echo done > run.log`)
+
+	for _, repo := range []string{"repo1", "repo2"} {
+		ch := testhelper.NewMockChannel(repo)
+
+		err := captureArtifacts(script, dir, []string{"reports", "*.log", "missing.xml"})(ctx, ch)
+		if err != nil {
+			t.Fatalf("Expected artifacts to be captured for %s: %v", repo, err)
+		}
+
+		dest := artifactsPath(ctx, dir, repo)
+		testhelper.AssertContains(t, string(ch.Output()), []string{
+			`No artifacts matched "missing.xml"`,
+			"Captured 2 artifact(s) to " + dest,
+		})
+
+		// the artifacts of each repository are kept apart, at their paths within the repository
+		result, err := os.ReadFile(filepath.Join(dest, "reports", "nested", "result.txt"))
+		if err != nil {
+			t.Fatalf("Expected report to be captured for %s: %v", repo, err)
+		}
+
+		testhelper.AssertEqual(t, string(result), repo+"\n")
+
+		if _, err := os.Stat(filepath.Join(dest, "run.log")); err != nil {
+			t.Errorf("Expected log to be captured for %s: %v", repo, err)
+		}
+	}
+}
+
+func TestCaptureArtifactsAfterFailure(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+	dir := t.TempDir()
+	script := call.Exec("sh", "-c", "echo failing > report.txt && exit 3")
+
+	ch := testhelper.NewMockChannel("repo1")
+	err := captureArtifacts(script, dir, []string{"report.txt"})(ctx, ch)
+
+	// the command's failure is returned once its artifacts have been captured
+	testhelper.AssertError(t, err, true)
+
+	var exitErr interface{ ExitCode() int }
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Expected the command's exit error, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(artifactsPath(ctx, dir, "repo1"), "report.txt")); err != nil {
+		t.Errorf("Expected report to be captured after failure: %v", err)
+	}
+}
+
+func TestArtifactsPath(t *testing.T) {
+	ctx := loadFixture(t)
+
+	testhelper.AssertEqual(t, artifactsPath(ctx, "/out", "project/repo1"), filepath.Join("/out", "project", "repo1"))
+	testhelper.AssertEqual(t, artifactsPath(ctx, "/out", "project/mono//services/api"), filepath.Join("/out", "project", "mono", "services", "api"))
+}
+
+func TestValidateArtifactGlobs(t *testing.T) {
+	ctx := loadFixture(t)
+	cmd := Cmd()
+	cmd.SetContext(ctx)
+
+	if err := cmd.ParseFlags([]string{"-c", "true", "--capture-artifacts", "reports/[", "repo1"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	err := validateExecArgs(cmd, nil)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), `invalid artifact glob "reports/["`)
+}
+
+func TestValidateArtifactGlobsEscape(t *testing.T) {
+	tests := []struct {
+		name    string
+		glob    string
+		wantErr bool
+	}{
+		{name: "relative", glob: "reports/*.json", wantErr: false},
+		{name: "parent within repo", glob: "reports/../coverage.out", wantErr: false},
+		{name: "parent directory", glob: "../*", wantErr: true},
+		{name: "nested escape", glob: "reports/../../other/*", wantErr: true},
+		{name: "absolute", glob: "/etc/*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertError(t, validateArtifactGlob(tt.glob), tt.wantErr)
+		})
+	}
+}
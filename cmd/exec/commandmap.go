@@ -0,0 +1,28 @@
+package exec
+
+import (
+	"context"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// mappedExec returns a [call.Func] which runs the shell command mapped to each repository, rendered like -c commands.
+// Path-scoped targets may be mapped individually, falling back to the command of their repository. Repositories
+// without a command are skipped.
+func mappedExec(commands map[string]string) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		command, ok := commands[ch.Name()]
+		if !ok {
+			command, ok = commands[utils.ResolveRepoName(ch.Name())]
+		}
+
+		if !ok {
+			ch.Skip("no command in the command map")
+			return nil
+		}
+
+		return templateExec("sh", "-c", command)(ctx, ch)
+	}
+}
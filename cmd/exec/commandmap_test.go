@@ -0,0 +1,124 @@
+package exec
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestMappedExec(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2", "repo3"})
+
+	exec := mappedExec(map[string]string{
+		"repo1": "echo first",
+		"repo2": "echo second",
+	})
+
+	tests := []struct {
+		repo        string
+		wantOutput  string
+		wantSkipped string
+	}{
+		{repo: "repo1", wantOutput: "first\n"},
+		{repo: "repo2", wantOutput: "second\n"},
+		{repo: "repo3", wantSkipped: "no command in the command map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			ch := testhelper.NewMockChannel(tt.repo)
+
+			if err := exec(ctx, ch); err != nil {
+				t.Fatalf("Expected no error for %s: %v", tt.repo, err)
+			}
+
+			testhelper.AssertEqual(t, string(ch.Output()), tt.wantOutput)
+			testhelper.AssertEqual(t, ch.Skipped(), tt.wantSkipped)
+		})
+	}
+}
+
+func TestShellCmdWithCommandMap(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2", "repo3"})
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.OutputStyle, "native")
+
+	path := filepath.Join(t.TempDir(), "commands.yaml")
+	if err := os.WriteFile(path, []byte("repo1: touch first\nrepo2: touch second\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write command map: %v", err)
+	}
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-y", "-m", path, "repo1", "repo2", "repo3"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	// each repository runs only its own command
+	for repo, files := range map[string][]string{"repo1": {"first"}, "repo2": {"second"}, "repo3": nil} {
+		entries, err := os.ReadDir(utils.RepoPath(ctx, repo))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", repo, err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+
+		testhelper.AssertLength(t, names, len(files))
+
+		if len(files) > 0 {
+			testhelper.AssertContains(t, names, files)
+		}
+	}
+}
+
+func TestShellCmdCommandMapErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.yaml")
+	if err := os.WriteFile(empty, []byte("{}\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write command map: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "with inline command", args: []string{"-m", empty, "-c", "echo test"}, wantErr: "cannot specify --command-map"},
+		{name: "missing file", args: []string{"-y", "-m", filepath.Join(dir, "missing.yaml")}, wantErr: "failed to read command map"},
+		{name: "empty map", args: []string{"-y", "-m", empty}, wantErr: "does not map any repositories"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			cmd := Cmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append(tt.args, "repo1"))
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, true)
+			testhelper.AssertContains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -2,15 +2,24 @@ package exec
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ryclarke/batch-tool/call"
 	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/utils"
 )
 
 const (
@@ -18,12 +27,21 @@ const (
 	scriptFlag = "script"
 	fileFlag   = "file"
 	argsFlag   = "arg"
+	watchFlag  = "watch"
+	mapFlag    = "command-map"
+
+	artifactsFlag    = "capture-artifacts"
+	artifactsDirFlag = "artifacts-dir"
+
+	interactiveFlag = "interactive"
+	timeoutFlag     = "timeout"
+	templateFlag    = "template"
 )
 
 // Cmd configures the exec command
 func Cmd() *cobra.Command {
 	execCmd := &cobra.Command{
-		Use:     "exec {-c <command> | -f <file> [-a <arg>]...} [-y] <repository>...",
+		Use:     "exec {-c <command> | -f <file> [-a <arg>]... | -m <file>} [-y] <repository>...",
 		Aliases: []string{"sh"},
 		Short:   "[!DANGEROUS!] Execute a shell command or file across repositories",
 		Long: `Execute a shell command or file across multiple repositories.
@@ -45,9 +63,65 @@ Command Modes:
     permissions (chmod +x). Supports shell scripts, Python scripts, binaries, etc.
     Arguments can be passed to the file using one or more -a flags.
 
+  Command Map (-m):
+    Execute a different shell command in each repository, read from a YAML or
+    JSON file mapping repository names to commands. Path-scoped targets may be
+    mapped individually, falling back to the command of their repository.
+    Selected repositories without a command in the map are skipped.
+
 Confirmation:
   By default, the command prompts for confirmation before execution, showing the
-  command or file that will be executed. Use -y to skip confirmation.`,
+  command or file that will be executed. Use -y to skip confirmation.
+
+  Use --interactive (-i) to confirm each repository individually instead. Answer
+  "all" to run on every remaining repository without further prompts, or "quit"
+  to abort the remaining repositories. Interactive runs process one repository
+  at a time using the native output style.
+
+Templates:
+  Use --template to render the command, file arguments, and mapped commands as
+  Go templates for each repository, with {{.Repo}}, {{.Project}}, {{.Path}} (of
+  path-scoped targets) and {{.Branch}} (the default branch). Commands are also
+  rendered when a variables file is given with --template-vars, whose variables
+  are available as {{.Vars.<name>}}. Otherwise commands are passed through as
+  written, including templates of their own such as go list -f '{{.Path}}'. To
+  pass literal braces to a rendered command, escape them as {{"{{"}}.
+
+Environment:
+  Each command runs with BATCH_REPO, BATCH_PROJECT and BATCH_BRANCH (the
+  default branch, as in {{.Branch}}) set for its repository. Use --env (-e) KEY=VALUE
+  (repeatable) to set extra variables, or pass the path to an envfile.
+  Malformed --env values are rejected before anything runs.
+
+Artifacts:
+  Use --capture-artifacts with a glob (relative to each repository) to copy
+  the files produced by the command into an output directory afterward, such
+  as reports or coverage files. Each repository's artifacts are collected in
+  <artifacts-dir>/<project>/<repository>, keeping their paths within the
+  repository. Globs must stay within the repository, so absolute paths and
+  ".." are rejected. Artifacts are collected even if the command fails, and
+  globs which match nothing are reported without failing the repository. The
+  directory defaults to exec.artifacts-dir ("artifacts").
+
+Timeout:
+  Use --timeout to kill the command in a repository if it runs longer than the
+  given duration (e.g. 5m), such as a script left waiting on input. The command
+  is killed along with any processes it started, and the repository reports a
+  timeout error while the others continue. The default of 0 (or
+  exec.timeout in your config) means no timeout.
+
+Protected Paths:
+  Commands which appear to target a path matching one of the exec.protected-paths
+  globs in your config (e.g. "go.mod" or ".github/workflows/*") are refused unless
+  --force (-y) is used. Only paths written literally in the command or file
+  arguments are detected, so this is a safety net rather than a guarantee.
+
+Watch Mode:
+  Use --watch to re-run the command each time a file beneath the given path(s)
+  is modified, until interrupted with Ctrl+C. Bursts of changes are coalesced
+  into a single run after exec.watch-debounce (default 500ms) of inactivity.
+  Avoid watching paths which the command itself modifies, or it will re-run
+  after every execution.`,
 		Example: `  # Execute an inline command
   batch-tool exec -c "pwd" repo1 repo2
 
@@ -55,19 +129,63 @@ Confirmation:
   batch-tool exec -f /path/to/exec repo1 repo2
 
   # Execute a script with arguments
-  batch-tool exec -f ./deploy.sh -a prod -a us-east-1 repo1 repo2`,
+  batch-tool exec -f ./deploy.sh -a prod -a us-east-1 repo1 repo2
+
+  # Run a different command in each repository
+  batch-tool exec -m commands.yaml repo1 repo2
+
+  # Pass variables to a script, which can also read $BATCH_REPO
+  batch-tool exec -f ./deploy.sh -e STAGE=prod -e DRY_RUN=1 repo1 repo2
+
+  # Parameterize a command for each repository
+  batch-tool exec --template -c "echo {{.Repo}} on {{.Branch}}" repo1 repo2
+
+  # Parameterize a command with variables from a file
+  batch-tool exec --template-vars vars.yaml -c "echo {{.Repo}} {{.Vars.version}}" repo1 repo2
+
+  # Collect the test reports generated in each repository
+  batch-tool exec -c "go test -json ./... > report.json" --capture-artifacts report.json repo1 repo2
+
+  # Give up on repositories where the command takes longer than 5 minutes
+  batch-tool exec -c "make test" --timeout 5m repo1 repo2
+
+  # Re-run a script whenever it changes
+  batch-tool exec -y -f ./migrate.sh --watch ./migrate.sh repo1 repo2`,
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: catalog.CompletionFunc(),
-		PreRunE:           validateExecArgs,
-		RunE:              runExecCommand,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.Viper(cmd.Context()).BindPFlag(config.ExecArtifactsDir, cmd.Flags().Lookup(artifactsDirFlag)); err != nil {
+				return err
+			}
+
+			if err := config.Viper(cmd.Context()).BindPFlag(config.ExecTimeout, cmd.Flags().Lookup(timeoutFlag)); err != nil {
+				return err
+			}
+
+			if err := config.Viper(cmd.Context()).BindPFlag(config.ExecTemplate, cmd.Flags().Lookup(templateFlag)); err != nil {
+				return err
+			}
+
+			return validateExecArgs(cmd, args)
+		},
+		RunE: runExecCommand,
 	}
 
 	execCmd.Flags().StringP(scriptFlag, "c", "", "shell command to execute")
 	execCmd.Flags().StringP(fileFlag, "f", "", "path to an executable file to run")
 	execCmd.Flags().StringSliceP(argsFlag, "a", nil, "argument(s) to pass with the command (repeatable, requires -f|--file)")
+	execCmd.Flags().StringP(mapFlag, "m", "", "YAML or JSON file mapping repositories to the shell command to execute in each")
 	execCmd.Flags().BoolP(forceFlag, "y", false, "execute command without asking for confirmation")
+	execCmd.Flags().BoolP(interactiveFlag, "i", false, "confirm each repository individually before running the command")
+	execCmd.Flags().StringSlice(watchFlag, nil, "re-run the command when files beneath the given path(s) change")
+	execCmd.Flags().StringSlice(artifactsFlag, nil, "glob of files to copy out of each repository after the command runs (repeatable)")
+	execCmd.Flags().String(artifactsDirFlag, "artifacts", "directory to collect the captured artifacts in, namespaced by repository")
+	execCmd.Flags().Duration(timeoutFlag, 0, "kill the command in a repository if it runs longer than this (0 for no timeout)")
+	execCmd.Flags().Bool(templateFlag, false, "render the command and arguments as Go templates for each repository (e.g. {{.Repo}})")
 
-	return execCmd
+	output.RecordFlags(execCmd, scriptFlag, fileFlag, argsFlag, mapFlag, artifactsFlag, forceFlag, interactiveFlag, timeoutFlag, "env")
+
+	return call.Audited(call.PrintsSelection(execCmd))
 }
 
 // runExecCommand runs the exec command logic based on provided flags
@@ -77,9 +195,32 @@ func runExecCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	interactive, err := cmd.Flags().GetBool(interactiveFlag)
+	if err != nil {
+		return err
+	}
+
+	mapPath, err := cmd.Flags().GetString(mapFlag)
+	if err != nil {
+		return err
+	}
+
+	var commands map[string]string
+	if mapPath != "" {
+		if commands, err = config.LoadCommandMap(mapPath); err != nil {
+			return err
+		}
+	}
+
 	if ok, err := cmd.Flags().GetBool(forceFlag); err != nil {
 		return err
 	} else if !ok {
+		// refuse to run commands which appear to modify protected files
+		scripts := append(append([]string{command}, fileArgs...), slices.Sorted(maps.Values(commands))...)
+		if targets := protectedTargets(cmd.Context(), scripts...); len(targets) > 0 {
+			return fmt.Errorf("command appears to target protected paths: %s; use --%s to run it anyway", strings.Join(targets, ", "), forceFlag)
+		}
+
 		var preview string
 
 		if filePath != "" {
@@ -87,56 +228,192 @@ func runExecCommand(cmd *cobra.Command, args []string) error {
 			if len(fileArgs) > 0 {
 				preview += fmt.Sprintf(", args: %v", fileArgs)
 			}
+		} else if mapPath != "" {
+			preview = fmt.Sprintf("commands for %d repositories from %q", len(commands), mapPath)
 		} else {
 			preview = fmt.Sprintf("`sh -c %q`", command)
 		}
 
-		// DOUBLE CHECK with the user before running anything!
-		confirmed, err := confirmExecution(cmd.InOrStdin(), cmd.ErrOrStderr(), preview)
+		if interactive {
+			// each repository is confirmed individually instead
+			fmt.Fprintf(cmd.ErrOrStderr(), "Executing %s\n", preview)
+		} else {
+			// DOUBLE CHECK with the user before running anything!
+			confirmed, err := confirmExecution(cmd.Context(), cmd.InOrStdin(), cmd.ErrOrStderr(), preview)
+			if err != nil {
+				return err
+			}
+
+			if !confirmed {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Aborting.")
+				return nil
+			}
+		}
+	}
+
+	// Execute inline command via shell evaluation
+	callFunc := templateExec("sh", "-c", command)
+	if filePath != "" {
+		// Execute the file directly (supports both scripts and binaries)
+		callFunc = templateExec(filePath, fileArgs...)
+	} else if commands != nil {
+		// Execute the command mapped to each repository
+		callFunc = mappedExec(commands)
+	}
+
+	// the timeout applies to the command alone, so that artifacts are still captured from a command which timed out
+	callFunc = withTimeout(callFunc, config.Viper(cmd.Context()).GetDuration(config.ExecTimeout))
+
+	globs, err := cmd.Flags().GetStringSlice(artifactsFlag)
+	if err != nil {
+		return err
+	}
+
+	if len(globs) > 0 {
+		// resolve the directory up front, since commands run from within each repository
+		dir, err := filepath.Abs(config.Viper(cmd.Context()).GetString(config.ExecArtifactsDir))
 		if err != nil {
 			return err
 		}
 
-		if !confirmed {
-			fmt.Fprintln(cmd.ErrOrStderr(), "Aborting.")
-			return nil
+		callFunc = captureArtifacts(callFunc, dir, globs)
+	}
+
+	run := func() error {
+		return call.Do(cmd, args, callFunc)
+	}
+
+	if interactive {
+		// prompts share the terminal with the output, so run one repository at a time with streamed output
+		viper := config.Viper(cmd.Context())
+		viper.Set(config.MaxConcurrency, 1)
+		viper.Set(config.OutputStyle, output.Native)
+
+		// prompts are written from the worker while the handler writes the output, so serialize both
+		var mu sync.Mutex
+		cmd.SetOut(lockedWriter{mu: &mu, w: cmd.OutOrStdout()})
+		cmd.SetErr(lockedWriter{mu: &mu, w: cmd.ErrOrStderr()})
+
+		run = func() error {
+			return call.Do(cmd, args, newConfirmer(cmd.InOrStdin(), cmd.ErrOrStderr()).Wrap(callFunc))
 		}
 	}
 
-	// Execute the command or file
-	if filePath != "" {
-		// Execute the file directly (supports both scripts and binaries)
-		return call.Do(cmd, args, call.Exec(filePath, fileArgs...))
+	watchPaths, err := cmd.Flags().GetStringSlice(watchFlag)
+	if err != nil {
+		return err
 	}
 
-	// Execute inline command via shell evaluation
-	return call.Do(cmd, args, call.Exec("sh", "-c", command))
+	if len(watchPaths) > 0 {
+		return call.Watch(cmd, watchPaths, run)
+	}
+
+	return run()
+}
+
+// templateExec returns a [call.ExecGroup] Func which first renders the arguments as templates for each repository.
+func templateExec(command string, arguments ...string) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		rendered := make([]string, len(arguments))
+		for i, arg := range arguments {
+			var err error
+			if rendered[i], err = utils.RenderCommand(ctx, ch.Name(), arg); err != nil {
+				return err
+			}
+		}
+
+		return call.ExecGroup(command, rendered...)(ctx, ch)
+	}
+}
+
+// confirmExecution prompts the user for confirmation and returns true if confirmed. The prompt is rendered from the
+// configured template with the preview of the command, and an empty response is accepted only if configured.
+func confirmExecution(ctx context.Context, in io.Reader, out io.Writer, preview string) (bool, error) {
+	viper := config.Viper(ctx)
+	defaultYes := viper.GetBool(config.ExecConfirmDefault)
+
+	tmpl, err := template.New("prompt").Parse(viper.GetString(config.ExecConfirmPrompt))
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", config.ExecConfirmPrompt, err)
+	}
+
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, struct{ Preview string }{preview}); err != nil {
+		return false, fmt.Errorf("invalid %s: %w", config.ExecConfirmPrompt, err)
+	}
+
+	fmt.Fprintf(out, "%s %s: ", prompt.String(), choices(defaultYes))
+
+	resp, err := readResponse(bufio.NewReader(in), out, false, defaultYes)
+	if err != nil {
+		return false, err
+	}
+
+	return resp == responseYes, nil
+}
+
+// choices returns the yes/no choices of a prompt, capitalizing the choice made by an empty response.
+func choices(defaultYes bool) string {
+	if defaultYes {
+		return "[Y/n]"
+	}
+
+	return "[y/N]"
 }
 
-// confirmExecution prompts the user for confirmation and returns true if confirmed
-func confirmExecution(in io.Reader, out io.Writer, preview string) (bool, error) {
-	fmt.Fprintf(out, "Executing %s\n", preview)
-	fmt.Fprintf(out, "Are you sure? [y/N]: ")
+// response is a parsed answer to a confirmation prompt.
+type response int
 
-	reader := bufio.NewReader(in)
+const (
+	responseNo response = iota
+	responseYes
+	responseAll
+	responseQuit
+)
+
+// readResponse reads lines until a valid response is given, asking again after any invalid input.
+// The "all" and "quit" responses are only accepted if extended is set, and an empty response is taken
+// as "yes" only if defaultYes is set.
+func readResponse(reader *bufio.Reader, out io.Writer, extended, defaultYes bool) (response, error) {
 	for {
 		confirm, err := reader.ReadString('\n')
 		if err != nil {
-			return false, err
+			return responseNo, err
 		}
 
-		switch strings.TrimSpace(strings.ToLower(confirm)) {
-		case "no", "n", "":
-			// User said no (or provided no response)
-			return false, nil
+		switch answer := strings.TrimSpace(strings.ToLower(confirm)); answer {
+		case "":
+			// User provided no response, so the configured default applies
+			if defaultYes {
+				return responseYes, nil
+			}
+
+			return responseNo, nil
+
+		case "no", "n":
+			// User said no, abort execution
+			return responseNo, nil
 
 		case "yes", "y":
 			// User said yes, proceed with execution
-			return true, nil
+			return responseYes, nil
+
+		case "all", "a":
+			if extended {
+				return responseAll, nil
+			}
 
-		default:
-			// The response was invalid, so we ask again
-			fmt.Fprintf(out, "Expected 'yes' ('y') or 'no' ('n') [y/N]: ")
+		case "quit", "q":
+			if extended {
+				return responseQuit, nil
+			}
+		}
+
+		// The response was invalid, so we ask again
+		if extended {
+			fmt.Fprintf(out, "Expected 'yes' ('y'), 'no' ('n'), 'all' ('a') or 'quit' ('q') [y/N/a/q]: ")
+		} else {
+			fmt.Fprintf(out, "Expected 'yes' ('y') or 'no' ('n') %s: ", choices(defaultYes))
 		}
 	}
 }
@@ -152,15 +429,35 @@ func validateExecArgs(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("--%s|-a flags can only be used with --%s|-f", argsFlag, fileFlag)
 	}
 
-	// Check that exactly one of command or file is provided
-	if command == "" && filePath == "" {
-		return fmt.Errorf("no command provided; use the --%s|-c flag to specify a command or --%s|-f to specify a file", scriptFlag, fileFlag)
+	globs, err := cmd.Flags().GetStringSlice(artifactsFlag)
+	if err != nil {
+		return err
+	}
+
+	for _, glob := range globs {
+		if err := validateArtifactGlob(glob); err != nil {
+			return fmt.Errorf("invalid --%s: %w", artifactsFlag, err)
+		}
+	}
+
+	mapPath, err := cmd.Flags().GetString(mapFlag)
+	if err != nil {
+		return err
+	}
+
+	// Check that exactly one of command, file, or command map is provided
+	if command == "" && filePath == "" && mapPath == "" {
+		return fmt.Errorf("no command provided; use the --%s|-c flag to specify a command, --%s|-f to specify a file, or --%s|-m to map repositories to commands", scriptFlag, fileFlag, mapFlag)
 	}
 
 	if command != "" && filePath != "" {
 		return fmt.Errorf("cannot specify both --%s and --%s flags", scriptFlag, fileFlag)
 	}
 
+	if mapPath != "" && (command != "" || filePath != "") {
+		return fmt.Errorf("cannot specify --%s with --%s or --%s", mapFlag, scriptFlag, fileFlag)
+	}
+
 	// If file is provided, verify it is valid for execution
 	if filePath != "" {
 		if err := validateExecFile(filePath); err != nil {
@@ -168,6 +465,11 @@ func validateExecArgs(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	// Fail early on environment variables which would otherwise fail the command in every repository
+	if err := utils.ValidateEnv(config.Viper(cmd.Context()).GetStringSlice(config.CmdEnv)); err != nil {
+		return fmt.Errorf("invalid --env value: %w", err)
+	}
+
 	return nil
 }
 
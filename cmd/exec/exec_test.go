@@ -8,9 +8,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -30,7 +37,7 @@ func TestCmd(t *testing.T) {
 		t.Fatal("Cmd() returned nil")
 	}
 
-	expectedUse := "exec {-c <command> | -f <file> [-a <arg>]...} [-y] <repository>..."
+	expectedUse := "exec {-c <command> | -f <file> [-a <arg>]... | -m <file>} [-y] <repository>..."
 	if cmd.Use != expectedUse {
 		t.Errorf("Expected Use to be '%s', got %s", expectedUse, cmd.Use)
 	}
@@ -86,6 +93,10 @@ func TestShellCmdFlags(t *testing.T) {
 	if forceFlag.Shorthand != "y" {
 		t.Errorf("Expected force flag shorthand to be 'y', got %s", forceFlag.Shorthand)
 	}
+
+	if watchFlag := cmd.Flags().Lookup("watch"); watchFlag == nil {
+		t.Error("watch flag not found")
+	}
 }
 
 func TestShellCmdArgs(t *testing.T) {
@@ -228,7 +239,7 @@ func TestConfirmExecutionBasicResponses(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			var buf bytes.Buffer
-			confirmed, err := confirmExecution(mockStdin(tt.input), &buf, "test preview")
+			confirmed, err := confirmExecution(loadFixture(t), mockStdin(tt.input), &buf, "test preview")
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -257,7 +268,7 @@ func TestConfirmExecutionInvalidInput(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			var buf bytes.Buffer
-			confirmed, err := confirmExecution(mockStdin(tt.input), &buf, "test preview")
+			confirmed, err := confirmExecution(loadFixture(t), mockStdin(tt.input), &buf, "test preview")
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -299,7 +310,7 @@ func TestConfirmExecutionCaseInsensitive(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			var buf bytes.Buffer
-			confirmed, err := confirmExecution(mockStdin(tt.input), &buf, "test preview")
+			confirmed, err := confirmExecution(loadFixture(t), mockStdin(tt.input), &buf, "test preview")
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -328,7 +339,7 @@ func TestConfirmExecutionWithWhitespace(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			var buf bytes.Buffer
-			confirmed, err := confirmExecution(mockStdin(tt.input), &buf, "test preview")
+			confirmed, err := confirmExecution(loadFixture(t), mockStdin(tt.input), &buf, "test preview")
 			if err != nil {
 				t.Errorf("Unexpected error: %v", err)
 			}
@@ -362,6 +373,61 @@ func TestShellCmdExecutionAborted(t *testing.T) {
 	}
 }
 
+func TestShellCmdPrintSelection(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+
+	// the command is neither confirmed nor run
+	cmd.SetArgs([]string{"-c", "touch marker", "--print-selection", "repo1", "repo2"})
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	testhelper.AssertEqual(t, buf.String(), "repo1\nrepo2\n")
+
+	if _, err := os.Stat(filepath.Join(utils.RepoPath(ctx, "repo1"), "marker")); !os.IsNotExist(err) {
+		t.Errorf("Expected the command not to run when printing the selection, got %v", err)
+	}
+}
+
+func TestShellCmdPrintSelectionWithoutCommand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.SortRepos, true)
+
+	labels := catalog.Labels
+	t.Cleanup(func() { catalog.Labels = labels })
+
+	catalog.Labels = map[string]mapset.Set[string]{
+		"backend": mapset.NewSet("repo1", "repo2", "repo3"),
+	}
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+
+	// the selection is printed without the -c, -f, or -m flags needed to run a command
+	args := []string{"~backend", "!repo2"}
+	cmd.SetArgs(append([]string{"--print-selection"}, args...))
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	want := catalog.RepositoryList(ctx, args...).ToSlice()
+	sort.Strings(want)
+
+	testhelper.AssertLength(t, want, 2)
+	testhelper.AssertEqual(t, buf.String(), strings.Join(want, "\n")+"\n")
+}
+
 func TestShellCmdExecutionConfirmed(t *testing.T) {
 	ctx := loadFixture(t)
 	cmd := Cmd()
@@ -423,7 +489,7 @@ func TestConfirmExecutionEOF(t *testing.T) {
 	// Test handling of EOF (e.g., piped input that ends)
 
 	var buf bytes.Buffer
-	_, err := confirmExecution(mockStdin(""), &buf, "test preview")
+	_, err := confirmExecution(loadFixture(t), mockStdin(""), &buf, "test preview")
 	if !errors.Is(err, io.EOF) {
 		t.Errorf("Expected EOF error, got: %v", err)
 	}
@@ -456,7 +522,7 @@ func TestConfirmExecutionPromptContent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			var buf bytes.Buffer
-			_, _ = confirmExecution(mockStdin("n\n"), &buf, tt.preview)
+			_, _ = confirmExecution(loadFixture(t), mockStdin("n\n"), &buf, tt.preview)
 
 			output := buf.String()
 			if !strings.Contains(output, "Executing "+tt.preview) {
@@ -662,7 +728,7 @@ func TestConfirmExecutionWithFilePath(t *testing.T) {
 	preview := `file: "/path/to/script.sh"`
 
 	var buf bytes.Buffer
-	confirmed, err := confirmExecution(mockStdin("y\n"), &buf, preview)
+	confirmed, err := confirmExecution(loadFixture(t), mockStdin("y\n"), &buf, preview)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -681,7 +747,7 @@ func TestConfirmExecutionInlineCommand(t *testing.T) {
 	preview := "`sh -c \"echo test\"`"
 
 	var buf bytes.Buffer
-	confirmed, err := confirmExecution(mockStdin("y\n"), &buf, preview)
+	confirmed, err := confirmExecution(loadFixture(t), mockStdin("y\n"), &buf, preview)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -696,6 +762,55 @@ func TestConfirmExecutionInlineCommand(t *testing.T) {
 	}
 }
 
+func TestConfirmExecutionDefaultYes(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		confirmed bool
+	}{
+		{"empty", "\n", true},
+		{"yes", "y\n", true},
+		{"no", "n\n", false},
+		{"invalid then empty", "maybe\n\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.ExecConfirmDefault, true)
+
+			var buf bytes.Buffer
+			confirmed, err := confirmExecution(ctx, mockStdin(tt.input), &buf, "test preview")
+			testhelper.AssertError(t, err, false)
+			testhelper.AssertEqual(t, confirmed, tt.confirmed)
+			testhelper.AssertContains(t, buf.String(), "Are you sure? [Y/n]:")
+			testhelper.AssertNotContains(t, buf.String(), []string{"[y/N]"})
+		})
+	}
+}
+
+func TestConfirmExecutionCustomPrompt(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.ExecConfirmPrompt, "About to run {{.Preview}} in production. Continue?")
+
+	var buf bytes.Buffer
+	confirmed, err := confirmExecution(ctx, mockStdin("\n"), &buf, "`sh -c \"make deploy\"`")
+	testhelper.AssertError(t, err, false)
+	testhelper.AssertEqual(t, confirmed, false)
+	testhelper.AssertContains(t, buf.String(), "About to run `sh -c \"make deploy\"` in production. Continue? [y/N]: ")
+	testhelper.AssertNotContains(t, buf.String(), []string{"Are you sure?"})
+}
+
+func TestConfirmExecutionInvalidPrompt(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.ExecConfirmPrompt, "Executing {{.Preview")
+
+	var buf bytes.Buffer
+	_, err := confirmExecution(ctx, mockStdin("y\n"), &buf, "test preview")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "invalid "+config.ExecConfirmPrompt)
+}
+
 func TestShellCmdWithFileShowsFileName(t *testing.T) {
 	ctx := loadFixture(t)
 	cmd := Cmd()
@@ -871,6 +986,7 @@ func TestValidateExecFile(t *testing.T) {
 func TestValidateExecArgs(t *testing.T) {
 	t.Run("valid inline command", func(t *testing.T) {
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-c", "echo test", "repo1"})
 		cmd.ParseFlags([]string{"-c", "echo test"})
 
@@ -886,6 +1002,7 @@ func TestValidateExecArgs(t *testing.T) {
 		os.WriteFile(scriptPath, []byte("#!/bin/bash\necho test\n"), 0755)
 
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-f", scriptPath, "repo1"})
 		cmd.ParseFlags([]string{"-f", scriptPath})
 
@@ -897,6 +1014,7 @@ func TestValidateExecArgs(t *testing.T) {
 
 	t.Run("missing both command and file", func(t *testing.T) {
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"repo1"})
 		cmd.ParseFlags([]string{})
 
@@ -915,6 +1033,7 @@ func TestValidateExecArgs(t *testing.T) {
 		os.WriteFile(scriptPath, []byte("#!/bin/bash\necho test\n"), 0755)
 
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-c", "echo test", "-f", scriptPath, "repo1"})
 		cmd.ParseFlags([]string{"-c", "echo test", "-f", scriptPath})
 
@@ -929,6 +1048,7 @@ func TestValidateExecArgs(t *testing.T) {
 
 	t.Run("args without file", func(t *testing.T) {
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-c", "echo test", "-a", "arg1", "repo1"})
 		cmd.ParseFlags([]string{"-c", "echo test", "-a", "arg1"})
 
@@ -947,6 +1067,7 @@ func TestValidateExecArgs(t *testing.T) {
 		os.WriteFile(scriptPath, []byte("#!/bin/bash\necho test\n"), 0755)
 
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-f", scriptPath, "-a", "arg1", "-a", "arg2", "repo1"})
 		cmd.ParseFlags([]string{"-f", scriptPath, "-a", "arg1", "-a", "arg2"})
 
@@ -958,6 +1079,7 @@ func TestValidateExecArgs(t *testing.T) {
 
 	t.Run("nonexistent file", func(t *testing.T) {
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-f", "/nonexistent/file.sh", "repo1"})
 		cmd.ParseFlags([]string{"-f", "/nonexistent/file.sh"})
 
@@ -974,6 +1096,7 @@ func TestValidateExecArgs(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-f", tmpDir, "repo1"})
 		cmd.ParseFlags([]string{"-f", tmpDir})
 
@@ -992,6 +1115,7 @@ func TestValidateExecArgs(t *testing.T) {
 		os.WriteFile(scriptPath, []byte("#!/bin/bash\necho test\n"), 0644)
 
 		cmd := Cmd()
+		cmd.SetContext(loadFixture(t))
 		cmd.SetArgs([]string{"-f", scriptPath, "repo1"})
 		cmd.ParseFlags([]string{"-f", scriptPath})
 
@@ -1064,3 +1188,174 @@ func TestRunExecCommand(t *testing.T) {
 		}
 	})
 }
+
+func TestShellCmdPathScopedTargets(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"monorepo"})
+	repoDir := filepath.Join(reposPath, "example.com", "test-project", "monorepo")
+
+	for _, subdir := range []string{"services/api", "services/worker"} {
+		if err := os.MkdirAll(filepath.Join(repoDir, subdir), 0o755); err != nil {
+			t.Fatalf("Failed to create subdirectory: %v", err)
+		}
+	}
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitDirectory, reposPath)
+	viper.Set(config.GitHost, "example.com")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.OutputStyle, "native")
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-y", "-c", "echo \"running in $(basename $PWD)\"", "monorepo//services/api", "monorepo//services/worker"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("exec failed: %v\n%s", err, buf.String())
+	}
+
+	// Each path-scoped target runs in its own subdirectory and is labeled distinctly in the output
+	testhelper.AssertContains(t, buf.String(), []string{
+		"------ monorepo//services/api ------",
+		"running in api",
+		"------ monorepo//services/worker ------",
+		"running in worker",
+	})
+}
+
+func TestShellCmdWritesAudit(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	config.Viper(ctx).Set(config.AuditPath, path)
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-y", "-c", "echo test", "repo1"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected audit log to be written: %v", err)
+	}
+
+	testhelper.AssertContains(t, string(data), []string{`"command":"exec"`, `"repo":"repo1","success":true`, `"script":"echo test"`, `"force":"true"`})
+}
+
+func TestTemplateExec(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+
+	path := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(path, []byte("version: 1.2.3\nenv:\n  region: us-east-1\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write vars file: %v", err)
+	}
+
+	config.Viper(ctx).Set(config.TemplateVars, path)
+
+	tests := []struct {
+		name string
+		exec func() call.Func
+	}{
+		{
+			name: "inline command",
+			exec: func() call.Func {
+				return templateExec("sh", "-c", "echo {{.Repo}} {{.Vars.version}} {{.Vars.env.region}}")
+			},
+		},
+		{
+			name: "file arguments",
+			exec: func() call.Func {
+				return templateExec("echo", "{{.Repo}}", "{{.Vars.version}}", "{{.Vars.env.region}}")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, repo := range []string{"repo1", "repo2"} {
+				ch := testhelper.NewMockChannel(repo)
+
+				if err := tt.exec()(ctx, ch); err != nil {
+					t.Fatalf("Expected command to succeed for %s: %v", repo, err)
+				}
+
+				testhelper.AssertEqual(t, string(ch.Output()), repo+" 1.2.3 us-east-1\n")
+			}
+		})
+	}
+
+	// undefined variables fail the repository without running the command
+	ch := testhelper.NewMockChannel("repo1")
+	err := templateExec("sh", "-c", "echo {{.Vars.missing}}")(ctx, ch)
+
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertLength(t, ch.Output(), 0)
+}
+
+func TestShellCmdEnv(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+	config.Viper(ctx).Set(config.CmdEnv, []string{"STAGE=prod"})
+
+	cmd := Cmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-y", "--template", "-c", `echo "$BATCH_REPO:$STAGE {{.Repo}}"`, "repo1", "repo2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	// each command sees the variables of its own repository, and the templates render without a vars file when enabled
+	testhelper.AssertContains(t, buf.String(), []string{"repo1:prod repo1", "repo2:prod repo2"})
+}
+
+func TestShellCmdInvalidEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{name: "missing value separator", env: "STAGE", want: `"STAGE" is neither a KEY=VALUE pair nor a readable envfile`},
+		{name: "empty name", env: "=prod", want: `"=prod" has an invalid variable name ""`},
+		{name: "invalid name", env: "MY STAGE=prod", want: `"MY STAGE=prod" has an invalid variable name "MY STAGE"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			testhelper.SetupDirs(t, ctx, []string{"repo1"})
+			config.Viper(ctx).Set(config.CmdEnv, []string{tt.env})
+
+			cmd := Cmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"-y", "-c", "touch marker", "repo1"})
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, true)
+			testhelper.AssertContains(t, err.Error(), []string{"invalid --env value", tt.want})
+
+			// the command is rejected before it runs in any repository
+			if _, err := os.Stat(filepath.Join(utils.RepoPath(ctx, "repo1"), "marker")); err == nil {
+				t.Error("Expected the command not to run with an invalid --env value")
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package exec
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/output"
+)
+
+// errAborted is reported for each repository remaining after the user quits an interactive run.
+var errAborted = errors.New("aborted by user")
+
+// confirmer prompts for confirmation before the command runs on each repository. Responses are
+// read from a single reader shared by every repository in the run.
+type confirmer struct {
+	mu     sync.Mutex
+	reader *bufio.Reader
+	out    io.Writer
+	all    bool
+	quit   bool
+}
+
+// newConfirmer creates a confirmer which reads responses from in and writes prompts to out.
+func newConfirmer(in io.Reader, out io.Writer) *confirmer {
+	return &confirmer{reader: bufio.NewReader(in), out: out}
+}
+
+// Wrap returns a Func which runs the provided Func only after the user confirms the repository.
+// Repositories which are declined are skipped, and after "quit" every remaining repository is aborted.
+func (c *confirmer) Wrap(callFunc call.Func) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		run, err := c.confirm(ch.Name())
+		if err != nil {
+			return err
+		}
+
+		if !run {
+			ch.WriteString("Skipped by user")
+			ch.Skip("declined by user")
+
+			return nil
+		}
+
+		return callFunc(ctx, ch)
+	}
+}
+
+// lockedWriter serializes writes to the underlying writer with a mutex, which may be shared with other
+// writers so that prompts and command output are never interleaved mid-write.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (lw lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	return lw.w.Write(p)
+}
+
+// confirm prompts the user to run on the named repository unless a previous response applies.
+func (c *confirmer) confirm(name string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.quit:
+		return false, errAborted
+	case c.all:
+		return true, nil
+	}
+
+	fmt.Fprintf(c.out, "Run on %s? [y/N/a(ll)/q(uit)]: ", name)
+
+	resp, err := readResponse(c.reader, c.out, true, false)
+	if err != nil {
+		// stop prompting if input is exhausted, rather than failing on every remaining repository
+		c.quit = true
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	switch resp {
+	case responseAll:
+		c.all = true
+	case responseQuit:
+		c.quit = true
+		return false, errAborted
+	}
+
+	return resp != responseNo, nil
+}
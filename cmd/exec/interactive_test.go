@@ -0,0 +1,134 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestConfirmerResponses(t *testing.T) {
+	repos := []string{"repo1", "repo2", "repo3", "repo4"}
+
+	tests := []struct {
+		name        string
+		input       string
+		wantRun     []string
+		wantAborted []string
+		wantRetries int
+	}{
+		{
+			name:    "confirm each repository",
+			input:   "y\nn\nyes\n\n",
+			wantRun: []string{"repo1", "repo3"},
+		},
+		{
+			name:    "all disables further prompts",
+			input:   "n\nall\n",
+			wantRun: []string{"repo2", "repo3", "repo4"},
+		},
+		{
+			name:        "quit aborts remaining repositories",
+			input:       "y\nq\n",
+			wantRun:     []string{"repo1"},
+			wantAborted: []string{"repo2", "repo3", "repo4"},
+		},
+		{
+			name:        "invalid input is retried",
+			input:       "maybe\nA\n",
+			wantRun:     repos,
+			wantRetries: 1,
+		},
+		{
+			name:        "exhausted input aborts remaining repositories",
+			input:       "y\n",
+			wantRun:     []string{"repo1"},
+			wantAborted: []string{"repo3", "repo4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompts bytes.Buffer
+			c := newConfirmer(strings.NewReader(tt.input), &prompts)
+
+			var ran, aborted []string
+			for _, repo := range repos {
+				run, err := c.confirm(repo)
+				if errors.Is(err, errAborted) {
+					aborted = append(aborted, repo)
+				}
+
+				if run {
+					ran = append(ran, repo)
+				}
+			}
+
+			testhelper.AssertEqual(t, strings.Join(ran, ","), strings.Join(tt.wantRun, ","))
+			testhelper.AssertEqual(t, strings.Join(aborted, ","), strings.Join(tt.wantAborted, ","))
+			testhelper.AssertEqual(t, strings.Count(prompts.String(), "Expected 'yes' ('y'), 'no' ('n'), 'all' ('a') or 'quit' ('q')"), tt.wantRetries)
+		})
+	}
+}
+
+func TestConfirmerWrap(t *testing.T) {
+	var prompts bytes.Buffer
+	c := newConfirmer(strings.NewReader("n\ny\n"), &prompts)
+
+	var calls int
+	callFunc := c.Wrap(func(_ context.Context, _ output.Channel) error {
+		calls++
+		return nil
+	})
+
+	skipped := testhelper.NewMockChannel("repo1")
+	testhelper.AssertError(t, callFunc(context.Background(), skipped), false)
+	testhelper.AssertContains(t, string(skipped.Output()), []string{"Skipped by user"})
+
+	confirmed := testhelper.NewMockChannel("repo2")
+	testhelper.AssertError(t, callFunc(context.Background(), confirmed), false)
+
+	testhelper.AssertEqual(t, calls, 1)
+	testhelper.AssertContains(t, prompts.String(), []string{"Run on repo1? [y/N/a(ll)/q(uit)]: ", "Run on repo2?"})
+}
+
+func TestShellCmdInteractive(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo1", "repo2", "repo3"})
+
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.GitDirectory, reposPath)
+	viper.Set(config.GitHost, "example.com")
+	viper.Set(config.GitProject, "test-project")
+
+	cmd := Cmd()
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	cmd.SetIn(strings.NewReader("y\nn\nq\n"))
+	cmd.SetArgs([]string{"--interactive", "-c", "echo \"ran in $(basename $PWD)\"", "repo1", "repo2", "repo3"})
+
+	err := cmd.ExecuteContext(ctx)
+
+	// the aborted repository is reported as a failure
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, errBuf.String(), []string{
+		"Executing `sh -c",
+		"Run on repo1?",
+		"Run on repo2?",
+		"Run on repo3?",
+		"aborted by user",
+	})
+	testhelper.AssertContains(t, buf.String(), []string{"ran in repo1", "Skipped by user"})
+	testhelper.AssertNotContains(t, buf.String()+errBuf.String(), []string{"ran in repo2", "ran in repo3", "Are you sure?"})
+
+	// interactive runs are forced to run one repository at a time with native output
+	testhelper.AssertEqual(t, viper.GetInt(config.MaxConcurrency), 1)
+	testhelper.AssertEqual(t, viper.GetString(config.OutputStyle), output.Native)
+}
@@ -0,0 +1,59 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+// protectedTargets returns the words of the command which appear to refer to one of the configured protected
+// path globs. This is a heuristic safety net rather than a guarantee: paths are only detected if they appear
+// literally in the command (e.g. not if they are computed or referenced from within a script file).
+func protectedTargets(ctx context.Context, words ...string) []string {
+	globs := config.Viper(ctx).GetStringSlice(config.ExecProtectedPaths)
+	if len(globs) == 0 {
+		return nil
+	}
+
+	var targets []string
+
+	for _, word := range words {
+		for _, token := range strings.FieldsFunc(word, isShellSeparator) {
+			token = strings.TrimSuffix(path.Clean(strings.TrimPrefix(token, "./")), "/")
+
+			for _, glob := range globs {
+				if matchesProtected(strings.Trim(glob, "/"), token) {
+					targets = append(targets, fmt.Sprintf("%s (protected by %q)", token, glob))
+					break
+				}
+			}
+		}
+	}
+
+	return targets
+}
+
+// matchesProtected reports whether the token matches the glob, or names a parent directory of the protected paths.
+// Globs without a slash match the file name at any depth, as in a .gitignore file.
+func matchesProtected(glob, token string) bool {
+	if ok, _ := path.Match(glob, token); ok {
+		return true
+	}
+
+	if !strings.Contains(glob, "/") {
+		ok, _ := path.Match(glob, path.Base(token))
+		return ok
+	}
+
+	// e.g. "rm -rf .github" targets the protected path ".github/workflows/*"
+	return token != "." && strings.HasPrefix(glob, token+"/")
+}
+
+// isShellSeparator reports whether the rune separates words in a shell command, treating quotes,
+// redirections, pipes and assignments as separators.
+func isShellSeparator(r rune) bool {
+	return strings.ContainsRune(" \t\n;|&<>()'\"`=,", r)
+}
@@ -0,0 +1,89 @@
+package exec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestProtectedTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		want  []string
+	}{
+		{name: "exact file", words: []string{"rm go.mod"}, want: []string{"go.mod"}},
+		{name: "relative prefix", words: []string{"echo x > ./go.mod"}, want: []string{"go.mod"}},
+		{name: "file name at any depth", words: []string{"sed -i s/a/b/ services/api/go.mod"}, want: []string{"services/api/go.mod"}},
+		{name: "anchored glob", words: []string{`sed -i "s/x/y/" .github/workflows/ci.yml`}, want: []string{".github/workflows/ci.yml"}},
+		{name: "parent directory", words: []string{"rm -rf .github"}, want: []string{".github"}},
+		{name: "file arguments", words: []string{"", "--target", "go.mod"}, want: []string{"go.mod"}},
+		{name: "unprotected paths", words: []string{"cat README.md && ls .github/ISSUE_TEMPLATE/bug.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.ExecProtectedPaths, []string{"go.mod", ".github/workflows/*"})
+
+			got := protectedTargets(ctx, tt.words...)
+			testhelper.AssertLength(t, got, len(tt.want))
+
+			for i, want := range tt.want {
+				if !strings.HasPrefix(got[i], want+" ") {
+					t.Errorf("Expected target %q, got %q", want, got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProtectedTargetsUnconfigured(t *testing.T) {
+	ctx := loadFixture(t)
+
+	testhelper.AssertLength(t, protectedTargets(ctx, "rm go.mod"), 0)
+}
+
+func TestShellCmdProtectedPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		force   bool
+		wantErr bool
+	}{
+		{name: "blocked without force", wantErr: true},
+		{name: "allowed with force", force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.ExecProtectedPaths, []string{"go.mod"})
+			testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+			cmd := Cmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(mockStdin("yes\n"))
+
+			args := []string{"-c", "echo replaced > go.mod", "repo1"}
+			if tt.force {
+				args = append([]string{"--force"}, args...)
+			}
+
+			cmd.SetArgs(args)
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if tt.wantErr {
+				testhelper.AssertContains(t, err.Error(), []string{"protected paths", "go.mod"})
+				testhelper.AssertNotContains(t, buf.String(), []string{"Are you sure?"})
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/output"
+)
+
+// withTimeout returns a Func which runs the given Func with a context that is cancelled after the timeout, killing
+// the command in that repository (along with its process group) while the others continue. A timeout of 0 or less
+// leaves the Func unchanged.
+func withTimeout(callFunc call.Func, timeout time.Duration) call.Func {
+	if timeout <= 0 {
+		return callFunc
+	}
+
+	return func(ctx context.Context, ch output.Channel) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := callFunc(ctx, ch)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command timed out after %v: %w", timeout, err)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,83 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+	config.Viper(ctx).Set(config.SortRepos, false)
+
+	// the command sleeps in a child of the shell, which must be killed along with it
+	callFunc := mappedExec(map[string]string{
+		"repo1": "sleep 10",
+		"repo2": "echo done",
+	})
+
+	var buf bytes.Buffer
+	start := time.Now()
+
+	results, err := call.DoResults(testhelper.FakeCmd(t, ctx, &buf), []string{"repo1", "repo2"}, withTimeout(callFunc, 200*time.Millisecond), output.NativeHandler)
+	testhelper.AssertError(t, err, true)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the command to be killed at the timeout, but the run took %v", elapsed)
+	}
+
+	testhelper.AssertLength(t, results, 2)
+
+	// only the repository which timed out fails, while the other completes
+	if !results[0].Failed() || !strings.Contains(results[0].Err.Error(), "command timed out after 200ms") {
+		t.Errorf("Expected repo1 to report a timeout error, got %v", results[0].Err)
+	}
+
+	testhelper.AssertEqual(t, results[1].Failed(), false)
+	testhelper.AssertContains(t, buf.String(), []string{"done"})
+}
+
+func TestWithTimeoutDisabled(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+	ch := testhelper.NewMockChannel("repo1")
+
+	// a timeout of 0 leaves the command to run to completion
+	if err := withTimeout(templateExec("sh", "-c", "sleep 0.2 && echo finished"), 0)(ctx, ch); err != nil {
+		t.Fatalf("Expected command to succeed without a timeout: %v", err)
+	}
+
+	testhelper.AssertContains(t, string(ch.Output()), []string{"finished"})
+}
+
+func TestWithTimeoutCancelled(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+	// cancelling the run (e.g. on interrupt) still stops the command before the timeout
+	ctx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	ch := testhelper.NewMockChannel("repo1")
+	start := time.Now()
+
+	err := withTimeout(templateExec("sh", "-c", "sleep 10"), time.Minute)(ctx, ch)
+	testhelper.AssertError(t, err, true)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the command to be killed on cancellation, but it took %v", elapsed)
+	}
+
+	if strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a cancelled command not to report a timeout, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
@@ -72,3 +74,89 @@ func Branch(ctx context.Context, ch output.Channel) error {
 
 	return call.Exec("git", "checkout", "-B", branch)(ctx, ch)
 }
+
+// CheckoutBranch returns a Func which checks out the given branch and pushes it to the remote, so that a pull
+// request can be opened from it. The branch is created from the default branch if it doesn't exist locally or
+// on the remote.
+func CheckoutBranch(branch string) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		checkout := []string{"checkout", branch}
+
+		if !refExists(ctx, ch.Name(), "refs/heads/"+branch) && !refExists(ctx, ch.Name(), "refs/remotes/origin/"+branch) {
+			defaultBranch := catalog.GetBranchForRepo(ctx, utils.ResolveRepoName(ch.Name()))
+			if branch == defaultBranch {
+				return fmt.Errorf("refusing to check out %q - it is the default branch", branch)
+			}
+
+			// prefer the remote default branch, which may not be checked out locally
+			start := defaultBranch
+			if refExists(ctx, ch.Name(), "refs/remotes/origin/"+defaultBranch) {
+				start = "origin/" + defaultBranch
+			}
+
+			checkout = []string{"checkout", "--no-track", "-b", branch, start}
+		}
+
+		return call.Wrap(
+			call.Exec("git", checkout...),
+			call.Exec("git", "push", "-u", "origin", branch),
+		)(ctx, ch)
+	}
+}
+
+// refExists reports whether the given fully-qualified ref exists in the repository.
+func refExists(ctx context.Context, repo, ref string) bool {
+	cmd, err := utils.Cmd(ctx, repo, "git", "rev-parse", "--verify", "--quiet", ref)
+	if err != nil {
+		return false
+	}
+
+	return cmd.Run() == nil
+}
+
+// ErrUncommittedChanges is returned by DeleteBranch when the repository has uncommitted changes.
+var ErrUncommittedChanges = errors.New("repository has uncommitted changes")
+
+// ErrUnmergedCommits is returned by DeleteBranch when the local branch differs from the head that was merged.
+var ErrUnmergedCommits = errors.New("local branch differs from the merged head")
+
+// DeleteBranch returns a Func which checks out the default branch and deletes the given local branch. If the SHA of
+// the merged head is given, the branch is deleted even if it has not been merged locally (e.g. after a squash merge on
+// the remote), as long as it still points at that head. Otherwise git's own check for unmerged commits applies.
+// Nothing is changed if the repository has uncommitted changes, or if the branch has commits beyond the merged head.
+func DeleteBranch(branch, head string) call.Func {
+	return func(ctx context.Context, ch output.Channel) error {
+		defaultBranch := catalog.GetBranchForRepo(ctx, utils.ResolveRepoName(ch.Name()))
+		if branch == "" || branch == defaultBranch {
+			return fmt.Errorf("refusing to delete %q - it is the default branch", branch)
+		}
+
+		changes, err := lookupChanges(ctx, ch.Name())
+		if err != nil {
+			return fmt.Errorf("failed to check for uncommitted changes: %w", err)
+		}
+
+		if changes {
+			return ErrUncommittedChanges
+		}
+
+		deleteFlag := "-d"
+		if head != "" {
+			local, err := utils.BranchSHA(ctx, ch.Name(), branch)
+			if err != nil {
+				return err
+			}
+
+			if local != head {
+				return ErrUnmergedCommits
+			}
+
+			deleteFlag = "-D"
+		}
+
+		return call.Wrap(
+			call.Exec("git", "checkout", defaultBranch),
+			call.Exec("git", "branch", deleteFlag, branch),
+		)(ctx, ch)
+	}
+}
@@ -2,9 +2,15 @@ package git
 
 import (
 	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -178,3 +184,72 @@ func TestBranchCommandWithSpecialCharacters(t *testing.T) {
 		t.Errorf("Expected output to contain 'repo-1', got: %s", output)
 	}
 }
+
+func TestDeleteBranch(t *testing.T) {
+	tests := []struct {
+		name       string
+		branch     string
+		dirty      bool
+		ahead      bool // commit to the local branch after its head was merged
+		unknown    bool // the merged head isn't known, so only a fully merged branch is deleted
+		wantErr    error
+		wantBranch string
+		wantExists bool
+	}{
+		{name: "delete feature branch", branch: "feature-branch", wantBranch: "main"},
+		{name: "merged head unknown", branch: "feature-branch", unknown: true, wantBranch: "main"},
+		{name: "uncommitted changes", branch: "feature-branch", dirty: true, wantErr: ErrUncommittedChanges, wantBranch: "feature-branch", wantExists: true},
+		{name: "local branch ahead of merged head", branch: "feature-branch", ahead: true, wantErr: ErrUnmergedCommits, wantBranch: "feature-branch", wantExists: true},
+		{name: "default branch", branch: "main", wantBranch: "feature-branch", wantExists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+			ctx := setupTestGitContext(t, reposPath)
+			repoPath := utils.RepoPath(ctx, "repo-1")
+
+			if tt.dirty {
+				if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte("changed\n"), 0o600); err != nil {
+					t.Fatalf("Failed to modify test file: %v", err)
+				}
+			}
+
+			head, err := utils.BranchSHA(ctx, "repo-1", "feature-branch")
+			if err != nil {
+				t.Fatalf("Failed to lookup head: %v", err)
+			}
+
+			if tt.unknown {
+				head = ""
+			}
+
+			if tt.ahead {
+				testhelper.ExecCommand(t, repoPath, "git", "commit", "--allow-empty", "-m", "Unpushed commit")
+			}
+
+			ch := testhelper.NewMockChannel("repo-1")
+			err = DeleteBranch(tt.branch, head)(ctx, ch)
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			testhelper.AssertError(t, err, tt.wantErr != nil || tt.branch == "main")
+
+			out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+			if err != nil {
+				t.Fatalf("Failed to lookup current branch: %v", err)
+			}
+
+			if branch := strings.TrimSpace(string(out)); branch != tt.wantBranch {
+				t.Errorf("Expected current branch %q, got %q", tt.wantBranch, branch)
+			}
+
+			exists := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/feature-branch").Run() == nil
+			if exists != tt.wantExists {
+				t.Errorf("Expected feature branch to exist: %v, got %v", tt.wantExists, exists)
+			}
+		})
+	}
+}
@@ -50,16 +50,23 @@ pushes, and status checks.`,
 		addUpdateCmd(),
 	)
 
+	output.RecordFlags(gitCmd, branchFlag, messageFlag, amendFlag, pushFlag)
+
 	return gitCmd
 }
 
 // ValidateBranch returns an error if the current git branch is the default branch.
-// If a branch name is provided, it checks against that branch instead.
+// If a non-empty branch name is provided, it checks against that branch instead.
 func ValidateBranch(branch ...string) call.Func {
 	return func(ctx context.Context, ch output.Channel) error {
-		if len(branch) == 0 {
-			// Get the default branch from the catalog if not provided
-			branch = []string{catalog.GetBranchForRepo(ctx, ch.Name())}
+		var base string
+		if len(branch) > 0 {
+			base = branch[0]
+		}
+
+		if base == "" {
+			// Get the default branch of each repository from the catalog if not provided
+			base = catalog.GetBranchForRepo(ctx, ch.Name())
 		}
 
 		cmd, err := utils.Cmd(ctx, ch.Name(), "git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -72,7 +79,7 @@ func ValidateBranch(branch ...string) call.Func {
 			return err
 		}
 
-		if strings.TrimSpace(string(output)) == strings.TrimSpace(branch[0]) {
+		if strings.TrimSpace(string(output)) == strings.TrimSpace(base) {
 			return fmt.Errorf("skipping operation - %s is the base branch", strings.TrimSpace(string(output)))
 		}
 
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// updateAction is what git update would do to bring a repository up to date.
+type updateAction string
+
+const (
+	actionClone    updateAction = "clone"
+	actionFetch    updateAction = "fetch"
+	actionUpToDate updateAction = "up-to-date"
+)
+
+// planUpdate classifies what git update would do for the repository, without modifying it. A repository missing
+// from disk would be cloned, and one whose default branch differs from the remote would be fetched. The remote
+// is only read, using git ls-remote, so nothing is written locally.
+func planUpdate(ctx context.Context, name string) (updateAction, error) {
+	if name != "." {
+		repoName, _ := utils.SplitTarget(name)
+		if _, err := os.Stat(utils.RepoPath(ctx, repoName)); os.IsNotExist(err) {
+			return actionClone, nil
+		}
+	}
+
+	branch := utils.CatalogBranchLookup(ctx, utils.ResolveRepoName(name))
+
+	local, err := gitOutput(ctx, name, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		// the default branch hasn't been checked out locally yet
+		return actionFetch, nil
+	}
+
+	remote, err := gitOutput(ctx, name, "ls-remote", "origin", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from the remote: %w", branch, err)
+	}
+
+	if hash, _, _ := strings.Cut(remote, "\t"); hash != local {
+		return actionFetch, nil
+	}
+
+	return actionUpToDate, nil
+}
+
+// gitOutput runs the git command in the repository and returns its trimmed output.
+func gitOutput(ctx context.Context, name string, args ...string) (string, error) {
+	cmd, err := utils.Cmd(ctx, name, "git", args...)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := cmd.Output()
+
+	return strings.TrimSpace(string(out)), err
+}
+
+// printUpdatePlan reports what git update would do for each repository, grouped by action in the order the
+// repositories would be processed. Repositories which can't be classified are reported with their errors.
+func printUpdatePlan(ctx context.Context, w io.Writer, repos []string) {
+	groups := make(map[updateAction][]string)
+
+	var failed []string
+
+	for _, repo := range repos {
+		action, err := planUpdate(ctx, repo)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", repo, err))
+			continue
+		}
+
+		groups[action] = append(groups[action], repo)
+	}
+
+	for _, group := range []struct {
+		action updateAction
+		header string
+	}{
+		{actionClone, "Would clone"},
+		{actionFetch, "Would fetch and update"},
+		{actionUpToDate, "Already up to date"},
+	} {
+		if repos := groups[group.action]; len(repos) > 0 {
+			fmt.Fprintf(w, "%s (%d):\n  %s\n", group.header, len(repos), strings.Join(repos, "\n  "))
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "Unable to check (%d):\n  %s\n", len(failed), strings.Join(failed, "\n  "))
+	}
+}
@@ -12,6 +12,7 @@ import (
 const (
 	stashFlag   = "stash"
 	noStashFlag = "no-" + stashFlag
+	dryRunFlag  = "dry-run"
 )
 
 func addUpdateCmd() *cobra.Command {
@@ -33,6 +34,11 @@ WARNING: Without stash enabled, any uncommitted changes (staged or unstaged)
 will be destroyed during the update process. Use --stash or set git.stash-updates
 in your config to preserve your work.
 
+Repositories missing from disk are cloned first. Use the --dry-run flag to
+report which repositories would be cloned, which would be fetched, and which
+are already up to date, without cloning or changing anything. Only the remote
+default branch is read (using git ls-remote) to compare with the local one.
+
 The default branch name is determined from the repository catalog
 configuration, which typically reads it from the git repository's
 HEAD reference or uses a configured default.`,
@@ -43,13 +49,29 @@ HEAD reference or uses a configured default.`,
   batch-tool git update ~all
 
   # Update with automatic stash/restore of uncommitted changes
-  batch-tool git update --stash repo1 repo2`,
+  batch-tool git update --stash repo1 repo2
+
+  # Preview which repositories would be cloned or fetched
+  batch-tool git update --dry-run ~all`,
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: catalog.CompletionFunc(),
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			return utils.BindBoolFlags(cmd, config.StashUpdates, stashFlag, noStashFlag)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRun, err := cmd.Flags().GetBool(dryRunFlag); err != nil {
+				return err
+			} else if dryRun {
+				repos, err := call.SelectRepositories(cmd.Context(), args)
+				if err != nil {
+					return err
+				}
+
+				printUpdatePlan(cmd.Context(), cmd.OutOrStdout(), repos)
+
+				return nil
+			}
+
 			if config.Viper(cmd.Context()).GetBool(config.StashUpdates) {
 				return call.Do(cmd, args, call.Wrap(StashPush, Update, StashPop))
 			}
@@ -59,6 +81,7 @@ HEAD reference or uses a configured default.`,
 	}
 
 	utils.BuildBoolFlags(updateCmd, stashFlag, "", noStashFlag, "", "Automatically stash and restore uncommitted changes during update")
+	updateCmd.Flags().Bool(dryRunFlag, false, "report which repositories would be cloned or fetched without changing them")
 
 	return updateCmd
 }
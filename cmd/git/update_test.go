@@ -3,6 +3,7 @@ package git
 import (
 	"bytes"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -274,3 +275,45 @@ func TestUpdateWithConfigStash(t *testing.T) {
 		t.Errorf("Expected restored content to match original")
 	}
 }
+
+func TestUpdateDryRun(t *testing.T) {
+	// repo-2 has an unpushed commit, so its default branch differs from the remote
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"})
+	ctx := setupTestGitContext(t, reposPath)
+
+	repo2 := filepath.Join(reposPath, "example.com", "test-project", "repo-2")
+	refs := func() string {
+		out, err := exec.Command("git", "-C", repo2, "rev-parse", "HEAD", "refs/remotes/origin/main").Output()
+		if err != nil {
+			t.Fatalf("Failed to read refs: %v", err)
+		}
+
+		return string(out)
+	}
+
+	before := refs()
+
+	cmd := addUpdateCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--dry-run", "repo-1", "repo-2", "repo-3"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Would clone (1):\n  repo-3\n",
+		"Would fetch and update (1):\n  repo-2\n",
+		"Already up to date (1):\n  repo-1\n",
+	})
+
+	// nothing is cloned or fetched
+	if _, err := os.Stat(filepath.Join(reposPath, "example.com", "test-project", "repo-3")); !os.IsNotExist(err) {
+		t.Errorf("Expected repo-3 not to be cloned, got %v", err)
+	}
+
+	testhelper.AssertEqual(t, refs(), before)
+}
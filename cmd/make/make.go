@@ -61,6 +61,8 @@ Synchronous vs Concurrent:
 
 	makeCmd.Flags().StringSliceP(targetFlag, "t", nil, "make target(s), can be specified multiple times")
 
+	output.RecordFlags(makeCmd, targetFlag)
+
 	return makeCmd
 }
 
@@ -0,0 +1,98 @@
+package pr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const codeownersFlag = "reviewers-from-codeowners"
+
+// buildCodeownersFlags adds the flag for requesting reviewers from each repository's CODEOWNERS file.
+func buildCodeownersFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(codeownersFlag, false, "request reviews from the CODEOWNERS of the changed files")
+}
+
+// parseCodeownersFlags binds the CODEOWNERS flag to its configuration key.
+func parseCodeownersFlags(cmd *cobra.Command) {
+	config.Viper(cmd.Context()).BindPFlag(config.PrCodeowners, cmd.Flags().Lookup(codeownersFlag))
+}
+
+// codeownersReviewers returns the individual and team reviewers listed in the repository's CODEOWNERS file
+// for the files changed between the base branch and HEAD. If the base branch is unknown, all owners in the
+// file are returned. A repository without a CODEOWNERS file has no owners.
+func codeownersReviewers(ctx context.Context, repo, base string) (reviewers, teams []string, err error) {
+	co, err := loadCodeowners(utils.RepoPath(ctx, repo))
+	if err != nil || co == nil {
+		return nil, nil, err
+	}
+
+	files, err := changedFiles(ctx, repo, base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reviewers, teams = scm.SplitOwners(co.Owners(files...))
+
+	return reviewers, teams, nil
+}
+
+// loadCodeowners parses the first CODEOWNERS file found in the repository, returning nil if there is none.
+func loadCodeowners(repoPath string) (*scm.Codeowners, error) {
+	for _, path := range scm.CodeownersPaths {
+		file, err := os.Open(filepath.Join(repoPath, path))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		defer file.Close()
+
+		return scm.ParseCodeowners(file)
+	}
+
+	return nil, nil
+}
+
+// changedFiles lists the files changed on the current branch relative to the base branch,
+// returning nil if the base branch is unknown.
+func changedFiles(ctx context.Context, repo, base string) ([]string, error) {
+	if base == "" {
+		return nil, nil
+	}
+
+	cmd, err := utils.Cmd(ctx, repo, "git", "diff", "--name-only", base+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files changed against %s: %w", base, err)
+	}
+
+	return strings.FieldsFunc(string(out), func(r rune) bool { return r == '\n' }), nil
+}
+
+// mergeReviewers appends the extra reviewers to the given list, omitting duplicates.
+func mergeReviewers(reviewers, extra []string) []string {
+	set := mapset.NewSet(reviewers...)
+	for _, reviewer := range extra {
+		if set.Add(reviewer) {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+
+	return reviewers
+}
@@ -0,0 +1,55 @@
+package pr
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+)
+
+const yesFlag = "yes"
+
+// buildConfirmFlags adds the flag to skip the batch size confirmation to the command.
+func buildConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP(yesFlag, "y", false, "skip the confirmation for selections above pr.confirm-threshold")
+}
+
+// confirmBatchSize asks for confirmation before acting on more repositories than the configured threshold,
+// to guard against accidentally selecting far more repositories than intended. It returns true if the
+// command should proceed, which it always does at or below the threshold or when --yes is given.
+func confirmBatchSize(cmd *cobra.Command, args []string, action string) (bool, error) {
+	threshold := config.Viper(cmd.Context()).GetInt(config.PrConfirmThreshold)
+	if threshold <= 0 {
+		return true, nil
+	}
+
+	if yes, err := cmd.Flags().GetBool(yesFlag); err != nil || yes {
+		return yes, err
+	}
+
+	count := len(catalog.RepositoryNames(cmd.Context(), args...))
+	if count <= threshold {
+		return true, nil
+	}
+
+	out := cmd.ErrOrStderr()
+	fmt.Fprintf(out, "About to %s in %d repositories (more than %s: %d)\n", action, count, config.PrConfirmThreshold, threshold)
+	fmt.Fprintf(out, "Are you sure? [y/N]: ")
+
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && answer == "" {
+		return false, fmt.Errorf("failed to read confirmation (use --%s to skip it): %w", yesFlag, err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		fmt.Fprintln(out, "Aborting.")
+		return false, nil
+	}
+}
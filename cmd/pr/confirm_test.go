@@ -0,0 +1,122 @@
+package pr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestConfirmBatchSize(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+
+	tests := []struct {
+		name       string
+		threshold  int
+		args       []string
+		input      string
+		wantPrompt bool
+		wantMerged bool
+	}{
+		{
+			name:       "under threshold",
+			threshold:  2,
+			args:       []string{"repo-1", "repo-2"},
+			wantMerged: true,
+		},
+		{
+			name:       "over threshold confirmed",
+			threshold:  1,
+			args:       []string{"repo-1", "repo-2"},
+			input:      "y\n",
+			wantPrompt: true,
+			wantMerged: true,
+		},
+		{
+			name:       "over threshold declined",
+			threshold:  1,
+			args:       []string{"repo-1", "repo-2"},
+			input:      "n\n",
+			wantPrompt: true,
+		},
+		{
+			name:       "over threshold without an answer",
+			threshold:  1,
+			args:       []string{"repo-1", "repo-2"},
+			wantPrompt: true,
+		},
+		{
+			name:       "over threshold with yes flag",
+			threshold:  1,
+			args:       []string{"-y", "repo-1", "repo-2"},
+			wantMerged: true,
+		},
+		{
+			name:       "disabled threshold",
+			args:       []string{"repo-1", "repo-2"},
+			wantMerged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+			config.Viper(ctx).Set(config.PrConfirmThreshold, tt.threshold)
+
+			for _, repo := range []string{"repo-1", "repo-2"} {
+				if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+					t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+				}
+			}
+
+			cmd := addMergeCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, tt.wantPrompt && tt.input == "")
+
+			if tt.wantPrompt {
+				testhelper.AssertContains(t, buf.String(), "About to merge pull requests in 2 repositories (more than pr.confirm-threshold: 1)")
+			} else {
+				testhelper.AssertNotContains(t, buf.String(), []string{"About to merge pull requests"})
+			}
+
+			for _, repo := range []string{"repo-1", "repo-2"} {
+				testhelper.AssertEqual(t, provider.HasPullRequest(repo, "feature-branch"), !tt.wantMerged)
+			}
+		})
+	}
+}
+
+func TestNewCommandConfirmBatchSize(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+	config.Viper(ctx).Set(config.PrConfirmThreshold, 1)
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetIn(strings.NewReader("no\n"))
+	cmd.SetArgs([]string{"-t", "Test Title", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"About to open pull requests in 2 repositories", "Aborting."})
+
+	// no pull requests are opened once declined
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		testhelper.AssertEqual(t, provider.HasPullRequest(repo, "feature-branch"), false)
+	}
+}
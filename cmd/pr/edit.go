@@ -3,6 +3,8 @@ package pr
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -16,12 +18,15 @@ import (
 
 const (
 	resetReviewersFlag = "reset-reviewers"
+	resetLabelsFlag    = "reset-labels"
+	editDryRunFlag     = "dry-run"
+	onlyIfTitleFlag    = "only-if-title"
 )
 
 // addEditCmd initializes the pr edit command
 func addEditCmd() *cobra.Command {
 	editCmd := &cobra.Command{
-		Use:   "edit [-t <title>] [-d <description>] [-r <reviewer>]... [--reset-reviewers] <repository>...",
+		Use:   "edit [-t <title>] [-d <description>] [-r <reviewer>]... [--reset-reviewers] [-L <label>]... [--reset-labels] [--draft | --no-draft] <repository>...",
 		Short: "Update existing pull requests",
 		Long: `Update existing pull requests for the current branch.
 
@@ -31,6 +36,29 @@ provider API. You can update one or more of the following properties:
   - Description
   - Reviewers
   - Team Reviewers
+  - Assignees (--assign-me)
+  - Labels (-L, replacing the existing labels with --reset-labels)
+  - Draft status (--draft to convert to a draft, --no-draft to mark as ready for review)
+
+Dry Run:
+  Use --dry-run to print the title and description changes and exactly which
+  reviewers and team reviewers would be added or removed, without updating
+  the pull requests.
+
+Title Guard:
+  Use --only-if-title to update only the pull requests whose current title
+  contains the given text (case-insensitive). Pull requests with other titles,
+  such as ones opened by bots on the same branch name, are skipped and
+  reported without failing the batch.
+
+Force-Push Detection:
+  Use --check-head (or set pr.check-head) to warn before updating a pull
+  request whose head differs from the local branch, such as after the branch
+  was force-pushed or rewritten. The pull request is still updated.
+
+Templates:
+  When a variables file is given with --template-vars, the title and
+  description are rendered as Go templates for each repository.
 
 Branch Requirement:
   Must be on a feature branch with an existing PR.`,
@@ -41,18 +69,39 @@ Branch Requirement:
   batch-tool pr edit -r charlie repo1
 
   # Replace existing reviewers with new list
-  batch-tool pr edit -r alice -r bob --reset-reviewers repo1`,
+  batch-tool pr edit -r alice -r bob --reset-reviewers repo1
+
+  # Mark draft PRs as ready for review
+  batch-tool pr edit --no-draft repo1 repo2
+
+  # Replace the labels on the PRs
+  batch-tool pr edit -L needs-triage --reset-labels repo1 repo2
+
+  # Preview which reviewers would be added and removed
+  batch-tool pr edit -r alice --reset-reviewers --dry-run repo1
+
+  # Only update the PRs opened for a particular change
+  batch-tool pr edit --only-if-title "bump go version" -r alice ~backend`,
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: catalog.CompletionFunc(),
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			viper := config.Viper(cmd.Context())
 
 			viper.BindPFlag(config.PrResetReviewers, cmd.Flags().Lookup(resetReviewersFlag))
+			viper.BindPFlag(config.PrResetLabels, cmd.Flags().Lookup(resetLabelsFlag))
+			viper.BindPFlag(config.PrDryRun, cmd.Flags().Lookup(editDryRunFlag))
+			viper.BindPFlag(config.PrEditTitleGuard, cmd.Flags().Lookup(onlyIfTitleFlag))
+			viper.BindPFlag(config.PrCheckHead, cmd.Flags().Lookup(checkHeadFlag))
 
 			return parseCommonPRFlags(cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveCurrentUser(cmd.Context()); err != nil {
+				return err
+			}
+
 			buildPROptions(cmd)
+			buildPoolAssignment(cmd.Context(), args)
 
 			return call.Do(cmd, args, Edit)
 		},
@@ -60,6 +109,10 @@ Branch Requirement:
 
 	buildCommonPRFlags(editCmd)
 	editCmd.Flags().Bool(resetReviewersFlag, false, "replace the reviewer list instead of appending to it")
+	editCmd.Flags().Bool(resetLabelsFlag, false, "replace the label list instead of appending to it")
+	editCmd.Flags().Bool(editDryRunFlag, false, "show the changes that would be made without updating the pull requests")
+	editCmd.Flags().String(onlyIfTitleFlag, "", "only update pull requests whose current title contains this text (case-insensitive)")
+	buildCheckHeadFlag(editCmd)
 
 	return editCmd
 }
@@ -73,23 +126,134 @@ func Edit(ctx context.Context, ch output.Channel) error {
 	project := catalog.GetProjectForRepo(ctx, repoName)
 	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
 
-	branch, err := utils.LookupBranch(ctx, ch.Name())
-	if err != nil {
-		return fmt.Errorf("failed to lookup branch for %s: %w", repoName, err)
-	}
+	branch := lookupBranch(ctx, ch.Name())
 
 	// load PR options from config
 	opts := prOptions(ctx, repoName, false)
+	if err := renderPROptions(ctx, ch.Name(), &opts); err != nil {
+		return err
+	}
+
+	if len(opts.Reviewers) == 0 {
+		opts.Reviewers = poolReviewers(ctx, repoName)
+	}
+
+	opts.Reviewers = addCurrentReviewer(ctx, opts.Reviewers)
+
+	// add the team reviewers required by the repository's label policies unless overridden by flags
+	if len(opts.TeamReviewers) == 0 {
+		policy, err := lookupPolicy(ctx, repoName)
+		if err != nil {
+			return err
+		}
+
+		opts.TeamReviewers = policy.TeamReviewers
+	}
+
 	if err := provider.CheckCapabilities(&opts); err != nil {
 		return err
 	}
 
+	// fetch the existing pull request when it must be inspected before updating
+	var existing *scm.PullRequest
+	if guard := viper.GetString(config.PrEditTitleGuard); guard != "" || viper.GetBool(config.PrDryRun) {
+		pr, err := provider.GetPullRequest(repoName, branch)
+		if err != nil {
+			return err
+		}
+
+		if guard != "" && len(matchTitles([]*scm.PullRequest{pr}, guard)) == 0 {
+			reason := fmt.Sprintf("title of PR #%d does not contain %q", pr.Number, guard)
+			fmt.Fprintf(ch, "Skipped pull request (PR #%d) %s: title does not contain %q\n", pr.Number, pr.Title, guard)
+			ch.Skip(reason)
+
+			return nil
+		}
+
+		existing = pr
+	}
+
+	warnDivergedHead(ctx, ch, provider, repoName, branch)
+
+	if viper.GetBool(config.PrDryRun) {
+		fmt.Fprint(ch, previewEdit(existing, &opts))
+
+		return nil
+	}
+
 	pr, err := provider.UpdatePullRequest(repoName, branch, &opts)
 	if err != nil {
 		return err
 	}
 
+	ch.SetPullRequest(pr)
 	fmt.Fprint(ch, printPRInfo(pr, "Updated pull request", false))
 
 	return nil
 }
+
+// reviewerChanges lists the individual and team reviewers that applying the options would add to or remove from the pull request.
+type reviewerChanges struct {
+	AddReviewers, RemoveReviewers []string
+	AddTeams, RemoveTeams         []string
+}
+
+// diffReviewerChanges computes the reviewer changes between the pull request and the options, matching the behavior of
+// UpdatePullRequest: reviewers are only removed when resetting, and unspecified reviewer lists are left unchanged.
+func diffReviewerChanges(pr *scm.PullRequest, opts *scm.PROptions) reviewerChanges {
+	var changes reviewerChanges
+
+	if len(opts.Reviewers) > 0 {
+		changes.AddReviewers, changes.RemoveReviewers = scm.DiffReviewers(pr.Reviewers, opts.Reviewers, opts.ResetReviewers)
+	}
+
+	if len(opts.TeamReviewers) > 0 {
+		changes.AddTeams, changes.RemoveTeams = scm.DiffReviewers(pr.TeamReviewers, opts.TeamReviewers, opts.ResetReviewers)
+	}
+
+	return changes
+}
+
+// previewEdit describes the changes that applying the options would make to the pull request.
+func previewEdit(pr *scm.PullRequest, opts *scm.PROptions) string {
+	var info strings.Builder
+
+	fmt.Fprintf(&info, "Would update pull request (PR #%d) %s\n", pr.Number, pr.Title)
+	header := info.Len()
+
+	if opts.Title != "" && opts.Title != pr.Title {
+		fmt.Fprintf(&info, "  title: %q → %q\n", pr.Title, opts.Title)
+	}
+
+	if opts.Description != "" && opts.Description != pr.Description {
+		fmt.Fprintln(&info, "  description: updated")
+	}
+
+	changes := diffReviewerChanges(pr, opts)
+	writeChange(&info, "add reviewers", changes.AddReviewers)
+	writeChange(&info, "remove reviewers", changes.RemoveReviewers)
+	writeChange(&info, "add team reviewers", changes.AddTeams)
+	writeChange(&info, "remove team reviewers", changes.RemoveTeams)
+
+	assignees, _ := scm.DiffReviewers(pr.Assignees, opts.Assignees, false)
+	writeChange(&info, "add assignees", assignees)
+
+	if len(opts.Labels) > 0 || opts.ResetLabels {
+		addLabels, removeLabels := scm.DiffReviewers(pr.Labels, opts.Labels, opts.ResetLabels)
+		writeChange(&info, "add labels", addLabels)
+		writeChange(&info, "remove labels", removeLabels)
+	}
+
+	if info.Len() == header {
+		fmt.Fprintln(&info, "  no changes")
+	}
+
+	return info.String()
+}
+
+// writeChange writes a labelled list of names, if there are any.
+func writeChange(w io.Writer, label string, names []string) {
+	if len(names) > 0 {
+		fmt.Fprintf(w, "  %s: %s\n", label, strings.Join(names, ", "))
+	}
+}
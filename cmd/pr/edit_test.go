@@ -2,8 +2,15 @@ package pr
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
@@ -142,3 +149,220 @@ func TestEditCommandRunPRNotFound(t *testing.T) {
 		t.Errorf("Expected error message to contain 'pull request not found', got: %s", output)
 	}
 }
+
+func TestEditCommandRunWithLabelPolicy(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Updated PR Title")
+	viper.Set(config.LabelPolicies, map[string]any{
+		"infra": map[string]any{"team-reviewers": []string{"infra-team"}},
+	})
+
+	catalog.Labels["infra"] = mapset.NewSet("repo-1")
+	t.Cleanup(func() { delete(catalog.Labels, "infra") })
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Original Title"}); err != nil {
+			t.Fatalf("Failed to create test PR: %v", err)
+		}
+	}
+
+	cmd := addEditCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	for repo, want := range map[string]bool{"repo-1": true, "repo-2": false} {
+		pr, err := provider.GetPullRequest(repo, "feature-branch")
+		if err != nil {
+			t.Fatalf("Expected PR for %s: %v", repo, err)
+		}
+
+		if got := slices.Contains(pr.TeamReviewers, "infra-team"); got != want {
+			t.Errorf("Expected infra-team requested for %s: %v, got %v", repo, want, pr.TeamReviewers)
+		}
+	}
+}
+
+func TestDiffReviewerChanges(t *testing.T) {
+	pr := &scm.PullRequest{
+		Reviewers:     []string{"alice", "bob"},
+		TeamReviewers: []string{"org/backend", "org/platform"},
+	}
+
+	opts := &scm.PROptions{
+		Reviewers:      []string{"bob", "carol"},
+		TeamReviewers:  []string{"org/platform", "org/security"},
+		ResetReviewers: true,
+	}
+
+	want := reviewerChanges{
+		AddReviewers:    []string{"carol"},
+		RemoveReviewers: []string{"alice"},
+		AddTeams:        []string{"org/security"},
+		RemoveTeams:     []string{"org/backend"},
+	}
+
+	if got := diffReviewerChanges(pr, opts); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected changes %+v, got %+v", want, got)
+	}
+
+	// Without resetting, reviewers are only added
+	opts.ResetReviewers = false
+	want.RemoveReviewers, want.RemoveTeams = nil, nil
+
+	if got := diffReviewerChanges(pr, opts); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected changes %+v, got %+v", want, got)
+	}
+}
+
+func TestEditCommandDryRun(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Original Title", Reviewers: []string{"alice", "bob"}}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	// Any attempt to update the pull request fails the test run
+	provider.Errors["UpdatePullRequest"] = errors.New("unexpected update")
+
+	cmd := addEditCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--dry-run", "--reset-reviewers", "-t", "New Title", "-r", "bob", "-r", "carol", "repo-1"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Would update pull request",
+		`title: "Original Title" → "New Title"`,
+		"add reviewers: carol",
+		"remove reviewers: alice",
+	})
+
+	pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR: %v", err)
+	}
+
+	if pr.Title != "Original Title" || !reflect.DeepEqual(pr.Reviewers, []string{"alice", "bob"}) {
+		t.Errorf("Expected dry run to leave the PR unchanged, got %q %v", pr.Title, pr.Reviewers)
+	}
+}
+
+func TestEditCommandLabels(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantLabels []string
+		wantOutput []string
+	}{
+		{
+			name:       "append labels",
+			args:       []string{"-L", "needs-triage", "-L", "bug", "repo-1"},
+			wantLabels: []string{"bug", "needs-triage"},
+		},
+		{
+			name:       "reset labels",
+			args:       []string{"-L", "needs-triage", "--reset-labels", "repo-1"},
+			wantLabels: []string{"needs-triage"},
+		},
+		{
+			name:       "dry run",
+			args:       []string{"--dry-run", "-L", "needs-triage", "--reset-labels", "repo-1"},
+			wantLabels: []string{"bug"},
+			wantOutput: []string{"add labels: needs-triage", "remove labels: bug"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+
+			if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Title", Labels: []string{"bug"}}); err != nil {
+				t.Fatalf("Failed to create test PR: %v", err)
+			}
+
+			cmd := addEditCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			testhelper.AssertContains(t, buf.String(), tt.wantOutput)
+
+			pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+			if err != nil {
+				t.Fatalf("Expected PR: %v", err)
+			}
+
+			labels := slices.Sorted(slices.Values(pr.Labels))
+			testhelper.AssertEqual(t, strings.Join(tt.wantLabels, ","), strings.Join(labels, ","))
+		})
+	}
+}
+
+func TestEditCommandOnlyIfTitle(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Bump Go version"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	// An unrelated pull request on the same branch name, such as one opened by a bot
+	if _, err := provider.OpenPullRequest("repo-2", "feature-branch", &scm.PROptions{Title: "Bump lodash from 4.17.20 to 4.17.21"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addEditCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--only-if-title", "bump go", "-t", "Bump Go to 1.26", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Updated pull request",
+		`Bump lodash from 4.17.20 to 4.17.21: title does not contain "bump go"`,
+		"Skipped:",
+	})
+
+	updated, err := provider.GetPullRequest("repo-1", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR: %v", err)
+	}
+
+	testhelper.AssertEqual(t, updated.Title, "Bump Go to 1.26")
+
+	skipped, err := provider.GetPullRequest("repo-2", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR: %v", err)
+	}
+
+	testhelper.AssertEqual(t, skipped.Title, "Bump lodash from 4.17.20 to 4.17.21")
+}
@@ -0,0 +1,86 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// commit is the message of a single commit, split into its subject and body.
+type commit struct {
+	Subject string
+	Body    string
+}
+
+// fillPROptions derives the title and description of the pull request from the commits on the current branch
+// which aren't on the base branch, leaving any title or description which was explicitly provided. The title
+// is the subject of the latest commit, and the description is the body of a single commit or a list of the
+// subjects of multiple commits. Nothing is filled if the branch has no commits of its own.
+func fillPROptions(ctx context.Context, name string, opts *scm.PROptions) error {
+	commits, err := branchCommits(ctx, name, opts.BaseBranch)
+	if err != nil {
+		return err
+	}
+
+	if len(commits) == 0 {
+		return nil
+	}
+
+	if opts.Title == "" {
+		opts.Title = commits[len(commits)-1].Subject
+	}
+
+	if opts.Description == "" {
+		opts.Description = commitsDescription(commits)
+	}
+
+	return nil
+}
+
+// branchCommits lists the commits on the current branch relative to the base branch, oldest first.
+func branchCommits(ctx context.Context, repo, base string) ([]commit, error) {
+	if base == "" {
+		return nil, fmt.Errorf("unable to fill pull request from commits: base branch is unknown")
+	}
+
+	// separate the subject from the body with a unit separator, and each commit with a record separator
+	cmd, err := utils.Cmd(ctx, repo, "git", "log", "--reverse", "--format=%s%x1f%b%x1e", base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fill pull request from commits since %s: %w", base, err)
+	}
+
+	var commits []commit
+
+	for record := range strings.SplitSeq(string(out), "\x1e") {
+		subject, body, _ := strings.Cut(strings.TrimSpace(record), "\x1f")
+		if subject == "" {
+			continue
+		}
+
+		commits = append(commits, commit{Subject: strings.TrimSpace(subject), Body: strings.TrimSpace(body)})
+	}
+
+	return commits, nil
+}
+
+// commitsDescription describes the commits as the body of a single commit, or a list of the subjects of multiple commits.
+func commitsDescription(commits []commit) string {
+	if len(commits) == 1 {
+		return commits[0].Body
+	}
+
+	lines := make([]string, len(commits))
+	for i, c := range commits {
+		lines[i] = "- " + c.Subject
+	}
+
+	return strings.Join(lines, "\n")
+}
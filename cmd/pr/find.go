@@ -0,0 +1,100 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const findTitleFlag = "title-contains"
+
+func addFindCmd() *cobra.Command {
+	// findCmd represents the pr find command
+	findCmd := &cobra.Command{
+		Use:   "find --title-contains <text> <repository>...",
+		Short: "Find open pull requests by title",
+		Long: `Search the open pull requests of each repository for titles containing the given text.
+
+This is useful for recovering the pull requests created by a previous batch,
+such as when the local branches have since been switched or deleted. Matching
+is case-insensitive, and each match is reported with its number and source
+branch, which can then be passed to other pr commands with --branch.`,
+		Example: `  # Find the pull requests opened for a dependency bump
+  batch-tool pr find --title-contains "bump go version" ~backend
+
+  # Update the pull requests found above
+  batch-tool pr edit --branch bump-go -r alice ~backend`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: catalog.CompletionFunc(),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			config.Viper(cmd.Context()).BindPFlag(config.PrFindTitle, cmd.Flags().Lookup(findTitleFlag))
+
+			if strings.TrimSpace(config.Viper(cmd.Context()).GetString(config.PrFindTitle)) == "" {
+				return fmt.Errorf("--%s is required", findTitleFlag)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return call.Do(cmd, args, Find)
+		},
+	}
+
+	findCmd.Flags().String(findTitleFlag, "", "text to search for in pull request titles (case-insensitive)")
+
+	return findCmd
+}
+
+// Find lists the open pull requests for the given repository whose titles contain the configured search text.
+func Find(ctx context.Context, ch output.Channel) error {
+	viper := config.Viper(ctx)
+
+	repoName := utils.ResolveRepoName(ch.Name())
+	search := viper.GetString(config.PrFindTitle)
+
+	// Get project from repository metadata in catalog, fall back to default
+	project := catalog.GetProjectForRepo(ctx, repoName)
+	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
+
+	prs, err := provider.ListOpenPullRequests(repoName)
+	if err != nil {
+		return fmt.Errorf("failed to list pull requests for %s: %w", repoName, err)
+	}
+
+	matches := matchTitles(prs, search)
+	if len(matches) == 0 {
+		fmt.Fprintf(ch, "No open pull requests with titles containing %q\n", search)
+		ch.Skip("no matching pull requests")
+
+		return nil
+	}
+
+	for _, pr := range matches {
+		fmt.Fprintf(ch, "(PR #%d) %s [%s]\n", pr.Number, pr.Title, pr.Branch)
+	}
+
+	return nil
+}
+
+// matchTitles returns the pull requests whose titles contain the search text, ignoring case.
+func matchTitles(prs []*scm.PullRequest, search string) []*scm.PullRequest {
+	search = strings.ToLower(strings.TrimSpace(search))
+
+	var matches []*scm.PullRequest
+	for _, pr := range prs {
+		if strings.Contains(strings.ToLower(pr.Title), search) {
+			matches = append(matches, pr)
+		}
+	}
+
+	return matches
+}
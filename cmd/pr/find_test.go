@@ -0,0 +1,119 @@
+package pr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestMatchTitles(t *testing.T) {
+	prs := []*scm.PullRequest{
+		{Number: 1, Title: "Bump Go version to 1.26"},
+		{Number: 2, Title: "Fix flaky test"},
+		{Number: 3, Title: "chore: bump go version"},
+	}
+
+	tests := []struct {
+		name   string
+		search string
+		want   []int
+	}{
+		{name: "case-insensitive match", search: "bump go", want: []int{1, 3}},
+		{name: "surrounding whitespace ignored", search: "  flaky ", want: []int{2}},
+		{name: "no matches", search: "release", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, pr := range matchTitles(prs, tt.search) {
+				got = append(got, pr.Number)
+			}
+
+			testhelper.AssertEqual(t, len(got), len(tt.want))
+			for i := range tt.want {
+				testhelper.AssertEqual(t, got[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestFindCommandRun(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		wantErr    bool
+	}{
+		{
+			name:       "matches across repositories",
+			args:       []string{"--title-contains", "bump deps", "repo-1", "repo-2"},
+			wantOutput: []string{"Bump deps for repo-1 [deps-update]", "bump DEPS for repo-2 [deps-bump]"},
+		},
+		{
+			name:       "no matching titles",
+			args:       []string{"--title-contains", "release", "repo-1"},
+			wantOutput: []string{`No open pull requests with titles containing "release"`},
+		},
+		{
+			name:    "missing search text",
+			args:    []string{"repo-1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+
+			for _, pr := range []struct{ repo, branch, title string }{
+				{"repo-1", "deps-update", "Bump deps for repo-1"},
+				{"repo-1", "feature-branch", "Unrelated change"},
+				{"repo-2", "deps-bump", "bump DEPS for repo-2"},
+			} {
+				if _, err := provider.OpenPullRequest(pr.repo, pr.branch, &scm.PROptions{Title: pr.title}); err != nil {
+					t.Fatalf("Failed to create test PR for %s: %v", pr.repo, err)
+				}
+			}
+
+			cmd := addFindCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if !tt.wantErr {
+				testhelper.AssertContains(t, buf.String(), tt.wantOutput)
+				testhelper.AssertNotContains(t, buf.String(), []string{"Unrelated change"})
+			}
+		})
+	}
+}
+
+func TestFindCommandListError(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	provider.SetError("ListOpenPullRequests", errors.New("list failed"))
+
+	cmd := addFindCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--title-contains", "deps", "repo-1"})
+
+	err := cmd.ExecuteContext(ctx)
+
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, buf.String(), []string{"failed to list pull requests for repo-1", "list failed"})
+}
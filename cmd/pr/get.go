@@ -47,10 +47,7 @@ Branch Requirement:
 func Get(ctx context.Context, ch output.Channel) error {
 	viper := config.Viper(ctx)
 
-	branch, err := utils.LookupBranch(ctx, ch.Name())
-	if err != nil {
-		return fmt.Errorf("failed to lookup branch for %s: %w", ch.Name(), err)
-	}
+	branch := lookupBranch(ctx, ch.Name())
 	repoName := utils.ResolveRepoName(ch.Name())
 
 	// Get project from repository metadata in catalog, fall back to default
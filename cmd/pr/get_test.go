@@ -2,6 +2,7 @@ package pr
 
 import (
 	"bytes"
+	"path/filepath"
 	"testing"
 
 	"github.com/ryclarke/batch-tool/config"
@@ -106,6 +107,43 @@ func TestGetCommandRun(t *testing.T) {
 	}
 }
 
+func TestGetCommandRunMixedBranches(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	testhelper.ExecCommand(t, filepath.Join(reposPath, "example.com", "test-project", "repo-2"), "git", "checkout", "-b", "other-branch")
+
+	ctx, testProvider := setupTestContext(t, reposPath)
+	config.Viper(ctx).Set(config.Branch, "")
+
+	// Each repository has PRs on both branches, so the wrong lookup would find the wrong PR
+	for repo, branch := range map[string]string{"repo-1": "feature-branch", "repo-2": "other-branch"} {
+		for _, b := range []string{"feature-branch", "other-branch"} {
+			title := "Wrong PR"
+			if b == branch {
+				title = "Right PR"
+			}
+
+			if _, err := testProvider.OpenPullRequest(repo, b, &scm.PROptions{Title: title}); err != nil {
+				t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+			}
+		}
+	}
+
+	cmd := addGetCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	output := buf.String()
+	testhelper.AssertContains(t, output, []string{"Right PR"})
+	testhelper.AssertNotContains(t, output, []string{"Wrong PR"})
+}
+
 func TestGetCommandRunPRNotFound(t *testing.T) {
 	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
 	ctx, _ := setupTestContext(t, reposPath)
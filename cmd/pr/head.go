@@ -0,0 +1,57 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const checkHeadFlag = "check-head"
+
+// buildCheckHeadFlag adds the flag to compare the local branch with the head of the pull request to the command.
+func buildCheckHeadFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool(checkHeadFlag, false, "warn if the local branch differs from the head of the pull request (e.g. after a force-push)")
+}
+
+// warnDivergedHead warns if the head of the local branch differs from the head of its pull request, such as when
+// the branch was force-pushed or rewritten since it was last fetched, so that changes based on a stale view of the
+// branch can be reviewed. The check is only made if enabled, and never fails the command.
+func warnDivergedHead(ctx context.Context, ch output.Channel, provider scm.Provider, repoName, branch string) {
+	if !config.Viper(ctx).GetBool(config.PrCheckHead) {
+		return
+	}
+
+	local, err := utils.BranchSHA(ctx, ch.Name(), branch)
+	if err != nil {
+		fmt.Fprintf(ch, "Warning: unable to compare the local branch with the pull request: %v\n", err)
+		return
+	}
+
+	remote, err := provider.GetPullRequestHeadSHA(repoName, branch)
+	if err != nil {
+		fmt.Fprintf(ch, "Warning: unable to compare the local branch with the pull request: %v\n", err)
+		return
+	} else if remote == "" {
+		return // the provider didn't report the head of the pull request
+	}
+
+	if local != remote {
+		fmt.Fprintf(ch, "Warning: local branch %s (%s) differs from the head of the pull request (%s); it may have been force-pushed or rewritten\n",
+			branch, shortSHA(local), shortSHA(remote))
+	}
+}
+
+// shortSHA abbreviates the commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+
+	return sha
+}
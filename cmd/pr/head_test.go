@@ -0,0 +1,93 @@
+package pr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestWarnDivergedHead(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled bool
+		head     func(local string) string
+		err      error
+		want     string
+	}{
+		{name: "matching heads", head: func(local string) string { return local }},
+		{name: "diverged heads", head: func(string) string { return "0123456789abcdef0123456789abcdef01234567" },
+			want: "Warning: local branch feature-branch"},
+		{name: "head not reported", head: func(string) string { return "" }},
+		{name: "provider error", err: errors.New("not supported"), want: "unable to compare the local branch with the pull request: not supported"},
+		{name: "disabled", disabled: true, head: func(string) string { return "0123456789abcdef" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+			ctx, provider := setupTestContext(t, reposPath)
+			config.Viper(ctx).Set(config.PrCheckHead, !tt.disabled)
+
+			if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test PR"}); err != nil {
+				t.Fatalf("Failed to open pull request: %v", err)
+			}
+
+			local, err := utils.BranchSHA(ctx, "repo-1", "feature-branch")
+			if err != nil {
+				t.Fatalf("Failed to read local branch: %v", err)
+			}
+
+			if tt.head != nil {
+				if err := provider.SetPRHead("repo-1", "feature-branch", tt.head(local)); err != nil {
+					t.Fatalf("Failed to set head: %v", err)
+				}
+			}
+
+			if tt.err != nil {
+				provider.SetError("GetPullRequestHeadSHA", tt.err)
+			}
+
+			ch := testhelper.NewMockChannel("repo-1")
+			warnDivergedHead(ctx, ch, provider, "repo-1", "feature-branch")
+
+			if tt.want == "" {
+				testhelper.AssertEqual(t, string(ch.Output()), "")
+			} else {
+				testhelper.AssertContains(t, string(ch.Output()), tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCommandCheckHeadDiverged(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test PR"}); err != nil {
+		t.Fatalf("Failed to open pull request: %v", err)
+	}
+
+	if err := provider.SetPRHead("repo-1", "feature-branch", "0123456789abcdef0123456789abcdef01234567"); err != nil {
+		t.Fatalf("Failed to set head: %v", err)
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--check-head", "repo-1"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	// the warning doesn't prevent the merge
+	testhelper.AssertContains(t, buf.String(), "differs from the head of the pull request (0123456789ab)")
+	testhelper.AssertContains(t, buf.String(), "Merged pull request")
+}
@@ -0,0 +1,76 @@
+package pr
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+const (
+	assignMeFlag = "assign-me"
+	reviewMeFlag = "review-me"
+)
+
+// buildCurrentUserFlags adds the flags for assigning or requesting a review from the authenticated user.
+func buildCurrentUserFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(assignMeFlag, false, "assign the pull request to the authenticated user")
+	cmd.Flags().Bool(reviewMeFlag, false, "add the authenticated user as a reviewer")
+}
+
+// parseCurrentUserFlags binds the current user flags to their configuration keys.
+func parseCurrentUserFlags(cmd *cobra.Command) {
+	viper := config.Viper(cmd.Context())
+
+	viper.BindPFlag(config.PrAssignMe, cmd.Flags().Lookup(assignMeFlag))
+	viper.BindPFlag(config.PrReviewMe, cmd.Flags().Lookup(reviewMeFlag))
+}
+
+// resolveCurrentUser looks up the login of the authenticated user if it is needed by the current user flags,
+// storing it in the configuration so that the provider is only queried once per run.
+func resolveCurrentUser(ctx context.Context) error {
+	viper := config.Viper(ctx)
+
+	if !viper.GetBool(config.PrAssignMe) && !viper.GetBool(config.PrReviewMe) {
+		return nil
+	}
+
+	if viper.GetString(config.PrCurrentUser) != "" {
+		return nil
+	}
+
+	provider := scm.Get(ctx, viper.GetString(config.GitProvider), viper.GetString(config.GitProject))
+
+	user, err := provider.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	viper.Set(config.PrCurrentUser, user)
+
+	return nil
+}
+
+// currentAssignees returns the authenticated user as the sole assignee if requested.
+func currentAssignees(ctx context.Context) []string {
+	viper := config.Viper(ctx)
+
+	if user := viper.GetString(config.PrCurrentUser); user != "" && viper.GetBool(config.PrAssignMe) {
+		return []string{user}
+	}
+
+	return nil
+}
+
+// addCurrentReviewer adds the authenticated user to the given reviewers if requested.
+func addCurrentReviewer(ctx context.Context, reviewers []string) []string {
+	viper := config.Viper(ctx)
+
+	if user := viper.GetString(config.PrCurrentUser); user != "" && viper.GetBool(config.PrReviewMe) {
+		return mergeReviewers(reviewers, []string{user})
+	}
+
+	return reviewers
+}
@@ -2,12 +2,15 @@ package pr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ryclarke/batch-tool/call"
 	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/cmd/git"
 	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/output"
 	"github.com/ryclarke/batch-tool/scm"
@@ -15,9 +18,14 @@ import (
 )
 
 const (
-	checkFlag   = "check"
-	noCheckFlag = "force"
-	methodFlag  = "method"
+	checkFlag    = "check"
+	noCheckFlag  = "force"
+	methodFlag   = "method"
+	approvedFlag = "if-approved"
+	deleteFlag   = "delete-local-branch"
+	updateFlag   = "update-branch"
+
+	mergeDryRunFlag = "dry-run"
 )
 
 // addMergeCmd initializes the pr merge command
@@ -31,25 +39,79 @@ merge behavior for your SCM provider.
 
 Safety Checks:
   By default, the command verifies the PR is in an approved/mergeable state
-  before merging (only supported by GitHub provider).
+  before merging (only supported by GitHub provider). Pull requests which
+  can't be merged report the specific reason: merge conflicts to resolve,
+  a branch which is behind its base and needs a rebase, required checks or
+  reviews which are still pending, or a mergeable state which GitHub has not
+  determined yet.
+
+Approval Gate:
+  Use --if-approved to merge only pull requests that have the number of
+  approvals required by the base branch's protection rules (at least one) and
+  no outstanding change requests. Unapproved pull requests are skipped and
+  reported without failing the batch (only supported by GitHub provider).
+
+Update Branch:
+  Use --update-branch to bring pull requests which are behind their base
+  branch up to date before merging, by merging the base branch into the
+  head branch. The merge proceeds once the update has completed (only
+  supported by GitHub provider).
+
+Merge Method:
+  The merge method is taken from --method, then from the merge-method of the
+  repository's label policies or its project's policy (repos.policies), and
+  finally from git.default-merge-method. Repositories whose label policies
+  disagree on the merge method are reported as errors. Common alternative
+  names such as squash-merge or rebase-merge are accepted, along with any
+  aliases configured in pr.merge-method-aliases, and a method which the
+  provider doesn't support is rejected before anything is merged.
+
+Dry Run:
+  Use --dry-run to report whether each pull request can be merged, and the
+  reason if it can't, without merging anything. Unlike --check, which only
+  gates the merge, a dry run never merges. Pull requests which can't be
+  merged are reported as skipped (not supported by Bitbucket provider).
+
+Force-Push Detection:
+  Use --check-head (or set pr.check-head) to warn before merging a pull
+  request whose head differs from the local branch, such as after the branch
+  was force-pushed or rewritten. The pull request is still merged.
 
 Force Merge:
   Use --force (-f) to bypass status checks and merge anyway. This should be
   used with caution as it may merge PRs that haven't been properly reviewed
   or tested if merge policies are not configured properly on the remote.
 
+Confirmation:
+  When more repositories are selected than pr.confirm-threshold (default 50),
+  the command asks for confirmation before merging anything, showing the
+  number selected. Use --yes (-y) to skip it, e.g. in scripts.
+
 Post-Merge:
-  After merging, you typically want to:
-  - Update local default branch: batch-tool git update <repo>
-  - Clean up feature branch: git branch -d <branch>`,
+  Use --delete-local-branch to switch each local clone back to its default
+  branch and delete the merged feature branch. Clones with uncommitted changes,
+  or with local commits beyond the merged head, are left untouched. Afterward, you typically want to update the local
+  default branch: batch-tool git update <repo>`,
 		Example: `  # Merge approved PRs
   batch-tool pr merge repo1 repo2
 
+  # Merge only the PRs that have been approved
+  batch-tool pr merge --if-approved ~backend
+
+  # Update branches which are behind their base, then merge
+  batch-tool pr merge --update-branch ~backend
+
+  # Report which PRs can be merged without merging them
+  batch-tool pr merge --dry-run ~backend
+
   # Force merge without status checks
   batch-tool pr merge -f repo1
 
   # Merge and update branches afterward
-  batch-tool pr merge repo1 && batch-tool git update repo1`,
+  batch-tool pr merge repo1 && batch-tool git update repo1
+
+  # Merge and clean up the local feature branches
+  batch-tool pr merge --delete-local-branch ~backend`,
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: catalog.CompletionFunc(),
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
@@ -59,9 +121,40 @@ Post-Merge:
 				return err
 			}
 
+			if err := viper.BindPFlag(config.PrMergeApproved, cmd.Flags().Lookup(approvedFlag)); err != nil {
+				return err
+			}
+
+			if err := viper.BindPFlag(config.PrMergeDeleteLocal, cmd.Flags().Lookup(deleteFlag)); err != nil {
+				return err
+			}
+
+			if err := viper.BindPFlag(config.PrMergeUpdate, cmd.Flags().Lookup(updateFlag)); err != nil {
+				return err
+			}
+
+			if err := viper.BindPFlag(config.PrMergeDryRun, cmd.Flags().Lookup(mergeDryRunFlag)); err != nil {
+				return err
+			}
+
+			if err := viper.BindPFlag(config.PrCheckHead, cmd.Flags().Lookup(checkHeadFlag)); err != nil {
+				return err
+			}
+
 			return viper.BindPFlag(config.PrMergeMethod, cmd.Flags().Lookup(methodFlag))
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := resolveMergeMethod(cmd.Context()); err != nil {
+				return err
+			}
+
+			// nothing is merged by a dry run, so there is nothing to confirm
+			if !config.Viper(cmd.Context()).GetBool(config.PrMergeDryRun) {
+				if ok, err := confirmBatchSize(cmd, args, "merge pull requests"); err != nil || !ok {
+					return err
+				}
+			}
+
 			buildPROptions(cmd)
 
 			return call.Do(cmd, args, Merge)
@@ -71,7 +164,13 @@ Post-Merge:
 	// TODO: change default to true once GitHub API improves reliability of mergeable status (currently it can be stale and cause false negatives)
 	utils.BuildBoolFlagsDefault(mergeCmd, checkFlag, "", noCheckFlag, "f", false, "check PR status before merging (can be unreliable)")
 
-	mergeCmd.Flags().StringP(methodFlag, "m", "", "merge method to use (e.g. merge, squash, rebase)")
+	mergeCmd.Flags().StringP(methodFlag, "m", "", "merge method to use (e.g. merge, squash, rebase, or an alias such as squash-merge)")
+	mergeCmd.Flags().Bool(approvedFlag, false, "skip pull requests that do not have the required approvals")
+	mergeCmd.Flags().Bool(updateFlag, false, "update branches which are behind their base branch before merging")
+	mergeCmd.Flags().Bool(deleteFlag, false, "check out the default branch and delete the local feature branch after merging")
+	mergeCmd.Flags().Bool(mergeDryRunFlag, false, "report whether each pull request can be merged without merging it")
+	buildCheckHeadFlag(mergeCmd)
+	buildConfirmFlags(mergeCmd)
 
 	return mergeCmd
 }
@@ -85,22 +184,150 @@ func Merge(ctx context.Context, ch output.Channel) error {
 	project := catalog.GetProjectForRepo(ctx, repoName)
 	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
 
-	branch, err := utils.LookupBranch(ctx, ch.Name())
-	if err != nil {
-		return err
-	}
+	branch := lookupBranch(ctx, ch.Name())
 
 	opts := prOptions(ctx, repoName, true)
+
+	// use the merge method required by the repository's policies unless overridden by flags
+	if opts.Merge.Method == "" {
+		method, err := lookupMergeMethod(ctx, repoName)
+		if err != nil {
+			return err
+		}
+
+		opts.Merge.Method = scm.NormalizeMergeMethod(method, viper.GetStringMapString(config.PrMergeMethodAliases))
+	}
+
 	if err := provider.CheckCapabilities(&opts); err != nil {
 		return err
 	}
 
+	if viper.GetBool(config.PrMergeApproved) {
+		status, err := provider.GetReviewStatus(repoName, branch)
+		if err != nil {
+			return err
+		}
+
+		if !status.Approved() {
+			reason := "unapproved pull request: " + describeReviewStatus(status)
+			fmt.Fprintf(ch, "Skipped %s\n", reason)
+			ch.Skip(reason)
+
+			return nil
+		}
+	}
+
+	warnDivergedHead(ctx, ch, provider, repoName, branch)
+
+	// the head is looked up before merging, since a merged pull request can no longer be found by its branch
+	var head string
+	if viper.GetBool(config.PrMergeDeleteLocal) && !opts.Merge.DryRun {
+		head = lookupMergedHead(ch, provider, repoName, branch)
+	}
+
 	pr, err := provider.MergePullRequest(repoName, branch, &opts.Merge)
 	if err != nil {
 		return err
 	}
 
+	if opts.Merge.DryRun {
+		return reportMergeable(ch, pr)
+	}
+
+	ch.SetPullRequest(pr)
+
+	// the provider may only schedule the merge, such as while a GitLab pipeline is still running, in which case
+	// the feature branch is still needed
+	if pr.AutoMerge != "" {
+		fmt.Fprintf(ch, "Pull request (#%d) %s is scheduled to merge when the pipeline succeeds\n", pr.Number, pr.Title)
+		return nil
+	}
+
 	fmt.Fprintf(ch, "Merged pull request (#%d) %s\n", pr.Number, pr.Title)
 
+	if viper.GetBool(config.PrMergeDeleteLocal) {
+		return deleteLocalBranch(ctx, ch, branch, head)
+	}
+
+	return nil
+}
+
+// resolveMergeMethod replaces an alias given for the merge method (e.g. squash-merge) with the method it names, and
+// checks that the provider supports the method before any pull request is merged.
+func resolveMergeMethod(ctx context.Context) error {
+	viper := config.Viper(ctx)
+
+	method := viper.GetString(config.PrMergeMethod)
+	if method == "" {
+		return nil
+	}
+
+	canonical := scm.NormalizeMergeMethod(method, viper.GetStringMapString(config.PrMergeMethodAliases))
+
+	provider := scm.Get(ctx, viper.GetString(config.GitProvider), viper.GetString(config.GitProject))
+	if err := provider.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: canonical}}); err != nil {
+		return fmt.Errorf("invalid merge method %q: %w", method, err)
+	}
+
+	viper.Set(config.PrMergeMethod, canonical)
+
+	return nil
+}
+
+// reportMergeable reports whether the pull request checked by a dry run can be merged. A pull request which
+// can't be merged is skipped with the reason, so that it is listed in the summary without failing the batch.
+func reportMergeable(ch output.Channel, pr *scm.PullRequest) error {
+	ch.SetPullRequest(pr)
+
+	if pr.MergeBlocker != "" {
+		fmt.Fprintf(ch, "Pull request (#%d) %s can't be merged: %s\n", pr.Number, pr.Title, pr.MergeBlocker)
+		ch.Skip("not mergeable: " + pr.MergeBlocker)
+
+		return nil
+	}
+
+	fmt.Fprintf(ch, "Pull request (#%d) %s can be merged\n", pr.Number, pr.Title)
+
+	return nil
+}
+
+// lookupMergedHead returns the SHA of the head of the pull request which is about to be merged, or an empty string
+// (so that only a fully merged local branch is deleted) if the provider can't report it.
+func lookupMergedHead(ch output.Channel, provider scm.Provider, repoName, branch string) string {
+	head, err := provider.GetPullRequestHeadSHA(repoName, branch)
+	if err != nil {
+		fmt.Fprintf(ch, "Warning: unable to look up the head of the pull request, so the local branch is only deleted if fully merged: %v\n", err)
+		return ""
+	}
+
+	return head
+}
+
+// deleteLocalBranch switches the local clone to its default branch and deletes the merged feature branch, as long
+// as it has no commits beyond the merged head. A clone with uncommitted changes or unmerged commits is left in place
+// with a warning, since the merge itself succeeded.
+func deleteLocalBranch(ctx context.Context, ch output.Channel, branch, head string) error {
+	err := git.DeleteBranch(branch, head)(ctx, ch)
+	if errors.Is(err, git.ErrUncommittedChanges) || errors.Is(err, git.ErrUnmergedCommits) {
+		fmt.Fprintf(ch, "Warning: local branch %s not deleted: %v\n", branch, err)
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to delete local branch %s: %w", branch, err)
+	}
+
+	fmt.Fprintf(ch, "Deleted local branch %s\n", branch)
+
 	return nil
 }
+
+// describeReviewStatus summarizes the approvals and outstanding change requests of a pull request.
+func describeReviewStatus(status *scm.ReviewStatus) string {
+	summary := fmt.Sprintf("%d of %d required approvals", len(status.Approvers), status.RequiredApprovals)
+
+	if len(status.ChangesRequested) > 0 {
+		summary += fmt.Sprintf(", changes requested by %s", strings.Join(status.ChangesRequested, ", "))
+	}
+
+	return summary
+}
@@ -2,10 +2,18 @@ package pr
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -135,6 +143,27 @@ func TestMergeCommandRun(t *testing.T) {
 	}
 }
 
+// TestMergeRecordsPullRequest tests that the merged pull request is recorded in the result of the repository
+func TestMergeRecordsPullRequest(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	opened, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test Title"})
+	if err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	ch := testhelper.NewMockChannel("repo-1")
+	if err := Merge(ctx, ch); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	pr := ch.PullRequest()
+	if pr == nil || pr.Number != opened.Number || pr.MergeCommit == "" {
+		t.Errorf("Expected merged pull request #%d with a merge commit to be recorded, got %+v", opened.Number, pr)
+	}
+}
+
 func TestMergeCommandRunPRNotFound(t *testing.T) {
 	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
 	ctx, _ := setupTestContext(t, reposPath)
@@ -369,6 +398,16 @@ func TestMergeCommandMergeMethodFlag(t *testing.T) {
 			mergeMethod:  "rebase",
 			expectMethod: "rebase",
 		},
+		{
+			name:         "merge method alias: squash-merge",
+			mergeMethod:  "squash-merge",
+			expectMethod: "squash",
+		},
+		{
+			name:         "merge method alias: Rebase-Merge",
+			mergeMethod:  "Rebase-Merge",
+			expectMethod: "rebase",
+		},
 		{
 			name:         "no merge method specified (default)",
 			mergeMethod:  "",
@@ -426,6 +465,54 @@ func TestMergeCommandMergeMethodFlag(t *testing.T) {
 	}
 }
 
+// TestMergeCommandInvalidMergeMethod tests that unknown merge methods are rejected before any pull request is merged
+func TestMergeCommandInvalidMergeMethod(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+
+	tests := []struct {
+		name        string
+		mergeMethod string
+		aliases     map[string]string
+		wantErr     bool
+	}{
+		{name: "unknown method", mergeMethod: "fast-forward", wantErr: true},
+		{name: "configured alias", mergeMethod: "ff", aliases: map[string]string{"ff": "rebase"}, wantErr: false},
+		{name: "built-in alias with configured aliases", mergeMethod: "squash-merge", aliases: map[string]string{"ff": "rebase"}, wantErr: false},
+		{name: "alias to unsupported method", mergeMethod: "ff", aliases: map[string]string{"ff": "fast-forward"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCtx, testProvider := setupTestContext(t, reposPath)
+
+			if tt.aliases != nil {
+				config.Viper(testCtx).Set(config.PrMergeMethodAliases, tt.aliases)
+			}
+
+			if _, err := testProvider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test PR"}); err != nil {
+				t.Fatalf("Failed to create test PR: %v", err)
+			}
+
+			cmd := addMergeCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"--method", tt.mergeMethod, "repo-1"})
+
+			err := cmd.ExecuteContext(testCtx)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if tt.wantErr {
+				testhelper.AssertContains(t, err.Error(), "invalid merge method")
+				testhelper.AssertNotContains(t, buf.String(), []string{"Merged pull request"})
+			} else {
+				testhelper.AssertContains(t, buf.String(), "Merged pull request")
+			}
+		})
+	}
+}
+
 // TestMergeCommandMergeMethodShortFlag tests the short form of the merge method flag
 func TestMergeCommandMergeMethodShortFlag(t *testing.T) {
 	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
@@ -648,3 +735,432 @@ func TestMergeCommandConflictingFlags(t *testing.T) {
 	// Log the behavior for manual verification
 	t.Logf("Conflicting flags result - Error: %v, Output: %s", err, output)
 }
+
+// TestMergeCommandIfApproved tests that --if-approved only merges approved PRs
+func TestMergeCommandIfApproved(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"approved-repo", "pending-repo", "blocked-repo"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	statuses := map[string]*scm.ReviewStatus{
+		"approved-repo": {Approvers: []string{"alice", "bob"}, RequiredApprovals: 2},
+		"pending-repo":  {Approvers: []string{"alice"}, RequiredApprovals: 2},
+		"blocked-repo":  {Approvers: []string{"alice"}, ChangesRequested: []string{"carol"}, RequiredApprovals: 1},
+	}
+
+	for repo, status := range statuses {
+		if _, err := testProvider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+			t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+		}
+
+		if err := testProvider.SetPRReviewStatus(repo, "feature-branch", status); err != nil {
+			t.Fatalf("Failed to set review status for %s: %v", repo, err)
+		}
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--if-approved", "approved-repo", "pending-repo", "blocked-repo"})
+
+	if err := cmd.ExecuteContext(testCtx); err != nil {
+		t.Fatalf("Expected unapproved PRs to be skipped without error, got: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Merged pull request",
+		"Skipped unapproved pull request: 1 of 2 required approvals",
+		"Skipped unapproved pull request: 1 of 1 required approvals, changes requested by carol",
+		"Skipped:\n",
+		"  pending-repo: unapproved pull request: 1 of 2 required approvals\n",
+		"  blocked-repo: unapproved pull request: 1 of 1 required approvals, changes requested by carol\n",
+	})
+
+	if testProvider.HasPullRequest("approved-repo", "feature-branch") {
+		t.Error("Expected approved PR to be merged")
+	}
+
+	for _, repo := range []string{"pending-repo", "blocked-repo"} {
+		if !testProvider.HasPullRequest(repo, "feature-branch") {
+			t.Errorf("Expected unapproved PR for %s to remain open", repo)
+		}
+	}
+}
+
+// TestMergeCommandIfApprovedDefaultStatus tests that PRs without any reviews are not merged
+func TestMergeCommandIfApprovedDefaultStatus(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	if _, err := testProvider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--if-approved", "repo-1"})
+
+	if err := cmd.ExecuteContext(testCtx); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"Skipped unapproved pull request: 0 of 1 required approvals"})
+
+	if !testProvider.HasPullRequest("repo-1", "feature-branch") {
+		t.Error("Expected unreviewed PR to remain open")
+	}
+}
+
+// TestMergeCommandUpdateBranch tests that --update-branch brings behind PRs up to date before merging them
+func TestMergeCommandUpdateBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"behind-repo", "current-repo"}, true)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    bool
+		wantOutput []string
+	}{
+		{
+			name:       "behind PR fails without update",
+			args:       []string{"behind-repo", "current-repo"},
+			wantErr:    true,
+			wantOutput: []string{"is behind its base branch", "Merged pull request"},
+		},
+		{
+			name:       "behind PR is updated then merged",
+			args:       []string{"--update-branch", "behind-repo", "current-repo"},
+			wantOutput: []string{"Merged pull request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCtx, testProvider := setupTestContext(t, reposPath)
+
+			for _, repo := range []string{"behind-repo", "current-repo"} {
+				if _, err := testProvider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+					t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+				}
+			}
+
+			if err := testProvider.SetPRBehind("behind-repo", "feature-branch", true); err != nil {
+				t.Fatalf("Failed to set PR behind status: %v", err)
+			}
+
+			cmd := addMergeCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.ExecuteContext(testCtx)
+			testhelper.AssertError(t, err, tt.wantErr)
+			testhelper.AssertContains(t, buf.String(), tt.wantOutput)
+
+			testhelper.AssertEqual(t, testProvider.HasPullRequest("current-repo", "feature-branch"), false)
+			testhelper.AssertEqual(t, testProvider.HasPullRequest("behind-repo", "feature-branch"), tt.wantErr)
+		})
+	}
+}
+
+// TestMergeCommandPolicyMergeMethod tests that each repository is merged with the method of its label or project policy
+func TestMergeCommandPolicyMergeMethod(t *testing.T) {
+	repos := []string{"infra-repo", "app-repo", "other-repo"}
+	reposPath := testhelper.SetupRepos(t, repos, true)
+
+	catalog.Labels["infra"] = mapset.NewSet("infra-repo")
+	catalog.Labels["apps"] = mapset.NewSet("app-repo")
+	t.Cleanup(func() {
+		delete(catalog.Labels, "infra")
+		delete(catalog.Labels, "apps")
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "methods resolved per repository",
+			args: repos,
+			want: map[string]string{"infra-repo": "rebase", "app-repo": "merge", "other-repo": "squash"},
+		},
+		{
+			name: "method flag overrides policies",
+			args: append([]string{"--method", "merge"}, repos...),
+			want: map[string]string{"infra-repo": "merge", "app-repo": "merge", "other-repo": "merge"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCtx, testProvider := setupTestContext(t, reposPath)
+
+			config.Viper(testCtx).Set(config.LabelPolicies, map[string]any{
+				"infra":        map[string]any{"merge-method": "rebase"},
+				"apps":         map[string]any{"merge-method": "merge"},
+				"test-project": map[string]any{"merge-method": "squash"},
+			})
+
+			for _, repo := range repos {
+				if _, err := testProvider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+					t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+				}
+			}
+
+			cmd := addMergeCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.ExecuteContext(testCtx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			for repo, method := range tt.want {
+				testhelper.AssertEqual(t, testProvider.Merged[repo+":feature-branch"], method)
+			}
+		})
+	}
+}
+
+// TestMergeCommandPolicyMergeMethodConflict tests that labels requiring different merge methods fail the repository
+func TestMergeCommandPolicyMergeMethodConflict(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	config.Viper(testCtx).Set(config.LabelPolicies, map[string]any{
+		"infra": map[string]any{"merge-method": "rebase"},
+		"apps":  map[string]any{"merge-method": "merge"},
+	})
+
+	catalog.Labels["infra"] = mapset.NewSet("repo-1")
+	catalog.Labels["apps"] = mapset.NewSet("repo-1")
+	t.Cleanup(func() {
+		delete(catalog.Labels, "infra")
+		delete(catalog.Labels, "apps")
+	})
+
+	if _, err := testProvider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1"})
+
+	testhelper.AssertError(t, cmd.ExecuteContext(testCtx), true)
+	testhelper.AssertContains(t, buf.String(), "conflicting merge method policies for repo-1: merge and rebase")
+	testhelper.AssertEqual(t, testProvider.HasPullRequest("repo-1", "feature-branch"), true)
+}
+
+// TestMergeCommandPolicyOtherConflict tests that policies which disagree on settings other than the merge method
+// don't prevent merging
+func TestMergeCommandPolicyOtherConflict(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	config.Viper(testCtx).Set(config.LabelPolicies, map[string]any{
+		"infra": map[string]any{"base-branch": "develop", "merge-method": "rebase", "team-reviewers": []string{"infra-team"}},
+		"apps":  map[string]any{"base-branch": "release", "team-reviewers": []string{"app-team"}},
+	})
+
+	catalog.Labels["infra"] = mapset.NewSet("repo-1")
+	catalog.Labels["apps"] = mapset.NewSet("repo-1")
+	t.Cleanup(func() {
+		delete(catalog.Labels, "infra")
+		delete(catalog.Labels, "apps")
+	})
+
+	if _, err := testProvider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1"})
+
+	if err := cmd.ExecuteContext(testCtx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertEqual(t, testProvider.Merged["repo-1:feature-branch"], "rebase")
+}
+
+// TestMergeCommandDeleteLocalBranch tests that merged feature branches are removed from local clones
+func TestMergeCommandDeleteLocalBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2", "repo-3"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	for _, repo := range []string{"repo-1", "repo-2", "repo-3"} {
+		if _, err := testProvider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+			t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+		}
+
+		head, err := utils.BranchSHA(testCtx, repo, "feature-branch")
+		if err != nil {
+			t.Fatalf("Failed to lookup head of %s: %v", repo, err)
+		}
+
+		testProvider.Heads[repo+":feature-branch"] = head
+	}
+
+	// Uncommitted changes in repo-2 must prevent its local branch from being deleted
+	dirtyPath := filepath.Join(reposPath, "example.com", "test-project", "repo-2")
+	if err := os.WriteFile(filepath.Join(dirtyPath, "test.txt"), []byte("changed\n"), 0o600); err != nil {
+		t.Fatalf("Failed to modify test file: %v", err)
+	}
+
+	// A local commit in repo-3 which isn't part of the pull request must prevent its local branch from being deleted
+	testhelper.ExecCommand(t, filepath.Join(reposPath, "example.com", "test-project", "repo-3"), "git", "commit", "--allow-empty", "-m", "Unpushed commit")
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--delete-local-branch", "repo-1", "repo-2", "repo-3"})
+
+	if err := cmd.ExecuteContext(testCtx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Deleted local branch feature-branch",
+		"Warning: local branch feature-branch not deleted: repository has uncommitted changes",
+		"Warning: local branch feature-branch not deleted: local branch differs from the merged head",
+	})
+
+	// the merges succeeded, so leaving a local branch in place doesn't skip the repository
+	if strings.Contains(buf.String(), "Skipped:") {
+		t.Errorf("Expected no skipped repositories, got:\n%s", buf.String())
+	}
+
+	tests := []struct {
+		repo       string
+		wantBranch string
+		wantExists bool
+	}{
+		{repo: "repo-1", wantBranch: "main", wantExists: false},
+		{repo: "repo-2", wantBranch: "feature-branch", wantExists: true},
+		{repo: "repo-3", wantBranch: "feature-branch", wantExists: true},
+	}
+
+	for _, tt := range tests {
+		repoPath := filepath.Join(reposPath, "example.com", "test-project", tt.repo)
+
+		out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("Failed to lookup current branch for %s: %v", tt.repo, err)
+		}
+
+		if branch := strings.TrimSpace(string(out)); branch != tt.wantBranch {
+			t.Errorf("Expected %s to be on %q, got %q", tt.repo, tt.wantBranch, branch)
+		}
+
+		exists := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/feature-branch").Run() == nil
+		if exists != tt.wantExists {
+			t.Errorf("Expected feature branch in %s to exist: %v, got %v", tt.repo, tt.wantExists, exists)
+		}
+	}
+}
+
+// TestMergeCommandScheduled tests that a pull request which is only scheduled to merge isn't reported as merged,
+// and that its local branch is kept
+func TestMergeCommandScheduled(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	testCtx, testProvider := setupTestContext(t, reposPath)
+
+	if _, err := testProvider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	testProvider.Pending["repo-1:feature-branch"] = true
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--check", "--delete-local-branch", "repo-1"})
+
+	if err := cmd.ExecuteContext(testCtx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), "Pull request (#1) Test Title is scheduled to merge when the pipeline succeeds")
+
+	if strings.Contains(buf.String(), "Merged pull request") || strings.Contains(buf.String(), "Deleted local branch") {
+		t.Errorf("Expected the scheduled pull request not to be reported as merged, got:\n%s", buf.String())
+	}
+
+	repoPath := filepath.Join(reposPath, "example.com", "test-project", "repo-1")
+	if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/feature-branch").Run(); err != nil {
+		t.Errorf("Expected the feature branch to be kept: %v", err)
+	}
+}
+
+func TestMergeCommandDryRun(t *testing.T) {
+	repos := []string{"ready-repo", "conflict-repo", "behind-repo"}
+	reposPath := testhelper.SetupRepos(t, repos, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	// a dry run merges nothing, so it doesn't ask for confirmation
+	config.Viper(ctx).Set(config.PrConfirmThreshold, 1)
+
+	for _, repo := range repos {
+		if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+			t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+		}
+	}
+
+	if err := provider.SetPRMergeable("conflict-repo", "feature-branch", false); err != nil {
+		t.Fatalf("Failed to set PR mergeable status: %v", err)
+	}
+
+	if err := provider.SetPRBehind("behind-repo", "feature-branch", true); err != nil {
+		t.Fatalf("Failed to set PR behind status: %v", err)
+	}
+
+	cmd := addMergeCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs(append([]string{"--dry-run"}, repos...))
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Dry run failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Test Title can be merged",
+		"can't be merged: not mergeable",
+		"can't be merged: behind its base branch",
+	})
+	testhelper.AssertNotContains(t, buf.String(), []string{"Merged pull request", "Are you sure"})
+
+	// no merge is requested, so every pull request is still open
+	testhelper.AssertLength(t, provider.Merged, 0)
+
+	for _, repo := range repos {
+		if !provider.HasPullRequest(repo, "feature-branch") {
+			t.Errorf("Expected the pull request for %s to remain open after a dry run", repo)
+		}
+	}
+}
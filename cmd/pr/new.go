@@ -2,6 +2,7 @@ package pr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -17,13 +18,18 @@ import (
 )
 
 const (
-	baseBranchFlag = "base-branch"
+	baseBranchFlag        = "base-branch"
+	createBaseFlag        = "create-base"
+	checkoutFlag          = "checkout"
+	fillFlag              = "fill"
+	requiredReviewersFlag = "reviewers-required"
+	autoMergeFlag         = "auto-merge"
 )
 
 // addNewCmd initializes the pr new command
 func addNewCmd() *cobra.Command {
 	newCmd := &cobra.Command{
-		Use:   "new [--draft] [-t <title>] [-d <description>] [-r <reviewer>]... [-b <base-branch>] <repository>...",
+		Use:   "new [--draft] [-t <title>] [-d <description>] [--fill] [-r <reviewer>]... [-L <label>]... [-b <base-branch> [--create-base]] [--checkout <branch>] <repository>...",
 		Short: "Submit new pull requests",
 		Long: `Create new pull requests for the current branch in each repository.
 
@@ -33,8 +39,54 @@ This command creates a new PR from the current branch to the default branch
 Optional Information:
   - Title: PR title (defaults to the feature branch name)
   - Description: PR body/description text
-  - Reviewers: One or more reviewers to assign
+  - Reviewers: One or more reviewers to assign, with teams prefixed by @
+    (e.g. -r alice -r @my-org/platform-team)
+  - Reviewer Pool: Reviewers assigned round-robin across the batch to spread load
+  - CODEOWNERS: Owners of the changed files added as reviewers
+  - Assign Me / Review Me: Add the authenticated user as an assignee or reviewer
+  - Labels: One or more labels to attach to the PR (e.g. -L needs-triage)
   - Base Branch: Target branch for the PR (defaults to repo default branch)
+  - Auto-Merge: Merge the PR automatically once its checks and reviews pass
+    (--auto-merge, or --auto-merge=squash to choose the merge method; the
+    method must follow an equals sign, since --auto-merge squash would
+    select a repository named squash)
+
+Fill From Commits:
+  Use --fill to derive the title and description of each pull request from
+  the commits on its branch: the title from the subject of the latest commit,
+  and the description from the body of a single commit or a list of the
+  subjects of multiple commits. An explicit title or description is kept.
+
+Checkout:
+  Use --checkout <branch> to check out the given branch in each repository
+  (creating it from the default branch if it doesn't exist) and push it to the
+  remote before opening the pull request from it.
+
+Stacked Pull Requests:
+  When stacking pull requests, the base branch may not exist yet in every
+  repository. Use --create-base to create a missing base branch from the
+  default branch before opening the pull request (GitHub only). Without it,
+  a missing base branch is reported as an error.
+
+Required Reviewers:
+  Use --reviewers-required (or repos.reviewers-required in your config) to
+  require at least N reviewers (users and teams combined) after applying
+  flags, defaults, policies and CODEOWNERS. Repositories with fewer reviewers
+  fail before any pull request is opened.
+
+Label Policies:
+  Policies configured under repos.policies set the base branch and team
+  reviewers for repositories carrying a label. Explicit flags take precedence.
+
+Templates:
+  When a variables file is given with --template-vars, the title and
+  description are rendered as Go templates for each repository, with the
+  variables from the file available as {{.Vars.<name>}}.
+
+Confirmation:
+  When more repositories are selected than pr.confirm-threshold (default 50),
+  the command asks for confirmation before opening any pull requests, showing
+  the number selected. Use --yes (-y) to skip it, e.g. in scripts.
 
 Branch Validation:
   PRs cannot be created from the default branch. Ensure you're not on
@@ -42,26 +94,90 @@ Branch Validation:
 		Example: `  # Create PR with description and multiple reviewers
   batch-tool pr new -t "Fix bug" -d "Fixes issue #123" -r alice -r bob repo1 repo2
 
+  # Request reviews from a user and a team together
+  batch-tool pr new -t "Fix bug" -r alice -r @my-org/platform-team repo1
+
   # Create draft PR
-  batch-tool pr new -t "WIP" --draft repo1 repo2`,
+  batch-tool pr new -t "WIP" --draft repo1 repo2
+
+  # Spread review load by assigning two reviewers per PR from a pool
+  batch-tool pr new -t "Bump deps" --reviewer-pool alice,bob,carol,dave --reviewer-pool-count 2 '~backend'
+
+  # Request reviews from the CODEOWNERS of the changed files
+  batch-tool pr new -t "Refactor" --reviewers-from-codeowners repo1 repo2
+
+  # Reference a ticket from a variables file in each PR title
+  batch-tool pr new --template-vars vars.yaml -t "{{.Vars.ticket}}: Bump deps for {{.Repo}}" '~backend'
+
+  # Derive each title and description from the commits on the branch
+  batch-tool pr new --fill '~backend'
+
+  # Check out and push a branch in each repository, then open PRs from it
+  batch-tool pr new -t "Bump deps" --checkout chore/bump-deps '~backend'
+
+  # Stack PRs onto a base branch, creating it from the default branch where missing
+  batch-tool pr new -t "Part 2" -b feature/part-1 --create-base '~backend'
+
+  # Squash-merge dependency bumps automatically once their checks pass
+  batch-tool pr new -t "Bump deps" --auto-merge=squash '~backend'
+
+  # Refuse to open PRs which would have no reviewers
+  batch-tool pr new -t "Refactor" --reviewers-from-codeowners --reviewers-required 1 '~backend'`,
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: catalog.CompletionFunc(),
-		PreRunE: func(cmd *cobra.Command, _ []string) error {
-			config.Viper(cmd.Context()).BindPFlag(config.PrBaseBranch, cmd.Flags().Lookup(baseBranchFlag))
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			viper := config.Viper(cmd.Context())
+
+			if err := validateAutoMergeArgs(cmd, args); err != nil {
+				return err
+			}
+
+			viper.BindPFlag(config.PrBaseBranch, cmd.Flags().Lookup(baseBranchFlag))
+			viper.BindPFlag(config.PrCreateBase, cmd.Flags().Lookup(createBaseFlag))
+			viper.BindPFlag(config.PrCheckout, cmd.Flags().Lookup(checkoutFlag))
+			viper.BindPFlag(config.PrFill, cmd.Flags().Lookup(fillFlag))
+			viper.BindPFlag(config.RequiredReviewers, cmd.Flags().Lookup(requiredReviewersFlag))
+			viper.BindPFlag(config.PrAutoMerge, cmd.Flags().Lookup(autoMergeFlag))
+			parseCodeownersFlags(cmd)
 
 			return parseCommonPRFlags(cmd)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			viper := config.Viper(cmd.Context())
 
+			if err := resolveCurrentUser(cmd.Context()); err != nil {
+				return err
+			}
+
+			if ok, err := confirmBatchSize(cmd, args, "open pull requests"); err != nil || !ok {
+				return err
+			}
+
 			buildPROptions(cmd)
+			buildPoolAssignment(cmd.Context(), args)
+
+			callFunc := call.Wrap(validateBaseBranch, New)
 
-			return call.Do(cmd, args, call.Wrap(git.ValidateBranch(viper.GetString(config.PrBaseBranch)), New))
+			// check out the branch first, so the pull request is opened from it
+			if branch := viper.GetString(config.PrCheckout); branch != "" {
+				viper.Set(config.Branch, branch)
+				callFunc = call.Wrap(git.CheckoutBranch(branch), callFunc)
+			}
+
+			return call.Do(cmd, args, callFunc)
 		},
 	}
 
 	buildCommonPRFlags(newCmd)
+	buildConfirmFlags(newCmd)
+	buildCodeownersFlags(newCmd)
 	newCmd.Flags().StringP(baseBranchFlag, "b", "", "base branch for the pull request (default: repository default branch)")
+	newCmd.Flags().Bool(createBaseFlag, false, "create the base branch from the default branch if it does not exist")
+	newCmd.Flags().Bool(fillFlag, false, "derive the title and description from the commits on the branch, unless provided")
+	newCmd.Flags().String(checkoutFlag, "", "check out and push the given branch in each repository (creating it if needed) before opening the pull request")
+	newCmd.Flags().Int(requiredReviewersFlag, 0, "minimum number of reviewers (users and teams) each pull request must have")
+	newCmd.Flags().String(autoMergeFlag, "", "enable auto-merge once checks pass, optionally with a merge method (which must be given as --auto-merge=squash)")
+	newCmd.Flags().Lookup(autoMergeFlag).NoOptDefVal = "merge"
 
 	return newCmd
 }
@@ -75,28 +191,104 @@ func New(ctx context.Context, ch output.Channel) error {
 	project := catalog.GetProjectForRepo(ctx, repoName)
 	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
 
-	branch, err := utils.LookupBranch(ctx, ch.Name())
-	if err != nil {
-		return err
-	}
+	branch := lookupBranch(ctx, ch.Name())
 
 	// load PR options from config
 	opts := prOptions(ctx, repoName, false)
+	opts.CreateBaseBranch = viper.GetBool(config.PrCreateBase)
+
 	if err := provider.CheckCapabilities(&opts); err != nil {
 		return err
 	}
 
-	// get reviewers from config if not set via flags
+	if err := renderPROptions(ctx, ch.Name(), &opts); err != nil {
+		return err
+	}
+
+	// apply the policies of the repository's labels unless overridden by flags
+	policy, err := lookupPolicy(ctx, repoName)
+	if err != nil {
+		return err
+	}
+
+	if base := lookupBaseBranch(ctx, repoName, policy); base != "" {
+		opts.BaseBranch = base
+	}
+
+	// fill in the title and description after rendering, since commit messages aren't templates
+	if viper.GetBool(config.PrFill) {
+		if err := fillPROptions(ctx, ch.Name(), &opts); err != nil {
+			return err
+		}
+	}
+
+	// get reviewers from the reviewer pool or config if not set via flags
 	opts.Reviewers = lookupReviewers(ctx, repoName)
 	opts.TeamReviewers = lookupTeamReviewers(ctx, repoName)
 
+	if len(viper.GetStringSlice(config.PrTeamReviewers)) == 0 {
+		opts.TeamReviewers = mergeReviewers(opts.TeamReviewers, policy.TeamReviewers)
+	}
+
+	// add the owners of the changed files from the repository's CODEOWNERS file
+	if viper.GetBool(config.PrCodeowners) {
+		reviewers, teams, err := codeownersReviewers(ctx, ch.Name(), opts.BaseBranch)
+		if err != nil {
+			return err
+		}
+
+		opts.Reviewers = mergeReviewers(opts.Reviewers, reviewers)
+		opts.TeamReviewers = mergeReviewers(opts.TeamReviewers, teams)
+	}
+
+	opts.Reviewers = addCurrentReviewer(ctx, opts.Reviewers)
+
+	if required, count := viper.GetInt(config.RequiredReviewers), len(opts.Reviewers)+len(opts.TeamReviewers); count < required {
+		return fmt.Errorf("pull request would have %d reviewers (users and teams), but at least %d are required", count, required)
+	}
+
 	pr, err := provider.OpenPullRequest(repoName, branch, &opts)
-	if err != nil {
+	if err != nil && pr == nil {
+		if errors.Is(err, scm.ErrPullRequestExists) {
+			return fmt.Errorf("%w; use 'pr edit' to update it", err)
+		}
+
 		return err
 	}
 
+	// the pull request is recorded even if a later step failed (e.g. enabling auto-merge), since it was opened
+	ch.SetPullRequest(pr)
 	fmt.Fprint(ch, printPRInfo(pr, "New pull request", true))
 
+	return err
+}
+
+// validateBaseBranch skips the repository if its current branch is the base branch of the new pull request, as
+// resolved from the flags and policies of the repository.
+func validateBaseBranch(ctx context.Context, ch output.Channel) error {
+	policy, err := lookupPolicy(ctx, utils.ResolveRepoName(ch.Name()))
+	if err != nil {
+		return err
+	}
+
+	return git.ValidateBranch(lookupBaseBranch(ctx, utils.ResolveRepoName(ch.Name()), policy))(ctx, ch)
+}
+
+// validateAutoMergeArgs rejects a merge method given as a separate argument after --auto-merge (e.g. --auto-merge squash),
+// which would otherwise be taken as a repository, since the method is optional and must follow an equals sign.
+func validateAutoMergeArgs(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed(autoMergeFlag) {
+		return nil
+	}
+
+	aliases := config.Viper(cmd.Context()).GetStringMapString(config.PrMergeMethodAliases)
+
+	for _, arg := range args {
+		if scm.IsMergeMethod(arg, aliases) {
+			return fmt.Errorf("%q is a merge method rather than a repository; use --auto-merge=%s to choose it", arg, arg)
+		}
+	}
+
 	return nil
 }
 
@@ -111,6 +303,11 @@ func lookupReviewers(ctx context.Context, name string) []string {
 		return revs
 	}
 
+	// Use the reviewers assigned from the reviewer pool
+	if revs := poolReviewers(ctx, name); len(revs) > 0 {
+		return revs
+	}
+
 	reviewerMap := viper.GetStringMapStringSlice(config.DefaultReviewers)
 	tokenLabel := viper.GetString(config.TokenLabel)
 
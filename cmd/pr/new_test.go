@@ -2,12 +2,22 @@ package pr
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/spf13/cobra"
 
 	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -125,6 +135,42 @@ func TestNewCommandRunWithoutReviewers(t *testing.T) {
 	}
 }
 
+func TestNewCommandRunWithTemplateVars(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+	viper := config.Viper(ctx)
+
+	path := filepath.Join(t.TempDir(), "vars.json")
+	if err := os.WriteFile(path, []byte(`{"ticket": "OPS-42", "version": "1.2.3"}`), 0o600); err != nil {
+		t.Fatalf("Failed to write vars file: %v", err)
+	}
+
+	viper.Set(config.TemplateVars, path)
+	viper.Set(config.PrTitle, "{{.Vars.ticket}}: Bump {{.Repo}}")
+	viper.Set(config.PrDescription, "Upgrade to {{.Vars.version}}")
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		pr, err := provider.GetPullRequest(repo, "feature-branch")
+		if err != nil {
+			t.Fatalf("Expected PR for %s: %v", repo, err)
+		}
+
+		testhelper.AssertEqual(t, pr.Title, "OPS-42: Bump "+repo)
+		testhelper.AssertEqual(t, pr.Description, "Upgrade to 1.2.3")
+	}
+}
+
 func TestLookupReviewers(t *testing.T) {
 	ctx := loadFixture(t)
 	viper := config.Viper(ctx)
@@ -261,3 +307,458 @@ func TestLookupTeamReviewers(t *testing.T) {
 		t.Errorf("Expected [infra-team] from label, got %v", teamRevs)
 	}
 }
+
+func TestNewCommandRunWithCodeowners(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	repoDir := filepath.Join(reposPath, "example.com", "test-project", "repo-1")
+
+	// Only the api directory is changed on the feature branch
+	files := map[string]string{
+		"CODEOWNERS":    "* @lead\n/api/ @alice @acme/api-team\n/docs/ @writer @acme/docs-team\n",
+		"api/server.go": "package api\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	testhelper.ExecCommand(t, repoDir, "git", "add", ".")
+	testhelper.ExecCommand(t, repoDir, "git", "commit", "-m", "Add api server")
+
+	ctx, provider := setupTestContext(t, reposPath)
+	config.Viper(ctx).Set(config.PrTitle, "Test PR Title")
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--reviewers-from-codeowners", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR for repo-1: %v", err)
+	}
+
+	// The CODEOWNERS file itself is also changed, so its owner is requested as well
+	testhelper.AssertContains(t, pr.Reviewers, []string{"alice", "lead"})
+	testhelper.AssertContains(t, pr.TeamReviewers, "acme/api-team")
+
+	if slices.Contains(pr.Reviewers, "writer") || slices.Contains(pr.TeamReviewers, "acme/docs-team") {
+		t.Errorf("Expected only owners of changed files, got %v %v", pr.Reviewers, pr.TeamReviewers)
+	}
+
+	// Repositories without a CODEOWNERS file fall back to the configured reviewers
+	if !provider.HasPullRequest("repo-2", "feature-branch") {
+		t.Error("Expected PR for repo-2 without a CODEOWNERS file")
+	}
+}
+
+func TestCodeownersReviewersDiffFailure(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	repoDir := filepath.Join(reposPath, "example.com", "test-project", "repo-1")
+
+	if err := os.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @lead\n/docs/ @writer\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write CODEOWNERS: %v", err)
+	}
+
+	ctx, _ := setupTestContext(t, reposPath)
+
+	// An unknown base branch must not fall back to requesting every owner
+	reviewers, teams, err := codeownersReviewers(ctx, "repo-1", "missing-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "missing-branch")
+	testhelper.AssertLength(t, reviewers, 0)
+	testhelper.AssertLength(t, teams, 0)
+}
+
+func TestNewCommandRunWithCurrentUser(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+
+	ctx, provider := setupTestContext(t, reposPath)
+	provider.User = "octocat"
+
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Test PR Title")
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--assign-me", "--review-me", "-r", "alice", "repo-1"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR for repo-1: %v", err)
+	}
+
+	if want := []string{"alice", "octocat"}; !slices.Equal(pr.Reviewers, want) {
+		t.Errorf("Expected reviewers %v, got %v", want, pr.Reviewers)
+	}
+
+	if want := []string{"octocat"}; !slices.Equal(pr.Assignees, want) {
+		t.Errorf("Expected assignees %v, got %v", want, pr.Assignees)
+	}
+
+	// The login is cached for the rest of the run
+	provider.SetError("CurrentUser", errors.New("user lookup should be cached"))
+	if err := resolveCurrentUser(ctx); err != nil {
+		t.Errorf("Expected cached current user, got: %v", err)
+	}
+}
+
+func TestNewCommandRunWithLabelPolicy(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+
+	ctx, provider := setupTestContext(t, reposPath)
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Test PR Title")
+	viper.Set(config.LabelPolicies, map[string]any{
+		"infra": map[string]any{"base-branch": "release", "team-reviewers": []string{"infra-team"}},
+	})
+
+	catalog.Labels["infra"] = mapset.NewSet("repo-1")
+	t.Cleanup(func() { delete(catalog.Labels, "infra") })
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantBase map[string]string
+		wantTeam map[string]bool
+	}{
+		{
+			name:     "policy applies to labeled repos only",
+			args:     []string{"repo-1", "repo-2"},
+			wantBase: map[string]string{"repo-1": "release", "repo-2": "main"},
+			wantTeam: map[string]bool{"repo-1": true, "repo-2": false},
+		},
+		{
+			name:     "flags override policy",
+			args:     []string{"-b", "develop", "-R", "other-team", "repo-1"},
+			wantBase: map[string]string{"repo-1": "develop"},
+			wantTeam: map[string]bool{"repo-1": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider.PullRequests = make(map[string]*scm.PullRequest)
+
+			cmd := addNewCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			for repo, wantBase := range tt.wantBase {
+				pr, err := provider.GetPullRequest(repo, "feature-branch")
+				if err != nil {
+					t.Fatalf("Expected PR for %s: %v", repo, err)
+				}
+
+				if pr.BaseBranch != wantBase {
+					t.Errorf("Expected base branch %q for %s, got %q", wantBase, repo, pr.BaseBranch)
+				}
+
+				if got := slices.Contains(pr.TeamReviewers, "infra-team"); got != tt.wantTeam[repo] {
+					t.Errorf("Expected infra-team requested for %s: %v, got %v", repo, tt.wantTeam[repo], pr.TeamReviewers)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCommandRunSkipsPolicyBaseBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	testhelper.ExecCommand(t, filepath.Join(reposPath, "example.com", "test-project", "repo-1"), "git", "checkout", "-b", "release")
+
+	ctx, provider := setupTestContext(t, reposPath)
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Test PR Title")
+	viper.Set(config.LabelPolicies, map[string]any{
+		"~infra": map[string]any{"base-branch": "release"},
+	})
+
+	catalog.Labels["infra"] = mapset.NewSet("repo-1", "repo-2")
+	t.Cleanup(func() { delete(catalog.Labels, "infra") })
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"repo-1", "repo-2"})
+
+	testhelper.AssertError(t, cmd.ExecuteContext(ctx), true)
+	testhelper.AssertContains(t, buf.String(), "release is the base branch")
+
+	if _, err := provider.GetPullRequest("repo-1", "release"); err == nil {
+		t.Error("Expected no PR for repo-1, which is checked out on its policy base branch")
+	}
+
+	pr, err := provider.GetPullRequest("repo-2", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR for repo-2: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.BaseBranch, "release")
+}
+
+func TestNewCommandRunReviewersRequired(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Test PR Title")
+	viper.Set(config.DefaultReviewers, map[string][]string{"repo-1": {"alice"}})
+	viper.Set(config.DefaultTeamReviewers, map[string][]string{"repo-1": {"org/backend"}})
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--reviewers-required", "2", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err == nil {
+		t.Fatalf("Expected an error for the repository without reviewers\n%s", buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"pull request would have 0 reviewers (users and teams), but at least 2 are required"})
+
+	if !provider.HasPullRequest("repo-1", "feature-branch") {
+		t.Error("Expected PR for repo-1 with enough reviewers to be opened")
+	}
+
+	if provider.HasPullRequest("repo-2", "feature-branch") {
+		t.Error("Expected no PR for repo-2 without reviewers")
+	}
+}
+
+func TestNewCommandRunWithAutoMerge(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantMethod string
+		wantErr    string
+	}{
+		{name: "default method", args: []string{"--auto-merge", "repo-1"}, wantMethod: "merge"},
+		{name: "explicit method", args: []string{"--auto-merge=rebase", "repo-1"}, wantMethod: "rebase"},
+		{name: "method alias", args: []string{"--auto-merge=squash-merge", "repo-1"}, wantMethod: "squash"},
+		{name: "not requested", args: []string{"repo-1"}, wantMethod: ""},
+		{name: "unsupported method", args: []string{"--auto-merge=octopus", "repo-1"}, wantErr: `provider does not support merge method "octopus"`},
+		{name: "method without equals sign", args: []string{"--auto-merge", "squash", "repo-1"}, wantErr: "use --auto-merge=squash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+			config.Viper(ctx).Set(config.PrTitle, "Bump deps")
+
+			cmd := addNewCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.ExecuteContext(ctx)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing %q\n%s", tt.wantErr, buf.String())
+				}
+
+				testhelper.AssertContains(t, buf.String(), []string{tt.wantErr})
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+			if err != nil {
+				t.Fatalf("Expected PR: %v", err)
+			}
+
+			testhelper.AssertEqual(t, tt.wantMethod, pr.AutoMerge)
+
+			if tt.wantMethod != "" {
+				testhelper.AssertContains(t, buf.String(), []string{"Auto-merge: " + tt.wantMethod})
+			}
+		})
+	}
+}
+
+func TestNewCommandRunExistingPR(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	existing, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Existing PR"})
+	if err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-t", "New PR", "repo-1"})
+
+	if err := cmd.ExecuteContext(ctx); err == nil {
+		t.Fatal("Expected error when a pull request already exists")
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		fmt.Sprintf("a pull request already exists for branch feature-branch in repository repo-1 (PR #%d)", existing.Number),
+		"use 'pr edit' to update it",
+	})
+}
+
+func TestNewCommandRunWithCheckout(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	// the branch already exists locally in repo-1, and is created from the default branch in repo-2
+	testhelper.ExecCommand(t, utils.RepoPath(ctx, "repo-1"), "git", "branch", "chore/bump-deps")
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-t", "Bump deps", "--checkout", "chore/bump-deps", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		repoPath := utils.RepoPath(ctx, repo)
+
+		out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+		if err != nil {
+			t.Fatalf("Failed to lookup current branch: %v", err)
+		}
+
+		testhelper.AssertEqual(t, strings.TrimSpace(string(out)), "chore/bump-deps")
+
+		if err := exec.Command("git", "-C", repoPath, "ls-remote", "--exit-code", "origin", "chore/bump-deps").Run(); err != nil {
+			t.Errorf("Expected branch to be pushed to the remote for %s: %v", repo, err)
+		}
+
+		if !provider.HasPullRequest(repo, "chore/bump-deps") {
+			t.Errorf("Expected pull request to be opened from the checked out branch for %s", repo)
+		}
+	}
+}
+
+func TestNewCommandPrintSelection(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	// mount the pr command beneath a root, as its pre-run hook defers to the root's
+	cmd := &cobra.Command{Use: "batch-tool"}
+	cmd.AddCommand(Cmd())
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"pr", "new", "-t", "Test PR", "--print-selection", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertEqual(t, buf.String(), "repo-1\nrepo-2\n")
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		if provider.HasPullRequest(repo, "feature-branch") {
+			t.Errorf("Expected no pull request to be opened for %s when printing the selection", repo)
+		}
+	}
+}
+
+func TestNewCommandRunWithFill(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		commits   []string
+		wantTitle string
+		wantDesc  string
+	}{
+		{
+			name:      "single commit",
+			commits:   []string{"Fix the widget\n\nThe widget was broken."},
+			wantTitle: "Fix the widget",
+			wantDesc:  "The widget was broken.",
+		},
+		{
+			name:      "multiple commits",
+			commits:   []string{"Add the widget", "Fix the widget\n\nThe widget was broken."},
+			wantTitle: "Fix the widget",
+			wantDesc:  "- Add the widget\n- Fix the widget",
+		},
+		{
+			name:      "explicit title is kept",
+			args:      []string{"-t", "Widget changes"},
+			commits:   []string{"Fix the widget\n\nThe widget was broken."},
+			wantTitle: "Widget changes",
+			wantDesc:  "The widget was broken.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+			ctx, provider := setupTestContext(t, reposPath)
+
+			repoPath := utils.RepoPath(ctx, "repo-1")
+			for _, msg := range tt.commits {
+				testhelper.ExecCommand(t, repoPath, "git", "commit", "--allow-empty", "-m", msg)
+			}
+
+			cmd := addNewCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(append(append([]string{"--fill"}, tt.args...), "repo-1"))
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			pr, err := provider.GetPullRequest("repo-1", "feature-branch")
+			if err != nil {
+				t.Fatalf("Expected pull request to be opened: %v", err)
+			}
+
+			testhelper.AssertEqual(t, pr.Title, tt.wantTitle)
+			testhelper.AssertEqual(t, pr.Description, tt.wantDesc)
+		})
+	}
+}
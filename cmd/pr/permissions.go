@@ -0,0 +1,49 @@
+package pr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// warnMissingPermissions warns before running a write-capable command if the token appears to lack the permissions
+// to modify pull requests, so that a misconfigured token is noticed up front rather than as a failure in every
+// repository. The first selected repository of each project is checked. The check is advisory, so failures to
+// inspect the token are ignored.
+func warnMissingPermissions(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+	viper := config.Viper(ctx)
+
+	// read-only commands report their own failures, without the cost of inspecting the token first
+	if !viper.GetBool(config.PrCheckPermissions) || !call.IsAudited(cmd) {
+		return
+	}
+
+	checked := make(map[string]bool)
+
+	for _, name := range catalog.RepositoryNames(ctx, args...) {
+		repoName := utils.ResolveRepoName(name)
+
+		project := catalog.GetProjectForRepo(ctx, repoName)
+		if checked[project] {
+			continue
+		}
+
+		checked[project] = true
+
+		missing, err := scm.Get(ctx, viper.GetString(config.GitProvider), project).MissingPermissions(repoName, true)
+		if err != nil || len(missing) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: the token for project %s appears to lack %s (checked against %s); %s may fail\n",
+			project, strings.Join(missing, ", "), repoName, cmd.CommandPath())
+	}
+}
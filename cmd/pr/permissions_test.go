@@ -0,0 +1,90 @@
+package pr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestWarnMissingPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		missing  []string
+		err      error
+		disabled bool
+		want     string
+	}{
+		{name: "sufficient permissions"},
+		{
+			name:    "insufficient permissions",
+			missing: []string{"contents:write"},
+			want:    "WARNING: the token for project test-project appears to lack contents:write (checked against repo-1); batch-tool pr new may fail\n",
+		},
+		{name: "check disabled", missing: []string{"contents:write"}, disabled: true},
+		{name: "token can't be inspected", err: errors.New("bad credentials")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+			ctx, provider := setupTestContext(t, reposPath)
+
+			provider.Missing = tt.missing
+			if tt.err != nil {
+				provider.Errors["MissingPermissions"] = tt.err
+			}
+
+			config.Viper(ctx).Set(config.PrCheckPermissions, !tt.disabled)
+
+			// mount the pr command beneath a root, as its pre-run hook defers to the root's
+			cmd := &cobra.Command{Use: "batch-tool"}
+			cmd.AddCommand(Cmd())
+
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs([]string{"pr", "new", "-t", "Test PR", "--print-selection", "repo-1", "repo-2"})
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, stderr.String())
+			}
+
+			// the check is advisory, so the command still runs
+			testhelper.AssertEqual(t, stdout.String(), "repo-1\nrepo-2\n")
+			testhelper.AssertEqual(t, stderr.String(), tt.want)
+
+			// only the first repository of each project is checked
+			if count := strings.Count(stderr.String(), "WARNING"); count > 1 {
+				t.Errorf("Expected a single warning per project, got %d", count)
+			}
+		})
+	}
+}
+
+func TestWarnMissingPermissionsReadOnly(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+	provider.Missing = []string{"contents:write"}
+
+	cmd := &cobra.Command{Use: "batch-tool"}
+	cmd.AddCommand(Cmd())
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"pr", "get", "--print-selection", "repo-1"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, stderr.String())
+	}
+
+	// read-only commands don't check the token
+	testhelper.AssertEqual(t, stdout.String(), "repo-1\n")
+	testhelper.AssertEqual(t, stderr.String(), "")
+}
@@ -0,0 +1,112 @@
+package pr
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+)
+
+// lookupPolicy combines the pull request policies of all labels the given repository belongs to, falling back to the
+// policy of its project. Team reviewers are merged, while label policies which disagree on the base branch or merge
+// method are rejected.
+func lookupPolicy(ctx context.Context, name string) (config.PRPolicy, error) {
+	var result config.PRPolicy
+
+	labelPolicies, projectPolicy, err := repoPolicies(ctx, name)
+	if err != nil {
+		return result, err
+	}
+
+	for _, policy := range labelPolicies {
+		if policy.BaseBranch != "" {
+			if result.BaseBranch != "" && result.BaseBranch != policy.BaseBranch {
+				return result, fmt.Errorf("conflicting base branch policies for %s: %s and %s", name, result.BaseBranch, policy.BaseBranch)
+			}
+
+			result.BaseBranch = policy.BaseBranch
+		}
+
+		if policy.MergeMethod != "" {
+			if result.MergeMethod != "" && result.MergeMethod != policy.MergeMethod {
+				return result, fmt.Errorf("conflicting merge method policies for %s: %s and %s", name, result.MergeMethod, policy.MergeMethod)
+			}
+
+			result.MergeMethod = policy.MergeMethod
+		}
+
+		result.TeamReviewers = mergeReviewers(result.TeamReviewers, policy.TeamReviewers)
+	}
+
+	// the project's policy provides the settings which none of the labels set
+	result.BaseBranch = cmp.Or(result.BaseBranch, projectPolicy.BaseBranch)
+	result.MergeMethod = cmp.Or(result.MergeMethod, projectPolicy.MergeMethod)
+	result.TeamReviewers = mergeReviewers(result.TeamReviewers, projectPolicy.TeamReviewers)
+
+	return result, nil
+}
+
+// lookupBaseBranch returns the base branch of new pull requests for the given repository, which is set by the
+// --base-branch flag or else by the repository's policies. An empty branch means the repository's default branch.
+func lookupBaseBranch(ctx context.Context, name string, policy config.PRPolicy) string {
+	return cmp.Or(config.Viper(ctx).GetString(config.PrBaseBranch), policy.BaseBranch)
+}
+
+// lookupMergeMethod resolves only the merge method from the pull request policies of the given repository, in the
+// same way as lookupPolicy, so that policies which disagree on other settings don't prevent merging.
+func lookupMergeMethod(ctx context.Context, name string) (string, error) {
+	labelPolicies, projectPolicy, err := repoPolicies(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	var method string
+	for _, policy := range labelPolicies {
+		if policy.MergeMethod == "" {
+			continue
+		}
+
+		if method != "" && method != policy.MergeMethod {
+			return "", fmt.Errorf("conflicting merge method policies for %s: %s and %s", name, method, policy.MergeMethod)
+		}
+
+		method = policy.MergeMethod
+	}
+
+	return cmp.Or(method, projectPolicy.MergeMethod), nil
+}
+
+// repoPolicies returns the pull request policies of the labels the given repository belongs to, in the sorted order
+// of the labels, along with the policy of its project (which is empty if the project is also one of the labels).
+// The policy of a label may be keyed by its name or by the label token (e.g. "~infra"), like the default reviewers.
+func repoPolicies(ctx context.Context, name string) ([]config.PRPolicy, config.PRPolicy, error) {
+	policies, err := config.LoadPRPolicies(ctx)
+	if err != nil || len(policies) == 0 {
+		return nil, config.PRPolicy{}, err
+	}
+
+	labels := catalog.GetLabelsForRepo(name)
+	sort.Strings(labels)
+
+	tokenLabel := config.Viper(ctx).GetString(config.TokenLabel)
+
+	var labelPolicies []config.PRPolicy
+	for _, label := range labels {
+		for _, key := range []string{label, tokenLabel + label} {
+			if policy, ok := policies[key]; ok {
+				labelPolicies = append(labelPolicies, policy)
+			}
+		}
+	}
+
+	project := catalog.GetProjectForRepo(ctx, name)
+	if slices.Contains(labels, project) {
+		return labelPolicies, config.PRPolicy{}, nil
+	}
+
+	return labelPolicies, policies[project], nil
+}
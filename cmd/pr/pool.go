@@ -0,0 +1,96 @@
+package pr
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const (
+	reviewerPoolFlag      = "reviewer-pool"
+	reviewerPoolCountFlag = "reviewer-pool-count"
+	reviewerPoolSeedFlag  = "reviewer-pool-seed"
+)
+
+// buildReviewerPoolFlags adds the flags for balancing reviewer load across a pool.
+func buildReviewerPoolFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(reviewerPoolFlag, nil, "pool of reviewers to assign round-robin across the batch (repeatable)")
+	cmd.Flags().Int(reviewerPoolCountFlag, 1, "number of reviewers to assign from the pool to each pull request")
+	cmd.Flags().Int64(reviewerPoolSeedFlag, 0, "seed used to shuffle the reviewer pool for reproducible assignment")
+}
+
+// parseReviewerPoolFlags binds the reviewer pool flags to their configuration keys.
+func parseReviewerPoolFlags(cmd *cobra.Command) {
+	viper := config.Viper(cmd.Context())
+
+	viper.BindPFlag(config.PrReviewerPool, cmd.Flags().Lookup(reviewerPoolFlag))
+	viper.BindPFlag(config.PrPoolCount, cmd.Flags().Lookup(reviewerPoolCountFlag))
+	viper.BindPFlag(config.PrPoolSeed, cmd.Flags().Lookup(reviewerPoolSeedFlag))
+}
+
+// buildPoolAssignment distributes reviewers from the configured pool across the repositories
+// selected by args, storing the resulting per-repository assignment in the configuration.
+func buildPoolAssignment(ctx context.Context, args []string) {
+	viper := config.Viper(ctx)
+
+	pool := viper.GetStringSlice(config.PrReviewerPool)
+	if len(pool) == 0 {
+		return
+	}
+
+	var repos []string
+	if len(args) == 1 && strings.TrimSpace(args[0]) == "." {
+		repos = []string{utils.ResolveRepoName(".")}
+	} else {
+		repos = catalog.RepositoryNames(ctx, args...)
+	}
+
+	viper.Set(config.PrPoolAssignment, assignReviewers(repos, pool, viper.GetInt(config.PrPoolCount), viper.GetInt64(config.PrPoolSeed)))
+}
+
+// assignReviewers assigns count reviewers from the pool to each repository in round-robin order, so that
+// each reviewer receives an even share of the pull requests. Repositories are sorted and the pool is shuffled
+// using the given seed, making the assignment deterministic regardless of the order in which repositories run.
+func assignReviewers(repos, pool []string, count int, seed int64) map[string][]string {
+	pool = slices.Clone(pool)
+	sort.Strings(pool)
+	pool = slices.Compact(pool)
+
+	rng := rand.New(rand.NewPCG(uint64(seed), 0)) //nolint:gosec // deterministic shuffle, not security sensitive
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	count = max(1, min(count, len(pool)))
+
+	repos = slices.Clone(repos)
+	sort.Strings(repos)
+
+	assignment := make(map[string][]string, len(repos))
+	for i, repo := range repos {
+		reviewers := make([]string, count)
+		for j := range reviewers {
+			reviewers[j] = pool[(i*count+j)%len(pool)]
+		}
+
+		assignment[repo] = reviewers
+	}
+
+	return assignment
+}
+
+// poolReviewers returns the reviewers assigned to the given repository from the reviewer pool, if any.
+func poolReviewers(ctx context.Context, name string) []string {
+	assignment, ok := config.Viper(ctx).Get(config.PrPoolAssignment).(map[string][]string)
+	if !ok {
+		return nil
+	}
+
+	return assignment[name]
+}
@@ -0,0 +1,133 @@
+package pr
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestAssignReviewers(t *testing.T) {
+	pool := []string{"alice", "bob", "carol", "dave"}
+
+	tests := []struct {
+		name     string
+		repos    int
+		count    int
+		wantMin  int // minimum number of PRs assigned to each reviewer
+		wantMax  int // maximum number of PRs assigned to each reviewer
+		wantSize int // number of reviewers assigned to each PR
+	}{
+		{name: "one reviewer per PR", repos: 8, count: 1, wantMin: 2, wantMax: 2, wantSize: 1},
+		{name: "two reviewers per PR", repos: 8, count: 2, wantMin: 4, wantMax: 4, wantSize: 2},
+		{name: "uneven batch", repos: 10, count: 1, wantMin: 2, wantMax: 3, wantSize: 1},
+		{name: "count exceeds pool", repos: 3, count: 10, wantMin: 3, wantMax: 3, wantSize: 4},
+		{name: "count below minimum", repos: 4, count: 0, wantMin: 1, wantMax: 1, wantSize: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := make([]string, tt.repos)
+			for i := range repos {
+				repos[i] = fmt.Sprintf("repo-%02d", i)
+			}
+
+			assignment := assignReviewers(repos, pool, tt.count, 42)
+			testhelper.AssertLength(t, assignment, tt.repos)
+
+			load := make(map[string]int)
+			for repo, reviewers := range assignment {
+				if len(reviewers) != tt.wantSize {
+					t.Errorf("Expected %d reviewers for %s, got %v", tt.wantSize, repo, reviewers)
+				}
+
+				if len(slices.Compact(slices.Sorted(slices.Values(reviewers)))) != len(reviewers) {
+					t.Errorf("Expected distinct reviewers for %s, got %v", repo, reviewers)
+				}
+
+				for _, reviewer := range reviewers {
+					load[reviewer]++
+				}
+			}
+
+			for _, reviewer := range pool {
+				if load[reviewer] < tt.wantMin || load[reviewer] > tt.wantMax {
+					t.Errorf("Expected %s to review between %d and %d PRs, got %d", reviewer, tt.wantMin, tt.wantMax, load[reviewer])
+				}
+			}
+		})
+	}
+}
+
+func TestAssignReviewersDeterministic(t *testing.T) {
+	repos := []string{"repo-c", "repo-a", "repo-b", "repo-d"}
+	pool := []string{"alice", "bob", "carol"}
+
+	first := assignReviewers(repos, pool, 1, 7)
+
+	// The same seed yields the same assignment regardless of input order
+	reversed := slices.Clone(repos)
+	slices.Reverse(reversed)
+	if got := assignReviewers(reversed, []string{"carol", "alice", "bob"}, 1, 7); !reflect.DeepEqual(got, first) {
+		t.Errorf("Expected identical assignment for the same seed, got %v and %v", first, got)
+	}
+
+	// Different seeds shuffle the pool differently
+	differs := false
+	for seed := int64(0); seed < 10; seed++ {
+		if !reflect.DeepEqual(assignReviewers(repos, pool, 1, seed), first) {
+			differs = true
+			break
+		}
+	}
+
+	if !differs {
+		t.Error("Expected different seeds to produce different assignments")
+	}
+}
+
+func TestNewCommandRunWithReviewerPool(t *testing.T) {
+	repos := []string{"repo-1", "repo-2", "repo-3", "repo-4"}
+	reposPath := testhelper.SetupRepos(t, repos, true)
+
+	ctx, provider := setupTestContext(t, reposPath)
+	viper := config.Viper(ctx)
+	viper.Set(config.PrTitle, "Test PR Title")
+
+	cmd := addNewCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs(append([]string{"--reviewer-pool", "alice,bob", "--reviewer-pool-seed", "3"}, repos...))
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	want := assignReviewers(repos, []string{"alice", "bob"}, 1, 3)
+	load := make(map[string]int)
+
+	for _, repo := range repos {
+		pr, err := provider.GetPullRequest(repo, "feature-branch")
+		if err != nil {
+			t.Fatalf("Expected PR for %s: %v", repo, err)
+		}
+
+		if !reflect.DeepEqual(pr.Reviewers, want[repo]) {
+			t.Errorf("Expected reviewers %v for %s, got %v", want[repo], repo, pr.Reviewers)
+		}
+
+		for _, reviewer := range pr.Reviewers {
+			load[reviewer]++
+		}
+	}
+
+	if load["alice"] != 2 || load["bob"] != 2 {
+		t.Errorf("Expected reviewers to be evenly distributed, got %v", load)
+	}
+}
@@ -7,17 +7,21 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/ryclarke/batch-tool/call"
 	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
 	"github.com/ryclarke/batch-tool/scm"
 	"github.com/ryclarke/batch-tool/utils"
 )
 
 const (
+	prBranchFlag       = "branch"
 	prTitleFlag        = "title"
 	prDescriptionFlag  = "description"
 	prReviewerFlag     = "reviewer"
 	prTeamReviewerFlag = "team-reviewer"
+	prLabelFlag        = "label"
 	prDraftFlag        = "draft"
 	prNoDraftFlag      = "no-" + prDraftFlag
 )
@@ -41,16 +45,27 @@ Authentication:
 
 Branch Validation:
   PR commands validate that you're not on the default branch before executing.
-  Use feature branches for pull requests.`,
+  Use feature branches for pull requests.
+
+Branch Resolution:
+  The branch used to find each pull request is the --branch flag if provided,
+  otherwise the current checkout of each repository, falling back to the
+  repository's default branch from the catalog.`,
 		Example: `  # Get PR information
   batch-tool pr get repo1 repo2
 
+  # Find open PRs by title
+  batch-tool pr find --title-contains "bump deps" repo1 repo2
+
   # Create new PRs with title and reviewers
   batch-tool pr new -t "Add feature" -r alice -r bob repo1 repo2
 
   # Update existing PRs
   batch-tool pr edit -t "Updated title" -d "New description" repo1
 
+  # Copy a PR description from one repository to others
+  batch-tool pr sync-description --from repo1 repo2 repo3
+
   # Merge approved PRs
   batch-tool pr merge repo1 repo2`,
 		Args: cobra.MinimumNArgs(1),
@@ -62,18 +77,48 @@ Branch Validation:
 				}
 			}
 
-			return utils.ValidateRequiredConfig(cmd.Context(), config.AuthToken)
+			config.Viper(cmd.Context()).BindPFlag(config.Branch, cmd.Flags().Lookup(prBranchFlag))
+
+			// the token may be obtained from a credential helper instead of being set directly
+			if token, err := scm.AuthToken(cmd.Context()); err != nil {
+				return err
+			} else if token == "" {
+				return fmt.Errorf("%s is required - set as flag or env, or configure a %s", config.AuthToken, config.CredentialHelper)
+			}
+
+			warnMissingPermissions(cmd, args)
+
+			return nil
 		},
 	}
 
+	prCmd.PersistentFlags().String(prBranchFlag, "", "source branch of the pull request (default: current branch of each repository)")
+
+	// record the flags which identify the pull requests and the changes made to them
+	output.RecordFlags(prCmd, prBranchFlag, prTitleFlag, prReviewerFlag, prTeamReviewerFlag, prLabelFlag, baseBranchFlag, methodFlag,
+		noCheckFlag, approvedFlag, updateFlag, deleteFlag, resetReviewersFlag, resetLabelsFlag, mergeDryRunFlag, findTitleFlag, onlyIfTitleFlag)
+
 	prCmd.AddCommand(
 		addGetCmd(),
-		addNewCmd(),
-		addEditCmd(),
-		addMergeCmd(),
+		addFindCmd(),
+		call.Audited(addNewCmd()),
+		call.Audited(addEditCmd()),
+		call.Audited(addSyncDescriptionCmd()),
+		call.Audited(addMergeCmd()),
 	)
 
-	return prCmd
+	return call.PrintsSelection(prCmd)
+}
+
+// lookupBranch resolves the source branch of the pull request for the given repository. An explicit
+// --branch flag takes precedence, followed by the current checkout of the repository, and finally
+// the default branch from the catalog (e.g. when the repository is in a detached HEAD state).
+func lookupBranch(ctx context.Context, name string) string {
+	if branch, err := utils.LookupBranch(ctx, name); err == nil && branch != "" && branch != "HEAD" {
+		return branch
+	}
+
+	return catalog.GetBranchForRepo(ctx, utils.ResolveRepoName(name))
 }
 
 func prOptions(ctx context.Context, name string, merge bool) scm.PROptions {
@@ -100,6 +145,17 @@ func prOptions(ctx context.Context, name string, merge bool) scm.PROptions {
 	return opts
 }
 
+// renderPROptions renders the title and description of the pull request options as templates for the repository.
+func renderPROptions(ctx context.Context, name string, opts *scm.PROptions) (err error) {
+	if opts.Title, err = utils.RenderTemplate(ctx, name, opts.Title); err != nil {
+		return err
+	}
+
+	opts.Description, err = utils.RenderTemplate(ctx, name, opts.Description)
+
+	return err
+}
+
 func buildPROptions(cmd *cobra.Command) {
 	viper := config.Viper(cmd.Context())
 
@@ -110,14 +166,25 @@ func buildPROptions(cmd *cobra.Command) {
 
 		Reviewers:      viper.GetStringSlice(config.PrReviewers),
 		TeamReviewers:  viper.GetStringSlice(config.PrTeamReviewers),
+		Assignees:      currentAssignees(cmd.Context()),
+		Labels:         viper.GetStringSlice(config.PrLabels),
 		ResetReviewers: viper.GetBool(config.PrResetReviewers),
+		ResetLabels:    viper.GetBool(config.PrResetLabels),
 
 		Merge: scm.PRMergeOptions{
 			Method:         viper.GetString(config.PrMergeMethod),
 			CheckMergeable: viper.GetBool(config.PrMergeCheck),
+			UpdateBranch:   viper.GetBool(config.PrMergeUpdate),
+			DryRun:         viper.GetBool(config.PrMergeDryRun),
 		},
 	}
 
+	// Enable auto-merge with the requested method, resolving aliases (e.g. squash-merge)
+	if method := viper.GetString(config.PrAutoMerge); method != "" {
+		opts.AutoMerge = true
+		opts.AutoMergeMethod = scm.NormalizeMergeMethod(method, viper.GetStringMapString(config.PrMergeMethodAliases))
+	}
+
 	// Set draft option if flag was explicitly provided
 	if cmd.Flags().Changed(prDraftFlag) || cmd.Flags().Changed(prNoDraftFlag) {
 		draft := viper.GetBool(config.PrDraft)
@@ -142,6 +209,11 @@ func printPRInfo(pr *scm.PullRequest, header string, verbose bool) string {
 		fmt.Fprintf(&info, "(PR #%d) %s %v\n", pr.Number, pr.Title, pr.Reviewers)
 	}
 
+	// always report reviewers rejected by the provider, since they were silently left off the pull request
+	if len(pr.SkippedReviewers) > 0 {
+		fmt.Fprintf(&info, "Skipped invalid reviewers: %s\n", strings.Join(pr.SkippedReviewers, ", "))
+	}
+
 	if verbose && (pr.Branch != "" || pr.BaseBranch != "") {
 		head, base := pr.Branch, pr.BaseBranch
 		if head == "" {
@@ -154,6 +226,10 @@ func printPRInfo(pr *scm.PullRequest, header string, verbose bool) string {
 		fmt.Fprintf(&info, "Branch: %s → %s\n", head, base)
 	}
 
+	if verbose && pr.AutoMerge != "" {
+		fmt.Fprintf(&info, "Auto-merge: %s\n", pr.AutoMerge)
+	}
+
 	// print description if verbose and description is not empty
 	if verbose && pr.Description != "" {
 		fmt.Fprintf(&info, "Description:\n%s\n", pr.Description)
@@ -169,14 +245,43 @@ func parseCommonPRFlags(cmd *cobra.Command) error {
 	viper.BindPFlag(config.PrDescription, cmd.Flags().Lookup(prDescriptionFlag))
 	viper.BindPFlag(config.PrReviewers, cmd.Flags().Lookup(prReviewerFlag))
 	viper.BindPFlag(config.PrTeamReviewers, cmd.Flags().Lookup(prTeamReviewerFlag))
+	viper.BindPFlag(config.PrLabels, cmd.Flags().Lookup(prLabelFlag))
+
+	// route @-prefixed reviewers (e.g. -r @org/team) to the team reviewers
+	if users, teams := splitReviewers(viper.GetStringSlice(config.PrReviewers)); len(teams) > 0 {
+		viper.Set(config.PrReviewers, users)
+		viper.Set(config.PrTeamReviewers, mergeReviewers(viper.GetStringSlice(config.PrTeamReviewers), teams))
+	}
+
+	parseReviewerPoolFlags(cmd)
+	parseCurrentUserFlags(cmd)
 
 	return utils.BindBoolFlags(cmd, config.PrDraft, prDraftFlag, prNoDraftFlag)
 }
 
+// splitReviewers separates the given reviewers into users and teams, where teams are prefixed with "@" (e.g. "@org/team").
+// The prefix is removed from the returned teams.
+func splitReviewers(reviewers []string) (users, teams []string) {
+	users = make([]string, 0, len(reviewers))
+
+	for _, reviewer := range reviewers {
+		if team, ok := strings.CutPrefix(reviewer, "@"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, reviewer)
+		}
+	}
+
+	return users, teams
+}
+
 func buildCommonPRFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP(prTitleFlag, "t", "", "pull request title")
 	cmd.Flags().StringP(prDescriptionFlag, "d", "", "pull request description")
-	cmd.Flags().StringSliceP(prReviewerFlag, "r", nil, "pull request reviewer (repeatable)")
+	cmd.Flags().StringSliceP(prReviewerFlag, "r", nil, "pull request reviewer, or team reviewer when prefixed with @ (repeatable)")
 	cmd.Flags().StringSliceP(prTeamReviewerFlag, "R", nil, "pull request team reviewer (repeatable)")
+	cmd.Flags().StringSliceP(prLabelFlag, "L", nil, "pull request label (repeatable)")
 	utils.BuildBoolFlagsDefault(cmd, prDraftFlag, "", prNoDraftFlag, "", false, "mark pull request as a draft")
+	buildReviewerPoolFlags(cmd)
+	buildCurrentUserFlags(cmd)
 }
@@ -1,11 +1,19 @@
 package pr
 
 import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
 func TestPrCmd(t *testing.T) {
@@ -22,7 +30,7 @@ func TestPrCmdSubcommands(t *testing.T) {
 	cmd := Cmd()
 
 	subcommands := cmd.Commands()
-	expectedCommands := []string{"new", "edit", "merge"}
+	expectedCommands := []string{"new", "edit", "merge", "find", "sync-description"}
 
 	if len(subcommands) < len(expectedCommands) {
 		t.Errorf("Expected at least %d subcommands, got %d", len(expectedCommands), len(subcommands))
@@ -52,6 +60,10 @@ func TestPrCmdFlags(t *testing.T) {
 			t.Errorf("Did not expect root pr command to expose %q flag", name)
 		}
 	}
+
+	if cmd.PersistentFlags().Lookup(prBranchFlag) == nil {
+		t.Errorf("Expected root pr command to expose persistent %q flag", prBranchFlag)
+	}
 }
 
 func TestBuildCommonPRFlags(t *testing.T) {
@@ -135,6 +147,54 @@ func TestParseCommonPRFlags(t *testing.T) {
 	}
 }
 
+func TestSplitReviewers(t *testing.T) {
+	tests := []struct {
+		name      string
+		reviewers []string
+		wantUsers string
+		wantTeams string
+	}{
+		{name: "users only", reviewers: []string{"alice", "bob"}, wantUsers: "alice,bob"},
+		{name: "teams only", reviewers: []string{"@my-org/platform", "@security"}, wantTeams: "my-org/platform,security"},
+		{name: "mixed", reviewers: []string{"alice", "@my-org/platform", "bob", "@security"}, wantUsers: "alice,bob", wantTeams: "my-org/platform,security"},
+		{name: "empty", reviewers: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, teams := splitReviewers(tt.reviewers)
+
+			testhelper.AssertEqual(t, strings.Join(users, ","), tt.wantUsers)
+			testhelper.AssertEqual(t, strings.Join(teams, ","), tt.wantTeams)
+		})
+	}
+}
+
+func TestParseCommonPRFlagsTeamShorthand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	buildCommonPRFlags(cmd)
+
+	if err := cmd.Flags().Set("reviewer", "alice,@my-org/platform,bob,@my-org/security"); err != nil {
+		t.Fatalf("Failed to set reviewer flag: %v", err)
+	}
+
+	if err := cmd.Flags().Set("team-reviewer", "my-org/platform,my-org/infra"); err != nil {
+		t.Fatalf("Failed to set team-reviewer flag: %v", err)
+	}
+
+	if err := parseCommonPRFlags(cmd); err != nil {
+		t.Fatalf("parseCommonPRFlags failed: %v", err)
+	}
+
+	// teams given with either flag are combined without duplicates
+	testhelper.AssertEqual(t, strings.Join(viper.GetStringSlice(config.PrReviewers), ","), "alice,bob")
+	testhelper.AssertEqual(t, strings.Join(viper.GetStringSlice(config.PrTeamReviewers), ","), "my-org/platform,my-org/infra,my-org/security")
+}
+
 func TestPrCmdArgs(t *testing.T) {
 	loadFixture(t)
 
@@ -174,3 +234,110 @@ func TestPrCmdPersistentPreRunE(t *testing.T) {
 		t.Error("Expected error when auth token is not set")
 	}
 }
+
+func TestLookupBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2", "repo-3"}, true)
+	repoDir := func(name string) string {
+		return filepath.Join(reposPath, "example.com", "test-project", name)
+	}
+
+	// repo-1 stays on feature-branch, repo-2 moves to its own branch, repo-3 is detached
+	testhelper.ExecCommand(t, repoDir("repo-2"), "git", "checkout", "-b", "other-branch")
+	testhelper.ExecCommand(t, repoDir("repo-3"), "git", "checkout", "--detach")
+
+	catalog.Catalog["repo-3"] = scm.Repository{Name: "repo-3", Project: "test-project", DefaultBranch: "develop"}
+	t.Cleanup(func() { delete(catalog.Catalog, "repo-3") })
+
+	tests := []struct {
+		name   string
+		repo   string
+		branch string
+		want   string
+	}{
+		{name: "explicit branch takes precedence", repo: "repo-2", branch: "explicit-branch", want: "explicit-branch"},
+		{name: "current checkout", repo: "repo-1", want: "feature-branch"},
+		{name: "current checkout differs per repo", repo: "repo-2", want: "other-branch"},
+		{name: "detached head falls back to catalog", repo: "repo-3", want: "develop"},
+		{name: "missing repo falls back to default", repo: "repo-missing", want: "main"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := setupTestContext(t, reposPath)
+			viper := config.Viper(ctx)
+			viper.Set(config.Branch, tt.branch)
+			viper.Set(config.DefaultBranch, "main")
+
+			if got := lookupBranch(ctx, tt.repo); got != tt.want {
+				t.Errorf("lookupBranch(%q) = %q, want %q", tt.repo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrCommandsPartialFailure(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+
+	commands := []struct {
+		name    string
+		newCmd  func() *cobra.Command
+		success string
+	}{
+		{name: "get", newCmd: addGetCmd, success: "Test Title"},
+		{name: "edit", newCmd: addEditCmd, success: "Updated pull request"},
+		{name: "merge", newCmd: addMergeCmd, success: "Merged pull request"},
+	}
+
+	for _, command := range commands {
+		t.Run(command.name, func(t *testing.T) {
+			tests := []struct {
+				name    string
+				prRepos []string
+				wantErr string
+			}{
+				{
+					name:    "all repositories succeed",
+					prRepos: []string{"repo-1", "repo-2"},
+				},
+				{
+					name:    "one repository fails",
+					prRepos: []string{"repo-1"},
+					wantErr: "1 of 2 repositories failed",
+				},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx, provider := setupTestContext(t, reposPath)
+
+					for _, repo := range tt.prRepos {
+						if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Test Title"}); err != nil {
+							t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+						}
+					}
+
+					cmd := command.newCmd()
+
+					var buf bytes.Buffer
+					cmd.SetOut(&buf)
+					cmd.SetErr(&buf)
+					cmd.SetArgs([]string{"repo-1", "repo-2"})
+
+					err := cmd.ExecuteContext(ctx)
+					testhelper.AssertError(t, err, tt.wantErr != "")
+
+					// the repositories with a pull request are processed regardless of failures
+					testhelper.AssertContains(t, buf.String(), command.success)
+
+					if tt.wantErr != "" {
+						if !errors.Is(err, &call.Error{}) {
+							t.Errorf("Expected a call.Error, got %T: %v", err, err)
+						}
+
+						testhelper.AssertContains(t, err.Error(), tt.wantErr)
+					}
+				})
+			}
+		})
+	}
+}
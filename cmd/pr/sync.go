@@ -0,0 +1,147 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const (
+	syncFromFlag  = "from"
+	syncTitleFlag = "title"
+)
+
+// addSyncDescriptionCmd initializes the pr sync-description command
+func addSyncDescriptionCmd() *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync-description --from <repository> [--title] <repository>...",
+		Short: "Copy a pull request description to other pull requests",
+		Long: `Copy the description of one repository's pull request to the pull requests of other repositories.
+
+This is useful when coordinating a change across repositories: write the
+canonical description in one pull request, then propagate it to the rest.
+The source pull request is located the same way as the targets, using --branch
+or the current checkout of the source repository.
+
+Use --title to also copy the title. Pull requests which already match the
+source are skipped, as is the source repository if it is also selected.`,
+		Example: `  # Copy the description of the api pull request to the other platform repositories
+  batch-tool pr sync-description --from api '~platform'
+
+  # Copy both the title and the description
+  batch-tool pr sync-description --from api --title repo1 repo2`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: catalog.CompletionFunc(),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			viper := config.Viper(cmd.Context())
+
+			viper.BindPFlag(config.PrSyncFrom, cmd.Flags().Lookup(syncFromFlag))
+			viper.BindPFlag(config.PrSyncTitle, cmd.Flags().Lookup(syncTitleFlag))
+
+			if viper.GetString(config.PrSyncFrom) == "" {
+				return fmt.Errorf("--%s is required", syncFromFlag)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadSyncSource(cmd.Context()); err != nil {
+				return err
+			}
+
+			return call.Do(cmd, args, SyncDescription)
+		},
+	}
+
+	syncCmd.Flags().String(syncFromFlag, "", "repository whose pull request description is copied")
+	syncCmd.Flags().Bool(syncTitleFlag, false, "also copy the pull request title")
+
+	return syncCmd
+}
+
+// loadSyncSource retrieves the pull request of the source repository and stores its description
+// (and title, if requested) as the PR options applied to each target.
+func loadSyncSource(ctx context.Context) error {
+	viper := config.Viper(ctx)
+
+	source := viper.GetString(config.PrSyncFrom)
+	repoName := utils.ResolveRepoName(source)
+
+	project := catalog.GetProjectForRepo(ctx, repoName)
+	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
+
+	// look up the branch of the source in a child context, since it is cached in the config which the targets inherit
+	pr, err := provider.GetPullRequest(repoName, lookupBranch(config.SetChild(ctx), source))
+	if err != nil {
+		return fmt.Errorf("failed to get source pull request from %s: %w", repoName, err)
+	}
+
+	if pr.Description == "" {
+		return fmt.Errorf("source pull request (PR #%d) in %s has no description", pr.Number, repoName)
+	}
+
+	opts := scm.PROptions{Description: pr.Description}
+	if viper.GetBool(config.PrSyncTitle) {
+		opts.Title = pr.Title
+	}
+
+	viper.Set(config.PrOptions, opts)
+
+	return nil
+}
+
+// SyncDescription copies the description (and optionally the title) of the source pull request
+// to the pull request for the given repository.
+func SyncDescription(ctx context.Context, ch output.Channel) error {
+	viper := config.Viper(ctx)
+	repoName := utils.ResolveRepoName(ch.Name())
+
+	if repoName == utils.ResolveRepoName(viper.GetString(config.PrSyncFrom)) {
+		ch.Skip("source of the description")
+
+		return nil
+	}
+
+	// Get project from repository metadata in catalog, fall back to default
+	project := catalog.GetProjectForRepo(ctx, repoName)
+	provider := scm.Get(ctx, viper.GetString(config.GitProvider), project)
+
+	branch := lookupBranch(ctx, ch.Name())
+
+	pr, err := provider.GetPullRequest(repoName, branch)
+	if err != nil {
+		return err
+	}
+
+	source := prOptions(ctx, repoName, false)
+
+	// keep the current title unless it is being synced
+	opts := scm.PROptions{Title: pr.Title, Description: source.Description}
+	if source.Title != "" {
+		opts.Title = source.Title
+	}
+
+	if opts.Title == pr.Title && opts.Description == pr.Description {
+		fmt.Fprintf(ch, "Pull request (PR #%d) %s is already in sync\n", pr.Number, pr.Title)
+		ch.Skip("already in sync")
+
+		return nil
+	}
+
+	updated, err := provider.UpdatePullRequest(repoName, branch, &opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(ch, printPRInfo(updated, "Synced pull request", false))
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package pr
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestSyncDescriptionCommandRun(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2", "repo-3"}, true)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantTitles map[string]string
+		wantOutput []string
+	}{
+		{
+			name:       "description only",
+			args:       []string{"--from", "repo-1", "repo-2", "repo-3"},
+			wantTitles: map[string]string{"repo-2": "Title for repo-2", "repo-3": "Title for repo-3"},
+			wantOutput: []string{"Synced pull request", "Title for repo-2", "Title for repo-3"},
+		},
+		{
+			name:       "with title",
+			args:       []string{"--from", "repo-1", "--title", "repo-2", "repo-3"},
+			wantTitles: map[string]string{"repo-2": "Canonical title", "repo-3": "Canonical title"},
+			wantOutput: []string{"Synced pull request", "Canonical title"},
+		},
+		{
+			name:       "source repository selected",
+			args:       []string{"--from", "repo-1", "repo-1", "repo-2"},
+			wantTitles: map[string]string{"repo-1": "Canonical title", "repo-2": "Title for repo-2"},
+			wantOutput: []string{"Skipped:\n", "  repo-1: source of the description"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+
+			if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Canonical title", Description: "Canonical description"}); err != nil {
+				t.Fatalf("Failed to create source PR: %v", err)
+			}
+
+			for _, repo := range []string{"repo-2", "repo-3"} {
+				if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Title for " + repo, Description: "Stale description"}); err != nil {
+					t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+				}
+			}
+
+			cmd := addSyncDescriptionCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+			}
+
+			testhelper.AssertContains(t, buf.String(), tt.wantOutput)
+
+			for repo, title := range tt.wantTitles {
+				pr, err := provider.GetPullRequest(repo, "feature-branch")
+				if err != nil {
+					t.Fatalf("Expected PR for %s: %v", repo, err)
+				}
+
+				testhelper.AssertEqual(t, pr.Title, title)
+				testhelper.AssertEqual(t, pr.Description, "Canonical description")
+			}
+		})
+	}
+}
+
+// TestSyncDescriptionSourceBranch tests that the targets use their own branch rather than the source's
+func TestSyncDescriptionSourceBranch(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	testhelper.ExecCommand(t, filepath.Join(reposPath, "example.com", "test-project", "repo-1"), "git", "checkout", "-b", "source-branch")
+
+	ctx, provider := setupTestContext(t, reposPath)
+	config.Viper(ctx).Set(config.Branch, "") // use the current branch of each repository
+
+	if _, err := provider.OpenPullRequest("repo-1", "source-branch", &scm.PROptions{Title: "Canonical title", Description: "Canonical description"}); err != nil {
+		t.Fatalf("Failed to create source PR: %v", err)
+	}
+
+	if _, err := provider.OpenPullRequest("repo-2", "feature-branch", &scm.PROptions{Title: "Title for repo-2", Description: "Stale description"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	cmd := addSyncDescriptionCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--from", "repo-1", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	pr, err := provider.GetPullRequest("repo-2", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR for repo-2: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Description, "Canonical description")
+}
+
+func TestSyncDescriptionAlreadyInSync(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+	ctx, provider := setupTestContext(t, reposPath)
+
+	for _, repo := range []string{"repo-1", "repo-2"} {
+		if _, err := provider.OpenPullRequest(repo, "feature-branch", &scm.PROptions{Title: "Same title", Description: "Same description"}); err != nil {
+			t.Fatalf("Failed to create test PR for %s: %v", repo, err)
+		}
+	}
+
+	cmd := addSyncDescriptionCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"--from", "repo-1", "--title", "repo-2"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Command execution failed: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"is already in sync", "  repo-2: already in sync"})
+
+	pr, err := provider.GetPullRequest("repo-2", "feature-branch")
+	if err != nil {
+		t.Fatalf("Expected PR for repo-2: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Version, 1) // not updated
+}
+
+func TestSyncDescriptionErrors(t *testing.T) {
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1", "repo-2"}, true)
+
+	tests := []struct {
+		name    string
+		args    []string
+		source  *scm.PROptions
+		wantErr string
+	}{
+		{
+			name:    "missing source flag",
+			args:    []string{"repo-2"},
+			wantErr: "--from is required",
+		},
+		{
+			name:    "missing source pull request",
+			args:    []string{"--from", "repo-1", "repo-2"},
+			wantErr: "failed to get source pull request from repo-1",
+		},
+		{
+			name:    "empty source description",
+			args:    []string{"--from", "repo-1", "repo-2"},
+			source:  &scm.PROptions{Title: "No description"},
+			wantErr: "has no description",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, provider := setupTestContext(t, reposPath)
+
+			if tt.source != nil {
+				if _, err := provider.OpenPullRequest("repo-1", "feature-branch", tt.source); err != nil {
+					t.Fatalf("Failed to create source PR: %v", err)
+				}
+			}
+
+			cmd := addSyncDescriptionCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, true)
+			testhelper.AssertContains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
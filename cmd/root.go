@@ -23,15 +23,25 @@ import (
 	"github.com/ryclarke/batch-tool/utils"
 
 	// Register SCM providers
+	_ "github.com/ryclarke/batch-tool/scm/azuredevops"
 	_ "github.com/ryclarke/batch-tool/scm/bitbucket"
+	_ "github.com/ryclarke/batch-tool/scm/gitea"
 	_ "github.com/ryclarke/batch-tool/scm/github"
+	_ "github.com/ryclarke/batch-tool/scm/gitlab"
+	_ "github.com/ryclarke/batch-tool/scm/rest"
 )
 
 const (
-	configFlag = "config"
-	styleFlag  = "style"
-	printFlag  = "print"
-	envFlag    = "env"
+	configFlag      = "config"
+	styleFlag       = "style"
+	printFlag       = "print"
+	fileFlag        = "output-file"
+	groupFlag       = "group-by-label"
+	summaryFlag     = "summary-only-on-success"
+	summaryJSONFlag = "summary-json"
+	logDirFlag      = "log-dir"
+	envFlag         = "env"
+	varsFlag        = "template-vars"
 
 	waitFlag   = "wait"
 	noWaitFlag = "no-" + waitFlag
@@ -43,11 +53,26 @@ const (
 	noSortFlag = "no-" + sortFlag
 
 	maxConcurrencyFlag = "max-concurrency"
+	batchSizeFlag      = "batch-size"
 	syncFlag           = "sync"
 
-	catalogFlushFlag = "flush"
+	allowEmptyFlag    = "allow-empty"
+	branchPatternFlag = "branch-pattern"
+
+	noCacheFlag = "no-cache"
+	noSaveFlag  = "no-save"
+	refreshFlag = "refresh"
+
+	labelsUnusedFlag = "unused"
+
+	catalogFlushFlag  = "flush"
+	catalogDryRunFlag = "dry-run"
 )
 
+// skipCatalogInit is the annotation of commands which fetch the catalog themselves, so that it isn't
+// initialized before they run.
+const skipCatalogInit = "skip-catalog-init"
+
 // RootCmd configures the top-level root command along with all subcommands and flags
 func RootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
@@ -73,6 +98,9 @@ Repository Selection:
 
     Note: ~all is an implicit alias that includes all tracked repositories.
 
+    Label names may be globs, selecting every matching label (with any prefix).
+    Examples: ~legacy-* !~legacy-* +~legacy-api*
+
   Force Include (+ prefix):
     Include repositories or labels that would normally be excluded/unwanted.
     Examples: +excluded-repo +~unwanted-label
@@ -81,26 +109,60 @@ Repository Selection:
     Explicitly exclude specific repositories or labels from selection.
     Examples: !problem-repo !~experimental
 
+  Branch Filter (--branch-pattern):
+    Select only repositories in which a matching branch exists, either in the
+    local clone or on the remote. The pattern is a branch name or a glob.
+    Example: batch-tool pr merge ~backend --branch-pattern 'feature/*'
+
   Combining Selectors:
     Mix and match different selection methods in a single command.
     Example: batch-tool git status repo1 ~backend +special !~experimental
 
 Shell Note:
-  Special characters (!, +, ~) may need escaping depending on your shell.
+  Special characters (!, +, ~, *) may need escaping depending on your shell.
   For Bash/Zsh, use quotes or backslashes: '!repo' or \!repo`,
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			viper := config.Viper(cmd.Context())
 
 			viper.BindPFlag(config.OutputStyle, cmd.Flags().Lookup(styleFlag))
 			viper.BindPFlag(config.PrintResults, cmd.Flags().Lookup(printFlag))
+			viper.BindPFlag(config.OutputFile, cmd.Flags().Lookup(fileFlag))
+			viper.BindPFlag(config.GroupByLabel, cmd.Flags().Lookup(groupFlag))
+			viper.BindPFlag(config.SummaryOnly, cmd.Flags().Lookup(summaryFlag))
+			viper.BindPFlag(config.SummaryJSON, cmd.Flags().Lookup(summaryJSONFlag))
+			viper.BindPFlag(config.LogDir, cmd.Flags().Lookup(logDirFlag))
 			viper.BindPFlag(config.MaxConcurrency, cmd.Flags().Lookup(maxConcurrencyFlag))
+			viper.BindPFlag(config.BatchSize, cmd.Flags().Lookup(batchSizeFlag))
 			viper.BindPFlag(config.CmdEnv, cmd.Flags().Lookup(envFlag))
+			viper.BindPFlag(config.TemplateVars, cmd.Flags().Lookup(varsFlag))
+			viper.BindPFlag(config.AllowEmpty, cmd.Flags().Lookup(allowEmptyFlag))
+			viper.BindPFlag(config.BranchPattern, cmd.Flags().Lookup(branchPatternFlag))
+			bindCatalogFlags(cmd.Context(), cmd.Root())
 
 			// Validate output style is a valid selection
 			if err := utils.ValidateEnumConfig(cmd, config.OutputStyle, output.AvailableStyles); err != nil {
 				return err
 			}
 
+			// Stream plain output in place of the TUI when there's no terminal to display it
+			setPlainOutput(cmd)
+
+			// Validate the repository layout before any paths are derived from it
+			if err := utils.ValidateEnumConfig(cmd, config.GitLayout, utils.AvailableLayouts); err != nil {
+				return err
+			}
+
+			// Load the template vars file once for the run, reporting an unreadable file up front rather than once
+			// per repository
+			if path := viper.GetString(config.TemplateVars); path != "" {
+				vars, err := config.LoadTemplateVars(path)
+				if err != nil {
+					return err
+				}
+
+				cmd.SetContext(config.WithTemplateVars(cmd.Context(), path, vars))
+			}
+
 			// Don't allow both --max-concurrency and --sync to be set together
 			if err := utils.CheckMutuallyExclusiveFlags(cmd, maxConcurrencyFlag, syncFlag); err != nil {
 				return err
@@ -129,7 +191,9 @@ Shell Note:
 	// Add all subcommands to the root
 	rootCmd.AddCommand(
 		catalogCmd(),
+		doctorCmd(),
 		labelsCmd(),
+		runCmd(),
 		exec.Cmd(),
 		git.Cmd(),
 		make.Cmd(),
@@ -139,9 +203,22 @@ Shell Note:
 	rootCmd.PersistentFlags().StringVar(&config.CfgFile, configFlag, "", "config file (default is batch-tool.yaml)")
 	rootCmd.PersistentFlags().StringP(styleFlag, "o", output.TUI, fmt.Sprintf("output style: \"%v\"", strings.Join(output.AvailableStyles, "\", \"")))
 	rootCmd.PersistentFlags().BoolP(printFlag, "p", false, "print results to stdout after processing is complete")
+	rootCmd.PersistentFlags().String(fileFlag, "", "write the combined output of the run to a file")
+	rootCmd.PersistentFlags().Bool(groupFlag, false, "group the TUI output under the labels used to select the repositories")
+	rootCmd.PersistentFlags().Bool(summaryFlag, false, "print only the run summary if every repository succeeded, or the full output if any failed")
+	rootCmd.PersistentFlags().String(summaryJSONFlag, "", "write a JSON summary of the run to a file, or to stderr if \"-\"")
+	rootCmd.PersistentFlags().String(logDirFlag, "", "write the output and errors of each repository to a log file in this directory")
 	rootCmd.PersistentFlags().Int(maxConcurrencyFlag, runtime.NumCPU(), "maximum number of concurrent operations")
+	rootCmd.PersistentFlags().Int(batchSizeFlag, 0, "process repositories in batches of this size, finishing each batch before starting the next (0 for unlimited)")
 	rootCmd.PersistentFlags().Bool(syncFlag, false, "execute commands synchronously (same as --max-concurrency=1)")
 	rootCmd.PersistentFlags().StringSliceP(envFlag, "e", []string{}, "environment variables to set for command execution")
+	output.RedactFlag(rootCmd.PersistentFlags(), envFlag)
+	rootCmd.PersistentFlags().String(varsFlag, "", "YAML or JSON file of variables for command and pull request templates (available as .Vars)")
+	rootCmd.PersistentFlags().Bool(allowEmptyFlag, false, "proceed without error when no repositories match the provided filters")
+	rootCmd.PersistentFlags().String(branchPatternFlag, "", "select only repositories with a branch matching this name or glob, locally or on the remote")
+	rootCmd.PersistentFlags().Bool(noCacheFlag, false, "ignore the local catalog cache and fetch fresh repository data")
+	rootCmd.PersistentFlags().Bool(noSaveFlag, false, "do not write fetched repository data to the local catalog cache")
+	rootCmd.PersistentFlags().Bool(refreshFlag, false, "refetch repository data and rewrite the local catalog cache, even if it hasn't expired")
 
 	utils.BuildBoolFlags(rootCmd, waitFlag, "", noWaitFlag, "q", "wait for user to exit after processing is complete")
 	utils.BuildBoolFlags(rootCmd, skipUnwantedFlag, "", noSkipUnwantedFlag, "", "skip configured undesired labels")
@@ -154,25 +231,65 @@ Shell Note:
 // This is called by main.main(). It only needs to happen once to the RootCmd.
 func Execute() {
 	ctx := config.Init(context.Background())
+	rootCmd := RootCmd()
+
+	// The catalog is initialized before any pre-run hooks, so bind its flags up front
+	bindCatalogFlags(ctx, rootCmd)
+
+	// Show a spinner while repository data is fetched, since the first uncached command may otherwise appear to hang
+	catalog.NewFetchProgress = output.NewFetchProgress
 	cobra.OnInitialize(func() {
-		catalog.Init(ctx, false)
+		if needsCatalogInit(rootCmd, os.Args[1:]) {
+			catalog.Init(ctx, false)
+		}
 	})
 
-	rootCmd := RootCmd()
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
-		// Only print usage for setup or argument-parsing errors.
-		// Printing help for runtime errors would be redundant and confusing.
-		if !errors.Is(err, &call.Error{}) {
-			_ = rootCmd.UsageFunc()(rootCmd)
-
-			fmt.Println(err)
-			os.Exit(1)
-		}
+	if code := execute(ctx, rootCmd); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// needsCatalogInit reports whether the catalog must be initialized before running the command given by the
+// arguments, which isn't the case for commands that fetch it themselves (they would otherwise fetch it twice).
+func needsCatalogInit(rootCmd *cobra.Command, args []string) bool {
+	cmd, _, err := rootCmd.Find(args)
+
+	return err != nil || cmd.Annotations[skipCatalogInit] == ""
+}
 
-		// Runtime errors from call package
-		fmt.Println(err)
-		os.Exit(2)
+// execute runs the root command and reports any error, returning the exit status of the process.
+func execute(ctx context.Context, rootCmd *cobra.Command) int {
+	err := rootCmd.ExecuteContext(ctx)
+	if err == nil {
+		return 0
 	}
+
+	// Only print usage for setup or argument-parsing errors.
+	// Printing help for runtime errors would be redundant and confusing.
+	if !errors.Is(err, &call.Error{}) {
+		_ = rootCmd.UsageFunc()(rootCmd)
+
+		fmt.Fprintln(rootCmd.OutOrStdout(), err)
+		return 1
+	}
+
+	// Runtime errors from call package, which are kept off stdout with JSON output so that it remains valid JSON
+	out := rootCmd.OutOrStdout()
+	if config.Viper(ctx).GetString(config.OutputStyle) == output.JSON {
+		out = rootCmd.ErrOrStderr()
+	}
+
+	fmt.Fprintln(out, err)
+	return 2
+}
+
+// bindCatalogFlags binds the catalog cache flags of the root command to their configuration keys.
+func bindCatalogFlags(ctx context.Context, rootCmd *cobra.Command) {
+	viper := config.Viper(ctx)
+
+	viper.BindPFlag(config.CatalogNoCache, rootCmd.PersistentFlags().Lookup(noCacheFlag))
+	viper.BindPFlag(config.CatalogNoSave, rootCmd.PersistentFlags().Lookup(noSaveFlag))
+	viper.BindPFlag(config.CatalogRefresh, rootCmd.PersistentFlags().Lookup(refreshFlag))
 }
 
 // setTerminalWait handles auto-detection for non-interactive environments.
@@ -200,6 +317,21 @@ func setTerminalWait(cmd *cobra.Command) error {
 	return utils.BindBoolFlags(cmd, config.WaitOnExit, waitFlag, noWaitFlag)
 }
 
+// setPlainOutput selects the plain output style in non-interactive environments (e.g. CI), where the TUI would
+// garble the log or hang without a terminal, unless a style was explicitly chosen on the command line.
+func setPlainOutput(cmd *cobra.Command) {
+	viper := config.Viper(cmd.Context())
+
+	if cmd.Flags().Changed(styleFlag) || viper.GetString(config.OutputStyle) != output.TUI {
+		return
+	}
+
+	stdoutFd := os.Stdout.Fd()
+	if stdoutFd > math.MaxInt || !term.IsTerminal(int(stdoutFd)) { //nolint:gosec // bounds checked above
+		viper.Set(config.OutputStyle, output.Plain)
+	}
+}
+
 // labelsCmd configures the labels command
 func labelsCmd() *cobra.Command {
 	labelsCmd := &cobra.Command{
@@ -232,15 +364,35 @@ Filter Testing:
 
 Verbose Mode:
   The -v/--verbose flag expands label references to show all repositories
-  that would be included by each label in your filter expression.`,
+  that would be included by each label in your filter expression.
+
+Unused Labels:
+  The --unused flag lists the labels which are empty or only match unwanted
+  or archived repositories (as configured by repos.skip-unwanted and
+  repos.skip-archived), so that stale labels and aliases can be cleaned up.`,
 		Example: `# Test a label filter
   batch-tool labels ~web ~db
 
   # Test complex filter with exclusions and verbose output
-  batch-tool labels -v ~all !~experimental`,
+  batch-tool labels -v ~all !~experimental
+
+  # List labels which no longer select any repositories
+  batch-tool labels --unused`,
 		Args:              cobra.ArbitraryArgs,
 		ValidArgsFunction: catalog.CompletionFunc(),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if unused, err := cmd.Flags().GetBool(labelsUnusedFlag); err != nil {
+				return err
+			} else if unused {
+				if len(args) > 0 {
+					return fmt.Errorf("--%s doesn't accept filter arguments", labelsUnusedFlag)
+				}
+
+				printUnusedLabels(cmd)
+
+				return nil
+			}
+
 			// Import command(s) from the CLI flag
 			verbose, err := cmd.Flags().GetBool("verbose")
 			if err != nil {
@@ -256,10 +408,30 @@ Verbose Mode:
 	}
 
 	labelsCmd.Flags().BoolP("verbose", "v", false, "expand labels referenced in the given filter")
+	labelsCmd.Flags().Bool(labelsUnusedFlag, false, "list labels which are empty or only match unwanted or archived repositories")
 
 	return labelsCmd
 }
 
+// printUnusedLabels reports the labels which are empty or only match repositories excluded by default.
+func printUnusedLabels(cmd *cobra.Command) {
+	unused := catalog.UnusedLabels(cmd.Context())
+	if len(unused) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No unused labels found")
+		return
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Found %d unused labels:\n", len(unused))
+
+	for _, label := range unused {
+		if label.Repos == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s (no repositories)\n", label.Name)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s (%d unwanted or archived repositories)\n", label.Name, label.Repos)
+		}
+	}
+}
+
 // catalogCmd configures the catalog command
 func catalogCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -293,7 +465,16 @@ Cache Management:
   batch-tool catalog
 
   # Force refresh the catalog cache
-  batch-tool catalog -f`,
+  batch-tool catalog -f
+
+  # Refetch the catalog and summarize what changed since it was cached
+  batch-tool catalog refresh
+
+  # Preview what changed upstream before refreshing
+  batch-tool catalog diff
+
+  # Remove repositories which no longer exist upstream
+  batch-tool catalog prune`,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, _ []string) {
 			if flush, err := cmd.Flags().GetBool(catalogFlushFlag); err == nil && flush {
@@ -307,5 +488,162 @@ Cache Management:
 
 	cmd.Flags().BoolP(catalogFlushFlag, "f", false, "force refresh of catalog cache")
 
+	cmd.AddCommand(catalogPruneCmd())
+	cmd.AddCommand(catalogDiffCmd())
+	cmd.AddCommand(catalogRefreshCmd())
+
 	return cmd
 }
+
+// catalogPruneCmd configures the catalog prune command
+func catalogPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune [--dry-run]",
+		Short: "Remove stale repositories from the cached catalog",
+		Long: `Remove stale repositories from the cached repository catalog.
+
+When repositories are deleted or renamed upstream, the local catalog cache
+retains the old entries until it expires. This command refreshes the catalog
+from your configured SCM provider and removes any cached repositories which
+are no longer returned, along with their label references.
+
+Use the --dry-run flag to report stale repositories without modifying the cache.`,
+		Example: `  # Prune stale repositories from the catalog
+  batch-tool catalog prune
+
+  # Report stale repositories without removing them
+  batch-tool catalog prune --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, err := cmd.Flags().GetBool(catalogDryRunFlag)
+			if err != nil {
+				return err
+			}
+
+			pruned, err := catalog.Prune(cmd.Context(), dryRun)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case len(pruned) == 0:
+				fmt.Fprintln(cmd.OutOrStdout(), "No stale repositories found in the catalog")
+			case dryRun:
+				fmt.Fprintf(cmd.OutOrStdout(), "Would prune %d repositories from the catalog:\n  %s\n", len(pruned), strings.Join(pruned, "\n  "))
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d repositories from the catalog:\n  %s\n", len(pruned), strings.Join(pruned, "\n  "))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(catalogDryRunFlag, false, "report stale repositories without removing them")
+
+	return cmd
+}
+
+// catalogDiffCmd configures the catalog diff command
+func catalogDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the cached catalog with live repository data",
+		Long: `Compare the cached repository catalog with live data from your SCM provider.
+
+This command fetches the current repositories from your configured projects
+and reports the repositories added or removed upstream, along with any labels
+added to or removed from existing repositories, since the catalog was cached.
+The cache is not modified, so you can review the changes before refreshing it
+with 'batch-tool catalog -f' or 'batch-tool catalog prune'.`,
+		Example: `  # Show what changed upstream since the catalog was cached
+  batch-tool catalog diff`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			changes, err := catalog.Diff(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), formatCatalogChanges(changes))
+
+			return nil
+		},
+	}
+}
+
+// catalogRefreshCmd configures the catalog refresh command
+func catalogRefreshCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh",
+		Short: "Refetch the catalog and rewrite its cache",
+		Long: `Refetch the repository catalog from your SCM provider and rewrite its cache.
+
+The catalog cache is normally only refreshed when it expires (based on TTL),
+so changes made upstream, such as renamed labels, may not be visible until
+then. This command fetches the current repositories from your configured
+projects regardless of the TTL, replaces the cached catalog, and reports the
+repositories and labels added or removed since it was cached.`,
+		Example: `  # Refetch the catalog and summarize what changed
+  batch-tool catalog refresh`,
+		Args: cobra.NoArgs,
+		// An expired cache is compared with live data rather than refetched beforehand
+		Annotations: map[string]string{skipCatalogInit: "true"},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			changes, err := catalog.Refresh(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), formatCatalogChanges(changes))
+
+			return nil
+		},
+	}
+}
+
+// formatCatalogChanges describes the differences between the cached catalog and live data.
+func formatCatalogChanges(changes *catalog.Changes) string {
+	if changes.Empty() {
+		return "The catalog is up to date\n"
+	}
+
+	var out strings.Builder
+
+	if len(changes.Added) > 0 {
+		fmt.Fprintf(&out, "Added %d repositories:\n", len(changes.Added))
+		for _, name := range changes.Added {
+			fmt.Fprintf(&out, "  + %s\n", name)
+		}
+	}
+
+	if len(changes.Removed) > 0 {
+		fmt.Fprintf(&out, "Removed %d repositories:\n", len(changes.Removed))
+		for _, name := range changes.Removed {
+			fmt.Fprintf(&out, "  - %s\n", name)
+		}
+	}
+
+	if len(changes.AddedLabels) > 0 {
+		fmt.Fprintf(&out, "Added %d labels: %s\n", len(changes.AddedLabels), strings.Join(changes.AddedLabels, ", "))
+	}
+
+	if len(changes.RemovedLabels) > 0 {
+		fmt.Fprintf(&out, "Removed %d labels: %s\n", len(changes.RemovedLabels), strings.Join(changes.RemovedLabels, ", "))
+	}
+
+	if len(changes.Relabeled) > 0 {
+		fmt.Fprintf(&out, "Changed labels of %d repositories:\n", len(changes.Relabeled))
+		for _, change := range changes.Relabeled {
+			fmt.Fprintf(&out, "  ~ %s:", change.Repo)
+			for _, label := range change.Added {
+				fmt.Fprintf(&out, " +%s", label)
+			}
+			for _, label := range change.Removed {
+				fmt.Fprintf(&out, " -%s", label)
+			}
+			fmt.Fprintln(&out)
+		}
+	}
+
+	return out.String()
+}
@@ -3,14 +3,21 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/output"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/scm/fake"
 	"github.com/ryclarke/batch-tool/utils"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
@@ -204,6 +211,78 @@ func TestSyncFlagOverridesMaxConcurrency(t *testing.T) {
 	}
 }
 
+func TestPlainOutputWithoutTerminal(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		style string
+		want  string
+	}{
+		{name: "tui falls back to plain", args: []string{"catalog"}, style: output.TUI, want: output.Plain},
+		{name: "explicit style is kept", args: []string{"--style", "tui", "catalog"}, style: output.TUI, want: output.TUI},
+		{name: "configured native is kept", args: []string{"catalog"}, style: output.Native, want: output.Native},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.OutputStyle, tt.style)
+
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+
+			// stdout isn't a terminal when running tests
+			cmd.SetArgs(tt.args)
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+
+			testhelper.AssertEqual(t, config.Viper(ctx).GetString(config.OutputStyle), tt.want)
+		})
+	}
+}
+
+func TestAllowEmptyFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name:    "filter matching nothing",
+			args:    []string{"git", "status", "~nonexistent"},
+			wantErr: true,
+		},
+		{
+			name: "filter matching nothing with allow-empty",
+			args: []string{"--allow-empty", "git", "status", "~nonexistent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+
+			cmd.SetArgs(tt.args)
+			err := cmd.ExecuteContext(ctx)
+
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if tt.wantErr {
+				testhelper.AssertContains(t, err.Error(), []string{"no repositories matched: ~nonexistent"})
+			}
+		})
+	}
+}
+
 func TestNoSortFlagOverridesSortConfig(t *testing.T) {
 	ctx := loadFixture(t)
 	cmd := RootCmd()
@@ -374,6 +453,7 @@ func TestAllSubcommandsPresent(t *testing.T) {
 	subcommands := cmd.Commands()
 	expectedCommands := map[string]bool{
 		"catalog": false,
+		"doctor":  false,
 		"git":     false,
 		"pr":      false,
 		"make":    false,
@@ -413,6 +493,240 @@ func TestCatalogCommandOutput(t *testing.T) {
 	// Note: catalog output goes directly to fmt.Printf, not cmd.OutOrStdout()
 }
 
+func TestCatalogPruneCommand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	viper.Set(config.GitProvider, "fake-prune-cmd")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.CatalogCachePath, filepath.Join(t.TempDir(), "cache.json"))
+	testhelper.SetupFakeProviderWithRepos(t, ctx, "fake-prune-cmd", "test-project", []*scm.Repository{
+		{Name: "repo-1", Project: "test-project"},
+	})
+
+	originalCatalog, originalLabels := catalog.Catalog, catalog.Labels
+	t.Cleanup(func() { catalog.Catalog, catalog.Labels = originalCatalog, originalLabels })
+
+	tests := []struct {
+		name      string
+		args      []string
+		want      []string
+		wantGhost bool
+	}{
+		{
+			name:      "dry run reports stale repos",
+			args:      []string{"catalog", "prune", "--dry-run"},
+			want:      []string{"Would prune 1 repositories", "test-project/ghost"},
+			wantGhost: true,
+		},
+		{
+			name: "prune removes stale repos",
+			args: []string{"catalog", "prune"},
+			want: []string{"Pruned 1 repositories", "test-project/ghost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog.Catalog = map[string]scm.Repository{
+				"test-project/repo-1": {Name: "repo-1", Project: "test-project"},
+				"test-project/ghost":  {Name: "ghost", Project: "test-project"},
+			}
+			catalog.Labels = make(map[string]mapset.Set[string])
+
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("catalog prune failed: %v", err)
+			}
+
+			testhelper.AssertContains(t, buf.String(), tt.want)
+
+			if _, ok := catalog.Catalog["test-project/ghost"]; ok != tt.wantGhost {
+				t.Errorf("Expected ghost repo in catalog: %v, got: %v", tt.wantGhost, ok)
+			}
+		})
+	}
+}
+
+func TestLabelsUnusedCommand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.SkipUnwanted, true)
+	viper.Set(config.UnwantedLabels, []string{"deprecated"})
+
+	originalCatalog, originalLabels := catalog.Catalog, catalog.Labels
+	t.Cleanup(func() { catalog.Catalog, catalog.Labels = originalCatalog, originalLabels })
+
+	catalog.Catalog = map[string]scm.Repository{
+		"test-project/repo-1": {Name: "repo-1", Project: "test-project"},
+		"test-project/repo-2": {Name: "repo-2", Project: "test-project"},
+	}
+	catalog.Labels = map[string]mapset.Set[string]{
+		"deprecated": mapset.NewSet("test-project/repo-2"),
+		"backend":    mapset.NewSet("test-project/repo-1"),
+		"legacy":     mapset.NewSet("test-project/repo-2"),
+		"empty":      mapset.NewSet[string](),
+	}
+
+	cmd := RootCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"labels", "--unused"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("labels --unused failed: %v", err)
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{"Found 2 unused labels", "empty (no repositories)", "legacy (1 unwanted or archived repositories)"})
+	testhelper.AssertNotContains(t, buf.String(), []string{"backend", "deprecated"})
+
+	cmd = RootCmd()
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"labels", "--unused", "~backend"})
+
+	testhelper.AssertError(t, cmd.ExecuteContext(ctx), true)
+}
+
+func TestCatalogDiffCommand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	viper.Set(config.GitProvider, "fake-diff-cmd")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.CatalogCachePath, filepath.Join(t.TempDir(), "cache.json"))
+	testhelper.SetupFakeProviderWithRepos(t, ctx, "fake-diff-cmd", "test-project", []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", Labels: []string{"backend", "go"}},
+		{Name: "repo-2", Project: "test-project"},
+	})
+
+	originalCatalog, originalLabels := catalog.Catalog, catalog.Labels
+	t.Cleanup(func() { catalog.Catalog, catalog.Labels = originalCatalog, originalLabels })
+
+	tests := []struct {
+		name   string
+		cached map[string]scm.Repository
+		want   []string
+	}{
+		{
+			name: "reports changes",
+			cached: map[string]scm.Repository{
+				"test-project/repo-1": {Name: "repo-1", Project: "test-project", Labels: []string{"backend", "legacy"}},
+				"test-project/ghost":  {Name: "ghost", Project: "test-project"},
+			},
+			want: []string{
+				"Added 1 repositories:\n  + test-project/repo-2",
+				"Removed 1 repositories:\n  - test-project/ghost",
+				"Changed labels of 1 repositories:\n  ~ test-project/repo-1: +go -legacy",
+			},
+		},
+		{
+			name: "up to date",
+			cached: map[string]scm.Repository{
+				"test-project/repo-1": {Name: "repo-1", Project: "test-project", Labels: []string{"go", "backend"}},
+				"test-project/repo-2": {Name: "repo-2", Project: "test-project"},
+			},
+			want: []string{"The catalog is up to date"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog.Catalog = tt.cached
+			catalog.Labels = make(map[string]mapset.Set[string])
+
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"catalog", "diff"})
+
+			if err := cmd.ExecuteContext(ctx); err != nil {
+				t.Fatalf("catalog diff failed: %v", err)
+			}
+
+			testhelper.AssertContains(t, buf.String(), tt.want)
+
+			// the cached catalog is left as it was
+			testhelper.AssertLength(t, catalog.Catalog, len(tt.cached))
+		})
+	}
+}
+
+func TestCatalogRefreshCommand(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	viper.Set(config.GitProvider, "fake-refresh-cmd")
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.CatalogCachePath, filepath.Join(t.TempDir(), "cache.json"))
+	testhelper.SetupFakeProviderWithRepos(t, ctx, "fake-refresh-cmd", "test-project", []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", Labels: []string{"backend", "go"}},
+		{Name: "repo-2", Project: "test-project"},
+	})
+
+	originalCatalog, originalLabels := catalog.Catalog, catalog.Labels
+	t.Cleanup(func() { catalog.Catalog, catalog.Labels = originalCatalog, originalLabels })
+
+	catalog.Catalog = map[string]scm.Repository{
+		"test-project/repo-1": {Name: "repo-1", Project: "test-project", Labels: []string{"backend", "legacy"}},
+		"test-project/ghost":  {Name: "ghost", Project: "test-project"},
+	}
+	catalog.Labels = make(map[string]mapset.Set[string])
+
+	cmd := RootCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"catalog", "refresh"})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("catalog refresh failed: %v", err)
+	}
+
+	testhelper.AssertContains(t, buf.String(), []string{
+		"Added 1 repositories:\n  + test-project/repo-2",
+		"Removed 1 repositories:\n  - test-project/ghost",
+		"Added 1 labels: go",
+		"Removed 1 labels: legacy",
+	})
+
+	// the catalog is replaced with the live data
+	testhelper.AssertLength(t, catalog.Catalog, 2)
+	if _, ok := catalog.Catalog["test-project/ghost"]; ok {
+		t.Error("Expected ghost repo to be removed from the catalog")
+	}
+}
+
+func TestNeedsCatalogInit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"catalog", []string{"catalog"}, true},
+		{"catalog refresh", []string{"catalog", "refresh"}, false},
+		{"catalog refresh with flags", []string{"--refresh", "catalog", "refresh"}, false},
+		{"unknown command", []string{"unknown"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertEqual(t, needsCatalogInit(RootCmd(), tt.args), tt.want)
+		})
+	}
+}
+
 func TestLongDescription(t *testing.T) {
 	_ = loadFixture(t)
 	cmd := RootCmd()
@@ -548,3 +862,80 @@ func TestSetTerminalWait(t *testing.T) {
 		})
 	}
 }
+
+func TestDoctorProviderCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "authorized",
+			want: []string{"✓ test-project: authorized as octocat", "✓ other-project: authorized as octocat"},
+		},
+		{
+			name:    "auth error",
+			err:     fmt.Errorf("401 Unauthorized\nbad credentials"),
+			want:    []string{"✗ test-project: 401 Unauthorized", "✗ other-project: 401 Unauthorized"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			viper := config.Viper(ctx)
+
+			providerName := "fake-doctor-" + strings.ReplaceAll(tt.name, " ", "-")
+			viper.Set(config.GitProvider, providerName)
+			viper.Set(config.GitProject, "test-project")
+			viper.Set(config.GitProjects, []string{"other-project"})
+
+			provider := testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", nil).(*fake.Fake)
+			provider.User = "octocat"
+			if tt.err != nil {
+				provider.Errors = map[string]error{"CurrentUser": tt.err}
+			}
+
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"doctor", "provider"})
+
+			err := cmd.ExecuteContext(ctx)
+			testhelper.AssertError(t, err, tt.wantErr)
+			testhelper.AssertContains(t, buf.String(), tt.want)
+			testhelper.AssertNotContains(t, buf.String(), []string{"bad credentials"})
+
+			if tt.wantErr && !strings.Contains(err.Error(), "2 of 2 projects failed") {
+				t.Errorf("Expected failure count in error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestExecuteJSONFailure(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+
+	cmd := RootCmd()
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"--style", "json", "exec", "-y", "-c", `test "$BATCH_REPO" = repo1`, "repo1", "repo2"})
+
+	testhelper.AssertEqual(t, execute(ctx, cmd), 2)
+
+	// the failure is reported on stderr, so that stdout remains valid JSON
+	var results []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("Expected stdout to be valid JSON: %v\n%s", err, buf.String())
+	}
+
+	testhelper.AssertLength(t, results, 2)
+	testhelper.AssertContains(t, errBuf.String(), []string{"1 of 2 repositories failed"})
+}
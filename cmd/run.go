@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/config"
+)
+
+const runCommand = "run"
+
+// runCmd configures the run command
+func runCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   runCommand + " <batchfile>",
+		Short: "Execute a sequence of batch operations from a file",
+		Long: `Execute a sequence of batch operations described in a YAML batch file.
+
+Each step runs a batch-tool command against a repository selection, sharing
+the same repository catalog. Steps are executed in the order they are defined.
+
+Batch File Format:
+  on-failure: stop          # default failure policy: "stop" or "continue"
+  steps:
+    - name: Update dependencies
+      command: exec         # batch-tool subcommand, e.g. "exec" or "pr new"
+      args: [-y, -c, "go get -u ./..."]
+      repos: ["~backend"]
+      config:               # configuration overrides for this step only
+        channels.max-concurrency: 2
+      on-failure: continue  # failure policy override for this step
+
+Failure Policy:
+  With the "stop" policy, no further steps are executed after a step fails.
+  With the "continue" policy, the remaining steps are executed and the run
+  reports the number of failed steps when complete.`,
+		Example: `  # Execute the steps in a batch file
+  batch-tool run release.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			batch, err := config.LoadBatchFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			return runBatch(cmd, batch)
+		},
+	}
+
+	return cmd
+}
+
+// runBatch executes each step of the batch file in order, following the configured failure policy.
+func runBatch(cmd *cobra.Command, batch *config.BatchFile) error {
+	var failed int
+
+	for i, step := range batch.Steps {
+		name := step.Name
+		if name == "" {
+			name = step.Command
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "==> Step %d/%d: %s\n", i+1, len(batch.Steps), name)
+
+		if err := runStep(cmd, step); err != nil {
+			failed++
+
+			if batch.Policy(step) == config.BatchStop {
+				return call.NewError(fmt.Errorf("step %d (%s) failed: %w", i+1, name, err))
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Step %d (%s) failed: %v\n", i+1, name, err)
+		}
+	}
+
+	if failed > 0 {
+		return call.NewError(fmt.Errorf("%d of %d steps failed", failed, len(batch.Steps)))
+	}
+
+	return nil
+}
+
+// runStep executes a single batch step using a fresh command tree and an isolated configuration.
+func runStep(cmd *cobra.Command, step config.BatchStep) error {
+	args := strings.Fields(step.Command)
+	if len(args) == 0 || args[0] == runCommand {
+		return fmt.Errorf("invalid batch command %q", step.Command)
+	}
+
+	args = append(args, step.Args...)
+	args = append(args, step.Repos...)
+
+	v, err := config.Overlay(cmd.Context(), step.Config)
+	if err != nil {
+		return fmt.Errorf("invalid config overrides: %w", err)
+	}
+
+	stepCmd := RootCmd()
+	stepCmd.SetArgs(args)
+	stepCmd.SetIn(cmd.InOrStdin())
+	stepCmd.SetOut(cmd.OutOrStdout())
+	stepCmd.SetErr(cmd.ErrOrStderr())
+	stepCmd.SilenceUsage = true
+
+	return stepCmd.ExecuteContext(config.SetViper(cmd.Context(), v))
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/call"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	"github.com/ryclarke/batch-tool/scm/fake"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// setupRunTest configures temp repositories and a fake provider with an open PR for repo-1
+func setupRunTest(t *testing.T) context.Context {
+	t.Helper()
+
+	reposPath := testhelper.SetupRepos(t, []string{"repo-1"}, true)
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	providerName := "fake-run-" + t.Name()
+	viper.Set(config.GitProvider, providerName)
+	viper.Set(config.GitProject, "test-project")
+	viper.Set(config.GitHost, "example.com")
+	viper.Set(config.GitDirectory, reposPath)
+	viper.Set(config.AuthToken, "")
+
+	provider := testhelper.SetupFakeProviderWithRepos(t, ctx, providerName, "test-project", []*scm.Repository{
+		{Name: "repo-1", Project: "test-project", DefaultBranch: "main"},
+	}).(*fake.Fake)
+
+	if _, err := provider.OpenPullRequest("repo-1", "feature-branch", &scm.PROptions{Title: "Batch PR"}); err != nil {
+		t.Fatalf("Failed to create test PR: %v", err)
+	}
+
+	return ctx
+}
+
+func writeRunFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "batch.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write batch file: %v", err)
+	}
+
+	return path
+}
+
+func TestRunCommand(t *testing.T) {
+	ctx := setupRunTest(t)
+
+	// The auth token is only provided to the pr step via its config overrides
+	path := writeRunFile(t, `
+steps:
+  - name: Say hello
+    command: exec
+    args: [-y, -c, "echo hello from $(basename $PWD)"]
+    repos: [repo-1]
+  - command: pr get
+    repos: [repo-1]
+    config:
+      auth-token: step-token
+`)
+
+	cmd := RootCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"run", path})
+
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("run command failed: %v\n%s", err, buf.String())
+	}
+
+	output := buf.String()
+	testhelper.AssertContains(t, output, []string{
+		"==> Step 1/2: Say hello",
+		"hello from repo-1",
+		"==> Step 2/2: pr get",
+		"Batch PR",
+	})
+
+	// Step config overrides must not leak into the parent configuration
+	if got := config.Viper(ctx).GetString(config.AuthToken); got != "" {
+		t.Errorf("Expected auth token override to be scoped to the step, got %q", got)
+	}
+}
+
+func TestRunCommandFailurePolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		wantErr    string
+		wantSecond bool
+	}{
+		{
+			name:    "stop after failed step",
+			policy:  config.BatchStop,
+			wantErr: "step 1 (exec) failed",
+		},
+		{
+			name:       "continue after failed step",
+			policy:     config.BatchContinue,
+			wantErr:    "1 of 2 steps failed",
+			wantSecond: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := setupRunTest(t)
+
+			path := writeRunFile(t, `
+on-failure: `+tt.policy+`
+steps:
+  - command: exec
+    args: [-y, -c, "exit 1"]
+    repos: [repo-1]
+  - command: exec
+    args: [-y, -c, "echo second step"]
+    repos: [repo-1]
+`)
+
+			cmd := RootCmd()
+
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs([]string{"run", path})
+
+			err := cmd.ExecuteContext(ctx)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+
+			if !errors.Is(err, &call.Error{}) {
+				t.Errorf("Expected step failures to be reported as runtime errors, got: %v", err)
+			}
+
+			if got := strings.Contains(buf.String(), "second step"); got != tt.wantSecond {
+				t.Errorf("Expected second step executed: %v, got: %v\n%s", tt.wantSecond, got, buf.String())
+			}
+		})
+	}
+}
+
+func TestRunCommandRejectsNestedRun(t *testing.T) {
+	ctx := setupRunTest(t)
+	path := writeRunFile(t, "steps:\n  - command: run other.yaml\n")
+
+	cmd := RootCmd()
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"run", path})
+
+	if err := cmd.ExecuteContext(ctx); err == nil || !strings.Contains(err.Error(), "invalid batch command") {
+		t.Errorf("Expected nested run to be rejected, got: %v", err)
+	}
+}
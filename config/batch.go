@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// Failure policies for batch file execution.
+const (
+	BatchStop     = "stop"
+	BatchContinue = "continue"
+)
+
+// BatchFile describes a sequence of batch-tool commands to execute in order.
+type BatchFile struct {
+	// OnFailure is the default failure policy for all steps ("stop" or "continue").
+	OnFailure string `mapstructure:"on-failure"`
+	// Steps are executed in the order they are defined.
+	Steps []BatchStep `mapstructure:"steps"`
+}
+
+// BatchStep describes a single batch-tool command within a batch file.
+type BatchStep struct {
+	// Name is an optional description of the step.
+	Name string `mapstructure:"name"`
+	// Command is the (space-separated) batch-tool subcommand to run, e.g. "pr new".
+	Command string `mapstructure:"command"`
+	// Args are the flags and arguments passed to the command.
+	Args []string `mapstructure:"args"`
+	// Repos are the repository selectors passed to the command.
+	Repos []string `mapstructure:"repos"`
+	// Config overrides configuration values for this step only.
+	Config map[string]any `mapstructure:"config"`
+	// OnFailure overrides the failure policy of the batch file for this step.
+	OnFailure string `mapstructure:"on-failure"`
+}
+
+// Policy returns the failure policy for the given step, falling back to the batch file default.
+func (b *BatchFile) Policy(step BatchStep) string {
+	if step.OnFailure != "" {
+		return step.OnFailure
+	}
+
+	if b.OnFailure != "" {
+		return b.OnFailure
+	}
+
+	return BatchStop
+}
+
+// LoadBatchFile reads and validates the YAML batch file at the given path.
+func LoadBatchFile(path string) (*BatchFile, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s: %w", path, err)
+	}
+
+	var batch BatchFile
+	if err := v.Unmarshal(&batch); err != nil {
+		return nil, fmt.Errorf("failed to parse batch file %s: %w", path, err)
+	}
+
+	if len(batch.Steps) == 0 {
+		return nil, fmt.Errorf("batch file %s does not define any steps", path)
+	}
+
+	if err := validatePolicy(batch.OnFailure); err != nil {
+		return nil, err
+	}
+
+	for i, step := range batch.Steps {
+		if step.Command == "" {
+			return nil, fmt.Errorf("step %d of batch file %s is missing a command", i+1, path)
+		}
+
+		if err := validatePolicy(step.OnFailure); err != nil {
+			return nil, fmt.Errorf("step %d of batch file %s: %w", i+1, path, err)
+		}
+	}
+
+	return &batch, nil
+}
+
+func validatePolicy(policy string) error {
+	switch policy {
+	case "", BatchStop, BatchContinue:
+		return nil
+	default:
+		return fmt.Errorf("invalid failure policy %q (expected %q or %q)", policy, BatchStop, BatchContinue)
+	}
+}
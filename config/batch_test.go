@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+func writeBatchFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "batch.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write batch file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadBatchFile(t *testing.T) {
+	path := writeBatchFile(t, `
+on-failure: continue
+steps:
+  - name: Say hello
+    command: exec
+    args: [-y, -c, "echo hello"]
+    repos: ["~backend", "!repo-2"]
+  - command: pr get
+    repos: [repo-1]
+    config:
+      auth-token: step-token
+    on-failure: stop
+`)
+
+	batch, err := config.LoadBatchFile(path)
+	if err != nil {
+		t.Fatalf("LoadBatchFile() returned error: %v", err)
+	}
+
+	if len(batch.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(batch.Steps))
+	}
+
+	first, second := batch.Steps[0], batch.Steps[1]
+	if first.Name != "Say hello" || first.Command != "exec" {
+		t.Errorf("unexpected first step: %+v", first)
+	}
+	if len(first.Args) != 3 || first.Args[2] != "echo hello" {
+		t.Errorf("unexpected first step args: %v", first.Args)
+	}
+	if len(first.Repos) != 2 || first.Repos[1] != "!repo-2" {
+		t.Errorf("unexpected first step repos: %v", first.Repos)
+	}
+	if second.Config[config.AuthToken] != "step-token" {
+		t.Errorf("expected step config override, got %v", second.Config)
+	}
+
+	if got := batch.Policy(first); got != config.BatchContinue {
+		t.Errorf("expected file policy %q for first step, got %q", config.BatchContinue, got)
+	}
+	if got := batch.Policy(second); got != config.BatchStop {
+		t.Errorf("expected step policy %q for second step, got %q", config.BatchStop, got)
+	}
+}
+
+func TestLoadBatchFileDefaultPolicy(t *testing.T) {
+	batch, err := config.LoadBatchFile(writeBatchFile(t, "steps:\n  - command: git status\n"))
+	if err != nil {
+		t.Fatalf("LoadBatchFile() returned error: %v", err)
+	}
+
+	if got := batch.Policy(batch.Steps[0]); got != config.BatchStop {
+		t.Errorf("expected default policy %q, got %q", config.BatchStop, got)
+	}
+}
+
+func TestLoadBatchFileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "no steps", content: "on-failure: stop\n"},
+		{name: "missing command", content: "steps:\n  - repos: [repo-1]\n"},
+		{name: "invalid file policy", content: "on-failure: maybe\nsteps:\n  - command: git status\n"},
+		{name: "invalid step policy", content: "steps:\n  - command: git status\n    on-failure: maybe\n"},
+		{name: "malformed yaml", content: "steps: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := config.LoadBatchFile(writeBatchFile(t, tt.content)); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := config.LoadBatchFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing batch file, got nil")
+	}
+}
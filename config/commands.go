@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// LoadCommandMap reads the mapping of repository names to shell commands from the YAML or JSON file at the given path.
+// Unlike configuration keys, the repository names are case-sensitive.
+func LoadCommandMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command map %s: %w", path, err)
+	}
+
+	// JSON is a subset of YAML, so a single decoder handles both formats
+	commands := make(map[string]string)
+	if err := yaml.Unmarshal(data, &commands); err != nil {
+		return nil, fmt.Errorf("failed to parse command map %s: %w", path, err)
+	}
+
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("command map %s does not map any repositories", path)
+	}
+
+	return commands, nil
+}
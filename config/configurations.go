@@ -34,12 +34,28 @@ const (
 	DefaultBranch      = "git.default-branch"
 	StashUpdates       = "git.stash-updates"
 	DefaultMergeMethod = "git.default-merge-method"
+	CloneProtocol      = "git.clone-protocol"
 
 	// CloneSSHURLTmpl is the SSH URL template with placeholders: User, Host, Project, Repo
 	CloneSSHURLTmpl = "ssh://%s@%s/%s/%s.git"
+	// CloneHTTPSURLTmpl is the HTTPS URL template with placeholders: Host, Project, Repo
+	CloneHTTPSURLTmpl = "https://%s/%s/%s.git"
+
+	// CloneProtocolHTTPS and CloneProtocolSSH are the supported values of CloneProtocol
+	CloneProtocolHTTPS = "https"
+	CloneProtocolSSH   = "ssh"
+
+	// GitLayout is the strategy used to derive the directory of each repository beneath GitDirectory.
+	// GitLayoutHost nests repositories by host and project, GitLayoutProject by project alone,
+	// and GitLayoutFlat places them directly in GitDirectory by name.
+	GitLayout        = "git.layout"
+	GitLayoutHost    = "host"
+	GitLayoutProject = "project"
+	GitLayoutFlat    = "flat"
 
 	SortRepos      = "repos.sort"
 	RepoAliases    = "repos.aliases"
+	RepoPaths      = "repos.paths"
 	UnwantedLabels = "repos.unwanted-labels"
 	SkipArchived   = "repos.skip-archived"
 	SkipUnwanted   = "repos.skip-unwanted"
@@ -47,28 +63,77 @@ const (
 
 	DefaultReviewers     = "repos.reviewers"
 	DefaultTeamReviewers = "repos.team-reviewers"
-
-	CatalogCachePath = "repos.cache.path"
-	CatalogCacheTTL  = "repos.cache.ttl"
-
-	Branch    = "branch"
-	AuthToken = "auth-token"
+	LabelPolicies        = "repos.policies"
+	LabelColors          = "repos.label-colors"
+	RequiredReviewers    = "repos.reviewers-required"
+	AllowEmpty           = "repos.allow-empty"
+	BranchPattern        = "repos.branch-pattern"
+
+	CatalogCachePath     = "repos.cache.path"
+	CatalogCacheDir      = "repos.cache.directory"
+	CatalogCacheCompress = "repos.cache.compress"
+	CatalogCacheTTL      = "repos.cache.ttl"
+	CatalogNoCache       = "repos.cache.no-cache"
+	CatalogNoSave        = "repos.cache.no-save"
+	CatalogRefresh       = "repos.cache.refresh"
+
+	AuditPath = "audit.path"
+
+	ExecProtectedPaths = "exec.protected-paths"
+	ExecWatchDebounce  = "exec.watch-debounce"
+	ExecArtifactsDir   = "exec.artifacts-dir"
+	ExecConfirmPrompt  = "exec.confirm-prompt"
+	ExecConfirmDefault = "exec.confirm-default-yes"
+	ExecTimeout        = "exec.timeout"
+	ExecTemplate       = "exec.template"
+
+	TemplateVars = "template.vars-file"
+
+	Branch           = "branch"
+	AuthToken        = "auth-token"
+	CredentialHelper = "credential-helper"
 
 	TokenLabel  = "repos.tokens.label"
 	TokenSkip   = "repos.tokens.skip"
 	TokenForced = "repos.tokens.forced"
+	// TokenIntersect marks a label whose repositories the selection is narrowed to (e.g. &go &critical)
+	TokenIntersect = "repos.tokens.intersect"
 
 	OutputStyle  = "channels.output-style"
 	PrintResults = "channels.print-results"
 	WaitOnExit   = "channels.wait-on-exit"
+	OutputFile   = "channels.output-file"
+	GroupByLabel = "channels.group-by-label"
+	SummaryOnly  = "channels.summary-only-on-success"
+	SummaryJSON  = "channels.summary-json"
+	LogDir       = "channels.log-dir"
 
 	ChannelBuffer  = "channels.buffer-size"
 	MaxConcurrency = "channels.max-concurrency"
+	BatchSize      = "channels.batch-size"
 	WriteBackoff   = "channels.write-backoff"
+	StartJitter    = "channels.start-jitter"
+	JitterSeed     = "channels.start-jitter-seed"
 
 	GithubHourlyWriteLimit = "github.hourly-write-limit"
 	GithubBackoffSmall     = "github.write-backoff-small"
 	GithubBackoffLarge     = "github.write-backoff-large"
+	GithubRequestTimeout   = "github.request-timeout"
+
+	GithubReviewerChunkSize  = "github.reviewer-chunk-size"
+	GithubReviewerChunkDelay = "github.reviewer-chunk-delay"
+
+	// UseTopicsAsLabels controls whether the topics of GitHub repositories are used as catalog labels
+	UseTopicsAsLabels = "github.topics-as-labels"
+
+	GiteaBaseURL = "gitea.base-url"
+
+	GitLabBaseURL = "gitlab.base-url"
+
+	AzureDevOpsOrganization = "azuredevops.organization"
+	AzureDevOpsBaseURL      = "azuredevops.base-url"
+
+	RESTConfig = "rest"
 
 	// == COMMAND FLAGS == //
 	CmdEnv = "cmd.args.env"
@@ -81,16 +146,48 @@ const (
 	GitStashAllowAny = "git.args.stash.allow-any"
 
 	// pr
-	PrOptions        = "pr.args.options"
-	PrTitle          = "pr.args.title"
-	PrDescription    = "pr.args.description"
-	PrDraft          = "pr.args.draft"
-	PrReviewers      = "pr.args.reviewers"
-	PrTeamReviewers  = "pr.args.team-reviewers"
-	PrResetReviewers = "pr.args.reset-reviewers"
-	PrBaseBranch     = "pr.args.base-branch"
-	PrMergeCheck     = "pr.args.merge-check"
-	PrMergeMethod    = "pr.args.merge-method"
+	PrOptions          = "pr.args.options"
+	PrTitle            = "pr.args.title"
+	PrDescription      = "pr.args.description"
+	PrDraft            = "pr.args.draft"
+	PrReviewers        = "pr.args.reviewers"
+	PrTeamReviewers    = "pr.args.team-reviewers"
+	PrResetReviewers   = "pr.args.reset-reviewers"
+	PrLabels           = "pr.args.labels"
+	PrResetLabels      = "pr.args.reset-labels"
+	PrDryRun           = "pr.args.dry-run"
+	PrEditTitleGuard   = "pr.args.edit-only-if-title"
+	PrFindTitle        = "pr.args.find-title-contains"
+	PrSyncFrom         = "pr.args.sync-from"
+	PrSyncTitle        = "pr.args.sync-title"
+	PrReviewerPool     = "pr.args.reviewer-pool"
+	PrPoolCount        = "pr.args.reviewer-pool-count"
+	PrPoolSeed         = "pr.args.reviewer-pool-seed"
+	PrPoolAssignment   = "pr.args.reviewer-pool-assignment"
+	PrCodeowners       = "pr.args.reviewers-from-codeowners"
+	PrAssignMe         = "pr.args.assign-me"
+	PrReviewMe         = "pr.args.review-me"
+	PrCurrentUser      = "pr.args.current-user"
+	PrBaseBranch       = "pr.args.base-branch"
+	PrCreateBase       = "pr.args.create-base-branch"
+	PrAutoMerge        = "pr.args.auto-merge"
+	PrCheckout         = "pr.args.checkout"
+	PrFill             = "pr.args.fill"
+	PrMergeCheck       = "pr.args.merge-check"
+	PrMergeMethod      = "pr.args.merge-method"
+	PrMergeApproved    = "pr.args.merge-if-approved"
+	PrMergeDeleteLocal = "pr.args.merge-delete-local-branch"
+	PrMergeUpdate      = "pr.args.merge-update-branch"
+	PrMergeDryRun      = "pr.args.merge-dry-run"
+
+	// PrConfirmThreshold is the number of repositories above which pr new and pr merge ask for confirmation
+	PrConfirmThreshold = "pr.confirm-threshold"
+	// PrMergeMethodAliases maps alternative names of merge methods (e.g. squash-merge) to the names accepted by providers
+	PrMergeMethodAliases = "pr.merge-method-aliases"
+	// PrCheckPermissions enables a check before pr commands modify pull requests that the token appears to have the permissions they need
+	PrCheckPermissions = "pr.check-permissions"
+	// PrCheckHead enables a warning before pr edit and pr merge when the local branch differs from the head of the pull request
+	PrCheckHead = "pr.check-head"
 
 	// make
 	MakeTargets = "make.args.targets"
@@ -147,6 +244,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault(StashUpdates, false)
 	v.SetDefault(SortRepos, true)
 	v.SetDefault(DefaultMergeMethod, "squash") // "merge", "squash", or "rebase" (only supported by GitHub provider for now)
+	v.SetDefault(CloneProtocol, CloneProtocolHTTPS)
+	v.SetDefault(GitLayout, GitLayoutHost)
 
 	v.SetDefault(SkipArchived, true)
 	v.SetDefault(SkipUnwanted, true)
@@ -154,12 +253,27 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault(SuperSetLabel, "all")
 
 	v.SetDefault(CatalogCachePath, "") // empty means use default: gitdir/host/.batch-tool-cache.json
+	v.SetDefault(CatalogCacheDir, "")  // empty means use default: gitdir/host
+	v.SetDefault(CatalogCacheCompress, false)
 	v.SetDefault(CatalogCacheTTL, "24h")
+	v.SetDefault(CatalogNoCache, false)
+	v.SetDefault(CatalogNoSave, false)
+	v.SetDefault(CatalogRefresh, false)
+	v.SetDefault(AllowEmpty, false)
 	v.SetDefault(OutputStyle, "tui")
 	v.SetDefault(WaitOnExit, true) // Wait for user input after completion by default
 	v.SetDefault(ChannelBuffer, 100)
 	v.SetDefault(MaxConcurrency, runtime.NumCPU()) // Default to number of logical CPUs
+	v.SetDefault(BatchSize, 0)                     // unlimited, so all repositories run as a single batch
 	v.SetDefault(WriteBackoff, "1s")
+	v.SetDefault(StartJitter, "0s") // disabled, since most commands only run locally
+	v.SetDefault(JitterSeed, 0)
+	v.SetDefault(ExecWatchDebounce, "500ms")
+	v.SetDefault(ExecArtifactsDir, "artifacts")
+	v.SetDefault(ExecTimeout, "0s") // no timeout
+	v.SetDefault(ExecTemplate, false)
+	v.SetDefault(ExecConfirmPrompt, "Executing {{.Preview}}\nAre you sure?")
+	v.SetDefault(ExecConfirmDefault, false) // an empty response declines unless configured otherwise
 
 	// GitHub's secondary rate limit is 80 requests per minute, or 500 requests per hour
 	// 1s keeps us safely under the per-minute limit
@@ -168,12 +282,40 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault(GithubBackoffSmall, "1s")
 	v.SetDefault(GithubBackoffLarge, "8s")
 
+	// bound each GitHub API request so that a single stuck request can't hang a repository indefinitely
+	v.SetDefault(GithubRequestTimeout, "30s")
+
+	// request large sets of reviewers a few at a time so that a single pull request can't trip the secondary rate limit
+	v.SetDefault(GithubReviewerChunkSize, 10)
+	v.SetDefault(GithubReviewerChunkDelay, "1s")
+
+	// label repositories by their topics, so that they can be selected without curating repos.aliases
+	v.SetDefault(UseTopicsAsLabels, true)
+
+	v.SetDefault(AzureDevOpsBaseURL, "https://dev.azure.com")
+
 	// default reviewers in the form `repo: [reviewers...]`
 	v.SetDefault(DefaultReviewers, map[string][]string{})
 	v.SetDefault(DefaultTeamReviewers, map[string][]string{})
 
+	// pull request policies in the form `label: {base-branch: branch, team-reviewers: [teams...]}`
+	v.SetDefault(LabelPolicies, map[string]any{})
+
+	// label display colors in the form `label: color`
+	v.SetDefault(LabelColors, map[string]string{})
+
+	// reviewers assigned per pull request when balancing load across a reviewer pool
+	v.SetDefault(PrPoolCount, 1)
+	v.SetDefault(PrConfirmThreshold, 50)
+	v.SetDefault(PrCheckPermissions, true)
+	v.SetDefault(PrCheckHead, false)
+
+	// alternative merge method names in the form `alias: method`, in addition to the built-in aliases
+	v.SetDefault(PrMergeMethodAliases, map[string]string{})
+
 	// aliases in the form `alias: [repos...]`
 	v.SetDefault(RepoAliases, map[string][]string{})
+	v.SetDefault(RepoPaths, map[string][]string{})
 
 	// default git directory is $GOPATH/src if GOPATH is set, or current working directory otherwise
 	v.SetDefault(GitDirectory, defaultGitdir())
@@ -182,6 +324,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault(TokenLabel, "~")
 	v.SetDefault(TokenSkip, "!")
 	v.SetDefault(TokenForced, "+")
+	v.SetDefault(TokenIntersect, "&")
 }
 
 func defaultGitdir() string {
@@ -58,6 +58,31 @@ func TestSetChildIsolation(t *testing.T) {
 
 // TestWithCancelAndCancel verifies that the cancel function attached by config.WithCancel
 // can be retrieved via config.Cancel and propagates context cancellation.
+func TestOverlayPrecedence(t *testing.T) {
+	parent := config.New()
+	parent.Set("parent.key", "parent-value")
+	parent.Set("override.key", "parent-value")
+	ctx := config.SetViper(context.Background(), parent)
+
+	child, err := config.Overlay(ctx, map[string]any{"override.key": "override-value"})
+	if err != nil {
+		t.Fatalf("Overlay() returned error: %v", err)
+	}
+
+	if got := child.GetString("parent.key"); got != "parent-value" {
+		t.Errorf("expected inherited value, got %q", got)
+	}
+	if got := child.GetString("override.key"); got != "override-value" {
+		t.Errorf("expected override value, got %q", got)
+	}
+
+	// Explicit values on the overlay take precedence and do not leak to the parent
+	child.Set("parent.key", "child-value")
+	if got := parent.GetString("parent.key"); got != "parent-value" {
+		t.Errorf("expected parent to remain unchanged, got %q", got)
+	}
+}
+
 func TestWithCancelAndCancel(t *testing.T) {
 	ctx := context.Background()
 
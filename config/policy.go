@@ -0,0 +1,27 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// PRPolicy describes the pull request settings applied to repositories carrying a label, or belonging to a project.
+type PRPolicy struct {
+	// BaseBranch is the base branch for new pull requests.
+	BaseBranch string `mapstructure:"base-branch"`
+	// TeamReviewers are added to the team reviewers of new and edited pull requests.
+	TeamReviewers []string `mapstructure:"team-reviewers"`
+	// MergeMethod is the merge method used by pr merge when none is given with --method.
+	MergeMethod string `mapstructure:"merge-method"`
+}
+
+// LoadPRPolicies returns the configured pull request policies, keyed by label or project.
+func LoadPRPolicies(ctx context.Context) (map[string]PRPolicy, error) {
+	policies := make(map[string]PRPolicy)
+
+	if err := Viper(ctx).UnmarshalKey(LabelPolicies, &policies); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %w", LabelPolicies, err)
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// RESTProvider describes how the generic REST provider reaches an otherwise unsupported forge: the
+// endpoints it calls for each operation, and where the fields it needs are found in the JSON responses.
+type RESTProvider struct {
+	// BaseURL is prepended to the path of every endpoint.
+	BaseURL string `mapstructure:"base-url"`
+	// AuthHeader is the request header which carries the auth token.
+	AuthHeader string `mapstructure:"auth-header"`
+	// AuthScheme is written before the auth token in the AuthHeader (e.g. "Bearer"), and may be empty.
+	AuthScheme string `mapstructure:"auth-scheme"`
+	// MergeMethods are the merge methods accepted by the merge endpoint.
+	MergeMethods []string `mapstructure:"merge-methods"`
+
+	// Endpoints are keyed by operation, e.g. "open-pull-request".
+	Endpoints map[string]RESTEndpoint `mapstructure:"endpoints"`
+
+	Repository  RESTRepositoryFields  `mapstructure:"repository"`
+	PullRequest RESTPullRequestFields `mapstructure:"pull-request"`
+}
+
+// RESTEndpoint describes a single API request. Path and Body are Go templates.
+type RESTEndpoint struct {
+	// Method is the HTTP method, which defaults to POST for endpoints with a Body and GET otherwise.
+	Method string `mapstructure:"method"`
+	// Path is appended to the BaseURL, and may include a query string.
+	Path string `mapstructure:"path"`
+	// Body is the JSON request body.
+	Body string `mapstructure:"body"`
+	// Result is the dot-separated path of the result within the JSON response (the whole response if empty).
+	Result string `mapstructure:"result"`
+}
+
+// RESTRepositoryFields are the dot-separated paths of repository fields within a JSON repository object.
+type RESTRepositoryFields struct {
+	Name          string `mapstructure:"name"`
+	Description   string `mapstructure:"description"`
+	Public        string `mapstructure:"public"`
+	Archived      string `mapstructure:"archived"`
+	DefaultBranch string `mapstructure:"default-branch"`
+	Labels        string `mapstructure:"labels"`
+	CloneURL      string `mapstructure:"clone-url"`
+	SSHURL        string `mapstructure:"ssh-url"`
+	WebURL        string `mapstructure:"web-url"`
+}
+
+// RESTPullRequestFields are the dot-separated paths of pull request fields within a JSON pull request object.
+type RESTPullRequestFields struct {
+	ID          string `mapstructure:"id"`
+	Number      string `mapstructure:"number"`
+	Title       string `mapstructure:"title"`
+	Description string `mapstructure:"description"`
+	Branch      string `mapstructure:"branch"`
+	BaseBranch  string `mapstructure:"base-branch"`
+	Reviewers   string `mapstructure:"reviewers"`
+	Mergeable   string `mapstructure:"mergeable"`
+}
+
+// LoadRESTProvider returns the configuration of the generic REST provider.
+func LoadRESTProvider(ctx context.Context) (*RESTProvider, error) {
+	provider := &RESTProvider{
+		AuthHeader:  "Authorization",
+		AuthScheme:  "Bearer",
+		Repository:  RESTRepositoryFields{Name: "name"},
+		PullRequest: RESTPullRequestFields{Number: "number", Title: "title"},
+	}
+
+	if err := Viper(ctx).UnmarshalKey(RESTConfig, provider); err != nil {
+		return nil, fmt.Errorf("invalid %s configuration: %w", RESTConfig, err)
+	}
+
+	if provider.BaseURL == "" {
+		return nil, fmt.Errorf("invalid %s configuration: base-url is required", RESTConfig)
+	}
+
+	return provider, nil
+}
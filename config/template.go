@@ -0,0 +1,56 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// LoadTemplateVars reads the extra template variables from the YAML or JSON file at the given path.
+// Unlike configuration keys, the variable names are case-sensitive.
+func LoadTemplateVars(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template vars file %s: %w", path, err)
+	}
+
+	// JSON is a subset of YAML, so a single decoder handles both formats
+	vars := make(map[string]any)
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse template vars file %s: %w", path, err)
+	}
+
+	return vars, nil
+}
+
+type templateVarsKey struct{}
+
+// cachedTemplateVars holds the template variables loaded from the file at path.
+type cachedTemplateVars struct {
+	path string
+	vars map[string]any
+}
+
+// WithTemplateVars returns a copy of ctx carrying the template variables loaded from the file at the given path, so
+// that the file is read once per run rather than for every repository and template.
+func WithTemplateVars(ctx context.Context, path string, vars map[string]any) context.Context {
+	return context.WithValue(ctx, templateVarsKey{}, cachedTemplateVars{path: path, vars: vars})
+}
+
+// ContextTemplateVars returns the variables of the configured template vars file, or nil if none is configured.
+// The variables attached to ctx by WithTemplateVars are used if they were loaded from the same file, otherwise the
+// file is read.
+func ContextTemplateVars(ctx context.Context) (map[string]any, error) {
+	path := Viper(ctx).GetString(TemplateVars)
+	if path == "" {
+		return nil, nil
+	}
+
+	if cached, ok := ctx.Value(templateVarsKey{}).(cachedTemplateVars); ok && cached.path == path {
+		return cached.vars, nil
+	}
+
+	return LoadTemplateVars(path)
+}
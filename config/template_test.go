@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+func TestContextTemplateVars(t *testing.T) {
+	v := viper.New()
+	ctx := config.SetViper(context.Background(), v)
+
+	// no vars file configured
+	if vars, err := config.ContextTemplateVars(ctx); err != nil || vars != nil {
+		t.Errorf("expected no vars without a vars file, got %v (err: %v)", vars, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(path, []byte("Team: core\n"), 0o600); err != nil {
+		t.Fatalf("failed to write vars file: %v", err)
+	}
+
+	v.Set(config.TemplateVars, path)
+
+	vars, err := config.LoadTemplateVars(path)
+	if err != nil {
+		t.Fatalf("LoadTemplateVars() returned error: %v", err)
+	}
+
+	ctx = config.WithTemplateVars(ctx, path, vars)
+
+	// the cached vars are used without reading the file again
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("failed to remove vars file: %v", err)
+	}
+
+	if vars, err := config.ContextTemplateVars(ctx); err != nil || vars["Team"] != "core" {
+		t.Errorf("expected cached vars, got %v (err: %v)", vars, err)
+	}
+
+	// a different vars file (e.g. configured for a single step) is read instead
+	v.Set(config.TemplateVars, filepath.Join(t.TempDir(), "other.yaml"))
+
+	if _, err := config.ContextTemplateVars(ctx); err == nil {
+		t.Error("expected an error reading a different, missing vars file")
+	}
+}
@@ -24,6 +24,24 @@ func Child(ctx context.Context) *viper.Viper {
 	return v
 }
 
+// Overlay creates a new Viper instance which inherits all settings from the parent context as defaults, with the
+// provided overrides applied as configuration. Unlike Child, values from flags bound to the new instance take
+// precedence over both the inherited settings and the overrides.
+func Overlay(ctx context.Context, overrides map[string]any) (*viper.Viper, error) {
+	v := newViper()
+
+	// Inherit all settings from parent Viper at the lowest precedence
+	for key, value := range Viper(ctx).AllSettings() {
+		v.SetDefault(key, value)
+	}
+
+	if err := v.MergeConfigMap(overrides); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
 func newViper() *viper.Viper {
 	v := viper.NewWithOptions(viper.EnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_")))
 	v.AutomaticEnv() // read in environment variables that match
@@ -1,13 +1,17 @@
 package output
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/semaphore"
 
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
 )
 
 // Channel represents an output channel for concurrent operations
@@ -22,13 +26,25 @@ type Channel interface {
 
 	// WriteString writes a whole line to the output channel as a string.
 	io.StringWriter
-	// Write bytes directly to the output channel.
+	// Write bytes to the output channel, which emits them in messages of complete lines.
 	io.Writer
 
 	// WriteError writes an error to the error channel.
 	WriteError(err error)
 	// Failed indicates whether an error has been written to the error channel.
 	Failed() bool
+	// Skip records that the operation was skipped for the given reason, for inclusion in the run summary.
+	Skip(reason string)
+	// Skipped returns the reason the operation was skipped, or an empty string if it was not.
+	Skipped() string
+	// Error returns the errors written to the error channel joined together, or nil if there were none.
+	Error() error
+	// SetPullRequest records the pull request produced by the operation, for inclusion in its result.
+	SetPullRequest(pr *scm.PullRequest)
+	// PullRequest returns the pull request produced by the operation, or nil if there was none.
+	PullRequest() *scm.PullRequest
+	// Duration returns how long the operation ran, from when it started until the channel was closed.
+	Duration() time.Duration
 
 	// Start begins processing with the specified weight for semaphore acquisition.
 	Start(weight int64) error
@@ -50,11 +66,25 @@ func NewChannel(ctx context.Context, name string, sem *semaphore.Weighted, wg *s
 	}
 }
 
+// maxLineLength is the length beyond which a line without a newline is emitted in pieces, rather than being
+// buffered without limit.
+const maxLineLength = 64 * 1024
+
 type channel struct {
 	name   string
 	output chan []byte
 	err    chan error
 	failed bool
+	skip   string
+
+	mu      sync.Mutex // guards partial, since a command's stdout and stderr may be written concurrently
+	partial []byte     // the incomplete last line written, held until the rest of the line is written
+
+	errs []error
+	pr   *scm.PullRequest
+
+	started  time.Time // when processing started, after acquiring the semaphore
+	finished time.Time // when the channel was closed
 
 	ctx context.Context
 	sem *semaphore.Weighted
@@ -75,15 +105,31 @@ func (c *channel) Err() <-chan error {
 	return c.err
 }
 
+// Write buffers the bytes and sends the complete lines among them to the output channel, so that output read in
+// arbitrary chunks (e.g. from a command's pipes) is framed by line. An incomplete line is held until the rest of it
+// is written, or until the channel is closed.
 func (c *channel) Write(p []byte) (n int, _ error) {
 	if len(p) == 0 {
 		return 0, nil
 	}
 
-	// Make a copy to prevent caller from modifying the buffer
-	buf := make([]byte, len(p))
-	copy(buf, p)
-	c.output <- buf
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lines := append(c.partial, p...)
+
+	if i := bytes.LastIndexByte(lines, '\n'); i >= 0 {
+		c.emit(lines[:i+1])
+		lines = lines[i+1:]
+	}
+
+	for len(lines) >= maxLineLength {
+		c.emit(lines[:maxLineLength])
+		lines = lines[maxLineLength:]
+	}
+
+	// Copy the remainder so that the emitted lines' buffer isn't retained
+	c.partial = bytes.Clone(lines)
 
 	return len(p), nil
 }
@@ -95,13 +141,32 @@ func (c *channel) WriteString(s string) (n int, _ error) {
 		return 0, nil
 	}
 
-	c.output <- []byte(s + "\n")
+	c.Write([]byte(s + "\n"))
+
 	return len(s), nil
 }
 
+// emit sends a copy of the line to the output channel, to prevent the caller from modifying the buffer.
+func (c *channel) emit(line []byte) {
+	c.output <- bytes.Clone(line)
+}
+
+// flush terminates and sends the incomplete last line, if any, so that the final line of output is emitted
+// even if it doesn't end with a newline.
+func (c *channel) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.partial) > 0 {
+		c.emit(append(c.partial, '\n'))
+		c.partial = nil
+	}
+}
+
 // WriteError writes an error to the error channel.
 func (c *channel) WriteError(err error) {
 	c.failed = true
+	c.errs = append(c.errs, err)
 	c.err <- err
 }
 
@@ -109,6 +174,34 @@ func (c *channel) Failed() bool {
 	return c.failed
 }
 
+func (c *channel) Skip(reason string) {
+	c.skip = reason
+}
+
+func (c *channel) Skipped() string {
+	return c.skip
+}
+
+func (c *channel) Error() error {
+	return errors.Join(c.errs...)
+}
+
+func (c *channel) SetPullRequest(pr *scm.PullRequest) {
+	c.pr = pr
+}
+
+func (c *channel) PullRequest() *scm.PullRequest {
+	return c.pr
+}
+
+func (c *channel) Duration() time.Duration {
+	if c.started.IsZero() || c.finished.IsZero() {
+		return 0
+	}
+
+	return c.finished.Sub(c.started)
+}
+
 func (c *channel) Start(weight int64) error {
 	if weight <= 0 {
 		weight = 1 // valid default weight
@@ -116,6 +209,7 @@ func (c *channel) Start(weight int64) error {
 
 	if c.sem == nil {
 		// No semaphore to acquire, return immediately
+		c.started = time.Now()
 		return nil
 	}
 
@@ -125,11 +219,15 @@ func (c *channel) Start(weight int64) error {
 
 	// Acquired semaphore successfully, set release weight
 	c.release = weight
+	c.started = time.Now()
 
 	return nil
 }
 
 func (c *channel) Close() error {
+	c.flush()
+	c.finished = time.Now()
+
 	// close channels and signal worker completion
 	close(c.output)
 	close(c.err)
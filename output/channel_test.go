@@ -3,11 +3,16 @@ package output
 import (
 	"context"
 	"errors"
+	"os/exec"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"golang.org/x/sync/semaphore"
+
+	"github.com/ryclarke/batch-tool/scm"
 )
 
 func TestNewChannel(t *testing.T) {
@@ -314,3 +319,107 @@ func Test_channel_Start(t *testing.T) {
 		sem.Release(10)
 	})
 }
+
+func Test_channel_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		line   string // written with WriteString after the writes
+		want   []string
+	}{
+		{
+			name:   "complete lines",
+			writes: []string{"one\n", "two\nthree\n"},
+			want:   []string{"one\n", "two\nthree\n"},
+		},
+		{
+			name:   "line split across writes",
+			writes: []string{"on", "e\ntw", "o\n"},
+			want:   []string{"one\n", "two\n"},
+		},
+		{
+			name:   "final line without newline",
+			writes: []string{"one\ntw", "o"},
+			want:   []string{"one\n", "two\n"},
+		},
+		{
+			name:   "partial line completed by WriteString",
+			writes: []string{"one\nprefix: "},
+			line:   "line",
+			want:   []string{"one\n", "prefix: line\n"},
+		},
+		{
+			name:   "long line without newline",
+			writes: []string{strings.Repeat("x", maxLineLength+1)},
+			want:   []string{strings.Repeat("x", maxLineLength), "x\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &channel{
+				name:   "test",
+				output: make(chan []byte, 10),
+				err:    make(chan error, 1),
+				ctx:    context.Background(),
+			}
+
+			for _, w := range tt.writes {
+				if n, err := c.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+
+			if tt.line != "" {
+				c.WriteString(tt.line)
+			}
+
+			c.Close()
+
+			var got []string
+			for msg := range c.Out() {
+				got = append(got, string(msg))
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected messages %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResultOf(t *testing.T) {
+	t.Run("successful operation with a pull request", func(t *testing.T) {
+		c := &channel{name: "repo1", output: make(chan []byte), err: make(chan error, 1)}
+		pr := &scm.PullRequest{Number: 7, MergeCommit: "abc123"}
+		c.SetPullRequest(pr)
+
+		result := ResultOf(c)
+
+		if result.Repo != "repo1" || result.Failed() || result.ExitCode != 0 || result.PullRequest != pr {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("command exiting with an error", func(t *testing.T) {
+		exitErr := exec.Command("sh", "-c", "exit 3").Run()
+		c := &channel{name: "repo2", output: make(chan []byte), err: make(chan error, 2)}
+		c.WriteError(exitErr)
+		c.WriteError(errors.New("cleanup failed"))
+		c.Skip("not needed")
+
+		result := ResultOf(c)
+
+		if !result.Failed() || !errors.Is(result.Err, exitErr) {
+			t.Errorf("Expected result error to include %v, got %v", exitErr, result.Err)
+		}
+
+		if result.ExitCode != 3 {
+			t.Errorf("Expected exit code 3, got %d", result.ExitCode)
+		}
+
+		if result.Skipped != "not needed" {
+			t.Errorf("Expected skip reason, got %q", result.Skipped)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+const outputFileFailText = "Failed to write output file %q: %v\n"
+
+// writeOutputFile writes the combined output of a run to the configured output file, if any.
+// ANSI styling is removed so the file is readable outside of a terminal.
+func writeOutputFile(cmd *cobra.Command, content string) {
+	path := config.Viper(cmd.Context()).GetString(config.OutputFile)
+	if path == "" {
+		return
+	}
+
+	if err := os.WriteFile(path, []byte(ansi.Strip(content)), 0o600); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), outputFileFailText, path, err)
+	}
+}
+
+// formatSummary returns the summary line for a completed run, including the failure count if any.
+func formatSummary(total, failed int, elapsed time.Duration) string {
+	if failed > 0 {
+		return fmt.Sprintf(summaryTextFail, total, failed, elapsed)
+	}
+
+	return fmt.Sprintf(summaryText, total, elapsed)
+}
+
+// projectOutcome records the project of a repository in a completed run and whether it failed.
+type projectOutcome struct {
+	project string
+	failed  bool
+}
+
+// repoProject returns the SCM project of the repository processed by the named channel.
+func repoProject(ctx context.Context, name string) string {
+	_, project, _ := utils.ParseRepo(ctx, name)
+
+	return project
+}
+
+// formatProjectSummary returns a breakdown of the run summary with one line per project, sorted by name, listing
+// its repository and failure counts. It returns an empty string if all repositories belong to the same project.
+func formatProjectSummary(outcomes []projectOutcome) string {
+	totals, failures := make(map[string]int), make(map[string]int)
+
+	for _, outcome := range outcomes {
+		totals[outcome.project]++
+		if outcome.failed {
+			failures[outcome.project]++
+		}
+	}
+
+	if len(totals) < 2 {
+		return ""
+	}
+
+	projects := make([]string, 0, len(totals))
+	for project := range totals {
+		projects = append(projects, project)
+	}
+
+	sort.Strings(projects)
+
+	lines := make([]string, len(projects))
+	for i, project := range projects {
+		if failures[project] > 0 {
+			lines[i] = fmt.Sprintf(projectSummaryTextFail, project, totals[project], failures[project])
+		} else {
+			lines[i] = fmt.Sprintf(projectSummaryText, project, totals[project])
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// skippedRepo records a repository which was skipped during a run, and the reason it was skipped.
+type skippedRepo struct {
+	name   string
+	reason string
+}
+
+// formatSkippedSummary returns a section listing each skipped repository with its reason, in the order they were
+// processed. It returns an empty string if no repositories were skipped.
+func formatSkippedSummary(skipped []skippedRepo) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(skipped)+1)
+	lines = append(lines, skippedSummaryTitle)
+
+	for _, repo := range skipped {
+		lines = append(lines, fmt.Sprintf(skippedSummaryText, repo.name, repo.reason))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// repoResult records a repository processed during a run, and the errors it reported.
+type repoResult struct {
+	name string
+	errs []error
+}
+
+// formatResultsSummary returns a section listing each repository in the order they were processed, marked as passed
+// or failed, with the first line of its first error for failures. It returns an empty string for a single repository.
+func formatResultsSummary(results []repoResult) string {
+	if len(results) < 2 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(results)+1)
+	lines = append(lines, resultsSummaryTitle)
+
+	for _, repo := range results {
+		if len(repo.errs) == 0 {
+			lines = append(lines, "  "+fmt.Sprintf(repoSuccessFormat, repo.name))
+			continue
+		}
+
+		reason, _, _ := strings.Cut(strings.TrimSpace(repo.errs[0].Error()), "\n")
+		if more := len(repo.errs) - 1; more > 0 {
+			reason += fmt.Sprintf(" (+%d more)", more)
+		}
+
+		lines = append(lines, "  "+fmt.Sprintf(repoErrorFormat, repo.name)+": "+reason)
+	}
+
+	return strings.Join(lines, "\n")
+}
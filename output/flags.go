@@ -0,0 +1,92 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// recordFlagsAnnotation lists the flags of a command which are meaningful to record in its header and audit log.
+	recordFlagsAnnotation = "batch-tool/record-flags"
+
+	// redactFlagAnnotation marks a flag of KEY=VALUE entries whose values may hold secrets.
+	redactFlagAnnotation = "batch-tool/redact"
+)
+
+// defaultRecordFlags are recorded for commands which don't declare their own flags to record.
+var defaultRecordFlags = []string{"script", "file", "arg", "branch", "reviewer"}
+
+// FlagValue is the name and value of a recorded flag.
+type FlagValue struct {
+	Name  string
+	Value string
+}
+
+// RecordFlags declares the flags of the command (and its subcommands, unless they declare their own) which are
+// meaningful to record in the command header and audit log. Flags which aren't declared are never recorded, so
+// sensitive values such as credentials or environment variables can't leak into the audit log.
+func RecordFlags(cmd *cobra.Command, names ...string) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+
+	cmd.Annotations[recordFlagsAnnotation] = strings.Join(names, ",")
+
+	return cmd
+}
+
+// RecordedFlags returns the flags declared for the command which were explicitly provided, in the order they were
+// declared. The declaration of the nearest ancestor is used if the command doesn't declare its own.
+func RecordedFlags(cmd *cobra.Command) []FlagValue {
+	names := defaultRecordFlags
+
+	for c := cmd; c != nil; c = c.Parent() {
+		if declared, ok := c.Annotations[recordFlagsAnnotation]; ok {
+			names = strings.Split(declared, ",")
+			break
+		}
+	}
+
+	recorded := make([]FlagValue, 0, len(names))
+
+	for _, name := range names {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			recorded = append(recorded, FlagValue{Name: name, Value: flagValue(flag)})
+		}
+	}
+
+	return recorded
+}
+
+// RedactFlag marks a flag of KEY=VALUE entries, such as environment variables, whose values may hold secrets. Only
+// the keys of its entries are recorded, so that the flag can be declared to be recorded without leaking credentials.
+func RedactFlag(flags *pflag.FlagSet, name string) {
+	_ = flags.SetAnnotation(name, redactFlagAnnotation, []string{"true"})
+}
+
+// flagValue formats the value of the flag for recording, replacing the values of its KEY=VALUE entries if redacted.
+func flagValue(flag *pflag.Flag) string {
+	if _, redact := flag.Annotations[redactFlagAnnotation]; !redact {
+		return fmt.Sprint(flag.Value)
+	}
+
+	entries := []string{flag.Value.String()}
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		entries = slice.GetSlice()
+	}
+
+	for i, entry := range entries {
+		if key, _, ok := strings.Cut(entry, "="); ok {
+			entries[i] = key + "=***"
+		}
+	}
+
+	if _, ok := flag.Value.(pflag.SliceValue); ok {
+		return "[" + strings.Join(entries, ",") + "]"
+	}
+
+	return entries[0]
+}
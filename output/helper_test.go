@@ -3,9 +3,11 @@ package output
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/ryclarke/batch-tool/scm"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
@@ -30,6 +32,8 @@ type testChannel struct {
 	name   string
 	output chan []byte
 	err    chan error
+	skip   string
+	pr     *scm.PullRequest
 }
 
 func (tc *testChannel) Name() string       { return tc.name }
@@ -55,7 +59,13 @@ func (tc *testChannel) WriteError(err error) {
 func (tc *testChannel) Failed() bool {
 	return false
 }
-func (tc *testChannel) Start(_ int64) error { return nil }
+func (tc *testChannel) Skip(reason string)                 { tc.skip = reason }
+func (tc *testChannel) Skipped() string                    { return tc.skip }
+func (tc *testChannel) Error() error                       { return nil }
+func (tc *testChannel) SetPullRequest(pr *scm.PullRequest) { tc.pr = pr }
+func (tc *testChannel) PullRequest() *scm.PullRequest      { return tc.pr }
+func (tc *testChannel) Duration() time.Duration            { return 0 }
+func (tc *testChannel) Start(_ int64) error                { return nil }
 func (tc *testChannel) Close() error {
 	close(tc.output)
 	close(tc.err)
@@ -13,10 +13,14 @@ const (
 	TUI = "tui"
 	// Native is the native output style
 	Native = "native"
+	// JSON is the machine-readable output style, which prints the results of a command as a JSON array
+	JSON = "json"
+	// Plain is the non-interactive output style, which streams each line prefixed with its repository
+	Plain = "plain"
 )
 
 // AvailableStyles lists all supported output styles
-var AvailableStyles = []string{TUI, Native}
+var AvailableStyles = []string{TUI, Native, JSON, Plain}
 
 // Handler represents a function for processing streaming command output.
 type Handler func(cmd *cobra.Command, channels []Channel)
@@ -29,6 +33,10 @@ func GetHandler(ctx context.Context) Handler {
 	switch handlerType {
 	case Native:
 		return NativeHandler
+	case JSON:
+		return JSONHandler
+	case Plain:
+		return PlainHandler
 	default:
 		// Use more advanced TUI handler by default
 		return TUIHandler
@@ -44,7 +52,7 @@ func GetLabelHandler(ctx context.Context) LabelHandler {
 	handlerType := viper.GetString(config.OutputStyle)
 
 	switch handlerType {
-	case Native:
+	case Native, JSON, Plain:
 		return NativeLabels
 	default:
 		// Use more advanced TUI handler by default
@@ -61,7 +69,7 @@ func GetCatalogHandler(ctx context.Context) CatalogHandler {
 	handlerType := viper.GetString(config.OutputStyle)
 
 	switch handlerType {
-	case Native:
+	case Native, JSON, Plain:
 		return NativeCatalog
 	default:
 		// Use more advanced TUI handler by default
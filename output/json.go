@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// jsonResult is the machine-readable outcome of an operation on a single repository.
+type jsonResult struct {
+	Repo       string   `json:"repo"`
+	Success    bool     `json:"success"`
+	Output     string   `json:"output"`
+	Errors     []string `json:"errors"`
+	ExitCode   int      `json:"exit_code,omitempty"`
+	SkipReason string   `json:"skip_reason,omitempty"`
+	Duration   float64  `json:"duration_seconds"`
+}
+
+// JSONHandler is a machine-readable output Handler which collects the combined output, errors, and elapsed time
+// of each repository, and prints them to stdout as a single JSON array once every channel has closed. Nothing else
+// is printed, so that the output can be parsed as-is (e.g. in CI).
+func JSONHandler(cmd *cobra.Command, channels []Channel) {
+	results := make([]jsonResult, len(channels))
+
+	for i, ch := range channels {
+		var out bytes.Buffer
+		for data := range ch.Out() {
+			out.Write(data)
+		}
+
+		var errs []error
+		messages := make([]string, 0)
+		for err := range ch.Err() {
+			errs = append(errs, err)
+			messages = append(messages, err.Error())
+		}
+
+		results[i] = jsonResult{
+			Repo:       ch.Name(),
+			Success:    len(errs) == 0,
+			Output:     out.String(),
+			Errors:     messages,
+			ExitCode:   exitCode(errors.Join(errs...)),
+			SkipReason: ch.Skipped(),
+			Duration:   ch.Duration().Round(time.Millisecond).Seconds(),
+		}
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "ERROR: failed to encode JSON output: %v\n", err)
+		return
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), string(data))
+}
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// makeClosedChannel creates a closed test channel which has already written the given output and errors.
+func makeClosedChannel(name string, out []string, errs ...error) *testChannel {
+	tc := &testChannel{
+		name:   name,
+		output: make(chan []byte, len(out)),
+		err:    make(chan error, len(errs)),
+	}
+
+	for _, line := range out {
+		tc.WriteString(line)
+	}
+
+	for _, err := range errs {
+		tc.WriteError(err)
+	}
+
+	tc.Close()
+
+	return tc
+}
+
+func TestJSONHandler(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+
+	skipped := makeClosedChannel("repo3", nil)
+	skipped.Skip("no changes")
+
+	JSONHandler(cmd, []Channel{
+		makeClosedChannel("repo1", []string{"first line", "second line"}),
+		makeClosedChannel("repo2", []string{"partial output"}, errors.New("build failed"), errors.New("cleanup failed")),
+		skipped,
+	})
+
+	var results []jsonResult
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("Expected a single JSON array on stdout, got %q: %v", buf.String(), err)
+	}
+
+	testhelper.AssertLength(t, results, 3)
+	testhelper.AssertEqual(t, errBuf.String(), "")
+
+	tests := []struct {
+		want       jsonResult
+		wantErrors string
+	}{
+		{want: jsonResult{Repo: "repo1", Success: true, Output: "first line\nsecond line\n"}},
+		{want: jsonResult{Repo: "repo2", Success: false, Output: "partial output\n"}, wantErrors: "build failed,cleanup failed"},
+		{want: jsonResult{Repo: "repo3", Success: true, SkipReason: "no changes"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.want.Repo, func(t *testing.T) {
+			got := results[i]
+
+			testhelper.AssertEqual(t, got.Repo, tt.want.Repo)
+			testhelper.AssertEqual(t, got.Success, tt.want.Success)
+			testhelper.AssertEqual(t, got.Output, tt.want.Output)
+			testhelper.AssertEqual(t, got.SkipReason, tt.want.SkipReason)
+			testhelper.AssertEqual(t, strings.Join(got.Errors, ","), tt.wantErrors)
+		})
+	}
+}
+
+func TestJSONHandlerNoRepositories(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	JSONHandler(cmd, nil)
+
+	// An empty run still prints a valid (empty) array
+	testhelper.AssertEqual(t, strings.TrimSpace(buf.String()), "[]")
+}
+
+func TestJSONHandlerEmptyErrors(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+
+	JSONHandler(cmd, []Channel{makeClosedChannel("repo1", nil)})
+
+	// Successful repositories report an empty list of errors rather than null
+	testhelper.AssertContains(t, buf.String(), []string{`"errors": []`})
+}
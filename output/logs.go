@@ -0,0 +1,140 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// LogChannels wraps each channel so that its output and errors are also written to a log file for the repository
+// beneath the configured log directory, as a handler consumes them. The channels are returned as-is if no log
+// directory is configured. A channel whose log file can't be created is reported and left unwrapped.
+func LogChannels(cmd *cobra.Command, channels []Channel) []Channel {
+	dir := config.Viper(cmd.Context()).GetString(config.LogDir)
+	if dir == "" {
+		return channels
+	}
+
+	header := buildCommandString(cmd)
+	logged := make([]Channel, len(channels))
+
+	for i, ch := range channels {
+		path := logPath(cmd.Context(), dir, ch.Name())
+
+		file, err := createLogFile(path)
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: failed to create log file %q: %v\n", path, err)
+			logged[i] = ch
+
+			continue
+		}
+
+		logged[i] = newLogChannel(ch, file, header)
+	}
+
+	return logged
+}
+
+// logPath returns the path of the log file for the repository beneath dir, namespaced by its project and name
+// (and the subdirectory of path-scoped targets).
+func logPath(ctx context.Context, dir, repo string) string {
+	_, project, name := utils.ParseRepo(ctx, repo)
+	_, subdir := utils.SplitTarget(repo)
+
+	return filepath.Join(dir, project, name, filepath.FromSlash(subdir)) + ".log"
+}
+
+// createLogFile creates (or truncates) the log file at the given path, along with its parent directories.
+func createLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+}
+
+// logChannel is a Channel which tees the output and errors of the wrapped channel into a log file.
+type logChannel struct {
+	Channel
+
+	output chan []byte
+	err    chan error
+}
+
+// newLogChannel starts copying the output and errors of the channel into the log file, after the header line.
+// Errors are logged after the output, and the error channel is closed only once the log file is complete, so a
+// handler which has drained both channels never returns before the status line is written.
+func newLogChannel(ch Channel, file io.WriteCloser, header string) *logChannel {
+	lc := &logChannel{
+		Channel: ch,
+		output:  make(chan []byte, cap(ch.Out())),
+		err:     make(chan error, cap(ch.Err())),
+	}
+
+	fmt.Fprintln(file, header)
+
+	outputDone := make(chan struct{})
+
+	go func() {
+		defer close(outputDone)
+		defer close(lc.output)
+
+		for data := range ch.Out() {
+			file.Write([]byte(ansi.Strip(string(data))))
+			lc.output <- data
+		}
+	}()
+
+	go func() {
+		defer close(lc.err)
+
+		// errors are passed on as they arrive, but logged after the output so that the log reads in order
+		var errs []error
+		for err := range ch.Err() {
+			errs = append(errs, err)
+			lc.err <- err
+		}
+
+		<-outputDone
+
+		for _, err := range errs {
+			fmt.Fprintf(file, "ERROR: %v\n", err)
+		}
+
+		fmt.Fprintln(file, logStatus(ch, len(errs)))
+		file.Close()
+	}()
+
+	return lc
+}
+
+func (lc *logChannel) Out() <-chan []byte {
+	return lc.output
+}
+
+func (lc *logChannel) Err() <-chan error {
+	return lc.err
+}
+
+// logStatus returns the trailing status line of the log file for the closed channel.
+func logStatus(ch Channel, failed int) string {
+	elapsed := ch.Duration().Round(time.Millisecond)
+
+	switch {
+	case failed > 0:
+		return fmt.Sprintf("Status: failed with %d error(s) after %v", failed, elapsed)
+	case ch.Skipped() != "":
+		return fmt.Sprintf("Status: skipped (%s) after %v", ch.Skipped(), elapsed)
+	default:
+		return fmt.Sprintf("Status: succeeded after %v", elapsed)
+	}
+}
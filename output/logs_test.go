@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestLogChannels(t *testing.T) {
+	cmd := makeTestCommand(t)
+	dir := t.TempDir()
+	config.Viper(cmd.Context()).Set(config.LogDir, dir)
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+
+	channels := []Channel{
+		makeClosedChannel("test-project/repo1", []string{"first line", "\x1b[32msecond line\x1b[0m"}),
+		makeClosedChannel("test-project/repo2", []string{"partial output"}, errors.New("build failed")),
+	}
+
+	NativeHandler(cmd, LogChannels(cmd, channels))
+
+	// the handler still sees the output and errors of each repository
+	testhelper.AssertContains(t, buf.String(), []string{"first line", "partial output"})
+	testhelper.AssertContains(t, errBuf.String(), []string{"build failed"})
+
+	header := buildCommandString(cmd)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			path: filepath.Join(dir, "test-project", "repo1.log"),
+			want: header + "\nfirst line\nsecond line\nStatus: succeeded after 0s\n",
+		},
+		{
+			path: filepath.Join(dir, "test-project", "repo2.log"),
+			want: header + "\npartial output\nERROR: build failed\nStatus: failed with 1 error(s) after 0s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.path), func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("Expected log file to exist: %v", err)
+			}
+
+			testhelper.AssertEqual(t, string(data), tt.want)
+		})
+	}
+}
+
+func TestLogChannelsSkipped(t *testing.T) {
+	cmd := makeTestCommand(t)
+	dir := t.TempDir()
+	config.Viper(cmd.Context()).Set(config.LogDir, dir)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	ch := makeClosedChannel("test-project/mono//services/api", nil)
+	ch.Skip("no changes")
+
+	NativeHandler(cmd, LogChannels(cmd, []Channel{ch}))
+
+	// path-scoped targets are logged beneath the directory of their repository
+	data, err := os.ReadFile(filepath.Join(dir, "test-project", "mono", "services", "api.log"))
+	if err != nil {
+		t.Fatalf("Expected log file to exist: %v", err)
+	}
+
+	testhelper.AssertContains(t, string(data), []string{"Status: skipped (no changes) after 0s"})
+}
+
+func TestLogChannelsDisabled(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	channels := makeTestChannels([]string{"repo1"}, true)
+
+	// without a log directory the channels are passed through unwrapped
+	if got := LogChannels(cmd, channels); got[0] != channels[0] {
+		t.Errorf("Expected the channel to be returned as-is, got %T", got[0])
+	}
+}
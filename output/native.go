@@ -1,52 +1,165 @@
 package output
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
 )
 
 // NativeHandler is a simple output Handler that batches and prints output from each repository's channels in sequence.
 // It is straightforward and compatible with all terminal environments, but lacks interactivity and modern UI features.
 func NativeHandler(cmd *cobra.Command, channels []Channel) {
-	for _, ch := range channels {
+	start := time.Now()
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	// Hold back the repository output until it is known whether any repository failed
+	var held *heldOutput
+	summaryOnly := config.Viper(cmd.Context()).GetBool(config.SummaryOnly)
+	if summaryOnly {
+		held = new(heldOutput)
+		out, errOut = held.writer(out), held.writer(errOut)
+	}
+
+	// Capture a copy of everything printed if the combined output is persisted to a file
+	var log *bytes.Buffer
+	if config.Viper(cmd.Context()).GetString(config.OutputFile) != "" {
+		log = new(bytes.Buffer)
+		out, errOut = io.MultiWriter(out, log), io.MultiWriter(errOut, log)
+	}
+
+	var failed int
+	var skipped []skippedRepo
+	outcomes := make([]projectOutcome, len(channels))
+	results := make([]repoResult, len(channels))
+
+	for i, ch := range channels {
 		// print header with repository name
-		fmt.Fprintf(cmd.OutOrStdout(), "\n------ %s ------\n", ch.Name())
+		fmt.Fprintf(out, "\n------ %s ------\n", ch.Name())
 
 		// Read bytes and drite directly to output
 		for data := range ch.Out() {
-			cmd.OutOrStdout().Write(data)
+			out.Write(data)
 		}
 
 		// print any errors for this repo to Stderr
+		var hasErr bool
 		for err := range ch.Err() {
-			fmt.Fprintln(cmd.ErrOrStderr(), "ERROR: ", err)
+			fmt.Fprintln(errOut, "ERROR: ", err)
+			results[i].errs = append(results[i].errs, err)
+			hasErr = true
 		}
+
+		if hasErr {
+			failed++
+		}
+
+		if reason := ch.Skipped(); reason != "" {
+			skipped = append(skipped, skippedRepo{name: ch.Name(), reason: reason})
+		}
+
+		outcomes[i] = projectOutcome{project: repoProject(cmd.Context(), ch.Name()), failed: hasErr}
+		results[i].name = ch.Name()
+	}
+
+	summary := formatSummary(len(channels), failed, time.Since(start).Round(time.Second))
+
+	if summaryOnly {
+		// Only show the full output if something went wrong, otherwise the summary is enough
+		held.release(failed > 0)
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "\n%s\n", summary)
+	}
+
+	// Mark each repository as passed or failed, like the TUI, when the run spans several of them
+	if resultsSummary := formatResultsSummary(results); resultsSummary != "" {
+		fmt.Fprintf(errOut, "\n%s\n", resultsSummary)
+	}
+
+	// Break down the results by project when the run spans several of them
+	projectSummary := formatProjectSummary(outcomes)
+	if projectSummary != "" {
+		fmt.Fprintf(errOut, "\n%s\n", projectSummary)
+	}
+
+	// List the repositories which were skipped, along with the reason for each
+	if skippedSummary := formatSkippedSummary(skipped); skippedSummary != "" {
+		fmt.Fprintf(errOut, "\n%s\n", skippedSummary)
+	}
+
+	if log != nil {
+		writeOutputFile(cmd, buildCommandString(cmd)+"\n"+summary+"\n"+log.String())
 	}
 }
 
+// heldOutput records writes to several destinations in order, so that they can be replayed later.
+type heldOutput struct {
+	writes   []heldWrite
+	released bool
+}
+
+type heldWrite struct {
+	dest io.Writer
+	data []byte
+}
+
+// writer returns a writer which records its writes to be replayed to the destination, until the output is released.
+func (h *heldOutput) writer(dest io.Writer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		if h.released {
+			return dest.Write(p)
+		}
+
+		h.writes = append(h.writes, heldWrite{dest: dest, data: bytes.Clone(p)})
+		return len(p), nil
+	})
+}
+
+// release stops holding back output, first writing the recorded output to its destinations (in the order it
+// was originally written) if replay is true, or discarding it otherwise.
+func (h *heldOutput) release(replay bool) {
+	if replay {
+		for _, w := range h.writes {
+			w.dest.Write(w.data)
+		}
+	}
+
+	h.writes, h.released = nil, true
+}
+
+// writerFunc adapts a function to the io.Writer interface.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
 // NativeCatalog displays the repository catalog in a simple text format.
 func NativeCatalog(cmd *cobra.Command) {
 	ctx := cmd.Context()
 	viper := config.Viper(ctx)
 
 	// Get sorted repository names
-	repoNames := make([]string, 0, len(catalog.Catalog))
-	for name := range catalog.Catalog {
+	snapshot := catalog.Repositories()
+	repoNames := make([]string, 0, len(snapshot))
+	for name := range snapshot {
 		repoNames = append(repoNames, name)
 	}
 	sort.Strings(repoNames)
 
-	fmt.Fprintf(cmd.OutOrStdout(), "Repository Catalog (%d repositories)\n", len(catalog.Catalog))
+	fmt.Fprintln(cmd.OutOrStdout(), catalogTitle(ctx))
 	fmt.Fprintln(cmd.OutOrStdout())
 
 	for _, name := range repoNames {
-		repo := catalog.Catalog[name]
+		repo := snapshot[name]
 
 		fmt.Fprintf(cmd.OutOrStdout(), "## %s\n", name)
 
@@ -97,8 +210,10 @@ func printLabels(cmd *cobra.Command, labels ...string) {
 	ctx := cmd.Context()
 	viper := config.Viper(ctx)
 
+	labelSets := catalog.LabelSets()
+
 	if len(labels) == 0 {
-		for label := range catalog.Labels {
+		for label := range labelSets {
 			if label == viper.GetString(config.SuperSetLabel) {
 				continue
 			}
@@ -110,11 +225,12 @@ func printLabels(cmd *cobra.Command, labels ...string) {
 	sort.Strings(labels)
 
 	for _, label := range labels {
-		if set, ok := catalog.Labels[label]; ok && set.Cardinality() > 0 {
+		// parsed filters carry their label tokens (e.g. frontend~)
+		label = utils.CleanFilter(ctx, label)
+
+		if set, ok := labelSets[label]; ok && set.Cardinality() > 0 {
 			repos := set.ToSlice()
-			if viper.GetBool(config.SortRepos) {
-				sort.Strings(repos)
-			}
+			sort.Strings(repos)
 
 			fmt.Fprintf(cmd.OutOrStdout(), "  ~ %s ~\n%s\n", label, strings.Join(repos, ", "))
 		} else {
@@ -148,6 +264,11 @@ func printSet(cmd *cobra.Command, verbose bool, labelGroup catalog.LabelGroup, r
 			printLabels(cmd, labelGroup.Included.ToSlice()...)
 		}
 
+		if intersected := labelGroup.Intersected; intersected.Set != nil && intersected.Cardinality() > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nIntersected labels:\n")
+			printLabels(cmd, intersected.ToSlice()...)
+		}
+
 		if labelGroup.Excluded.Cardinality() > 0 {
 			fmt.Fprintf(cmd.OutOrStdout(), "\nExcluded labels:\n")
 			printLabels(cmd, labelGroup.Excluded.ToSlice()...)
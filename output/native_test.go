@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -86,7 +89,14 @@ func TestNativeLabels_PrintAllLabels(t *testing.T) {
 				"frontend": mapset.NewSet("zebra-app", "apple-app", "mobile-app"),
 			},
 			sortRepos:    true,
-			wantContains: []string{"Available labels:", "apple-app", "mobile-app", "zebra-app"},
+			wantContains: []string{"Available labels:", "apple-app, mobile-app, zebra-app"},
+		},
+		{
+			name: "label repos sorted without repo sorting",
+			setupLabels: map[string]mapset.Set[string]{
+				"frontend": mapset.NewSet("zebra-app", "apple-app", "mobile-app"),
+			},
+			wantContains: []string{"Available labels:", "apple-app, mobile-app, zebra-app"},
 		},
 	}
 
@@ -195,6 +205,21 @@ func TestNativeLabels_PrintSet(t *testing.T) {
 			},
 			wantContains: []string{"Excluded labels:", "old-api", "deprecated"},
 		},
+		{
+			name:    "intersected labels verbose",
+			verbose: true,
+			filters: []string{"frontend~", "&mobile"},
+			setupLabels: map[string]mapset.Set[string]{
+				"frontend": mapset.NewSet("web-app", "mobile-app"),
+				"mobile":   mapset.NewSet("mobile-app", "ios-app"),
+			},
+			setupCatalog: map[string]scm.Repository{
+				"web-app":    {Name: "web-app"},
+				"mobile-app": {Name: "mobile-app"},
+				"ios-app":    {Name: "ios-app"},
+			},
+			wantContains: []string{"This matches 1 repository: mobile-app", "Intersected labels:", "  ~ mobile ~\nios-app, mobile-app"},
+		},
 		{
 			name:    "no matches",
 			verbose: false,
@@ -314,3 +339,211 @@ func TestNativeCatalog_Archived(t *testing.T) {
 		t.Error("Did not expect active-repo to be marked (archived)")
 	}
 }
+
+// TestNativeHandlerOutputFile tests that the combined output is also written to the configured output file
+func TestNativeHandlerOutputFile(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1", "repo2"})
+
+	path := filepath.Join(t.TempDir(), "run.log")
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 10)
+	viper.Set(config.SortRepos, false)
+	viper.Set(config.OutputFile, path)
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		ch.WriteString("output from " + ch.Name())
+		if ch.Name() == "repo2" {
+			return errors.New("test error for " + ch.Name())
+		}
+
+		return nil
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	if err := call.Do(cmd, []string{"repo1", "repo2"}, callFunc, output.NativeHandler); err == nil {
+		t.Fatal("Expected Do to return an aggregated failure error")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	content := string(data)
+	testhelper.AssertContains(t, content, []string{
+		"Executing",
+		"2 repositories (1 failed)",
+		"------ repo1 ------\noutput from repo1",
+		"------ repo2 ------\noutput from repo2",
+		"ERROR:  test error for repo2",
+	})
+
+	// Output is still printed to the terminal
+	testhelper.AssertContains(t, buf.String(), []string{"output from repo1", "output from repo2"})
+	testhelper.AssertContains(t, errBuf.String(), "test error for repo2")
+}
+
+// TestNativeHandlerProjectSummary tests that the results of a multi-project run are broken down by project
+func TestNativeHandlerProjectSummary(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"alpha/repo1", "beta/repo2", "beta/repo3"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 10)
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		if ch.Name() == "beta/repo3" {
+			return errors.New("bad token")
+		}
+
+		return nil
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	if err := call.Do(cmd, repos, callFunc, output.NativeHandler); err == nil {
+		t.Fatal("Expected Do to return an aggregated failure error")
+	}
+
+	testhelper.AssertContains(t, errBuf.String(), []string{"  alpha: 1 repositories\n  beta: 2 repositories (1 failed)\n"})
+}
+
+// TestNativeHandlerResultsSummary tests that each repository is marked as passed or failed after the run
+func TestNativeHandlerResultsSummary(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 10)
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		switch ch.Name() {
+		case "repo2":
+			return errors.New("exit status 1\nwith more detail")
+		case "repo3":
+			ch.Skip("nothing to do")
+		}
+
+		return nil
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	if err := call.Do(cmd, repos, callFunc, output.NativeHandler); err == nil {
+		t.Fatal("Expected Do to return an aggregated failure error")
+	}
+
+	testhelper.AssertContains(t, errBuf.String(), []string{"Results:\n  ✓ repo1\n  ✗ repo2: exit status 1\n  ✓ repo3\n"})
+}
+
+// TestNativeHandlerResultsSummarySingleRepo tests that a single repository is not listed again after its output
+func TestNativeHandlerResultsSummarySingleRepo(t *testing.T) {
+	ctx := loadFixture(t)
+	testhelper.SetupDirs(t, ctx, []string{"repo1"})
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	call.Do(cmd, []string{"repo1"}, func(context.Context, output.Channel) error { return nil }, output.NativeHandler)
+
+	testhelper.AssertNotContains(t, errBuf.String(), []string{"Results:"})
+}
+
+// TestNativeHandlerSkippedSummary tests that skipped repositories are listed with their reasons after the run
+func TestNativeHandlerSkippedSummary(t *testing.T) {
+	ctx := loadFixture(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	testhelper.SetupDirs(t, ctx, repos)
+
+	viper := config.Viper(ctx)
+	viper.Set(config.MaxConcurrency, 1)
+	viper.Set(config.ChannelBuffer, 10)
+
+	callFunc := func(_ context.Context, ch output.Channel) error {
+		if ch.Name() != "repo2" {
+			ch.Skip("nothing to do")
+		}
+
+		return nil
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := fakeCmd(t, ctx, &buf)
+	cmd.SetErr(&errBuf)
+
+	if err := call.Do(cmd, repos, callFunc, output.NativeHandler); err != nil {
+		t.Fatalf("Expected Do to succeed, got: %v", err)
+	}
+
+	testhelper.AssertContains(t, errBuf.String(), []string{"Skipped:\n  repo1: nothing to do\n  repo3: nothing to do\n"})
+}
+
+func TestNativeHandlerSummaryOnlyOnSuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		failRepo   string
+		wantOut    []string
+		wantErrOut []string
+		notWantOut []string
+	}{
+		{
+			name:       "all succeeded",
+			wantErrOut: []string{"3 repositories | Elapsed:"},
+			notWantOut: []string{"------ repo1 ------", "output for repo1"},
+		},
+		{
+			name:       "with failure",
+			failRepo:   "repo2",
+			wantOut:    []string{"------ repo1 ------", "output for repo1", "------ repo3 ------", "output for repo3"},
+			wantErrOut: []string{"ERROR:  repo2 failed", "3 repositories (1 failed) | Elapsed:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			repos := []string{"repo1", "repo2", "repo3"}
+			testhelper.SetupDirs(t, ctx, repos)
+
+			viper := config.Viper(ctx)
+			viper.Set(config.MaxConcurrency, 1)
+			viper.Set(config.ChannelBuffer, 10)
+			viper.Set(config.SummaryOnly, true)
+
+			callFunc := func(_ context.Context, ch output.Channel) error {
+				fmt.Fprintf(ch, "output for %s\n", ch.Name())
+
+				if ch.Name() == tt.failRepo {
+					return fmt.Errorf("%s failed", ch.Name())
+				}
+
+				return nil
+			}
+
+			var buf, errBuf bytes.Buffer
+			cmd := fakeCmd(t, ctx, &buf)
+			cmd.SetErr(&errBuf)
+
+			call.Do(cmd, repos, callFunc, output.NativeHandler)
+
+			testhelper.AssertContains(t, buf.String(), tt.wantOut)
+			testhelper.AssertContains(t, errBuf.String(), tt.wantErrOut)
+			testhelper.AssertNotContains(t, buf.String(), tt.notWantOut)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// PlainHandler is a non-interactive output Handler which streams the output of every repository to stdout as it
+// arrives, prefixing each line with the name of its repository (e.g. "[repo-name] ..."), and their errors to stderr.
+// It never moves the cursor or redraws the screen, so it is suited to CI logs and environments without a terminal.
+func PlainHandler(cmd *cobra.Command, channels []Channel) {
+	if len(channels) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), noReposText)
+		return
+	}
+
+	start := time.Now()
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+
+	// guards the writers, so that lines from concurrent repositories are never split
+	var mu sync.Mutex
+	failed := make([]bool, len(channels))
+
+	wg := new(sync.WaitGroup)
+	for i, ch := range channels {
+		prefix := "[" + ch.Name() + "] "
+
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for data := range ch.Out() {
+				mu.Lock()
+				writePrefixed(out, prefix, data)
+				mu.Unlock()
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for err := range ch.Err() {
+				mu.Lock()
+				writePrefixed(errOut, prefix+"ERROR: ", []byte(err.Error()))
+				mu.Unlock()
+
+				failed[i] = true
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Keep streaming after cancellation, since the in-flight commands report their interruption as they stop
+	select {
+	case <-done:
+	case <-cmd.Context().Done():
+		mu.Lock()
+		fmt.Fprintln(errOut, "Cancelled, waiting for in-flight repositories to stop...")
+		mu.Unlock()
+
+		<-done
+	}
+
+	var numFailed int
+	var skipped []skippedRepo
+	for i, ch := range channels {
+		if failed[i] {
+			numFailed++
+		}
+
+		if reason := ch.Skipped(); reason != "" {
+			skipped = append(skipped, skippedRepo{name: ch.Name(), reason: reason})
+		}
+	}
+
+	fmt.Fprintf(errOut, "\n%s\n", formatSummary(len(channels), numFailed, time.Since(start).Round(time.Second)))
+
+	// List the repositories which were skipped, along with the reason for each
+	if skippedSummary := formatSkippedSummary(skipped); skippedSummary != "" {
+		fmt.Fprintf(errOut, "\n%s\n", skippedSummary)
+	}
+}
+
+// writePrefixed writes each line of the data to w, starting with the prefix and ending with a newline.
+func writePrefixed(w io.Writer, prefix string, data []byte) {
+	for line := range bytes.Lines(data) {
+		fmt.Fprintf(w, "%s%s\n", prefix, bytes.TrimSuffix(line, []byte("\n")))
+	}
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestPlainHandler(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+
+	channels := makeTestChannels([]string{"repo1", "repo2"}, false)
+	first, second := channels[0].(*testChannel), channels[1].(*testChannel)
+
+	// Interleave the output of both repositories, which is printed as it arrives
+	go func() {
+		for i := 1; i <= 3; i++ {
+			first.WriteString(fmt.Sprintf("one %d", i))
+			second.WriteString(fmt.Sprintf("two %d", i))
+		}
+
+		second.Write([]byte("two 4\ntwo 5\n")) // several lines at once are each prefixed
+		second.WriteError(errors.New("build failed"))
+		second.Skip("no changes")
+
+		first.Close()
+		second.Close()
+	}()
+
+	PlainHandler(cmd, channels)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	testhelper.AssertLength(t, lines, 8)
+
+	// Each line is prefixed with its repository, and each repository's lines keep their order
+	perRepo := map[string][]string{}
+	for _, line := range lines {
+		repo, text, ok := strings.Cut(line, " ")
+		if !ok || !strings.HasPrefix(repo, "[") || !strings.HasSuffix(repo, "]") {
+			t.Fatalf("Expected a repository prefix on line %q", line)
+		}
+
+		perRepo[repo] = append(perRepo[repo], text)
+	}
+
+	testhelper.AssertEqual(t, strings.Join(perRepo["[repo1]"], ","), "one 1,one 2,one 3")
+	testhelper.AssertEqual(t, strings.Join(perRepo["[repo2]"], ","), "two 1,two 2,two 3,two 4,two 5")
+
+	// Errors are printed to stderr, followed by the summary of the run
+	testhelper.AssertContains(t, errBuf.String(), []string{"[repo2] ERROR: build failed", "1 failed", "repo2: no changes"})
+	testhelper.AssertNotContains(t, buf.String(), []string{"ERROR", "\x1b["})
+}
+
+func TestPlainHandlerCancelled(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	cmd.SetContext(ctx)
+	cancel()
+
+	var buf, errBuf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+
+	channels := makeTestChannels([]string{"repo1"}, false)
+	ch := channels[0].(*testChannel)
+
+	go func() {
+		ch.WriteString("interrupted")
+		ch.Close()
+	}()
+
+	PlainHandler(cmd, channels)
+
+	// The output of in-flight repositories is still streamed after cancellation
+	testhelper.AssertContains(t, errBuf.String(), []string{"Cancelled"})
+	testhelper.AssertEqual(t, buf.String(), "[repo1] interrupted\n")
+}
+
+func TestPlainHandlerNoRepositories(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	var errBuf bytes.Buffer
+	cmd.SetErr(&errBuf)
+
+	PlainHandler(cmd, nil)
+
+	testhelper.AssertContains(t, errBuf.String(), []string{noReposText})
+}
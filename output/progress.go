@@ -0,0 +1,147 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"golang.org/x/term"
+
+	"github.com/ryclarke/batch-tool/catalog"
+)
+
+// NewFetchProgress returns a spinner on stderr which shows the progress of fetching the repository catalog
+// before the command output begins. Nothing is reported when stderr isn't a terminal, to keep logs clean.
+func NewFetchProgress(_ context.Context) catalog.FetchProgress {
+	if fd := os.Stderr.Fd(); fd > math.MaxInt || !term.IsTerminal(int(fd)) { //nolint:gosec // bounds checked
+		return catalog.NoFetchProgress{}
+	}
+
+	return newFetchSpinner(os.Stderr, spinner.Dot.FPS)
+}
+
+// fetchSpinner renders a single status line, redrawn as each project is fetched and on every tick of the spinner.
+type fetchSpinner struct {
+	mu       sync.Mutex
+	out      io.Writer
+	interval time.Duration
+
+	frame    int
+	total    int
+	fetched  int
+	repos    int
+	project  string
+	running  bool
+	stopTick chan struct{}
+	ticking  sync.WaitGroup
+}
+
+func newFetchSpinner(out io.Writer, interval time.Duration) *fetchSpinner {
+	return &fetchSpinner{out: out, interval: interval}
+}
+
+// Start draws the status line and begins animating the spinner.
+func (s *fetchSpinner) Start(projects int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.total = projects
+	s.running = true
+	s.stopTick = make(chan struct{})
+	s.render()
+
+	s.ticking.Add(1)
+
+	go func(stop <-chan struct{}) {
+		defer s.ticking.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.tick()
+			}
+		}
+	}(s.stopTick)
+}
+
+// Fetching shows the project currently being fetched.
+func (s *fetchSpinner) Fetching(project string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.project = project
+	s.render()
+}
+
+// Fetched counts the project and its repositories as fetched.
+func (s *fetchSpinner) Fetched(_ string, repos int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fetched++
+	s.repos += repos
+	s.render()
+}
+
+// Stop halts the spinner and clears its status line, so the command output starts on a clean line.
+func (s *fetchSpinner) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+
+	s.running = false
+	close(s.stopTick)
+	s.mu.Unlock()
+
+	// wait for the ticker outside the lock, since a tick in progress needs it to finish
+	s.ticking.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprint(s.out, "\r\033[K")
+}
+
+// tick advances the spinner to its next frame.
+func (s *fetchSpinner) tick() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	s.frame = (s.frame + 1) % len(spinner.Dot.Frames)
+	s.render()
+}
+
+// render redraws the status line in place. The caller must hold mu.
+func (s *fetchSpinner) render() {
+	fmt.Fprintf(s.out, "\r\033[K%s", s.view())
+}
+
+// view returns the current status line.
+func (s *fetchSpinner) view() string {
+	status := "Fetching repositories"
+	if s.project != "" && s.fetched < s.total {
+		status += " from " + s.project
+	}
+
+	return fmt.Sprintf("%s %s (%d/%d projects, %d repositories)", spinner.Dot.Frames[s.frame], status, s.fetched, s.total, s.repos)
+}
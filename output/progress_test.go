@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// syncBuffer is a bytes.Buffer which may be written by the spinner's ticker while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestFetchSpinnerTransitions(t *testing.T) {
+	out := &syncBuffer{}
+	s := newFetchSpinner(out, time.Hour) // ticks are driven manually
+
+	s.Start(2)
+	testhelper.AssertEqual(t, s.view(), spinner.Dot.Frames[0]+" Fetching repositories (0/2 projects, 0 repositories)")
+
+	s.Fetching("alpha")
+	testhelper.AssertEqual(t, s.view(), spinner.Dot.Frames[0]+" Fetching repositories from alpha (0/2 projects, 0 repositories)")
+
+	s.Fetched("alpha", 3)
+	s.tick()
+	s.Fetching("beta")
+	testhelper.AssertEqual(t, s.view(), spinner.Dot.Frames[1]+" Fetching repositories from beta (1/2 projects, 3 repositories)")
+
+	s.Fetched("beta", 4)
+	testhelper.AssertEqual(t, s.view(), spinner.Dot.Frames[1]+" Fetching repositories (2/2 projects, 7 repositories)")
+
+	s.Stop()
+
+	// each transition redraws the line in place, and stopping clears it
+	lines := strings.Split(out.String(), "\r\033[K")
+	testhelper.AssertEqual(t, lines[len(lines)-1], "")
+	testhelper.AssertLength(t, lines, 8)
+	testhelper.AssertEqual(t, lines[len(lines)-2], spinner.Dot.Frames[1]+" Fetching repositories (2/2 projects, 7 repositories)")
+}
+
+func TestFetchSpinnerTicks(t *testing.T) {
+	out := &syncBuffer{}
+	s := newFetchSpinner(out, time.Millisecond)
+
+	s.Start(1)
+	s.Fetching("alpha")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), spinner.Dot.Frames[1]) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected spinner to advance, got output %q", out.String())
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Stop()
+
+	// no further frames are drawn once stopped
+	stopped := out.String()
+	time.Sleep(10 * time.Millisecond)
+	testhelper.AssertEqual(t, out.String(), stopped)
+	testhelper.AssertEqual(t, strings.HasSuffix(stopped, "\r\033[K"), true)
+}
+
+func TestFetchSpinnerStopWithoutStart(t *testing.T) {
+	out := &syncBuffer{}
+	s := newFetchSpinner(out, time.Millisecond)
+
+	s.Stop()
+	testhelper.AssertEqual(t, out.String(), "")
+
+	// stopping twice is harmless
+	s.Start(1)
+	s.Stop()
+	s.Stop()
+	testhelper.AssertEqual(t, strings.Count(out.String(), "\r\033[K"), 2)
+}
+
+func TestNewFetchProgressWithoutTerminal(t *testing.T) {
+	// test output is never a terminal, so nothing is reported
+	if _, ok := NewFetchProgress(loadFixture(t)).(*fetchSpinner); ok {
+		t.Error("Expected no spinner when stderr is not a terminal")
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// Result is the typed outcome of an operation on a single repository, for programmatic use of batch-tool.
+// It is complete once the channel of the repository has been closed.
+type Result struct {
+	// Repo is the name of the repository (or path-scoped target) the operation ran on.
+	Repo string
+	// Err joins the errors written to the channel, or is nil if the operation succeeded.
+	Err error
+	// Skipped is the reason the operation was skipped, or empty if it was not.
+	Skipped string
+	// ExitCode is the exit code of the command which failed the operation, or 0 if no command exited with an error.
+	ExitCode int
+	// PullRequest is the pull request opened, updated, or merged by the operation, if any.
+	PullRequest *scm.PullRequest
+	// Duration is how long the operation ran on the repository.
+	Duration time.Duration
+}
+
+// Failed indicates whether the operation failed.
+func (r Result) Failed() bool {
+	return r.Err != nil
+}
+
+// ResultOf returns the result of the operation on the given channel.
+func ResultOf(ch Channel) Result {
+	err := ch.Error()
+
+	return Result{
+		Repo:        ch.Name(),
+		Err:         err,
+		Skipped:     ch.Skipped(),
+		ExitCode:    exitCode(err),
+		PullRequest: ch.PullRequest(),
+		Duration:    ch.Duration(),
+	}
+}
+
+// Results returns the results of the operations on the given channels, in the same order.
+func Results(channels []Channel) []Result {
+	results := make([]Result, len(channels))
+	for i, ch := range channels {
+		results[i] = ResultOf(ch)
+	}
+
+	return results
+}
+
+// exitCode returns the exit code of the command which caused the error, or 0 if it wasn't caused by a command exiting.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return 0
+}
@@ -12,12 +12,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
 )
 
-// List of flag names which should be included in the command display for context.
-var includeFlags = []string{"script", "file", "arg", "branch", "reviewer"}
-
 // TUIHandler is an OutputHandler that uses a TUI to provide a modern, interactive interface.
 // It displays repository progress with styled output, real-time updates, and a cleaner visual presentation.
 func TUIHandler(cmd *cobra.Command, channels []Channel) {
@@ -56,9 +54,35 @@ func TUIHandler(cmd *cobra.Command, channels []Channel) {
 		return
 	}
 
-	// If the user requested to persist output, print it to the terminal
-	if m, ok := finalModel.(*model); ok && m.printOutput {
+	m, ok := finalModel.(*model)
+	if !ok {
+		return
+	}
+
+	writeOutputFile(cmd, m.fullOutput())
+	printResults(cmd, m)
+}
+
+// printResults prints the results which should remain visible once the TUI is cleared on exit.
+func printResults(cmd *cobra.Command, m *model) {
+	switch {
+	case m.printOutput:
+		// The user requested to persist output, so print it to the terminal
 		printFullOutput(cmd, m)
+
+	case m.summaryOnly:
+		// Only show the full output if something went wrong, otherwise the summary is enough
+		if m.countFailed() > 0 {
+			printFullOutput(cmd, m)
+		} else {
+			printSummary(cmd, m)
+		}
+
+	default:
+		// Keep the skipped repositories visible
+		if skippedSummary := m.skippedSummary(); skippedSummary != "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), skippedSummary)
+		}
 	}
 }
 
@@ -68,6 +92,8 @@ type model struct {
 
 	command    string
 	repos      []*repoStatus
+	groups     []repoGroup
+	focus      int
 	cancelFunc context.CancelFunc
 	startTime  time.Time
 	endTime    time.Time
@@ -80,13 +106,16 @@ type model struct {
 	styles     outputStyles
 
 	printOutput bool
+	summaryOnly bool
 	waitOnExit  bool
+	failedOnly  bool // show only the sections of failed repositories in the viewport
 }
 
 // repoStatus represents the state of a repository's processing
 type repoStatus struct {
 	Channel
 
+	project    string
 	output     []byte
 	errors     []error
 	completed  bool
@@ -106,8 +135,11 @@ type repoErrorMsg struct {
 	err   error
 }
 
+// repoCompletedMsg reports that the output or error channel of a repository has closed. The index is always the
+// position of the repository in the original selection, regardless of the order in which repositories start.
 type repoCompletedMsg struct {
-	index int
+	index  int
+	errors bool // the error channel closed, rather than the output channel
 }
 
 type tickMsg time.Time
@@ -119,6 +151,7 @@ func initialModel(cmd *cobra.Command, channels []Channel, cancel context.CancelF
 	for i, ch := range channels {
 		repoStatuses[i] = &repoStatus{
 			Channel: ch,
+			project: repoProject(cmd.Context(), ch.Name()),
 		}
 	}
 
@@ -129,15 +162,28 @@ func initialModel(cmd *cobra.Command, channels []Channel, cancel context.CancelF
 		errs[i] = ch.Err()
 	}
 
-	return &model{
+	m := &model{
 		command:    buildCommandString(cmd),
 		repos:      repoStatuses,
 		cancelFunc: cancel,
 		startTime:  time.Now(),
 
 		printOutput: viper.GetBool(config.PrintResults),
+		summaryOnly: viper.GetBool(config.SummaryOnly),
 		waitOnExit:  viper.GetBool(config.WaitOnExit),
 	}
+
+	if viper.GetBool(config.GroupByLabel) {
+		names := make([]string, len(channels))
+		for i, ch := range channels {
+			names[i] = ch.Name()
+		}
+
+		labels := catalog.LabelSets()
+		m.groups = groupByLabel(names, selectionLabels(cmd.Context(), cmd.Flags().Args(), labels), labels)
+	}
+
+	return m
 }
 
 // buildCommandString constructs a display string for the executing command
@@ -152,10 +198,8 @@ func buildCommandString(cmd *cobra.Command) string {
 
 	// Add flags which add crucial context to the command
 	printedFlags := make([]string, 0)
-	for _, flagName := range includeFlags {
-		if flag := cmd.Flags().Lookup(flagName); flag != nil && flag.Changed {
-			printedFlags = append(printedFlags, fmt.Sprintf("%s: `%v`", flagName, flag.Value))
-		}
+	for _, flag := range RecordedFlags(cmd) {
+		printedFlags = append(printedFlags, fmt.Sprintf("%s: `%s`", flag.Name, flag.Value))
 	}
 
 	if len(printedFlags) > 0 {
@@ -203,7 +247,7 @@ func waitForError(index int, ch Channel) tea.Cmd {
 	return func() tea.Msg {
 		err, ok := <-ch.Err()
 		if !ok {
-			return repoCompletedMsg{index: index}
+			return repoCompletedMsg{index: index, errors: true}
 		}
 
 		return repoErrorMsg{index: index, err: err}
@@ -283,6 +327,16 @@ func (m *model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.quitting = true
 		return m, tea.Quit
 
+	case "e":
+		// Toggle between the sections of all repositories and only those which failed
+		m.mu.Lock()
+		m.failedOnly = !m.failedOnly
+		m.mu.Unlock()
+
+		m.viewport.SetContent(m.buildContent())
+		m.viewport.GotoTop()
+		return m, nil
+
 	case "p":
 		// Only allow print and quit with 'p' after all processing is complete
 		if m.allDone {
@@ -301,6 +355,12 @@ func (m *model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		fallthrough
 
 	default:
+		// Move between or collapse the output groups, if any
+		if m.handleGroupKey(msg.String()) {
+			m.viewport.SetContent(m.buildContent())
+			return m, nil
+		}
+
 		// Use shared viewport navigation handler
 		if handleKeyPress(&m.viewport, msg.String()) {
 			return m, nil
@@ -339,7 +399,7 @@ func (m *model) handleRepoError(msg repoErrorMsg) (tea.Model, tea.Cmd) {
 
 // handleRepoCompleted processes completion messages from repositories
 func (m *model) handleRepoCompleted(msg repoCompletedMsg) (tea.Model, tea.Cmd) {
-	allDone, valid := m.markRepoChannelClosed(msg.index)
+	allDone, valid := m.markRepoChannelClosed(msg.index, msg.errors)
 	if !valid {
 		return m, nil
 	}
@@ -359,6 +419,11 @@ func (m *model) handleRepoCompleted(msg repoCompletedMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// validIndex reports whether the index refers to a repository of the model.
+func (m *model) validIndex(index int) bool {
+	return index >= 0 && index < len(m.repos)
+}
+
 // appendRepoOutput appends data to a repo's output buffer.
 // Returns the channel and whether the message should be skipped (initial empty line).
 // Returns nil channel if index is out of bounds.
@@ -366,12 +431,12 @@ func (m *model) appendRepoOutput(index int, data []byte) (ch Channel, skip bool)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if index >= len(m.repos) {
+	if !m.validIndex(index) {
 		return nil, false
 	}
 
-	// First output signals that the subprocess has started
-	if !m.repos[index].active {
+	// First output signals that the subprocess has started, which happens in any order when concurrency is limited
+	if !m.repos[index].active && !m.repos[index].completed {
 		m.repos[index].active = true
 		if len(data) == 0 {
 			// Skip an initial empty line
@@ -389,7 +454,7 @@ func (m *model) appendRepoError(index int, err error) Channel {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if index >= len(m.repos) {
+	if !m.validIndex(index) {
 		return nil
 	}
 
@@ -397,21 +462,21 @@ func (m *model) appendRepoError(index int, err error) Channel {
 	return m.repos[index].Channel
 }
 
-// markRepoChannelClosed marks a channel as closed and checks completion state.
+// markRepoChannelClosed marks the output or error channel of a repo as closed and checks completion state.
 // Returns (allDone, valid) where valid is false if index is out of bounds.
-func (m *model) markRepoChannelClosed(index int) (allDone, valid bool) {
+func (m *model) markRepoChannelClosed(index int, errors bool) (allDone, valid bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if index >= len(m.repos) {
+	if !m.validIndex(index) {
 		return false, false
 	}
 
-	// Track which channel closed
-	if !m.repos[index].outputDone {
-		m.repos[index].outputDone = true
-	} else {
+	// Track which channel closed, since the error channel may be seen to close before the output is drained
+	if errors {
 		m.repos[index].errorsDone = true
+	} else {
+		m.repos[index].outputDone = true
 	}
 
 	// Only mark as completed when BOTH channels are closed
@@ -450,17 +515,57 @@ func (m *model) allReposCompleted() bool {
 
 // buildContent generates the scrollable content for the viewport
 func (m *model) buildContent() string {
+	return m.renderContent(true)
+}
+
+// renderContent generates the output of all repositories, grouped by label if configured. When interactive,
+// the sections of collapsed groups are hidden, as are the sections of successful repositories when showing
+// only failures; otherwise all sections are shown.
+func (m *model) renderContent(interactive bool) string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	show := func(*repoStatus) bool { return true }
+	if interactive && m.failedOnly {
+		show = func(repo *repoStatus) bool { return repo.completed && repo.failed }
+	}
+
+	var content string
+	if len(m.groups) > 0 {
+		content = m.formatGroups(interactive, show)
+	} else {
+		content = m.formatRepoSections(filterRepos(m.repos, show))
+	}
+
+	if content == "" && interactive && m.failedOnly {
+		return m.styles.status.Render(noFailedText)
+	}
+
+	return content
+}
+
+// filterRepos returns the repositories for which show returns true, in their original order.
+func filterRepos(repos []*repoStatus, show func(*repoStatus) bool) []*repoStatus {
+	filtered := make([]*repoStatus, 0, len(repos))
+	for _, repo := range repos {
+		if show(repo) {
+			filtered = append(filtered, repo)
+		}
+	}
+
+	return filtered
+}
+
+// formatRepoSections formats the sections of the given repositories, separated by a divider line.
+func (m *model) formatRepoSections(repos []*repoStatus) string {
 	var content strings.Builder
 
-	for i, repo := range m.repos {
+	for i, repo := range repos {
 		// Add repository section
 		content.WriteString(m.formatRepoSection(repo))
 
 		// Add separator between repos (except for the last one)
-		if i < len(m.repos)-1 {
+		if i < len(repos)-1 {
 			content.WriteString(m.styles.separator.Render(separatorLine))
 			content.WriteString("\n")
 		}
@@ -472,23 +577,60 @@ func (m *model) buildContent() string {
 // printFullOutput prints the complete output to the terminal without viewport wrapping.
 // This allows the full output to be persisted after the TUI exits.
 func printFullOutput(cmd *cobra.Command, m *model) {
+	printSummary(cmd, m)
+	fmt.Fprintln(cmd.ErrOrStderr())
+
+	out := cmd.OutOrStdout()
+
+	// Print all repository outputs using shared formatting logic
+	content := m.renderContent(false)
+	fmt.Fprint(out, content)
+	fmt.Fprintln(out)
+}
+
+// printSummary prints the command header and run summary to the terminal.
+func printSummary(cmd *cobra.Command, m *model) {
+	failed := m.countFailed()
+	projectSummary := m.projectSummary()
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	out := cmd.OutOrStdout()
 	err := cmd.ErrOrStderr()
 
 	// Print command header
 	fmt.Fprintln(err, m.styles.progress.Render(m.command))
 
 	// Print output summary
-	fmt.Fprintln(err, m.styles.progress.Render(fmt.Sprintf(summaryText, len(m.repos), m.getDuration())))
-	fmt.Fprintln(err)
+	fmt.Fprintln(err, m.styles.progress.Render(formatSummary(len(m.repos), failed, m.getDuration())))
+	if projectSummary != "" {
+		fmt.Fprintln(err, m.styles.progress.Render(projectSummary))
+	}
+	if skippedSummary := m.skippedSummary(); skippedSummary != "" {
+		fmt.Fprintln(err, m.styles.progress.Render(skippedSummary))
+	}
+}
 
-	// Print all repository outputs using shared formatting logic
-	content := m.buildContent()
-	fmt.Fprint(out, content)
-	fmt.Fprintln(out)
+// fullOutput returns the command header, run summary and all repository output as a single combined text.
+func (m *model) fullOutput() string {
+	var b strings.Builder
+
+	b.WriteString(m.command)
+	b.WriteString("\n")
+	b.WriteString(formatSummary(len(m.repos), m.countFailed(), m.getDuration()))
+	b.WriteString("\n")
+	if projectSummary := m.projectSummary(); projectSummary != "" {
+		b.WriteString(projectSummary)
+		b.WriteString("\n")
+	}
+	if skippedSummary := m.skippedSummary(); skippedSummary != "" {
+		b.WriteString(skippedSummary)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.renderContent(false))
+
+	return b.String()
 }
 
 // formatRepoSection formats a complete repository section including header, output, and errors.
@@ -617,11 +759,22 @@ func (m *model) renderFooter() string {
 
 	if m.allDone {
 		b.WriteString(m.styles.status.Render(footerDone))
-		b.WriteString(m.styles.status.Render(footerVim))
 	} else {
 		b.WriteString(m.styles.status.Render(footerText))
-		b.WriteString(m.styles.status.Render(footerVim))
 	}
+
+	// offer to show only the failures once there are any
+	if m.failedOnly {
+		b.WriteString(m.styles.status.Render(footerShowAll))
+	} else if m.countFailed() > 0 {
+		b.WriteString(m.styles.status.Render(footerFailedOnly))
+	}
+
+	if len(m.groups) > 0 {
+		b.WriteString(m.styles.status.Render(footerGroups))
+	}
+
+	b.WriteString(m.styles.status.Render(footerVim))
 	b.WriteString("\n")
 
 	return b.String()
@@ -642,6 +795,31 @@ func (m *model) countCompleted() int {
 	return count
 }
 
+// projectSummary returns the per-project breakdown of the completed repositories, if they span several projects.
+func (m *model) projectSummary() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	outcomes := make([]projectOutcome, 0, len(m.repos))
+	for _, repo := range m.repos {
+		outcomes = append(outcomes, projectOutcome{project: repo.project, failed: repo.completed && repo.failed})
+	}
+
+	return formatProjectSummary(outcomes)
+}
+
+// skippedSummary returns the list of completed repositories which were skipped, along with their reasons.
+func (m *model) skippedSummary() string {
+	var skipped []skippedRepo
+	for _, repo := range m.repos {
+		if reason := repo.Skipped(); repo.completed && reason != "" {
+			skipped = append(skipped, skippedRepo{name: repo.Name(), reason: reason})
+		}
+	}
+
+	return formatSkippedSummary(skipped)
+}
+
 // countFailed returns the number of repositories that completed with errors
 func (m *model) countFailed() int {
 	m.mu.RLock()
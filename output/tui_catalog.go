@@ -65,17 +65,18 @@ type repoWithMetadata struct {
 
 func newCatalogModel(ctx context.Context) catalogModel {
 	viper := config.Viper(ctx)
-	repos := make([]repoWithMetadata, 0, len(catalog.Catalog))
+	snapshot := catalog.Repositories()
+	repos := make([]repoWithMetadata, 0, len(snapshot))
 
 	// Convert catalog to sorted slice
-	repoNames := make([]string, 0, len(catalog.Catalog))
-	for name := range catalog.Catalog {
+	repoNames := make([]string, 0, len(snapshot))
+	for name := range snapshot {
 		repoNames = append(repoNames, name)
 	}
 	sort.Strings(repoNames)
 
 	for _, name := range repoNames {
-		repo := catalog.Catalog[name]
+		repo := snapshot[name]
 		labels := repo.Labels
 		if viper.GetBool(config.SortRepos) && len(labels) > 0 {
 			sort.Strings(labels)
@@ -259,8 +260,7 @@ func (m catalogModel) View() string {
 	var b strings.Builder
 
 	// Title with repository count
-	title := fmt.Sprintf("Repository Catalog (%d repositories)", len(m.repos))
-	b.WriteString(styles.title.Render(title))
+	b.WriteString(styles.title.Render(catalogTitle(m.ctx)))
 	b.WriteString("\n\n")
 
 	b.WriteString(m.viewport.View())
@@ -278,10 +278,20 @@ func (m catalogModel) printFullOutput(cmd *cobra.Command) {
 	out := cmd.OutOrStdout()
 
 	// Title
-	title := fmt.Sprintf("Repository Catalog (%d repositories)", len(m.repos))
-	fmt.Fprintln(out, styles.title.Render(title))
+	fmt.Fprintln(out, styles.title.Render(catalogTitle(m.ctx)))
 	fmt.Fprintln(out)
 
 	// Print content (reuses buildContent)
 	fmt.Fprint(out, m.buildContent())
 }
+
+// catalogTitle returns the catalog heading, including the count of wanted repositories
+// if any repositories in the catalog are excluded by default.
+func catalogTitle(ctx context.Context) string {
+	total := len(catalog.Repositories())
+	if wanted := catalog.WantedRepos(ctx).Cardinality(); wanted != total {
+		return fmt.Sprintf("Repository Catalog (%d / %d repositories)", wanted, total)
+	}
+
+	return fmt.Sprintf("Repository Catalog (%d repositories)", total)
+}
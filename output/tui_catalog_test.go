@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	mapset "github.com/deckarep/golang-set/v2"
 
 	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
@@ -364,3 +365,55 @@ func TestCatalogStylesArchived(t *testing.T) {
 		t.Errorf("Expected archivedDim foreground %q, got %q", colorComment, got)
 	}
 }
+
+// TestWantedCountsConsistent verifies that the catalog title, label counts, and repository
+// selection all agree on which repositories are wanted.
+func TestWantedCountsConsistent(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+	viper.Set(config.SkipUnwanted, true)
+	viper.Set(config.SkipArchived, true)
+	viper.Set(config.UnwantedLabels, []string{"deprecated"})
+	viper.Set(config.SuperSetLabel, "all")
+
+	catalog.Catalog = map[string]scm.Repository{
+		"repo1": {Name: "repo1"},
+		"repo2": {Name: "repo2"},
+		"repo3": {Name: "repo3", Labels: []string{"deprecated"}},
+		"repo4": {Name: "repo4", Archived: true},
+	}
+	catalog.Labels = map[string]mapset.Set[string]{
+		"all":        mapset.NewSet("repo1", "repo2", "repo3", "repo4"),
+		"core":       mapset.NewSet("repo1", "repo2", "repo3", "repo4"),
+		"deprecated": mapset.NewSet("repo3"),
+	}
+
+	selected := catalog.RepositoryList(ctx, "~all")
+	if selected.Cardinality() != 2 {
+		t.Fatalf("Expected 2 selected repos, got %v", selected.ToSlice())
+	}
+
+	if got, want := catalogTitle(ctx), "Repository Catalog (2 / 4 repositories)"; got != want {
+		t.Errorf("catalogTitle() = %q, want %q", got, want)
+	}
+
+	m := newLabelsListModel(ctx, false)
+	m.width = 100
+	if content := m.buildContent(); !strings.Contains(content, "(2 / 4)") {
+		t.Errorf("Expected label count '(2 / 4)' in content, got:\n%s", content)
+	}
+
+	// Disabling the filters makes every repository wanted on all paths
+	viper.Set(config.SkipUnwanted, false)
+	viper.Set(config.SkipArchived, false)
+
+	if got := catalog.RepositoryList(ctx, "~all"); got.Cardinality() != 4 {
+		t.Errorf("Expected 4 selected repos, got %v", got.ToSlice())
+	}
+	if got, want := catalogTitle(ctx), "Repository Catalog (4 repositories)"; got != want {
+		t.Errorf("catalogTitle() = %q, want %q", got, want)
+	}
+	if content := m.buildContent(); strings.Contains(content, "(2 / 4)") || !strings.Contains(content, "(4)") {
+		t.Errorf("Expected label count '(4)' in content, got:\n%s", content)
+	}
+}
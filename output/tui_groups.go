@@ -0,0 +1,166 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/catalog"
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// repoGroup is a collapsible section of the TUI output containing the repositories selected by a label.
+type repoGroup struct {
+	label     string // empty for the repositories which weren't selected by any label
+	repos     []int  // indices of the model's repositories
+	collapsed bool
+}
+
+// selectionLabels returns the labels used to select repositories, in the order they were given, as categorized by
+// [catalog.GroupLabels]. Glob filters are expanded to the matching labels in alphabetical order. Excluded labels are
+// ignored, since none of their repositories are selected.
+func selectionLabels(ctx context.Context, filters []string, labels map[string]mapset.Set[string]) []string {
+	group := catalog.GroupLabels(ctx, filters...)
+	token := config.Viper(ctx).GetString(config.TokenLabel)
+
+	var selection []string
+	for _, filter := range filters {
+		name := utils.CleanFilter(ctx, filter)
+		if key := name + token; !group.Included.Contains(key) && !group.Forced.Contains(key) && !group.Intersected.Contains(key) {
+			continue
+		}
+
+		for _, label := range catalog.MatchLabelNames(name, labels) {
+			if !slices.Contains(selection, label) {
+				selection = append(selection, label)
+			}
+		}
+	}
+
+	return selection
+}
+
+// groupByLabel groups the repositories under their primary label, which is the first of the selection labels
+// that includes them. Repositories which aren't included by any of the labels (e.g. those selected by name) are
+// grouped last under an empty label, and empty groups are omitted. It returns nil if no labels were selected.
+func groupByLabel(names, selection []string, labels map[string]mapset.Set[string]) []repoGroup {
+	if len(selection) == 0 {
+		return nil
+	}
+
+	groups := make([]repoGroup, len(selection)+1)
+	for i, label := range selection {
+		groups[i].label = label
+	}
+
+	for i, name := range names {
+		repo, _ := utils.SplitTarget(name)
+
+		primary := len(selection)
+		for j, label := range selection {
+			if set, ok := labels[label]; ok && set.Contains(repo) {
+				primary = j
+				break
+			}
+		}
+
+		groups[primary].repos = append(groups[primary].repos, i)
+	}
+
+	return slices.DeleteFunc(groups, func(group repoGroup) bool {
+		return len(group.repos) == 0
+	})
+}
+
+// handleGroupKey moves the focus between the output groups and collapses or expands the focused group.
+// Returns true if the key was handled, false otherwise.
+func (m *model) handleGroupKey(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.groups) == 0 {
+		return false
+	}
+
+	switch key {
+	case "tab":
+		m.focus = (m.focus + 1) % len(m.groups)
+	case "shift+tab":
+		m.focus = (m.focus + len(m.groups) - 1) % len(m.groups)
+	case " ":
+		m.groups[m.focus].collapsed = !m.groups[m.focus].collapsed
+	default:
+		return false
+	}
+
+	return true
+}
+
+// formatGroups formats the repository sections under their group headers. When interactive, the focused
+// group header is highlighted and the sections of collapsed groups are hidden. Only the repositories for which
+// show returns true are included, and groups without any such repositories are left out.
+func (m *model) formatGroups(interactive bool, show func(*repoStatus) bool) string {
+	var content strings.Builder
+
+	for i, group := range m.groups {
+		repos := make([]*repoStatus, 0, len(group.repos))
+		for _, index := range group.repos {
+			if show(m.repos[index]) {
+				repos = append(repos, m.repos[index])
+			}
+		}
+
+		if len(repos) == 0 && len(group.repos) > 0 {
+			continue
+		}
+
+		if content.Len() > 0 {
+			content.WriteString("\n")
+		}
+
+		content.WriteString(m.formatGroupHeader(group, interactive && i == m.focus, interactive && group.collapsed))
+		content.WriteString("\n")
+
+		if interactive && group.collapsed {
+			continue
+		}
+
+		content.WriteString(m.formatRepoSections(repos))
+	}
+
+	return content.String()
+}
+
+// formatGroupHeader returns a styled group header with the number of repositories (and failures) in the group.
+func (m *model) formatGroupHeader(group repoGroup, focused, collapsed bool) string {
+	marker, name := groupExpandedMarker, groupOtherText
+	if collapsed {
+		marker = groupCollapsedMarker
+	}
+
+	if group.label != "" {
+		name = fmt.Sprintf(labelNameFormat, group.label)
+	}
+
+	failed := 0
+	for _, index := range group.repos {
+		if repo := m.repos[index]; repo.completed && repo.failed {
+			failed++
+		}
+	}
+
+	header := fmt.Sprintf(groupHeaderFormat, marker, name, len(group.repos))
+	if failed > 0 {
+		header = fmt.Sprintf(groupHeaderFormatFail, marker, name, len(group.repos), failed)
+	}
+
+	if focused {
+		return m.styles.groupFocused.Render(header)
+	}
+
+	return m.styles.groupHeader.Render(header)
+}
@@ -0,0 +1,210 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set/v2"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestSelectionLabels(t *testing.T) {
+	ctx := loadFixture(t)
+
+	labels := map[string]mapset.Set[string]{
+		"backend":      mapset.NewSet("api"),
+		"experimental": mapset.NewSet("lab"),
+		"go":           mapset.NewSet("api"),
+		"legacy-api":   mapset.NewSet("old-api"),
+		"legacy-web":   mapset.NewSet("old-web"),
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		want    string
+	}{
+		{
+			name:    "included and forced labels in order",
+			filters: []string{"~backend", "repo1", "!~deprecated", "+~experimental", "~backend", "!repo2"},
+			want:    "backend,experimental",
+		},
+		{
+			name:    "intersected labels",
+			filters: []string{"~backend", "&go", "&~experimental"},
+			want:    "backend,go,experimental",
+		},
+		{
+			name:    "glob labels are expanded",
+			filters: []string{"~legacy-*", "~backend", "~legacy-api"},
+			want:    "legacy-api,legacy-web,backend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertEqual(t, strings.Join(selectionLabels(ctx, tt.filters, labels), ","), tt.want)
+		})
+	}
+}
+
+func TestGroupByLabel(t *testing.T) {
+	labels := map[string]mapset.Set[string]{
+		"backend":  mapset.NewSet("api", "worker", "shared"),
+		"frontend": mapset.NewSet("web", "shared"),
+		"unused":   mapset.NewSet("other"),
+	}
+
+	tests := []struct {
+		name      string
+		names     []string
+		selection []string
+		want      []string
+	}{
+		{
+			name:      "primary label is the first selected",
+			names:     []string{"api", "shared", "web", "worker"},
+			selection: []string{"backend", "frontend"},
+			want:      []string{"backend: [0 1 3]", "frontend: [2]"},
+		},
+		{
+			name:      "selection order determines the primary label",
+			names:     []string{"api", "shared", "web", "worker"},
+			selection: []string{"frontend", "backend"},
+			want:      []string{"frontend: [1 2]", "backend: [0 3]"},
+		},
+		{
+			name:      "repositories selected by name are grouped last",
+			names:     []string{"standalone", "web", "api"},
+			selection: []string{"backend", "frontend"},
+			want:      []string{"backend: [2]", "frontend: [1]", ": [0]"},
+		},
+		{
+			name:      "path-scoped targets use their repository",
+			names:     []string{"api//services/auth", "web"},
+			selection: []string{"backend", "frontend"},
+			want:      []string{"backend: [0]", "frontend: [1]"},
+		},
+		{
+			name:      "empty and unknown labels are omitted",
+			names:     []string{"api"},
+			selection: []string{"missing", "frontend", "backend"},
+			want:      []string{"backend: [0]"},
+		},
+		{
+			name:      "no selection labels",
+			names:     []string{"api", "web"},
+			selection: nil,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupByLabel(tt.names, tt.selection, labels)
+
+			got := make([]string, len(groups))
+			for i, group := range groups {
+				got[i] = fmt.Sprintf("%s: %v", group.label, group.repos)
+			}
+
+			testhelper.AssertEqual(t, strings.Join(got, "; "), strings.Join(tt.want, "; "))
+		})
+	}
+}
+
+func TestGroupedContent(t *testing.T) {
+	cmd := makeTestCommand(t)
+	m := initialModel(cmd, makeTestChannels([]string{"api", "web", "worker"}, true), testCancelFunc)
+
+	m.groups = []repoGroup{{label: "backend", repos: []int{0, 2}}, {repos: []int{1}}}
+	m.repos[0].output = []byte("api output\n")
+	m.repos[1].output = []byte("web output\n")
+	m.repos[2].completed, m.repos[2].failed = true, true
+
+	content := m.buildContent()
+	testhelper.AssertContains(t, content, []string{
+		"▾ # backend (2 repositories, 1 failed)",
+		"▾ (other repositories) (1 repositories)",
+		"api output",
+		"web output",
+	})
+
+	// the repositories are listed under their group headers
+	if strings.Index(content, "web output") < strings.Index(content, "(other repositories)") {
+		t.Errorf("Expected web output to follow its group header, got:\n%s", content)
+	}
+
+	// collapse the first group
+	testhelper.AssertEqual(t, m.handleGroupKey(" "), true)
+
+	content = m.buildContent()
+	testhelper.AssertContains(t, content, []string{"▸ # backend", "web output"})
+	testhelper.AssertNotContains(t, content, []string{"api output"})
+
+	// the full output always includes every group
+	testhelper.AssertContains(t, m.fullOutput(), []string{"▾ # backend", "api output", "web output"})
+}
+
+func TestGroupedContentFailedOnly(t *testing.T) {
+	cmd := makeTestCommand(t)
+	m := initialModel(cmd, makeTestChannels([]string{"api", "web", "worker"}, true), testCancelFunc)
+
+	m.groups = []repoGroup{{label: "backend", repos: []int{0, 2}}, {repos: []int{1}}}
+	m.repos[0].output = []byte("api output\n")
+	m.repos[0].completed = true
+	m.repos[1].output = []byte("web output\n")
+	m.repos[1].completed = true
+	m.repos[2].output = []byte("worker output\n")
+	m.repos[2].completed, m.repos[2].failed = true, true
+	m.failedOnly = true
+
+	// only the failed repository is shown, and groups without failures are left out
+	content := m.buildContent()
+	testhelper.AssertContains(t, content, []string{"▾ # backend (2 repositories, 1 failed)", "worker output"})
+	testhelper.AssertNotContains(t, content, []string{"api output", "web output", "(other repositories)"})
+}
+
+func TestHandleGroupKey(t *testing.T) {
+	cmd := makeTestCommand(t)
+	m := initialModel(cmd, makeTestChannels([]string{"api", "web"}, true), testCancelFunc)
+
+	// keys are not handled without groups
+	testhelper.AssertEqual(t, m.handleGroupKey("tab"), false)
+
+	m.groups = []repoGroup{{label: "backend", repos: []int{0}}, {label: "frontend", repos: []int{1}}}
+
+	testhelper.AssertEqual(t, m.handleGroupKey("tab"), true)
+	testhelper.AssertEqual(t, m.focus, 1)
+
+	testhelper.AssertEqual(t, m.handleGroupKey("tab"), true)
+	testhelper.AssertEqual(t, m.focus, 0)
+
+	testhelper.AssertEqual(t, m.handleGroupKey("shift+tab"), true)
+	testhelper.AssertEqual(t, m.focus, 1)
+
+	testhelper.AssertEqual(t, m.handleGroupKey(" "), true)
+	testhelper.AssertEqual(t, m.groups[1].collapsed, true)
+	testhelper.AssertEqual(t, m.groups[0].collapsed, false)
+
+	testhelper.AssertEqual(t, m.handleGroupKey("x"), false)
+}
+
+func TestInitialModelGroupByLabel(t *testing.T) {
+	cmd := makeTestCommand(t)
+	config.Viper(cmd.Context()).Set(config.GroupByLabel, true)
+
+	if err := cmd.ParseFlags([]string{"~missing", "api"}); err != nil {
+		t.Fatalf("Failed to parse args: %v", err)
+	}
+
+	m := initialModel(cmd, makeTestChannels([]string{"api"}, true), testCancelFunc)
+
+	// the unknown label is omitted, leaving only the ungrouped repository
+	testhelper.AssertLength(t, m.groups, 1)
+	testhelper.AssertEqual(t, m.groups[0].label, "")
+	testhelper.AssertContains(t, m.renderFooter(), "Tab/Shift+Tab")
+}
@@ -82,8 +82,10 @@ func newLabelsListModel(ctx context.Context, verbose bool) labelsListModel {
 	viper := config.Viper(ctx)
 	labels := make([]labelWithRepos, 0)
 
-	labelNames := make([]string, 0, len(catalog.Labels))
-	for label := range catalog.Labels {
+	labelSets := catalog.LabelSets()
+
+	labelNames := make([]string, 0, len(labelSets))
+	for label := range labelSets {
 		if label == viper.GetString(config.SuperSetLabel) {
 			continue
 		}
@@ -99,11 +101,9 @@ func newLabelsListModel(ctx context.Context, verbose bool) labelsListModel {
 			continue
 		}
 
-		if set, ok := catalog.Labels[label]; ok && set.Cardinality() > 0 {
+		if set, ok := labelSets[label]; ok && set.Cardinality() > 0 {
 			repos := set.ToSlice()
-			if viper.GetBool(config.SortRepos) {
-				sort.Strings(repos)
-			}
+			sort.Strings(repos)
 			labels = append(labels, labelWithRepos{name: label, repos: repos, isUnwanted: isUnwanted})
 		} else {
 			labels = append(labels, labelWithRepos{name: label, empty: true, isUnwanted: isUnwanted})
@@ -180,8 +180,8 @@ func (m labelsListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m labelsListModel) buildContent() string {
-	unwantedRepos := getUnwantedRepos(m.ctx)
-	styles := newLabelStyles(m.width)
+	unwantedRepos := catalog.UnwantedRepos(m.ctx)
+	styles := newLabelStyles(m.ctx, m.width)
 	var b strings.Builder
 
 	for i, label := range m.labels {
@@ -202,7 +202,7 @@ func (m labelsListModel) buildLabelContent(b *strings.Builder, styles labelStyle
 	if label.isUnwanted {
 		b.WriteString(styles.excluded.Render(fmt.Sprintf(labelNameFormat, label.name)))
 	} else {
-		b.WriteString(styles.normal.Render(fmt.Sprintf(labelNameFormat, label.name)))
+		b.WriteString(styles.label(label.name).Render(fmt.Sprintf(labelNameFormat, label.name)))
 	}
 	b.WriteString(" ")
 
@@ -256,7 +256,7 @@ func (m labelsListModel) View() string {
 		return "Loading labels..."
 	}
 
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 	var b strings.Builder
 
 	b.WriteString(styles.title.Render("Available Labels:"))
@@ -279,9 +279,10 @@ type labelsFilterModel struct {
 	labelGroup catalog.LabelGroup
 	repos      []string
 	labels     struct {
-		forced   []labelWithRepos
-		included []labelWithRepos
-		excluded []labelWithRepos
+		forced      []labelWithRepos
+		included    []labelWithRepos
+		excluded    []labelWithRepos
+		intersected []labelWithRepos
 	}
 	viewport viewport.Model
 	ready    bool
@@ -318,11 +319,12 @@ func newLabelsFilterModel(ctx context.Context, verbose bool, filters []string) l
 
 // verboseInit populates the labelWithRepos slices for each label category.
 func (m *labelsFilterModel) verboseInit(ctx context.Context, labelGroup catalog.LabelGroup) {
-	forced, included, excluded := labelGroup.ToSlices()
+	forced, included, excluded, intersected := labelGroup.ToSlices()
 
 	m.labels.forced = buildLabelWithRepos(ctx, forced)
 	m.labels.included = buildLabelWithRepos(ctx, included)
 	m.labels.excluded = buildLabelWithRepos(ctx, excluded)
+	m.labels.intersected = buildLabelWithRepos(ctx, intersected)
 }
 
 // buildLabelWithRepos converts label names into labelWithRepos structs with their repositories.
@@ -331,15 +333,13 @@ func buildLabelWithRepos(ctx context.Context, labelNames []string) []labelWithRe
 		return nil
 	}
 
-	viper := config.Viper(ctx)
+	labelSets := catalog.LabelSets()
 	labels := make([]labelWithRepos, 0, len(labelNames))
 
 	for _, label := range labelNames {
-		if set, ok := catalog.Labels[utils.CleanFilter(ctx, label)]; ok && set.Cardinality() > 0 {
+		if set, ok := labelSets[utils.CleanFilter(ctx, label)]; ok && set.Cardinality() > 0 {
 			repos := set.ToSlice()
-			if viper.GetBool(config.SortRepos) {
-				sort.Strings(repos)
-			}
+			sort.Strings(repos)
 			labels = append(labels, labelWithRepos{name: label, repos: repos})
 		} else {
 			labels = append(labels, labelWithRepos{name: label, empty: true})
@@ -409,13 +409,16 @@ func (m labelsFilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m labelsFilterModel) buildSetString() string {
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 
 	const (
-		union = "∪" // U+222A
-		minus = "∖" // U+2216
+		union     = "∪" // U+222A
+		minus     = "∖" // U+2216
+		intersect = "∩" // U+2229
 	)
 
+	intersected := m.labelGroup.Intersected.Set != nil && m.labelGroup.Intersected.Cardinality() > 0
+
 	var b strings.Builder
 
 	// Build colored set notation
@@ -425,7 +428,7 @@ func (m labelsFilterModel) buildSetString() string {
 		b.WriteString(styles.forced.Render(m.labelGroup.Forced.String()))
 		b.WriteString(styles.symbol.Render(")"))
 
-		if m.labelGroup.Included.Cardinality() == 0 {
+		if m.labelGroup.Included.Cardinality() == 0 && !intersected {
 			// if labels are only forced or excluded, then the exclusions aren't relevant
 			return b.String()
 		}
@@ -436,10 +439,23 @@ func (m labelsFilterModel) buildSetString() string {
 		}
 	}
 
-	// Included labels
-	b.WriteString(styles.symbol.Render("("))
-	b.WriteString(styles.normal.Render(m.labelGroup.Included.String()))
-	b.WriteString(styles.symbol.Render(")"))
+	// Included labels, narrowed by any intersected labels
+	switch {
+	case !intersected:
+		b.WriteString(styles.symbol.Render("("))
+		b.WriteString(styles.normal.Render(m.labelGroup.Included.String()))
+		b.WriteString(styles.symbol.Render(")"))
+	case m.labelGroup.Included.Cardinality() == 0:
+		b.WriteString(styles.symbol.Render("("))
+		b.WriteString(styles.normal.Render(m.labelGroup.Intersected.Intersection()))
+		b.WriteString(styles.symbol.Render(")"))
+	default:
+		b.WriteString(styles.symbol.Render("(("))
+		b.WriteString(styles.normal.Render(m.labelGroup.Included.String()))
+		b.WriteString(styles.symbol.Render(") " + intersect + " ("))
+		b.WriteString(styles.normal.Render(m.labelGroup.Intersected.Intersection()))
+		b.WriteString(styles.symbol.Render("))"))
+	}
 
 	if m.labelGroup.Excluded.Cardinality() > 0 {
 		// Excluded labels (not included unless forced)
@@ -458,7 +474,7 @@ func (m labelsFilterModel) buildSetString() string {
 
 func (m labelsFilterModel) buildContent(ctx context.Context) string {
 	var b strings.Builder
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 
 	// Matched repositories summary
 	b.WriteString("This matches ")
@@ -488,6 +504,10 @@ func (m labelsFilterModel) buildContent(ctx context.Context) string {
 			m.buildVerboseContent(ctx, m.labels.included, &b, styles, styles.normal, styles.wrap(styles.repo), "Included")
 		}
 
+		if len(m.labels.intersected) > 0 {
+			m.buildVerboseContent(ctx, m.labels.intersected, &b, styles, styles.normal, styles.wrap(styles.repo), "Intersected")
+		}
+
 		if len(m.labels.excluded) > 0 {
 			m.buildVerboseContent(ctx, m.labels.excluded, &b, styles, styles.excluded, styles.wrap(styles.unwanted), "Excluded")
 		}
@@ -526,7 +546,7 @@ func (m labelsFilterModel) View() string {
 	}
 
 	var b strings.Builder
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 
 	// Title and set representation
 	b.WriteString(styles.title.Render("Selected Set:"))
@@ -545,7 +565,7 @@ func (m labelsFilterModel) View() string {
 
 // printFullOutput prints the complete labels list output to stdout, reusing the buildContent logic
 func (m labelsListModel) printFullOutput(cmd *cobra.Command) {
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 	out := cmd.OutOrStdout()
 
 	// Title
@@ -558,7 +578,7 @@ func (m labelsListModel) printFullOutput(cmd *cobra.Command) {
 
 // printFullOutput prints the complete filtered labels output to stdout, reusing the buildContent logic
 func (m labelsFilterModel) printFullOutput(cmd *cobra.Command) {
-	styles := newLabelStyles(m.width)
+	styles := newLabelStyles(m.ctx, m.width)
 	out := cmd.OutOrStdout()
 
 	// Title and set representation
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
 func setupLabelsTest(t *testing.T) context.Context {
@@ -376,6 +377,19 @@ func TestLabelsFilterModelBuildContent(t *testing.T) {
 	}
 }
 
+func TestLabelsFilterModelBuildContentIntersected(t *testing.T) {
+	ctx := setupLabelsTest(t)
+	catalog.Labels["go"] = mapset.NewSet("repo2", "repo4")
+
+	m := newLabelsFilterModel(ctx, true, []string{"core~", "&go"})
+	m.width = 100
+
+	testhelper.AssertLength(t, m.labels.intersected, 1)
+
+	content := m.buildContent(ctx)
+	testhelper.AssertContains(t, content, []string{"This matches", "Intersected labels:", "repo2, repo4"})
+}
+
 func TestLabelsFilterModelView(t *testing.T) {
 	ctx := setupLabelsTest(t)
 	m := newLabelsFilterModel(ctx, false, []string{"core"})
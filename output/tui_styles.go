@@ -2,14 +2,14 @@ package output
 
 import (
 	"context"
+	"os"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
-	mapset "github.com/deckarep/golang-set/v2"
 
-	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
 )
 
@@ -39,12 +39,26 @@ const (
 	progressText     = "Progress: %d/" + summaryText
 	progressTextFail = "Progress: %d/" + summaryTextFail
 
+	projectSummaryText     = "  %s: %d repositories"
+	projectSummaryTextFail = "  %s: %d repositories (%d failed)"
+
+	resultsSummaryTitle = "Results:"
+
+	skippedSummaryTitle = "Skipped:"
+	skippedSummaryText  = "  %s: %s"
+
 	noReposText = "No repositories matched by provided filter, nothing to do."
 	footerText  = "scroll: ↑/↓ | paging: PgUp/PgDn/Home/End | cancel: q/Esc/Ctrl+C"
 	footerDone  = "✓ All done! " + "scroll: ↑/↓ | paging: PgUp/PgDn/Home/End" + " | print output: p | quit: Enter/Esc or q"
 	footerVim   = "\n\t(also supports Vim keybinds)"
 	tuiFailText = "Error running TUI: %v\nUsing fallback output handler...\n"
 
+	footerGroups = "\n\tgroups: Tab/Shift+Tab to select | Space to collapse/expand"
+
+	footerFailedOnly = " | failed only: e"
+	footerShowAll    = " | show all: e"
+	noFailedText     = "No repositories have failed."
+
 	repoWaitingFormat = "⏸ %s"
 	repoActiveFormat  = "▶ %s"
 	repoSuccessFormat = "✓ %s"
@@ -52,6 +66,12 @@ const (
 
 	emptyLabelText  = "(empty label)"
 	labelNameFormat = "# %s"
+
+	groupHeaderFormat     = "%s %s (%d repositories)"
+	groupHeaderFormatFail = "%s %s (%d repositories, %d failed)"
+	groupExpandedMarker   = "▾"
+	groupCollapsedMarker  = "▸"
+	groupOtherText        = "(other repositories)"
 )
 
 // -- Common style constructors
@@ -89,6 +109,9 @@ type outputStyles struct {
 	repoSuccess lipgloss.Style
 	repoError   lipgloss.Style
 
+	groupHeader  lipgloss.Style
+	groupFocused lipgloss.Style
+
 	separator lipgloss.Style
 	status    lipgloss.Style
 	output    lipgloss.Style
@@ -109,6 +132,9 @@ func newOutputStyles(width int) outputStyles {
 		repoSuccess: color(colorGreen).Bold(true),
 		repoError:   color(colorRed).Bold(true),
 
+		groupHeader:  color(colorPurple).Bold(true),
+		groupFocused: color(colorPurple).Bold(true).Underline(true),
+
 		separator: color(colorCurrentLine),
 		status:    color(colorPurple).Italic(true),
 		output:    wrapColor(colorForeground, width),
@@ -137,11 +163,15 @@ type labelStyles struct {
 	section   lipgloss.Style
 	title     lipgloss.Style
 	help      lipgloss.Style
+
+	// configured colors of labels, keyed by lowercase name
+	colors map[string]string
 }
 
-func newLabelStyles(width int) labelStyles {
+func newLabelStyles(ctx context.Context, width int) labelStyles {
 	return labelStyles{
-		wrap: wrapStyleFunc(width),
+		wrap:   wrapStyleFunc(width),
+		colors: labelColors(ctx),
 
 		repo:     color(colorForeground),
 		unwanted: color(colorComment),
@@ -158,6 +188,42 @@ func newLabelStyles(width int) labelStyles {
 	}
 }
 
+// label returns the style of the named label, using its configured color if it has one.
+func (s labelStyles) label(name string) lipgloss.Style {
+	if c, ok := s.colors[strings.ToLower(name)]; ok {
+		return s.normal.Foreground(lipgloss.Color(c))
+	}
+
+	return s.normal
+}
+
+// labelColors returns the configured colors of labels, keyed by lowercase name since configuration keys are
+// case-insensitive. Six-digit hex colors may omit the leading "#", as GitHub reports label colors. No colors are returned
+// if NO_COLOR is set, so the default styles are used.
+func labelColors(ctx context.Context) map[string]string {
+	if os.Getenv("NO_COLOR") != "" {
+		return nil
+	}
+
+	colors := make(map[string]string)
+	for name, c := range config.Viper(ctx).GetStringMapString(config.LabelColors) {
+		if c = strings.TrimSpace(c); c == "" {
+			continue
+		}
+
+		if hexColor.MatchString(c) {
+			c = "#" + c
+		}
+
+		colors[strings.ToLower(name)] = c
+	}
+
+	return colors
+}
+
+// hexColor matches a six-digit hex color without its leading "#", which can't be mistaken for an ANSI color number.
+var hexColor = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
 // catalogStyles contains styles for the catalog display
 type catalogStyles struct {
 	wrap func(style ...lipgloss.Style) lipgloss.Style
@@ -286,19 +352,6 @@ func renderProgressBar(styles outputStyles, completed, errors, total, width int)
 	return bar.String()
 }
 
-// Helper to get unwanted repos set
-func getUnwantedRepos(ctx context.Context) mapset.Set[string] {
-	unwantedRepos := mapset.NewSet[string]()
-
-	for _, unwanted := range config.Viper(ctx).GetStringSlice(config.UnwantedLabels) {
-		if set, ok := catalog.Labels[unwanted]; ok {
-			unwantedRepos = unwantedRepos.Union(set)
-		}
-	}
-
-	return unwantedRepos
-}
-
 // Helper to check if a label is unwanted
 func isLabelUnwanted(ctx context.Context, labelName string) bool {
 	viper := config.Viper(ctx)
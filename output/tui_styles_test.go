@@ -5,9 +5,8 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/bubbles/viewport"
-	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/charmbracelet/lipgloss"
 
-	"github.com/ryclarke/batch-tool/catalog"
 	"github.com/ryclarke/batch-tool/config"
 )
 
@@ -23,7 +22,7 @@ func TestNewOutputStyles(t *testing.T) {
 
 func TestNewLabelStyles(t *testing.T) {
 	width := 100
-	styles := newLabelStyles(width)
+	styles := newLabelStyles(loadFixture(t), width)
 
 	// Verify wrap function is initialized
 	if styles.wrap == nil {
@@ -33,6 +32,52 @@ func TestNewLabelStyles(t *testing.T) {
 	// Verify key styles exist (no width check as they don't have width set)
 }
 
+func TestLabelStylesColors(t *testing.T) {
+	tests := []struct {
+		name    string
+		label   string
+		noColor bool
+		want    lipgloss.TerminalColor
+	}{
+		{name: "hex color", label: "backend", want: lipgloss.Color("#0e8a16")},
+		{name: "github hex color without prefix", label: "frontend", want: lipgloss.Color("#d73a4a")},
+		{name: "ansi color", label: "infra", want: lipgloss.Color("123")},
+		{name: "case-insensitive label", label: "Backend", want: lipgloss.Color("#0e8a16")},
+		{name: "unset color", label: "other", want: lipgloss.Color(colorPurple)},
+		{name: "empty color", label: "blank", want: lipgloss.Color(colorPurple)},
+		{name: "NO_COLOR", label: "backend", noColor: true, want: lipgloss.Color(colorPurple)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noColor {
+				t.Setenv("NO_COLOR", "1")
+			} else {
+				t.Setenv("NO_COLOR", "")
+			}
+
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.LabelColors, map[string]string{
+				"backend":  "#0e8a16",
+				"frontend": "d73a4a",
+				"infra":    "123",
+				"blank":    " ",
+			})
+
+			styles := newLabelStyles(ctx, 100)
+
+			if got := styles.label(tt.label).GetForeground(); got != tt.want {
+				t.Errorf("label(%q) foreground = %v, want %v", tt.label, got, tt.want)
+			}
+
+			// the other label attributes are kept
+			if !styles.label(tt.label).GetBold() {
+				t.Errorf("Expected label(%q) to be bold", tt.label)
+			}
+		})
+	}
+}
+
 func TestHandleViewportKeyPress(t *testing.T) {
 	vp := viewport.New(80, 24)
 	vp.SetContent(strings.Repeat("line\n", 100))
@@ -87,36 +132,6 @@ func TestHandleViewportKeyPress(t *testing.T) {
 	}
 }
 
-func TestGetUnwantedRepos(t *testing.T) {
-	ctx := loadFixture(t)
-	viper := config.Viper(ctx)
-
-	// Set up unwanted labels in config
-	unwantedLabels := []string{"unwanted1", "unwanted2"}
-	viper.Set(config.UnwantedLabels, unwantedLabels)
-
-	// Create mock catalog with unwanted labels
-	catalog.Labels = make(map[string]mapset.Set[string])
-	catalog.Labels["unwanted1"] = mapset.NewSet("repo1", "repo2")
-	catalog.Labels["unwanted2"] = mapset.NewSet("repo3", "repo4")
-	catalog.Labels["wanted"] = mapset.NewSet("repo5", "repo6")
-
-	unwanted := getUnwantedRepos(ctx)
-
-	// Verify unwanted repos are included
-	if !unwanted.Contains("repo1") {
-		t.Error("Expected repo1 to be in unwanted set")
-	}
-	if !unwanted.Contains("repo3") {
-		t.Error("Expected repo3 to be in unwanted set")
-	}
-
-	// Verify wanted repos are not included
-	if unwanted.Contains("repo5") {
-		t.Error("Expected repo5 to NOT be in unwanted set")
-	}
-}
-
 func TestIsLabelUnwanted(t *testing.T) {
 	ctx := loadFixture(t)
 	viper := config.Viper(ctx)
@@ -2,6 +2,8 @@ package output
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +11,9 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+
+	"github.com/ryclarke/batch-tool/config"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
 
 // TestBuildCommandString tests the command string building logic
@@ -44,6 +49,42 @@ func TestBuildCommandString(t *testing.T) {
 			},
 			expected: "Executing test (script: `test.sh`)",
 		},
+		{
+			name: "command with declared flags",
+			setup: func(cmd *cobra.Command) {
+				cmd.Use = "test"
+				cmd.Flags().String("message", "", "commit message")
+				cmd.Flags().String("script", "", "script path")
+				cmd.Flags().String("auth-token", "", "token")
+				cmd.ParseFlags([]string{"--message=fix", "--script=test.sh", "--auth-token=secret"})
+				RecordFlags(cmd, "message")
+			},
+			expected: "Executing test (message: `fix`)",
+		},
+		{
+			name: "redacted flag records only keys",
+			setup: func(cmd *cobra.Command) {
+				cmd.Use = "test"
+				cmd.Flags().StringSlice("env", nil, "environment variables")
+				RedactFlag(cmd.Flags(), "env")
+				cmd.ParseFlags([]string{"--env=AUTH_TOKEN=secret", "--env=vars.env"})
+				RecordFlags(cmd, "env")
+			},
+			expected: "Executing test (env: `[AUTH_TOKEN=***,vars.env]`)",
+		},
+		{
+			name: "subcommand inherits declared flags",
+			setup: func(cmd *cobra.Command) {
+				parent := RecordFlags(&cobra.Command{Use: "parent"}, "message")
+				parent.AddCommand(cmd)
+
+				cmd.Use = "test"
+				cmd.Flags().String("message", "", "commit message")
+				cmd.Flags().String("auth-token", "", "token")
+				cmd.ParseFlags([]string{"--message=fix", "--auth-token=secret"})
+			},
+			expected: "Executing parent test (message: `fix`)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -170,7 +211,7 @@ func TestHandleRepoCompleted(t *testing.T) {
 	}
 
 	// Second completion (error channel closed)
-	newModel, _ = m.handleRepoCompleted(msg)
+	newModel, _ = m.handleRepoCompleted(repoCompletedMsg{index: 0, errors: true})
 	m = newModel.(*model)
 
 	if !m.repos[0].errorsDone {
@@ -577,6 +618,9 @@ func TestWaitForError(t *testing.T) {
 		if completedMsg.index != 0 {
 			t.Errorf("Expected index 0, got %d", completedMsg.index)
 		}
+		if !completedMsg.errors {
+			t.Error("Expected completion to be reported for the error channel")
+		}
 	} else {
 		t.Errorf("Expected repoCompletedMsg, got %T", msg)
 	}
@@ -592,10 +636,9 @@ func TestHandleRepoCompletedSetsAllDone(t *testing.T) {
 	m.viewport = viewport.New(80, 24)
 
 	// Complete both channels
-	msg := repoCompletedMsg{index: 0}
-	newModel, _ := m.handleRepoCompleted(msg)
+	newModel, _ := m.handleRepoCompleted(repoCompletedMsg{index: 0})
 	m = newModel.(*model)
-	newModel, _ = m.handleRepoCompleted(msg)
+	newModel, _ = m.handleRepoCompleted(repoCompletedMsg{index: 0, errors: true})
 	m = newModel.(*model)
 
 	if !m.allDone {
@@ -627,10 +670,9 @@ func TestHandleRepoCompletedWithError(t *testing.T) {
 	m.repos[0].errors = []error{errors.New("test error")}
 
 	// Complete both channels
-	msg := repoCompletedMsg{index: 0}
-	newModel, _ := m.handleRepoCompleted(msg)
+	newModel, _ := m.handleRepoCompleted(repoCompletedMsg{index: 0})
 	m = newModel.(*model)
-	newModel, _ = m.handleRepoCompleted(msg)
+	newModel, _ = m.handleRepoCompleted(repoCompletedMsg{index: 0, errors: true})
 	m = newModel.(*model)
 
 	if !m.repos[0].failed {
@@ -645,6 +687,133 @@ func TestHandleRepoCompletedWithError(t *testing.T) {
 	}
 }
 
+// TestHandleReposStartedOutOfOrder tests that each section maps to the right repository when repositories start
+// and complete in a different order than they were selected, as happens with limited concurrency.
+func TestHandleReposStartedOutOfOrder(t *testing.T) {
+	cmd := makeTestCommand(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	channels := makeTestChannels(repos, false)
+
+	m := initialModel(cmd, channels, testCancelFunc)
+	m.viewport = viewport.New(80, 24)
+
+	update := func(msg tea.Msg) {
+		t.Helper()
+
+		newModel, _ := m.Update(msg)
+		m = newModel.(*model)
+	}
+
+	// repo3 starts first and its error channel is seen to close before its output is drained
+	update(repoOutputMsg{index: 2, data: []byte{}})
+	update(repoOutputMsg{index: 2, data: []byte("output of repo3\n")})
+	update(repoCompletedMsg{index: 2, errors: true})
+
+	if !m.repos[2].active || m.repos[2].completed {
+		t.Error("Expected repo3 to still be active until its output channel closes")
+	}
+
+	update(repoOutputMsg{index: 2, data: []byte("more output of repo3\n")})
+	update(repoCompletedMsg{index: 2})
+
+	// repo1 starts once repo3 releases its slot, and repo2 last
+	update(repoOutputMsg{index: 0, data: []byte{}})
+	update(repoOutputMsg{index: 0, data: []byte("output of repo1\n")})
+	update(repoErrorMsg{index: 0, err: errors.New("repo1 failed")})
+	update(repoOutputMsg{index: 1, data: []byte{}})
+
+	if !m.repos[0].active || !m.repos[1].active || m.repos[2].active {
+		t.Errorf("Expected only repo1 and repo2 to be active, got %v %v %v", m.repos[0].active, m.repos[1].active, m.repos[2].active)
+	}
+
+	update(repoOutputMsg{index: 1, data: []byte("output of repo2\n")})
+	update(repoCompletedMsg{index: 0})
+	update(repoCompletedMsg{index: 0, errors: true})
+	update(repoCompletedMsg{index: 1, errors: true})
+	update(repoCompletedMsg{index: 1})
+
+	want := map[int]string{
+		0: "output of repo1\n",
+		1: "output of repo2\n",
+		2: "output of repo3\nmore output of repo3\n",
+	}
+
+	for i, repo := range repos {
+		testhelper.AssertEqual(t, m.repos[i].Name(), repo)
+		testhelper.AssertEqual(t, string(m.repos[i].output), want[i])
+
+		if !m.repos[i].completed {
+			t.Errorf("Expected %s to be completed", repo)
+		}
+	}
+
+	if !m.repos[0].failed || m.repos[1].failed || m.repos[2].failed {
+		t.Error("Expected only repo1 to be marked as failed")
+	}
+
+	if !m.allDone {
+		t.Error("Expected allDone to be true after all repos complete")
+	}
+
+	// Clean up channels
+	for _, ch := range channels {
+		tc := ch.(*testChannel)
+		close(tc.output)
+		close(tc.err)
+	}
+}
+
+// TestToggleFailedOnly tests toggling the viewport between all repositories and only the failed ones
+func TestToggleFailedOnly(t *testing.T) {
+	cmd := makeTestCommand(t)
+	repos := []string{"repo1", "repo2", "repo3"}
+	m := initialModel(cmd, makeTestChannels(repos, true), testCancelFunc)
+	m.viewport = viewport.New(80, 24)
+
+	m.repos[0].output = []byte("output of repo1\n")
+	m.repos[0].completed = true
+	m.repos[1].output = []byte("output of repo2\n")
+	m.repos[1].completed, m.repos[1].failed = true, true
+	m.repos[1].errors = []error{errors.New("repo2 failed")}
+	m.repos[2].output = []byte("output of repo3\n")
+	m.repos[2].active = true
+
+	toggle := func() {
+		t.Helper()
+
+		newModel, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+		m = newModel.(*model)
+	}
+
+	testhelper.AssertContains(t, m.renderFooter(), footerFailedOnly)
+
+	// 'p' before completion falls through to the default key handling rather than toggling the filter
+	newModel, _ := m.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = newModel.(*model)
+	testhelper.AssertEqual(t, m.failedOnly, false)
+	testhelper.AssertEqual(t, m.quitting, false)
+
+	toggle()
+	testhelper.AssertEqual(t, m.failedOnly, true)
+
+	content := m.buildContent()
+	testhelper.AssertContains(t, content, []string{"repo2", "output of repo2", "repo2 failed"})
+	testhelper.AssertNotContains(t, content, []string{"output of repo1", "output of repo3"})
+	testhelper.AssertContains(t, m.renderFooter(), footerShowAll)
+
+	// the full output always includes every repository
+	testhelper.AssertContains(t, m.fullOutput(), []string{"output of repo1", "output of repo2", "output of repo3"})
+
+	toggle()
+	testhelper.AssertEqual(t, m.failedOnly, false)
+	testhelper.AssertContains(t, m.buildContent(), []string{"output of repo1", "output of repo2", "output of repo3"})
+
+	// without any failures, the filtered view says so
+	m.repos[1].failed = false
+	toggle()
+	testhelper.AssertContains(t, m.buildContent(), noFailedText)
+}
+
 // TestHandleRepoCompletedOutOfBounds tests handling completion for invalid index
 func TestHandleRepoCompletedOutOfBounds(t *testing.T) {
 	cmd := makeTestCommand(t)
@@ -664,6 +833,14 @@ func TestHandleRepoCompletedOutOfBounds(t *testing.T) {
 		t.Error("Expected repo to not be marked completed for out of bounds index")
 	}
 
+	// Negative indexes are rejected as well
+	newModel, _ = m.handleRepoCompleted(repoCompletedMsg{index: -1})
+	m = newModel.(*model)
+
+	if ch, _ := m.appendRepoOutput(-1, []byte("data")); ch != nil {
+		t.Error("Expected no channel for a negative index")
+	}
+
 	// Clean up channels
 	for _, ch := range channels {
 		tc := ch.(*testChannel)
@@ -1059,3 +1236,195 @@ func TestTickCmd(t *testing.T) {
 		t.Fatal("Expected tickCmd to return a command")
 	}
 }
+
+// TestFullOutputWrittenToFile tests that the combined TUI output is written to the output file without ANSI styling
+func TestFullOutputWrittenToFile(t *testing.T) {
+	cmd := makeTestCommand(t)
+	path := filepath.Join(t.TempDir(), "run.log")
+	config.Viper(cmd.Context()).Set(config.OutputFile, path)
+
+	m := initialModel(cmd, makeTestChannels([]string{"repo1", "repo2"}, true), testCancelFunc)
+	m.styles = newOutputStyles(80)
+	m.allDone = true
+	m.endTime = m.startTime.Add(2 * time.Second)
+
+	m.repos[0].completed = true
+	m.repos[0].output = []byte("line 1\n\x1b[32mline 2\x1b[0m")
+
+	m.repos[1].completed = true
+	m.repos[1].failed = true
+	m.repos[1].output = []byte("error line")
+	m.repos[1].errors = []error{errors.New("test error")}
+
+	writeOutputFile(cmd, m.fullOutput())
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	content := string(data)
+	testhelper.AssertContains(t, content, []string{
+		"Executing test",
+		"2 repositories (1 failed) | Elapsed: 2s",
+		"✓ repo1",
+		"line 1",
+		"line 2",
+		"✗ repo2",
+		"error line",
+		"ERROR: test error",
+	})
+
+	if strings.Contains(content, "\x1b[") {
+		t.Errorf("Expected output file without ANSI escape sequences, got %q", content)
+	}
+}
+
+// TestFormatProjectSummary tests the per-project breakdown of the run summary
+func TestFormatProjectSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []projectOutcome
+		want     string
+	}{
+		{
+			name:     "single project",
+			outcomes: []projectOutcome{{project: "alpha"}, {project: "alpha", failed: true}},
+			want:     "",
+		},
+		{
+			name: "two projects",
+			outcomes: []projectOutcome{
+				{project: "beta", failed: true},
+				{project: "alpha"},
+				{project: "beta"},
+				{project: "alpha"},
+				{project: "beta", failed: true},
+			},
+			want: "  alpha: 2 repositories\n  beta: 3 repositories (2 failed)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertEqual(t, formatProjectSummary(tt.outcomes), tt.want)
+		})
+	}
+}
+
+// TestFullOutputProjectSummary tests that the combined TUI output breaks down the results of a multi-project run
+func TestFullOutputProjectSummary(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	m := initialModel(cmd, makeTestChannels([]string{"alpha/repo1", "beta/repo2", "beta/repo3"}, true), testCancelFunc)
+	m.allDone = true
+	m.endTime = m.startTime.Add(2 * time.Second)
+
+	for _, repo := range m.repos {
+		repo.completed = true
+	}
+
+	m.repos[2].failed = true
+
+	testhelper.AssertContains(t, m.fullOutput(), []string{
+		"3 repositories (1 failed) | Elapsed: 2s\n  alpha: 1 repositories\n  beta: 2 repositories (1 failed)\n",
+	})
+}
+
+// TestFormatSkippedSummary tests the listing of skipped repositories in the run summary
+func TestFormatSkippedSummary(t *testing.T) {
+	testhelper.AssertEqual(t, formatSkippedSummary(nil), "")
+
+	skipped := []skippedRepo{
+		{name: "repo1", reason: "declined by user"},
+		{name: "repo3", reason: "no matching pull requests"},
+	}
+
+	testhelper.AssertEqual(t, formatSkippedSummary(skipped), "Skipped:\n  repo1: declined by user\n  repo3: no matching pull requests")
+}
+
+// TestFormatResultsSummary tests the pass/fail listing of repositories in the native run summary
+func TestFormatResultsSummary(t *testing.T) {
+	testhelper.AssertEqual(t, formatResultsSummary([]repoResult{{name: "repo1"}}), "")
+
+	results := []repoResult{
+		{name: "repo1"},
+		{name: "repo2", errs: []error{errors.New("merge conflict\nin go.mod"), errors.New("cleanup failed")}},
+		{name: "repo3", errs: []error{errors.New("  timed out  ")}},
+	}
+
+	testhelper.AssertEqual(t, formatResultsSummary(results), "Results:\n  ✓ repo1\n  ✗ repo2: merge conflict (+1 more)\n  ✗ repo3: timed out")
+}
+
+// TestFullOutputSkippedSummary tests that the combined TUI output lists skipped repositories with their reasons
+func TestFullOutputSkippedSummary(t *testing.T) {
+	cmd := makeTestCommand(t)
+
+	channels := makeTestChannels([]string{"repo1", "repo2", "repo3"}, true)
+	channels[1].Skip("unapproved pull request")
+
+	m := initialModel(cmd, channels, testCancelFunc)
+	m.allDone = true
+
+	for _, repo := range m.repos {
+		repo.completed = true
+	}
+
+	out := m.fullOutput()
+	testhelper.AssertContains(t, out, []string{"Skipped:\n  repo2: unapproved pull request"})
+
+	if strings.Contains(out, "repo1: ") || strings.Contains(out, "repo3: ") {
+		t.Errorf("Expected only skipped repositories in the summary, got %q", out)
+	}
+}
+
+func TestPrintResultsSummaryOnlyOnSuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		failed     bool
+		wantOut    []string
+		wantErrOut []string
+	}{
+		{
+			name:       "all succeeded",
+			wantErrOut: []string{"Executing test", "2 repositories | Elapsed:"},
+		},
+		{
+			name:       "with failure",
+			failed:     true,
+			wantOut:    []string{"✓ repo1", "line 1", "✗ repo2", "ERROR: test error"},
+			wantErrOut: []string{"Executing test", "2 repositories (1 failed) | Elapsed:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output, errOut strings.Builder
+			cmd := makeTestCommand(t)
+			cmd.SetOut(&output)
+			cmd.SetErr(&errOut)
+			config.Viper(cmd.Context()).Set(config.SummaryOnly, true)
+
+			m := initialModel(cmd, makeTestChannels([]string{"repo1", "repo2"}, true), testCancelFunc)
+			m.allDone = true
+
+			m.repos[0].completed = true
+			m.repos[0].output = []byte("line 1")
+
+			m.repos[1].completed = true
+			if tt.failed {
+				m.repos[1].failed = true
+				m.repos[1].errors = []error{errors.New("test error")}
+			}
+
+			printResults(cmd, m)
+
+			if len(tt.wantOut) == 0 {
+				testhelper.AssertEqual(t, output.String(), "")
+			}
+
+			testhelper.AssertContains(t, output.String(), tt.wantOut)
+			testhelper.AssertContains(t, errOut.String(), tt.wantErrOut)
+		})
+	}
+}
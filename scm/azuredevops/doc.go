@@ -0,0 +1,14 @@
+// Copyright 2018-2026 Ryan Clarke (ryclarke-github@rkc.aleeas.com)
+//
+// Licensed under the Apache License, Version 2.0
+
+/*
+Package azuredevops implements the scm.Provider contract against Azure DevOps Repos.
+
+Repositories are grouped by Azure DevOps project within the configured
+organization, so each catalog project maps to an Azure DevOps project. The
+package translates provider-neutral pull-request operations into the Git REST
+API, resolving reviewer names to Azure DevOps identity IDs and merge methods to
+completion merge strategies.
+*/
+package azuredevops
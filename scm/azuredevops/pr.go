@@ -0,0 +1,455 @@
+package azuredevops
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// minimumReviewersPolicy is the ID of the "Minimum number of reviewers" branch policy type.
+const minimumReviewersPolicy = "fa4e907d-c16b-4a4c-9dfa-4906e5d171dd"
+
+// reviewer votes at or above voteApproved approve a pull request, and votes at or below voteWaiting block it
+const (
+	voteApproved = 5
+	voteWaiting  = -5
+)
+
+// mergeStrategies maps the provider-neutral merge methods to Azure DevOps completion merge strategies.
+var mergeStrategies = map[string]string{
+	"merge":  "noFastForward",
+	"squash": "squash",
+	"rebase": "rebase",
+}
+
+// GetPullRequest retrieves a pull request by repository name and source branch.
+func (a *AzureDevOps) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	resp, err := a.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestByNumber retrieves a pull request by repository name and ID.
+func (a *AzureDevOps) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := get[prResp](a, a.prURL(repo, number))
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("no pull request #%d found in repository %s", number, repo)
+		}
+
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the last commit of the source branch of a pull request by repository name
+// and source branch.
+func (a *AzureDevOps) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	resp, err := a.getPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.LastMergeSourceCommit == nil {
+		return "", nil
+	}
+
+	return resp.LastMergeSourceCommit.CommitID, nil
+}
+
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (a *AzureDevOps) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	queryParams := url.Values{}
+	queryParams.Set("searchCriteria.status", "active")
+
+	resp, err := list[*prResp](a, queryParams, func(q url.Values) string {
+		return a.repoURL(repo, q, "pullrequests")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	output := make([]*scm.PullRequest, len(resp))
+	for i, pr := range resp {
+		output[i] = parsePR(pr)
+	}
+
+	return output, nil
+}
+
+// OpenPullRequest opens a new pull request in the specified repository.
+func (a *AzureDevOps) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	// reads are less restrictive than a failed write, so check for existing PR first
+	if existing, err := a.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parsePR(existing))
+	}
+
+	// if title is not specified, use the branch name
+	if opts.Title == "" {
+		opts.Title = branch
+	}
+
+	// use provided base branch or fall back to configured default
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = config.Viper(a.ctx).GetString(config.DefaultBranch)
+	}
+
+	payload := &prPayload{
+		SourceRefName: "refs/heads/" + branch,
+		TargetRefName: "refs/heads/" + baseBranch,
+		Title:         opts.Title,
+		Description:   opts.Description,
+		IsDraft:       opts.Draft,
+	}
+
+	// reviewers are requested by identity ID rather than by name
+	userIDs, err := a.reviewerIDs(opts.Reviewers, false)
+	if err != nil {
+		return nil, err
+	}
+
+	teamIDs, err := a.reviewerIDs(opts.TeamReviewers, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range append(userIDs, teamIDs...) {
+		payload.Reviewers = append(payload.Reviewers, reviewerRef{ID: id})
+	}
+
+	pr, err := send[prResp](a, http.MethodPost, a.repoURL(repo, nil, "pullrequests"), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	return parsePR(pr), nil
+}
+
+// UpdatePullRequest updates an existing pull request.
+func (a *AzureDevOps) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	pr, err := a.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, changed := processChanges(opts); changed {
+		if pr, err = send[prResp](a, http.MethodPatch, a.prURL(repo, pr.PullRequestID), payload); err != nil {
+			return nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+	}
+
+	// if there are reviewer changes, apply them regardless of whether other changes were made
+	if len(opts.Reviewers) == 0 && len(opts.TeamReviewers) == 0 {
+		return parsePR(pr), nil
+	}
+
+	if err = a.applyReviewers(repo, pr, opts.Reviewers, false, opts.ResetReviewers); err != nil {
+		return nil, err
+	}
+
+	if err = a.applyReviewers(repo, pr, opts.TeamReviewers, true, opts.ResetReviewers); err != nil {
+		return nil, err
+	}
+
+	// Refresh PR to get updated reviewer list
+	if pr, err = get[prResp](a, a.prURL(repo, pr.PullRequestID)); err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return parsePR(pr), nil
+}
+
+// MergePullRequest completes an existing pull request.
+func (a *AzureDevOps) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PRMergeOptions{} // default options
+	}
+
+	pr, err := a.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		checked := parsePR(pr)
+		if pr.MergeStatus != "succeeded" {
+			checked.MergeBlocker = fmt.Sprintf("merge status %q", pr.MergeStatus)
+		}
+
+		return checked, nil
+	}
+
+	if opts.CheckMergeable && pr.MergeStatus != "succeeded" {
+		return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable: %s", branch, pr.PullRequestID, repo, pr.MergeStatus)
+	}
+
+	// if no merge method specified, use the default from config (if set)
+	method := opts.Method
+	if method == "" {
+		method = config.Viper(a.ctx).GetString(config.DefaultMergeMethod)
+	}
+
+	// Azure DevOps completes with a merge commit unless another strategy is specified
+	payload := &completePayload{
+		Status:                "completed",
+		LastMergeSourceCommit: pr.LastMergeSourceCommit,
+	}
+
+	if method != "" {
+		strategy, ok := mergeStrategies[method]
+		if !ok {
+			return nil, fmt.Errorf("merge method %q is not supported by the Azure DevOps provider", method)
+		}
+
+		payload.CompletionOptions = &completionOptions{MergeStrategy: strategy}
+	}
+
+	if _, err := send[prResp](a, http.MethodPatch, a.prURL(repo, pr.PullRequestID), payload); err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	return parsePR(pr), nil
+}
+
+// GetReviewStatus retrieves the approval state of a pull request, using the branch policies
+// of its target branch to determine the number of required approvals.
+func (a *AzureDevOps) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	pr, err := a.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := a.requiredApprovals(pr.Repository.ID, pr.TargetRefName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReviewStatus(pr.Reviewers, required), nil
+}
+
+func (a *AzureDevOps) getPullRequest(repo, branch string) (*prResp, error) {
+	queryParams := url.Values{}
+	queryParams.Set("searchCriteria.status", "active")
+	queryParams.Set("searchCriteria.sourceRefName", "refs/heads/"+branch)
+	queryParams.Set("$top", "1")
+
+	resp, err := get[listResp[*prResp]](a, a.repoURL(repo, queryParams, "pullrequests"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	if len(resp.Value) == 0 {
+		return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+	}
+
+	return resp.Value[0], nil
+}
+
+// constructs the URL for the given pull request.
+func (a *AzureDevOps) prURL(repo string, id int, path ...string) string {
+	return a.repoURL(repo, nil, append([]string{"pullrequests", strconv.Itoa(id)}, path...)...)
+}
+
+// requiredApprovals returns the number of approvals required by the "Minimum number of reviewers" policies of the
+// given target branch. If the branch has no such policy or its policies cannot be read, a single approval is required.
+func (a *AzureDevOps) requiredApprovals(repositoryID, refName string) (int, error) {
+	queryParams := url.Values{}
+	queryParams.Set("repositoryId", repositoryID)
+	queryParams.Set("refName", refName)
+	queryParams.Set("policyType", minimumReviewersPolicy)
+
+	resp, err := get[listResp[policyResp]](a, a.projectURL(queryParams, "policy", "configurations"))
+	if err != nil {
+		// treat inaccessible policies as unprotected
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden) {
+			return 1, nil
+		}
+
+		return 0, fmt.Errorf("failed to get branch policies: %w", err)
+	}
+
+	// always require at least one approval, even if the policies don't
+	required := 1
+	for _, policy := range resp.Value {
+		if policy.IsEnabled && policy.IsBlocking {
+			required = max(required, policy.Settings.MinimumApproverCount)
+		}
+	}
+
+	return required, nil
+}
+
+// processChanges builds the update payload for the requested title, description and draft changes.
+func processChanges(opts *scm.PROptions) (payload *prPayload, changed bool) {
+	payload = &prPayload{}
+
+	if opts.Title != "" {
+		payload.Title = opts.Title
+		changed = true
+	}
+
+	if opts.Description != "" {
+		payload.Description = opts.Description
+		changed = true
+	}
+
+	if opts.Draft != nil {
+		payload.IsDraft = opts.Draft
+		changed = true
+	}
+
+	return payload, changed
+}
+
+// parseReviewStatus sorts the individual reviewers of a pull request by their current vote.
+func parseReviewStatus(reviewers []reviewerResp, required int) *scm.ReviewStatus {
+	status := &scm.ReviewStatus{RequiredApprovals: required}
+
+	for _, reviewer := range reviewers {
+		// team votes mirror the votes of their members
+		if reviewer.IsContainer {
+			continue
+		}
+
+		switch {
+		case reviewer.Vote >= voteApproved:
+			status.Approvers = append(status.Approvers, reviewer.UniqueName)
+		case reviewer.Vote <= voteWaiting:
+			status.ChangesRequested = append(status.ChangesRequested, reviewer.UniqueName)
+		}
+	}
+
+	return status
+}
+
+type prResp struct {
+	PullRequestID int    `json:"pullRequestId"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	SourceRefName string `json:"sourceRefName"`
+	TargetRefName string `json:"targetRefName"`
+	IsDraft       bool   `json:"isDraft"`
+	MergeStatus   string `json:"mergeStatus"`
+
+	Repository struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"repository"`
+
+	Reviewers             []reviewerResp `json:"reviewers"`
+	LastMergeSourceCommit *commitRef     `json:"lastMergeSourceCommit,omitempty"`
+}
+
+type reviewerResp struct {
+	ID          string `json:"id"`
+	UniqueName  string `json:"uniqueName"`
+	DisplayName string `json:"displayName"`
+	Vote        int    `json:"vote"`
+	IsContainer bool   `json:"isContainer"`
+}
+
+type commitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+type reviewerRef struct {
+	ID string `json:"id"`
+}
+
+type prPayload struct {
+	SourceRefName string        `json:"sourceRefName,omitempty"`
+	TargetRefName string        `json:"targetRefName,omitempty"`
+	Title         string        `json:"title,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	IsDraft       *bool         `json:"isDraft,omitempty"`
+	Reviewers     []reviewerRef `json:"reviewers,omitempty"`
+}
+
+type completePayload struct {
+	Status                string             `json:"status"`
+	LastMergeSourceCommit *commitRef         `json:"lastMergeSourceCommit,omitempty"`
+	CompletionOptions     *completionOptions `json:"completionOptions,omitempty"`
+}
+
+type completionOptions struct {
+	MergeStrategy string `json:"mergeStrategy"`
+}
+
+type policyResp struct {
+	IsEnabled  bool `json:"isEnabled"`
+	IsBlocking bool `json:"isBlocking"`
+	Settings   struct {
+		MinimumApproverCount int `json:"minimumApproverCount"`
+	} `json:"settings"`
+}
+
+// reviewers returns the names of the individual or team reviewers of the pull request, keyed to their identity IDs.
+func (pr *prResp) reviewers(teams bool) (names []string, ids map[string]string) {
+	ids = make(map[string]string)
+
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.IsContainer != teams {
+			continue
+		}
+
+		name := reviewer.UniqueName
+		if teams {
+			name = teamName(reviewer.DisplayName)
+		}
+
+		names = append(names, name)
+		ids[name] = reviewer.ID
+	}
+
+	return names, ids
+}
+
+// teamName strips the "[Project]\" prefix from the display name of a team reviewer.
+func teamName(displayName string) string {
+	if i := strings.LastIndex(displayName, `\`); i >= 0 {
+		return displayName[i+1:]
+	}
+
+	return displayName
+}
+
+func parsePR(resp *prResp) *scm.PullRequest {
+	pr := &scm.PullRequest{
+		ID:        resp.PullRequestID,
+		Number:    resp.PullRequestID,
+		Draft:     resp.IsDraft,
+		Mergeable: resp.MergeStatus == "succeeded",
+
+		Title:       resp.Title,
+		Description: resp.Description,
+		Branch:      strings.TrimPrefix(resp.SourceRefName, "refs/heads/"),
+		BaseBranch:  strings.TrimPrefix(resp.TargetRefName, "refs/heads/"),
+		Repo:        resp.Repository.Name,
+	}
+
+	pr.Reviewers, _ = resp.reviewers(false)
+	pr.TeamReviewers, _ = resp.reviewers(true)
+
+	return pr
+}
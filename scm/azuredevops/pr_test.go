@@ -0,0 +1,512 @@
+package azuredevops
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+const pullsPath = "/test-org/test-project/_apis/git/repositories/test-repo/pullrequests"
+
+// mockADOPR creates an Azure DevOps pull request API response
+func mockADOPR(id int, branch, title string, reviewers ...map[string]any) map[string]any {
+	return map[string]any{
+		"pullRequestId":         id,
+		"title":                 title,
+		"description":           "PR description",
+		"sourceRefName":         "refs/heads/" + branch,
+		"targetRefName":         "refs/heads/main",
+		"mergeStatus":           "succeeded",
+		"repository":            map[string]any{"id": "repo-id", "name": "test-repo"},
+		"reviewers":             reviewers,
+		"lastMergeSourceCommit": map[string]any{"commitId": "abc123"},
+	}
+}
+
+// mockReviewer creates an Azure DevOps reviewer with the given vote
+func mockReviewer(id, uniqueName string, vote int) map[string]any {
+	return map[string]any{"id": id, "uniqueName": uniqueName, "displayName": uniqueName, "vote": vote}
+}
+
+// mockTeamReviewer creates an Azure DevOps team reviewer
+func mockTeamReviewer(id, name string) map[string]any {
+	return map[string]any{"id": id, "displayName": `[test-project]\` + name, "isContainer": true}
+}
+
+// listOf wraps the values in an Azure DevOps list response
+func listOf(values ...map[string]any) map[string]any {
+	return map[string]any{"count": len(values), "value": values}
+}
+
+// decodeBody decodes the JSON request body into a map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+
+	return body
+}
+
+// mockIdentity creates an Azure DevOps identity with the given account name and mail address
+func mockIdentity(id, account, mail string) map[string]any {
+	return map[string]any{"id": id, "properties": map[string]any{
+		"Account": map[string]any{"$type": "System.String", "$value": account},
+		"Mail":    map[string]any{"$type": "System.String", "$value": mail},
+	}}
+}
+
+// handleIdentities serves identity lookups for the given users (by unique name) and teams (by name). Like the real
+// search, users are matched fuzzily, by any part of their name.
+func handleIdentities(t *testing.T, mux *http.ServeMux, users, teams map[string]string) {
+	mux.HandleFunc("GET /test-org/_apis/identities", func(w http.ResponseWriter, r *http.Request) {
+		var identities []map[string]any
+		for name, id := range users {
+			if strings.Contains(name, r.URL.Query().Get("filterValue")) {
+				identities = append(identities, mockIdentity(id, name, name))
+			}
+		}
+
+		writeJSON(t, w, listOf(identities...))
+	})
+	mux.HandleFunc("GET /test-org/_apis/projects/test-project/teams/{team}", func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := teams[r.PathValue("team")]; ok {
+			writeJSON(t, w, map[string]any{"id": id})
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+}
+
+func TestGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.Path, pullsPath)
+		testhelper.AssertEqual(t, r.URL.Query().Get("searchCriteria.status"), "active")
+		testhelper.AssertEqual(t, r.URL.Query().Get("searchCriteria.sourceRefName"), "refs/heads/feature-branch")
+
+		writeJSON(t, w, listOf(mockADOPR(12, "feature-branch", "Test PR",
+			mockReviewer("id-alice", "alice@example.com", 0),
+			mockTeamReviewer("id-backend", "Backend"),
+		)))
+	}))
+	defer server.Close()
+
+	pr, err := newTestAzureDevOps(t, server).GetPullRequest("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 12)
+	testhelper.AssertEqual(t, pr.Title, "Test PR")
+	testhelper.AssertEqual(t, pr.Description, "PR description")
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, pr.Repo, "test-repo")
+	testhelper.AssertEqual(t, pr.Mergeable, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice@example.com")
+	testhelper.AssertEqual(t, strings.Join(pr.TeamReviewers, ","), "Backend")
+}
+
+func TestGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.Path, pullsPath+"/12")
+
+		writeJSON(t, w, mockADOPR(12, "feature-branch", "Test PR", mockReviewer("id-alice", "alice@example.com", 0)))
+	}))
+	defer server.Close()
+
+	pr, err := newTestAzureDevOps(t, server).GetPullRequestByNumber("test-repo", 12)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 12)
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice@example.com")
+}
+
+func TestGetPullRequestByNumber_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).GetPullRequestByNumber("test-repo", 99)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no pull request #99 found in repository test-repo")
+}
+
+func TestGetPullRequest_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf())
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).GetPullRequest("test-repo", "feature-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no open pull request found for branch feature-branch")
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Query().Get("searchCriteria.status"), "active")
+
+		writeJSON(t, w, listOf(mockADOPR(1, "branch-1", "First PR"), mockADOPR(2, "branch-2", "Second PR")))
+	}))
+	defer server.Close()
+
+	prs, err := newTestAzureDevOps(t, server).ListOpenPullRequests("test-repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, prs, 2)
+	testhelper.AssertEqual(t, prs[1].Branch, "branch-2")
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	var created bool
+
+	mux := http.NewServeMux()
+	handleIdentities(t, mux, map[string]string{"alice@example.com": "id-alice"}, map[string]string{"Backend": "id-backend"})
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf())
+	})
+	mux.HandleFunc("POST "+pullsPath, func(w http.ResponseWriter, r *http.Request) {
+		created = true
+
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["sourceRefName"], "refs/heads/feature-branch")
+		testhelper.AssertEqual(t, body["targetRefName"], "refs/heads/main")
+		testhelper.AssertEqual(t, body["title"], "New PR")
+		testhelper.AssertEqual(t, body["isDraft"], true)
+
+		reviewers, _ := body["reviewers"].([]any)
+		testhelper.AssertLength(t, reviewers, 2)
+		testhelper.AssertEqual(t, reviewers[0].(map[string]any)["id"], "id-alice")
+		testhelper.AssertEqual(t, reviewers[1].(map[string]any)["id"], "id-backend")
+
+		w.WriteHeader(http.StatusCreated)
+		pr := mockADOPR(21, "feature-branch", "New PR", mockReviewer("id-alice", "alice@example.com", 0), mockTeamReviewer("id-backend", "Backend"))
+		pr["isDraft"] = true
+		writeJSON(t, w, pr)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	draft := true
+	pr, err := newTestAzureDevOps(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:         "New PR",
+		Reviewers:     []string{"alice@example.com"},
+		TeamReviewers: []string{"Backend"},
+		Draft:         &draft,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, created, true)
+	testhelper.AssertEqual(t, pr.Number, 21)
+	testhelper.AssertEqual(t, pr.Draft, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice@example.com")
+	testhelper.AssertEqual(t, strings.Join(pr.TeamReviewers, ","), "Backend")
+}
+
+func TestOpenPullRequest_UnknownReviewer(t *testing.T) {
+	mux := http.NewServeMux()
+	handleIdentities(t, mux, nil, nil)
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf())
+	})
+	mux.HandleFunc("POST "+pullsPath, func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no pull request to be created for an unknown reviewer")
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Reviewers: []string{"nobody"}})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no Azure DevOps identity found for reviewer nobody")
+}
+
+func TestLookupUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		reviewer   string
+		identities []map[string]any
+		want       string
+		wantErr    string
+	}{
+		{
+			name:     "exact match among fuzzy results",
+			reviewer: "al@example.com",
+			identities: []map[string]any{
+				mockIdentity("id-alice", "alice", "al@example.com.old"),
+				mockIdentity("id-al", "al", "al@example.com"),
+			},
+			want: "id-al",
+		},
+		{
+			name:       "account name ignoring case",
+			reviewer:   "Alice",
+			identities: []map[string]any{mockIdentity("id-alice", "alice", "alice@example.com")},
+			want:       "id-alice",
+		},
+		{
+			name:       "no exact match",
+			reviewer:   "al",
+			identities: []map[string]any{mockIdentity("id-alice", "alice", "alice@example.com")},
+			wantErr:    "no Azure DevOps identity found for reviewer al",
+		},
+		{
+			name:     "ambiguous name",
+			reviewer: "sam@example.com",
+			identities: []map[string]any{
+				mockIdentity("id-sam", "sam@example.com", "sam@example.com"),
+				mockIdentity("id-samantha", "samantha", "sam@example.com"),
+			},
+			wantErr: "reviewer sam@example.com is ambiguous: it matches 2 Azure DevOps identities",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				testhelper.AssertEqual(t, r.URL.Path, "/test-org/_apis/identities")
+				testhelper.AssertEqual(t, r.URL.Query().Get("filterValue"), tt.reviewer)
+
+				writeJSON(t, w, listOf(tt.identities...))
+			}))
+			defer server.Close()
+
+			id, err := newTestAzureDevOps(t, server).lookupUser(tt.reviewer)
+			if tt.wantErr != "" {
+				testhelper.AssertError(t, err, true)
+				testhelper.AssertContains(t, err.Error(), tt.wantErr)
+
+				return
+			}
+
+			testhelper.AssertError(t, err, false)
+			testhelper.AssertEqual(t, id, tt.want)
+		})
+	}
+}
+
+func TestOpenPullRequest_AlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+
+		writeJSON(t, w, listOf(mockADOPR(1, "feature-branch", "Existing PR")))
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).OpenPullRequest("test-repo", "feature-branch", nil)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "already exists")
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	var removed, added []string
+
+	mux := http.NewServeMux()
+	handleIdentities(t, mux, map[string]string{"erin@example.com": "id-erin"}, nil)
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf(mockADOPR(3, "feature-branch", "Old Title",
+			mockReviewer("id-alice", "alice@example.com", 0),
+			mockReviewer("id-bob", "bob@example.com", 10),
+		)))
+	})
+	mux.HandleFunc("PATCH "+pullsPath+"/3", func(w http.ResponseWriter, r *http.Request) {
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["title"], "New Title")
+		testhelper.AssertEqual(t, body["description"], nil)
+		testhelper.AssertEqual(t, body["isDraft"], false)
+
+		writeJSON(t, w, mockADOPR(3, "feature-branch", "New Title",
+			mockReviewer("id-alice", "alice@example.com", 0),
+			mockReviewer("id-bob", "bob@example.com", 10),
+		))
+	})
+	mux.HandleFunc("DELETE "+pullsPath+"/3/reviewers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		removed = append(removed, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT "+pullsPath+"/3/reviewers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		added = append(added, r.PathValue("id"))
+		testhelper.AssertEqual(t, decodeBody(t, r)["id"], r.PathValue("id"))
+
+		writeJSON(t, w, mockReviewer(r.PathValue("id"), "erin@example.com", 0))
+	})
+	mux.HandleFunc("GET "+pullsPath+"/3", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, mockADOPR(3, "feature-branch", "New Title",
+			mockReviewer("id-bob", "bob@example.com", 10),
+			mockReviewer("id-erin", "erin@example.com", 0),
+		))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	draft := false
+	pr, err := newTestAzureDevOps(t, server).UpdatePullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:          "New Title",
+		Reviewers:      []string{"bob@example.com", "erin@example.com"},
+		ResetReviewers: true,
+		Draft:          &draft,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, strings.Join(removed, ","), "id-alice")
+	testhelper.AssertEqual(t, strings.Join(added, ","), "id-erin")
+	testhelper.AssertEqual(t, pr.Title, "New Title")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "bob@example.com,erin@example.com")
+}
+
+func TestMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		defaultMethod string
+		wantStrategy  any
+		wantErr       bool
+	}{
+		{name: "no method", wantStrategy: nil},
+		{name: "configured default", defaultMethod: "squash", wantStrategy: "squash"},
+		{name: "merge", method: "merge", wantStrategy: "noFastForward"},
+		{name: "rebase", method: "rebase", defaultMethod: "squash", wantStrategy: "rebase"},
+		{name: "unsupported", method: "fast-forward", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(t, w, listOf(mockADOPR(5, "feature-branch", "PR to Merge")))
+			})
+			mux.HandleFunc("PATCH "+pullsPath+"/5", func(w http.ResponseWriter, r *http.Request) {
+				body = decodeBody(t, r)
+
+				pr := mockADOPR(5, "feature-branch", "PR to Merge")
+				pr["status"] = "completed"
+				writeJSON(t, w, pr)
+			})
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			a := newTestAzureDevOps(t, server)
+			config.Viper(a.ctx).Set(config.DefaultMergeMethod, tt.defaultMethod)
+
+			pr, err := a.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{Method: tt.method, CheckMergeable: true})
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if tt.wantErr {
+				return
+			}
+
+			testhelper.AssertEqual(t, pr.Number, 5)
+			testhelper.AssertEqual(t, body["status"], "completed")
+			testhelper.AssertEqual(t, body["lastMergeSourceCommit"].(map[string]any)["commitId"], "abc123")
+
+			var strategy any
+			if options, ok := body["completionOptions"].(map[string]any); ok {
+				strategy = options["mergeStrategy"]
+			}
+
+			testhelper.AssertEqual(t, strategy, tt.wantStrategy)
+		})
+	}
+}
+
+func TestMergePullRequest_NotMergeable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+
+		pr := mockADOPR(5, "feature-branch", "Conflicting PR")
+		pr["mergeStatus"] = "conflicts"
+		writeJSON(t, w, listOf(pr))
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "is not mergeable: conflicts")
+}
+
+func TestGetReviewStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf(mockADOPR(4, "feature-branch", "Reviewed PR",
+			mockReviewer("id-alice", "alice@example.com", 10),
+			mockReviewer("id-bob", "bob@example.com", 5),
+			mockReviewer("id-carol", "carol@example.com", -10),
+			mockReviewer("id-dave", "dave@example.com", 0),
+			mockTeamReviewer("id-backend", "Backend"),
+		)))
+	})
+	mux.HandleFunc("GET /test-org/test-project/_apis/policy/configurations", func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Query().Get("repositoryId"), "repo-id")
+		testhelper.AssertEqual(t, r.URL.Query().Get("refName"), "refs/heads/main")
+
+		writeJSON(t, w, listOf(
+			map[string]any{"isEnabled": true, "isBlocking": true, "settings": map[string]any{"minimumApproverCount": 2}},
+			map[string]any{"isEnabled": true, "isBlocking": false, "settings": map[string]any{"minimumApproverCount": 4}},
+		))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	status, err := newTestAzureDevOps(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 2)
+	testhelper.AssertEqual(t, strings.Join(status.Approvers, ","), "alice@example.com,bob@example.com")
+	testhelper.AssertEqual(t, strings.Join(status.ChangesRequested, ","), "carol@example.com")
+	testhelper.AssertEqual(t, status.Approved(), false)
+}
+
+func TestGetReviewStatus_NoPolicy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf(mockADOPR(4, "feature-branch", "Reviewed PR", mockReviewer("id-alice", "alice@example.com", 10))))
+	})
+	mux.HandleFunc("GET /test-org/test-project/_apis/policy/configurations", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, listOf())
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	status, err := newTestAzureDevOps(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 1)
+	testhelper.AssertEqual(t, status.Approved(), true)
+}
@@ -0,0 +1,272 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+const (
+	apiVersion = "7.1"
+
+	// pageSize is the number of items requested per page from list endpoints.
+	pageSize = 100
+
+	// identities of Azure DevOps Services are served from a separate host
+	servicesHost = "dev.azure.com"
+	identityHost = "vssps.dev.azure.com"
+)
+
+var caps = &scm.Capabilities{
+	TeamReviewers:  true,
+	ResetReviewers: true,
+	Draft:          true,
+	Assignees:      false,
+
+	MergeMethods:   []string{"merge", "squash", "rebase"},
+	CheckMergeable: true,
+}
+
+var _ scm.Provider = new(AzureDevOps)
+
+func init() {
+	// Register the Azure DevOps provider factory
+	scm.Register("azuredevops", New)
+}
+
+// New creates a new Azure DevOps SCM provider instance.
+func New(ctx context.Context, project string) scm.Provider {
+	viper := config.Viper(ctx)
+
+	base := strings.TrimSuffix(strings.TrimSpace(viper.GetString(config.AzureDevOpsBaseURL)), "/")
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		panic(fmt.Sprintf("azuredevops: invalid base URL %q: %v", base, err))
+	}
+
+	// Azure DevOps Server serves identities from the collection itself
+	identityURL := baseURL
+	if baseURL.Host == servicesHost {
+		identityURL = &url.URL{Scheme: baseURL.Scheme, Host: identityHost}
+	}
+
+	return &AzureDevOps{
+		client:       http.DefaultClient,
+		baseURL:      baseURL,
+		identityURL:  identityURL,
+		organization: viper.GetString(config.AzureDevOpsOrganization),
+		project:      project,
+		ctx:          ctx,
+	}
+}
+
+// AzureDevOps represents an SCM provider for the Azure DevOps Git REST API.
+type AzureDevOps struct {
+	client       *http.Client
+	baseURL      *url.URL
+	identityURL  *url.URL
+	organization string
+	project      string
+	ctx          context.Context
+}
+
+// CheckCapabilities validates that the provided PR options are supported by Azure DevOps.
+func (a *AzureDevOps) CheckCapabilities(opts *scm.PROptions) error {
+	return scm.ValidatePROptions(caps, opts)
+}
+
+// CurrentUser returns the login of the authenticated user.
+func (a *AzureDevOps) CurrentUser() (string, error) {
+	queryParams := url.Values{}
+	queryParams.Set("api-version", apiVersion+"-preview")
+
+	resp, err := get[connectionDataResp](a, a.url(a.baseURL, queryParams, a.organization, "_apis", "connectionData"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	if account := resp.AuthenticatedUser.Properties.Account.Value; account != "" {
+		return account, nil
+	}
+
+	return resp.AuthenticatedUser.ProviderDisplayName, nil
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of Azure DevOps tokens aren't inspected.
+func (a *AzureDevOps) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
+// constructs the URL for the Azure DevOps API endpoint at the given path beneath the base URL.
+func (a *AzureDevOps) url(base *url.URL, queryParams url.Values, path ...string) string {
+	apiURL := base.JoinPath(path...)
+
+	if queryParams == nil {
+		queryParams = url.Values{}
+	}
+
+	if queryParams.Get("api-version") == "" {
+		queryParams.Set("api-version", apiVersion)
+	}
+
+	apiURL.RawQuery = queryParams.Encode()
+
+	return apiURL.String()
+}
+
+// constructs the URL for an endpoint within the provider's project.
+func (a *AzureDevOps) projectURL(queryParams url.Values, path ...string) string {
+	return a.url(a.baseURL, queryParams, append([]string{a.organization, a.project, "_apis"}, path...)...)
+}
+
+// constructs the URL for an endpoint of the given repository within the provider's project.
+func (a *AzureDevOps) repoURL(repo string, queryParams url.Values, path ...string) string {
+	return a.projectURL(queryParams, append([]string{"git", "repositories", repo}, path...)...)
+}
+
+// apiError is returned when the Azure DevOps API responds with an error status.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.StatusCode, e.Body)
+}
+
+// listResp is the envelope of Azure DevOps list responses.
+type listResp[T any] struct {
+	Count int `json:"count"`
+	Value []T `json:"value"`
+}
+
+// convenience function to perform a GET request and unmarshal the response into the specified type.
+func get[T any](a *AzureDevOps, path string) (*T, error) {
+	req, err := http.NewRequestWithContext(a.ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return do[T](a, req)
+}
+
+// convenience function to GET every page of a list endpoint and combine the results.
+func list[T any](a *AzureDevOps, queryParams url.Values, urlFunc func(url.Values) string) ([]T, error) {
+	if queryParams == nil {
+		queryParams = url.Values{}
+	}
+
+	queryParams.Set("$top", strconv.Itoa(pageSize))
+
+	output := make([]T, 0)
+	for skip := 0; ; skip += pageSize {
+		queryParams.Set("$skip", strconv.Itoa(skip))
+
+		resp, err := get[listResp[T]](a, urlFunc(queryParams))
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, resp.Value...)
+
+		// a short page is the last one
+		if len(resp.Value) < pageSize {
+			break
+		}
+	}
+
+	return output, nil
+}
+
+// convenience function to send a JSON payload with the given method and unmarshal the response into the specified type.
+func send[T any](a *AzureDevOps, method, path string, payload any) (*T, error) {
+	var body io.Reader
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+		}
+
+		body = strings.NewReader(string(data))
+	}
+
+	req, err := http.NewRequestWithContext(a.ctx, method, path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return do[T](a, req)
+}
+
+// convenience function to perform an HTTP request and unmarshal the response into the specified type.
+// An empty response body leaves the result at its zero value.
+func do[T any](a *AzureDevOps, req *http.Request) (*T, error) {
+	token, err := scm.AuthToken(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// personal access tokens are sent as the password of basic authentication with an empty username
+	if token != "" {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(":"+token)))
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := parseError(resp); err != nil {
+		return nil, err
+	}
+
+	var result T
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func parseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	output, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error %d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	return &apiError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(output))}
+}
+
+type connectionDataResp struct {
+	AuthenticatedUser struct {
+		ProviderDisplayName string `json:"providerDisplayName"`
+		Properties          struct {
+			Account struct {
+				Value string `json:"$value"`
+			} `json:"Account"`
+		} `json:"properties"`
+	} `json:"authenticatedUser"`
+}
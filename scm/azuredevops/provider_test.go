@@ -0,0 +1,144 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func loadFixture(t *testing.T) context.Context {
+	return testhelper.LoadFixture(t, "../../config")
+}
+
+// newTestAzureDevOps creates a provider for the "test-project" project of the "test-org" organization
+// which sends all of its requests (including identity lookups) to the test server.
+func newTestAzureDevOps(t *testing.T, server *httptest.Server) *AzureDevOps {
+	t.Helper()
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.AuthToken, "test-token")
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	// start each test with no cached identities
+	identityMu.Lock()
+	identityCache = make(map[string]string)
+	identityMu.Unlock()
+
+	return &AzureDevOps{
+		client:       server.Client(),
+		baseURL:      baseURL,
+		identityURL:  baseURL,
+		organization: "test-org",
+		project:      "test-project",
+		ctx:          ctx,
+	}
+}
+
+// writeJSON encodes the value as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name         string
+		baseURL      string
+		wantURL      string
+		wantIdentity string
+	}{
+		{
+			name:         "services",
+			baseURL:      "https://dev.azure.com",
+			wantURL:      "https://dev.azure.com/my-org/my-project/_apis/git/repositories?api-version=7.1",
+			wantIdentity: "https://vssps.dev.azure.com/my-org/_apis/identities?api-version=7.1",
+		},
+		{
+			name:         "server collection",
+			baseURL:      "https://ado.example.com/tfs/",
+			wantURL:      "https://ado.example.com/tfs/my-org/my-project/_apis/git/repositories?api-version=7.1",
+			wantIdentity: "https://ado.example.com/tfs/my-org/_apis/identities?api-version=7.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.AzureDevOpsBaseURL, tt.baseURL)
+			config.Viper(ctx).Set(config.AzureDevOpsOrganization, "my-org")
+
+			provider, ok := New(ctx, "my-project").(*AzureDevOps)
+			if !ok {
+				t.Fatalf("Expected *AzureDevOps provider, got %T", provider)
+			}
+
+			testhelper.AssertEqual(t, provider.projectURL(nil, "git", "repositories"), tt.wantURL)
+			testhelper.AssertEqual(t, provider.url(provider.identityURL, nil, "my-org", "_apis", "identities"), tt.wantIdentity)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider := scm.Get(loadFixture(t), "azuredevops", "test-project")
+
+	if _, ok := provider.(*AzureDevOps); !ok {
+		t.Errorf("Expected *AzureDevOps provider, got %T", provider)
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	a := &AzureDevOps{}
+
+	testhelper.AssertError(t, a.CheckCapabilities(&scm.PROptions{TeamReviewers: []string{"team"}, Draft: new(bool)}), false)
+	testhelper.AssertError(t, a.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "rebase"}}), false)
+	testhelper.AssertError(t, a.CheckCapabilities(&scm.PROptions{Assignees: []string{"alice"}}), true)
+}
+
+func TestCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/test-org/_apis/connectionData")
+		testhelper.AssertEqual(t, r.Header.Get("Authorization"), "Basic "+base64.StdEncoding.EncodeToString([]byte(":test-token")))
+
+		writeJSON(t, w, map[string]any{
+			"authenticatedUser": map[string]any{
+				"providerDisplayName": "Alice",
+				"properties":          map[string]any{"Account": map[string]any{"$value": "alice@example.com"}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	login, err := newTestAzureDevOps(t, server).CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, login, "alice@example.com")
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("unauthorized"))
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).CurrentUser()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"error 401", "unauthorized"})
+}
@@ -0,0 +1,58 @@
+package azuredevops
+
+import (
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+type repoResp struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	DefaultBranch string      `json:"defaultBranch"`
+	IsDisabled    bool        `json:"isDisabled"`
+	RemoteURL     string      `json:"remoteUrl"`
+	SSHURL        string      `json:"sshUrl"`
+	WebURL        string      `json:"webUrl"`
+	Project       projectResp `json:"project"`
+}
+
+type projectResp struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+// ListRepositories lists all repositories in the specified project.
+func (a *AzureDevOps) ListRepositories() ([]*scm.Repository, error) {
+	// the repositories endpoint is not paginated
+	resp, err := get[listResp[repoResp]](a, a.projectURL(nil, "git", "repositories"))
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.Repository, len(resp.Value))
+	for i, repo := range resp.Value {
+		// default branches are reported as full ref names
+		defaultBranch := strings.TrimPrefix(repo.DefaultBranch, "refs/heads/")
+		if defaultBranch == "" {
+			// fall back on configured default branch if it isn't set for the repo (e.g. an empty repository)
+			defaultBranch = config.Viper(a.ctx).GetString(config.DefaultBranch)
+		}
+
+		output[i] = &scm.Repository{
+			Name:   repo.Name,
+			Public: repo.Project.Visibility == "public",
+			// Azure DevOps has no archived state, but disabled repositories can no longer be used
+			Archived:      repo.IsDisabled,
+			Project:       a.project,
+			DefaultBranch: defaultBranch,
+			CloneURL:      repo.RemoteURL,
+			SSHURL:        repo.SSHURL,
+			WebURL:        repo.WebURL,
+		}
+	}
+
+	return output, nil
+}
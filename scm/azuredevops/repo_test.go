@@ -0,0 +1,66 @@
+package azuredevops
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/test-org/test-project/_apis/git/repositories")
+
+		writeJSON(t, w, map[string]any{
+			"count": 2,
+			"value": []map[string]any{
+				{
+					"id":            "repo-id-1",
+					"name":          "api",
+					"defaultBranch": "refs/heads/develop",
+					"remoteUrl":     "https://dev.azure.com/test-org/test-project/_git/api",
+					"sshUrl":        "git@ssh.dev.azure.com:v3/test-org/test-project/api",
+					"webUrl":        "https://dev.azure.com/test-org/test-project/_git/api",
+					"project":       map[string]any{"name": "test-project", "visibility": "public"},
+				},
+				{
+					"id":         "repo-id-2",
+					"name":       "legacy",
+					"isDisabled": true,
+					"project":    map[string]any{"name": "test-project", "visibility": "private"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	repos, err := newTestAzureDevOps(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, 2)
+
+	testhelper.AssertEqual(t, repos[0].Name, "api")
+	testhelper.AssertEqual(t, repos[0].Project, "test-project")
+	testhelper.AssertEqual(t, repos[0].Public, true)
+	testhelper.AssertEqual(t, repos[0].Archived, false)
+	testhelper.AssertEqual(t, repos[0].DefaultBranch, "develop")
+	testhelper.AssertEqual(t, repos[0].CloneURL, "https://dev.azure.com/test-org/test-project/_git/api")
+	testhelper.AssertEqual(t, repos[0].SSHURL, "git@ssh.dev.azure.com:v3/test-org/test-project/api")
+
+	testhelper.AssertEqual(t, repos[1].Public, false)
+	testhelper.AssertEqual(t, repos[1].Archived, true)
+	testhelper.AssertEqual(t, repos[1].DefaultBranch, "main") // configured fallback
+}
+
+func TestListRepositoriesAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestAzureDevOps(t, server).ListRepositories()
+	testhelper.AssertError(t, err, true)
+}
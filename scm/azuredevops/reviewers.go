@@ -0,0 +1,165 @@
+package azuredevops
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// identityCache holds the identity IDs of resolved reviewers for the duration of a run,
+// keyed by organization, project, kind and name.
+var (
+	identityMu    sync.Mutex
+	identityCache = make(map[string]string)
+)
+
+// applyReviewers adds the named individual or team reviewers to the given pull request. If reset is set,
+// reviewers of the same kind which are not named are removed from the pull request.
+func (a *AzureDevOps) applyReviewers(repo string, pr *prResp, names []string, teams, reset bool) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	current, currentIDs := pr.reviewers(teams)
+	toAdd, toRemove := scm.DiffReviewers(current, names, reset)
+
+	for _, name := range toRemove {
+		if _, err := send[any](a, http.MethodDelete, a.prURL(repo, pr.PullRequestID, "reviewers", currentIDs[name]), nil); err != nil {
+			return fmt.Errorf("failed to remove reviewer %s: %w", name, err)
+		}
+	}
+
+	ids, err := a.reviewerIDs(toAdd, teams)
+	if err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if _, err := send[reviewerResp](a, http.MethodPut, a.prURL(repo, pr.PullRequestID, "reviewers", id), &reviewerRef{ID: id}); err != nil {
+			return fmt.Errorf("failed to request reviewer %s: %w", toAdd[i], err)
+		}
+	}
+
+	return nil
+}
+
+// reviewerIDs resolves the named individual or team reviewers to their Azure DevOps identity IDs.
+func (a *AzureDevOps) reviewerIDs(names []string, teams bool) ([]string, error) {
+	ids := make([]string, len(names))
+
+	for i, name := range names {
+		id, err := a.identityID(name, teams)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// identityID returns the identity ID of the named user or team, which is looked up at most once per run.
+func (a *AzureDevOps) identityID(name string, team bool) (string, error) {
+	kind := "user"
+	if team {
+		kind = "team"
+	}
+
+	key := strings.Join([]string{a.organization, a.project, kind, strings.ToLower(name)}, "\x00")
+
+	identityMu.Lock()
+	id, ok := identityCache[key]
+	identityMu.Unlock()
+
+	if ok {
+		return id, nil
+	}
+
+	var err error
+	if team {
+		id, err = a.lookupTeam(name)
+	} else {
+		id, err = a.lookupUser(name)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	identityMu.Lock()
+	identityCache[key] = id
+	identityMu.Unlock()
+
+	return id, nil
+}
+
+// lookupUser searches the organization's identities for the named user (e.g. by email or account name). The search
+// is fuzzy, so only identities whose unique name or mail address is exactly the given name are accepted, and a name
+// matching several identities is an error rather than picking one of them.
+func (a *AzureDevOps) lookupUser(name string) (string, error) {
+	queryParams := url.Values{}
+	queryParams.Set("searchFilter", "General")
+	queryParams.Set("filterValue", name)
+	queryParams.Set("queryMembership", "None")
+
+	resp, err := get[listResp[identityResp]](a, a.url(a.identityURL, queryParams, a.organization, "_apis", "identities"))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up reviewer %s: %w", name, err)
+	}
+
+	var ids []string
+	for _, identity := range resp.Value {
+		if identity.matches(name) && !slices.Contains(ids, identity.ID) {
+			ids = append(ids, identity.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no Azure DevOps identity found for reviewer %s", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("reviewer %s is ambiguous: it matches %d Azure DevOps identities", name, len(ids))
+	}
+}
+
+// lookupTeam retrieves the named team of the provider's project.
+func (a *AzureDevOps) lookupTeam(name string) (string, error) {
+	resp, err := get[identityResp](a, a.url(a.baseURL, nil, a.organization, "_apis", "projects", a.project, "teams", name))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up team reviewer %s: %w", name, err)
+	}
+
+	return resp.ID, nil
+}
+
+type identityResp struct {
+	ID         string                      `json:"id"`
+	Properties map[string]identityProperty `json:"properties,omitempty"`
+}
+
+type identityProperty struct {
+	Value string `json:"$value"`
+}
+
+// matches reports whether the unique name (the account name, optionally qualified by its domain) or the mail
+// address of the identity is the given name, ignoring case.
+func (i identityResp) matches(name string) bool {
+	account, mail := i.Properties["Account"].Value, i.Properties["Mail"].Value
+
+	candidates := []string{account, mail}
+	if domain := i.Properties["Domain"].Value; domain != "" {
+		candidates = append(candidates, domain+`\`+account)
+	}
+
+	return slices.ContainsFunc(candidates, func(candidate string) bool {
+		return candidate != "" && strings.EqualFold(candidate, name)
+	})
+}
@@ -0,0 +1,310 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+const (
+	// cloudHost is the git host of Bitbucket Cloud, which is served by the v2 API rather than the v1 API
+	// of Bitbucket Server and Data Center.
+	cloudHost = "bitbucket.org"
+
+	// cloudAPI is the root of the Bitbucket Cloud v2 API.
+	cloudAPI = "https://api.bitbucket.org/2.0"
+
+	// cloudPageSize is the number of items requested per page from list endpoints (the maximum for pull requests).
+	cloudPageSize = 50
+)
+
+var cloudCaps = &scm.Capabilities{
+	TeamReviewers:  false,
+	ResetReviewers: true,
+	Draft:          true,
+	Assignees:      false,
+
+	MergeMethods:   []string{"merge", "squash"},
+	CheckMergeable: false,
+}
+
+// memberCache holds the UUIDs of workspace members for the duration of a run, keyed by API URL and workspace,
+// and then by lowercase nickname and account ID.
+var (
+	memberMu    sync.Mutex
+	memberCache = make(map[string]map[string]string)
+)
+
+var _ scm.Provider = new(Cloud)
+
+// newCloud creates a new Bitbucket Cloud SCM provider instance for the given workspace.
+func newCloud(ctx context.Context, workspace string) *Cloud {
+	baseURL, err := url.Parse(cloudAPI)
+	if err != nil {
+		panic(fmt.Sprintf("bitbucket: invalid API URL %q: %v", cloudAPI, err))
+	}
+
+	return &Cloud{
+		client:    http.DefaultClient,
+		baseURL:   baseURL,
+		workspace: workspace,
+		ctx:       ctx,
+	}
+}
+
+// Cloud represents an SCM provider for the Bitbucket Cloud v2 API, where each project is a workspace.
+type Cloud struct {
+	client    *http.Client
+	baseURL   *url.URL
+	workspace string
+	ctx       context.Context
+}
+
+// CheckCapabilities validates that the provided PR options are supported by Bitbucket Cloud.
+func (c *Cloud) CheckCapabilities(opts *scm.PROptions) error {
+	return scm.ValidatePROptions(cloudCaps, opts)
+}
+
+// CurrentUser returns the nickname of the authenticated user.
+func (c *Cloud) CurrentUser() (string, error) {
+	resp, err := cloudGet[cloudUser](c, c.url(nil, "user"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return resp.Name(), nil
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of Bitbucket tokens aren't inspected.
+func (c *Cloud) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
+// constructs the URL for the Bitbucket Cloud API endpoint at the given path.
+func (c *Cloud) url(queryParams url.Values, path ...string) string {
+	apiURL := c.baseURL.JoinPath(path...)
+
+	// Add query parameters if provided
+	if queryParams != nil {
+		apiURL.RawQuery = queryParams.Encode()
+	}
+
+	return apiURL.String()
+}
+
+// constructs the URL for an endpoint of the given repository within the provider's workspace.
+func (c *Cloud) repoURL(repo string, queryParams url.Values, path ...string) string {
+	return c.url(queryParams, append([]string{"repositories", c.workspace, repo}, path...)...)
+}
+
+// constructs the URL for an endpoint of the given pull request.
+func (c *Cloud) prURL(repo string, id int, path ...string) string {
+	return c.repoURL(repo, nil, append([]string{"pullrequests", strconv.Itoa(id)}, path...)...)
+}
+
+// reviewers resolves the given reviewers to the user objects expected by the pull request API. Reviewers may be
+// given by nickname or account ID of a workspace member, or directly by UUID (e.g. "{a1b2c3...}").
+func (c *Cloud) reviewers(names []string) ([]cloudUser, error) {
+	users := make([]cloudUser, len(names))
+
+	for i, name := range names {
+		uuid, err := c.userUUID(name)
+		if err != nil {
+			return nil, err
+		}
+
+		users[i] = cloudUser{UUID: uuid}
+	}
+
+	return users, nil
+}
+
+// userUUID returns the UUID of the workspace member with the given nickname or account ID. The members of the
+// workspace are listed at most once per run, since Bitbucket Cloud can't look up users by name.
+func (c *Cloud) userUUID(name string) (string, error) {
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") {
+		return name, nil
+	}
+
+	key := c.url(nil) + "\x00" + c.workspace
+
+	// hold the lock while listing the members so that concurrent callers share a single listing
+	memberMu.Lock()
+	defer memberMu.Unlock()
+
+	members, ok := memberCache[key]
+	if !ok {
+		resp, err := cloudList[cloudMember](c, c.url(nil, "workspaces", c.workspace, "members"))
+		if err != nil {
+			return "", fmt.Errorf("failed to list members of workspace %s: %w", c.workspace, err)
+		}
+
+		members = make(map[string]string, 2*len(resp))
+		for _, member := range resp {
+			members[strings.ToLower(member.User.Nickname)] = member.User.UUID
+			members[strings.ToLower(member.User.AccountID)] = member.User.UUID
+		}
+
+		memberCache[key] = members
+	}
+
+	uuid, ok := members[strings.ToLower(name)]
+	if !ok || name == "" {
+		return "", fmt.Errorf("no Bitbucket user found with nickname %s in workspace %s", name, c.workspace)
+	}
+
+	return uuid, nil
+}
+
+// convenience function to perform a GET request and unmarshal the response into the specified type.
+func cloudGet[T any](c *Cloud, path string) (*T, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return cloudDo[T](c, req)
+}
+
+// convenience function to GET every page of a list endpoint and combine the results, following the
+// next page links of Bitbucket's paginated responses.
+func cloudList[T any](c *Cloud, path string) ([]T, error) {
+	next, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	queryParams := next.Query()
+	queryParams.Set("pagelen", strconv.Itoa(cloudPageSize))
+	next.RawQuery = queryParams.Encode()
+
+	output := make([]T, 0)
+	for path := next.String(); path != ""; {
+		resp, err := cloudGet[cloudPage[T]](c, path)
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, resp.Values...)
+		path = resp.Next
+	}
+
+	return output, nil
+}
+
+// convenience function to send a JSON payload with the given method and unmarshal the response into the specified type.
+func cloudSend[T any](c *Cloud, method, path string, payload any) (*T, error) {
+	req, err := c.newRequest(method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudDo[T](c, req)
+}
+
+// convenience function to perform an HTTP request and unmarshal the response into the specified type.
+func cloudDo[T any](c *Cloud, req *http.Request) (*T, error) {
+	resp, err := c.execute(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return decode[T](resp)
+}
+
+// decode unmarshals the body of the response into the specified type.
+func decode[T any](resp *http.Response) (*T, error) {
+	var result T
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// newRequest creates a request which sends the given payload as JSON.
+func (c *Cloud) newRequest(method, path string, payload any) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, path, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return req, nil
+}
+
+// execute authenticates and performs the HTTP request, returning an apiError for error responses.
+// The caller is responsible for closing the body of a successful response.
+func (c *Cloud) execute(req *http.Request) (*http.Response, error) {
+	token, err := scm.AuthToken(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if err := parseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// isNotFound reports whether the error is a 404 response from the Bitbucket API.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// cloudPage is a page of a paginated Bitbucket Cloud list response.
+type cloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+type cloudUser struct {
+	UUID        string `json:"uuid"`
+	DisplayName string `json:"display_name,omitempty"`
+	Nickname    string `json:"nickname,omitempty"`
+	AccountID   string `json:"account_id,omitempty"`
+}
+
+// Name returns the user's nickname, falling back on their display name if it isn't set.
+func (u *cloudUser) Name() string {
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+
+	return u.DisplayName
+}
+
+type cloudMember struct {
+	User cloudUser `json:"user"`
+}
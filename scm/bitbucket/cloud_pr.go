@@ -0,0 +1,431 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// mergeStrategies maps the supported merge methods to Bitbucket Cloud merge strategies.
+var mergeStrategies = map[string]string{
+	"merge":  "merge_commit",
+	"squash": "squash",
+}
+
+// GetPullRequest retrieves an open pull request by repository name and source branch.
+func (c *Cloud) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	resp, err := c.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCloudPR(repo, resp), nil
+}
+
+// GetPullRequestByNumber retrieves a pull request by repository name and number, regardless of its state.
+func (c *Cloud) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := cloudGet[cloudPRResp](c, c.prURL(repo, number))
+	if isNotFound(err) {
+		return nil, fmt.Errorf("no pull request #%d found in repository %s", number, repo)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d for %s: %w", number, repo, err)
+	}
+
+	return parseCloudPR(repo, resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request. Bitbucket Cloud reports an
+// abbreviated hash for the source of a pull request, so the full hash is looked up from its commit.
+func (c *Cloud) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	pr, err := c.getPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+
+	if pr.Source.Commit == nil || pr.Source.Commit.Hash == "" {
+		return "", nil
+	}
+
+	commit, err := cloudGet[cloudCommit](c, c.repoURL(repo, nil, "commit", pr.Source.Commit.Hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to get head commit of pull request #%d: %w", pr.ID, err)
+	}
+
+	return commit.Hash, nil
+}
+
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (c *Cloud) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "OPEN")
+
+	prs, err := cloudList[*cloudPRResp](c, c.repoURL(repo, queryParams, "pullrequests"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	output := make([]*scm.PullRequest, len(prs))
+	for i, pr := range prs {
+		output[i] = parseCloudPR(repo, pr)
+	}
+
+	return output, nil
+}
+
+// OpenPullRequest opens a new pull request in the specified repository.
+func (c *Cloud) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	// check for existing PR first (reads are less restrictive than a failed write)
+	if existing, err := c.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parseCloudPR(repo, existing))
+	}
+
+	// default PR title is branch name
+	title := opts.Title
+	if title == "" {
+		title = branch
+	}
+
+	// use provided base branch or fall back to configured default
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = config.Viper(c.ctx).GetString(config.DefaultBranch)
+	}
+
+	reviewers, err := c.reviewers(opts.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &cloudPRPayload{
+		Title:       title,
+		Description: opts.Description,
+		Source:      newCloudRef(branch),
+		Destination: newCloudRef(baseBranch),
+		Reviewers:   reviewers,
+		Draft:       opts.Draft,
+	}
+
+	pr, err := cloudSend[cloudPRResp](c, http.MethodPost, c.repoURL(repo, nil, "pullrequests"), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	return parseCloudPR(repo, pr), nil
+}
+
+// UpdatePullRequest updates an existing pull request.
+func (c *Cloud) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	pr, err := c.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, changed, err := c.processChanges(pr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if pr, err = cloudSend[cloudPRResp](c, http.MethodPut, c.prURL(repo, pr.ID), payload); err != nil {
+			return nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+	}
+
+	return parseCloudPR(repo, pr), nil
+}
+
+// MergePullRequest merges an existing pull request.
+func (c *Cloud) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PRMergeOptions{} // default options
+	}
+
+	if opts.DryRun {
+		return nil, fmt.Errorf("checking PR mergeability is not currently supported by the Bitbucket provider")
+	}
+
+	pr, err := c.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// if no merge method specified, use the default from config (if set)
+	method := opts.Method
+	if method == "" {
+		method = config.Viper(c.ctx).GetString(config.DefaultMergeMethod)
+	}
+
+	strategy, ok := mergeStrategies[method]
+	if !ok {
+		return nil, fmt.Errorf("merge method %q is not supported by the Bitbucket provider", method)
+	}
+
+	req, err := c.newRequest(http.MethodPost, c.prURL(repo, pr.ID, "merge"), &cloudMergePayload{MergeStrategy: strategy})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.execute(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// long-running merges are accepted and completed asynchronously, without reporting the merged pull request
+	if resp.StatusCode == http.StatusAccepted {
+		return parseCloudPR(repo, pr), nil
+	}
+
+	merged, err := decode[cloudPRResp](resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCloudPR(repo, merged), nil
+}
+
+// GetReviewStatus retrieves the approval state of a pull request, requiring the number of approvals set by the
+// branch restrictions of its destination branch (and at least one).
+func (c *Cloud) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	pr, err := c.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := c.requiredApprovals(repo, pr.Destination.Branch.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &scm.ReviewStatus{RequiredApprovals: required}
+	for _, participant := range pr.Participants {
+		switch {
+		case participant.State == "changes_requested":
+			status.ChangesRequested = append(status.ChangesRequested, participant.User.Name())
+		case participant.Approved:
+			status.Approvers = append(status.Approvers, participant.User.Name())
+		}
+	}
+
+	return status, nil
+}
+
+func (c *Cloud) getPullRequest(repo, branch string) (*cloudPRResp, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "OPEN")
+	queryParams.Set("q", fmt.Sprintf("source.branch.name = %s", strconv.Quote(branch)))
+
+	resp, err := cloudGet[cloudPage[*cloudPRResp]](c, c.repoURL(repo, queryParams, "pullrequests"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull requests for %s/%s: %w", repo, branch, err)
+	}
+
+	if len(resp.Values) == 0 {
+		return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+	}
+
+	return resp.Values[0], nil
+}
+
+// requiredApprovals returns the number of approvals required to merge into the given branch, which is the
+// largest required by any matching branch restriction (and at least one).
+func (c *Cloud) requiredApprovals(repo, branch string) (int, error) {
+	queryParams := url.Values{}
+	queryParams.Set("kind", "require_approvals_to_merge")
+
+	restrictions, err := cloudList[cloudRestriction](c, c.repoURL(repo, queryParams, "branch-restrictions"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list branch restrictions: %w", err)
+	}
+
+	required := 1
+	for _, restriction := range restrictions {
+		// restrictions matched by the branching model (rather than a glob) are ignored
+		if matched, _ := path.Match(restriction.Pattern, branch); matched && restriction.BranchMatchKind == "glob" {
+			required = max(required, restriction.Value)
+		}
+	}
+
+	return required, nil
+}
+
+// processChanges builds the payload for updating the pull request, reporting whether anything changed.
+func (c *Cloud) processChanges(pr *cloudPRResp, opts *scm.PROptions) (payload *cloudPRPayload, changed bool, err error) {
+	// the title is required by the update endpoint, so the current title and description are always sent
+	payload = &cloudPRPayload{Title: pr.Title, Description: pr.Description}
+
+	if opts.Title != "" && opts.Title != pr.Title {
+		payload.Title, changed = opts.Title, true
+	}
+
+	if opts.Description != "" && opts.Description != pr.Description {
+		payload.Description, changed = opts.Description, true
+	}
+
+	if opts.Draft != nil && *opts.Draft != pr.Draft {
+		payload.Draft, changed = opts.Draft, true
+	}
+
+	if len(opts.Reviewers) > 0 || opts.ResetReviewers {
+		added, err := c.reviewers(opts.Reviewers)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// the reviewers of the pull request are replaced with those sent, so keep the current ones unless resetting
+		reviewers := added
+		if !opts.ResetReviewers {
+			reviewers = mergeReviewers(pr.Reviewers, added)
+		}
+
+		if !sameReviewers(pr.Reviewers, reviewers) {
+			payload.Reviewers, changed = reviewers, true
+		}
+	}
+
+	return payload, changed, nil
+}
+
+// mergeReviewers appends the added reviewers to the current ones, omitting those already present.
+func mergeReviewers(current, added []cloudUser) []cloudUser {
+	output := make([]cloudUser, 0, len(current)+len(added))
+	output = append(output, current...)
+
+	for _, user := range added {
+		if !containsReviewer(output, user.UUID) {
+			output = append(output, user)
+		}
+	}
+
+	return output
+}
+
+// sameReviewers reports whether both lists contain the same reviewers, regardless of order.
+func sameReviewers(a, b []cloudUser) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, user := range b {
+		if !containsReviewer(a, user.UUID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsReviewer(users []cloudUser, uuid string) bool {
+	for _, user := range users {
+		if user.UUID == uuid {
+			return true
+		}
+	}
+
+	return false
+}
+
+type cloudPRResp struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	Draft       bool     `json:"draft"`
+	Source      cloudRef `json:"source"`
+	Destination cloudRef `json:"destination"`
+
+	Reviewers    []cloudUser        `json:"reviewers"`
+	Participants []cloudParticipant `json:"participants"`
+	MergeCommit  *cloudCommit       `json:"merge_commit"`
+
+	Links struct {
+		HTML cloudLink `json:"html"`
+	} `json:"links"`
+}
+
+type cloudPRPayload struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Source      *cloudRef   `json:"source,omitempty"`
+	Destination *cloudRef   `json:"destination,omitempty"`
+	Reviewers   []cloudUser `json:"reviewers,omitempty"`
+	Draft       *bool       `json:"draft,omitempty"`
+}
+
+type cloudMergePayload struct {
+	MergeStrategy string `json:"merge_strategy"`
+}
+
+type cloudRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit *cloudCommit `json:"commit,omitempty"`
+}
+
+// newCloudRef creates a reference to the given branch for a pull request payload.
+func newCloudRef(branch string) *cloudRef {
+	ref := &cloudRef{}
+	ref.Branch.Name = branch
+
+	return ref
+}
+
+type cloudCommit struct {
+	Hash string `json:"hash"`
+}
+
+type cloudLink struct {
+	Href string `json:"href"`
+}
+
+type cloudParticipant struct {
+	User     cloudUser `json:"user"`
+	Role     string    `json:"role"`
+	Approved bool      `json:"approved"`
+	State    string    `json:"state"`
+}
+
+type cloudRestriction struct {
+	Kind            string `json:"kind"`
+	Pattern         string `json:"pattern"`
+	BranchMatchKind string `json:"branch_match_kind"`
+	Value           int    `json:"value"`
+}
+
+func parseCloudPR(repo string, resp *cloudPRResp) *scm.PullRequest {
+	pr := &scm.PullRequest{
+		ID:          resp.ID,
+		Number:      resp.ID,
+		Title:       resp.Title,
+		Description: resp.Description,
+		Branch:      resp.Source.Branch.Name,
+		BaseBranch:  resp.Destination.Branch.Name,
+		Repo:        repo,
+		URL:         resp.Links.HTML.Href,
+		Draft:       resp.Draft,
+	}
+
+	for _, reviewer := range resp.Reviewers {
+		pr.Reviewers = append(pr.Reviewers, reviewer.Name())
+	}
+
+	if resp.MergeCommit != nil {
+		pr.MergeCommit = resp.MergeCommit.Hash
+	}
+
+	return pr
+}
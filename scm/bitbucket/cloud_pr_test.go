@@ -0,0 +1,531 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+const cloudPRsPath = "/2.0/repositories/test-ws/test-repo/pullrequests"
+
+// cloudMembers are the members of the mock workspace, by nickname.
+var cloudMembers = map[string]string{"alice": "{u1}", "bob": "{u2}", "carol": "{u3}"}
+
+// mockCloudPR creates a Bitbucket Cloud pull request API response
+func mockCloudPR(id int, branch, title string, reviewers ...string) map[string]any {
+	users := make([]map[string]any, len(reviewers))
+	for i, reviewer := range reviewers {
+		users[i] = map[string]any{"uuid": cloudMembers[reviewer], "nickname": reviewer, "display_name": strings.ToUpper(reviewer)}
+	}
+
+	return map[string]any{
+		"id":          id,
+		"title":       title,
+		"description": "PR description",
+		"state":       "OPEN",
+		"source": map[string]any{
+			"branch": map[string]any{"name": branch},
+			"commit": map[string]any{"hash": "abc123def456"},
+		},
+		"destination": map[string]any{"branch": map[string]any{"name": "main"}},
+		"reviewers":   users,
+		"links": map[string]any{
+			"html": map[string]any{"href": fmt.Sprintf("https://bitbucket.org/test-ws/test-repo/pull-requests/%d", id)},
+		},
+	}
+}
+
+// serveMembers responds to a listing of the workspace members, reporting whether the request was one.
+func serveMembers(t *testing.T, w http.ResponseWriter, r *http.Request) bool {
+	t.Helper()
+
+	if r.Method != http.MethodGet || r.URL.Path != "/2.0/workspaces/test-ws/members" {
+		return false
+	}
+
+	var members []any
+	for nickname, uuid := range cloudMembers {
+		members = append(members, map[string]any{"user": map[string]any{"uuid": uuid, "nickname": nickname}})
+	}
+
+	writeJSON(t, w, page("", members...))
+
+	return true
+}
+
+// decodePayload decodes the JSON request body into the pull request payload.
+func decodePayload(t *testing.T, r *http.Request) *cloudPRPayload {
+	t.Helper()
+
+	var payload cloudPRPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+
+	return &payload
+}
+
+// uuids joins the UUIDs of the users for comparison.
+func uuids(users []cloudUser) string {
+	output := make([]string, len(users))
+	for i, user := range users {
+		output[i] = user.UUID
+	}
+
+	return strings.Join(output, ",")
+}
+
+func TestCloudGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath)
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "OPEN")
+		testhelper.AssertEqual(t, r.URL.Query().Get("q"), `source.branch.name = "feature-branch"`)
+
+		writeJSON(t, w, page("", mockCloudPR(2, "feature-branch", "Test PR", "alice", "bob")))
+	}))
+	defer server.Close()
+
+	pr, err := newTestCloud(t, server).GetPullRequest("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.ID, 2)
+	testhelper.AssertEqual(t, pr.Number, 2)
+	testhelper.AssertEqual(t, pr.Title, "Test PR")
+	testhelper.AssertEqual(t, pr.Description, "PR description")
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, pr.Repo, "test-repo")
+	testhelper.AssertEqual(t, pr.URL, "https://bitbucket.org/test-ws/test-repo/pull-requests/2")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestCloudGetPullRequestNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, page(""))
+	}))
+	defer server.Close()
+
+	_, err := newTestCloud(t, server).GetPullRequest("test-repo", "feature-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no open pull request found for branch feature-branch in repository test-repo"})
+}
+
+func TestCloudGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == cloudPRsPath+"/404" {
+			http.NotFound(w, r)
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath+"/7")
+
+		pr := mockCloudPR(7, "feature-branch", "Merged PR")
+		pr["state"] = "MERGED"
+		pr["merge_commit"] = map[string]any{"hash": "def456"}
+		writeJSON(t, w, pr)
+	}))
+	defer server.Close()
+
+	c := newTestCloud(t, server)
+
+	pr, err := c.GetPullRequestByNumber("test-repo", 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 7)
+	testhelper.AssertEqual(t, pr.MergeCommit, "def456")
+
+	_, err = c.GetPullRequestByNumber("test-repo", 404)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no pull request #404 found in repository test-repo"})
+}
+
+func TestCloudGetPullRequestHeadSHA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case cloudPRsPath:
+			writeJSON(t, w, page("", mockCloudPR(2, "feature-branch", "Test PR")))
+		case "/2.0/repositories/test-ws/test-repo/commit/abc123def456":
+			writeJSON(t, w, map[string]any{"hash": "abc123def4567890abc123def4567890abc123de"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	sha, err := newTestCloud(t, server).GetPullRequestHeadSHA("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, sha, "abc123def4567890abc123def4567890abc123de")
+}
+
+func TestCloudListOpenPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath)
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "OPEN")
+
+		if r.URL.Query().Get("page") == "" {
+			writeJSON(t, w, page("http://"+r.Host+r.URL.Path+"?state=OPEN&page=2", mockCloudPR(1, "branch-1", "First PR")))
+			return
+		}
+
+		writeJSON(t, w, page("", mockCloudPR(2, "branch-2", "Second PR")))
+	}))
+	defer server.Close()
+
+	prs, err := newTestCloud(t, server).ListOpenPullRequests("test-repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, prs, 2)
+	testhelper.AssertEqual(t, prs[0].Branch, "branch-1")
+	testhelper.AssertEqual(t, prs[1].Branch, "branch-2")
+}
+
+func TestCloudOpenPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveMembers(t, w, r) {
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath)
+
+		if r.Method == http.MethodGet {
+			writeJSON(t, w, page(""))
+			return
+		}
+
+		testhelper.AssertEqual(t, r.Method, http.MethodPost)
+
+		payload := decodePayload(t, r)
+		testhelper.AssertEqual(t, payload.Title, "New feature")
+		testhelper.AssertEqual(t, payload.Description, "Feature description")
+		testhelper.AssertEqual(t, payload.Source.Branch.Name, "feature-branch")
+		testhelper.AssertEqual(t, payload.Destination.Branch.Name, "develop")
+		testhelper.AssertEqual(t, uuids(payload.Reviewers), "{u1},{u2}")
+		testhelper.AssertEqual(t, *payload.Draft, true)
+
+		pr := mockCloudPR(5, "feature-branch", "New feature", "alice", "bob")
+		pr["draft"] = true
+		writeJSON(t, w, pr)
+	}))
+	defer server.Close()
+
+	draft := true
+	pr, err := newTestCloud(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:       "New feature",
+		Description: "Feature description",
+		BaseBranch:  "develop",
+		Reviewers:   []string{"alice", "bob"},
+		Draft:       &draft,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 5)
+	testhelper.AssertEqual(t, pr.Draft, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestCloudOpenPullRequestDefaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			writeJSON(t, w, page(""))
+			return
+		}
+
+		payload := decodePayload(t, r)
+		testhelper.AssertEqual(t, payload.Title, "feature-branch")
+		testhelper.AssertEqual(t, payload.Destination.Branch.Name, "main")
+		testhelper.AssertEqual(t, payload.Draft == nil, true)
+		testhelper.AssertLength(t, payload.Reviewers, 0)
+
+		writeJSON(t, w, mockCloudPR(5, "feature-branch", "feature-branch"))
+	}))
+	defer server.Close()
+
+	pr, err := newTestCloud(t, server).OpenPullRequest("test-repo", "feature-branch", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Title, "feature-branch")
+}
+
+func TestCloudOpenPullRequestUnknownReviewer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveMembers(t, w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		writeJSON(t, w, page(""))
+	}))
+	defer server.Close()
+
+	_, err := newTestCloud(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Reviewers: []string{"nobody"}})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no Bitbucket user found with nickname nobody in workspace test-ws"})
+}
+
+func TestCloudOpenPullRequestAlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		writeJSON(t, w, page("", mockCloudPR(3, "feature-branch", "Existing PR")))
+	}))
+	defer server.Close()
+
+	_, err := newTestCloud(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Title: "New PR"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"a pull request already exists", "PR #3", "pull-requests/3"})
+}
+
+func TestCloudUpdatePullRequest(t *testing.T) {
+	draft, ready := true, false
+
+	tests := []struct {
+		name          string
+		opts          *scm.PROptions
+		wantUpdate    bool
+		wantTitle     string
+		wantReviewers string
+		wantDraft     *bool
+	}{
+		{
+			name:          "append reviewers",
+			opts:          &scm.PROptions{Reviewers: []string{"bob", "alice"}},
+			wantUpdate:    true,
+			wantTitle:     "Test PR",
+			wantReviewers: "{u1},{u2}",
+		},
+		{
+			name:          "reset reviewers",
+			opts:          &scm.PROptions{Reviewers: []string{"bob"}, ResetReviewers: true},
+			wantUpdate:    true,
+			wantTitle:     "Test PR",
+			wantReviewers: "{u2}",
+		},
+		{
+			name:       "title and description",
+			opts:       &scm.PROptions{Title: "New title", Description: "New description"},
+			wantUpdate: true,
+			wantTitle:  "New title",
+		},
+		{
+			name:       "convert to draft",
+			opts:       &scm.PROptions{Draft: &draft},
+			wantUpdate: true,
+			wantTitle:  "Test PR",
+			wantDraft:  &draft,
+		},
+		{
+			name: "already ready",
+			opts: &scm.PROptions{Draft: &ready},
+		},
+		{
+			name: "existing reviewer",
+			opts: &scm.PROptions{Reviewers: []string{"alice"}},
+		},
+		{
+			name: "no changes",
+			opts: &scm.PROptions{Title: "Test PR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if serveMembers(t, w, r) {
+					return
+				}
+
+				if r.Method == http.MethodGet {
+					testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath)
+					writeJSON(t, w, page("", mockCloudPR(2, "feature-branch", "Test PR", "alice")))
+					return
+				}
+
+				updated = true
+				testhelper.AssertEqual(t, r.Method, http.MethodPut)
+				testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath+"/2")
+
+				payload := decodePayload(t, r)
+				testhelper.AssertEqual(t, payload.Title, tt.wantTitle)
+				testhelper.AssertEqual(t, uuids(payload.Reviewers), tt.wantReviewers)
+				testhelper.AssertEqual(t, payload.Draft == nil, tt.wantDraft == nil)
+
+				writeJSON(t, w, mockCloudPR(2, "feature-branch", payload.Title, "alice"))
+			}))
+			defer server.Close()
+
+			pr, err := newTestCloud(t, server).UpdatePullRequest("test-repo", "feature-branch", tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, updated, tt.wantUpdate)
+			testhelper.AssertEqual(t, pr.Number, 2)
+		})
+	}
+}
+
+func TestCloudMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         *scm.PRMergeOptions
+		wantStrategy string
+		accepted     bool
+		wantCommit   string
+		wantErr      string
+	}{
+		{
+			name:         "merge commit",
+			opts:         &scm.PRMergeOptions{Method: "merge"},
+			wantStrategy: "merge_commit",
+			wantCommit:   "def456",
+		},
+		{
+			name:         "default method",
+			opts:         &scm.PRMergeOptions{},
+			wantStrategy: "squash",
+			wantCommit:   "def456",
+		},
+		{
+			name:         "accepted asynchronously",
+			opts:         &scm.PRMergeOptions{Method: "squash"},
+			wantStrategy: "squash",
+			accepted:     true,
+		},
+		{
+			name:    "unsupported method",
+			opts:    &scm.PRMergeOptions{Method: "rebase"},
+			wantErr: `merge method "rebase" is not supported by the Bitbucket provider`,
+		},
+		{
+			name:    "dry run",
+			opts:    &scm.PRMergeOptions{DryRun: true},
+			wantErr: "checking PR mergeability is not currently supported by the Bitbucket provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(t, w, page("", mockCloudPR(2, "feature-branch", "Test PR")))
+					return
+				}
+
+				merged = true
+				testhelper.AssertEqual(t, r.Method, http.MethodPost)
+				testhelper.AssertEqual(t, r.URL.Path, cloudPRsPath+"/2/merge")
+
+				var payload cloudMergePayload
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatalf("Failed to decode request body: %v", err)
+				}
+
+				testhelper.AssertEqual(t, payload.MergeStrategy, tt.wantStrategy)
+
+				if tt.accepted {
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+
+				pr := mockCloudPR(2, "feature-branch", "Test PR")
+				pr["state"] = "MERGED"
+				pr["merge_commit"] = map[string]any{"hash": "def456"}
+				writeJSON(t, w, pr)
+			}))
+			defer server.Close()
+
+			pr, err := newTestCloud(t, server).MergePullRequest("test-repo", "feature-branch", tt.opts)
+			if tt.wantErr != "" {
+				testhelper.AssertError(t, err, true)
+				testhelper.AssertContains(t, err.Error(), []string{tt.wantErr})
+				testhelper.AssertEqual(t, merged, false)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, merged, true)
+			testhelper.AssertEqual(t, pr.Number, 2)
+			testhelper.AssertEqual(t, pr.MergeCommit, tt.wantCommit)
+		})
+	}
+}
+
+func TestCloudMergePullRequestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			writeJSON(t, w, page("", mockCloudPR(2, "feature-branch", "Test PR")))
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type":"error","error":{"message":"You can't merge until you resolve all merge conflicts."}}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestCloud(t, server).MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{Method: "merge"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"failed to merge pull request", "error 400", "merge conflicts"})
+}
+
+func TestCloudGetReviewStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case cloudPRsPath:
+			pr := mockCloudPR(2, "feature-branch", "Test PR", "alice", "bob")
+			pr["participants"] = []map[string]any{
+				{"user": map[string]any{"nickname": "alice"}, "role": "REVIEWER", "approved": true, "state": "approved"},
+				{"user": map[string]any{"nickname": "bob"}, "role": "REVIEWER", "approved": false, "state": "changes_requested"},
+				{"user": map[string]any{"nickname": "carol"}, "role": "PARTICIPANT", "approved": false},
+			}
+			writeJSON(t, w, page("", pr))
+		case "/2.0/repositories/test-ws/test-repo/branch-restrictions":
+			testhelper.AssertEqual(t, r.URL.Query().Get("kind"), "require_approvals_to_merge")
+			writeJSON(t, w, page("",
+				map[string]any{"kind": "require_approvals_to_merge", "pattern": "main", "branch_match_kind": "glob", "value": 2},
+				map[string]any{"kind": "require_approvals_to_merge", "pattern": "release/*", "branch_match_kind": "glob", "value": 3},
+				map[string]any{"kind": "require_approvals_to_merge", "branch_match_kind": "branching_model", "value": 4},
+			))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	status, err := newTestCloud(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 2)
+	testhelper.AssertEqual(t, strings.Join(status.Approvers, ","), "alice")
+	testhelper.AssertEqual(t, strings.Join(status.ChangesRequested, ","), "bob")
+	testhelper.AssertEqual(t, status.Approved(), false)
+}
@@ -0,0 +1,77 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+type cloudRepoResp struct {
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		Clone []struct {
+			Name string `json:"name"`
+			Href string `json:"href"`
+		} `json:"clone"`
+		HTML cloudLink `json:"html"`
+	} `json:"links"`
+}
+
+// ListRepositories lists all repositories in the provider's workspace.
+func (c *Cloud) ListRepositories() ([]*scm.Repository, error) {
+	repos, err := cloudList[cloudRepoResp](c, c.url(nil, "repositories", c.workspace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories in workspace %s: %w", c.workspace, err)
+	}
+
+	output := make([]*scm.Repository, len(repos))
+	for i, repo := range repos {
+		output[i] = &scm.Repository{
+			Name:        repo.Slug,
+			Description: repo.Description,
+			Public:      !repo.IsPrivate,
+			// Bitbucket Cloud has no native concept of an archived repository (or of repository labels)
+			Archived: false,
+			Project:  c.workspace,
+			WebURL:   repo.Links.HTML.Href,
+		}
+
+		if repo.MainBranch != nil {
+			output[i].DefaultBranch = repo.MainBranch.Name
+		} else {
+			// fall back on configured default branch if it isn't set for the repo
+			output[i].DefaultBranch = config.Viper(c.ctx).GetString(config.DefaultBranch)
+		}
+
+		for _, link := range repo.Links.Clone {
+			switch link.Name {
+			case "https":
+				output[i].CloneURL = stripUser(link.Href)
+			case "ssh":
+				output[i].SSHURL = link.Href
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// stripUser removes the username which Bitbucket Cloud embeds in HTTPS clone URLs, so that clones
+// authenticate with the configured token rather than prompting for that user's password.
+func stripUser(cloneURL string) string {
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return cloneURL
+	}
+
+	u.User = nil
+
+	return u.String()
+}
@@ -0,0 +1,204 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+// newTestCloud creates a Bitbucket Cloud provider for the "test-ws" workspace which sends its requests to the test server.
+func newTestCloud(t *testing.T, server *httptest.Server) *Cloud {
+	t.Helper()
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.AuthToken, "test-token")
+
+	baseURL, err := url.Parse(server.URL + "/2.0")
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	// members are cached by API URL, so each test server starts with an empty cache
+	t.Cleanup(func() {
+		memberMu.Lock()
+		defer memberMu.Unlock()
+		delete(memberCache, baseURL.String()+"\x00test-ws")
+	})
+
+	return &Cloud{
+		client:    server.Client(),
+		baseURL:   baseURL,
+		workspace: "test-ws",
+		ctx:       ctx,
+	}
+}
+
+// writeJSON encodes the value as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// page creates a paginated Bitbucket Cloud response, linking to the next page if it isn't empty.
+func page(next string, values ...any) map[string]any {
+	resp := map[string]any{"values": values, "pagelen": cloudPageSize}
+	if next != "" {
+		resp["next"] = next
+	}
+
+	return resp
+}
+
+func TestNewCloud(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.GitHost, "Bitbucket.org")
+
+	provider, ok := New(ctx, "test-ws").(*Cloud)
+	if !ok {
+		t.Fatalf("Expected *Cloud provider, got %T", provider)
+	}
+
+	testhelper.AssertEqual(t, provider.workspace, "test-ws")
+	testhelper.AssertEqual(t, provider.prURL("repo", 7, "merge"), "https://api.bitbucket.org/2.0/repositories/test-ws/repo/pullrequests/7/merge")
+}
+
+func TestCloudCheckCapabilities(t *testing.T) {
+	draft := true
+	c := &Cloud{}
+
+	testhelper.AssertError(t, c.CheckCapabilities(&scm.PROptions{Draft: &draft, ResetReviewers: true}), false)
+	testhelper.AssertError(t, c.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "squash"}}), false)
+	testhelper.AssertError(t, c.CheckCapabilities(&scm.PROptions{Assignees: []string{"alice"}}), true)
+	testhelper.AssertError(t, c.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "rebase"}}), true)
+}
+
+func TestCloudCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/2.0/user")
+		testhelper.AssertEqual(t, r.Header.Get("Authorization"), "Bearer test-token")
+
+		writeJSON(t, w, map[string]any{"uuid": "{u1}", "nickname": "alice", "display_name": "Alice Smith"})
+	}))
+	defer server.Close()
+
+	username, err := newTestCloud(t, server).CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, username, "alice")
+}
+
+func TestCloudUserUUID(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		testhelper.AssertEqual(t, r.URL.Path, "/2.0/workspaces/test-ws/members")
+
+		// members are split across pages to exercise pagination
+		if r.URL.Query().Get("page") == "" {
+			testhelper.AssertEqual(t, r.URL.Query().Get("pagelen"), fmt.Sprint(cloudPageSize))
+			writeJSON(t, w, page("http://"+r.Host+r.URL.Path+"?page=2",
+				map[string]any{"user": map[string]any{"uuid": "{u1}", "nickname": "Alice", "account_id": "acct-1"}}))
+
+			return
+		}
+
+		writeJSON(t, w, page("", map[string]any{"user": map[string]any{"uuid": "{u2}", "nickname": "bob", "account_id": "acct-2"}}))
+	}))
+	defer server.Close()
+
+	c := newTestCloud(t, server)
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "alice", want: "{u1}"},
+		{name: "acct-2", want: "{u2}"},
+		{name: "{u3}", want: "{u3}"}, // UUIDs are used as given
+		{name: "nobody", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uuid, err := c.userUUID(tt.name)
+			testhelper.AssertError(t, err, tt.wantErr)
+			testhelper.AssertEqual(t, uuid, tt.want)
+		})
+	}
+
+	// both pages are listed once, and then cached
+	testhelper.AssertEqual(t, lookups, 2)
+}
+
+func TestCloudListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/2.0/repositories/test-ws")
+
+		if r.URL.Query().Get("page") == "" {
+			writeJSON(t, w, page("http://"+r.Host+r.URL.Path+"?page=2", map[string]any{
+				"slug":        "first-repo",
+				"description": "The first repository",
+				"is_private":  false,
+				"mainbranch":  map[string]any{"name": "develop"},
+				"links": map[string]any{
+					"clone": []map[string]any{
+						{"name": "https", "href": "https://someone@bitbucket.org/test-ws/first-repo.git"},
+						{"name": "ssh", "href": "git@bitbucket.org:test-ws/first-repo.git"},
+					},
+					"html": map[string]any{"href": "https://bitbucket.org/test-ws/first-repo"},
+				},
+			}))
+
+			return
+		}
+
+		writeJSON(t, w, page("", map[string]any{"slug": "empty-repo", "is_private": true}))
+	}))
+	defer server.Close()
+
+	repos, err := newTestCloud(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, 2)
+
+	first := repos[0]
+	testhelper.AssertEqual(t, first.Name, "first-repo")
+	testhelper.AssertEqual(t, first.Description, "The first repository")
+	testhelper.AssertEqual(t, first.Public, true)
+	testhelper.AssertEqual(t, first.Project, "test-ws")
+	testhelper.AssertEqual(t, first.DefaultBranch, "develop")
+	testhelper.AssertEqual(t, first.CloneURL, "https://bitbucket.org/test-ws/first-repo.git")
+	testhelper.AssertEqual(t, first.SSHURL, "git@bitbucket.org:test-ws/first-repo.git")
+	testhelper.AssertEqual(t, first.WebURL, "https://bitbucket.org/test-ws/first-repo")
+
+	// a repository without a main branch (e.g. empty) falls back on the configured default
+	testhelper.AssertEqual(t, repos[1].Public, false)
+	testhelper.AssertEqual(t, repos[1].DefaultBranch, "main")
+}
+
+func TestCloudListRepositoriesAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"type":"error","error":{"message":"Access denied"}}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestCloud(t, server).ListRepositories()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"error 403", "Access denied"})
+}
@@ -23,6 +23,24 @@ func (b *Bitbucket) GetPullRequest(repo, branch string) (*scm.PullRequest, error
 	return parsePR(resp), nil
 }
 
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (b *Bitbucket) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "OPEN")
+	queryParams.Set("limit", "1000")
+	values, err := list[*prResp](b, repo, queryParams, "pull-requests")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	output := make([]*scm.PullRequest, len(values))
+	for i, pr := range values {
+		output[i] = parsePR(pr)
+	}
+
+	return output, nil
+}
+
 // OpenPullRequest opens a new pull request in the specified repository.
 func (b *Bitbucket) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
 	if opts == nil {
@@ -30,8 +48,8 @@ func (b *Bitbucket) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*
 	}
 
 	// check for existing PR first (reads are less restrictive than a failed write)
-	if _, err := b.getPullRequest(repo, branch); err == nil {
-		return nil, fmt.Errorf("a pull request already exists for branch %s in repository %s", branch, repo)
+	if existing, err := b.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parsePR(existing))
 	}
 
 	// default PR title is branch name
@@ -51,7 +69,12 @@ func (b *Bitbucket) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*
 		return nil, fmt.Errorf("failed to open pull request: %w", err)
 	}
 
-	// only ID is returned in response, so we need to set the rest of the fields for the return value
+	// Refresh the pull request to include the reviewers added by the server (e.g. default reviewers). The pull request
+	// is open either way, so if that fails the fields of the response are filled in from the options instead.
+	if created, err := b.GetPullRequestByNumber(repo, int(pr.ID)); err == nil {
+		return created, nil
+	}
+
 	pr.Title = opts.Title
 	pr.Description = opts.Description
 	pr.FromRef = prRef{
@@ -115,7 +138,11 @@ func (b *Bitbucket) UpdatePullRequest(repo, branch string, opts *scm.PROptions)
 }
 
 // MergePullRequest merges an existing pull request.
-func (b *Bitbucket) MergePullRequest(repo, branch string, _ *scm.PRMergeOptions) (*scm.PullRequest, error) {
+func (b *Bitbucket) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts != nil && opts.DryRun {
+		return nil, fmt.Errorf("checking PR mergeability is not currently supported by the Bitbucket provider")
+	}
+
 	pr, err := b.GetPullRequest(repo, branch)
 	if err != nil {
 		return nil, err
@@ -135,6 +162,26 @@ func (b *Bitbucket) MergePullRequest(repo, branch string, _ *scm.PRMergeOptions)
 	return pr, nil
 }
 
+// GetPullRequestByNumber retrieves a pull request by repository name and number (its ID), regardless of its state.
+func (b *Bitbucket) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := get[prResp](b, b.url(repo, nil, "pull-requests", strconv.Itoa(number)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request [%d] for %s: %w", number, repo, err)
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request.
+func (b *Bitbucket) GetPullRequestHeadSHA(_, _ string) (string, error) {
+	return "", fmt.Errorf("retrieving the head of a pull request is not currently supported by the Bitbucket provider")
+}
+
+// GetReviewStatus retrieves the approval state of a pull request.
+func (b *Bitbucket) GetReviewStatus(_, _ string) (*scm.ReviewStatus, error) {
+	return nil, fmt.Errorf("retrieving review status is not currently supported by the Bitbucket provider")
+}
+
 func (b *Bitbucket) getPullRequest(repo, branch string) (*prResp, error) {
 	queryParams := url.Values{}
 	queryParams.Set("direction", "outgoing")
@@ -151,9 +198,7 @@ func (b *Bitbucket) getPullRequest(repo, branch string) (*prResp, error) {
 	return resp.Values[0], nil
 }
 
-type prListResp struct {
-	Values []*prResp `json:"values"`
-}
+type prListResp = serverPage[*prResp]
 
 type prResp struct {
 	ID      float64 `json:"id,omitempty"`
@@ -277,6 +277,95 @@ func TestGetPullRequest_APIError(t *testing.T) {
 	}
 }
 
+func TestListOpenPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/pull-requests") {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("state") != "OPEN" {
+			t.Errorf("Expected state=OPEN, got %s", r.URL.Query().Get("state"))
+		}
+
+		resp := map[string]interface{}{
+			"values": []map[string]interface{}{
+				mockBitbucketPRResponse(42, "Bump dependencies", "", nil),
+				mockBitbucketPRResponse(43, "Fix flaky test", "", []string{"alice"}),
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	prs, err := b.ListOpenPullRequests("test-repo")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(prs) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(prs))
+	}
+	if prs[0].ID != 42 || prs[0].Title != "Bump dependencies" {
+		t.Errorf("Unexpected first pull request: %+v", prs[0])
+	}
+	if prs[1].ID != 43 || len(prs[1].Reviewers) != 1 {
+		t.Errorf("Unexpected second pull request: %+v", prs[1])
+	}
+}
+
+func TestListOpenPullRequests_Paged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Return the results across two pages
+		var resp map[string]interface{}
+		switch start := r.URL.Query().Get("start"); start {
+		case "":
+			resp = map[string]interface{}{
+				"values":        []map[string]interface{}{mockBitbucketPRResponse(42, "Bump dependencies", "", nil)},
+				"isLastPage":    false,
+				"nextPageStart": 1,
+			}
+		case "1":
+			resp = map[string]interface{}{
+				"values":     []map[string]interface{}{mockBitbucketPRResponse(43, "Fix flaky test", "", nil)},
+				"isLastPage": true,
+			}
+		default:
+			t.Errorf("Unexpected page start: %s", start)
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	prs, err := b.ListOpenPullRequests("test-repo")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(prs) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(prs))
+	}
+	if prs[0].ID != 42 || prs[1].ID != 43 {
+		t.Errorf("Unexpected pull requests: %+v, %+v", prs[0], prs[1])
+	}
+}
+
+func TestListOpenPullRequests_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"errors": [{"message": "Internal Server Error"}]}`))
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	if _, err := b.ListOpenPullRequests("test-repo"); err == nil {
+		t.Fatal("Expected error for API failure")
+	}
+}
+
 func TestOpenPullRequest(t *testing.T) {
 	requestPhase := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -353,6 +442,15 @@ func TestOpenPullRequest_WithReviewers(t *testing.T) {
 			json.NewEncoder(w).Encode(pr)
 			return
 		}
+
+		// Phase 3: Refresh the created PR, which includes the default reviewers added by the server
+		if requestPhase == 3 && r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pull-requests/100") {
+			pr := mockBitbucketPRResponse(100, "New Feature", "", []string{"alice", "bob", "carol"})
+			json.NewEncoder(w).Encode(pr)
+			return
+		}
+
+		t.Errorf("Unexpected request %d: %s %s", requestPhase, r.Method, r.URL.Path)
 	}))
 	defer server.Close()
 
@@ -366,8 +464,8 @@ func TestOpenPullRequest_WithReviewers(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	if len(pr.Reviewers) != 2 {
-		t.Errorf("Expected 2 reviewers, got %d", len(pr.Reviewers))
+	if len(pr.Reviewers) != 3 {
+		t.Errorf("Expected 3 reviewers including the default reviewer, got %v", pr.Reviewers)
 	}
 }
 
@@ -699,3 +797,55 @@ func TestGenPR_DefaultBaseBranch(t *testing.T) {
 		t.Error("Expected toRef to have default branch")
 	}
 }
+
+func TestGetReviewStatus_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	if _, err := b.GetReviewStatus("test-repo", "feature-branch"); err == nil || !strings.Contains(err.Error(), "not currently supported") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
+
+func TestGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/repos/test-repo/pull-requests/42") {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		json.NewEncoder(w).Encode(mockBitbucketPRResponse(42, "Test PR", "PR description", []string{"alice"}))
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	pr, err := b.GetPullRequestByNumber("test-repo", 42)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if pr.Number != 42 || pr.Title != "Test PR" {
+		t.Errorf("Expected PR #42 'Test PR', got #%d '%s'", pr.Number, pr.Title)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0] != "alice" {
+		t.Errorf("Expected reviewer alice, got %v", pr.Reviewers)
+	}
+}
+
+func TestGetPullRequestByNumber_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"errors": []map[string]interface{}{{"message": "Pull request 42 does not exist in TEST/test-repo."}},
+		})
+	}))
+	defer server.Close()
+
+	b := newTestBitbucket(t, server)
+	if _, err := b.GetPullRequestByNumber("test-repo", 42); err == nil || !strings.Contains(err.Error(), "failed to get pull request [42]") {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
@@ -16,6 +18,7 @@ var caps = &scm.Capabilities{
 	TeamReviewers:  false,
 	ResetReviewers: true,
 	Draft:          false,
+	Assignees:      false,
 
 	MergeMethods:   []string{},
 	CheckMergeable: false,
@@ -28,13 +31,20 @@ func init() {
 	scm.Register("bitbucket", New)
 }
 
-// New creates a new Bitbucket SCM provider instance.
+// New creates a new Bitbucket SCM provider instance. Bitbucket Cloud (bitbucket.org) is served by the
+// v2 API, and any other host is treated as Bitbucket Server or Data Center.
 func New(ctx context.Context, project string) scm.Provider {
 	viper := config.Viper(ctx)
+
+	host := viper.GetString(config.GitHost)
+	if strings.EqualFold(host, cloudHost) {
+		return newCloud(ctx, project)
+	}
+
 	return &Bitbucket{
 		client:  http.DefaultClient,
 		scheme:  "https",
-		host:    viper.GetString(config.GitHost),
+		host:    host,
 		project: project,
 		ctx:     ctx,
 	}
@@ -54,6 +64,16 @@ func (b *Bitbucket) CheckCapabilities(opts *scm.PROptions) error {
 	return scm.ValidatePROptions(caps, opts)
 }
 
+// CurrentUser returns the login of the authenticated user.
+func (b *Bitbucket) CurrentUser() (string, error) {
+	return "", fmt.Errorf("retrieving the current user is not currently supported by the Bitbucket provider")
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of Bitbucket tokens aren't inspected.
+func (b *Bitbucket) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
 // constructs the base URL for the Bitbucket API endpoint.
 func (b *Bitbucket) url(repo string, queryParams url.Values, path ...string) string {
 	scheme := b.scheme
@@ -93,10 +113,45 @@ func get[T any](b *Bitbucket, path string) (*T, error) {
 	return do[T](b, req)
 }
 
+// convenience function to GET every page of a list endpoint and combine the results, following the
+// nextPageStart of Bitbucket Server's paginated responses until the last page.
+func list[T any](b *Bitbucket, repo string, queryParams url.Values, path ...string) ([]T, error) {
+	if queryParams == nil {
+		queryParams = url.Values{}
+	}
+
+	output := make([]T, 0)
+	for {
+		resp, err := get[serverPage[T]](b, b.url(repo, queryParams, path...))
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, resp.Values...)
+
+		if resp.IsLastPage || resp.NextPageStart == 0 {
+			return output, nil
+		}
+
+		queryParams.Set("start", strconv.Itoa(resp.NextPageStart))
+	}
+}
+
+// serverPage is a page of a paginated Bitbucket Server list response.
+type serverPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
 // convenience function to perform an HTTP request and unmarshal the response into the specified type.
 func do[T any](b *Bitbucket, req *http.Request) (*T, error) {
-	viper := config.Viper(b.ctx)
-	req.Header.Set("Authorization", "Bearer "+viper.GetString(config.AuthToken))
+	token, err := scm.AuthToken(b.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
 	if req.Body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -125,6 +180,16 @@ func doResp[T any](client *http.Client, req *http.Request) (*T, error) {
 	return &result, nil
 }
 
+// apiError is returned when the Bitbucket API responds with an error status.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.StatusCode, e.Body)
+}
+
 func parseError(resp *http.Response) error {
 	if resp.StatusCode < 400 {
 		return nil
@@ -135,5 +200,5 @@ func parseError(resp *http.Response) error {
 		return fmt.Errorf("error %d: failed to read response body: %w", resp.StatusCode, err)
 	}
 
-	return fmt.Errorf("error %d: %s", resp.StatusCode, output)
+	return &apiError{StatusCode: resp.StatusCode, Body: string(output)}
 }
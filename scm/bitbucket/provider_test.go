@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/ryclarke/batch-tool/scm"
@@ -299,3 +300,11 @@ func TestBitbucketDefaultBranchJSONParsing(t *testing.T) {
 		t.Errorf("Expected display ID 'main', got '%s'", resp.DisplayID)
 	}
 }
+
+func TestCurrentUser_Unsupported(t *testing.T) {
+	provider := New(loadFixture(t), "test-project")
+
+	if _, err := provider.CurrentUser(); err == nil || !strings.Contains(err.Error(), "not currently supported") {
+		t.Errorf("Expected unsupported error, got: %v", err)
+	}
+}
@@ -27,22 +27,20 @@ type bitbucketRepository struct {
 	// We'll fetch it separately
 }
 
-type repositoryListResp struct {
-	Values []*bitbucketRepository `json:"values"`
-}
+type repositoryListResp = serverPage[*bitbucketRepository]
 
 // ListRepositories lists all repositories in the specified project.
 func (b *Bitbucket) ListRepositories() ([]*scm.Repository, error) {
 	queryParams := url.Values{}
 	queryParams.Set("limit", "1000")
-	resp, err := get[repositoryListResp](b, b.url("", queryParams, "repos"))
+	values, err := list[*bitbucketRepository](b, "", queryParams, "repos")
 	if err != nil {
 		return nil, err
 	}
 
 	// Convert BitBucket-specific format to standard Repository format
-	repositories := make([]*scm.Repository, len(resp.Values))
-	for i, bbRepo := range resp.Values {
+	repositories := make([]*scm.Repository, len(values))
+	for i, bbRepo := range values {
 		// Create standard Repository struct
 		repo := &scm.Repository{
 			Name:        bbRepo.Name,
@@ -77,23 +75,23 @@ func (b *Bitbucket) ListRepositories() ([]*scm.Repository, error) {
 	return repositories, nil
 }
 
-type labelListResp struct {
-	Values []struct {
-		Name string `json:"name"`
-	} `json:"values"`
+type bitbucketLabel struct {
+	Name string `json:"name"`
 }
 
+type labelListResp = serverPage[bitbucketLabel]
+
 func (b *Bitbucket) getLabels(repo string) ([]string, error) {
 	queryParams := url.Values{}
 	queryParams.Set("limit", "100")
-	resp, err := get[labelListResp](b, b.url(repo, queryParams, "labels"))
+	values, err := list[bitbucketLabel](b, repo, queryParams, "labels")
 	if err != nil {
 		return nil, err
 	}
 
 	// Flatten the API response to extract the list of labels
-	var labels = make([]string, len(resp.Values))
-	for i, val := range resp.Values {
+	var labels = make([]string, len(values))
+	for i, val := range values {
 		labels[i] = val.Name
 	}
 
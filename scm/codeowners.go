@@ -0,0 +1,147 @@
+package scm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CodeownersPaths lists the locations searched for a CODEOWNERS file, relative to the repository root, in order of precedence.
+var CodeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Codeowners is a parsed CODEOWNERS file.
+type Codeowners struct {
+	rules []codeownersRule
+}
+
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeowners parses a CODEOWNERS file. Each non-comment line consists of a gitignore-style
+// path pattern followed by zero or more owners (e.g. "@user", "@org/team" or an email address).
+func ParseCodeowners(r io.Reader) (*Codeowners, error) {
+	co := &Codeowners{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern, err := compileCodeownersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CODEOWNERS pattern %q on line %d: %w", fields[0], lineNum, err)
+		}
+
+		co.rules = append(co.rules, codeownersRule{pattern: pattern, owners: fields[1:]})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	return co, nil
+}
+
+// Owners returns the sorted owners of the given paths. As with the SCM providers, the last matching
+// rule takes precedence for each path. If no paths are given, every owner in the file is returned.
+func (co *Codeowners) Owners(paths ...string) []string {
+	var owners []string
+
+	if len(paths) == 0 {
+		for _, rule := range co.rules {
+			owners = append(owners, rule.owners...)
+		}
+	}
+
+	for _, path := range paths {
+		path = strings.TrimPrefix(path, "/")
+
+		for i := len(co.rules) - 1; i >= 0; i-- {
+			if co.rules[i].pattern.MatchString(path) {
+				owners = append(owners, co.rules[i].owners...)
+				break
+			}
+		}
+	}
+
+	slices.Sort(owners)
+
+	return slices.Compact(owners)
+}
+
+// SplitOwners separates CODEOWNERS entries into individual reviewers ("@user") and team reviewers
+// ("@org/team"), with the leading "@" removed. Email owners cannot be requested as reviewers and are ignored.
+func SplitOwners(owners []string) (reviewers, teams []string) {
+	for _, owner := range owners {
+		name, ok := strings.CutPrefix(owner, "@")
+		if !ok || name == "" {
+			continue
+		}
+
+		if strings.Contains(name, "/") {
+			teams = append(teams, name)
+		} else {
+			reviewers = append(reviewers, name)
+		}
+	}
+
+	return reviewers, teams
+}
+
+// compileCodeownersPattern converts a gitignore-style CODEOWNERS pattern to a regular expression
+// matching repository-relative file paths.
+func compileCodeownersPattern(pattern string) (*regexp.Regexp, error) {
+	// Patterns containing a non-trailing slash are relative to the repository root
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	// A trailing "/*" matches direct children only, otherwise a match also covers directory contents
+	recursive := !strings.HasSuffix(pattern, "/*") || strings.HasSuffix(pattern, "**/*")
+
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(pattern[i:], "**/"):
+				expr.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(pattern[i:], "**"):
+				expr.WriteString(".*")
+				i++
+			default:
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if recursive {
+		expr.WriteString("(?:/.*)?")
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,68 @@
+package scm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleCodeowners = `# Default owners for everything in the repo
+*                   @acme/maintainers
+
+# Language-specific owners
+*.go                @gopher
+/docs/              @acme/docs-team @writer
+build/logs/         @ops
+scripts/*           @scripter
+apps/**/config.yml  @configurator
+
+# No owners: overrides earlier rules
+/vendor/
+`
+
+func TestCodeownersOwners(t *testing.T) {
+	co, err := ParseCodeowners(strings.NewReader(sampleCodeowners))
+	if err != nil {
+		t.Fatalf("ParseCodeowners failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{name: "default owner", paths: []string{"README.md"}, want: []string{"@acme/maintainers"}},
+		{name: "extension at any depth", paths: []string{"pkg/util/main.go"}, want: []string{"@gopher"}},
+		{name: "anchored directory", paths: []string{"docs/guide/intro.md"}, want: []string{"@acme/docs-team", "@writer"}},
+		{name: "anchored directory not nested", paths: []string{"src/docs/intro.md"}, want: []string{"@acme/maintainers"}},
+		{name: "relative directory", paths: []string{"build/logs/out.log"}, want: []string{"@ops"}},
+		{name: "direct children only", paths: []string{"scripts/run.sh", "scripts/lib/util.sh"}, want: []string{"@acme/maintainers", "@scripter"}},
+		{name: "double star", paths: []string{"apps/web/prod/config.yml"}, want: []string{"@configurator"}},
+		{name: "unowned override", paths: []string{"vendor/lib/lib.go"}, want: nil},
+		{name: "multiple paths", paths: []string{"main.go", "docs/a.md", "other.go"}, want: []string{"@acme/docs-team", "@gopher", "@writer"}},
+		{
+			name: "whole file",
+			want: []string{"@acme/docs-team", "@acme/maintainers", "@configurator", "@gopher", "@ops", "@scripter", "@writer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := co.Owners(tt.paths...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected owners %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitOwners(t *testing.T) {
+	reviewers, teams := SplitOwners([]string{"@alice", "@acme/platform", "bob@example.com", "@", "@carol"})
+
+	if want := []string{"alice", "carol"}; !reflect.DeepEqual(reviewers, want) {
+		t.Errorf("Expected reviewers %v, got %v", want, reviewers)
+	}
+
+	if want := []string{"acme/platform"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("Expected teams %v, got %v", want, teams)
+	}
+}
@@ -0,0 +1,121 @@
+package scm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+)
+
+// credentialCache holds tokens obtained from the credential helper for the duration of a run,
+// keyed by helper, provider and host.
+var (
+	credentialMu    sync.Mutex
+	credentialCache = make(map[string]string)
+)
+
+// AuthToken returns the API token for the configured SCM provider and host. A token set directly in the
+// configuration takes precedence, otherwise the configured credential helper is invoked (at most once per run)
+// to obtain it. An empty token is returned without error if neither is configured.
+func AuthToken(ctx context.Context) (string, error) {
+	viper := config.Viper(ctx)
+
+	if token := viper.GetString(config.AuthToken); token != "" {
+		return token, nil
+	}
+
+	helper := viper.GetString(config.CredentialHelper)
+	if helper == "" {
+		return "", nil
+	}
+
+	provider, host := viper.GetString(config.GitProvider), viper.GetString(config.GitHost)
+	key := strings.Join([]string{helper, provider, host}, "\x00")
+
+	// hold the lock while invoking the helper so that concurrent callers share a single invocation
+	credentialMu.Lock()
+	defer credentialMu.Unlock()
+
+	if token, ok := credentialCache[key]; ok {
+		return token, nil
+	}
+
+	token, err := runCredentialHelper(ctx, helper, provider, host)
+	if err != nil {
+		return "", err
+	}
+
+	credentialCache[key] = token
+
+	return token, nil
+}
+
+// runCredentialHelper invokes the credential helper using the git credential helper protocol: the helper is run
+// with the "get" argument and receives the protocol and host on stdin. The provider name is also exposed to the
+// helper through the BATCH_TOOL_PROVIDER environment variable. The helper is split into its arguments and run
+// directly rather than through a shell, expanding a leading ~/ to the home directory.
+func runCredentialHelper(ctx context.Context, helper, provider, host string) (string, error) {
+	args, err := utils.SplitCommand(helper)
+	if err != nil {
+		return "", fmt.Errorf("credential helper %q: %w", helper, err)
+	} else if len(args) == 0 {
+		return "", fmt.Errorf("credential helper %q: no command given", helper)
+	}
+
+	if rest, ok := strings.CutPrefix(args[0], "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			args[0] = filepath.Join(home, rest)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], "get")...)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Env = append(os.Environ(), "BATCH_TOOL_PROVIDER="+provider)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper %q failed: %w: %s", helper, err, strings.TrimSpace(stderr.String()))
+	}
+
+	token, err := parseCredential(out)
+	if err != nil {
+		return "", fmt.Errorf("credential helper %q: %w", helper, err)
+	}
+
+	return token, nil
+}
+
+// parseCredential extracts the token from the helper output. Output in the git credential format uses the
+// value of the "password" attribute, otherwise the output is expected to be the bare token.
+func parseCredential(out []byte) (string, error) {
+	var lines []string
+
+	for line := range strings.Lines(string(out)) {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		if token, ok := strings.CutPrefix(line, "password="); ok {
+			return token, nil
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 1 && !strings.Contains(lines[0], "=") {
+		return lines[0], nil
+	}
+
+	return "", errors.New("no token found in output")
+}
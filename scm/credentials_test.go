@@ -0,0 +1,156 @@
+package scm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// writeHelper writes a fake credential helper script which records each invocation to a log file.
+func writeHelper(t *testing.T, body string) (helper, log string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	helper = filepath.Join(dir, "helper.sh")
+	log = filepath.Join(dir, "invocations.log")
+
+	script := "#!/bin/sh\necho \"$1 $BATCH_TOOL_PROVIDER\" >> " + log + "\n" + body + "\n"
+	if err := os.WriteFile(helper, []byte(script), 0o700); err != nil {
+		t.Fatalf("Failed to write credential helper: %v", err)
+	}
+
+	return helper, log
+}
+
+func TestAuthTokenCredentialHelper(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		args    string
+		host    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "helper arguments",
+			body: "shift\necho \"$1\"",
+			args: `'with space' "$HOME;"`,
+			want: "$HOME;",
+		},
+		{
+			name: "git credential format",
+			body: "cat > /dev/null\nprintf 'protocol=https\\nhost=example.com\\nusername=bot\\npassword=helper-token\\n'",
+			want: "helper-token",
+		},
+		{
+			name: "bare token",
+			body: "echo helper-token",
+			want: "helper-token",
+		},
+		{
+			name: "host from stdin",
+			body: "sed -n 's/^host=//p'",
+			host: "stdin.example.com",
+			want: "stdin.example.com",
+		},
+		{
+			name:    "helper failure",
+			body:    "echo 'no credentials' >&2\nexit 1",
+			wantErr: "no credentials",
+		},
+		{
+			name:    "no token in output",
+			body:    "echo 'username=bot'",
+			wantErr: "no token found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			viper := config.Viper(ctx)
+
+			helper, _ := writeHelper(t, tt.body)
+			viper.Set(config.AuthToken, "")
+			viper.Set(config.CredentialHelper, strings.TrimSpace(helper+" "+tt.args))
+			viper.Set(config.GitProvider, "github")
+			viper.Set(config.GitHost, tt.host)
+
+			token, err := scm.AuthToken(ctx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token != tt.want {
+				t.Errorf("Expected token %q, got %q", tt.want, token)
+			}
+		})
+	}
+}
+
+func TestAuthTokenCredentialHelperCached(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	helper, log := writeHelper(t, "echo cached-token")
+	viper.Set(config.AuthToken, "")
+	viper.Set(config.CredentialHelper, helper)
+	viper.Set(config.GitProvider, "bitbucket")
+	viper.Set(config.GitHost, "cached.example.com")
+
+	for range 3 {
+		token, err := scm.AuthToken(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if token != "cached-token" {
+			t.Errorf("Expected token %q, got %q", "cached-token", token)
+		}
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Failed to read invocation log: %v", err)
+	}
+
+	// The helper is invoked once per run with the "get" action and the provider name
+	if got := strings.TrimSpace(string(data)); got != "get bitbucket" {
+		t.Errorf("Expected a single invocation %q, got %q", "get bitbucket", got)
+	}
+}
+
+func TestAuthTokenPrecedence(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	helper, log := writeHelper(t, "echo helper-token")
+	viper.Set(config.CredentialHelper, helper)
+	viper.Set(config.GitHost, "precedence.example.com")
+
+	// A token set directly takes precedence over the credential helper
+	viper.Set(config.AuthToken, "config-token")
+	if token, err := scm.AuthToken(ctx); err != nil || token != "config-token" {
+		t.Errorf("Expected configured token, got %q (err: %v)", token, err)
+	}
+
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		t.Error("Expected credential helper not to be invoked")
+	}
+
+	// Without either, no token is returned
+	viper.Set(config.AuthToken, "")
+	viper.Set(config.CredentialHelper, "")
+	if token, err := scm.AuthToken(ctx); err != nil || token != "" {
+		t.Errorf("Expected empty token, got %q (err: %v)", token, err)
+	}
+}
@@ -1,10 +1,13 @@
 package fake
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"maps"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/ryclarke/batch-tool/scm"
 )
@@ -20,9 +23,16 @@ func init() {
 type Fake struct {
 	Project      string
 	Repositories []*scm.Repository
-	PullRequests map[string]*scm.PullRequest // key: "repo:branch"
-	Errors       map[string]error            // configurable errors for testing
-	Capabilities *scm.Capabilities           // configurable capabilities for testing
+	PullRequests map[string]*scm.PullRequest  // key: "repo:branch"
+	Reviews      map[string]*scm.ReviewStatus // key: "repo:branch"
+	Behind       map[string]bool              // key: "repo:branch", branches out of date with their base
+	Pending      map[string]bool              // key: "repo:branch", pull requests waiting on their pipeline
+	Heads        map[string]string            // key: "repo:branch", SHA of the head commit of pull requests
+	Merged       map[string]string            // key: "repo:branch", merge method requested for merged pull requests
+	Errors       map[string]error             // configurable errors for testing
+	Capabilities *scm.Capabilities            // configurable capabilities for testing
+	User         string                       // login returned by CurrentUser
+	Missing      []string                     // permissions returned by MissingPermissions
 }
 
 // New creates a new fake SCM provider with the specified project
@@ -31,13 +41,24 @@ func New(_ context.Context, project string) scm.Provider {
 		Project:      project,
 		Repositories: make([]*scm.Repository, 0),
 		PullRequests: make(map[string]*scm.PullRequest),
+		Reviews:      make(map[string]*scm.ReviewStatus),
+		Behind:       make(map[string]bool),
+		Pending:      make(map[string]bool),
+		Heads:        make(map[string]string),
+		Merged:       make(map[string]string),
 		Errors:       make(map[string]error),
+		User:         "fake-user",
 		Capabilities: &scm.Capabilities{
 			TeamReviewers:  true,
 			ResetReviewers: true,
 			Draft:          true,
+			Assignees:      true,
+			Labels:         true,
+			CreateBase:     true,
+			AutoMerge:      true,
 			MergeMethods:   []string{"merge", "squash", "rebase"},
 			CheckMergeable: true,
+			UpdateBranch:   true,
 		},
 	}
 }
@@ -116,9 +137,13 @@ func (f *Fake) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
 			Title:         pr.Title,
 			Description:   pr.Description,
 			Branch:        pr.Branch,
+			BaseBranch:    pr.BaseBranch,
 			Repo:          pr.Repo,
 			Reviewers:     make([]string, 0, len(pr.Reviewers)),
 			TeamReviewers: make([]string, 0, len(pr.TeamReviewers)),
+			Assignees:     append([]string(nil), pr.Assignees...),
+			Labels:        append([]string(nil), pr.Labels...),
+			AutoMerge:     pr.AutoMerge,
 			Mergeable:     pr.Mergeable,
 			ID:            pr.ID,
 			Number:        pr.Number,
@@ -135,6 +160,37 @@ func (f *Fake) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
 	return nil, fmt.Errorf("pull request not found for %s:%s", repo, branch)
 }
 
+// GetPullRequestByNumber retrieves a pull request by repository name and number
+func (f *Fake) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	if err := f.Errors["GetPullRequestByNumber"]; err != nil {
+		return nil, err
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(f.PullRequests)) {
+		if pr := f.PullRequests[key]; strings.HasPrefix(key, repo+":") && pr.Number == number {
+			return copyPR(pr), nil
+		}
+	}
+
+	return nil, fmt.Errorf("pull request #%d not found for %s", number, repo)
+}
+
+// ListOpenPullRequests lists all pull requests in the specified repository, ordered by source branch
+func (f *Fake) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	if err := f.Errors["ListOpenPullRequests"]; err != nil {
+		return nil, err
+	}
+
+	result := make([]*scm.PullRequest, 0)
+	for _, key := range slices.Sorted(maps.Keys(f.PullRequests)) {
+		if strings.HasPrefix(key, repo+":") {
+			result = append(result, copyPR(f.PullRequests[key]))
+		}
+	}
+
+	return result, nil
+}
+
 // OpenPullRequest creates a new pull request
 func (f *Fake) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
 	if opts == nil {
@@ -148,24 +204,35 @@ func (f *Fake) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.P
 	key := fmt.Sprintf("%s:%s", repo, branch)
 
 	// Check if PR already exists
-	if _, exists := f.PullRequests[key]; exists {
-		return nil, fmt.Errorf("pull request already exists for %s:%s", repo, branch)
+	if existing, exists := f.PullRequests[key]; exists {
+		return nil, scm.ExistingPullRequestError(repo, branch, existing)
 	}
 
 	// Create new PR
 	prID := len(f.PullRequests) + 1
 	pr := &scm.PullRequest{
 		ID:            prID,
+		Number:        prID,
 		Version:       1,
 		Title:         opts.Title,
 		Description:   opts.Description,
 		Branch:        branch,
+		BaseBranch:    opts.BaseBranch,
 		Repo:          repo,
 		Reviewers:     opts.Reviewers,
 		TeamReviewers: opts.TeamReviewers,
+		Assignees:     opts.Assignees,
+		Labels:        opts.Labels,
 		Mergeable:     true, // Default to mergeable
 	}
 
+	if opts.AutoMerge {
+		pr.AutoMerge = opts.AutoMergeMethod
+		if pr.AutoMerge == "" {
+			pr.AutoMerge = "merge"
+		}
+	}
+
 	f.PullRequests[key] = pr
 
 	// Return a copy
@@ -231,6 +298,24 @@ func (f *Fake) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm
 		pr.TeamReviewers = uniqueTeamReviewers
 	}
 
+	// Add assignees, keeping any existing ones
+	for _, assignee := range opts.Assignees {
+		if !slices.Contains(pr.Assignees, assignee) {
+			pr.Assignees = append(pr.Assignees, assignee)
+		}
+	}
+
+	// Replace or add labels, keeping any existing ones unless resetting
+	if opts.ResetLabels {
+		pr.Labels = append([]string(nil), opts.Labels...)
+	} else {
+		for _, label := range opts.Labels {
+			if !slices.Contains(pr.Labels, label) {
+				pr.Labels = append(pr.Labels, label)
+			}
+		}
+	}
+
 	// Return a copy
 	return copyPR(pr), nil
 }
@@ -252,15 +337,115 @@ func (f *Fake) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (
 		return nil, fmt.Errorf("pull request not found for %s:%s", repo, branch)
 	}
 
+	// Report mergeability without merging for a dry run
+	if opts.DryRun {
+		checked := copyPR(pr)
+
+		switch {
+		case !pr.Mergeable:
+			checked.MergeBlocker = "not mergeable (conflicts, required checks failing, etc)"
+		case f.Behind[key] && !opts.UpdateBranch:
+			checked.MergeBlocker = "behind its base branch"
+		}
+
+		return checked, nil
+	}
+
 	// Check mergeability if check flag is enabled
 	if opts.CheckMergeable && !pr.Mergeable {
 		return nil, fmt.Errorf("pull request for %s:%s is not mergeable (conflicts, required checks failing, etc)", repo, branch)
 	}
 
+	// Like GitLab, a checked pull request which is only waiting on its pipeline is scheduled to merge instead
+	if opts.CheckMergeable && f.Pending[key] {
+		scheduled := copyPR(pr)
+		scheduled.AutoMerge = cmp.Or(opts.Method, "merge")
+
+		return scheduled, nil
+	}
+
+	// Bring the branch up to date with its base if requested, otherwise it can't be merged
+	if f.Behind[key] {
+		if !opts.UpdateBranch {
+			return nil, fmt.Errorf("pull request for %s:%s is behind its base branch", repo, branch)
+		}
+
+		if err := f.Errors["UpdateBranch"]; err != nil {
+			return nil, err
+		}
+
+		delete(f.Behind, key)
+		pr.Version++
+	}
+
 	delete(f.PullRequests, key)
+	delete(f.Reviews, key)
+	delete(f.Behind, key)
 
-	// Return a copy
-	return copyPR(pr), nil
+	f.Merged[key] = opts.Method
+
+	// Return a copy, with a deterministic merge commit
+	merged := copyPR(pr)
+	merged.MergeCommit = fmt.Sprintf("merge-%d", pr.Number)
+
+	return merged, nil
+}
+
+// GetPullRequestHeadSHA retrieves the configured SHA of the head commit of a pull request, which is empty unless set
+func (f *Fake) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	if err := f.Errors["GetPullRequestHeadSHA"]; err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s:%s", repo, branch)
+	if _, exists := f.PullRequests[key]; !exists {
+		return "", fmt.Errorf("pull request not found for %s:%s", repo, branch)
+	}
+
+	return f.Heads[key], nil
+}
+
+// GetReviewStatus retrieves the approval state of a pull request. Pull requests without
+// any configured reviews require a single approval and have none.
+func (f *Fake) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	if err := f.Errors["GetReviewStatus"]; err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s:%s", repo, branch)
+	if _, exists := f.PullRequests[key]; !exists {
+		return nil, fmt.Errorf("pull request not found for %s:%s", repo, branch)
+	}
+
+	status, exists := f.Reviews[key]
+	if !exists {
+		return &scm.ReviewStatus{RequiredApprovals: 1}, nil
+	}
+
+	// Return a copy to prevent mutations
+	return &scm.ReviewStatus{
+		Approvers:         append([]string(nil), status.Approvers...),
+		ChangesRequested:  append([]string(nil), status.ChangesRequested...),
+		RequiredApprovals: status.RequiredApprovals,
+	}, nil
+}
+
+// CurrentUser returns the configured login of the authenticated user
+func (f *Fake) CurrentUser() (string, error) {
+	if err := f.Errors["CurrentUser"]; err != nil {
+		return "", err
+	}
+
+	return f.User, nil
+}
+
+// MissingPermissions returns the configured permissions which the token lacks
+func (f *Fake) MissingPermissions(_ string, _ bool) ([]string, error) {
+	if err := f.Errors["MissingPermissions"]; err != nil {
+		return nil, err
+	}
+
+	return f.Missing, nil
 }
 
 // Test helper methods for configuring the fake provider
@@ -311,6 +496,36 @@ func (f *Fake) SetPRMergeable(repo, branch string, mergeable bool) error {
 	return nil
 }
 
+// SetPRBehind sets whether the branch of a pull request is out of date with its base for testing
+func (f *Fake) SetPRBehind(repo, branch string, behind bool) error {
+	key := fmt.Sprintf("%s:%s", repo, branch)
+	if _, exists := f.PullRequests[key]; !exists {
+		return fmt.Errorf("pull request not found for %s:%s", repo, branch)
+	}
+	f.Behind[key] = behind
+	return nil
+}
+
+// SetPRHead sets the SHA of the head commit of a pull request for testing
+func (f *Fake) SetPRHead(repo, branch, sha string) error {
+	key := fmt.Sprintf("%s:%s", repo, branch)
+	if _, exists := f.PullRequests[key]; !exists {
+		return fmt.Errorf("pull request not found for %s:%s", repo, branch)
+	}
+	f.Heads[key] = sha
+	return nil
+}
+
+// SetPRReviewStatus sets the approval state of a pull request for testing
+func (f *Fake) SetPRReviewStatus(repo, branch string, status *scm.ReviewStatus) error {
+	key := fmt.Sprintf("%s:%s", repo, branch)
+	if _, exists := f.PullRequests[key]; !exists {
+		return fmt.Errorf("pull request not found for %s:%s", repo, branch)
+	}
+	f.Reviews[key] = status
+	return nil
+}
+
 // GetRepositoryCount returns the number of repositories in the fake provider
 func (f *Fake) GetRepositoryCount() int {
 	return len(f.Repositories)
@@ -332,6 +547,9 @@ func (f *Fake) HasPullRequest(repo, branch string) bool {
 func (f *Fake) Clear() {
 	f.Repositories = make([]*scm.Repository, 0)
 	f.PullRequests = make(map[string]*scm.PullRequest)
+	f.Reviews = make(map[string]*scm.ReviewStatus)
+	f.Behind = make(map[string]bool)
+	f.Merged = make(map[string]string)
 	f.Errors = make(map[string]error)
 }
 
@@ -446,9 +664,13 @@ func copyPR(pr *scm.PullRequest) *scm.PullRequest {
 		Title:         pr.Title,
 		Description:   pr.Description,
 		Branch:        pr.Branch,
+		BaseBranch:    pr.BaseBranch,
 		Repo:          pr.Repo,
 		Reviewers:     make([]string, 0, len(pr.Reviewers)),
 		TeamReviewers: make([]string, 0, len(pr.TeamReviewers)),
+		Assignees:     append([]string(nil), pr.Assignees...),
+		Labels:        append([]string(nil), pr.Labels...),
+		AutoMerge:     pr.AutoMerge,
 		ID:            pr.ID,
 		Number:        pr.Number,
 		Version:       pr.Version,
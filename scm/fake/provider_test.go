@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/ryclarke/batch-tool/scm"
@@ -189,6 +191,77 @@ func TestGetPullRequestNotFound(t *testing.T) {
 	}
 }
 
+func TestGetPullRequestByNumber(t *testing.T) {
+	testRepos := CreateTestRepositories("test-project")
+	f := NewFake("test-project", testRepos)
+
+	first, err := f.OpenPullRequest("repo-1", "feature-a", &scm.PROptions{Title: "First PR"})
+	if err != nil {
+		t.Fatalf("Failed to open pull request: %v", err)
+	}
+
+	second, err := f.OpenPullRequest("repo-2", "feature-b", &scm.PROptions{Title: "Second PR"})
+	if err != nil {
+		t.Fatalf("Failed to open pull request: %v", err)
+	}
+
+	retrievedPR, err := f.GetPullRequestByNumber("repo-1", first.Number)
+	if err != nil {
+		t.Fatalf("Failed to get pull request: %v", err)
+	}
+
+	if retrievedPR.Branch != "feature-a" || retrievedPR.Title != "First PR" {
+		t.Errorf("Expected PR for feature-a, got %s (%s)", retrievedPR.Branch, retrievedPR.Title)
+	}
+
+	// the number of a pull request in another repository doesn't match
+	if _, err := f.GetPullRequestByNumber("repo-1", second.Number); err == nil {
+		t.Error("Expected error when getting a pull request from another repository")
+	}
+}
+
+func TestGetPullRequestByNumberNotFound(t *testing.T) {
+	testRepos := CreateTestRepositories("test-project")
+	f := NewFake("test-project", testRepos)
+
+	_, err := f.GetPullRequestByNumber("repo-1", 42)
+	if err == nil || !strings.Contains(err.Error(), "pull request #42 not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	testRepos := CreateTestRepositories("test-project")
+	f := NewFake("test-project", testRepos)
+
+	for _, pr := range []struct{ repo, branch string }{
+		{"repo-1", "feature-b"},
+		{"repo-1", "feature-a"},
+		{"repo-2", "feature-c"},
+	} {
+		if _, err := f.OpenPullRequest(pr.repo, pr.branch, &scm.PROptions{Title: pr.branch}); err != nil {
+			t.Fatalf("Failed to open pull request: %v", err)
+		}
+	}
+
+	prs, err := f.ListOpenPullRequests("repo-1")
+	if err != nil {
+		t.Fatalf("Failed to list pull requests: %v", err)
+	}
+
+	if len(prs) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(prs))
+	}
+
+	if prs[0].Branch != "feature-a" || prs[1].Branch != "feature-b" {
+		t.Errorf("Expected pull requests ordered by branch, got %s and %s", prs[0].Branch, prs[1].Branch)
+	}
+
+	if prs, err = f.ListOpenPullRequests("repo-3"); err != nil || len(prs) != 0 {
+		t.Errorf("Expected no pull requests for repo-3, got %v (err: %v)", prs, err)
+	}
+}
+
 func TestUpdatePullRequest(t *testing.T) {
 	testRepos := CreateTestRepositories("test-project")
 	f := NewFake("test-project", testRepos)
@@ -433,6 +506,45 @@ func TestMergePullRequestForceBypassesChecks(t *testing.T) {
 	}
 }
 
+// TestMergePullRequestUpdateBranch tests that a branch which is behind must be updated before merging
+func TestMergePullRequestUpdateBranch(t *testing.T) {
+	testRepos := CreateTestRepositories("test-project")
+	f := NewFake("test-project", testRepos)
+
+	_, err := f.OpenPullRequest("repo-1", "stale-branch", &scm.PROptions{Title: "Stale PR", Description: "Behind main"})
+	if err != nil {
+		t.Fatalf("Failed to open pull request: %v", err)
+	}
+
+	if err = f.SetPRBehind("repo-1", "stale-branch", true); err != nil {
+		t.Fatalf("Failed to set PR behind status: %v", err)
+	}
+
+	// Without updating the branch, merge should fail
+	_, err = f.MergePullRequest("repo-1", "stale-branch", &scm.PRMergeOptions{CheckMergeable: true})
+	if err == nil || !contains(err.Error(), "is behind its base branch") {
+		t.Fatalf("Expected behind error, got: %v", err)
+	}
+
+	if !f.HasPullRequest("repo-1", "stale-branch") {
+		t.Fatal("Expected PR to still exist after failed merge")
+	}
+
+	// With the update, the branch is brought up to date and then merged
+	mergedPR, err := f.MergePullRequest("repo-1", "stale-branch", &scm.PRMergeOptions{CheckMergeable: true, UpdateBranch: true})
+	if err != nil {
+		t.Fatalf("Expected merge with update to succeed: %v", err)
+	}
+
+	if mergedPR.Version != 2 {
+		t.Errorf("Expected the branch update to bump the PR version to 2, got %d", mergedPR.Version)
+	}
+
+	if f.HasPullRequest("repo-1", "stale-branch") || f.Behind["repo-1:stale-branch"] {
+		t.Error("Expected PR to be merged")
+	}
+}
+
 // TestSetPRMergeableNotFound tests error handling when PR doesn't exist
 func TestSetPRMergeableNotFound(t *testing.T) {
 	testRepos := CreateTestRepositories("test-project")
@@ -861,3 +973,70 @@ func TestGetPullRequestWithTeamReviewers(t *testing.T) {
 		t.Errorf("TeamReviewers: got %v, want [org/team1]", pr.TeamReviewers)
 	}
 }
+
+func TestFakeGetReviewStatus(t *testing.T) {
+	f := NewFake("test-project", []*scm.Repository{{Name: "repo-1"}})
+
+	if _, err := f.GetReviewStatus("repo-1", "test-branch"); err == nil {
+		t.Error("Expected error for missing pull request")
+	}
+
+	if _, err := f.OpenPullRequest("repo-1", "test-branch", &scm.PROptions{Title: "Test PR"}); err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+
+	// Pull requests without configured reviews are unapproved
+	status, err := f.GetReviewStatus("repo-1", "test-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status.Approved() || status.RequiredApprovals != 1 {
+		t.Errorf("Expected default status to require one approval, got %+v", status)
+	}
+
+	if err = f.SetPRReviewStatus("repo-1", "test-branch", &scm.ReviewStatus{Approvers: []string{"alice"}, RequiredApprovals: 1}); err != nil {
+		t.Fatalf("Failed to set review status: %v", err)
+	}
+
+	if status, err = f.GetReviewStatus("repo-1", "test-branch"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !status.Approved() {
+		t.Errorf("Expected pull request to be approved, got %+v", status)
+	}
+
+	// Configured errors are returned
+	f.SetError("GetReviewStatus", errors.New("review error"))
+	if _, err = f.GetReviewStatus("repo-1", "test-branch"); err == nil {
+		t.Error("Expected configured error")
+	}
+}
+
+func TestFakeCurrentUserAndAssignees(t *testing.T) {
+	f := NewFake("test-project", []*scm.Repository{{Name: "repo-1"}})
+	f.User = "octocat"
+
+	user, err := f.CurrentUser()
+	if err != nil || user != "octocat" {
+		t.Fatalf("Expected current user octocat, got %q (%v)", user, err)
+	}
+
+	if _, err = f.OpenPullRequest("repo-1", "test-branch", &scm.PROptions{Title: "Test PR", Assignees: []string{user}}); err != nil {
+		t.Fatalf("Failed to create pull request: %v", err)
+	}
+
+	// Updating keeps existing assignees without duplicating them
+	pr, err := f.UpdatePullRequest("repo-1", "test-branch", &scm.PROptions{Title: "Test PR", Assignees: []string{"octocat", "hubot"}})
+	if err != nil {
+		t.Fatalf("Failed to update pull request: %v", err)
+	}
+
+	if want := []string{"octocat", "hubot"}; !slices.Equal(pr.Assignees, want) {
+		t.Errorf("Expected assignees %v, got %v", want, pr.Assignees)
+	}
+
+	f.SetError("CurrentUser", errors.New("user error"))
+	if _, err = f.CurrentUser(); err == nil {
+		t.Error("Expected configured error")
+	}
+}
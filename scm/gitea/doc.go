@@ -0,0 +1,13 @@
+// Copyright 2018-2026 Ryan Clarke (ryclarke-github@rkc.aleeas.com)
+//
+// Licensed under the Apache License, Version 2.0
+
+/*
+Package gitea implements the scm.Provider contract against the Gitea API.
+
+It translates provider-neutral repository and pull-request operations into
+Gitea's GitHub-like v1 REST API and maps responses back to scm models consumed
+by the rest of the application. The instance is reached through the configured
+base URL, falling back on the configured git host.
+*/
+package gitea
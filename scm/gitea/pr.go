@@ -0,0 +1,477 @@
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// mergeStyles maps the provider-neutral merge methods to Gitea's merge styles.
+var mergeStyles = map[string]string{
+	"merge":  "merge",
+	"squash": "squash",
+	"rebase": "rebase",
+}
+
+// GetPullRequest retrieves a pull request by repository name and source branch.
+func (g *Gitea) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	resp, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestByNumber retrieves a pull request by repository name and number.
+func (g *Gitea) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := get[prResp](g, g.repoURL(repo, nil, "pulls", strconv.Itoa(number)))
+	if err != nil {
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("no pull request #%d found in repository %s", number, repo)
+		}
+
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request by repository name and source branch.
+func (g *Gitea) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	resp, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Head.Sha, nil
+}
+
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (g *Gitea) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	resp, err := g.listPullRequests(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.PullRequest, len(resp))
+	for i, pr := range resp {
+		output[i] = parsePR(pr)
+	}
+
+	return output, nil
+}
+
+// OpenPullRequest opens a new pull request in the specified repository.
+func (g *Gitea) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	// reads are less restrictive than a failed write, so check for existing PR first
+	if existing, err := g.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parsePR(existing))
+	}
+
+	// if title is not specified, use the branch name
+	if opts.Title == "" {
+		opts.Title = branch
+	}
+
+	// use provided base branch or fall back to configured default
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = config.Viper(g.ctx).GetString(config.DefaultBranch)
+	}
+
+	payload := &prPayload{
+		Head:      branch,
+		Base:      baseBranch,
+		Title:     opts.Title,
+		Body:      opts.Description,
+		Assignees: opts.Assignees,
+	}
+
+	pr, err := send[prResp](g, http.MethodPost, g.repoURL(repo, nil, "pulls"), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	opts.ResetReviewers = false // suppress ResetReviewers when opening a new PR
+	if pr, err = g.applyReviewers(repo, pr, opts); err != nil {
+		return nil, err
+	}
+
+	return parsePR(pr), nil
+}
+
+// UpdatePullRequest updates an existing pull request.
+func (g *Gitea) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	if opts.Draft != nil {
+		return nil, fmt.Errorf("updating draft status is not currently supported by the Gitea provider")
+	}
+
+	pr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, changed := processChanges(pr, opts); changed {
+		if pr, err = send[prResp](g, http.MethodPatch, g.repoURL(repo, nil, "pulls", strconv.Itoa(pr.Number)), payload); err != nil {
+			return nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+	}
+
+	// if there are reviewer changes, apply them regardless of whether other changes were made
+	if pr, err = g.applyReviewers(repo, pr, opts); err != nil {
+		return nil, err
+	}
+
+	return parsePR(pr), nil
+}
+
+// MergePullRequest merges an existing pull request.
+func (g *Gitea) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PRMergeOptions{} // default options
+	}
+
+	pr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		checked := parsePR(pr)
+		if !pr.Mergeable {
+			checked.MergeBlocker = "not mergeable"
+		}
+
+		return checked, nil
+	}
+
+	if opts.CheckMergeable && !pr.Mergeable {
+		return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable", branch, pr.Number, repo)
+	}
+
+	// if no merge method specified, use the default from config (if set)
+	method := opts.Method
+	if method == "" {
+		method = config.Viper(g.ctx).GetString(config.DefaultMergeMethod)
+	}
+
+	style := "merge" // Gitea requires a merge style, so default to a merge commit
+	if method != "" {
+		var ok bool
+		if style, ok = mergeStyles[method]; !ok {
+			return nil, fmt.Errorf("merge method %q is not supported by the Gitea provider", method)
+		}
+	}
+
+	payload := &mergePayload{Do: style}
+	if err := sendNoContent(g, http.MethodPost, g.repoURL(repo, nil, "pulls", strconv.Itoa(pr.Number), "merge"), payload); err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	return parsePR(pr), nil
+}
+
+// GetReviewStatus retrieves the approval state of a pull request, using the protection
+// rules of its base branch to determine the number of required approvals.
+func (g *Gitea) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	pr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := g.requiredApprovals(repo, pr.Base.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := list[reviewResp](g, nil, "repos", g.project, repo, "pulls", strconv.Itoa(pr.Number), "reviews")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+
+	return parseReviewStatus(reviews, required), nil
+}
+
+func (g *Gitea) getPullRequest(repo, branch string) (*prResp, error) {
+	prs, err := g.listPullRequests(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gitea can't filter pull requests by source branch, so search the open pull requests for it
+	for _, pr := range prs {
+		if pr.Head.Ref == branch {
+			return pr, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+}
+
+func (g *Gitea) listPullRequests(repo string) ([]*prResp, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "open")
+
+	prs, err := list[*prResp](g, queryParams, "repos", g.project, repo, "pulls")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	return prs, nil
+}
+
+// applyReviewers applies the specified reviewers and team reviewers to the given pull request. If ResetReviewers
+// is set, reviewers which are not in the provided lists are removed, otherwise they are added to the existing ones.
+func (g *Gitea) applyReviewers(repo string, pr *prResp, opts *scm.PROptions) (*prResp, error) {
+	if len(opts.Reviewers) == 0 && len(opts.TeamReviewers) == 0 {
+		return pr, nil
+	}
+
+	add := &reviewersPayload{Reviewers: opts.Reviewers, TeamReviewers: opts.TeamReviewers}
+	remove := &reviewersPayload{}
+
+	if opts.ResetReviewers {
+		if len(opts.Reviewers) > 0 {
+			add.Reviewers, remove.Reviewers = scm.DiffReviewers(pr.reviewers(), opts.Reviewers, true)
+		}
+
+		if len(opts.TeamReviewers) > 0 {
+			add.TeamReviewers, remove.TeamReviewers = scm.DiffReviewers(pr.teamReviewers(), opts.TeamReviewers, true)
+		}
+	}
+
+	path := g.repoURL(repo, nil, "pulls", strconv.Itoa(pr.Number), "requested_reviewers")
+
+	if !remove.empty() {
+		if err := sendNoContent(g, http.MethodDelete, path, remove); err != nil {
+			return nil, fmt.Errorf("failed to remove reviewers: %w", err)
+		}
+	}
+
+	if !add.empty() {
+		if err := sendNoContent(g, http.MethodPost, path, add); err != nil {
+			return nil, fmt.Errorf("failed to request reviewers: %w", err)
+		}
+	}
+
+	// Refresh PR to get updated reviewer list
+	refreshed, err := get[prResp](g, g.repoURL(repo, nil, "pulls", strconv.Itoa(pr.Number)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return refreshed, nil
+}
+
+// requiredApprovals returns the number of approving reviews required by the protection rules of the given base
+// branch. If the branch is unprotected or its protection rules cannot be read, a single approval is required.
+func (g *Gitea) requiredApprovals(repo, branch string) (int, error) {
+	protection, err := get[branchProtectionResp](g, g.repoURL(repo, nil, "branch_protections", branch))
+	if err != nil {
+		// reading protection rules requires admin access, so treat inaccessible rules as unprotected
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden) {
+			return 1, nil
+		}
+
+		return 0, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+
+	// always require at least one approval, even if the protection rules don't
+	return max(1, protection.RequiredApprovals), nil
+}
+
+// processChanges builds the edit payload for the requested title, description and assignee changes.
+func processChanges(pr *prResp, opts *scm.PROptions) (payload *prPayload, changed bool) {
+	payload = &prPayload{}
+
+	if opts.Title != "" {
+		payload.Title = opts.Title
+		changed = true
+	}
+
+	if opts.Description != "" {
+		payload.Body = opts.Description
+		changed = true
+	}
+
+	// Gitea replaces the assignees on edit, so keep any existing assignees
+	if len(opts.Assignees) > 0 {
+		payload.Assignees = pr.assignees()
+		for _, assignee := range opts.Assignees {
+			if !slices.Contains(payload.Assignees, assignee) {
+				payload.Assignees = append(payload.Assignees, assignee)
+			}
+		}
+
+		changed = true
+	}
+
+	return payload, changed
+}
+
+// parseReviewStatus reduces the submitted reviews to the latest decisive review from each reviewer.
+func parseReviewStatus(reviews []reviewResp, required int) *scm.ReviewStatus {
+	latest := make(map[string]string)
+	order := make([]string, 0)
+
+	for _, review := range reviews {
+		state := review.State
+		if review.Dismissed {
+			state = "DISMISSED"
+		}
+
+		if state != "APPROVED" && state != "REQUEST_CHANGES" && state != "DISMISSED" {
+			continue // comments and pending reviews don't change a reviewer's decision
+		}
+
+		login := review.User.Login
+		if _, exists := latest[login]; !exists {
+			order = append(order, login)
+		}
+
+		latest[login] = state
+	}
+
+	status := &scm.ReviewStatus{RequiredApprovals: required}
+
+	for _, login := range order {
+		switch latest[login] {
+		case "APPROVED":
+			status.Approvers = append(status.Approvers, login)
+		case "REQUEST_CHANGES":
+			status.ChangesRequested = append(status.ChangesRequested, login)
+		}
+	}
+
+	return status
+}
+
+type prResp struct {
+	ID        int64  `json:"id"`
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Mergeable bool   `json:"mergeable"`
+	HTMLURL   string `json:"html_url"`
+
+	Head prBranch `json:"head"`
+	Base prBranch `json:"base"`
+
+	RequestedReviewers      []userResp `json:"requested_reviewers"`
+	RequestedReviewersTeams []teamResp `json:"requested_reviewers_teams"`
+	Assignees               []userResp `json:"assignees"`
+}
+
+type prBranch struct {
+	Ref  string    `json:"ref"`
+	Sha  string    `json:"sha"`
+	Repo *repoResp `json:"repo"`
+}
+
+type teamResp struct {
+	Name string `json:"name"`
+}
+
+type prPayload struct {
+	Head      string   `json:"head,omitempty"`
+	Base      string   `json:"base,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	Body      string   `json:"body,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+type reviewersPayload struct {
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"team_reviewers,omitempty"`
+}
+
+type mergePayload struct {
+	Do string `json:"Do"`
+}
+
+type reviewResp struct {
+	State     string   `json:"state"`
+	Dismissed bool     `json:"dismissed"`
+	User      userResp `json:"user"`
+}
+
+type branchProtectionResp struct {
+	RequiredApprovals int `json:"required_approvals"`
+}
+
+func (p *reviewersPayload) empty() bool {
+	return len(p.Reviewers) == 0 && len(p.TeamReviewers) == 0
+}
+
+// reviewers returns the logins of the users requested to review the pull request.
+func (pr *prResp) reviewers() []string {
+	output := make([]string, len(pr.RequestedReviewers))
+	for i, user := range pr.RequestedReviewers {
+		output[i] = user.Login
+	}
+
+	return output
+}
+
+// teamReviewers returns the names of the teams requested to review the pull request.
+func (pr *prResp) teamReviewers() []string {
+	output := make([]string, len(pr.RequestedReviewersTeams))
+	for i, team := range pr.RequestedReviewersTeams {
+		output[i] = team.Name
+	}
+
+	return output
+}
+
+// assignees returns the logins of the users assigned to the pull request.
+func (pr *prResp) assignees() []string {
+	output := make([]string, len(pr.Assignees))
+	for i, user := range pr.Assignees {
+		output[i] = user.Login
+	}
+
+	return output
+}
+
+func parsePR(resp *prResp) *scm.PullRequest {
+	pr := &scm.PullRequest{
+		ID:        int(resp.ID),
+		Number:    resp.Number,
+		Mergeable: resp.Mergeable,
+
+		Title:         resp.Title,
+		Description:   resp.Body,
+		URL:           resp.HTMLURL,
+		Branch:        resp.Head.Ref,
+		BaseBranch:    resp.Base.Ref,
+		Reviewers:     resp.reviewers(),
+		TeamReviewers: resp.teamReviewers(),
+	}
+
+	if assignees := resp.assignees(); len(assignees) > 0 {
+		pr.Assignees = assignees
+	}
+
+	if resp.Base.Repo != nil {
+		pr.Repo = resp.Base.Repo.Name
+	}
+
+	return pr
+}
@@ -0,0 +1,393 @@
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+const pullsPath = "/api/v1/repos/test-org/test-repo/pulls"
+
+// mockGiteaPR creates a Gitea pull request API response
+func mockGiteaPR(number int, branch, title string, reviewers ...string) map[string]any {
+	requested := make([]map[string]any, len(reviewers))
+	for i, reviewer := range reviewers {
+		requested[i] = map[string]any{"login": reviewer}
+	}
+
+	return map[string]any{
+		"id":                  number + 1000,
+		"number":              number,
+		"title":               title,
+		"body":                "PR description",
+		"mergeable":           true,
+		"head":                map[string]any{"ref": branch},
+		"base":                map[string]any{"ref": "main", "repo": map[string]any{"name": "test-repo"}},
+		"requested_reviewers": requested,
+	}
+}
+
+// decodeBody decodes the JSON request body into a map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+
+	return body
+}
+
+// joinValues joins a decoded JSON list of strings for comparison.
+func joinValues(v any) string {
+	list, _ := v.([]any)
+
+	values := make([]string, len(list))
+	for i, item := range list {
+		values[i], _ = item.(string)
+	}
+
+	return strings.Join(values, ",")
+}
+
+func TestGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.Path, pullsPath)
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "open")
+
+		writeJSON(t, w, []map[string]any{
+			mockGiteaPR(1, "other-branch", "Other PR"),
+			mockGiteaPR(2, "feature-branch", "Test PR", "alice", "bob"),
+		})
+	}))
+	defer server.Close()
+
+	pr, err := newTestGitea(t, server).GetPullRequest("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.ID, 1002)
+	testhelper.AssertEqual(t, pr.Number, 2)
+	testhelper.AssertEqual(t, pr.Title, "Test PR")
+	testhelper.AssertEqual(t, pr.Description, "PR description")
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, pr.Repo, "test-repo")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestGetPullRequest_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{mockGiteaPR(1, "other-branch", "Other PR")})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).GetPullRequest("test-repo", "feature-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no open pull request found for branch feature-branch")
+}
+
+func TestGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.Path, pullsPath+"/2")
+
+		writeJSON(t, w, mockGiteaPR(2, "feature-branch", "Test PR", "alice"))
+	}))
+	defer server.Close()
+
+	pr, err := newTestGitea(t, server).GetPullRequestByNumber("test-repo", 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 2)
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice")
+}
+
+func TestGetPullRequestByNumber_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).GetPullRequestByNumber("test-repo", 99)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no pull request #99 found in repository test-repo")
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{
+			mockGiteaPR(1, "branch-1", "First PR"),
+			mockGiteaPR(2, "branch-2", "Second PR"),
+		})
+	}))
+	defer server.Close()
+
+	prs, err := newTestGitea(t, server).ListOpenPullRequests("test-repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, prs, 2)
+	testhelper.AssertEqual(t, prs[1].Branch, "branch-2")
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	var created, requested bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{})
+	})
+	mux.HandleFunc("POST "+pullsPath, func(w http.ResponseWriter, r *http.Request) {
+		created = true
+
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["head"], "feature-branch")
+		testhelper.AssertEqual(t, body["base"], "main")
+		testhelper.AssertEqual(t, body["title"], "New PR")
+		testhelper.AssertEqual(t, body["body"], "Description")
+		testhelper.AssertEqual(t, joinValues(body["assignees"]), "carol")
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, mockGiteaPR(7, "feature-branch", "New PR"))
+	})
+	mux.HandleFunc("POST "+pullsPath+"/7/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, joinValues(body["reviewers"]), "alice")
+		testhelper.AssertEqual(t, joinValues(body["team_reviewers"]), "backend")
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, []map[string]any{})
+	})
+	mux.HandleFunc("GET "+pullsPath+"/7", func(w http.ResponseWriter, _ *http.Request) {
+		pr := mockGiteaPR(7, "feature-branch", "New PR", "alice")
+		pr["requested_reviewers_teams"] = []map[string]any{{"name": "backend"}}
+		writeJSON(t, w, pr)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pr, err := newTestGitea(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:         "New PR",
+		Description:   "Description",
+		Reviewers:     []string{"alice"},
+		TeamReviewers: []string{"backend"},
+		Assignees:     []string{"carol"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, created, true)
+	testhelper.AssertEqual(t, requested, true)
+	testhelper.AssertEqual(t, pr.Number, 7)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice")
+	testhelper.AssertEqual(t, strings.Join(pr.TeamReviewers, ","), "backend")
+}
+
+func TestOpenPullRequest_AlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+
+		writeJSON(t, w, []map[string]any{mockGiteaPR(1, "feature-branch", "Existing PR")})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).OpenPullRequest("test-repo", "feature-branch", nil)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "already exists")
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	var removed, added bool
+
+	pr := mockGiteaPR(3, "feature-branch", "Old Title", "alice", "bob")
+	pr["assignees"] = []map[string]any{{"login": "carol"}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{pr})
+	})
+	mux.HandleFunc("PATCH "+pullsPath+"/3", func(w http.ResponseWriter, r *http.Request) {
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["title"], "New Title")
+		testhelper.AssertEqual(t, body["body"], nil)
+		testhelper.AssertEqual(t, joinValues(body["assignees"]), "carol,dave")
+
+		updated := mockGiteaPR(3, "feature-branch", "New Title", "alice", "bob")
+		writeJSON(t, w, updated)
+	})
+	mux.HandleFunc("DELETE "+pullsPath+"/3/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		removed = true
+		testhelper.AssertEqual(t, joinValues(decodeBody(t, r)["reviewers"]), "alice")
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST "+pullsPath+"/3/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		added = true
+		testhelper.AssertEqual(t, joinValues(decodeBody(t, r)["reviewers"]), "erin")
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, []map[string]any{})
+	})
+	mux.HandleFunc("GET "+pullsPath+"/3", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, mockGiteaPR(3, "feature-branch", "New Title", "bob", "erin"))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	result, err := newTestGitea(t, server).UpdatePullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:          "New Title",
+		Reviewers:      []string{"bob", "erin"},
+		ResetReviewers: true,
+		Assignees:      []string{"carol", "dave"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, removed, true)
+	testhelper.AssertEqual(t, added, true)
+	testhelper.AssertEqual(t, result.Title, "New Title")
+	testhelper.AssertEqual(t, strings.Join(result.Reviewers, ","), "bob,erin")
+}
+
+func TestUpdatePullRequest_Draft(t *testing.T) {
+	_, err := (&Gitea{}).UpdatePullRequest("test-repo", "feature-branch", &scm.PROptions{Draft: new(bool)})
+	testhelper.AssertError(t, err, true)
+}
+
+func TestMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		defaultMethod string
+		wantStyle     string
+		wantErr       bool
+	}{
+		{name: "no method", wantStyle: "merge"},
+		{name: "configured default", defaultMethod: "squash", wantStyle: "squash"},
+		{name: "squash", method: "squash", wantStyle: "squash"},
+		{name: "rebase", method: "rebase", defaultMethod: "squash", wantStyle: "rebase"},
+		{name: "unsupported", method: "fast-forward", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var style any
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+				writeJSON(t, w, []map[string]any{mockGiteaPR(5, "feature-branch", "PR to Merge")})
+			})
+			mux.HandleFunc("POST "+pullsPath+"/5/merge", func(w http.ResponseWriter, r *http.Request) {
+				style = decodeBody(t, r)["Do"]
+				w.WriteHeader(http.StatusOK) // Gitea responds to a merge with an empty body
+			})
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			g := newTestGitea(t, server)
+			config.Viper(g.ctx).Set(config.DefaultMergeMethod, tt.defaultMethod)
+
+			pr, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{Method: tt.method, CheckMergeable: true})
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if !tt.wantErr {
+				testhelper.AssertEqual(t, style, tt.wantStyle)
+				testhelper.AssertEqual(t, pr.Number, 5)
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_NotMergeable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+
+		pr := mockGiteaPR(5, "feature-branch", "Conflicting PR")
+		pr["mergeable"] = false
+		writeJSON(t, w, []map[string]any{pr})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "is not mergeable")
+}
+
+func TestGetReviewStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{mockGiteaPR(4, "feature-branch", "Reviewed PR")})
+	})
+	mux.HandleFunc("GET /api/v1/repos/test-org/test-repo/branch_protections/main", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, map[string]any{"required_approvals": 2})
+	})
+	mux.HandleFunc("GET "+pullsPath+"/4/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{
+			{"state": "REQUEST_CHANGES", "user": map[string]any{"login": "alice"}},
+			{"state": "APPROVED", "user": map[string]any{"login": "alice"}},
+			{"state": "COMMENT", "user": map[string]any{"login": "bob"}},
+			{"state": "REQUEST_CHANGES", "user": map[string]any{"login": "carol"}},
+			{"state": "APPROVED", "dismissed": true, "user": map[string]any{"login": "dave"}},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	status, err := newTestGitea(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 2)
+	testhelper.AssertEqual(t, strings.Join(status.Approvers, ","), "alice")
+	testhelper.AssertEqual(t, strings.Join(status.ChangesRequested, ","), "carol")
+	testhelper.AssertEqual(t, status.Approved(), false)
+}
+
+func TestGetReviewStatus_Unprotected(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{mockGiteaPR(4, "feature-branch", "Reviewed PR")})
+	})
+	mux.HandleFunc("GET /api/v1/repos/test-org/test-repo/branch_protections/main", http.NotFound)
+	mux.HandleFunc("GET "+pullsPath+"/4/reviews", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{{"state": "APPROVED", "user": map[string]any{"login": "alice"}}})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	status, err := newTestGitea(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 1)
+	testhelper.AssertEqual(t, status.Approved(), true)
+}
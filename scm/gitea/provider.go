@@ -0,0 +1,254 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// pageSize is the number of items requested per page from list endpoints.
+const pageSize = 50
+
+var caps = &scm.Capabilities{
+	TeamReviewers:  true,
+	ResetReviewers: true,
+	Draft:          false,
+	Assignees:      true,
+
+	MergeMethods:   []string{"merge", "squash", "rebase"},
+	CheckMergeable: true,
+}
+
+var _ scm.Provider = new(Gitea)
+
+func init() {
+	// Register the Gitea provider factory
+	scm.Register("gitea", New)
+}
+
+// New creates a new Gitea SCM provider instance.
+func New(ctx context.Context, project string) scm.Provider {
+	viper := config.Viper(ctx)
+
+	base := strings.TrimSuffix(strings.TrimSpace(viper.GetString(config.GiteaBaseURL)), "/")
+	if base == "" {
+		base = "https://" + viper.GetString(config.GitHost)
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		panic(fmt.Sprintf("gitea: invalid base URL %q: %v", base, err))
+	}
+
+	return &Gitea{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		project: project,
+		ctx:     ctx,
+	}
+}
+
+// Gitea represents an SCM provider for the Gitea v1 API.
+type Gitea struct {
+	client  *http.Client
+	baseURL *url.URL
+	project string
+	ctx     context.Context
+}
+
+// CheckCapabilities validates that the provided PR options are supported by Gitea.
+func (g *Gitea) CheckCapabilities(opts *scm.PROptions) error {
+	return scm.ValidatePROptions(caps, opts)
+}
+
+// CurrentUser returns the login of the authenticated user.
+func (g *Gitea) CurrentUser() (string, error) {
+	resp, err := get[userResp](g, g.url(nil, "user"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return resp.Login, nil
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of Gitea tokens aren't inspected.
+func (g *Gitea) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
+// constructs the URL for the Gitea API endpoint at the given path.
+func (g *Gitea) url(queryParams url.Values, path ...string) string {
+	apiURL := g.baseURL.JoinPath("api", "v1").JoinPath(path...)
+
+	// Add query parameters if provided
+	if queryParams != nil {
+		apiURL.RawQuery = queryParams.Encode()
+	}
+
+	return apiURL.String()
+}
+
+// constructs the URL for an endpoint of the given repository within the provider's project.
+func (g *Gitea) repoURL(repo string, queryParams url.Values, path ...string) string {
+	return g.url(queryParams, append([]string{"repos", g.project, repo}, path...)...)
+}
+
+// apiError is returned when the Gitea API responds with an error status.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.StatusCode, e.Body)
+}
+
+// convenience function to perform a GET request and unmarshal the response into the specified type.
+func get[T any](g *Gitea, path string) (*T, error) {
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return do[T](g, req)
+}
+
+// convenience function to GET every page of a list endpoint and combine the results.
+func list[T any](g *Gitea, queryParams url.Values, path ...string) ([]T, error) {
+	if queryParams == nil {
+		queryParams = url.Values{}
+	}
+
+	queryParams.Set("limit", strconv.Itoa(pageSize))
+
+	output := make([]T, 0)
+	for page := 1; ; page++ {
+		queryParams.Set("page", strconv.Itoa(page))
+
+		resp, err := get[[]T](g, g.url(queryParams, path...))
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, *resp...)
+
+		// a short page is the last one
+		if len(*resp) < pageSize {
+			break
+		}
+	}
+
+	return output, nil
+}
+
+// convenience function to send a JSON payload with the given method and unmarshal the response into the specified type.
+func send[T any](g *Gitea, method, path string, payload any) (*T, error) {
+	req, err := newJSONRequest(g, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return do[T](g, req)
+}
+
+// convenience function to send a JSON payload with the given method, ignoring any response body.
+func sendNoContent(g *Gitea, method, path string, payload any) error {
+	req, err := newJSONRequest(g, method, path, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.execute(req)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// newJSONRequest creates a request with the given payload marshaled as its JSON body.
+func newJSONRequest(g *Gitea, method, path string, payload any) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(g.ctx, method, path, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return req, nil
+}
+
+// convenience function to perform an HTTP request and unmarshal the response into the specified type.
+func do[T any](g *Gitea, req *http.Request) (*T, error) {
+	resp, err := g.execute(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result T
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// execute authenticates and performs the HTTP request, returning an apiError for error responses.
+// The caller is responsible for closing the body of a successful response.
+func (g *Gitea) execute(req *http.Request) (*http.Response, error) {
+	token, err := scm.AuthToken(g.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if err := parseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func parseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	output, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error %d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	return &apiError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(output))}
+}
+
+type userResp struct {
+	Login string `json:"login"`
+}
@@ -0,0 +1,119 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func loadFixture(t *testing.T) context.Context {
+	return testhelper.LoadFixture(t, "../../config")
+}
+
+// newTestGitea creates a Gitea provider for the "test-org" project which sends its requests to the test server.
+func newTestGitea(t *testing.T, server *httptest.Server) *Gitea {
+	t.Helper()
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.AuthToken, "test-token")
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	return &Gitea{
+		client:  server.Client(),
+		baseURL: baseURL,
+		project: "test-org",
+		ctx:     ctx,
+	}
+}
+
+// writeJSON encodes the value as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{name: "base url", baseURL: "https://gitea.example.com/", want: "https://gitea.example.com/api/v1/user"},
+		{name: "base url with path", baseURL: "https://example.com/gitea", want: "https://example.com/gitea/api/v1/user"},
+		{name: "git host fallback", baseURL: "", want: "https://github.com/api/v1/user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.GiteaBaseURL, tt.baseURL)
+
+			provider, ok := New(ctx, "test-org").(*Gitea)
+			if !ok {
+				t.Fatalf("Expected *Gitea provider, got %T", provider)
+			}
+
+			testhelper.AssertEqual(t, provider.project, "test-org")
+			testhelper.AssertEqual(t, provider.url(nil, "user"), tt.want)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider := scm.Get(loadFixture(t), "gitea", "test-org")
+
+	if _, ok := provider.(*Gitea); !ok {
+		t.Errorf("Expected *Gitea provider, got %T", provider)
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	g := &Gitea{}
+
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{TeamReviewers: []string{"team"}, Assignees: []string{"alice"}}), false)
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "squash"}}), false)
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{Draft: new(bool)}), true)
+}
+
+func TestCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v1/user")
+		testhelper.AssertEqual(t, r.Header.Get("Authorization"), "token test-token")
+
+		writeJSON(t, w, map[string]any{"login": "alice"})
+	}))
+	defer server.Close()
+
+	login, err := newTestGitea(t, server).CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, login, "alice")
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"token is required"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).CurrentUser()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"error 401", "token is required"})
+}
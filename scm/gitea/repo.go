@@ -0,0 +1,60 @@
+package gitea
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+type repoResp struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Private       bool     `json:"private"`
+	Archived      bool     `json:"archived"`
+	DefaultBranch string   `json:"default_branch"`
+	Topics        []string `json:"topics"`
+	CloneURL      string   `json:"clone_url"`
+	SSHURL        string   `json:"ssh_url"`
+	HTMLURL       string   `json:"html_url"`
+}
+
+// ListRepositories lists all repositories in the specified project.
+// Supports both organization and user repositories.
+func (g *Gitea) ListRepositories() ([]*scm.Repository, error) {
+	repos, err := list[repoResp](g, nil, "orgs", g.project, "repos")
+
+	// the project is not an organization, so fall back on the user's repositories
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		repos, err = list[repoResp](g, nil, "users", g.project, "repos")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.Repository, len(repos))
+	for i, repo := range repos {
+		if repo.DefaultBranch == "" {
+			// fall back on configured default branch if it isn't set for the repo
+			repo.DefaultBranch = config.Viper(g.ctx).GetString(config.DefaultBranch)
+		}
+
+		output[i] = &scm.Repository{
+			Name:          repo.Name,
+			Description:   repo.Description,
+			Public:        !repo.Private,
+			Archived:      repo.Archived,
+			Project:       g.project,
+			DefaultBranch: repo.DefaultBranch,
+			Labels:        repo.Topics,
+			CloneURL:      repo.CloneURL,
+			SSHURL:        repo.SSHURL,
+			WebURL:        repo.HTMLURL,
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,90 @@
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v1/orgs/test-org/repos")
+
+		// return a full first page to exercise pagination
+		var repos []map[string]any
+		if r.URL.Query().Get("page") == "1" {
+			for i := range pageSize {
+				repos = append(repos, map[string]any{"name": fmt.Sprintf("repo-%d", i), "default_branch": "main"})
+			}
+		} else {
+			repos = append(repos, map[string]any{
+				"name":        "last-repo",
+				"description": "The last repository",
+				"private":     true,
+				"archived":    true,
+				"topics":      []string{"api"},
+				"clone_url":   "https://gitea.example.com/test-org/last-repo.git",
+				"ssh_url":     "git@gitea.example.com:test-org/last-repo.git",
+				"html_url":    "https://gitea.example.com/test-org/last-repo",
+			})
+		}
+
+		writeJSON(t, w, repos)
+	}))
+	defer server.Close()
+
+	repos, err := newTestGitea(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, pageSize+1)
+	testhelper.AssertEqual(t, repos[0].Name, "repo-0")
+	testhelper.AssertEqual(t, repos[0].Public, true)
+
+	last := repos[pageSize]
+	testhelper.AssertEqual(t, last.Name, "last-repo")
+	testhelper.AssertEqual(t, last.Project, "test-org")
+	testhelper.AssertEqual(t, last.Public, false)
+	testhelper.AssertEqual(t, last.Archived, true)
+	testhelper.AssertEqual(t, last.DefaultBranch, "main") // configured fallback
+	testhelper.AssertEqual(t, strings.Join(last.Labels, ","), "api")
+	testhelper.AssertEqual(t, last.CloneURL, "https://gitea.example.com/test-org/last-repo.git")
+	testhelper.AssertEqual(t, last.SSHURL, "git@gitea.example.com:test-org/last-repo.git")
+	testhelper.AssertEqual(t, last.WebURL, "https://gitea.example.com/test-org/last-repo")
+}
+
+func TestListRepositoriesUserFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/orgs/test-org/repos" {
+			http.NotFound(w, r)
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v1/users/test-org/repos")
+		writeJSON(t, w, []map[string]any{{"name": "personal-repo"}})
+	}))
+	defer server.Close()
+
+	repos, err := newTestGitea(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, 1)
+	testhelper.AssertEqual(t, repos[0].Name, "personal-repo")
+}
+
+func TestListRepositoriesAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := newTestGitea(t, server).ListRepositories()
+	testhelper.AssertError(t, err, true)
+}
@@ -0,0 +1,151 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+)
+
+const (
+	markReadyForReviewMutation = `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) { pullRequest { isDraft } }
+}`
+	convertToDraftMutation = `mutation($id: ID!) {
+  convertPullRequestToDraft(input: {pullRequestId: $id}) { pullRequest { isDraft } }
+}`
+	enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { pullRequest { number } }
+}`
+)
+
+// errAutoMergeUnavailable is returned when GitHub refuses to enable auto-merge because the repository doesn't allow it.
+var errAutoMergeUnavailable = errors.New("auto-merge is not available for this pull request (enable \"Allow auto-merge\" in the repository settings and protect the base branch)")
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+// graphqlURL returns the GraphQL endpoint matching the client's REST base URL. GitHub Enterprise serves
+// the REST API from /api/v3/ and GraphQL from /api/graphql, while github.com serves both from the API root.
+func (g *Github) graphqlURL() string {
+	base := g.client.BaseURL
+	if strings.HasSuffix(base.Path, "/api/v3/") {
+		return base.ResolveReference(&url.URL{Path: "../graphql"}).String()
+	}
+
+	return base.ResolveReference(&url.URL{Path: "graphql"}).String()
+}
+
+// graphql executes the given GraphQL query, returning an error if the request fails or the response reports errors.
+func (g *Github) graphql(query string, variables map[string]any) error {
+	req, err := g.client.NewRequest(http.MethodPost, g.graphqlURL(), &graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	var resp graphqlResponse
+	if _, err = g.client.Do(g.ctx, req, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Errors) > 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// setDraft converts the given pull request to a draft or marks it as ready for review. The REST API
+// cannot change the draft status of an existing pull request, so this uses the GraphQL mutations instead.
+func (g *Github) setDraft(pr *github.PullRequest, draft bool) (*github.PullRequest, error) {
+	if pr.GetDraft() == draft {
+		return pr, nil
+	}
+
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	mutation := markReadyForReviewMutation
+	if draft {
+		mutation = convertToDraftMutation
+	}
+
+	variables := map[string]any{"id": pr.GetNodeID()}
+
+	if err := g.graphql(mutation, variables); err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to update draft status: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to update draft status: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if err = g.graphql(mutation, variables); err != nil {
+			return nil, fmt.Errorf("failed to update draft status after retry: %w", detailedError(err))
+		}
+	}
+
+	pr.Draft = github.Ptr(draft)
+
+	return pr, nil
+}
+
+// enableAutoMerge enables auto-merge on the given pull request, merging with the given method (or GitHub's default
+// of merge) once its requirements are met.
+func (g *Github) enableAutoMerge(pr *github.PullRequest, method string) (*github.PullRequest, error) {
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	variables := map[string]any{"id": pr.GetNodeID()}
+	if method != "" {
+		variables["method"] = strings.ToUpper(method)
+	}
+
+	if err := g.graphql(enableAutoMergeMutation, variables); err != nil {
+		if autoMergeUnavailable(err) {
+			return nil, fmt.Errorf("failed to enable auto-merge: %w: %w", errAutoMergeUnavailable, err)
+		}
+
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to enable auto-merge: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to enable auto-merge: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if err = g.graphql(enableAutoMergeMutation, variables); err != nil {
+			return nil, fmt.Errorf("failed to enable auto-merge after retry: %w", detailedError(err))
+		}
+	}
+
+	if method == "" {
+		method = "merge"
+	}
+
+	pr.AutoMerge = &github.PullRequestAutoMerge{MergeMethod: github.Ptr(method)}
+
+	return pr, nil
+}
+
+// autoMergeUnavailable reports whether the error is GitHub refusing auto-merge because of the repository's settings.
+func autoMergeUnavailable(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	return strings.Contains(msg, "protected branch rules not configured") || strings.Contains(msg, "auto merge is not allowed")
+}
@@ -0,0 +1,79 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// oauthScopesHeader lists the scopes of a classic token; it is absent from responses to fine-grained tokens.
+const oauthScopesHeader = "X-OAuth-Scopes"
+
+// MissingPermissions returns the permissions which the token appears to lack for the repository. The scopes of
+// a classic token are read from the response headers, while a fine-grained token is checked against the access
+// to the repository reported by GitHub. A token which can't see the repository at all lacks metadata:read, and
+// one without push access lacks contents:write, which is needed to push the branches of its pull requests.
+func (g *Github) MissingPermissions(repo string, write bool) ([]string, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	_, resp, err := g.client.Users.Get(g.ctx, "")
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to get token scopes: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to get token scopes: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if _, resp, err = g.client.Users.Get(g.ctx, ""); err != nil {
+			return nil, fmt.Errorf("failed to get token scopes after retry: %w", detailedError(err))
+		}
+	}
+
+	if scopes, ok := resp.Header[http.CanonicalHeaderKey(oauthScopesHeader)]; ok {
+		return missingScopes(scopes, write), nil
+	}
+
+	repository, httpResp, err := g.client.Repositories.Get(g.ctx, g.project, repo)
+	if err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			return []string{"metadata:read"}, nil
+		}
+
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to get repository permissions: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to get repository permissions: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if repository, _, err = g.client.Repositories.Get(g.ctx, g.project, repo); err != nil {
+			return nil, fmt.Errorf("failed to get repository permissions after retry: %w", detailedError(err))
+		}
+	}
+
+	if write && !repository.GetPermissions()["push"] {
+		return []string{"contents:write"}, nil
+	}
+
+	return nil, nil
+}
+
+// missingScopes returns the scopes which a classic token needs but lacks. Pull requests can only be modified
+// with the repo scope, or public_repo for public repositories; reading needs no scope for public repositories.
+func missingScopes(header []string, write bool) []string {
+	var scopes []string
+	for _, value := range header {
+		for scope := range strings.SplitSeq(value, ",") {
+			scopes = append(scopes, strings.TrimSpace(scope))
+		}
+	}
+
+	if write && !slices.Contains(scopes, "repo") && !slices.Contains(scopes, "public_repo") {
+		return []string{"repo"}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      *string // nil for a fine-grained token
+		push        bool
+		noRepo      bool
+		write       bool
+		wantMissing []string
+	}{
+		{name: "classic token with repo scope", scopes: ptr("repo, read:org"), write: true},
+		{name: "classic token with public_repo scope", scopes: ptr("public_repo"), write: true},
+		{name: "classic token without repo scope", scopes: ptr("read:org, gist"), write: true, wantMissing: []string{"repo"}},
+		{name: "classic token without scopes", scopes: ptr(""), write: true, wantMissing: []string{"repo"}},
+		{name: "classic token reading without scopes", scopes: ptr("")},
+		{name: "fine-grained token with push access", push: true, write: true},
+		{name: "fine-grained token without push access", write: true, wantMissing: []string{"contents:write"}},
+		{name: "fine-grained token reading without push access"},
+		{name: "fine-grained token without repository access", noRepo: true, wantMissing: []string{"metadata:read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repoRequested bool
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/user":
+					if tt.scopes != nil {
+						w.Header().Set(oauthScopesHeader, *tt.scopes)
+					}
+
+					json.NewEncoder(w).Encode(map[string]interface{}{"login": "octocat"})
+				case "/repos/test-org/repo-1":
+					repoRequested = true
+
+					if tt.noRepo {
+						w.WriteHeader(http.StatusNotFound)
+						json.NewEncoder(w).Encode(map[string]interface{}{"message": "Not Found"})
+
+						return
+					}
+
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"name":        "repo-1",
+						"permissions": map[string]bool{"pull": true, "push": tt.push},
+					})
+				default:
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+
+			missing, err := g.MissingPermissions("repo-1", tt.write)
+			testhelper.AssertError(t, err, false)
+			testhelper.AssertLength(t, missing, len(tt.wantMissing))
+
+			if len(tt.wantMissing) > 0 {
+				testhelper.AssertContains(t, missing, tt.wantMissing)
+			}
+
+			if classic := tt.scopes != nil; classic && repoRequested {
+				t.Error("Expected the repository not to be requested for a classic token")
+			}
+		})
+	}
+}
+
+func TestMissingPermissionsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "Bad credentials"})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+
+	_, err := g.MissingPermissions("repo-1", true)
+	testhelper.AssertError(t, err, true)
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -1,7 +1,10 @@
 package github
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/google/go-github/v74/github"
 
@@ -19,6 +22,52 @@ func (g *Github) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
 	return parsePR(resp), nil
 }
 
+// GetPullRequestByState retrieves the most recent pull request for the given source branch in the given state,
+// which is one of open, closed, merged, or all (an empty state defaults to open).
+func (g *Github) GetPullRequestByState(repo, branch, state string) (*scm.PullRequest, error) {
+	resp, err := g.getPullRequestByState(repo, branch, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestByNumber retrieves a pull request by repository name and number.
+func (g *Github) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := g.getPullRequestByNumber(repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePR(resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request by repository name and source branch.
+func (g *Github) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	resp, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.GetHead().GetSHA(), nil
+}
+
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (g *Github) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	resp, err := g.listPullRequests(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.PullRequest, len(resp))
+	for i, pr := range resp {
+		output[i] = parsePR(pr)
+	}
+
+	return output, nil
+}
+
 // OpenPullRequest opens a new pull request in the specified repository.
 func (g *Github) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
 	if opts == nil {
@@ -26,8 +75,8 @@ func (g *Github) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm
 	}
 
 	// reads are less restrictive than a failed write, so check for existing PR first
-	if _, err := g.getPullRequest(repo, branch); err == nil {
-		return nil, fmt.Errorf("a pull request already exists for branch %s in repository %s", branch, repo)
+	if existing, err := g.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parsePR(existing))
 	}
 
 	// if title is not specified, use the branch name
@@ -47,17 +96,55 @@ func (g *Github) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm
 		req.Draft = github.Ptr(*opts.Draft)
 	}
 
+	if opts.CreateBaseBranch && opts.BaseBranch != "" {
+		if err := g.ensureBranch(repo, opts.BaseBranch); err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err := g.openPullRequest(repo, req)
 	if err != nil {
+		// a missing base branch is rejected as a generic validation failure, so report a missing branch clearly
+		if isValidationError(err) {
+			if _, branchErr := g.defaultBranch(repo); errors.Is(branchErr, errNoDefaultBranch) {
+				return nil, branchErr
+			}
+
+			if opts.BaseBranch != "" {
+				if _, branchErr := g.branchSHA(repo, opts.BaseBranch); errors.Is(branchErr, errNoBranch) {
+					return nil, branchErr
+				}
+			}
+		}
+
 		return nil, err
 	}
 
 	opts.ResetReviewers = false // suppress ResetReviewers when opening a new PR
-	if resp, err = g.applyAllReviewers(repo, resp, opts); err != nil {
+	resp, skipped, err := g.applyAllReviewers(repo, resp, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	return parsePR(resp), nil
+	if resp, err = g.applyAssignees(repo, resp, opts); err != nil {
+		return nil, err
+	}
+
+	if resp, err = g.applyLabels(repo, resp, opts); err != nil {
+		return nil, err
+	}
+
+	if opts.AutoMerge {
+		if _, autoMergeErr := g.enableAutoMerge(resp, opts.AutoMergeMethod); autoMergeErr != nil {
+			// the pull request is returned along with the error, since it was opened regardless
+			err = fmt.Errorf("opened pull request #%d, but %w", resp.GetNumber(), autoMergeErr)
+		}
+	}
+
+	pr := parsePR(resp)
+	pr.SkippedReviewers = skipped
+
+	return pr, err
 }
 
 // UpdatePullRequest updates an existing pull request.
@@ -67,10 +154,6 @@ func (g *Github) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*s
 		return nil, err
 	}
 
-	if opts.Draft != nil {
-		return nil, fmt.Errorf("updating draft status is not currently supported by the GitHub provider")
-	}
-
 	req, changes := g.processChanges(opts)
 	if changes {
 		if pr, err = g.editPullRequest(repo, pr.GetNumber(), req); err != nil {
@@ -78,12 +161,30 @@ func (g *Github) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*s
 		}
 	}
 
+	if opts.Draft != nil {
+		if pr, err = g.setDraft(pr, *opts.Draft); err != nil {
+			return nil, err
+		}
+	}
+
 	// if there are reviewer changes, apply them regardless of whether other changes were made
-	if pr, err = g.applyAllReviewers(repo, pr, opts); err != nil {
+	pr, skipped, err := g.applyAllReviewers(repo, pr, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	return parsePR(pr), nil
+	if pr, err = g.applyAssignees(repo, pr, opts); err != nil {
+		return nil, err
+	}
+
+	if pr, err = g.applyLabels(repo, pr, opts); err != nil {
+		return nil, err
+	}
+
+	result := parsePR(pr)
+	result.SkippedReviewers = skipped
+
+	return result, nil
 }
 
 // MergePullRequest merges an existing pull request
@@ -97,8 +198,31 @@ func (g *Github) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions)
 		return nil, err
 	}
 
-	if opts.CheckMergeable && !pr.GetMergeable() {
-		return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable: %s", branch, pr.GetNumber(), repo, pr.GetMergeableState())
+	if opts.CheckMergeable || opts.UpdateBranch || opts.DryRun {
+		// the mergeable state is only reported when retrieving a single pull request
+		if pr, err = g.getPullRequestByNumber(repo, pr.GetNumber()); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun {
+		checked := parsePR(pr)
+		checked.MergeBlocker = mergeBlocker(pr)
+		checked.Mergeable = checked.MergeBlocker == ""
+
+		return checked, nil
+	}
+
+	if opts.UpdateBranch && pr.GetMergeableState() == "behind" {
+		if pr, err = g.updateBranch(repo, pr); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.CheckMergeable {
+		if reason := mergeBlocker(pr); reason != "" {
+			return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable: %s", branch, pr.GetNumber(), repo, reason)
+		}
 	}
 
 	// if no merge method specified, use the default from config (if set)
@@ -106,85 +230,198 @@ func (g *Github) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions)
 		opts.Method = config.Viper(g.ctx).GetString(config.DefaultMergeMethod)
 	}
 
-	if err = g.mergePullRequest(repo, pr.GetNumber(), opts.Method); err != nil {
+	sha, err := g.mergePullRequest(repo, pr.GetNumber(), opts.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := parsePR(pr)
+	merged.MergeCommit = sha
+
+	return merged, nil
+}
+
+// GetReviewStatus retrieves the approval state of a pull request, using the protection
+// rules of its base branch to determine the number of required approvals.
+func (g *Github) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	pr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := g.requiredApprovals(repo, pr.GetBase().GetRef())
+	if err != nil {
 		return nil, err
 	}
 
-	return parsePR(pr), nil
+	reviews, err := g.listReviews(repo, pr.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReviewStatus(reviews, required), nil
 }
 
 func (g *Github) getPullRequest(repo, branch string) (*github.PullRequest, error) {
+	return g.getPullRequestByState(repo, branch, scm.PRStateOpen)
+}
+
+func (g *Github) getPullRequestByState(repo, branch, state string) (*github.PullRequest, error) {
+	if state == "" {
+		state = scm.PRStateOpen
+	}
+
+	if !scm.ValidPRState(state) {
+		return nil, fmt.Errorf("invalid pull request state %q: must be one of %s, %s, %s, or %s",
+			state, scm.PRStateOpen, scm.PRStateClosed, scm.PRStateMerged, scm.PRStateAll)
+	}
+
 	// acquire read lock (and release it when done)
 	defer g.readLock()()
 
+	// GitHub has no merged state, so merged pull requests are found by filtering the closed ones
 	opts := &github.PullRequestListOptions{
-		State: "open",
-		Head:  fmt.Sprintf("%s:%s", g.project, branch),
-	}
+		State:       state,
+		Head:        fmt.Sprintf("%s:%s", g.project, branch),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if state == scm.PRStateMerged {
+		opts.State = scm.PRStateClosed
+	}
+
+	// a branch may have many closed pull requests which weren't merged, so every page is searched
+	for {
+		prs, resp, err := g.client.PullRequests.List(g.ctx, g.project, repo, opts)
+		if err != nil {
+			if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
+				return nil, fmt.Errorf("failed to get pull request: %w: %w", rateErr, detailedError(err))
+			} else if !retry {
+				return nil, fmt.Errorf("failed to get pull request: %w", detailedError(err))
+			}
+
+			// retry the request after waiting for the rate limit to reset
+			if prs, resp, err = g.client.PullRequests.List(g.ctx, g.project, repo, opts); err != nil {
+				return nil, fmt.Errorf("failed to get pull request after retry: %w", detailedError(err))
+			}
+		}
 
-	resp, _, err := g.client.PullRequests.List(g.ctx, g.project, repo, opts)
-	if err != nil {
-		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, fmt.Errorf("failed to get pull request: %w: %w", rateErr, err)
-		} else if !retry {
-			return nil, fmt.Errorf("failed to get pull request: %w", err)
+		for _, pr := range prs {
+			if state != scm.PRStateMerged || pr.MergedAt != nil {
+				return pr, nil
+			}
 		}
 
-		// retry the request after waiting for the rate limit to reset
-		if resp, _, err = g.client.PullRequests.List(g.ctx, g.project, repo, opts); err != nil {
-			return nil, fmt.Errorf("failed to get pull request after retry: %w", err)
+		if resp.NextPage == 0 {
+			break
 		}
+
+		opts.Page = resp.NextPage
 	}
 
-	if len(resp) == 0 {
-		return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+	if state == scm.PRStateAll {
+		return nil, fmt.Errorf("no pull request found for branch %s in repository %s", branch, repo)
 	}
 
-	return resp[0], nil
+	return nil, fmt.Errorf("no %s pull request found for branch %s in repository %s", state, branch, repo)
+}
+
+func (g *Github) listPullRequests(repo string) ([]*github.PullRequest, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	output := make([]*github.PullRequest, 0)
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		prs, resp, err := g.client.PullRequests.List(g.ctx, g.project, repo, opts)
+		if err != nil {
+			if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
+				return nil, fmt.Errorf("failed to list pull requests: %w: %w", rateErr, detailedError(err))
+			} else if !retry {
+				return nil, fmt.Errorf("failed to list pull requests: %w", detailedError(err))
+			}
+
+			// retry the request after waiting for the rate limit to reset
+			if prs, resp, err = g.client.PullRequests.List(g.ctx, g.project, repo, opts); err != nil {
+				return nil, fmt.Errorf("failed to list pull requests after retry: %w", detailedError(err))
+			}
+		}
+
+		output = append(output, prs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return output, nil
 }
 
 func (g *Github) getPullRequestByNumber(repo string, prNumber int) (*github.PullRequest, error) {
 	// acquire read lock (and release it when done)
 	defer g.readLock()()
 
-	resp, _, err := g.client.PullRequests.Get(g.ctx, g.project, repo, prNumber)
+	resp, httpResp, err := g.client.PullRequests.Get(g.ctx, g.project, repo, prNumber)
 	if err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("no pull request #%d found in repository %s", prNumber, repo)
+		}
+
 		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, fmt.Errorf("failed to get pull request: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to get pull request: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to get pull request: %w", err)
+			return nil, fmt.Errorf("failed to get pull request: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if resp, _, err = g.client.PullRequests.Get(g.ctx, g.project, repo, prNumber); err != nil {
-			return nil, fmt.Errorf("failed to get pull request after retry: %w", err)
+			return nil, fmt.Errorf("failed to get pull request after retry: %w", detailedError(err))
 		}
 	}
 
 	return resp, nil
 }
 
+// openPullRequest opens the pull request, retrying once after a rate limit or a timed out request. A failed request
+// may still have opened the pull request (e.g. a timeout after GitHub processed it), so a pull request which is now
+// open for the branch is returned instead of retrying, rather than opening a duplicate.
 func (g *Github) openPullRequest(repo string, req *github.NewPullRequest) (*github.PullRequest, error) {
-	// acquire write lock (and release it when done)
-	defer g.writeLock()()
-
-	resp, _, err := g.client.PullRequests.Create(g.ctx, g.project, repo, req)
+	resp, err := g.createPullRequest(repo, req)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return nil, fmt.Errorf("failed to open pull request: %w: %w", rateErr, err)
-		} else if !retry {
-			return nil, fmt.Errorf("failed to open pull request: %w", err)
+			return nil, fmt.Errorf("failed to open pull request: %w: %w", rateErr, detailedError(err))
+		} else if !retry && !g.isTimeout(err) {
+			return nil, fmt.Errorf("failed to open pull request: %w", detailedError(err))
 		}
 
-		// retry the request after waiting for the rate limit to reset
-		if resp, _, err = g.client.PullRequests.Create(g.ctx, g.project, repo, req); err != nil {
-			return nil, fmt.Errorf("failed to open pull request after retry: %w", err)
+		if existing, getErr := g.getPullRequest(repo, req.GetHead()); getErr == nil {
+			return existing, nil
+		}
+
+		// retry the request, after waiting for the rate limit to reset if it was exceeded
+		if resp, err = g.createPullRequest(repo, req); err != nil {
+			return nil, fmt.Errorf("failed to open pull request after retry: %w", detailedError(err))
 		}
 	}
 
 	return resp, nil
 }
 
+// createPullRequest makes a single request to open the pull request.
+func (g *Github) createPullRequest(repo string, req *github.NewPullRequest) (*github.PullRequest, error) {
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	resp, _, err := g.client.PullRequests.Create(g.ctx, g.project, repo, req)
+
+	return resp, err
+}
+
 func (g *Github) editPullRequest(repo string, prNumber int, req *github.PullRequest) (*github.PullRequest, error) {
 	// acquire write lock (and release it when done)
 	defer g.writeLock()()
@@ -192,21 +429,21 @@ func (g *Github) editPullRequest(repo string, prNumber int, req *github.PullRequ
 	pr, _, err := g.client.PullRequests.Edit(g.ctx, g.project, repo, prNumber, req)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return nil, fmt.Errorf("failed to update pull request: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to update pull request: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to update pull request: %w", err)
+			return nil, fmt.Errorf("failed to update pull request: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if pr, _, err = g.client.PullRequests.Edit(g.ctx, g.project, repo, prNumber, req); err != nil {
-			return nil, fmt.Errorf("failed to update pull request after retry: %w", err)
+			return nil, fmt.Errorf("failed to update pull request after retry: %w", detailedError(err))
 		}
 	}
 
 	return pr, nil
 }
 
-func (g *Github) mergePullRequest(repo string, prNumber int, mergeMethod string) error {
+func (g *Github) mergePullRequest(repo string, prNumber int, mergeMethod string) (string, error) {
 	// acquire write lock (and release it when done)
 	defer g.writeLock()()
 
@@ -218,17 +455,74 @@ func (g *Github) mergePullRequest(repo string, prNumber int, mergeMethod string)
 		opts = &github.PullRequestOptions{MergeMethod: mergeMethod}
 	}
 
-	_, _, err := g.client.PullRequests.Merge(g.ctx, g.project, repo, prNumber, "", opts)
+	result, _, err := g.client.PullRequests.Merge(g.ctx, g.project, repo, prNumber, "", opts)
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return "", fmt.Errorf("failed to merge pull request: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return "", fmt.Errorf("failed to merge pull request: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if result, _, err = g.client.PullRequests.Merge(g.ctx, g.project, repo, prNumber, "", opts); err != nil {
+			return "", fmt.Errorf("failed to merge pull request after retry: %w", detailedError(err))
+		}
+	}
+
+	return result.GetSHA(), nil
+}
+
+// updateBranch merges the base branch into the head branch of a pull request which is behind, then waits
+// for GitHub to finish the update in the background and returns the refreshed pull request.
+func (g *Github) updateBranch(repo string, pr *github.PullRequest) (*github.PullRequest, error) {
+	if err := g.requestBranchUpdate(repo, pr); err != nil {
+		return nil, err
+	}
+
+	for range updateBranchAttempts {
+		if err := g.pause(config.Viper(g.ctx).GetDuration(config.WriteBackoff)); err != nil {
+			return nil, err
+		}
+
+		updated, err := g.getPullRequestByNumber(repo, pr.GetNumber())
+		if err != nil {
+			return nil, err
+		}
+
+		if state := updated.GetMergeableState(); state != "behind" && state != "unknown" {
+			return updated, nil
+		}
+	}
+
+	return nil, fmt.Errorf("timed out waiting for the branch of pull request [%d] for %s to be updated", pr.GetNumber(), repo)
+}
+
+func (g *Github) requestBranchUpdate(repo string, pr *github.PullRequest) error {
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	// guard against updating a branch which has received new commits since it was retrieved
+	opts := &github.PullRequestBranchUpdateOptions{ExpectedHeadSHA: pr.GetHead().SHA}
+
+	_, _, err := g.client.PullRequests.UpdateBranch(g.ctx, g.project, repo, pr.GetNumber(), opts)
 	if err != nil {
+		// the update is scheduled as a background job on GitHub's side
+		var accepted *github.AcceptedError
+		if errors.As(err, &accepted) {
+			return nil
+		}
+
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return fmt.Errorf("failed to merge pull request: %w: %w", rateErr, err)
+			return fmt.Errorf("failed to update pull request branch: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return fmt.Errorf("failed to merge pull request: %w", err)
+			return fmt.Errorf("failed to update pull request branch: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
-		if _, _, err = g.client.PullRequests.Merge(g.ctx, g.project, repo, prNumber, "", opts); err != nil {
-			return fmt.Errorf("failed to merge pull request after retry: %w", err)
+		if _, _, err = g.client.PullRequests.UpdateBranch(g.ctx, g.project, repo, pr.GetNumber(), opts); err != nil {
+			if !errors.As(err, &accepted) {
+				return fmt.Errorf("failed to update pull request branch after retry: %w", detailedError(err))
+			}
 		}
 	}
 
@@ -248,11 +542,106 @@ func (g *Github) processChanges(opts *scm.PROptions) (req *github.PullRequest, c
 		changed = true
 	}
 
-	// TODO: handle updating the draft status when supported by the provider
-
 	return req, changed
 }
 
+// applyAssignees adds the specified assignees to the given pull request, keeping any existing assignees.
+func (g *Github) applyAssignees(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, error) {
+	if len(opts.Assignees) == 0 {
+		return pr, nil
+	}
+
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	issue, _, err := g.client.Issues.AddAssignees(g.ctx, g.project, repo, pr.GetNumber(), opts.Assignees)
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to add assignees: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to add assignees: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if issue, _, err = g.client.Issues.AddAssignees(g.ctx, g.project, repo, pr.GetNumber(), opts.Assignees); err != nil {
+			return nil, fmt.Errorf("failed to add assignees after retry: %w", detailedError(err))
+		}
+	}
+
+	pr.Assignees = issue.Assignees
+
+	return pr, nil
+}
+
+// applyLabels adds the specified labels to the given pull request, or replaces its labels when ResetLabels is set.
+func (g *Github) applyLabels(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, error) {
+	if len(opts.Labels) == 0 && !opts.ResetLabels {
+		return pr, nil
+	}
+
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	apply := g.client.Issues.AddLabelsToIssue
+	if opts.ResetLabels {
+		apply = g.client.Issues.ReplaceLabelsForIssue
+	}
+
+	// resetting without any labels must send an empty list to clear them, since GitHub rejects null
+	requested := opts.Labels
+	if requested == nil {
+		requested = []string{}
+	}
+
+	labels, _, err := apply(g.ctx, g.project, repo, pr.GetNumber(), requested)
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return nil, fmt.Errorf("failed to apply labels: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return nil, fmt.Errorf("failed to apply labels: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if labels, _, err = apply(g.ctx, g.project, repo, pr.GetNumber(), requested); err != nil {
+			return nil, fmt.Errorf("failed to apply labels after retry: %w", detailedError(err))
+		}
+	}
+
+	pr.Labels = labels
+
+	return pr, nil
+}
+
+// updateBranchAttempts is the number of times to check whether a branch update has completed before giving up.
+const updateBranchAttempts = 10
+
+// mergeBlockers describes why a pull request in each of GitHub's mergeable states can't be merged, and what to do about it.
+var mergeBlockers = map[string]string{
+	"dirty":   "merge conflicts with the base branch (resolve the conflicts)",
+	"behind":  "branch is out of date with the base branch (rebase or update the branch)",
+	"blocked": "blocked by required status checks or reviews (wait for the checks to pass or for approval)",
+	"unknown": "mergeability has not been determined yet (try again shortly)",
+	"draft":   "pull request is a draft (mark it as ready for review)",
+}
+
+// mergeBlocker returns the reason that the pull request can't be merged, or an empty string if it is mergeable.
+func mergeBlocker(pr *github.PullRequest) string {
+	state := pr.GetMergeableState()
+	if reason, ok := mergeBlockers[state]; ok {
+		return reason
+	}
+
+	if pr.GetMergeable() {
+		return ""
+	}
+
+	if state == "" {
+		return mergeBlockers["unknown"]
+	}
+
+	return fmt.Sprintf("mergeable state %q", state)
+}
+
 func parsePR(resp *github.PullRequest) *scm.PullRequest {
 	pr := &scm.PullRequest{
 		ID:        int(resp.GetID()),
@@ -262,10 +651,19 @@ func parsePR(resp *github.PullRequest) *scm.PullRequest {
 
 		Title:         resp.GetTitle(),
 		Description:   resp.GetBody(),
+		URL:           resp.GetHTMLURL(),
 		Reviewers:     make([]string, 0, len(resp.RequestedReviewers)),
 		TeamReviewers: make([]string, 0, len(resp.RequestedTeams)),
 	}
 
+	if resp.MergedAt != nil {
+		pr.MergeCommit = resp.GetMergeCommitSHA()
+	}
+
+	if resp.GetAutoMerge() != nil {
+		pr.AutoMerge = strings.ToLower(resp.GetAutoMerge().GetMergeMethod())
+	}
+
 	if resp.GetHead() != nil {
 		pr.Branch = resp.GetHead().GetRef()
 	}
@@ -283,7 +681,15 @@ func parsePR(resp *github.PullRequest) *scm.PullRequest {
 	}
 
 	for _, team := range resp.RequestedTeams {
-		pr.TeamReviewers = append(pr.TeamReviewers, team.GetSlug())
+		pr.TeamReviewers = append(pr.TeamReviewers, teamName(team))
+	}
+
+	for _, assignee := range resp.Assignees {
+		pr.Assignees = append(pr.Assignees, assignee.GetLogin())
+	}
+
+	for _, label := range resp.Labels {
+		pr.Labels = append(pr.Labels, label.GetName())
 	}
 
 	return pr
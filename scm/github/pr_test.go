@@ -3,14 +3,20 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/v74/github"
 
+	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
 )
 
@@ -127,6 +133,178 @@ func TestGetPullRequest_NotFound(t *testing.T) {
 	}
 }
 
+func mockMergedPRResponse(id int64, number int, branch, sha string) map[string]interface{} {
+	pr := mockPRResponse(id, number, "Merged PR", "", branch, false, nil)
+	pr["state"] = "closed"
+	pr["merged_at"] = "2024-01-02T03:04:05Z"
+	pr["merge_commit_sha"] = sha
+
+	return pr
+}
+
+func TestGetPullRequestByState(t *testing.T) {
+	closed := mockPRResponse(100, 10, "Closed PR", "", "feature-branch", false, nil)
+	closed["state"] = "closed"
+
+	tests := []struct {
+		name        string
+		state       string
+		queryState  string
+		prs         []map[string]interface{}
+		wantNumber  int
+		wantCommit  string
+		wantErr     string
+		wantNoQuery bool
+	}{
+		{
+			name:       "default to open",
+			state:      "",
+			queryState: "open",
+			prs:        []map[string]interface{}{mockPRResponse(12345, 42, "Test PR", "", "feature-branch", true, nil)},
+			wantNumber: 42,
+		},
+		{
+			name:       "open",
+			state:      "open",
+			queryState: "open",
+			prs:        []map[string]interface{}{mockPRResponse(12345, 42, "Test PR", "", "feature-branch", true, nil)},
+			wantNumber: 42,
+		},
+		{
+			name:       "closed",
+			state:      "closed",
+			queryState: "closed",
+			prs:        []map[string]interface{}{closed},
+			wantNumber: 10,
+		},
+		{
+			name:       "merged skips unmerged closed pull requests",
+			state:      "merged",
+			queryState: "closed",
+			prs:        []map[string]interface{}{closed, mockMergedPRResponse(200, 20, "feature-branch", "abc123")},
+			wantNumber: 20,
+			wantCommit: "abc123",
+		},
+		{
+			name:       "all",
+			state:      "all",
+			queryState: "all",
+			prs:        []map[string]interface{}{mockMergedPRResponse(200, 20, "feature-branch", "abc123")},
+			wantNumber: 20,
+			wantCommit: "abc123",
+		},
+		{
+			name:       "merged not found",
+			state:      "merged",
+			queryState: "closed",
+			prs:        []map[string]interface{}{closed},
+			wantErr:    "no merged pull request found",
+		},
+		{
+			name:       "closed not found",
+			state:      "closed",
+			queryState: "closed",
+			prs:        []map[string]interface{}{},
+			wantErr:    "no closed pull request found",
+		},
+		{
+			name:       "all not found",
+			state:      "all",
+			queryState: "all",
+			prs:        []map[string]interface{}{},
+			wantErr:    "no pull request found",
+		},
+		{
+			name:        "invalid state",
+			state:       "pending",
+			wantErr:     "invalid pull request state",
+			wantNoQuery: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queried := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				queried = true
+				if got := r.URL.Query().Get("state"); got != tt.queryState {
+					t.Errorf("Expected state query %q, got %q", tt.queryState, got)
+				}
+				if got := r.URL.Query().Get("head"); got != "test-org:feature-branch" {
+					t.Errorf("Expected head query 'test-org:feature-branch', got %q", got)
+				}
+
+				json.NewEncoder(w).Encode(tt.prs)
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.GetPullRequestByState("test-repo", "feature-branch", tt.state)
+
+			if queried == tt.wantNoQuery {
+				t.Errorf("Expected query to be sent: %v", !tt.wantNoQuery)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if pr.Number != tt.wantNumber {
+				t.Errorf("Expected number %d, got %d", tt.wantNumber, pr.Number)
+			}
+			if pr.MergeCommit != tt.wantCommit {
+				t.Errorf("Expected merge commit %q, got %q", tt.wantCommit, pr.MergeCommit)
+			}
+		})
+	}
+}
+
+func TestGetPullRequestByStatePaged(t *testing.T) {
+	closed := mockPRResponse(100, 10, "Closed PR", "", "feature-branch", false, nil)
+	closed["state"] = "closed"
+
+	pages := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("Expected per_page=100, got %q", got)
+		}
+
+		// The merged pull request is only on the second page, behind closed pull requests which weren't merged
+		if r.URL.Query().Get("page") != "2" {
+			w.Header().Set("Link", `<`+server.URL+r.URL.Path+`?page=2>; rel="next"`)
+			json.NewEncoder(w).Encode([]map[string]interface{}{closed})
+
+			return
+		}
+
+		json.NewEncoder(w).Encode([]map[string]interface{}{mockMergedPRResponse(200, 20, "feature-branch", "abc123")})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	pr, err := g.GetPullRequestByState("test-repo", "feature-branch", "merged")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if pr.Number != 20 {
+		t.Errorf("Expected number 20, got %d", pr.Number)
+	}
+	if pages != 2 {
+		t.Errorf("Expected 2 pages to be requested, got %d", pages)
+	}
+}
+
 func TestGetPullRequest_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -142,6 +320,131 @@ func TestGetPullRequest_APIError(t *testing.T) {
 	}
 }
 
+func TestGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected GET request, got %s", r.Method)
+		}
+		if r.URL.Path != "/repos/test-org/test-repo/pulls/42" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+
+		json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "Test PR", "PR description", "feature-branch", true, []string{"alice"}))
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	pr, err := g.GetPullRequestByNumber("test-repo", 42)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if pr.Number != 42 {
+		t.Errorf("Expected number 42, got %d", pr.Number)
+	}
+	if pr.Branch != "feature-branch" {
+		t.Errorf("Expected branch 'feature-branch', got '%s'", pr.Branch)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0] != "alice" {
+		t.Errorf("Unexpected reviewers: %v", pr.Reviewers)
+	}
+}
+
+func TestGetPullRequestByNumber_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	_, err := g.GetPullRequestByNumber("test-repo", 99)
+
+	if err == nil {
+		t.Fatal("Expected error for nonexistent PR")
+	}
+	if !strings.Contains(err.Error(), "no pull request #99 found in repository test-repo") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestGetPullRequestHeadSHA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		pr := mockPRResponse(12345, 42, "Test PR", "PR description", "feature-branch", true, nil)
+		pr["head"] = map[string]interface{}{"ref": "feature-branch", "sha": "abc123"}
+		json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	sha, err := g.GetPullRequestHeadSHA("test-repo", "feature-branch")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sha != "abc123" {
+		t.Errorf("Expected head SHA 'abc123', got '%s'", sha)
+	}
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/repos/test-org/test-repo/pulls") {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if state := r.URL.Query().Get("state"); state != "open" {
+			t.Errorf("Expected state=open, got %q", state)
+		}
+
+		// Return the results across two pages
+		if r.URL.Query().Get("page") != "2" {
+			w.Header().Set("Link", `<`+server.URL+r.URL.Path+`?page=2>; rel="next"`)
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				mockPRResponse(1, 10, "Bump dependencies", "", "deps-update", true, nil),
+			})
+
+			return
+		}
+
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			mockPRResponse(2, 11, "Fix flaky test", "", "fix-test", true, nil),
+		})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	prs, err := g.ListOpenPullRequests("test-repo")
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(prs) != 2 {
+		t.Fatalf("Expected 2 pull requests, got %d", len(prs))
+	}
+	if prs[0].Number != 10 || prs[0].Branch != "deps-update" {
+		t.Errorf("Unexpected first pull request: %+v", prs[0])
+	}
+	if prs[1].Number != 11 || prs[1].Title != "Fix flaky test" {
+		t.Errorf("Unexpected second pull request: %+v", prs[1])
+	}
+}
+
+func TestListOpenPullRequests_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Internal Server Error"})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	if _, err := g.ListOpenPullRequests("test-repo"); err == nil {
+		t.Fatal("Expected error for API failure")
+	}
+}
+
 func TestOpenPullRequest(t *testing.T) {
 	requestCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -194,13 +497,135 @@ func TestOpenPullRequest(t *testing.T) {
 	}
 }
 
+func TestOpenPullRequest_NoDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		defaultBranch string
+		branchExists  bool
+		wantErr       string
+	}{
+		{name: "empty default branch", defaultBranch: "", wantErr: "repository has no default branch: empty-repo"},
+		{name: "default branch not created", defaultBranch: "main", wantErr: "repository has no default branch: empty-repo"},
+		{name: "default branch exists", defaultBranch: "main", branchExists: true, wantErr: "422"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{})
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls"):
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					json.NewEncoder(w).Encode(map[string]interface{}{"message": "Validation Failed"})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos/test-org/empty-repo"):
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "empty-repo", "default_branch": tt.defaultBranch})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/branches/main") && tt.branchExists:
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "main"})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			_, err := g.OpenPullRequest("empty-repo", "feature-branch", &scm.PROptions{Title: "New PR", BaseBranch: "main"})
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenPullRequest_CreateBaseBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		createBase  bool
+		wantErr     string
+		wantCreated bool
+	}{
+		{name: "creates missing base branch", createBase: true, wantCreated: true},
+		{name: "missing base branch fails when disabled", wantErr: "branch does not exist: feature/part-1 in repository test-repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created bool
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{})
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls"):
+					if !created {
+						w.WriteHeader(http.StatusUnprocessableEntity)
+						json.NewEncoder(w).Encode(map[string]interface{}{"message": "Validation Failed"})
+						return
+					}
+
+					w.WriteHeader(http.StatusCreated)
+					json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "Part 2", "", "feature-branch", true, nil))
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos/test-org/test-repo"):
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "test-repo", "default_branch": "main"})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/branches/main"):
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "main", "commit": map[string]interface{}{"sha": "abc123"}})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/branches/feature/part-1") && created:
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "feature/part-1", "commit": map[string]interface{}{"sha": "abc123"}})
+				case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/git/refs"):
+					var req map[string]string
+					json.NewDecoder(r.Body).Decode(&req)
+
+					if req["ref"] != "refs/heads/feature/part-1" || req["sha"] != "abc123" {
+						t.Errorf("Unexpected ref creation request: %v", req)
+					}
+
+					created = true
+					w.WriteHeader(http.StatusCreated)
+					json.NewEncoder(w).Encode(map[string]interface{}{"ref": req["ref"], "object": map[string]string{"sha": req["sha"]}})
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+				Title:            "Part 2",
+				BaseBranch:       "feature/part-1",
+				CreateBaseBranch: tt.createBase,
+			})
+
+			if created != tt.wantCreated {
+				t.Errorf("Expected base branch created: %v, got: %v", tt.wantCreated, created)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if pr.Number != 42 {
+				t.Errorf("Expected PR #42, got #%d", pr.Number)
+			}
+		})
+	}
+}
+
 func TestOpenPullRequest_AlreadyExists(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		// Return existing PR
-		prs := []map[string]interface{}{
-			mockPRResponse(12345, 42, "Existing PR", "description", "feature-branch", true, nil),
-		}
-		json.NewEncoder(w).Encode(prs)
+		existing := mockPRResponse(12345, 42, "Existing PR", "description", "feature-branch", true, nil)
+		existing["html_url"] = "https://github.com/test-org/test-repo/pull/42"
+
+		json.NewEncoder(w).Encode([]map[string]interface{}{existing})
 	}))
 	defer server.Close()
 
@@ -215,6 +640,76 @@ func TestOpenPullRequest_AlreadyExists(t *testing.T) {
 	if !strings.Contains(err.Error(), "already exists") {
 		t.Errorf("Unexpected error message: %v", err)
 	}
+
+	// the existing pull request is identified so that it can be found or updated instead
+	if !strings.Contains(err.Error(), "(PR #42 https://github.com/test-org/test-repo/pull/42)") {
+		t.Errorf("Expected error to identify the existing PR, got: %v", err)
+	}
+}
+
+func TestOpenPullRequest_TimeoutAfterCreate(t *testing.T) {
+	tests := []struct {
+		name        string
+		created     bool // whether the timed out request opened the pull request
+		wantCreates int32
+	}{
+		{name: "created before timeout", created: true, wantCreates: 1},
+		{name: "not created before timeout", created: false, wantCreates: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var creates, opened atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					// list open pull requests for the branch
+					if opened.Load() == 0 {
+						json.NewEncoder(w).Encode([]map[string]interface{}{})
+						return
+					}
+
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						mockPRResponse(12345, 42, "New PR", "", "feature-branch", true, nil),
+					})
+				case http.MethodPost:
+					// the first request times out, after opening the pull request if configured
+					if creates.Add(1) == 1 {
+						if tt.created {
+							opened.Store(1)
+						}
+
+						time.Sleep(200 * time.Millisecond)
+
+						return
+					}
+
+					opened.Store(1)
+					w.WriteHeader(http.StatusCreated)
+					json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "New PR", "", "feature-branch", true, nil))
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			g.client = github.NewClient(&http.Client{Timeout: 50 * time.Millisecond})
+			g.client.BaseURL, _ = g.client.BaseURL.Parse(server.URL + "/")
+
+			pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Title: "New PR", BaseBranch: "main"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if pr.Number != 42 {
+				t.Errorf("Expected PR #42, got #%d", pr.Number)
+			}
+
+			if got := creates.Load(); got != tt.wantCreates {
+				t.Errorf("Expected %d create requests, got %d", tt.wantCreates, got)
+			}
+		})
+	}
 }
 
 func TestOpenPullRequest_WithReviewers(t *testing.T) {
@@ -405,29 +900,466 @@ func TestUpdatePullRequest_NotFound(t *testing.T) {
 	}
 }
 
-func TestMergePullRequest(t *testing.T) {
-	requestPhase := 0
+func TestOpenPullRequest_Draft(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestPhase++
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls") {
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+			return
+		}
 
-		// Phase 1: Get PR
-		if requestPhase == 1 && r.Method == http.MethodGet {
+		if r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/pulls") {
+			var req map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+
+			if req["draft"] != true {
+				t.Errorf("Expected draft: true in request body, got %v", req["draft"])
+			}
+
+			pr := mockPRResponse(99999, 100, "WIP", "", "feature-branch", true, nil)
+			pr["draft"] = true
+			json.NewEncoder(w).Encode(pr)
+			return
+		}
+
+		t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:      "WIP",
+		BaseBranch: "main",
+		Draft:      boolPtr(true),
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !pr.Draft {
+		t.Error("Expected created PR to be a draft")
+	}
+}
+
+func TestUpdatePullRequest_Draft(t *testing.T) {
+	tests := []struct {
+		name         string
+		currentDraft bool
+		draft        bool
+		wantMutation string
+		graphqlError string
+		wantErr      string
+	}{
+		{
+			name:         "mark ready for review",
+			currentDraft: true,
+			draft:        false,
+			wantMutation: "markPullRequestReadyForReview",
+		},
+		{
+			name:         "convert to draft",
+			currentDraft: false,
+			draft:        true,
+			wantMutation: "convertPullRequestToDraft",
+		},
+		{
+			name:         "already in requested state",
+			currentDraft: true,
+			draft:        true,
+		},
+		{
+			name:         "mutation error",
+			currentDraft: true,
+			draft:        false,
+			wantMutation: "markPullRequestReadyForReview",
+			graphqlError: "Resource not accessible by integration",
+			wantErr:      "Resource not accessible by integration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutated := ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls") {
+					pr := mockPRResponse(12345, 42, "Title", "", "feature-branch", true, nil)
+					pr["draft"] = tt.currentDraft
+					pr["node_id"] = "PR_node42"
+					json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+					return
+				}
+
+				if r.Method == http.MethodPost && r.URL.Path == "/graphql" {
+					var req struct {
+						Query     string         `json:"query"`
+						Variables map[string]any `json:"variables"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Errorf("Failed to decode request: %v", err)
+					}
+
+					if req.Variables["id"] != "PR_node42" {
+						t.Errorf("Expected pull request node ID 'PR_node42', got %v", req.Variables["id"])
+					}
+
+					for _, name := range []string{"markPullRequestReadyForReview", "convertPullRequestToDraft"} {
+						if strings.Contains(req.Query, name) {
+							mutated = name
+						}
+					}
+
+					if tt.graphqlError != "" {
+						json.NewEncoder(w).Encode(map[string]interface{}{
+							"errors": []map[string]string{{"message": tt.graphqlError}},
+						})
+						return
+					}
+
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{}})
+					return
+				}
+
+				t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.UpdatePullRequest("test-repo", "feature-branch", &scm.PROptions{Draft: boolPtr(tt.draft)})
+
+			if mutated != tt.wantMutation {
+				t.Errorf("Expected mutation %q, got %q", tt.wantMutation, mutated)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if pr.Draft != tt.draft {
+				t.Errorf("Expected draft %v, got %v", tt.draft, pr.Draft)
+			}
+		})
+	}
+}
+
+func TestApplyLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *scm.PROptions
+		wantMethod  string
+		wantRequest []string
+		response    []string
+		wantLabels  []string
+	}{
+		{
+			name:        "append labels",
+			opts:        &scm.PROptions{Labels: []string{"needs-triage"}},
+			wantMethod:  http.MethodPost,
+			wantRequest: []string{"needs-triage"},
+			response:    []string{"bug", "needs-triage"},
+			wantLabels:  []string{"bug", "needs-triage"},
+		},
+		{
+			name:        "reset labels",
+			opts:        &scm.PROptions{Labels: []string{"needs-triage"}, ResetLabels: true},
+			wantMethod:  http.MethodPut,
+			wantRequest: []string{"needs-triage"},
+			response:    []string{"needs-triage"},
+			wantLabels:  []string{"needs-triage"},
+		},
+		{
+			name:        "reset clears labels",
+			opts:        &scm.PROptions{ResetLabels: true},
+			wantMethod:  http.MethodPut,
+			wantRequest: []string{},
+			response:    []string{},
+			wantLabels:  nil,
+		},
+		{
+			name: "no labels",
+			opts: &scm.PROptions{Title: "Updated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labelRequests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls"):
+					pr := mockPRResponse(12345, 42, "Title", "", "feature-branch", true, nil)
+					pr["labels"] = []map[string]interface{}{{"name": "bug"}}
+					json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+				case r.Method == http.MethodPatch && strings.Contains(r.URL.Path, "/pulls/42"):
+					pr := mockPRResponse(12345, 42, "Updated", "", "feature-branch", true, nil)
+					pr["labels"] = []map[string]interface{}{{"name": "bug"}}
+					json.NewEncoder(w).Encode(pr)
+				case r.URL.Path == "/repos/test-org/test-repo/issues/42/labels":
+					labelRequests++
+					if r.Method != tt.wantMethod {
+						t.Errorf("Expected %s request, got %s", tt.wantMethod, r.Method)
+					}
+
+					body, _ := io.ReadAll(r.Body)
+
+					// an empty list is sent to clear the labels, since null is rejected
+					var req []string
+					if err := json.Unmarshal(body, &req); err != nil {
+						t.Errorf("Failed to decode request: %v", err)
+					}
+					if req == nil {
+						t.Errorf("Expected a list of labels, got %s", body)
+					}
+					if !slices.Equal(req, tt.wantRequest) {
+						t.Errorf("Expected labels %v, got %v", tt.wantRequest, req)
+					}
+
+					labels := make([]map[string]interface{}, len(tt.response))
+					for i, name := range tt.response {
+						labels[i] = map[string]interface{}{"name": name}
+					}
+					json.NewEncoder(w).Encode(labels)
+				default:
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.UpdatePullRequest("test-repo", "feature-branch", tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.wantMethod == "" {
+				if labelRequests != 0 {
+					t.Errorf("Expected no label requests, got %d", labelRequests)
+				}
+				if !slices.Equal(pr.Labels, []string{"bug"}) {
+					t.Errorf("Expected existing labels [bug], got %v", pr.Labels)
+				}
+				return
+			}
+
+			if labelRequests != 1 {
+				t.Errorf("Expected 1 label request, got %d", labelRequests)
+			}
+			if !slices.Equal(pr.Labels, tt.wantLabels) {
+				t.Errorf("Expected labels %v, got %v", tt.wantLabels, pr.Labels)
+			}
+		})
+	}
+}
+
+func TestOpenPullRequest_WithLabels(t *testing.T) {
+	labelRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode(mockPRResponse(99999, 100, "New Feature", "", "feature-branch", true, nil))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/test-org/test-repo/issues/100/labels":
+			labelRequests++
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"name": "needs-triage"}})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:      "New Feature",
+		BaseBranch: "main",
+		Labels:     []string{"needs-triage"},
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if labelRequests != 1 {
+		t.Errorf("Expected 1 label request, got %d", labelRequests)
+	}
+	if !slices.Equal(pr.Labels, []string{"needs-triage"}) {
+		t.Errorf("Expected labels [needs-triage], got %v", pr.Labels)
+	}
+}
+
+func TestOpenPullRequest_AutoMerge(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		wantMethod   any
+		graphqlError string
+		wantErr      error
+		wantErrMsg   string
+		wantAuto     string
+	}{
+		{
+			name:       "default method",
+			wantMethod: nil,
+			wantAuto:   "merge",
+		},
+		{
+			name:       "squash",
+			method:     "squash",
+			wantMethod: "SQUASH",
+			wantAuto:   "squash",
+		},
+		{
+			name:         "auto-merge not enabled for the repository",
+			method:       "squash",
+			wantMethod:   "SQUASH",
+			graphqlError: "Pull request Protected branch rules not configured for this branch",
+			wantErr:      errAutoMergeUnavailable,
+			wantErrMsg:   "opened pull request #100, but failed to enable auto-merge",
+		},
+		{
+			name:         "other mutation error",
+			method:       "merge",
+			wantMethod:   "MERGE",
+			graphqlError: "Pull request is in clean status",
+			wantErrMsg:   "Pull request is in clean status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutations := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{})
+				case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/pulls"):
+					pr := mockPRResponse(99999, 100, "Bump deps", "", "feature-branch", true, nil)
+					pr["node_id"] = "PR_node100"
+					json.NewEncoder(w).Encode(pr)
+				case r.Method == http.MethodPost && r.URL.Path == "/graphql":
+					mutations++
+
+					var req struct {
+						Query     string         `json:"query"`
+						Variables map[string]any `json:"variables"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Errorf("Failed to decode request: %v", err)
+					}
+
+					if !strings.Contains(req.Query, "enablePullRequestAutoMerge") {
+						t.Errorf("Expected enablePullRequestAutoMerge mutation, got %q", req.Query)
+					}
+					if req.Variables["id"] != "PR_node100" {
+						t.Errorf("Expected pull request node ID 'PR_node100', got %v", req.Variables["id"])
+					}
+					if req.Variables["method"] != tt.wantMethod {
+						t.Errorf("Expected merge method %v, got %v", tt.wantMethod, req.Variables["method"])
+					}
+
+					if tt.graphqlError != "" {
+						json.NewEncoder(w).Encode(map[string]interface{}{
+							"errors": []map[string]string{{"message": tt.graphqlError}},
+						})
+						return
+					}
+
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{}})
+				default:
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+				Title:           "Bump deps",
+				BaseBranch:      "main",
+				AutoMerge:       true,
+				AutoMergeMethod: tt.method,
+			})
+
+			if mutations != 1 {
+				t.Errorf("Expected 1 auto-merge mutation, got %d", mutations)
+			}
+
+			if tt.wantErrMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrMsg) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErrMsg, err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error to wrap %v, got %v", tt.wantErr, err)
+				}
+
+				// the opened pull request is still returned, so that it can be reported
+				if pr == nil || pr.Number != 100 {
+					t.Errorf("Expected the opened pull request #100 to be returned, got %+v", pr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if pr.AutoMerge != tt.wantAuto {
+				t.Errorf("Expected auto-merge method %q, got %q", tt.wantAuto, pr.AutoMerge)
+			}
+		})
+	}
+}
+
+func TestGraphqlURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{baseURL: "https://api.github.com/", want: "https://api.github.com/graphql"},
+		{baseURL: "https://github.example.com/api/v3/", want: "https://github.example.com/api/graphql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(tt.baseURL)
+
+			g := &Github{client: client}
+			if got := g.graphqlURL(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMergePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		// Get PR by branch
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
 			prs := []map[string]interface{}{
 				mockPRResponse(12345, 42, "PR to Merge", "", "feature-branch", true, nil),
 			}
 			json.NewEncoder(w).Encode(prs)
-			return
-		}
 
-		// Phase 2: Merge PR
-		if requestPhase == 2 && r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/merge") {
+		// Get PR by number to check its mergeable state
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "PR to Merge", "", "feature-branch", true, nil))
+
+		// Merge PR
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/pulls/42/merge"):
 			result := map[string]interface{}{
 				"sha":     "abc123",
 				"merged":  true,
 				"message": "Pull Request successfully merged",
 			}
 			json.NewEncoder(w).Encode(result)
-			return
+
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
 		}
 	}))
 	defer server.Close()
@@ -442,25 +1374,252 @@ func TestMergePullRequest(t *testing.T) {
 	if pr.Number != 42 {
 		t.Errorf("Expected PR number 42, got %d", pr.Number)
 	}
+
+	if pr.MergeCommit != "abc123" {
+		t.Errorf("Expected merge commit abc123, got %q", pr.MergeCommit)
+	}
 }
 
 func TestMergePullRequest_NotMergeable(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-		prs := []map[string]interface{}{
-			mockPRResponse(12345, 42, "PR", "", "feature-branch", false, nil),
+	tests := []struct {
+		state      string
+		mergeable  bool
+		wantReason string
+	}{
+		{state: "dirty", wantReason: "merge conflicts with the base branch (resolve the conflicts)"},
+		{state: "behind", mergeable: true, wantReason: "branch is out of date with the base branch (rebase or update the branch)"},
+		{state: "blocked", mergeable: true, wantReason: "blocked by required status checks or reviews (wait for the checks to pass or for approval)"},
+		{state: "unknown", wantReason: "mergeability has not been determined yet (try again shortly)"},
+		{state: "draft", wantReason: "pull request is a draft (mark it as ready for review)"},
+		{state: "", wantReason: "mergeability has not been determined yet (try again shortly)"},
+		{state: "surprising", wantReason: `mergeable state "surprising"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pr := mockPRResponse(12345, 42, "PR", "", "feature-branch", tt.mergeable, nil)
+				pr["mergeable_state"] = tt.state
+
+				switch {
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+					json.NewEncoder(w).Encode(pr)
+				default:
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			_, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true})
+
+			if err == nil {
+				t.Fatal("Expected error for non-mergeable PR")
+			}
+			if !strings.Contains(err.Error(), "is not mergeable: "+tt.wantReason) {
+				t.Errorf("Unexpected error message: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_DryRun(t *testing.T) {
+	tests := []struct {
+		state      string
+		mergeable  bool
+		wantReason string
+	}{
+		{state: "clean", mergeable: true},
+		{state: "dirty", wantReason: "merge conflicts with the base branch (resolve the conflicts)"},
+		{state: "behind", mergeable: true, wantReason: "branch is out of date with the base branch (rebase or update the branch)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				pr := mockPRResponse(12345, 42, "PR", "", "feature-branch", tt.mergeable, nil)
+				pr["mergeable_state"] = tt.state
+
+				switch {
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+				case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+					json.NewEncoder(w).Encode(pr)
+				default:
+					// neither the merge nor the update-branch endpoint is called
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			pr, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{DryRun: true, UpdateBranch: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if pr.Number != 42 {
+				t.Errorf("Expected PR number 42, got %d", pr.Number)
+			}
+
+			if pr.MergeBlocker != tt.wantReason {
+				t.Errorf("Expected merge blocker %q, got %q", tt.wantReason, pr.MergeBlocker)
+			}
+
+			if pr.Mergeable != (tt.wantReason == "") {
+				t.Errorf("Expected mergeable to be %v, got %v", tt.wantReason == "", pr.Mergeable)
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_UpdateBranch(t *testing.T) {
+	var updated, merged bool
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr := mockPRResponse(12345, 42, "PR", "", "feature-branch", true, nil)
+		pr["head"] = map[string]interface{}{"ref": "feature-branch", "sha": "abc123"}
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			// the update completes in the background after the first poll
+			if !updated || polls == 0 {
+				pr["mergeable_state"] = "behind"
+			}
+			if updated {
+				polls++
+			}
+			json.NewEncoder(w).Encode(pr)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/pulls/42/update-branch"):
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["expected_head_sha"] != "abc123" {
+				t.Errorf("Expected head SHA abc123, got %v", body["expected_head_sha"])
+			}
+
+			updated = true
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "Updating pull request branch."})
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/pulls/42/merge"):
+			if !updated {
+				t.Error("Expected the branch to be updated before merging")
+			}
+
+			merged = true
+			json.NewEncoder(w).Encode(map[string]interface{}{"merged": true})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
 		}
-		json.NewEncoder(w).Encode(prs)
 	}))
 	defer server.Close()
 
 	g := newTestGithub(t, server)
-	_, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true})
+	if _, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true, UpdateBranch: true}); err != nil {
+		t.Fatalf("MergePullRequest() error = %v", err)
+	}
 
-	if err == nil {
-		t.Fatal("Expected error for non-mergeable PR")
+	if !merged {
+		t.Error("Expected the pull request to be merged")
 	}
-	if !strings.Contains(err.Error(), "not mergeable") {
-		t.Errorf("Unexpected error message: %v", err)
+	if polls != 2 {
+		t.Errorf("Expected to poll until the update completed, got %d polls", polls)
+	}
+}
+
+func TestMergePullRequest_UpdateBranchFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr := mockPRResponse(12345, 42, "PR", "", "feature-branch", true, nil)
+		pr["mergeable_state"] = "behind"
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			json.NewEncoder(w).Encode(pr)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/pulls/42/update-branch"):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "merge conflict between base and head"})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	_, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{UpdateBranch: true})
+
+	if err == nil || !strings.Contains(err.Error(), "failed to update pull request branch") {
+		t.Errorf("Expected update branch error, got: %v", err)
+	}
+}
+
+func TestMergePullRequest_UpdateBranchCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pr := mockPRResponse(12345, 42, "PR", "", "feature-branch", true, nil)
+		pr["mergeable_state"] = "behind"
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{pr})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls/42"):
+			json.NewEncoder(w).Encode(pr)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/pulls/42/update-branch"):
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "Updating pull request branch."})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	config.Viper(g.ctx).Set(config.WriteBackoff, "1m")
+
+	ctx, cancel := context.WithCancel(g.ctx)
+	g.ctx = ctx
+
+	// cancel while waiting to poll the updated branch, which must not wait out the backoff
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := g.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{UpdateBranch: true})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the poll to be interrupted by cancellation, took %s", elapsed)
+	}
+}
+
+func TestMergeBlocker(t *testing.T) {
+	tests := []struct {
+		state     string
+		mergeable bool
+		want      string
+	}{
+		{state: "clean", mergeable: true, want: ""},
+		{state: "unstable", mergeable: true, want: ""},
+		{state: "has_hooks", mergeable: true, want: ""},
+		{state: "dirty", want: mergeBlockers["dirty"]},
+		{state: "behind", mergeable: true, want: mergeBlockers["behind"]},
+		{state: "blocked", mergeable: true, want: mergeBlockers["blocked"]},
+		{state: "unknown", want: mergeBlockers["unknown"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			pr := &github.PullRequest{MergeableState: github.Ptr(tt.state), Mergeable: github.Ptr(tt.mergeable)}
+
+			if got := mergeBlocker(pr); got != tt.want {
+				t.Errorf("mergeBlocker() = %q, want %q", got, tt.want)
+			}
+		})
 	}
 }
 
@@ -772,7 +1931,7 @@ func TestReplaceReviewers_AllNew(t *testing.T) {
 
 	g := newTestGithub(t, server)
 
-	_, err := g.replaceReviewers("test-repo", 42, []string{"charlie", "david"})
+	_, _, err := g.replaceReviewers("test-repo", 42, []string{"charlie", "david"})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -827,7 +1986,7 @@ func TestReplaceReviewers_PartialOverlap(t *testing.T) {
 	g := newTestGithub(t, server)
 
 	// Replace alice,bob with bob,charlie (keep bob, remove alice, add charlie)
-	_, err := g.replaceReviewers("test-repo", 42, []string{"bob", "charlie"})
+	_, _, err := g.replaceReviewers("test-repo", 42, []string{"bob", "charlie"})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -865,7 +2024,7 @@ func TestReplaceReviewers_SameReviewers(t *testing.T) {
 	g := newTestGithub(t, server)
 
 	// Same reviewers - no changes needed
-	_, err := g.replaceReviewers("test-repo", 42, []string{"alice", "bob"})
+	_, _, err := g.replaceReviewers("test-repo", 42, []string{"alice", "bob"})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -875,6 +2034,80 @@ func TestReplaceReviewers_SameReviewers(t *testing.T) {
 	}
 }
 
+func TestReplaceReviewers_SkipsInvalid(t *testing.T) {
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/requested_reviewers"):
+			reviewers := map[string]interface{}{
+				"users": []map[string]interface{}{
+					{"login": "alice"},
+				},
+			}
+			json.NewEncoder(w).Encode(reviewers)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			var req github.ReviewersRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			// GitHub rejects the whole request if any reviewer is invalid
+			if slices.Contains(req.Reviewers, "ghost") {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(map[string]string{"message": "Reviews may only be requested from collaborators."})
+				return
+			}
+
+			requested = append(requested, req.Reviewers...)
+			json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "Title", "", "branch", true, requested))
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls/42"):
+			json.NewEncoder(w).Encode(mockPRResponse(12345, 42, "Title", "", "branch", true, requested))
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+
+	pr, skipped, err := g.replaceReviewers("test-repo", 42, []string{"bob", "ghost", "charlie"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := strings.Join(skipped, ","); got != "ghost" {
+		t.Errorf("Expected skipped reviewers 'ghost', got %q", got)
+	}
+
+	if got := strings.Join(requested, ","); got != "bob,charlie" {
+		t.Errorf("Expected valid reviewers 'bob,charlie' to be requested, got %q", got)
+	}
+
+	if len(pr.RequestedReviewers) != 2 {
+		t.Errorf("Expected 2 requested reviewers, got %d", len(pr.RequestedReviewers))
+	}
+}
+
+func TestReplaceReviewers_OtherErrorsFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"users": []map[string]interface{}{}})
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Internal error"})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+
+	if _, _, err := g.replaceReviewers("test-repo", 42, []string{"bob"}); err == nil {
+		t.Fatal("Expected error for API failure")
+	}
+}
+
 func TestListReviewers(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if !strings.Contains(r.URL.Path, "/requested_reviewers") {
@@ -1059,7 +2292,7 @@ func TestMergePullRequest_APIError(t *testing.T) {
 	defer server.Close()
 
 	g := newTestGithub(t, server)
-	err := g.mergePullRequest("test-repo", 42, "invalid-merge-method")
+	_, err := g.mergePullRequest("test-repo", 42, "invalid-merge-method")
 
 	if err == nil {
 		t.Fatal("Expected error for API failure")
@@ -1171,3 +2404,180 @@ func TestTeamReviewersWithMixedReviewers(t *testing.T) {
 		t.Fatal("expected PR, got nil")
 	}
 }
+
+func TestGetReviewStatus(t *testing.T) {
+	reviews := []map[string]interface{}{
+		{"user": map[string]interface{}{"login": "alice"}, "state": "COMMENTED"},
+		{"user": map[string]interface{}{"login": "bob"}, "state": "APPROVED"},
+		{"user": map[string]interface{}{"login": "alice"}, "state": "APPROVED"},
+		{"user": map[string]interface{}{"login": "carol"}, "state": "CHANGES_REQUESTED"},
+		{"user": map[string]interface{}{"login": "bob"}, "state": "COMMENTED"},
+		{"user": map[string]interface{}{"login": "carol"}, "state": "DISMISSED"},
+	}
+
+	tests := []struct {
+		name          string
+		protection    int // HTTP status returned for branch protection
+		requiredCount int
+		wantRequired  int
+		wantApproved  bool
+	}{
+		{name: "protected branch", protection: http.StatusOK, requiredCount: 2, wantRequired: 2, wantApproved: true},
+		{name: "protection requires more approvals", protection: http.StatusOK, requiredCount: 3, wantRequired: 3, wantApproved: false},
+		{name: "protection requires no approvals", protection: http.StatusOK, requiredCount: 0, wantRequired: 1, wantApproved: true},
+		{name: "unprotected branch", protection: http.StatusNotFound, wantRequired: 1, wantApproved: true},
+		{name: "protection not readable", protection: http.StatusForbidden, wantRequired: 1, wantApproved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/branches/main/protection"):
+					if tt.protection != http.StatusOK {
+						w.WriteHeader(tt.protection)
+						json.NewEncoder(w).Encode(map[string]interface{}{"message": "Not Found"})
+						return
+					}
+
+					json.NewEncoder(w).Encode(map[string]interface{}{
+						"required_pull_request_reviews": map[string]interface{}{
+							"required_approving_review_count": tt.requiredCount,
+						},
+					})
+				case strings.HasSuffix(r.URL.Path, "/pulls/42/reviews"):
+					json.NewEncoder(w).Encode(reviews)
+				case strings.HasSuffix(r.URL.Path, "/pulls"):
+					json.NewEncoder(w).Encode([]map[string]interface{}{
+						mockPRResponse(12345, 42, "Test PR", "", "feature-branch", true, nil),
+					})
+				default:
+					t.Errorf("Unexpected path: %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			status, err := g.GetReviewStatus("test-repo", "feature-branch")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if status.RequiredApprovals != tt.wantRequired {
+				t.Errorf("Expected %d required approvals, got %d", tt.wantRequired, status.RequiredApprovals)
+			}
+
+			// the latest decisive review from each reviewer determines their decision
+			if len(status.Approvers) != 2 || status.Approvers[0] != "bob" || status.Approvers[1] != "alice" {
+				t.Errorf("Expected approvers [bob alice], got %v", status.Approvers)
+			}
+			if len(status.ChangesRequested) != 0 {
+				t.Errorf("Expected dismissed change request to be ignored, got %v", status.ChangesRequested)
+			}
+
+			if status.Approved() != tt.wantApproved {
+				t.Errorf("Expected approved=%v, got %+v", tt.wantApproved, status)
+			}
+		})
+	}
+}
+
+func TestGetReviewStatus_ChangesRequested(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/protection"):
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "Branch not protected"})
+		case strings.HasSuffix(r.URL.Path, "/reviews"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"user": map[string]interface{}{"login": "alice"}, "state": "APPROVED"},
+				{"user": map[string]interface{}{"login": "bob"}, "state": "CHANGES_REQUESTED"},
+			})
+		default:
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				mockPRResponse(12345, 42, "Test PR", "", "feature-branch", true, nil),
+			})
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	status, err := g.GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if status.Approved() {
+		t.Errorf("Expected outstanding change request to block approval, got %+v", status)
+	}
+	if len(status.ChangesRequested) != 1 || status.ChangesRequested[0] != "bob" {
+		t.Errorf("Expected changes requested by bob, got %v", status.ChangesRequested)
+	}
+}
+
+func TestGetReviewStatus_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/protection"):
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasSuffix(r.URL.Path, "/reviews"):
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				mockPRResponse(12345, 42, "Test PR", "", "feature-branch", true, nil),
+			})
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	if _, err := g.GetReviewStatus("test-repo", "feature-branch"); err == nil || !strings.Contains(err.Error(), "failed to list reviews") {
+		t.Errorf("Expected review listing error, got: %v", err)
+	}
+}
+
+func TestOpenPullRequest_WithAssignees(t *testing.T) {
+	var assigned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode([]map[string]interface{}{})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/pulls"):
+			json.NewEncoder(w).Encode(mockPRResponse(99999, 100, "New Feature", "", "feature-branch", true, nil))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/issues/100/assignees"):
+			var req struct {
+				Assignees []string `json:"assignees"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			assigned = req.Assignees
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"number":    100,
+				"assignees": []map[string]interface{}{{"login": "octocat"}},
+			})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+	pr, err := g.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:      "New Feature",
+		BaseBranch: "main",
+		Assignees:  []string{"octocat"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(assigned) != 1 || assigned[0] != "octocat" {
+		t.Errorf("Expected octocat to be assigned, got %v", assigned)
+	}
+	if len(pr.Assignees) != 1 || pr.Assignees[0] != "octocat" {
+		t.Errorf("Expected assignees [octocat], got %v", pr.Assignees)
+	}
+}
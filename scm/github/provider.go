@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -31,9 +32,14 @@ var (
 		TeamReviewers:  true,
 		ResetReviewers: true,
 		Draft:          true,
+		Assignees:      true,
+		Labels:         true,
+		CreateBase:     true,
+		AutoMerge:      true,
 
 		MergeMethods:   []string{"merge", "squash", "rebase"},
 		CheckMergeable: true,
+		UpdateBranch:   true,
 	}
 )
 
@@ -47,11 +53,15 @@ func init() {
 // New creates a new GitHub provider instance.
 func New(ctx context.Context, project string) scm.Provider {
 	viper := config.Viper(ctx)
-	client := github.NewClient(http.DefaultClient).WithAuthToken(viper.GetString(config.AuthToken))
 
-	if host := cleanHostname(viper.GetString(config.GitHost)); host != githubSaaSHost && host != "" {
-		var err error
+	token, err := scm.AuthToken(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+	}
 
+	client := github.NewClient(httpClient(ctx)).WithAuthToken(token)
+
+	if host := cleanHostname(viper.GetString(config.GitHost)); host != githubSaaSHost && host != "" {
 		if client, err = client.WithEnterpriseURLs(
 			fmt.Sprintf("https://%s/api/v3/", host),
 			fmt.Sprintf("https://%s/api/uploads/", host),
@@ -67,6 +77,12 @@ func New(ctx context.Context, project string) scm.Provider {
 	}
 }
 
+// httpClient returns the HTTP client used for GitHub API requests. Each request is bounded by the configured
+// request timeout (if any), independently of any deadline on the overall command context.
+func httpClient(ctx context.Context) *http.Client {
+	return &http.Client{Timeout: config.Viper(ctx).GetDuration(config.GithubRequestTimeout)}
+}
+
 // Github implements the scm.Provider interface for GitHub.
 type Github struct {
 	client  *github.Client
@@ -79,6 +95,28 @@ func (g *Github) CheckCapabilities(opts *scm.PROptions) error {
 	return scm.ValidatePROptions(caps, opts)
 }
 
+// CurrentUser returns the login of the authenticated user.
+func (g *Github) CurrentUser() (string, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	user, _, err := g.client.Users.Get(g.ctx, "")
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return "", fmt.Errorf("failed to get current user: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return "", fmt.Errorf("failed to get current user: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if user, _, err = g.client.Users.Get(g.ctx, ""); err != nil {
+			return "", fmt.Errorf("failed to get current user after retry: %w", detailedError(err))
+		}
+	}
+
+	return user.GetLogin(), nil
+}
+
 // handleRateLimitError checks if the error is a rate limit error and waits for the limit to reset.
 // Returns true if a retry should be attempted, false if the error is not rate-limit related.
 // The search parameter indicates whether to check search rate limits (true) or core rate limits (false).
@@ -97,6 +135,62 @@ func (g *Github) handleRateLimitError(err error, search bool) (shouldRetry bool,
 	return true, nil
 }
 
+// isTimeout reports whether the error is a single request exceeding its timeout (see github.request-timeout), as
+// opposed to the overall run being cancelled or exceeding its deadline.
+func (g *Github) isTimeout(err error) bool {
+	var netErr net.Error
+
+	return g.ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// apiError describes an error response from the GitHub API by its message and any field-level errors,
+// instead of the request URL and raw error structs reported by go-github.
+type apiError struct {
+	resp *github.ErrorResponse
+}
+
+// detailedError wraps GitHub API error responses in an apiError, and returns any other error unchanged.
+func detailedError(err error) error {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return err
+	}
+
+	return &apiError{resp: errResp}
+}
+
+// Error implements the error interface.
+func (e *apiError) Error() string {
+	var msg strings.Builder
+
+	if e.resp.Response != nil {
+		fmt.Fprintf(&msg, "%d ", e.resp.Response.StatusCode)
+	}
+
+	msg.WriteString(e.resp.Message)
+
+	for i, fieldErr := range e.resp.Errors {
+		if i == 0 {
+			msg.WriteString(": ")
+		} else {
+			msg.WriteString("; ")
+		}
+
+		if fieldErr.Message != "" {
+			msg.WriteString(fieldErr.Message)
+		} else {
+			fmt.Fprintf(&msg, "%s.%s %s", fieldErr.Resource, fieldErr.Field, fieldErr.Code)
+		}
+	}
+
+	return msg.String()
+}
+
+// Unwrap returns the underlying error response.
+func (e *apiError) Unwrap() error {
+	return e.resp
+}
+
 func (g *Github) waitForRateLimit(search bool) error {
 	rate, err := g.checkRateLimit(search)
 	if err != nil {
@@ -115,7 +209,7 @@ func (g *Github) waitForRateLimit(search bool) error {
 func (g *Github) checkRateLimit(search bool) (*github.Rate, error) {
 	limits, _, err := g.client.RateLimit.Get(g.ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check rate limits: %w", err)
+		return nil, fmt.Errorf("failed to check rate limits: %w", detailedError(err))
 	}
 
 	if search {
@@ -145,8 +239,8 @@ func (g *Github) writeLock() (done func()) {
 	}
 
 	return func() {
-		// delay release to avoid rate limiting
-		time.Sleep(config.Viper(g.ctx).GetDuration(config.WriteBackoff))
+		// delay release to avoid rate limiting, unless cancelled (in which case no more writes follow)
+		_ = g.pause(config.Viper(g.ctx).GetDuration(config.WriteBackoff))
 		sem.Release(writeWeight)
 	}
 }
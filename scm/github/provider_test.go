@@ -3,12 +3,16 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/google/go-github/v74/github"
+
+	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
 	testhelper "github.com/ryclarke/batch-tool/utils/testing"
 )
@@ -238,3 +242,118 @@ func TestHandleRateLimitError_RetrySuccess(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/user" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"login": "octocat"})
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+
+	user, err := g.CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user != "octocat" {
+		t.Errorf("Expected login octocat, got %q", user)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Simulate a stuck request which never completes on its own
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"login": "octocat"})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	g := newTestGithub(t, server)
+	config.Viper(g.ctx).Set(config.GithubRequestTimeout, "50ms")
+
+	// Replace the test client with one using the configured request timeout
+	g.client = github.NewClient(httpClient(g.ctx))
+	g.client.BaseURL, _ = g.client.BaseURL.Parse(server.URL + "/")
+
+	start := time.Now()
+
+	if _, err := g.CurrentUser(); err == nil {
+		t.Fatal("Expected error when the request timeout is exceeded")
+	}
+
+	// The request is abandoned well before the (30s) overall command deadline
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected request to time out quickly, took %s", elapsed)
+	}
+
+	if g.ctx.Err() != nil {
+		t.Errorf("Expected the command context to remain active, got %v", g.ctx.Err())
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	ctx := loadFixture(t)
+
+	if client := httpClient(ctx); client.Timeout != 30*time.Second {
+		t.Errorf("Expected default request timeout of 30s, got %s", client.Timeout)
+	}
+
+	config.Viper(ctx).Set(config.GithubRequestTimeout, 0)
+
+	if client := httpClient(ctx); client.Timeout != 0 {
+		t.Errorf("Expected request timeout to be disabled, got %s", client.Timeout)
+	}
+}
+
+func TestDetailedError(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors []map[string]string
+		want   string
+	}{
+		{
+			name:   "message only",
+			errors: nil,
+			want:   "failed to request reviewers: 422 Validation Failed",
+		},
+		{
+			name: "field errors",
+			errors: []map[string]string{
+				{"resource": "PullRequest", "field": "reviewers", "code": "custom", "message": "reviewer ghost is not a collaborator"},
+				{"resource": "PullRequest", "field": "base", "code": "invalid"},
+			},
+			want: "failed to request reviewers: 422 Validation Failed: reviewer ghost is not a collaborator; PullRequest.base invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(map[string]interface{}{"message": "Validation Failed", "errors": tt.errors})
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			_, err := g.requestReviewers("test-repo", 42, []string{"ghost"})
+
+			testhelper.AssertEqual(t, fmt.Sprint(err), tt.want)
+
+			// the underlying error response is still available to callers
+			if !isValidationError(err) {
+				t.Errorf("Expected a validation error, got: %v", err)
+			}
+		})
+	}
+}
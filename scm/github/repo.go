@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -20,6 +21,8 @@ func (g *Github) ListRepositories() ([]*scm.Repository, error) {
 		ListOptions: github.ListOptions{PerPage: 20},
 	}
 
+	useTopics := config.Viper(g.ctx).GetBool(config.UseTopicsAsLabels)
+
 	defer g.readLock()()
 
 	for {
@@ -35,6 +38,12 @@ func (g *Github) ListRepositories() ([]*scm.Repository, error) {
 				repo.DefaultBranch = &defaultBranch
 			}
 
+			// repository topics are merged with locally-configured labels (repos.aliases) by the catalog
+			var labels []string
+			if useTopics {
+				labels = repo.Topics
+			}
+
 			output = append(output, &scm.Repository{
 				Name:          repo.GetName(),
 				Description:   repo.GetDescription(),
@@ -42,7 +51,10 @@ func (g *Github) ListRepositories() ([]*scm.Repository, error) {
 				Archived:      repo.GetArchived(),
 				Project:       g.project,
 				DefaultBranch: repo.GetDefaultBranch(),
-				Labels:        repo.Topics,
+				Labels:        labels,
+				CloneURL:      repo.GetCloneURL(),
+				SSHURL:        repo.GetSSHURL(),
+				WebURL:        repo.GetHTMLURL(),
 			})
 		}
 
@@ -71,9 +83,9 @@ func (g *Github) listRepositories(ctx context.Context, opt *github.RepositoryLis
 		}
 
 		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, nil, fmt.Errorf("failed to list repositories: %w: %w", rateErr, err)
+			return nil, nil, fmt.Errorf("failed to list repositories: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, nil, fmt.Errorf("failed to list repositories: %w", err)
+			return nil, nil, fmt.Errorf("failed to list repositories: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
@@ -88,7 +100,7 @@ func (g *Github) listRepositories(ctx context.Context, opt *github.RepositoryLis
 				return g.listUserRepositories(userOpt)
 			}
 
-			return nil, nil, fmt.Errorf("failed to list repositories after retry: %w", err)
+			return nil, nil, fmt.Errorf("failed to list repositories after retry: %w", detailedError(err))
 		}
 	}
 
@@ -99,16 +111,121 @@ func (g *Github) listUserRepositories(opt *github.RepositoryListByUserOptions) (
 	repos, resp, err := g.client.Repositories.ListByUser(g.ctx, g.project, opt)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, nil, fmt.Errorf("failed to list user repositories: %w: %w", rateErr, err)
+			return nil, nil, fmt.Errorf("failed to list user repositories: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, nil, fmt.Errorf("failed to list user repositories: %w", err)
+			return nil, nil, fmt.Errorf("failed to list user repositories: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if repos, resp, err = g.client.Repositories.ListByUser(g.ctx, g.project, opt); err != nil {
-			return nil, nil, fmt.Errorf("failed to list user repositories after retry: %w", err)
+			return nil, nil, fmt.Errorf("failed to list user repositories after retry: %w", detailedError(err))
 		}
 	}
 
 	return repos, resp, nil
 }
+
+// errNoDefaultBranch indicates that a repository has no usable default branch, such as a freshly created repository without any commits.
+var errNoDefaultBranch = errors.New("repository has no default branch")
+
+// defaultBranch returns the default branch of the repository, or errNoDefaultBranch if it is unset or doesn't exist yet.
+func (g *Github) defaultBranch(repo string) (string, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	resp, _, err := g.client.Repositories.Get(g.ctx, g.project, repo)
+	if err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
+			return "", fmt.Errorf("failed to get repository: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return "", fmt.Errorf("failed to get repository: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if resp, _, err = g.client.Repositories.Get(g.ctx, g.project, repo); err != nil {
+			return "", fmt.Errorf("failed to get repository after retry: %w", detailedError(err))
+		}
+	}
+
+	branch := resp.GetDefaultBranch()
+	if branch == "" {
+		return "", fmt.Errorf("%w: %s", errNoDefaultBranch, repo)
+	}
+
+	// an empty repository reports a default branch which hasn't been created yet
+	if _, httpResp, err := g.client.Repositories.GetBranch(g.ctx, g.project, repo, branch, 0); err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%w: %s", errNoDefaultBranch, repo)
+		}
+
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+
+	return branch, nil
+}
+
+// errNoBranch indicates that a branch doesn't exist in the repository.
+var errNoBranch = errors.New("branch does not exist")
+
+// branchSHA returns the SHA of the head commit of the branch, or errNoBranch if the branch doesn't exist.
+func (g *Github) branchSHA(repo, branch string) (string, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	resp, httpResp, err := g.client.Repositories.GetBranch(g.ctx, g.project, repo, branch, 0)
+	if err != nil {
+		if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%w: %s in repository %s", errNoBranch, branch, repo)
+		}
+
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+
+	return resp.GetCommit().GetSHA(), nil
+}
+
+// ensureBranch creates the branch from the head of the default branch if it doesn't exist yet.
+func (g *Github) ensureBranch(repo, branch string) error {
+	_, err := g.branchSHA(repo, branch)
+	if !errors.Is(err, errNoBranch) {
+		return err // the branch already exists, or its lookup failed
+	}
+
+	defaultBranch, err := g.defaultBranch(repo)
+	if err != nil {
+		return err
+	}
+
+	sha, err := g.branchSHA(repo, defaultBranch)
+	if err != nil {
+		return err
+	}
+
+	return g.createRef(repo, branch, sha)
+}
+
+// createRef creates a new branch in the repository pointing at the given commit SHA.
+func (g *Github) createRef(repo, branch, sha string) error {
+	// acquire write lock (and release it when done)
+	defer g.writeLock()()
+
+	ref := &github.Reference{
+		Ref:    github.Ptr("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.Ptr(sha)},
+	}
+
+	if _, _, err := g.client.Git.CreateRef(g.ctx, g.project, repo, ref); err != nil {
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return fmt.Errorf("failed to create branch %s: %w: %w", branch, rateErr, detailedError(err))
+		} else if !retry {
+			return fmt.Errorf("failed to create branch %s: %w", branch, detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if _, _, err = g.client.Git.CreateRef(g.ctx, g.project, repo, ref); err != nil {
+			return fmt.Errorf("failed to create branch %s after retry: %w", branch, detailedError(err))
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
 )
 
 // mockRepoResponse creates a GitHub repository API response
@@ -16,6 +18,9 @@ func mockRepoResponse(name, description string, private bool, defaultBranch stri
 		"private":        private,
 		"default_branch": defaultBranch,
 		"topics":         topics,
+		"clone_url":      "https://github.com/test-org/" + name + ".git",
+		"ssh_url":        "git@github.com:test-org/" + name + ".git",
+		"html_url":       "https://github.com/test-org/" + name,
 	}
 }
 
@@ -71,6 +76,15 @@ func TestListRepositories_Organization(t *testing.T) {
 	if len(repos[0].Labels) != 2 || repos[0].Labels[0] != "go" {
 		t.Errorf("Expected labels [go, cli], got %v", repos[0].Labels)
 	}
+	if repos[0].CloneURL != "https://github.com/test-org/repo-1.git" {
+		t.Errorf("Expected HTTPS clone URL, got '%s'", repos[0].CloneURL)
+	}
+	if repos[0].SSHURL != "git@github.com:test-org/repo-1.git" {
+		t.Errorf("Expected SSH clone URL, got '%s'", repos[0].SSHURL)
+	}
+	if repos[0].WebURL != "https://github.com/test-org/repo-1" {
+		t.Errorf("Expected web URL, got '%s'", repos[0].WebURL)
+	}
 
 	// Verify second repo (private)
 	if repos[1].Public != false {
@@ -313,3 +327,37 @@ func TestListRepositories_TopicsMapping(t *testing.T) {
 		}
 	}
 }
+
+func TestListRepositories_TopicsAsLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		useTopics  bool
+		wantLabels string
+	}{
+		{name: "enabled", useTopics: true, wantLabels: "backend,go"},
+		{name: "disabled", useTopics: false, wantLabels: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				json.NewEncoder(w).Encode([]map[string]interface{}{
+					mockRepoResponse("topic-repo", "", false, "main", []string{"backend", "go"}),
+				})
+			}))
+			defer server.Close()
+
+			g := newTestGithub(t, server)
+			config.Viper(g.ctx).Set(config.UseTopicsAsLabels, tt.useTopics)
+
+			repos, err := g.ListRepositories()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := strings.Join(repos[0].Labels, ","); got != tt.wantLabels {
+				t.Errorf("Expected labels %q, got %q", tt.wantLabels, got)
+			}
+		})
+	}
+}
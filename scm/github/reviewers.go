@@ -1,50 +1,67 @@
 package github
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"time"
 
-	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/google/go-github/v74/github"
 
+	"github.com/ryclarke/batch-tool/config"
 	"github.com/ryclarke/batch-tool/scm"
 )
 
 // applyAllReviewers applies the specified reviewers and team reviewers to the given pull request based on the provided options.
-func (g *Github) applyAllReviewers(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, error) {
+// It also returns any reviewers which were skipped because GitHub rejected them (e.g. deactivated accounts).
+func (g *Github) applyAllReviewers(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, []string, error) {
 	if opts == nil {
 		opts = &scm.PROptions{}
 	}
 
-	var err error
+	var (
+		skipped []string
+		err     error
+	)
 
 	if len(opts.Reviewers) > 0 {
-		if pr, err = g.applyReviewers(repo, pr, opts); err != nil {
-			return nil, err
+		if pr, skipped, err = g.applyReviewers(repo, pr, opts); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	if len(opts.TeamReviewers) > 0 {
 		if pr, err = g.applyTeamReviewers(repo, pr, opts); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return pr, nil
+	return pr, skipped, nil
 }
 
 // applyReviewers applies the specified individual reviewers to the given pull request.
-func (g *Github) applyReviewers(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, error) {
+func (g *Github) applyReviewers(repo string, pr *github.PullRequest, opts *scm.PROptions) (*github.PullRequest, []string, error) {
 	// If ResetReviewers is true, replace existing reviewers with the provided list (default behavior is to append)
 	if opts.ResetReviewers {
 		return g.replaceReviewers(repo, pr.GetNumber(), opts.Reviewers)
 	}
 
 	// GitHub's RequestReviewers API appends to existing reviewers
-	return g.requestReviewers(repo, pr.GetNumber(), opts.Reviewers)
+	pr, err := g.requestReviewers(repo, pr.GetNumber(), opts.Reviewers)
+
+	return pr, nil, err
 }
 
-// requestReviewers requests the specified reviewers for the given pull request.
+// requestReviewers requests the specified reviewers for the given pull request, in chunks if there are many of them.
 func (g *Github) requestReviewers(repo string, prNumber int, reviewers []string) (*github.PullRequest, error) {
+	return g.requestInChunks(reviewers, func(chunk []string) (*github.PullRequest, error) {
+		return g.requestReviewerChunk(repo, prNumber, chunk)
+	})
+}
+
+// requestReviewerChunk requests a single chunk of reviewers for the given pull request.
+func (g *Github) requestReviewerChunk(repo string, prNumber int, reviewers []string) (*github.PullRequest, error) {
 	// acquire write lock (and release it when done)
 	defer g.writeLock()()
 
@@ -53,52 +70,134 @@ func (g *Github) requestReviewers(repo string, prNumber int, reviewers []string)
 	resp, _, err := g.client.PullRequests.RequestReviewers(g.ctx, g.project, repo, prNumber, req)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return nil, fmt.Errorf("failed to request reviewers: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to request reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to request reviewers: %w", err)
+			return nil, fmt.Errorf("failed to request reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if resp, _, err = g.client.PullRequests.RequestReviewers(g.ctx, g.project, repo, prNumber, req); err != nil {
-			return nil, fmt.Errorf("failed to request reviewers after retry: %w", err)
+			return nil, fmt.Errorf("failed to request reviewers after retry: %w", detailedError(err))
 		}
 	}
 
 	return resp, nil
 }
 
-// replaceReviewers replaces the current reviewers with the provided list
-func (g *Github) replaceReviewers(repo string, prNumber int, newReviewers []string) (*github.PullRequest, error) {
+// requestInChunks splits the reviewers into chunks of the configured size and requests each chunk in turn, pausing
+// between chunks to stay under GitHub's secondary rate limits. GitHub appends requested reviewers, so the pull request
+// returned for the final chunk includes the reviewers from every chunk.
+func (g *Github) requestInChunks(reviewers []string, request func(chunk []string) (*github.PullRequest, error)) (*github.PullRequest, error) {
+	viper := config.Viper(g.ctx)
+
+	size := viper.GetInt(config.GithubReviewerChunkSize)
+	if size <= 0 || size > len(reviewers) {
+		size = max(len(reviewers), 1)
+	}
+
+	var (
+		pr  *github.PullRequest
+		err error
+	)
+
+	for i, chunk := range slices.Collect(slices.Chunk(reviewers, size)) {
+		if i > 0 {
+			if err = g.pause(viper.GetDuration(config.GithubReviewerChunkDelay)); err != nil {
+				return nil, err
+			}
+		}
+
+		if pr, err = request(chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	return pr, nil
+}
+
+// pause waits for the given duration, returning early with an error if the context is cancelled.
+func (g *Github) pause(delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-g.ctx.Done():
+		return g.ctx.Err()
+	}
+}
+
+// replaceReviewers replaces the current reviewers with the provided list. Reviewers which GitHub rejects as invalid
+// (e.g. deactivated accounts) are skipped and returned, so the remaining reviewers are still applied.
+func (g *Github) replaceReviewers(repo string, prNumber int, newReviewers []string) (*github.PullRequest, []string, error) {
 	// Get current reviewers
 	currentReviewers, err := g.listReviewers(repo, prNumber)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Build sets for comparison
-	currentSet := mapset.NewSet(currentReviewers...)
-	newSet := mapset.NewSet(newReviewers...)
-
 	// Find reviewers to add or remove
-	toRemove := currentSet.Difference(newSet)
-	toAdd := newSet.Difference(currentSet)
+	toAdd, toRemove := scm.DiffReviewers(currentReviewers, newReviewers, true)
 
 	// Remove old reviewers
-	if toRemove.Cardinality() > 0 {
-		if err = g.removeReviewers(repo, prNumber, toRemove.ToSlice()); err != nil {
-			return nil, err
+	if len(toRemove) > 0 {
+		if err = g.removeReviewers(repo, prNumber, toRemove); err != nil {
+			return nil, nil, err
 		}
 	}
 
+	var skipped []string
+
 	// Add new reviewers
-	if toAdd.Cardinality() > 0 {
-		if _, err = g.requestReviewers(repo, prNumber, toAdd.ToSlice()); err != nil {
-			return nil, err
+	if len(toAdd) > 0 {
+		if skipped, err = g.requestValidReviewers(repo, prNumber, toAdd); err != nil {
+			return nil, nil, err
 		}
 	}
 
 	// Refresh PR to get updated reviewer list
-	return g.getPullRequestByNumber(repo, prNumber)
+	pr, err := g.getPullRequestByNumber(repo, prNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pr, skipped, nil
+}
+
+// requestValidReviewers requests the specified reviewers for the given pull request. GitHub rejects the whole request
+// if any login is invalid without saying which, so on a validation failure each reviewer is requested individually
+// and the logins which are rejected are skipped and returned.
+func (g *Github) requestValidReviewers(repo string, prNumber int, reviewers []string) ([]string, error) {
+	_, err := g.requestReviewers(repo, prNumber, reviewers)
+	if err == nil || !isValidationError(err) {
+		return nil, err
+	}
+
+	var skipped []string
+
+	for _, reviewer := range reviewers {
+		if _, err := g.requestReviewers(repo, prNumber, []string{reviewer}); err != nil {
+			if !isValidationError(err) {
+				return nil, err
+			}
+
+			skipped = append(skipped, reviewer)
+		}
+	}
+
+	return skipped, nil
+}
+
+// isValidationError reports whether the error is a validation failure (422 Unprocessable Entity) from the GitHub API.
+func isValidationError(err error) bool {
+	var errResp *github.ErrorResponse
+
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnprocessableEntity
 }
 
 // listReviewers returns a list of usernames of the reviewers for the given pull request.
@@ -109,14 +208,14 @@ func (g *Github) listReviewers(repo string, prNumber int) ([]string, error) {
 	reviewers, _, err := g.client.PullRequests.ListReviewers(g.ctx, g.project, repo, prNumber, nil)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, fmt.Errorf("failed to list reviewers: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to list reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to list reviewers: %w", err)
+			return nil, fmt.Errorf("failed to list reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if reviewers, _, err = g.client.PullRequests.ListReviewers(g.ctx, g.project, repo, prNumber, nil); err != nil {
-			return nil, fmt.Errorf("failed to list reviewers after retry: %w", err)
+			return nil, fmt.Errorf("failed to list reviewers after retry: %w", detailedError(err))
 		}
 	}
 
@@ -140,14 +239,14 @@ func (g *Github) removeReviewers(repo string, prNumber int, reviewers []string)
 	_, err := g.client.PullRequests.RemoveReviewers(g.ctx, g.project, repo, prNumber, github.ReviewersRequest{Reviewers: reviewers})
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return fmt.Errorf("failed to remove reviewers: %w: %w", rateErr, err)
+			return fmt.Errorf("failed to remove reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return fmt.Errorf("failed to remove reviewers: %w", err)
+			return fmt.Errorf("failed to remove reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if _, err = g.client.PullRequests.RemoveReviewers(g.ctx, g.project, repo, prNumber, github.ReviewersRequest{Reviewers: reviewers}); err != nil {
-			return fmt.Errorf("failed to remove reviewers after retry: %w", err)
+			return fmt.Errorf("failed to remove reviewers after retry: %w", detailedError(err))
 		}
 	}
 
@@ -165,8 +264,15 @@ func (g *Github) applyTeamReviewers(repo string, pr *github.PullRequest, opts *s
 	return g.requestTeamReviewers(repo, pr.GetNumber(), opts.TeamReviewers)
 }
 
-// requestTeamReviewers requests the specified team reviewers for the given pull request.
+// requestTeamReviewers requests the specified team reviewers for the given pull request, in chunks if there are many of them.
 func (g *Github) requestTeamReviewers(repo string, prNumber int, teamReviewers []string) (*github.PullRequest, error) {
+	return g.requestInChunks(teamReviewers, func(chunk []string) (*github.PullRequest, error) {
+		return g.requestTeamReviewerChunk(repo, prNumber, chunk)
+	})
+}
+
+// requestTeamReviewerChunk requests a single chunk of team reviewers for the given pull request.
+func (g *Github) requestTeamReviewerChunk(repo string, prNumber int, teamReviewers []string) (*github.PullRequest, error) {
 	// acquire write lock (and release it when done)
 	defer g.writeLock()()
 
@@ -175,14 +281,14 @@ func (g *Github) requestTeamReviewers(repo string, prNumber int, teamReviewers [
 	resp, _, err := g.client.PullRequests.RequestReviewers(g.ctx, g.project, repo, prNumber, req)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return nil, fmt.Errorf("failed to request team reviewers: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to request team reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to request team reviewers: %w", err)
+			return nil, fmt.Errorf("failed to request team reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if resp, _, err = g.client.PullRequests.RequestReviewers(g.ctx, g.project, repo, prNumber, req); err != nil {
-			return nil, fmt.Errorf("failed to request team reviewers after retry: %w", err)
+			return nil, fmt.Errorf("failed to request team reviewers after retry: %w", detailedError(err))
 		}
 	}
 
@@ -197,24 +303,19 @@ func (g *Github) replaceTeamReviewers(repo string, prNumber int, newTeamReviewer
 		return nil, err
 	}
 
-	// Build sets for comparison
-	currentSet := mapset.NewSet(currentTeamReviewers...)
-	newSet := mapset.NewSet(newTeamReviewers...)
-
 	// Find team reviewers to add or remove
-	toRemove := currentSet.Difference(newSet)
-	toAdd := newSet.Difference(currentSet)
+	toAdd, toRemove := scm.DiffReviewers(currentTeamReviewers, newTeamReviewers, true)
 
 	// Remove old team reviewers
-	if toRemove.Cardinality() > 0 {
-		if err = g.removeTeamReviewers(repo, prNumber, toRemove.ToSlice()); err != nil {
+	if len(toRemove) > 0 {
+		if err = g.removeTeamReviewers(repo, prNumber, toRemove); err != nil {
 			return nil, err
 		}
 	}
 
 	// Add new team reviewers
-	if toAdd.Cardinality() > 0 {
-		if _, err = g.requestTeamReviewers(repo, prNumber, toAdd.ToSlice()); err != nil {
+	if len(toAdd) > 0 {
+		if _, err = g.requestTeamReviewers(repo, prNumber, toAdd); err != nil {
 			return nil, err
 		}
 	}
@@ -231,27 +332,37 @@ func (g *Github) listTeamReviewers(repo string, prNumber int) ([]string, error)
 	reviewers, _, err := g.client.PullRequests.ListReviewers(g.ctx, g.project, repo, prNumber, nil)
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, true); rateErr != nil {
-			return nil, fmt.Errorf("failed to list team reviewers: %w: %w", rateErr, err)
+			return nil, fmt.Errorf("failed to list team reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return nil, fmt.Errorf("failed to list team reviewers: %w", err)
+			return nil, fmt.Errorf("failed to list team reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if reviewers, _, err = g.client.PullRequests.ListReviewers(g.ctx, g.project, repo, prNumber, nil); err != nil {
-			return nil, fmt.Errorf("failed to list team reviewers after retry: %w", err)
+			return nil, fmt.Errorf("failed to list team reviewers after retry: %w", detailedError(err))
 		}
 	}
 
 	result := make([]string, 0, len(reviewers.Teams))
 	for _, team := range reviewers.Teams {
 		if team.Organization != nil && team.Slug != nil {
-			result = append(result, fmt.Sprintf("%s/%s", team.Organization.GetLogin(), team.GetSlug()))
+			result = append(result, teamName(team))
 		}
 	}
 
 	return result, nil
 }
 
+// teamName returns the team reviewer name in the "org/team-slug" format accepted by the API,
+// falling back to the bare slug if the organization is not included in the response.
+func teamName(team *github.Team) string {
+	if team.Organization == nil {
+		return team.GetSlug()
+	}
+
+	return fmt.Sprintf("%s/%s", team.Organization.GetLogin(), team.GetSlug())
+}
+
 // removeTeamReviewers removes the specified team reviewers from the given pull request.
 func (g *Github) removeTeamReviewers(repo string, prNumber int, teamReviewers []string) error {
 	if len(teamReviewers) == 0 {
@@ -264,16 +375,118 @@ func (g *Github) removeTeamReviewers(repo string, prNumber int, teamReviewers []
 	_, err := g.client.PullRequests.RemoveReviewers(g.ctx, g.project, repo, prNumber, github.ReviewersRequest{TeamReviewers: teamReviewers})
 	if err != nil {
 		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
-			return fmt.Errorf("failed to remove team reviewers: %w: %w", rateErr, err)
+			return fmt.Errorf("failed to remove team reviewers: %w: %w", rateErr, detailedError(err))
 		} else if !retry {
-			return fmt.Errorf("failed to remove team reviewers: %w", err)
+			return fmt.Errorf("failed to remove team reviewers: %w", detailedError(err))
 		}
 
 		// retry the request after waiting for the rate limit to reset
 		if _, err = g.client.PullRequests.RemoveReviewers(g.ctx, g.project, repo, prNumber, github.ReviewersRequest{TeamReviewers: teamReviewers}); err != nil {
-			return fmt.Errorf("failed to remove team reviewers after retry: %w", err)
+			return fmt.Errorf("failed to remove team reviewers after retry: %w", detailedError(err))
 		}
 	}
 
 	return nil
 }
+
+// listReviews returns all submitted reviews for the given pull request.
+func (g *Github) listReviews(repo string, prNumber int) ([]*github.PullRequestReview, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	output := make([]*github.PullRequestReview, 0)
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		reviews, resp, err := g.client.PullRequests.ListReviews(g.ctx, g.project, repo, prNumber, opts)
+		if err != nil {
+			if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+				return nil, fmt.Errorf("failed to list reviews: %w: %w", rateErr, detailedError(err))
+			} else if !retry {
+				return nil, fmt.Errorf("failed to list reviews: %w", detailedError(err))
+			}
+
+			// retry the request after waiting for the rate limit to reset
+			if reviews, resp, err = g.client.PullRequests.ListReviews(g.ctx, g.project, repo, prNumber, opts); err != nil {
+				return nil, fmt.Errorf("failed to list reviews after retry: %w", detailedError(err))
+			}
+		}
+
+		output = append(output, reviews...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return output, nil
+}
+
+// requiredApprovals returns the number of approving reviews required by the protection rules of the given base
+// branch. If the branch is unprotected or its protection rules cannot be read, a single approval is required.
+func (g *Github) requiredApprovals(repo, branch string) (int, error) {
+	// acquire read lock (and release it when done)
+	defer g.readLock()()
+
+	protection, resp, err := g.client.Repositories.GetBranchProtection(g.ctx, g.project, repo, branch)
+	if err != nil {
+		// reading protection rules requires admin access, so treat inaccessible rules as unprotected
+		if errors.Is(err, github.ErrBranchNotProtected) || (resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden)) {
+			return 1, nil
+		}
+
+		if retry, rateErr := g.handleRateLimitError(err, false); rateErr != nil {
+			return 0, fmt.Errorf("failed to get branch protection: %w: %w", rateErr, detailedError(err))
+		} else if !retry {
+			return 0, fmt.Errorf("failed to get branch protection: %w", detailedError(err))
+		}
+
+		// retry the request after waiting for the rate limit to reset
+		if protection, _, err = g.client.Repositories.GetBranchProtection(g.ctx, g.project, repo, branch); err != nil {
+			return 0, fmt.Errorf("failed to get branch protection after retry: %w", detailedError(err))
+		}
+	}
+
+	reviewRules := protection.GetRequiredPullRequestReviews()
+	if reviewRules == nil {
+		return 1, nil
+	}
+
+	// always require at least one approval, even if the protection rules don't
+	return max(1, reviewRules.RequiredApprovingReviewCount), nil
+}
+
+// parseReviewStatus reduces the submitted reviews to the latest decisive review from each reviewer.
+func parseReviewStatus(reviews []*github.PullRequestReview, required int) *scm.ReviewStatus {
+	latest := make(map[string]string)
+	order := make([]string, 0)
+
+	for _, review := range reviews {
+		state := review.GetState()
+		if state != "APPROVED" && state != "CHANGES_REQUESTED" && state != "DISMISSED" {
+			continue // comments don't change a reviewer's decision
+		}
+
+		login := review.GetUser().GetLogin()
+		if _, exists := latest[login]; !exists {
+			order = append(order, login)
+		}
+
+		latest[login] = state
+	}
+
+	status := &scm.ReviewStatus{RequiredApprovals: required}
+
+	for _, login := range order {
+		switch latest[login] {
+		case "APPROVED":
+			status.Approvers = append(status.Approvers, login)
+		case "CHANGES_REQUESTED":
+			status.ChangesRequested = append(status.ChangesRequested, login)
+		}
+	}
+
+	return status
+}
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/ryclarke/batch-tool/config"
 )
 
 // TestListTeamReviewers tests listing team reviewers with various scenarios
@@ -327,3 +331,58 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+// TestRequestReviewersInChunks tests that reviewers beyond the chunk size are requested in multiple requests
+func TestRequestReviewersInChunks(t *testing.T) {
+	var requests [][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/test-org/test-repo/pulls/42/requested_reviewers" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req.Reviewers)
+
+		// GitHub appends reviewers, so respond with every reviewer requested so far
+		var all []string
+		for _, chunk := range requests {
+			all = append(all, chunk...)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mockPRResponse(1, 42, "Test PR", "", "feature", true, all))
+	}))
+	defer server.Close()
+
+	g := newTestGithub(t, server)
+
+	viper := config.Viper(g.ctx)
+	viper.Set(config.GithubReviewerChunkSize, 2)
+	viper.Set(config.GithubReviewerChunkDelay, time.Millisecond)
+
+	reviewers := []string{"alice", "bob", "charlie", "dave", "eve"}
+
+	pr, err := g.requestReviewers("test-repo", 42, reviewers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 chunked requests, got %d: %v", len(requests), requests)
+	}
+
+	for i, want := range [][]string{{"alice", "bob"}, {"charlie", "dave"}, {"eve"}} {
+		if !slices.Equal(requests[i], want) {
+			t.Errorf("Expected request %d to contain %v, got %v", i, want, requests[i])
+		}
+	}
+
+	if got := len(pr.RequestedReviewers); got != len(reviewers) {
+		t.Errorf("Expected %d reviewers on the pull request, got %d", len(reviewers), got)
+	}
+}
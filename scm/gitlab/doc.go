@@ -0,0 +1,14 @@
+// Copyright 2018-2026 Ryan Clarke (ryclarke-github@rkc.aleeas.com)
+//
+// Licensed under the Apache License, Version 2.0
+
+/*
+Package gitlab implements the scm.Provider contract against the GitLab API.
+
+It translates provider-neutral repository and pull-request operations into
+GitLab's v4 REST API, using merge requests as the pull request equivalent, and
+maps responses back to scm models consumed by the rest of the application. The
+project is a GitLab group (or user namespace), and the instance is reached
+through the configured base URL, falling back on the configured git host.
+*/
+package gitlab
@@ -0,0 +1,459 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// draftPrefix marks a merge request as a draft when it starts the title.
+const draftPrefix = "Draft: "
+
+// draftPattern matches the title prefixes which GitLab recognizes as marking a merge request as a draft.
+var draftPattern = regexp.MustCompile(`(?i)^\s*(\[draft\]|\(draft\)|draft:|draft\s+-|\[wip\]|wip:)\s*`)
+
+// pipelinePending lists the merge statuses which only wait on a pipeline, so the merge request can be
+// merged automatically once the pipeline succeeds.
+var pipelinePending = []string{"ci_must_pass", "ci_still_running"}
+
+// mergeBlockers describes why a merge request with each of GitLab's detailed merge statuses can't be merged.
+var mergeBlockers = map[string]string{
+	"blocked_status":           "blocked by another merge request",
+	"broken_status":            "source branch can't be merged into the target branch",
+	"checking":                 "GitLab is still checking mergeability (retry shortly)",
+	"unchecked":                "GitLab has not checked mergeability yet (retry shortly)",
+	"preparing":                "GitLab is still preparing the merge request (retry shortly)",
+	"approvals_syncing":        "GitLab is still syncing approvals (retry shortly)",
+	"ci_must_pass":             "pipeline must succeed before merging",
+	"ci_still_running":         "pipeline is still running",
+	"conflict":                 "merge conflicts must be resolved",
+	"discussions_not_resolved": "open discussions must be resolved",
+	"draft_status":             "merge request is a draft (mark it as ready)",
+	"need_rebase":              "source branch must be rebased onto the target branch",
+	"not_approved":             "merge request is missing required approvals",
+	"not_open":                 "merge request is not open",
+	"requested_changes":        "a reviewer requested changes",
+}
+
+// GetPullRequest retrieves a merge request by repository name and source branch.
+func (g *GitLab) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	resp, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePR(repo, resp), nil
+}
+
+// GetPullRequestByNumber retrieves a merge request by repository name and internal ID (its number within the repository).
+func (g *GitLab) GetPullRequestByNumber(repo string, number int) (*scm.PullRequest, error) {
+	resp, err := get[mrResp](g, g.mrURL(repo, number))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("no pull request #%d found in repository %s", number, repo)
+		}
+
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return parsePR(repo, resp), nil
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a merge request by repository name and source branch.
+func (g *GitLab) GetPullRequestHeadSHA(repo, branch string) (string, error) {
+	resp, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.SHA, nil
+}
+
+// ListOpenPullRequests lists all open merge requests in the specified repository.
+func (g *GitLab) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "opened")
+
+	resp, err := list[*mrResp](g, queryParams, "projects", g.project+"/"+repo, "merge_requests")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	output := make([]*scm.PullRequest, len(resp))
+	for i, mr := range resp {
+		output[i] = parsePR(repo, mr)
+	}
+
+	return output, nil
+}
+
+// OpenPullRequest opens a new merge request in the specified repository.
+func (g *GitLab) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	// reads are less restrictive than a failed write, so check for existing PR first
+	if existing, err := g.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, parsePR(repo, existing))
+	}
+
+	// if title is not specified, use the branch name
+	if opts.Title == "" {
+		opts.Title = branch
+	}
+
+	// use provided base branch or fall back to configured default
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = config.Viper(g.ctx).GetString(config.DefaultBranch)
+	}
+
+	payload := &mrPayload{
+		SourceBranch: branch,
+		TargetBranch: baseBranch,
+		Title:        draftTitle(opts.Title, opts.Draft != nil && *opts.Draft),
+		Description:  opts.Description,
+	}
+
+	if len(opts.Labels) > 0 {
+		labels := strings.Join(opts.Labels, ",")
+		payload.Labels = &labels
+	}
+
+	var err error
+	if payload.ReviewerIDs, err = g.userIDs(opts.Reviewers); err != nil {
+		return nil, err
+	}
+
+	if payload.AssigneeIDs, err = g.userIDs(opts.Assignees); err != nil {
+		return nil, err
+	}
+
+	mr, err := send[mrResp](g, http.MethodPost, g.repoURL(repo, nil, "merge_requests"), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	return parsePR(repo, mr), nil
+}
+
+// UpdatePullRequest updates an existing merge request.
+func (g *GitLab) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	mr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, changed, err := g.processChanges(mr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if mr, err = send[mrResp](g, http.MethodPut, g.mrURL(repo, mr.IID), payload); err != nil {
+			return nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+	}
+
+	return parsePR(repo, mr), nil
+}
+
+// MergePullRequest merges an existing merge request. When checking mergeability, a merge request which is only
+// waiting on its pipeline is set to merge automatically once the pipeline succeeds; forcing the merge skips this.
+func (g *GitLab) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PRMergeOptions{} // default options
+	}
+
+	mr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		checked := parsePR(repo, mr)
+		if !checked.Mergeable {
+			checked.MergeBlocker = mergeBlocker(mr.DetailedMergeStatus)
+		}
+
+		return checked, nil
+	}
+
+	// if no merge method specified, use the default from config (if set)
+	method := opts.Method
+	if method == "" {
+		method = config.Viper(g.ctx).GetString(config.DefaultMergeMethod)
+	}
+
+	payload := &mergePayload{SHA: mr.SHA}
+
+	switch method {
+	case "", "merge":
+		method = "merge"
+	case "squash":
+		payload.Squash = true
+	default:
+		return nil, fmt.Errorf("merge method %q is not supported by the GitLab provider", method)
+	}
+
+	if opts.CheckMergeable && mr.DetailedMergeStatus != "mergeable" {
+		if !slices.Contains(pipelinePending, mr.DetailedMergeStatus) {
+			return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable: %s", branch, mr.IID, repo, mergeBlocker(mr.DetailedMergeStatus))
+		}
+
+		payload.MergeWhenPipelineSucceeds = true
+	}
+
+	merged, err := send[mrResp](g, http.MethodPut, g.mrURL(repo, mr.IID, "merge"), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	result := parsePR(repo, merged)
+	if payload.MergeWhenPipelineSucceeds {
+		result.AutoMerge = method
+	}
+
+	return result, nil
+}
+
+// GetReviewStatus retrieves the approval state of a merge request, requiring at least one approval
+// even if the project's approval rules don't. Reviewers who requested changes are read from their review state.
+func (g *GitLab) GetReviewStatus(repo, branch string) (*scm.ReviewStatus, error) {
+	mr, err := g.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := get[approvalsResp](g, g.mrURL(repo, mr.IID, "approvals"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvals: %w", err)
+	}
+
+	reviewers, err := get[[]reviewerResp](g, g.mrURL(repo, mr.IID, "reviewers"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviewers: %w", err)
+	}
+
+	status := &scm.ReviewStatus{RequiredApprovals: max(1, approvals.ApprovalsRequired)}
+	for _, approval := range approvals.ApprovedBy {
+		status.Approvers = append(status.Approvers, approval.User.Username)
+	}
+
+	for _, reviewer := range *reviewers {
+		if reviewer.State == "requested_changes" {
+			status.ChangesRequested = append(status.ChangesRequested, reviewer.User.Username)
+		}
+	}
+
+	return status, nil
+}
+
+func (g *GitLab) getPullRequest(repo, branch string) (*mrResp, error) {
+	queryParams := url.Values{}
+	queryParams.Set("state", "opened")
+	queryParams.Set("source_branch", branch)
+
+	mrs, err := get[[]*mrResp](g, g.repoURL(repo, queryParams, "merge_requests"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	if len(*mrs) == 0 {
+		return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+	}
+
+	return (*mrs)[0], nil
+}
+
+// processChanges builds the update payload for the requested changes. GitLab replaces the reviewers and
+// assignees on update, so the existing ones are kept unless resetting.
+func (g *GitLab) processChanges(mr *mrResp, opts *scm.PROptions) (payload *mrPayload, changed bool, err error) {
+	payload = &mrPayload{}
+
+	if opts.Title != "" || opts.Draft != nil {
+		title, draft := mr.Title, mr.Draft
+		if opts.Title != "" {
+			title = opts.Title
+		}
+
+		if opts.Draft != nil {
+			draft = *opts.Draft
+		}
+
+		payload.Title = draftTitle(title, draft)
+		changed = true
+	}
+
+	if opts.Description != "" {
+		payload.Description = opts.Description
+		changed = true
+	}
+
+	if len(opts.Reviewers) > 0 {
+		reviewers := opts.Reviewers
+		if !opts.ResetReviewers {
+			reviewers = mergeUsernames(usernames(mr.Reviewers), opts.Reviewers)
+		}
+
+		if payload.ReviewerIDs, err = g.userIDs(reviewers); err != nil {
+			return nil, false, err
+		}
+
+		changed = true
+	}
+
+	if len(opts.Assignees) > 0 {
+		if payload.AssigneeIDs, err = g.userIDs(mergeUsernames(usernames(mr.Assignees), opts.Assignees)); err != nil {
+			return nil, false, err
+		}
+
+		changed = true
+	}
+
+	if opts.ResetLabels {
+		labels := strings.Join(opts.Labels, ",") // an empty list removes every label
+		payload.Labels = &labels
+		changed = true
+	} else if len(opts.Labels) > 0 {
+		payload.AddLabels = strings.Join(opts.Labels, ",")
+		changed = true
+	}
+
+	return payload, changed, nil
+}
+
+// draftTitle returns the title with GitLab's draft prefix if draft is set, and without any draft prefix otherwise.
+func draftTitle(title string, draft bool) string {
+	title = draftPattern.ReplaceAllString(title, "")
+	if draft {
+		return draftPrefix + title
+	}
+
+	return title
+}
+
+// mergeBlocker describes why a merge request with the given detailed merge status can't be merged.
+func mergeBlocker(status string) string {
+	if reason, ok := mergeBlockers[status]; ok {
+		return reason
+	}
+
+	return fmt.Sprintf("not mergeable (%s)", status)
+}
+
+// mergeUsernames appends the added usernames which aren't already in the current list.
+func mergeUsernames(current, added []string) []string {
+	output := slices.Clone(current)
+	for _, username := range added {
+		if !slices.Contains(output, username) {
+			output = append(output, username)
+		}
+	}
+
+	return output
+}
+
+// usernames returns the usernames of the given users.
+func usernames(users []userResp) []string {
+	output := make([]string, len(users))
+	for i, user := range users {
+		output[i] = user.Username
+	}
+
+	return output
+}
+
+type mrResp struct {
+	ID          int64  `json:"id"`
+	IID         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Draft       bool   `json:"draft"`
+	WebURL      string `json:"web_url"`
+
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	SHA          string `json:"sha"`
+
+	DetailedMergeStatus string `json:"detailed_merge_status"`
+	MergeCommitSHA      string `json:"merge_commit_sha"`
+	SquashCommitSHA     string `json:"squash_commit_sha"`
+
+	Reviewers []userResp `json:"reviewers"`
+	Assignees []userResp `json:"assignees"`
+	Labels    []string   `json:"labels"`
+}
+
+type mrPayload struct {
+	SourceBranch string  `json:"source_branch,omitempty"`
+	TargetBranch string  `json:"target_branch,omitempty"`
+	Title        string  `json:"title,omitempty"`
+	Description  string  `json:"description,omitempty"`
+	ReviewerIDs  []int   `json:"reviewer_ids,omitempty"`
+	AssigneeIDs  []int   `json:"assignee_ids,omitempty"`
+	Labels       *string `json:"labels,omitempty"`
+	AddLabels    string  `json:"add_labels,omitempty"`
+}
+
+type mergePayload struct {
+	Squash                    bool   `json:"squash"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds"`
+	SHA                       string `json:"sha,omitempty"`
+}
+
+type reviewerResp struct {
+	User  userResp `json:"user"`
+	State string   `json:"state"`
+}
+
+type approvalsResp struct {
+	ApprovalsRequired int `json:"approvals_required"`
+	ApprovedBy        []struct {
+		User userResp `json:"user"`
+	} `json:"approved_by"`
+}
+
+func parsePR(repo string, resp *mrResp) *scm.PullRequest {
+	pr := &scm.PullRequest{
+		ID:        int(resp.ID),
+		Number:    resp.IID,
+		Draft:     resp.Draft,
+		Mergeable: resp.DetailedMergeStatus == "mergeable",
+
+		Title:       resp.Title,
+		Description: resp.Description,
+		URL:         resp.WebURL,
+		Branch:      resp.SourceBranch,
+		BaseBranch:  resp.TargetBranch,
+		Repo:        repo,
+		Reviewers:   usernames(resp.Reviewers),
+	}
+
+	if assignees := usernames(resp.Assignees); len(assignees) > 0 {
+		pr.Assignees = assignees
+	}
+
+	if len(resp.Labels) > 0 {
+		pr.Labels = resp.Labels
+	}
+
+	// squash merges only report the squash commit unless the project also creates merge commits
+	pr.MergeCommit = resp.MergeCommitSHA
+	if pr.MergeCommit == "" {
+		pr.MergeCommit = resp.SquashCommitSHA
+	}
+
+	return pr
+}
@@ -0,0 +1,568 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+const mrsPath = "/api/v4/projects/test-org/test-repo/merge_requests"
+
+// userIDs are the IDs of the users known to the mock GitLab server.
+var userIDs = map[string]int{"alice": 11, "bob": 12, "carol": 13}
+
+// mockMR creates a GitLab merge request API response
+func mockMR(iid int, branch, title, status string, reviewers ...string) map[string]any {
+	users := make([]map[string]any, len(reviewers))
+	for i, reviewer := range reviewers {
+		users[i] = map[string]any{"id": userIDs[reviewer], "username": reviewer}
+	}
+
+	return map[string]any{
+		"id":                    iid + 1000,
+		"iid":                   iid,
+		"title":                 title,
+		"description":           "MR description",
+		"draft":                 strings.HasPrefix(title, draftPrefix),
+		"web_url":               fmt.Sprintf("https://gitlab.example.com/test-org/test-repo/-/merge_requests/%d", iid),
+		"source_branch":         branch,
+		"target_branch":         "main",
+		"sha":                   "abc123",
+		"detailed_merge_status": status,
+		"reviewers":             users,
+	}
+}
+
+// serveUser responds to a user lookup by username, reporting whether the request was one.
+func serveUser(t *testing.T, w http.ResponseWriter, r *http.Request) bool {
+	t.Helper()
+
+	if r.Method != http.MethodGet || r.URL.Path != "/api/v4/users" {
+		return false
+	}
+
+	username := r.URL.Query().Get("username")
+	if id, ok := userIDs[username]; ok {
+		writeJSON(t, w, []map[string]any{{"id": id, "username": username}})
+	} else {
+		writeJSON(t, w, []map[string]any{})
+	}
+
+	return true
+}
+
+// decodeBody decodes the JSON request body into a map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+
+	return body
+}
+
+// joinValues joins a decoded JSON list for comparison.
+func joinValues(v any) string {
+	list, _ := v.([]any)
+
+	values := make([]string, len(list))
+	for i, item := range list {
+		values[i] = fmt.Sprint(item)
+	}
+
+	return strings.Join(values, ",")
+}
+
+func TestGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		testhelper.AssertEqual(t, r.URL.EscapedPath(), "/api/v4/projects/test-org%2Ftest-repo/merge_requests")
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "opened")
+		testhelper.AssertEqual(t, r.URL.Query().Get("source_branch"), "feature-branch")
+
+		mr := mockMR(2, "feature-branch", "Test MR", "mergeable", "alice", "bob")
+		mr["assignees"] = []map[string]any{{"id": 13, "username": "carol"}}
+		mr["labels"] = []string{"bug"}
+		writeJSON(t, w, []map[string]any{mr})
+	}))
+	defer server.Close()
+
+	pr, err := newTestGitLab(t, server).GetPullRequest("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.ID, 1002)
+	testhelper.AssertEqual(t, pr.Number, 2)
+	testhelper.AssertEqual(t, pr.Title, "Test MR")
+	testhelper.AssertEqual(t, pr.Description, "MR description")
+	testhelper.AssertEqual(t, pr.URL, "https://gitlab.example.com/test-org/test-repo/-/merge_requests/2")
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, pr.Repo, "test-repo")
+	testhelper.AssertEqual(t, pr.Mergeable, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+	testhelper.AssertEqual(t, strings.Join(pr.Assignees, ","), "carol")
+	testhelper.AssertEqual(t, strings.Join(pr.Labels, ","), "bug")
+}
+
+func TestGetPullRequestNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitLab(t, server).GetPullRequest("test-repo", "feature-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no open pull request found for branch feature-branch in repository test-repo"})
+}
+
+func TestGetPullRequestByNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == mrsPath+"/404" {
+			http.NotFound(w, r)
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, mrsPath+"/7")
+
+		mr := mockMR(7, "feature-branch", "Merged MR", "not_open")
+		mr["merge_commit_sha"] = "def456"
+		writeJSON(t, w, mr)
+	}))
+	defer server.Close()
+
+	g := newTestGitLab(t, server)
+
+	pr, err := g.GetPullRequestByNumber("test-repo", 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 7)
+	testhelper.AssertEqual(t, pr.MergeCommit, "def456")
+
+	_, err = g.GetPullRequestByNumber("test-repo", 404)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no pull request #404 found in repository test-repo"})
+}
+
+func TestGetPullRequestHeadSHA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(t, w, []map[string]any{mockMR(2, "feature-branch", "Test MR", "mergeable")})
+	}))
+	defer server.Close()
+
+	sha, err := newTestGitLab(t, server).GetPullRequestHeadSHA("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, sha, "abc123")
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, mrsPath)
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "opened")
+
+		writeJSON(t, w, []map[string]any{
+			mockMR(1, "branch-1", "First MR", "mergeable"),
+			mockMR(2, "branch-2", "Second MR", "conflict"),
+		})
+	}))
+	defer server.Close()
+
+	prs, err := newTestGitLab(t, server).ListOpenPullRequests("test-repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, prs, 2)
+	testhelper.AssertEqual(t, prs[0].Branch, "branch-1")
+	testhelper.AssertEqual(t, prs[1].Mergeable, false)
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveUser(t, w, r) {
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, mrsPath)
+
+		if r.Method == http.MethodGet {
+			writeJSON(t, w, []map[string]any{})
+			return
+		}
+
+		testhelper.AssertEqual(t, r.Method, http.MethodPost)
+
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["source_branch"], "feature-branch")
+		testhelper.AssertEqual(t, body["target_branch"], "develop")
+		testhelper.AssertEqual(t, body["title"], "Draft: New feature")
+		testhelper.AssertEqual(t, body["description"], "Feature description")
+		testhelper.AssertEqual(t, joinValues(body["reviewer_ids"]), "11,12")
+		testhelper.AssertEqual(t, joinValues(body["assignee_ids"]), "13")
+		testhelper.AssertEqual(t, body["labels"], "bug,backend")
+
+		mr := mockMR(5, "feature-branch", "Draft: New feature", "draft_status", "alice", "bob")
+		mr["labels"] = []string{"bug", "backend"}
+		writeJSON(t, w, mr)
+	}))
+	defer server.Close()
+
+	draft := true
+	pr, err := newTestGitLab(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:       "New feature",
+		Description: "Feature description",
+		BaseBranch:  "develop",
+		Reviewers:   []string{"alice", "bob"},
+		Assignees:   []string{"carol"},
+		Labels:      []string{"bug", "backend"},
+		Draft:       &draft,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 5)
+	testhelper.AssertEqual(t, pr.Draft, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+	testhelper.AssertEqual(t, strings.Join(pr.Labels, ","), "bug,backend")
+}
+
+func TestOpenPullRequestUnknownReviewer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveUser(t, w, r) {
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		writeJSON(t, w, []map[string]any{})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitLab(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Reviewers: []string{"nobody"}})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no GitLab user found with username nobody"})
+}
+
+func TestOpenPullRequestAlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.Method, http.MethodGet)
+		writeJSON(t, w, []map[string]any{mockMR(3, "feature-branch", "Existing MR", "mergeable")})
+	}))
+	defer server.Close()
+
+	_, err := newTestGitLab(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Title: "New MR"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"a pull request already exists", "PR #3", "merge_requests/3"})
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	draft, ready := true, false
+
+	tests := []struct {
+		name      string
+		title     string
+		opts      *scm.PROptions
+		want      map[string]string // expected fields of the update payload, or nil if no update is sent
+		wantDraft bool
+	}{
+		{
+			name: "append reviewers",
+			opts: &scm.PROptions{Reviewers: []string{"bob"}},
+			want: map[string]string{"reviewer_ids": "11,12"},
+		},
+		{
+			name: "reset reviewers",
+			opts: &scm.PROptions{Reviewers: []string{"bob"}, ResetReviewers: true},
+			want: map[string]string{"reviewer_ids": "12"},
+		},
+		{
+			name: "title and description",
+			opts: &scm.PROptions{Title: "New title", Description: "New description"},
+			want: map[string]string{"title": "New title", "description": "New description"},
+		},
+		{
+			name: "add labels",
+			opts: &scm.PROptions{Labels: []string{"bug", "backend"}},
+			want: map[string]string{"add_labels": "bug,backend"},
+		},
+		{
+			name: "reset labels",
+			opts: &scm.PROptions{Labels: []string{"bug"}, ResetLabels: true},
+			want: map[string]string{"labels": "bug"},
+		},
+		{
+			name: "clear labels",
+			opts: &scm.PROptions{ResetLabels: true},
+			want: map[string]string{"labels": ""},
+		},
+		{
+			name:      "convert to draft",
+			opts:      &scm.PROptions{Draft: &draft},
+			want:      map[string]string{"title": "Draft: Test MR"},
+			wantDraft: true,
+		},
+		{
+			name:  "mark ready",
+			title: "Draft: Test MR",
+			opts:  &scm.PROptions{Draft: &ready},
+			want:  map[string]string{"title": "Test MR"},
+		},
+		{
+			name: "no changes",
+			opts: &scm.PROptions{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title := tt.title
+			if title == "" {
+				title = "Test MR"
+			}
+
+			updated := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if serveUser(t, w, r) {
+					return
+				}
+
+				if r.Method == http.MethodGet {
+					testhelper.AssertEqual(t, r.URL.Path, mrsPath)
+					writeJSON(t, w, []map[string]any{mockMR(2, "feature-branch", title, "mergeable", "alice")})
+					return
+				}
+
+				updated = true
+				testhelper.AssertEqual(t, r.Method, http.MethodPut)
+				testhelper.AssertEqual(t, r.URL.Path, mrsPath+"/2")
+
+				body := decodeBody(t, r)
+				testhelper.AssertEqual(t, len(body), len(tt.want))
+
+				for key, want := range tt.want {
+					got, ok := body[key].(string)
+					if !ok {
+						got = joinValues(body[key])
+					}
+
+					testhelper.AssertEqual(t, got, want)
+				}
+
+				newTitle, _ := body["title"].(string)
+				if newTitle == "" {
+					newTitle = title
+				}
+
+				writeJSON(t, w, mockMR(2, "feature-branch", newTitle, "mergeable", "alice"))
+			}))
+			defer server.Close()
+
+			pr, err := newTestGitLab(t, server).UpdatePullRequest("test-repo", "feature-branch", tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, updated, tt.want != nil)
+			testhelper.AssertEqual(t, pr.Draft, tt.wantDraft)
+		})
+	}
+}
+
+func TestMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		opts          *scm.PRMergeOptions
+		wantSquash    bool
+		wantPipeline  bool
+		wantAutoMerge string
+		wantErr       string
+	}{
+		{
+			name:   "mergeable",
+			status: "mergeable",
+			opts:   &scm.PRMergeOptions{Method: "merge", CheckMergeable: true},
+		},
+		{
+			name:       "default method",
+			status:     "mergeable",
+			opts:       &scm.PRMergeOptions{CheckMergeable: true},
+			wantSquash: true,
+		},
+		{
+			name:          "merge when pipeline succeeds",
+			status:        "ci_still_running",
+			opts:          &scm.PRMergeOptions{Method: "squash", CheckMergeable: true},
+			wantSquash:    true,
+			wantPipeline:  true,
+			wantAutoMerge: "squash",
+		},
+		{
+			name:    "not mergeable",
+			status:  "conflict",
+			opts:    &scm.PRMergeOptions{CheckMergeable: true},
+			wantErr: "pull request feature-branch [2] for test-repo is not mergeable: merge conflicts must be resolved",
+		},
+		{
+			name:   "forced merge skips the check",
+			status: "ci_still_running",
+			opts:   &scm.PRMergeOptions{Method: "merge"},
+		},
+		{
+			name:    "unsupported method",
+			status:  "mergeable",
+			opts:    &scm.PRMergeOptions{Method: "rebase"},
+			wantErr: `merge method "rebase" is not supported by the GitLab provider`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(t, w, []map[string]any{mockMR(2, "feature-branch", "Test MR", tt.status)})
+					return
+				}
+
+				merged = true
+				testhelper.AssertEqual(t, r.Method, http.MethodPut)
+				testhelper.AssertEqual(t, r.URL.Path, mrsPath+"/2/merge")
+
+				body := decodeBody(t, r)
+				testhelper.AssertEqual(t, body["squash"], tt.wantSquash)
+				testhelper.AssertEqual(t, body["merge_when_pipeline_succeeds"], tt.wantPipeline)
+				testhelper.AssertEqual(t, body["sha"], "abc123")
+
+				mr := mockMR(2, "feature-branch", "Test MR", "not_open")
+				if !tt.wantPipeline {
+					mr["merge_commit_sha"] = "def456"
+				}
+				writeJSON(t, w, mr)
+			}))
+			defer server.Close()
+
+			pr, err := newTestGitLab(t, server).MergePullRequest("test-repo", "feature-branch", tt.opts)
+			if tt.wantErr != "" {
+				testhelper.AssertError(t, err, true)
+				testhelper.AssertContains(t, err.Error(), []string{tt.wantErr})
+				testhelper.AssertEqual(t, merged, false)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, merged, true)
+			testhelper.AssertEqual(t, pr.AutoMerge, tt.wantAutoMerge)
+
+			if !tt.wantPipeline {
+				testhelper.AssertEqual(t, pr.MergeCommit, "def456")
+			}
+		})
+	}
+}
+
+func TestMergePullRequestDryRun(t *testing.T) {
+	tests := []struct {
+		status      string
+		wantBlocker string
+	}{
+		{status: "mergeable", wantBlocker: ""},
+		{status: "need_rebase", wantBlocker: "source branch must be rebased onto the target branch"},
+		{status: "draft_status", wantBlocker: "merge request is a draft (mark it as ready)"},
+		{status: "something_new", wantBlocker: "not mergeable (something_new)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+
+				writeJSON(t, w, []map[string]any{mockMR(2, "feature-branch", "Test MR", tt.status)})
+			}))
+			defer server.Close()
+
+			pr, err := newTestGitLab(t, server).MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{DryRun: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			testhelper.AssertEqual(t, pr.MergeBlocker, tt.wantBlocker)
+		})
+	}
+}
+
+func TestGetReviewStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case mrsPath:
+			writeJSON(t, w, []map[string]any{mockMR(2, "feature-branch", "Test MR", "requested_changes", "alice", "bob")})
+		case mrsPath + "/2/approvals":
+			writeJSON(t, w, map[string]any{
+				"approvals_required": 2,
+				"approved_by":        []map[string]any{{"user": map[string]any{"username": "alice"}}},
+			})
+		case mrsPath + "/2/reviewers":
+			writeJSON(t, w, []map[string]any{
+				{"user": map[string]any{"username": "alice"}, "state": "approved"},
+				{"user": map[string]any{"username": "bob"}, "state": "requested_changes"},
+			})
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	status, err := newTestGitLab(t, server).GetReviewStatus("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, status.RequiredApprovals, 2)
+	testhelper.AssertEqual(t, strings.Join(status.Approvers, ","), "alice")
+	testhelper.AssertEqual(t, strings.Join(status.ChangesRequested, ","), "bob")
+	testhelper.AssertEqual(t, status.Approved(), false)
+}
+
+func TestDraftTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		draft bool
+		want  string
+	}{
+		{title: "Add feature", draft: true, want: "Draft: Add feature"},
+		{title: "Add feature", draft: false, want: "Add feature"},
+		{title: "Draft: Add feature", draft: true, want: "Draft: Add feature"},
+		{title: "Draft: Add feature", draft: false, want: "Add feature"},
+		{title: "[WIP] Add feature", draft: false, want: "Add feature"},
+		{title: "(draft) Add feature", draft: true, want: "Draft: Add feature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			testhelper.AssertEqual(t, draftTitle(tt.title, tt.draft), tt.want)
+		})
+	}
+}
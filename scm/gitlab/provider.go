@@ -0,0 +1,305 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// pageSize is the number of items requested per page from list endpoints.
+const pageSize = 100
+
+var caps = &scm.Capabilities{
+	TeamReviewers:  false,
+	ResetReviewers: true,
+	Draft:          true,
+	Assignees:      true,
+	Labels:         true,
+
+	MergeMethods:   []string{"merge", "squash"},
+	CheckMergeable: true,
+}
+
+// userCache holds the IDs of resolved users for the duration of a run, keyed by API URL and username.
+var (
+	userMu    sync.Mutex
+	userCache = make(map[string]int)
+)
+
+var _ scm.Provider = new(GitLab)
+
+func init() {
+	// Register the GitLab provider factory
+	scm.Register("gitlab", New)
+}
+
+// New creates a new GitLab SCM provider instance.
+func New(ctx context.Context, project string) scm.Provider {
+	viper := config.Viper(ctx)
+
+	base := strings.TrimSuffix(strings.TrimSpace(viper.GetString(config.GitLabBaseURL)), "/")
+	if base == "" {
+		base = "https://" + viper.GetString(config.GitHost)
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		panic(fmt.Sprintf("gitlab: invalid base URL %q: %v", base, err))
+	}
+
+	return &GitLab{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		project: project,
+		ctx:     ctx,
+	}
+}
+
+// GitLab represents an SCM provider for the GitLab v4 API.
+type GitLab struct {
+	client  *http.Client
+	baseURL *url.URL
+	project string
+	ctx     context.Context
+}
+
+// CheckCapabilities validates that the provided PR options are supported by GitLab.
+func (g *GitLab) CheckCapabilities(opts *scm.PROptions) error {
+	return scm.ValidatePROptions(caps, opts)
+}
+
+// CurrentUser returns the username of the authenticated user.
+func (g *GitLab) CurrentUser() (string, error) {
+	resp, err := get[userResp](g, g.url(nil, "user"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return resp.Username, nil
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of GitLab tokens aren't inspected.
+func (g *GitLab) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
+// constructs the URL for the GitLab API endpoint at the given path. Each path element is escaped as a single
+// segment, so that namespaced IDs such as "group/repo" are sent as "group%2Frepo" as GitLab requires.
+func (g *GitLab) url(queryParams url.Values, path ...string) string {
+	escaped := make([]string, len(path))
+	for i, segment := range path {
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	apiURL := g.baseURL.String() + "/api/v4/" + strings.Join(escaped, "/")
+
+	// Add query parameters if provided
+	if queryParams != nil {
+		apiURL += "?" + queryParams.Encode()
+	}
+
+	return apiURL
+}
+
+// constructs the URL for an endpoint of the given repository within the provider's project.
+func (g *GitLab) repoURL(repo string, queryParams url.Values, path ...string) string {
+	return g.url(queryParams, append([]string{"projects", g.project + "/" + repo}, path...)...)
+}
+
+// constructs the URL for an endpoint of the given merge request.
+func (g *GitLab) mrURL(repo string, iid int, path ...string) string {
+	return g.repoURL(repo, nil, append([]string{"merge_requests", strconv.Itoa(iid)}, path...)...)
+}
+
+// userIDs resolves the given usernames to their GitLab user IDs.
+func (g *GitLab) userIDs(usernames []string) ([]int, error) {
+	ids := make([]int, len(usernames))
+
+	for i, username := range usernames {
+		id, err := g.userID(username)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// userID returns the ID of the user with the given username, which is looked up at most once per run.
+func (g *GitLab) userID(username string) (int, error) {
+	key := g.url(nil) + "\x00" + strings.ToLower(username)
+
+	userMu.Lock()
+	id, ok := userCache[key]
+	userMu.Unlock()
+
+	if ok {
+		return id, nil
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("username", username)
+
+	users, err := get[[]userResp](g, g.url(queryParams, "users"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up user %s: %w", username, err)
+	}
+
+	if len(*users) == 0 {
+		return 0, fmt.Errorf("no GitLab user found with username %s", username)
+	}
+
+	id = (*users)[0].ID
+
+	userMu.Lock()
+	userCache[key] = id
+	userMu.Unlock()
+
+	return id, nil
+}
+
+// apiError is returned when the GitLab API responds with an error status.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("error %d: %s", e.StatusCode, e.Body)
+}
+
+// isNotFound reports whether the error is a 404 response from the GitLab API.
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// convenience function to perform a GET request and unmarshal the response into the specified type.
+func get[T any](g *GitLab, path string) (*T, error) {
+	req, err := http.NewRequestWithContext(g.ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return do[T](g, req)
+}
+
+// convenience function to GET every page of a list endpoint and combine the results.
+func list[T any](g *GitLab, queryParams url.Values, path ...string) ([]T, error) {
+	if queryParams == nil {
+		queryParams = url.Values{}
+	}
+
+	queryParams.Set("per_page", strconv.Itoa(pageSize))
+
+	output := make([]T, 0)
+	for page := 1; ; page++ {
+		queryParams.Set("page", strconv.Itoa(page))
+
+		resp, err := get[[]T](g, g.url(queryParams, path...))
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, *resp...)
+
+		// a short page is the last one
+		if len(*resp) < pageSize {
+			break
+		}
+	}
+
+	return output, nil
+}
+
+// convenience function to send a JSON payload with the given method and unmarshal the response into the specified type.
+func send[T any](g *GitLab, method, path string, payload any) (*T, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(g.ctx, method, path, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return do[T](g, req)
+}
+
+// convenience function to perform an HTTP request and unmarshal the response into the specified type.
+func do[T any](g *GitLab, req *http.Request) (*T, error) {
+	resp, err := g.execute(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result T
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// execute authenticates and performs the HTTP request, returning an apiError for error responses.
+// The caller is responsible for closing the body of a successful response.
+func (g *GitLab) execute(req *http.Request) (*http.Response, error) {
+	token, err := scm.AuthToken(g.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	if err := parseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func parseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	output, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error %d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	return &apiError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(output))}
+}
+
+type userResp struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
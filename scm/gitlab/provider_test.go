@@ -0,0 +1,163 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func loadFixture(t *testing.T) context.Context {
+	return testhelper.LoadFixture(t, "../../config")
+}
+
+// newTestGitLab creates a GitLab provider for the "test-org" project which sends its requests to the test server.
+func newTestGitLab(t *testing.T, server *httptest.Server) *GitLab {
+	t.Helper()
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.AuthToken, "test-token")
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	return &GitLab{
+		client:  server.Client(),
+		baseURL: baseURL,
+		project: "test-org",
+		ctx:     ctx,
+	}
+}
+
+// writeJSON encodes the value as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{name: "base url", baseURL: "https://gitlab.example.com/", want: "https://gitlab.example.com/api/v4/user"},
+		{name: "base url with path", baseURL: "https://example.com/gitlab", want: "https://example.com/gitlab/api/v4/user"},
+		{name: "git host fallback", baseURL: "", want: "https://github.com/api/v4/user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+			config.Viper(ctx).Set(config.GitLabBaseURL, tt.baseURL)
+
+			provider, ok := New(ctx, "test-org").(*GitLab)
+			if !ok {
+				t.Fatalf("Expected *GitLab provider, got %T", provider)
+			}
+
+			testhelper.AssertEqual(t, provider.project, "test-org")
+			testhelper.AssertEqual(t, provider.url(nil, "user"), tt.want)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	provider := scm.Get(loadFixture(t), "gitlab", "test-org")
+
+	if _, ok := provider.(*GitLab); !ok {
+		t.Errorf("Expected *GitLab provider, got %T", provider)
+	}
+}
+
+func TestRepoURL(t *testing.T) {
+	g := &GitLab{baseURL: &url.URL{Scheme: "https", Host: "gitlab.example.com"}, project: "group/subgroup"}
+
+	// the namespaced project path is a single escaped path segment
+	testhelper.AssertEqual(t, g.repoURL("repo", nil, "merge_requests"),
+		"https://gitlab.example.com/api/v4/projects/group%2Fsubgroup%2Frepo/merge_requests")
+	testhelper.AssertEqual(t, g.mrURL("repo", 7, "merge"),
+		"https://gitlab.example.com/api/v4/projects/group%2Fsubgroup%2Frepo/merge_requests/7/merge")
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	g := &GitLab{}
+
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{Assignees: []string{"alice"}, Labels: []string{"bug"}, Draft: new(bool)}), false)
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "squash", CheckMergeable: true}}), false)
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{TeamReviewers: []string{"team"}}), true)
+	testhelper.AssertError(t, g.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "rebase"}}), true)
+}
+
+func TestCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v4/user")
+		testhelper.AssertEqual(t, r.Header.Get("PRIVATE-TOKEN"), "test-token")
+
+		writeJSON(t, w, map[string]any{"id": 1, "username": "alice"})
+	}))
+	defer server.Close()
+
+	username, err := newTestGitLab(t, server).CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, username, "alice")
+}
+
+func TestUserIDCached(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v4/users")
+
+		switch r.URL.Query().Get("username") {
+		case "alice":
+			writeJSON(t, w, []map[string]any{{"id": 11, "username": "alice"}})
+		default:
+			writeJSON(t, w, []map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	g := newTestGitLab(t, server)
+
+	for range 2 {
+		id, err := g.userID("alice")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		testhelper.AssertEqual(t, id, 11)
+	}
+
+	testhelper.AssertEqual(t, lookups, 1)
+
+	_, err := g.userID("nobody")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"no GitLab user found with username nobody"})
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"401 Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestGitLab(t, server).CurrentUser()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), []string{"error 401", "401 Unauthorized"})
+}
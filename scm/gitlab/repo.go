@@ -0,0 +1,62 @@
+package gitlab
+
+import (
+	"net/url"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+type repoResp struct {
+	Path          string   `json:"path"`
+	Description   string   `json:"description"`
+	Visibility    string   `json:"visibility"`
+	Archived      bool     `json:"archived"`
+	DefaultBranch string   `json:"default_branch"`
+	Topics        []string `json:"topics"`
+	HTTPURL       string   `json:"http_url_to_repo"`
+	SSHURL        string   `json:"ssh_url_to_repo"`
+	WebURL        string   `json:"web_url"`
+}
+
+// ListRepositories lists all repositories in the specified project.
+// Supports both group and user namespaces.
+func (g *GitLab) ListRepositories() ([]*scm.Repository, error) {
+	// only list the projects directly within the group, since subgroups are separate catalog projects
+	queryParams := url.Values{}
+	queryParams.Set("include_subgroups", "false")
+
+	repos, err := list[repoResp](g, queryParams, "groups", g.project, "projects")
+
+	// the project is not a group, so fall back on the user's repositories
+	if isNotFound(err) {
+		repos, err = list[repoResp](g, nil, "users", g.project, "projects")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.Repository, len(repos))
+	for i, repo := range repos {
+		if repo.DefaultBranch == "" {
+			// fall back on configured default branch if it isn't set for the repo
+			repo.DefaultBranch = config.Viper(g.ctx).GetString(config.DefaultBranch)
+		}
+
+		output[i] = &scm.Repository{
+			Name:          repo.Path,
+			Description:   repo.Description,
+			Public:        repo.Visibility == "public",
+			Archived:      repo.Archived,
+			Project:       g.project,
+			DefaultBranch: repo.DefaultBranch,
+			Labels:        repo.Topics,
+			CloneURL:      repo.HTTPURL,
+			SSHURL:        repo.SSHURL,
+			WebURL:        repo.WebURL,
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,91 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v4/groups/test-org/projects")
+		testhelper.AssertEqual(t, r.URL.Query().Get("include_subgroups"), "false")
+
+		// return a full first page to exercise pagination
+		var repos []map[string]any
+		if r.URL.Query().Get("page") == "1" {
+			for i := range pageSize {
+				repos = append(repos, map[string]any{"path": fmt.Sprintf("repo-%d", i), "visibility": "public", "default_branch": "main"})
+			}
+		} else {
+			repos = append(repos, map[string]any{
+				"path":             "last-repo",
+				"description":      "The last repository",
+				"visibility":       "private",
+				"archived":         true,
+				"topics":           []string{"api"},
+				"http_url_to_repo": "https://gitlab.example.com/test-org/last-repo.git",
+				"ssh_url_to_repo":  "git@gitlab.example.com:test-org/last-repo.git",
+				"web_url":          "https://gitlab.example.com/test-org/last-repo",
+			})
+		}
+
+		writeJSON(t, w, repos)
+	}))
+	defer server.Close()
+
+	repos, err := newTestGitLab(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, pageSize+1)
+	testhelper.AssertEqual(t, repos[0].Name, "repo-0")
+	testhelper.AssertEqual(t, repos[0].Public, true)
+
+	last := repos[pageSize]
+	testhelper.AssertEqual(t, last.Name, "last-repo")
+	testhelper.AssertEqual(t, last.Project, "test-org")
+	testhelper.AssertEqual(t, last.Public, false)
+	testhelper.AssertEqual(t, last.Archived, true)
+	testhelper.AssertEqual(t, last.DefaultBranch, "main") // configured fallback
+	testhelper.AssertEqual(t, strings.Join(last.Labels, ","), "api")
+	testhelper.AssertEqual(t, last.CloneURL, "https://gitlab.example.com/test-org/last-repo.git")
+	testhelper.AssertEqual(t, last.SSHURL, "git@gitlab.example.com:test-org/last-repo.git")
+	testhelper.AssertEqual(t, last.WebURL, "https://gitlab.example.com/test-org/last-repo")
+}
+
+func TestListRepositoriesUserFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/groups/test-org/projects" {
+			http.NotFound(w, r)
+			return
+		}
+
+		testhelper.AssertEqual(t, r.URL.Path, "/api/v4/users/test-org/projects")
+		writeJSON(t, w, []map[string]any{{"path": "personal-repo"}})
+	}))
+	defer server.Close()
+
+	repos, err := newTestGitLab(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, 1)
+	testhelper.AssertEqual(t, repos[0].Name, "personal-repo")
+}
+
+func TestListRepositoriesAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := newTestGitLab(t, server).ListRepositories()
+	testhelper.AssertError(t, err, true)
+}
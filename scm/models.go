@@ -9,6 +9,12 @@ type Repository struct {
 	Project       string   `json:"project"`
 	DefaultBranch string   `json:"default_branch"`
 	Labels        []string `json:"labels,omitempty"`
+
+	// CloneURL and SSHURL are the HTTPS and SSH clone URLs, and WebURL is the repository's browser page.
+	// These are empty if the provider does not report them.
+	CloneURL string `json:"clone_url,omitempty"`
+	SSHURL   string `json:"ssh_url,omitempty"`
+	WebURL   string `json:"web_url,omitempty"`
 }
 
 // PullRequest represents a pull request in a repository.
@@ -20,6 +26,24 @@ type PullRequest struct {
 	Repo          string   `json:"repo,omitempty"`
 	Reviewers     []string `json:"reviewers,omitempty"`
 	TeamReviewers []string `json:"team_reviewers,omitempty"`
+	Assignees     []string `json:"assignees,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+
+	// URL is the pull request's browser page, or empty if the provider does not report it
+	URL string `json:"url,omitempty"`
+
+	// SkippedReviewers lists the requested reviewers which the provider rejected as invalid (e.g. deactivated accounts)
+	SkippedReviewers []string `json:"skipped_reviewers,omitempty"`
+
+	// MergeCommit is the SHA of the commit which merged the pull request, or empty if it was not reported
+	MergeCommit string `json:"merge_commit,omitempty"`
+
+	// AutoMerge is the merge method the provider will use once the pull request's requirements are met,
+	// or empty if auto-merge is not enabled
+	AutoMerge string `json:"auto_merge,omitempty"`
+
+	// MergeBlocker is the reason that a dry run found the pull request can't be merged, or empty if it can be
+	MergeBlocker string `json:"merge_blocker,omitempty"`
 
 	ID        int  `json:"id"`
 	Number    int  `json:"number"`
@@ -28,16 +52,57 @@ type PullRequest struct {
 	Mergeable bool `json:"mergeable"`
 }
 
+// Pull request states accepted when looking up a pull request by branch.
+const (
+	PRStateOpen   = "open"
+	PRStateClosed = "closed" // closed without regard to whether the pull request was merged
+	PRStateMerged = "merged"
+	PRStateAll    = "all"
+)
+
+// ValidPRState reports whether the given state is one of the supported pull request states.
+func ValidPRState(state string) bool {
+	switch state {
+	case PRStateOpen, PRStateClosed, PRStateMerged, PRStateAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReviewStatus represents the approval state of a pull request.
+type ReviewStatus struct {
+	Approvers         []string `json:"approvers,omitempty"`
+	ChangesRequested  []string `json:"changes_requested,omitempty"`
+	RequiredApprovals int      `json:"required_approvals"`
+}
+
+// Approved reports whether the pull request has the required number of approvals
+// and no reviewer is currently requesting changes.
+func (s *ReviewStatus) Approved() bool {
+	return len(s.Approvers) >= s.RequiredApprovals && len(s.ChangesRequested) == 0
+}
+
 // PROptions holds options for creating or updating pull requests.
 type PROptions struct {
 	Title          string
 	Description    string
 	Reviewers      []string
 	TeamReviewers  []string
+	Assignees      []string
+	Labels         []string
 	ResetReviewers bool
+	ResetLabels    bool // replace the labels of an existing pull request instead of adding to them
 	BaseBranch     string
 	Draft          *bool
 
+	// AutoMerge enables auto-merge after opening a pull request, merging with AutoMergeMethod (or the provider's default)
+	AutoMerge       bool
+	AutoMergeMethod string
+
+	// CreateBaseBranch creates a missing base branch from the default branch before opening a pull request
+	CreateBaseBranch bool
+
 	Merge PRMergeOptions
 }
 
@@ -45,4 +110,6 @@ type PROptions struct {
 type PRMergeOptions struct {
 	Method         string
 	CheckMergeable bool
+	UpdateBranch   bool // bring a branch which is behind up to date with its base before merging
+	DryRun         bool // report whether the pull request can be merged (setting MergeBlocker) without merging it
 }
@@ -50,3 +50,28 @@ func TestRepositoryStruct(t *testing.T) {
 		}
 	}
 }
+
+func TestReviewStatusApproved(t *testing.T) {
+	tests := []struct {
+		name   string
+		status ReviewStatus
+		want   bool
+	}{
+		{name: "no reviews", status: ReviewStatus{RequiredApprovals: 1}, want: false},
+		{name: "enough approvals", status: ReviewStatus{Approvers: []string{"alice"}, RequiredApprovals: 1}, want: true},
+		{name: "too few approvals", status: ReviewStatus{Approvers: []string{"alice"}, RequiredApprovals: 2}, want: false},
+		{
+			name:   "changes requested",
+			status: ReviewStatus{Approvers: []string{"alice", "bob"}, ChangesRequested: []string{"carol"}, RequiredApprovals: 1},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Approved(); got != tt.want {
+				t.Errorf("Approved() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
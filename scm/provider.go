@@ -2,6 +2,7 @@ package scm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -20,12 +21,41 @@ type Provider interface {
 
 	// GetPullRequest retrieves a pull request by repository name and source branch.
 	GetPullRequest(repo, branch string) (*PullRequest, error)
-	// OpenPullRequest opens a new pull request in the specified repository.
+	// GetPullRequestByNumber retrieves a pull request by repository name and number, regardless of its state.
+	GetPullRequestByNumber(repo string, number int) (*PullRequest, error)
+	// ListOpenPullRequests lists all open pull requests in the specified repository.
+	ListOpenPullRequests(repo string) ([]*PullRequest, error)
+	// OpenPullRequest opens a new pull request in the specified repository. If the pull request is opened but a later
+	// step fails (e.g. enabling auto-merge), it is returned along with the error.
 	OpenPullRequest(repo, branch string, opts *PROptions) (*PullRequest, error)
 	// UpdatePullRequest updates an existing pull request.
 	UpdatePullRequest(repo, branch string, opts *PROptions) (*PullRequest, error)
 	// MergePullRequest merges an existing pull request.
 	MergePullRequest(repo, branch string, opts *PRMergeOptions) (*PullRequest, error)
+	// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request by repository name and source branch.
+	GetPullRequestHeadSHA(repo, branch string) (string, error)
+	// GetReviewStatus retrieves the approval state of a pull request by repository name and source branch.
+	GetReviewStatus(repo, branch string) (*ReviewStatus, error)
+
+	// CurrentUser returns the login of the authenticated user.
+	CurrentUser() (string, error)
+	// MissingPermissions returns the permissions which the token appears to lack to read the specified repository,
+	// or to modify its pull requests if write is true. It returns none if the provider can't inspect its token.
+	MissingPermissions(repo string, write bool) ([]string, error)
+}
+
+// ErrPullRequestExists indicates that a pull request is already open for the source branch.
+var ErrPullRequestExists = errors.New("a pull request already exists")
+
+// ExistingPullRequestError reports that the given pull request is already open for the branch, identifying it by
+// its number and URL (if known) so that it can be found or updated instead.
+func ExistingPullRequestError(repo, branch string, pr *PullRequest) error {
+	existing := fmt.Sprintf("PR #%d", pr.Number)
+	if pr.URL != "" {
+		existing += " " + pr.URL
+	}
+
+	return fmt.Errorf("%w for branch %s in repository %s (%s)", ErrPullRequestExists, branch, repo, existing)
 }
 
 // Get retrieves a registered SCM provider by name.
@@ -0,0 +1,14 @@
+// Copyright 2018-2026 Ryan Clarke (ryclarke-github@rkc.aleeas.com)
+//
+// Licensed under the Apache License, Version 2.0
+
+/*
+Package rest implements the scm.Provider contract for otherwise unsupported
+forges, driven entirely by configuration.
+
+Each operation is mapped to an HTTP endpoint whose path and JSON body are
+rendered from Go templates, and the repository and pull-request fields are
+read from the JSON responses using configured dot-separated paths. This allows
+interoperability with a new forge without writing a dedicated provider.
+*/
+package rest
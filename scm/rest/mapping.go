@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// lookup returns the value at the dot-separated path within the decoded JSON data. Numeric segments
+// index into arrays, and other segments are applied to every element of an array, so "reviewers.login"
+// collects the login of each reviewer. An empty path returns the data itself, and a missing value is nil.
+func lookup(data any, path string) any {
+	if path == "" {
+		return data
+	}
+
+	segment, rest, _ := strings.Cut(path, ".")
+
+	switch value := data.(type) {
+	case map[string]any:
+		return lookup(value[segment], rest)
+	case []any:
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= len(value) {
+				return nil
+			}
+
+			return lookup(value[index], rest)
+		}
+
+		output := make([]any, 0, len(value))
+		for _, item := range value {
+			if found := lookup(item, path); found != nil {
+				output = append(output, found)
+			}
+		}
+
+		return output
+	default:
+		return nil
+	}
+}
+
+// asString formats a JSON scalar as a string, or returns an empty string for anything else.
+func asString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// asInt converts a JSON number (or numeric string) to an int, or returns zero for anything else.
+func asInt(value any) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
+
+// asBool converts a JSON boolean (or boolean string) to a bool, or returns false for anything else.
+func asBool(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+// asStrings converts a JSON array of scalars to strings, or returns nil for anything else.
+func asStrings(value any) []string {
+	list, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	output := make([]string, 0, len(list))
+	for _, item := range list {
+		if s := asString(item); s != "" {
+			output = append(output, s)
+		}
+	}
+
+	return output
+}
+
+// asList returns the items of a JSON array result, treating a single object as a list of one.
+func asList(value any) ([]any, error) {
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case map[string]any:
+		return []any{v}, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("expected a JSON array or object, got %T", value)
+	}
+}
+
+// parseRepository maps a JSON repository object to a Repository using the configured field paths.
+func parseRepository(data any, fields config.RESTRepositoryFields) *scm.Repository {
+	return &scm.Repository{
+		Name:          asString(lookupField(data, fields.Name)),
+		Description:   asString(lookupField(data, fields.Description)),
+		Public:        asBool(lookupField(data, fields.Public)),
+		Archived:      asBool(lookupField(data, fields.Archived)),
+		DefaultBranch: asString(lookupField(data, fields.DefaultBranch)),
+		Labels:        asStrings(lookupField(data, fields.Labels)),
+		CloneURL:      asString(lookupField(data, fields.CloneURL)),
+		SSHURL:        asString(lookupField(data, fields.SSHURL)),
+		WebURL:        asString(lookupField(data, fields.WebURL)),
+	}
+}
+
+// parsePR maps a JSON pull request object to a PullRequest using the configured field paths.
+func parsePR(data any, fields config.RESTPullRequestFields) *scm.PullRequest {
+	pr := &scm.PullRequest{
+		ID:          asInt(lookupField(data, fields.ID)),
+		Number:      asInt(lookupField(data, fields.Number)),
+		Title:       asString(lookupField(data, fields.Title)),
+		Description: asString(lookupField(data, fields.Description)),
+		Branch:      asString(lookupField(data, fields.Branch)),
+		BaseBranch:  asString(lookupField(data, fields.BaseBranch)),
+		Reviewers:   asStrings(lookupField(data, fields.Reviewers)),
+		Mergeable:   asBool(lookupField(data, fields.Mergeable)),
+	}
+
+	// forges often identify pull requests by a single value
+	if pr.ID == 0 {
+		pr.ID = pr.Number
+	} else if pr.Number == 0 {
+		pr.Number = pr.ID
+	}
+
+	return pr
+}
+
+// lookupField is like lookup, but an unmapped (empty) field path has no value.
+func lookupField(data any, path string) any {
+	if path == "" {
+		return nil
+	}
+
+	return lookup(data, path)
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"encoding/json"
+	"testing"
+
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestLookup(t *testing.T) {
+	var data any
+	if err := json.Unmarshal([]byte(`{
+		"id": 42,
+		"head": {"ref": "feature"},
+		"reviewers": [{"login": "alice"}, {"login": "bob"}, {"name": "team"}],
+		"labels": ["api", "go"]
+	}`), &data); err != nil {
+		t.Fatalf("Failed to parse test data: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "number", path: "id", want: "42"},
+		{name: "nested", path: "head.ref", want: "feature"},
+		{name: "array index", path: "labels.1", want: "go"},
+		{name: "array index out of range", path: "labels.5", want: ""},
+		{name: "missing", path: "head.sha", want: ""},
+		{name: "through scalar", path: "id.value", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertEqual(t, asString(lookup(data, tt.path)), tt.want)
+		})
+	}
+
+	t.Run("array elements", func(t *testing.T) {
+		logins := asStrings(lookup(data, "reviewers.login"))
+		testhelper.AssertLength(t, logins, 2)
+		testhelper.AssertEqual(t, logins[1], "bob")
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		testhelper.AssertEqual(t, asString(lookup(lookup(data, ""), "head.ref")), "feature")
+	})
+}
+
+func TestConversions(t *testing.T) {
+	testhelper.AssertEqual(t, asInt(float64(7)), 7)
+	testhelper.AssertEqual(t, asInt("12"), 12)
+	testhelper.AssertEqual(t, asInt(nil), 0)
+
+	testhelper.AssertEqual(t, asBool(true), true)
+	testhelper.AssertEqual(t, asBool("true"), true)
+	testhelper.AssertEqual(t, asBool(float64(1)), false)
+
+	testhelper.AssertEqual(t, asString(1.5), "1.5")
+	testhelper.AssertEqual(t, asString(false), "false")
+
+	items, err := asList(map[string]any{"id": 1})
+	testhelper.AssertError(t, err, false)
+	testhelper.AssertLength(t, items, 1)
+
+	_, err = asList("not a list")
+	testhelper.AssertError(t, err, true)
+}
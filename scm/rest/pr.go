@@ -0,0 +1,257 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// GetPullRequest retrieves a pull request by repository name and source branch.
+func (r *REST) GetPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	return r.getPullRequest(repo, branch)
+}
+
+// ListOpenPullRequests lists all open pull requests in the specified repository.
+func (r *REST) ListOpenPullRequests(repo string) ([]*scm.PullRequest, error) {
+	prs, err := r.listPullRequests(ListPullRequests, &templateData{Project: r.project, Repo: repo})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s: %w", repo, err)
+	}
+
+	return prs, nil
+}
+
+// OpenPullRequest opens a new pull request in the specified repository.
+func (r *REST) OpenPullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	// reads are less restrictive than a failed write, so check for existing PR first
+	if existing, err := r.getPullRequest(repo, branch); err == nil {
+		return nil, scm.ExistingPullRequestError(repo, branch, existing)
+	}
+
+	// if title is not specified, use the branch name
+	if opts.Title == "" {
+		opts.Title = branch
+	}
+
+	// use provided base branch or fall back to configured default
+	baseBranch := opts.BaseBranch
+	if baseBranch == "" {
+		baseBranch = config.Viper(r.ctx).GetString(config.DefaultBranch)
+	}
+
+	data := &templateData{
+		Project:     r.project,
+		Repo:        repo,
+		Branch:      branch,
+		BaseBranch:  baseBranch,
+		Title:       opts.Title,
+		Description: opts.Description,
+		Reviewers:   opts.Reviewers,
+	}
+
+	result, err := r.call(OpenPullRequest, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	pr := parsePR(result, r.cfg.PullRequest)
+
+	// fill in any fields which aren't mapped or aren't returned by the forge
+	if pr.Title == "" {
+		pr.Title = opts.Title
+	}
+
+	if pr.Description == "" {
+		pr.Description = opts.Description
+	}
+
+	if pr.Branch == "" {
+		pr.Branch = branch
+	}
+
+	if pr.BaseBranch == "" {
+		pr.BaseBranch = baseBranch
+	}
+
+	if len(pr.Reviewers) == 0 {
+		pr.Reviewers = opts.Reviewers
+	}
+
+	return pr, nil
+}
+
+// UpdatePullRequest updates an existing pull request.
+func (r *REST) UpdatePullRequest(repo, branch string, opts *scm.PROptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PROptions{} // default options
+	}
+
+	if opts.Title == "" && opts.Description == "" && len(opts.Reviewers) == 0 {
+		return nil, fmt.Errorf("no updates provided")
+	}
+
+	pr, err := r.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	data := prData(r.project, repo, pr)
+
+	// unchanged fields keep their current values, and new reviewers are added to the existing ones
+	if opts.Title != "" {
+		data.Title = opts.Title
+	}
+
+	if opts.Description != "" {
+		data.Description = opts.Description
+	}
+
+	for _, reviewer := range opts.Reviewers {
+		if !slices.Contains(data.Reviewers, reviewer) {
+			data.Reviewers = append(data.Reviewers, reviewer)
+		}
+	}
+
+	result, err := r.call(UpdatePullRequest, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	updated := parsePR(result, r.cfg.PullRequest)
+	if updated.Number == 0 {
+		// the forge didn't return the updated pull request, so report the requested state
+		updated = pr
+		updated.Title, updated.Description, updated.Reviewers = data.Title, data.Description, data.Reviewers
+	}
+
+	return updated, nil
+}
+
+// MergePullRequest merges an existing pull request.
+func (r *REST) MergePullRequest(repo, branch string, opts *scm.PRMergeOptions) (*scm.PullRequest, error) {
+	if opts == nil {
+		opts = &scm.PRMergeOptions{} // default options
+	}
+
+	pr, err := r.getPullRequest(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		if !pr.Mergeable {
+			pr.MergeBlocker = "not mergeable"
+		}
+
+		return pr, nil
+	}
+
+	if opts.CheckMergeable && !pr.Mergeable {
+		return nil, fmt.Errorf("pull request %s [%d] for %s is not mergeable", branch, pr.Number, repo)
+	}
+
+	data := prData(r.project, repo, pr)
+
+	// if no merge method specified, use the default from config (if set)
+	data.Method = opts.Method
+	if data.Method == "" {
+		data.Method = config.Viper(r.ctx).GetString(config.DefaultMergeMethod)
+	}
+
+	if data.Method != "" && !slices.Contains(r.cfg.MergeMethods, data.Method) {
+		return nil, fmt.Errorf("merge method %q is not supported by the REST provider configuration", data.Method)
+	}
+
+	if _, err := r.call(MergePullRequest, data); err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// GetPullRequestByNumber retrieves a pull request by repository name and number.
+func (r *REST) GetPullRequestByNumber(_ string, _ int) (*scm.PullRequest, error) {
+	return nil, fmt.Errorf("retrieving a pull request by number is not currently supported by the REST provider")
+}
+
+// GetPullRequestHeadSHA retrieves the SHA of the head commit of a pull request.
+func (r *REST) GetPullRequestHeadSHA(_, _ string) (string, error) {
+	return "", fmt.Errorf("retrieving the head of a pull request is not currently supported by the REST provider")
+}
+
+// GetReviewStatus retrieves the approval state of a pull request.
+func (r *REST) GetReviewStatus(_, _ string) (*scm.ReviewStatus, error) {
+	return nil, fmt.Errorf("retrieving review status is not currently supported by the REST provider")
+}
+
+// getPullRequest retrieves the open pull request for the branch using the get endpoint if it is configured,
+// otherwise by searching the open pull requests for the branch.
+func (r *REST) getPullRequest(repo, branch string) (*scm.PullRequest, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	operation := GetPullRequest
+	if _, ok := r.cfg.Endpoints[GetPullRequest]; !ok {
+		operation = ListPullRequests
+	}
+
+	prs, err := r.listPullRequests(operation, &templateData{Project: r.project, Repo: repo, Branch: branch})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	for _, pr := range prs {
+		// the branch can't be checked if it isn't mapped, so rely on the endpoint's filtering
+		if pr.Branch == branch || r.cfg.PullRequest.Branch == "" {
+			if pr.Branch == "" {
+				pr.Branch = branch
+			}
+
+			return pr, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no open pull request found for branch %s in repository %s", branch, repo)
+}
+
+// listPullRequests calls the endpoint for the given operation and parses each pull request in its result.
+func (r *REST) listPullRequests(operation string, data *templateData) ([]*scm.PullRequest, error) {
+	result, err := r.call(operation, data)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := asList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]*scm.PullRequest, len(items))
+	for i, item := range items {
+		output[i] = parsePR(item, r.cfg.PullRequest)
+	}
+
+	return output, nil
+}
+
+// prData returns the template data describing an existing pull request.
+func prData(project, repo string, pr *scm.PullRequest) *templateData {
+	return &templateData{
+		Project:     project,
+		Repo:        repo,
+		Branch:      pr.Branch,
+		BaseBranch:  pr.BaseBranch,
+		Title:       pr.Title,
+		Description: pr.Description,
+		Reviewers:   slices.Clone(pr.Reviewers),
+		ID:          pr.ID,
+		Number:      pr.Number,
+	}
+}
@@ -0,0 +1,269 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+const pullsPath = "/api/repos/test-project/test-repo/pulls"
+
+// mockForgePR creates a pull request in the format of the sample forge mapping
+func mockForgePR(iid int, branch, title string, reviewers ...string) map[string]any {
+	users := make([]any, len(reviewers))
+	for i, reviewer := range reviewers {
+		users[i] = map[string]any{"username": reviewer}
+	}
+
+	return map[string]any{
+		"iid":       iid,
+		"title":     title,
+		"body":      "PR description",
+		"source":    map[string]any{"branch": branch},
+		"target":    map[string]any{"branch": "main"},
+		"reviewers": users,
+		"checks":    map[string]any{"mergeable": true},
+	}
+}
+
+// handleGetPR serves the get endpoint of the sample mapping, filtering the given pull requests by source branch.
+func handleGetPR(t *testing.T, mux *http.ServeMux, prs ...map[string]any) {
+	mux.HandleFunc("GET "+pullsPath, func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Query().Get("state"), "open")
+
+		matches := make([]any, 0)
+		for _, pr := range prs {
+			source := r.URL.Query().Get("source")
+			if source == "" || pr["source"].(map[string]any)["branch"] == source {
+				matches = append(matches, pr)
+			}
+		}
+
+		writeJSON(t, w, map[string]any{"data": matches})
+	})
+}
+
+func TestGetPullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	handleGetPR(t, mux, mockForgePR(1, "other-branch", "Other PR"), mockForgePR(2, "feature/branch", "Test PR", "alice", "bob"))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pr, err := newTestREST(t, server).GetPullRequest("test-repo", "feature/branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.ID, 2)
+	testhelper.AssertEqual(t, pr.Number, 2)
+	testhelper.AssertEqual(t, pr.Title, "Test PR")
+	testhelper.AssertEqual(t, pr.Description, "PR description")
+	testhelper.AssertEqual(t, pr.Branch, "feature/branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, pr.Mergeable, true)
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestGetPullRequestFromList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Query().Get("source"), "")
+
+		writeJSON(t, w, map[string]any{"data": []any{mockForgePR(1, "other-branch", "Other PR"), mockForgePR(2, "feature-branch", "Test PR")}})
+	}))
+	defer server.Close()
+
+	// without a get endpoint, the open pull requests are searched for the branch
+	r := newTestREST(t, server)
+	delete(r.cfg.Endpoints, GetPullRequest)
+
+	pr, err := r.GetPullRequest("test-repo", "feature-branch")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Number, 2)
+
+	_, err = r.GetPullRequest("test-repo", "missing-branch")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "no open pull request found for branch missing-branch")
+}
+
+func TestListOpenPullRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	handleGetPR(t, mux, mockForgePR(1, "branch-1", "First PR"), mockForgePR(2, "branch-2", "Second PR"))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	prs, err := newTestREST(t, server).ListOpenPullRequests("test-repo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, prs, 2)
+	testhelper.AssertEqual(t, prs[1].Branch, "branch-2")
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	var created bool
+
+	mux := http.NewServeMux()
+	handleGetPR(t, mux)
+	mux.HandleFunc("POST "+pullsPath, func(w http.ResponseWriter, r *http.Request) {
+		created = true
+
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["source"], "feature-branch")
+		testhelper.AssertEqual(t, body["target"], "main")
+		testhelper.AssertEqual(t, body["title"], `Fix "quoted" title`)
+		testhelper.AssertEqual(t, body["body"], "Line one\nLine two")
+		testhelper.AssertLength(t, body["reviewers"], 2)
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, mockForgePR(9, "feature-branch", `Fix "quoted" title`, "alice", "bob"))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pr, err := newTestREST(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:       `Fix "quoted" title`,
+		Description: "Line one\nLine two",
+		Reviewers:   []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, created, true)
+	testhelper.AssertEqual(t, pr.Number, 9)
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestOpenPullRequest_EmptyResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	handleGetPR(t, mux)
+	mux.HandleFunc("POST "+pullsPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pr, err := newTestREST(t, server).OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Reviewers: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the requested values are reported when the forge doesn't return the new pull request
+	testhelper.AssertEqual(t, pr.Title, "feature-branch")
+	testhelper.AssertEqual(t, pr.Branch, "feature-branch")
+	testhelper.AssertEqual(t, pr.BaseBranch, "main")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice")
+}
+
+func TestOpenPullRequest_AlreadyExists(t *testing.T) {
+	mux := http.NewServeMux()
+	handleGetPR(t, mux, mockForgePR(1, "feature-branch", "Existing PR"))
+	mux.HandleFunc("POST "+pullsPath, func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("Expected no pull request to be created")
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := newTestREST(t, server).OpenPullRequest("test-repo", "feature-branch", nil)
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "already exists")
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	handleGetPR(t, mux, mockForgePR(3, "feature-branch", "Old Title", "alice"))
+	mux.HandleFunc("PATCH "+pullsPath+"/3", func(w http.ResponseWriter, r *http.Request) {
+		body := decodeBody(t, r)
+		testhelper.AssertEqual(t, body["title"], "New Title")
+		testhelper.AssertEqual(t, body["body"], "PR description") // unchanged
+		testhelper.AssertLength(t, body["reviewers"], 2)
+
+		writeJSON(t, w, mockForgePR(3, "feature-branch", "New Title", "alice", "bob"))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	pr, err := newTestREST(t, server).UpdatePullRequest("test-repo", "feature-branch", &scm.PROptions{
+		Title:     "New Title",
+		Reviewers: []string{"alice", "bob"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, pr.Title, "New Title")
+	testhelper.AssertEqual(t, strings.Join(pr.Reviewers, ","), "alice,bob")
+}
+
+func TestMergePullRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		defaultMethod string
+		wantStrategy  any
+		wantErr       bool
+	}{
+		{name: "no method", wantStrategy: ""},
+		{name: "configured default", defaultMethod: "squash", wantStrategy: "squash"},
+		{name: "merge", method: "merge", defaultMethod: "squash", wantStrategy: "merge"},
+		{name: "unsupported", method: "rebase", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var strategy any
+
+			mux := http.NewServeMux()
+			handleGetPR(t, mux, mockForgePR(5, "feature-branch", "PR to Merge"))
+			mux.HandleFunc("PUT "+pullsPath+"/5/merge", func(w http.ResponseWriter, r *http.Request) {
+				strategy = decodeBody(t, r)["strategy"]
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			r := newTestREST(t, server)
+			config.Viper(r.ctx).Set(config.DefaultMergeMethod, tt.defaultMethod)
+
+			pr, err := r.MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{Method: tt.method, CheckMergeable: true})
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if !tt.wantErr {
+				testhelper.AssertEqual(t, pr.Number, 5)
+				testhelper.AssertEqual(t, strategy, tt.wantStrategy)
+			}
+		})
+	}
+}
+
+func TestMergePullRequest_NotMergeable(t *testing.T) {
+	pr := mockForgePR(5, "feature-branch", "Conflicting PR")
+	pr["checks"] = map[string]any{"mergeable": false}
+
+	mux := http.NewServeMux()
+	handleGetPR(t, mux, pr)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err := newTestREST(t, server).MergePullRequest("test-repo", "feature-branch", &scm.PRMergeOptions{CheckMergeable: true})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "is not mergeable")
+}
@@ -0,0 +1,237 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// Operations which can be mapped to an endpoint in the configuration.
+const (
+	ListRepositories  = "list-repositories"
+	ListPullRequests  = "list-pull-requests"
+	GetPullRequest    = "get-pull-request"
+	OpenPullRequest   = "open-pull-request"
+	UpdatePullRequest = "update-pull-request"
+	MergePullRequest  = "merge-pull-request"
+	CurrentUser       = "current-user"
+)
+
+// templateFuncs are available to endpoint path and body templates.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. for quoting strings within a request body
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"path":  url.PathEscape,
+	"query": url.QueryEscape,
+}
+
+var _ scm.Provider = new(REST)
+
+func init() {
+	// Register the generic REST provider factory
+	scm.Register("rest", New)
+}
+
+// New creates a new generic REST provider instance. An invalid configuration is reported by each operation.
+func New(ctx context.Context, project string) scm.Provider {
+	cfg, err := config.LoadRESTProvider(ctx)
+
+	return &REST{
+		client:  http.DefaultClient,
+		cfg:     cfg,
+		err:     err,
+		project: project,
+		ctx:     ctx,
+	}
+}
+
+// REST represents an SCM provider for a forge described by endpoint templates and JSON field mappings.
+type REST struct {
+	client  *http.Client
+	cfg     *config.RESTProvider
+	err     error
+	project string
+	ctx     context.Context
+}
+
+// templateData holds the values available to endpoint templates.
+type templateData struct {
+	Project     string
+	Repo        string
+	Branch      string
+	BaseBranch  string
+	Title       string
+	Description string
+	Reviewers   []string
+	Method      string
+
+	// ID and Number identify an existing pull request.
+	ID     int
+	Number int
+}
+
+// CheckCapabilities validates that the provided PR options are supported by the configured endpoints.
+func (r *REST) CheckCapabilities(opts *scm.PROptions) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return scm.ValidatePROptions(&scm.Capabilities{
+		MergeMethods:   r.cfg.MergeMethods,
+		CheckMergeable: r.cfg.PullRequest.Mergeable != "",
+	}, opts)
+}
+
+// CurrentUser returns the login of the authenticated user.
+func (r *REST) CurrentUser() (string, error) {
+	result, err := r.call(CurrentUser, &templateData{Project: r.project})
+	if err != nil {
+		return "", fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return asString(result), nil
+}
+
+// MissingPermissions reports no missing permissions, since the permissions of REST tokens aren't inspected.
+func (r *REST) MissingPermissions(_ string, _ bool) ([]string, error) {
+	return nil, nil
+}
+
+// call renders the endpoint for the given operation, performs the request and returns its result.
+func (r *REST) call(operation string, data *templateData) (any, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	endpoint, ok := r.cfg.Endpoints[operation]
+	if !ok || endpoint.Path == "" {
+		return nil, fmt.Errorf("the %s endpoint is not configured for the REST provider", operation)
+	}
+
+	req, err := r.newRequest(operation, endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup(resp, endpoint.Result), nil
+}
+
+// newRequest renders the path and body templates of the endpoint into an HTTP request.
+func (r *REST) newRequest(operation string, endpoint config.RESTEndpoint, data *templateData) (*http.Request, error) {
+	path, err := render(operation+" path", endpoint.Path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+
+	method := endpoint.Method
+	if endpoint.Body != "" {
+		rendered, err := render(operation+" body", endpoint.Body, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if !json.Valid([]byte(rendered)) {
+			return nil, fmt.Errorf("the %s body template did not render valid JSON: %s", operation, rendered)
+		}
+
+		body = strings.NewReader(rendered)
+
+		if method == "" {
+			method = http.MethodPost
+		}
+	}
+
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(r.ctx, strings.ToUpper(method), strings.TrimSuffix(r.cfg.BaseURL, "/")+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return req, nil
+}
+
+// render executes the named template with the given data.
+func render(name, text string, data *templateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// do authenticates and performs the HTTP request, decoding the JSON response (if any).
+func (r *REST) do(req *http.Request) (any, error) {
+	token, err := scm.AuthToken(r.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set(r.cfg.AuthHeader, strings.TrimSpace(r.cfg.AuthScheme+" "+token))
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := parseError(resp); err != nil {
+		return nil, err
+	}
+
+	var result any
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return result, nil
+}
+
+func parseError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	output, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error %d: failed to read response body: %w", resp.StatusCode, err)
+	}
+
+	return fmt.Errorf("error %d: %s", resp.StatusCode, strings.TrimSpace(string(output)))
+}
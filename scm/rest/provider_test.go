@@ -0,0 +1,192 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func loadFixture(t *testing.T) context.Context {
+	return testhelper.LoadFixture(t, "../../config")
+}
+
+// newTestREST creates a REST provider for the "test-project" project, configured with the sample
+// mapping in testdata/forge.yaml and sending its requests to the test server.
+func newTestREST(t *testing.T, server *httptest.Server) *REST {
+	t.Helper()
+	ctx := loadFixture(t)
+
+	mapping, err := os.ReadFile("testdata/forge.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read sample mapping: %v", err)
+	}
+
+	viper := config.Viper(ctx)
+	viper.SetConfigType("yaml")
+	if err := viper.MergeConfig(strings.NewReader(strings.ReplaceAll(string(mapping), "{{SERVER}}", server.URL))); err != nil {
+		t.Fatalf("Failed to load sample mapping: %v", err)
+	}
+
+	viper.Set(config.AuthToken, "test-token")
+
+	provider, ok := New(ctx, "test-project").(*REST)
+	if !ok {
+		t.Fatalf("Expected *REST provider, got %T", provider)
+	}
+
+	if provider.err != nil {
+		t.Fatalf("Unexpected configuration error: %v", provider.err)
+	}
+
+	return provider
+}
+
+// writeJSON encodes the value as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// decodeBody decodes the JSON request body into a map.
+func decodeBody(t *testing.T, r *http.Request) map[string]any {
+	t.Helper()
+
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode request body: %v", err)
+	}
+
+	return body
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	provider := scm.Get(loadFixture(t), "rest", "test-project")
+
+	if _, ok := provider.(*REST); !ok {
+		t.Fatalf("Expected *REST provider, got %T", provider)
+	}
+
+	// every operation reports the missing base URL
+	_, err := provider.ListRepositories()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "base-url is required")
+
+	_, err = provider.GetPullRequest("test-repo", "feature-branch")
+	testhelper.AssertError(t, err, true)
+
+	testhelper.AssertError(t, provider.CheckCapabilities(&scm.PROptions{}), true)
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	r := newTestREST(t, server)
+
+	testhelper.AssertError(t, r.CheckCapabilities(&scm.PROptions{Reviewers: []string{"alice"}}), false)
+	testhelper.AssertError(t, r.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "squash", CheckMergeable: true}}), false)
+	testhelper.AssertError(t, r.CheckCapabilities(&scm.PROptions{Merge: scm.PRMergeOptions{Method: "rebase"}}), true)
+	testhelper.AssertError(t, r.CheckCapabilities(&scm.PROptions{TeamReviewers: []string{"team"}}), true)
+}
+
+func TestCurrentUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/me")
+		testhelper.AssertEqual(t, r.Header.Get("X-Forge-Token"), "test-token")
+		testhelper.AssertEqual(t, r.Header.Get("Authorization"), "")
+
+		writeJSON(t, w, map[string]any{"user": map[string]any{"username": "alice"}})
+	}))
+	defer server.Close()
+
+	login, err := newTestREST(t, server).CurrentUser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertEqual(t, login, "alice")
+}
+
+func TestUnconfiguredEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	r := newTestREST(t, server)
+	delete(r.cfg.Endpoints, CurrentUser)
+
+	_, err := r.CurrentUser()
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "the current-user endpoint is not configured")
+}
+
+func TestInvalidBodyTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+
+		writeJSON(t, w, map[string]any{"data": []any{}})
+	}))
+	defer server.Close()
+
+	r := newTestREST(t, server)
+
+	// an unquoted string value renders invalid JSON
+	endpoint := r.cfg.Endpoints[OpenPullRequest]
+	endpoint.Body = `{"title": {{.Title}}}`
+	r.cfg.Endpoints[OpenPullRequest] = endpoint
+
+	_, err := r.OpenPullRequest("test-repo", "feature-branch", &scm.PROptions{Title: "New PR"})
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "did not render valid JSON")
+}
+
+func TestListRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testhelper.AssertEqual(t, r.URL.Path, "/api/projects/test-project/repos")
+
+		writeJSON(t, w, map[string]any{"data": []any{
+			map[string]any{
+				"slug":       "api",
+				"summary":    "The API",
+				"visibility": map[string]any{"public": true},
+				"branches":   map[string]any{"default": "develop"},
+				"tags":       []any{map[string]any{"name": "backend"}, map[string]any{"name": "go"}},
+				"links":      map[string]any{"clone": "https://forge.example.com/test-project/api.git"},
+			},
+			map[string]any{"slug": "web"},
+			map[string]any{"summary": "missing a name"},
+		}})
+	}))
+	defer server.Close()
+
+	repos, err := newTestREST(t, server).ListRepositories()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	testhelper.AssertLength(t, repos, 2)
+
+	testhelper.AssertEqual(t, repos[0].Name, "api")
+	testhelper.AssertEqual(t, repos[0].Description, "The API")
+	testhelper.AssertEqual(t, repos[0].Project, "test-project")
+	testhelper.AssertEqual(t, repos[0].Public, true)
+	testhelper.AssertEqual(t, repos[0].DefaultBranch, "develop")
+	testhelper.AssertEqual(t, strings.Join(repos[0].Labels, ","), "backend,go")
+	testhelper.AssertEqual(t, repos[0].CloneURL, "https://forge.example.com/test-project/api.git")
+
+	testhelper.AssertEqual(t, repos[1].Public, false)
+	testhelper.AssertEqual(t, repos[1].DefaultBranch, "main") // configured fallback
+}
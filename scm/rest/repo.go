@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
+)
+
+// ListRepositories lists all repositories in the specified project.
+func (r *REST) ListRepositories() ([]*scm.Repository, error) {
+	result, err := r.call(ListRepositories, &templateData{Project: r.project})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	items, err := asList(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	output := make([]*scm.Repository, 0, len(items))
+	for _, item := range items {
+		repo := parseRepository(item, r.cfg.Repository)
+		if repo.Name == "" {
+			continue // not a usable repository
+		}
+
+		if repo.DefaultBranch == "" {
+			// fall back on configured default branch if it isn't mapped or set for the repo
+			repo.DefaultBranch = config.Viper(r.ctx).GetString(config.DefaultBranch)
+		}
+
+		repo.Project = r.project
+		output = append(output, repo)
+	}
+
+	return output, nil
+}
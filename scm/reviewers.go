@@ -0,0 +1,25 @@
+package scm
+
+import (
+	"slices"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+// DiffReviewers returns the sorted reviewers which must be added to and removed from the current reviewers
+// to apply the desired reviewers. Reviewers are only removed when resetting, otherwise the desired reviewers
+// are appended to the current ones.
+func DiffReviewers(current, desired []string, reset bool) (toAdd, toRemove []string) {
+	currentSet := mapset.NewSet(current...)
+	desiredSet := mapset.NewSet(desired...)
+
+	toAdd = desiredSet.Difference(currentSet).ToSlice()
+	slices.Sort(toAdd)
+
+	if reset {
+		toRemove = currentSet.Difference(desiredSet).ToSlice()
+		slices.Sort(toRemove)
+	}
+
+	return toAdd, toRemove
+}
@@ -0,0 +1,36 @@
+package scm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffReviewers(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []string
+		desired    []string
+		reset      bool
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{name: "append partial overlap", current: []string{"alice", "bob"}, desired: []string{"carol", "bob"}, wantAdd: []string{"carol"}},
+		{name: "reset partial overlap", current: []string{"alice", "bob"}, desired: []string{"carol", "bob"}, reset: true, wantAdd: []string{"carol"}, wantRemove: []string{"alice"}},
+		{name: "reset to same reviewers", current: []string{"alice"}, desired: []string{"alice"}, reset: true, wantAdd: []string{}, wantRemove: []string{}},
+		{name: "no current reviewers", desired: []string{"bob", "alice"}, wantAdd: []string{"alice", "bob"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toAdd, toRemove := DiffReviewers(tt.current, tt.desired, tt.reset)
+
+			if !reflect.DeepEqual(toAdd, tt.wantAdd) {
+				t.Errorf("Expected to add %v, got %v", tt.wantAdd, toAdd)
+			}
+
+			if !reflect.DeepEqual(toRemove, tt.wantRemove) {
+				t.Errorf("Expected to remove %v, got %v", tt.wantRemove, toRemove)
+			}
+		})
+	}
+}
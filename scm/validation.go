@@ -2,6 +2,7 @@ package scm
 
 import (
 	"fmt"
+	"strings"
 
 	mapset "github.com/deckarep/golang-set/v2"
 )
@@ -11,9 +12,54 @@ type Capabilities struct {
 	TeamReviewers  bool
 	ResetReviewers bool
 	Draft          bool
+	Assignees      bool
+	Labels         bool
+	CreateBase     bool
+	AutoMerge      bool
 
 	MergeMethods   []string
 	CheckMergeable bool
+	UpdateBranch   bool
+}
+
+// mergeMethodAliases are the alternative names of merge methods which are always accepted.
+var mergeMethodAliases = map[string]string{
+	"merge-commit":     "merge",
+	"squash-merge":     "squash",
+	"squash-and-merge": "squash",
+	"rebase-merge":     "rebase",
+	"rebase-and-merge": "rebase",
+}
+
+// NormalizeMergeMethod returns the merge method named by the given alias (e.g. "squash-merge" for "squash"),
+// matching aliases case-insensitively. The configured aliases are layered over the built-in ones, and methods
+// which aren't aliases are returned unchanged.
+func NormalizeMergeMethod(method string, aliases map[string]string) string {
+	method = strings.TrimSpace(method)
+
+	if canonical, ok := aliases[strings.ToLower(method)]; ok {
+		return canonical
+	}
+
+	if canonical, ok := mergeMethodAliases[strings.ToLower(method)]; ok {
+		return canonical
+	}
+
+	return method
+}
+
+// IsMergeMethod reports whether the name is one of the common merge methods (merge, squash, or rebase), or an alias
+// of one.
+func IsMergeMethod(name string, aliases map[string]string) bool {
+	method := strings.ToLower(NormalizeMergeMethod(name, aliases))
+
+	for _, canonical := range mergeMethodAliases {
+		if method == canonical {
+			return true
+		}
+	}
+
+	return false
 }
 
 // ValidatePROptions validates that the provided PR options are supported by the given capabilities.
@@ -38,13 +84,37 @@ func ValidatePROptions(caps *Capabilities, opts *PROptions) error {
 		return fmt.Errorf("provider does not support draft pull requests")
 	}
 
+	if !caps.Assignees && len(opts.Assignees) > 0 {
+		return fmt.Errorf("provider does not support assignees")
+	}
+
+	if !caps.Labels && (len(opts.Labels) > 0 || opts.ResetLabels) {
+		return fmt.Errorf("provider does not support labels")
+	}
+
+	if !caps.CreateBase && opts.CreateBaseBranch {
+		return fmt.Errorf("provider does not support creating base branches")
+	}
+
+	if !caps.AutoMerge && opts.AutoMerge {
+		return fmt.Errorf("provider does not support auto-merge")
+	}
+
+	if opts.AutoMergeMethod != "" && !mapset.NewSet(caps.MergeMethods...).Contains(opts.AutoMergeMethod) {
+		return fmt.Errorf("provider does not support merge method %q", opts.AutoMergeMethod)
+	}
+
 	if opts.Merge.Method != "" && !mapset.NewSet(caps.MergeMethods...).Contains(opts.Merge.Method) {
 		return fmt.Errorf("provider does not support merge method %q", opts.Merge.Method)
 	}
 
-	if !caps.CheckMergeable && opts.Merge.CheckMergeable {
+	if !caps.CheckMergeable && (opts.Merge.CheckMergeable || opts.Merge.DryRun) {
 		return fmt.Errorf("provider does not support checking PR mergeability")
 	}
 
+	if !caps.UpdateBranch && opts.Merge.UpdateBranch {
+		return fmt.Errorf("provider does not support updating PR branches")
+	}
+
 	return nil
 }
@@ -128,6 +128,98 @@ func TestValidatePROptions(t *testing.T) {
 			wantErr:    true,
 			errMessage: "does not support draft",
 		},
+		{
+			name: "no_support_with_assignees_fails",
+			caps: &scm.Capabilities{},
+			opts: &scm.PROptions{
+				Assignees: []string{"user1"},
+			},
+			wantErr:    true,
+			errMessage: "does not support assignees",
+		},
+		{
+			name: "no_support_with_labels_fails",
+			caps: &scm.Capabilities{},
+			opts: &scm.PROptions{
+				Labels: []string{"needs-triage"},
+			},
+			wantErr:    true,
+			errMessage: "does not support labels",
+		},
+		{
+			name: "no_support_with_reset_labels_fails",
+			caps: &scm.Capabilities{},
+			opts: &scm.PROptions{
+				ResetLabels: true,
+			},
+			wantErr:    true,
+			errMessage: "does not support labels",
+		},
+		{
+			name: "labels_supported",
+			caps: &scm.Capabilities{Labels: true},
+			opts: &scm.PROptions{
+				Labels:      []string{"needs-triage"},
+				ResetLabels: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "no_support_with_auto_merge_fails",
+			caps: &scm.Capabilities{MergeMethods: []string{"merge"}},
+			opts: &scm.PROptions{
+				AutoMerge: true,
+			},
+			wantErr:    true,
+			errMessage: "does not support auto-merge",
+		},
+		{
+			name: "auto_merge_with_unsupported_method_fails",
+			caps: &scm.Capabilities{AutoMerge: true, MergeMethods: []string{"merge", "squash"}},
+			opts: &scm.PROptions{
+				AutoMerge:       true,
+				AutoMergeMethod: "rebase",
+			},
+			wantErr:    true,
+			errMessage: `does not support merge method "rebase"`,
+		},
+		{
+			name: "auto_merge_supported",
+			caps: &scm.Capabilities{AutoMerge: true, MergeMethods: []string{"merge", "squash"}},
+			opts: &scm.PROptions{
+				AutoMerge:       true,
+				AutoMergeMethod: "squash",
+			},
+			wantErr: false,
+		},
+		{
+			name: "no_support_with_merge_dry_run_fails",
+			caps: &scm.Capabilities{},
+			opts: &scm.PROptions{
+				Merge: scm.PRMergeOptions{DryRun: true},
+			},
+			wantErr:    true,
+			errMessage: "does not support checking PR mergeability",
+		},
+		{
+			name: "no_support_with_update_branch_fails",
+			caps: &scm.Capabilities{CheckMergeable: true},
+			opts: &scm.PROptions{
+				Merge: scm.PRMergeOptions{UpdateBranch: true},
+			},
+			wantErr:    true,
+			errMessage: "does not support updating PR branches",
+		},
+		{
+			name: "no_support_with_create_base_fails",
+			caps: &scm.Capabilities{},
+			opts: &scm.PROptions{
+				BaseBranch:       "feature/part-1",
+				CreateBaseBranch: true,
+			},
+			wantErr:    true,
+			errMessage: "does not support creating base branches",
+		},
 		{
 			name: "no_support_with_reviewers_ok",
 			caps: &scm.Capabilities{
@@ -204,3 +296,47 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNormalizeMergeMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		aliases map[string]string
+		want    string
+	}{
+		{name: "method", method: "squash", want: "squash"},
+		{name: "built-in alias", method: "squash-merge", want: "squash"},
+		{name: "built-in alias ignores case", method: "Rebase-Merge", want: "rebase"},
+		{name: "built-in alias ignores whitespace", method: " merge-commit ", want: "merge"},
+		{name: "unknown method", method: "fast-forward", want: "fast-forward"},
+		{name: "empty", method: "", want: ""},
+		{name: "configured alias", method: "ff", aliases: map[string]string{"ff": "rebase"}, want: "rebase"},
+		{name: "built-in alias with configured aliases", method: "squash-and-merge", aliases: map[string]string{"ff": "rebase"}, want: "squash"},
+		{name: "configured alias overrides built-in", method: "merge-commit", aliases: map[string]string{"merge-commit": "squash"}, want: "squash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scm.NormalizeMergeMethod(tt.method, tt.aliases); got != tt.want {
+				t.Errorf("NormalizeMergeMethod(%q) = %q, want %q", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMergeMethod(t *testing.T) {
+	aliases := map[string]string{"ff": "rebase"}
+
+	for name, want := range map[string]bool{
+		"merge":        true,
+		"Squash":       true,
+		"squash-merge": true,
+		"ff":           true,
+		"octopus":      false,
+		"repo-1":       false,
+	} {
+		if got := scm.IsMergeMethod(name, aliases); got != want {
+			t.Errorf("IsMergeMethod(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -5,13 +5,19 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 
 	"github.com/ryclarke/batch-tool/config"
 )
 
+// GitAuthEnv returns the environment variables which authenticate git with the remote of the repository. It is
+// initialized in the call package to avoid a circular import, and returns nothing by default.
+var GitAuthEnv = func(_ context.Context, _ string) ([]string, error) { return nil, nil }
+
 // Cmd creates an exec.Cmd configured for the given repository context,
 // to facilitate consistent environment and working directory setup.
+// Git commands are also authenticated with the repository's remote (see GitAuthEnv).
 func Cmd(ctx context.Context, repo, command string, arguments ...string) (*exec.Cmd, error) {
 	cmd := exec.CommandContext(ctx, command, arguments...)
 	cmd.Dir = RepoPath(ctx, repo)
@@ -21,6 +27,15 @@ func Cmd(ctx context.Context, repo, command string, arguments ...string) (*exec.
 		return nil, fmt.Errorf("failed to construct environment for %q: %w", repo, err)
 	}
 
+	if command == "git" {
+		auth, err := GitAuthEnv(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to authenticate git for %q: %w", repo, err)
+		}
+
+		env = append(env, auth...)
+	}
+
 	cmd.Env = env
 
 	return cmd, nil
@@ -43,6 +58,11 @@ func Env(ctx context.Context, repo string) ([]string, error) {
 	env = append(env, fmt.Sprintf("GIT_DEFAULT_BRANCH=%s", CatalogBranchLookup(ctx, repoName)))
 	env = append(env, fmt.Sprintf("GIT_PROJECT=%s", CatalogProjectLookup(ctx, repoName)))
 
+	// Namespaced metadata which won't collide with variables the command already uses, matching the template fields
+	env = append(env, fmt.Sprintf("BATCH_REPO=%s", repoName))
+	env = append(env, fmt.Sprintf("BATCH_BRANCH=%s", CatalogBranchLookup(ctx, repoName)))
+	env = append(env, fmt.Sprintf("BATCH_PROJECT=%s", CatalogProjectLookup(ctx, repoName)))
+
 	// Add user-specified environment variables
 	envArgs := viper.GetStringSlice(config.CmdEnv)
 	for _, envArg := range envArgs {
@@ -63,6 +83,30 @@ func Env(ctx context.Context, repo string) ([]string, error) {
 	return env, nil
 }
 
+// envName matches the valid names of environment variables.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnv checks that each of the CmdEnv entries is either a KEY=VALUE pair with a valid variable name,
+// or the path to a readable envfile, so that mistakes are reported before any command runs.
+func ValidateEnv(entries []string) error {
+	for _, entry := range entries {
+		key, _, ok := strings.Cut(entry, "=")
+		if !ok {
+			if _, err := parseEnvFile(entry); err != nil {
+				return fmt.Errorf("%q is neither a KEY=VALUE pair nor a readable envfile: %w", entry, err)
+			}
+
+			continue
+		}
+
+		if !envName.MatchString(key) {
+			return fmt.Errorf("%q has an invalid variable name %q; expected KEY=VALUE", entry, key)
+		}
+	}
+
+	return nil
+}
+
 func parseEnvFile(envArg string) ([]string, error) {
 	envs := make([]string, 0)
 
@@ -87,3 +131,55 @@ func parseEnvFile(envArg string) ([]string, error) {
 
 	return envs, nil
 }
+
+// SplitCommand splits a command line into its arguments like a POSIX shell would, without invoking one: arguments
+// are separated by unquoted whitespace, single quotes preserve their contents literally, and backslashes escape the
+// next character (within double quotes, only when it is special there). Variables and globs are not expanded.
+func SplitCommand(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
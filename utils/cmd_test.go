@@ -3,6 +3,7 @@ package utils_test
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -33,6 +34,9 @@ func TestEnv(t *testing.T) {
 				testhelper.AssertContains(t, env, "REPO_NAME=test-repo")
 				testhelper.AssertContains(t, env, "GIT_BRANCH=main")
 				testhelper.AssertContains(t, env, "GIT_PROJECT=default-project")
+				testhelper.AssertContains(t, env, "BATCH_REPO=test-repo")
+				testhelper.AssertContains(t, env, "BATCH_BRANCH=main")
+				testhelper.AssertContains(t, env, "BATCH_PROJECT=default-project")
 			},
 		},
 		{
@@ -190,3 +194,57 @@ ENV_FILE_VAR3=value3`
 		})
 	}
 }
+
+func TestValidateEnv(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envfile, []byte("FILE_VAR=value\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write envfile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		entries []string
+		wantErr bool
+	}{
+		{name: "key value pairs", entries: []string{"STAGE=prod", "_DEBUG=", "URL=http://host?a=b"}},
+		{name: "envfile", entries: []string{envfile}},
+		{name: "missing envfile", entries: []string{"STAGE"}, wantErr: true},
+		{name: "empty name", entries: []string{"=prod"}, wantErr: true},
+		{name: "name with spaces", entries: []string{"MY STAGE=prod"}, wantErr: true},
+		{name: "name starting with a digit", entries: []string{"1STAGE=prod"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testhelper.AssertError(t, utils.ValidateEnv(tt.entries), tt.wantErr)
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "plain words", line: "git  credential-osxkeychain\t", want: []string{"git", "credential-osxkeychain"}},
+		{name: "single quotes", line: `helper 'a b' '$HOME'`, want: []string{"helper", "a b", "$HOME"}},
+		{name: "double quotes", line: `helper "a \"b\" \c" ""`, want: []string{"helper", `a "b" \c`, ""}},
+		{name: "escapes", line: `/path/with\ space --flag=\'x\'`, want: []string{"/path/with space", "--flag='x'"}},
+		{name: "adjacent quotes", line: `pre'fix'"ed"`, want: []string{"prefixed"}},
+		{name: "no shell operators", line: "helper; rm -rf /", want: []string{"helper;", "rm", "-rf", "/"}},
+		{name: "empty", line: "  ", want: nil},
+		{name: "unterminated quote", line: `helper "oops`, wantErr: true},
+		{name: "trailing escape", line: `helper \`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.SplitCommand(tt.line)
+			testhelper.AssertError(t, err, tt.wantErr)
+			testhelper.AssertEqual(t, strings.Join(got, "|"), strings.Join(tt.want, "|"))
+			testhelper.AssertLength(t, got, len(tt.want))
+		})
+	}
+}
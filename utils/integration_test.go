@@ -134,19 +134,29 @@ func TestSCMIntegrationWithUtils(t *testing.T) {
 
 	t.Run("RepoURLWithSCMContext", func(t *testing.T) {
 		tests := []struct {
-			name    string
-			repo    string
-			wantURL string
+			name     string
+			protocol string
+			repo     string
+			wantURL  string
 		}{
 			{
-				name:    "generates correct SSH URL",
-				repo:    "repo-1",
-				wantURL: "ssh://testuser@github.com/test-project/repo-1.git",
+				name:     "generates correct SSH URL",
+				protocol: config.CloneProtocolSSH,
+				repo:     "repo-1",
+				wantURL:  "ssh://testuser@github.com/test-project/repo-1.git",
+			},
+			{
+				name:     "generates correct HTTPS URL",
+				protocol: config.CloneProtocolHTTPS,
+				repo:     "repo-1",
+				wantURL:  "https://github.com/test-project/repo-1.git",
 			},
 		}
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
+				config.Viper(ctx).Set(config.CloneProtocol, tt.protocol)
+
 				url := utils.RepoURL(ctx, tt.repo)
 				testhelper.AssertEqual(t, url, tt.wantURL)
 			})
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// SetProcessGroup runs the command in its own process group, which is killed as a whole when the command's context
+// is done, so that any processes it spawned (e.g. from a shell script) are stopped along with it.
+func SetProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	cmd.Cancel = func() error {
+		// the negative PID signals every process in the group, which shares the command's PID
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package utils
+
+import (
+	"os/exec"
+)
+
+// SetProcessGroup is a no-op on Windows, where only the command itself is killed when its context is done.
+func SetProcessGroup(_ *exec.Cmd) {}
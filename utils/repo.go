@@ -17,12 +17,42 @@ var (
 	CatalogProjectLookup = defaultProjectLookup
 	// CatalogBranchLookup is a function type for looking up branch from catalog
 	CatalogBranchLookup = defaultBranchLookup
+	// CatalogURLLookup is a function type for looking up the clone URL from catalog
+	CatalogURLLookup = defaultURLLookup
 )
 
-// ParseRepo splits a repo identifier into its component parts
+// PathSeparator separates the repository from the subdirectory in a path-scoped target, e.g. "monorepo//services/api".
+const PathSeparator = "//"
+
+// AvailableLayouts lists the supported strategies for deriving repository directories (see config.GitLayout)
+var AvailableLayouts = []string{config.GitLayoutHost, config.GitLayoutProject, config.GitLayoutFlat}
+
+// SplitTarget splits a path-scoped target into its repository and the subdirectory within it.
+// The subdirectory is empty for targets which refer to a whole repository.
+func SplitTarget(target string) (repo, subdir string) {
+	repo, subdir, _ = strings.Cut(target, PathSeparator)
+
+	return repo, strings.Trim(subdir, "/")
+}
+
+// ValidateTarget checks that the subdirectory of a path-scoped target stays within its repository.
+func ValidateTarget(target string) error {
+	_, subdir := SplitTarget(target)
+
+	for segment := range strings.FieldsFuncSeq(subdir, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("invalid target %q: the path must not contain %q", target, "..")
+		}
+	}
+
+	return nil
+}
+
+// ParseRepo splits a repo identifier into its component parts, ignoring the subdirectory of path-scoped targets
 func ParseRepo(ctx context.Context, repo string) (host, project, name string) {
 	viper := config.Viper(ctx)
 
+	repo, _ = SplitTarget(repo)
 	parts := strings.Split(strings.Trim(repo, "/ "), "/")
 	name = parts[len(parts)-1]
 
@@ -41,7 +71,7 @@ func ParseRepo(ctx context.Context, repo string) (host, project, name string) {
 	return
 }
 
-// RepoPath returns the full repository path for the given name
+// RepoPath returns the full repository path for the given name, including the subdirectory of path-scoped targets
 func RepoPath(ctx context.Context, repo string) string {
 	viper := config.Viper(ctx)
 
@@ -64,9 +94,9 @@ func RepoPath(ctx context.Context, repo string) string {
 		return path
 	}
 
-	host, project, name := ParseRepo(ctx, repo)
+	_, subdir := SplitTarget(repo)
 
-	path, err := filepath.Abs(filepath.Join(viper.GetString(config.GitDirectory), host, project, name))
+	path, err := filepath.Abs(filepath.Join(viper.GetString(config.GitDirectory), repoDir(ctx, repo), subdir))
 	if err != nil {
 		panic(fmt.Sprintf("error determining absolute repo path: %v", err))
 	}
@@ -74,11 +104,43 @@ func RepoPath(ctx context.Context, repo string) string {
 	return path
 }
 
+// repoDir returns the directory of the repository relative to the git directory, using the configured layout.
+// Namespaced hosts and projects (e.g. nested groups) become nested directories, and path segments which would
+// escape the git directory are escaped so each repository always maps to its own directory beneath it.
+func repoDir(ctx context.Context, repo string) string {
+	host, project, name := ParseRepo(ctx, repo)
+
+	switch config.Viper(ctx).GetString(config.GitLayout) {
+	case config.GitLayoutFlat:
+		return safePath(name)
+	case config.GitLayoutProject:
+		return filepath.Join(safePath(project), safePath(name))
+	default:
+		return filepath.Join(safePath(host), safePath(project), safePath(name))
+	}
+}
+
+// safePath converts a slash-separated name into a relative filesystem path, dropping empty segments
+// and escaping segments made up only of dots (such as "..") so the path stays beneath its parent.
+func safePath(name string) string {
+	segments := strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' })
+
+	for i, segment := range segments {
+		if strings.Trim(segment, ".") == "" {
+			segments[i] = strings.ReplaceAll(segment, ".", "%2E")
+		}
+	}
+
+	return filepath.Join(segments...)
+}
+
 // ResolveRepoName returns the SCM repository name for the given argument.
-// The special repo argument "." resolves to the current directory basename.
+// The special repo argument "." resolves to the current directory basename,
+// and path-scoped targets resolve to the repository containing them.
 func ResolveRepoName(repo string) string {
 	if repo != "." {
-		return repo
+		name, _ := SplitTarget(repo)
+		return name
 	}
 
 	cwd, err := os.Getwd()
@@ -89,16 +151,31 @@ func ResolveRepoName(repo string) string {
 	return filepath.Base(cwd)
 }
 
-// RepoURL returns the repository remote url for the given name
+// RepoURL returns the repository remote url for the given name, using the configured clone protocol.
+// The URL reported by the SCM provider is preferred, falling back to one built from the repository's host and project.
 func RepoURL(ctx context.Context, repo string) string {
 	viper := config.Viper(ctx)
 
 	host, project, name := ParseRepo(ctx, repo)
+	ssh := CloneWithSSH(ctx)
+
+	if url := CatalogURLLookup(ctx, project+"/"+name, ssh); url != "" {
+		return url
+	}
+
+	if ssh {
+		return fmt.Sprintf(config.CloneSSHURLTmpl,
+			viper.GetString(config.GitUser),
+			host, project, name,
+		)
+	}
+
+	return fmt.Sprintf(config.CloneHTTPSURLTmpl, host, project, name)
+}
 
-	return fmt.Sprintf(config.CloneSSHURLTmpl,
-		viper.GetString(config.GitUser),
-		host, project, name,
-	)
+// CloneWithSSH reports whether repositories are cloned over SSH rather than HTTPS.
+func CloneWithSSH(ctx context.Context) bool {
+	return strings.EqualFold(strings.TrimSpace(config.Viper(ctx).GetString(config.CloneProtocol)), config.CloneProtocolSSH)
 }
 
 // LookupBranch returns the target branch for the given repository
@@ -123,6 +200,21 @@ func LookupBranch(ctx context.Context, name string) (string, error) {
 	return branch, nil
 }
 
+// BranchSHA returns the SHA of the head commit of the local branch in the given repository.
+func BranchSHA(ctx context.Context, name, branch string) (string, error) {
+	cmd, err := Cmd(ctx, name, "git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("local branch %s not found: %w", branch, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
 func defaultProjectLookup(ctx context.Context, _ string) string {
 	return config.Viper(ctx).GetString(config.GitProject)
 }
@@ -130,3 +222,7 @@ func defaultProjectLookup(ctx context.Context, _ string) string {
 func defaultBranchLookup(ctx context.Context, _ string) string {
 	return config.Viper(ctx).GetString(config.DefaultBranch)
 }
+
+func defaultURLLookup(_ context.Context, _ string, _ bool) string {
+	return ""
+}
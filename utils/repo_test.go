@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -94,6 +95,16 @@ func TestRepoPath(t *testing.T) {
 			repo:     "custom-project/my-repo",
 			wantPath: "/test/gitdir/src/github.com/custom-project/my-repo",
 		},
+		{
+			name:     "path-scoped target",
+			repo:     "my-repo//services/api",
+			wantPath: "/test/gitdir/src/github.com/test-project/my-repo/services/api",
+		},
+		{
+			name:     "path-scoped target with custom project",
+			repo:     "custom-project/my-repo//services/api/",
+			wantPath: "/test/gitdir/src/github.com/custom-project/my-repo/services/api",
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +117,99 @@ func TestRepoPath(t *testing.T) {
 	}
 }
 
+func TestRepoPathLayouts(t *testing.T) {
+	ctx := loadFixture(t)
+	viper := config.Viper(ctx)
+
+	viper.Set(config.GitDirectory, "/test/gitdir/src")
+	viper.Set(config.GitHost, "github.com")
+	viper.Set(config.GitProject, "test-project")
+
+	// Resolve nested groups from the catalog, as with GitLab subgroups
+	lookup := utils.CatalogProjectLookup
+	utils.CatalogProjectLookup = func(_ context.Context, name string) string {
+		if name == "nested-repo" {
+			return "group/subgroup"
+		}
+
+		return "test-project"
+	}
+	t.Cleanup(func() { utils.CatalogProjectLookup = lookup })
+
+	tests := []struct {
+		layout   string
+		repo     string
+		wantPath string
+	}{
+		{layout: config.GitLayoutHost, repo: "my-repo", wantPath: "/test/gitdir/src/github.com/test-project/my-repo"},
+		{layout: config.GitLayoutHost, repo: "team-a/service-1", wantPath: "/test/gitdir/src/github.com/team-a/service-1"},
+		{layout: config.GitLayoutHost, repo: "nested-repo", wantPath: "/test/gitdir/src/github.com/group/subgroup/nested-repo"},
+		{layout: config.GitLayoutHost, repo: "../escape", wantPath: "/test/gitdir/src/github.com/%2E%2E/escape"},
+		{layout: config.GitLayoutProject, repo: "my-repo", wantPath: "/test/gitdir/src/test-project/my-repo"},
+		{layout: config.GitLayoutProject, repo: "team-a/service-1", wantPath: "/test/gitdir/src/team-a/service-1"},
+		{layout: config.GitLayoutProject, repo: "team-b/service-1//cmd", wantPath: "/test/gitdir/src/team-b/service-1/cmd"},
+		{layout: config.GitLayoutProject, repo: "nested-repo", wantPath: "/test/gitdir/src/group/subgroup/nested-repo"},
+		{layout: config.GitLayoutProject, repo: "../escape", wantPath: "/test/gitdir/src/%2E%2E/escape"},
+		{layout: config.GitLayoutFlat, repo: "my-repo", wantPath: "/test/gitdir/src/my-repo"},
+		{layout: config.GitLayoutFlat, repo: "team-a/service-1", wantPath: "/test/gitdir/src/service-1"},
+		{layout: config.GitLayoutFlat, repo: "team-a/service-1//cmd", wantPath: "/test/gitdir/src/service-1/cmd"},
+		{layout: config.GitLayoutFlat, repo: "nested-repo", wantPath: "/test/gitdir/src/nested-repo"},
+		{layout: config.GitLayoutFlat, repo: "team-a/..", wantPath: "/test/gitdir/src/%2E%2E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout+"/"+tt.repo, func(t *testing.T) {
+			viper.Set(config.GitLayout, tt.layout)
+
+			if got := utils.RepoPath(ctx, tt.repo); got != tt.wantPath {
+				t.Errorf("RepoPath() = %v, want %v", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestSplitTarget(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantRepo   string
+		wantSubdir string
+	}{
+		{target: "my-repo", wantRepo: "my-repo"},
+		{target: "project/my-repo", wantRepo: "project/my-repo"},
+		{target: "my-repo//services/api", wantRepo: "my-repo", wantSubdir: "services/api"},
+		{target: "project/my-repo//services/api/", wantRepo: "project/my-repo", wantSubdir: "services/api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			repo, subdir := utils.SplitTarget(tt.target)
+			if repo != tt.wantRepo || subdir != tt.wantSubdir {
+				t.Errorf("SplitTarget() = (%q, %q), want (%q, %q)", repo, subdir, tt.wantRepo, tt.wantSubdir)
+			}
+		})
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	tests := []struct {
+		target  string
+		wantErr bool
+	}{
+		{target: "my-repo"},
+		{target: "my-repo//services/api"},
+		{target: "my-repo//services/..api"},
+		{target: "my-repo//..", wantErr: true},
+		{target: "my-repo//services/../../other-repo", wantErr: true},
+		{target: `my-repo//services\..\..`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			testhelper.AssertError(t, utils.ValidateTarget(tt.target), tt.wantErr)
+		})
+	}
+}
+
 func TestRepoPathEmptyRepo(t *testing.T) {
 	ctx := loadFixture(t)
 	viper := config.Viper(ctx)
@@ -155,6 +259,11 @@ func TestResolveRepoName(t *testing.T) {
 			repo: "owner/repo",
 			want: "owner/repo",
 		},
+		{
+			name: "path-scoped target resolves to repository",
+			repo: "owner/repo//services/api",
+			want: "owner/repo",
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,31 +277,59 @@ func TestResolveRepoName(t *testing.T) {
 }
 
 func TestRepoURL(t *testing.T) {
-	ctx := loadFixture(t)
-
 	tests := []struct {
-		name         string
-		repo         string
-		wantContains []string
+		name       string
+		protocol   string
+		catalogURL string
+		want       string
 	}{
 		{
-			name:         "generates URL with host, project, and repo",
-			repo:         "my-repo",
-			wantContains: []string{"github.com", "test-project", "my-repo"},
+			name:     "defaults to HTTPS",
+			protocol: "",
+			want:     "https://github.com/test-project/my-repo.git",
+		},
+		{
+			name:     "HTTPS preference",
+			protocol: "https",
+			want:     "https://github.com/test-project/my-repo.git",
+		},
+		{
+			name:     "SSH preference",
+			protocol: "SSH",
+			want:     "ssh://git@github.com/test-project/my-repo.git",
+		},
+		{
+			name:       "prefers catalog URL",
+			protocol:   "ssh",
+			catalogURL: "git@github.com:test-project/my-repo.git",
+			want:       "git@github.com:test-project/my-repo.git",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
 			viper := config.Viper(ctx)
 			viper.Set(config.GitUser, "git")
 			viper.Set(config.GitHost, "github.com")
 			viper.Set(config.GitProject, "test-project")
 
-			url := utils.RepoURL(ctx, tt.repo)
-			for _, want := range tt.wantContains {
-				testhelper.AssertContains(t, url, want)
+			if tt.protocol != "" {
+				viper.Set(config.CloneProtocol, tt.protocol)
 			}
+
+			original := utils.CatalogURLLookup
+			t.Cleanup(func() { utils.CatalogURLLookup = original })
+
+			utils.CatalogURLLookup = func(_ context.Context, repoName string, ssh bool) string {
+				if repoName != "test-project/my-repo" || ssh != utils.CloneWithSSH(ctx) {
+					t.Errorf("Unexpected catalog lookup for %q (ssh=%v)", repoName, ssh)
+				}
+
+				return tt.catalogURL
+			}
+
+			testhelper.AssertEqual(t, utils.RepoURL(ctx, "my-repo"), tt.want)
 		})
 	}
 }
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ryclarke/batch-tool/config"
+)
+
+// TemplateData is the context available when rendering a command or pull request template for a repository.
+type TemplateData struct {
+	Repo    string // name of the repository
+	Project string // project containing the repository
+	Path    string // subdirectory of a path-scoped target, if any
+	Branch  string // default branch of the repository
+
+	Vars map[string]any // extra variables loaded from the template vars file
+}
+
+// RenderTemplate renders the text as a Go template for the given repository when a template vars file is
+// configured, and returns it unchanged otherwise. Referencing a variable which isn't defined is an error.
+func RenderTemplate(ctx context.Context, repo, text string) (string, error) {
+	if config.Viper(ctx).GetString(config.TemplateVars) == "" {
+		return text, nil
+	}
+
+	return render(ctx, repo, text)
+}
+
+// RenderCommand is like RenderTemplate, but also renders commands when templating is enabled for exec (see
+// config.ExecTemplate). Otherwise commands are returned unchanged, so that templates meant for the command itself
+// (e.g. go list -f '{{.Path}}') are passed through.
+func RenderCommand(ctx context.Context, repo, text string) (string, error) {
+	viper := config.Viper(ctx)
+	if viper.GetString(config.TemplateVars) == "" && !viper.GetBool(config.ExecTemplate) {
+		return text, nil
+	}
+
+	return render(ctx, repo, text)
+}
+
+// render renders the text as a Go template for the given repository, with the variables from the template vars
+// file (if any) available as .Vars.
+func render(ctx context.Context, repo, text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	vars, err := config.ContextTemplateVars(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(repo).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", text, err)
+	}
+
+	_, project, name := ParseRepo(ctx, repo)
+	_, subdir := SplitTarget(repo)
+
+	data := TemplateData{
+		Repo:    name,
+		Project: project,
+		Path:    subdir,
+		Branch:  CatalogBranchLookup(ctx, name),
+		Vars:    vars,
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render template for %s: %w", repo, err)
+	}
+
+	return rendered.String(), nil
+}
@@ -0,0 +1,177 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/utils"
+	testhelper "github.com/ryclarke/batch-tool/utils/testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	varsYAML := "ticket: OPS-42\nVersion: 1.2.3\nregions:\n  primary: us-east-1\n"
+	varsJSON := `{"ticket": "OPS-43", "regions": {"primary": "eu-west-1"}}`
+
+	tests := []struct {
+		name    string
+		vars    string // contents of the vars file, if any
+		varsExt string
+		repo    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no vars file leaves text unchanged",
+			repo: "repo1",
+			text: "echo {{.Repo}}",
+			want: "echo {{.Repo}}",
+		},
+		{
+			name:    "built-in variables",
+			vars:    varsYAML,
+			varsExt: ".yaml",
+			repo:    "project/repo1//services/api",
+			text:    "{{.Project}}/{{.Repo}} {{.Path}} {{.Branch}}",
+			want:    "project/repo1 services/api main",
+		},
+		{
+			name:    "yaml vars are case-sensitive and nested",
+			vars:    varsYAML,
+			varsExt: ".yml",
+			repo:    "repo1",
+			text:    "{{.Vars.ticket}}: bump to {{.Vars.Version}} in {{.Vars.regions.primary}}",
+			want:    "OPS-42: bump to 1.2.3 in us-east-1",
+		},
+		{
+			name:    "json vars",
+			vars:    varsJSON,
+			varsExt: ".json",
+			repo:    "repo1",
+			text:    "{{.Vars.ticket}} {{.Vars.regions.primary}}",
+			want:    "OPS-43 eu-west-1",
+		},
+		{
+			name:    "text without template actions",
+			vars:    varsYAML,
+			varsExt: ".yaml",
+			repo:    "repo1",
+			text:    "plain text",
+			want:    "plain text",
+		},
+		{
+			name:    "undefined variable",
+			vars:    varsYAML,
+			varsExt: ".yaml",
+			repo:    "repo1",
+			text:    "{{.Vars.missing}}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			vars:    varsYAML,
+			varsExt: ".yaml",
+			repo:    "repo1",
+			text:    "{{.Vars.ticket",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+
+			if tt.vars != "" {
+				path := filepath.Join(t.TempDir(), "vars"+tt.varsExt)
+				if err := os.WriteFile(path, []byte(tt.vars), 0o600); err != nil {
+					t.Fatalf("Failed to write vars file: %v", err)
+				}
+
+				config.Viper(ctx).Set(config.TemplateVars, path)
+			}
+
+			got, err := utils.RenderTemplate(ctx, tt.repo, tt.text)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if !tt.wantErr {
+				testhelper.AssertEqual(t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateMissingVarsFile(t *testing.T) {
+	ctx := loadFixture(t)
+	config.Viper(ctx).Set(config.TemplateVars, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	_, err := utils.RenderTemplate(ctx, "repo1", "{{.Repo}}")
+	testhelper.AssertError(t, err, true)
+	testhelper.AssertContains(t, err.Error(), "failed to read template vars file")
+}
+
+func TestRenderCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     bool
+		template bool
+		text     string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "built-in fields when enabled",
+			template: true,
+			text:     "echo {{.Project}}/{{.Repo}} {{ .Branch }} {{printf \"%s\" $.Repo}}",
+			want:     "echo project/repo1 main repo1",
+		},
+		{
+			name: "commands are passed through unless enabled",
+			text: "go list -m -f '{{.Path}}' && echo {{.Repo}}",
+			want: "go list -m -f '{{.Path}}' && echo {{.Repo}}",
+		},
+		{
+			name:     "escaped braces alongside built-in fields",
+			template: true,
+			text:     `echo {{.Repo}} && go list -f '{{"{{"}}.Dir}}'`,
+			want:     "echo repo1 && go list -f '{{.Dir}}'",
+		},
+		{
+			name:     "undefined fields alongside built-in fields",
+			template: true,
+			text:     "echo {{.Repo}} && go list -f '{{.Dir}}'",
+			wantErr:  true,
+		},
+		{
+			name: "everything is rendered with a vars file",
+			vars: true,
+			text: "echo {{.Vars.version}}",
+			want: "echo 1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := loadFixture(t)
+
+			if tt.vars {
+				path := filepath.Join(t.TempDir(), "vars.yaml")
+				if err := os.WriteFile(path, []byte("version: 1.2.3\n"), 0o600); err != nil {
+					t.Fatalf("Failed to write vars file: %v", err)
+				}
+
+				config.Viper(ctx).Set(config.TemplateVars, path)
+			}
+
+			config.Viper(ctx).Set(config.ExecTemplate, tt.template)
+
+			got, err := utils.RenderCommand(ctx, "project/repo1", tt.text)
+			testhelper.AssertError(t, err, tt.wantErr)
+
+			if !tt.wantErr {
+				testhelper.AssertEqual(t, got, tt.want)
+			}
+		})
+	}
+}
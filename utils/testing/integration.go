@@ -73,6 +73,10 @@ func SetupRealProvider(t *testing.T, ctx context.Context, providerName string) {
 		envVar = "GITHUB_TEST_TOKEN"
 	case "bitbucket":
 		envVar = "BITBUCKET_TEST_TOKEN"
+	case "gitea":
+		envVar = "GITEA_TEST_TOKEN"
+	case "azuredevops":
+		envVar = "AZURE_DEVOPS_TEST_TOKEN"
 	default:
 		t.Fatalf("Unknown provider: %s", providerName)
 	}
@@ -5,10 +5,12 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ryclarke/batch-tool/config"
+	"github.com/ryclarke/batch-tool/scm"
 	"github.com/ryclarke/batch-tool/utils"
 )
 
@@ -76,6 +78,8 @@ type MockChannel struct {
 	output []byte
 	err    error
 	failed bool
+	skip   string
+	pr     *scm.PullRequest
 }
 
 // NewMockChannel creates a new MockChannel with the given name.
@@ -121,6 +125,31 @@ func (m *MockChannel) Failed() bool {
 	return m.failed
 }
 
+// Skip records the reason the operation was skipped.
+func (m *MockChannel) Skip(reason string) {
+	m.skip = reason
+}
+
+// Skipped returns the recorded skip reason, if any.
+func (m *MockChannel) Skipped() string {
+	return m.skip
+}
+
+// SetPullRequest records the pull request produced by the operation.
+func (m *MockChannel) SetPullRequest(pr *scm.PullRequest) {
+	m.pr = pr
+}
+
+// PullRequest returns the recorded pull request, if any.
+func (m *MockChannel) PullRequest() *scm.PullRequest {
+	return m.pr
+}
+
+// Duration returns zero, since the mock doesn't track time.
+func (m *MockChannel) Duration() time.Duration {
+	return 0
+}
+
 // Start is a no-op for the mock.
 func (m *MockChannel) Start(_ int64) error {
 	return nil
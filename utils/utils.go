@@ -19,6 +19,7 @@ func CleanFilter(ctx context.Context, input string) string {
 		viper.GetString(config.TokenLabel), "",
 		viper.GetString(config.TokenSkip), "",
 		viper.GetString(config.TokenForced), "",
+		viper.GetString(config.TokenIntersect), "",
 	)
 
 	return replacer.Replace(input)